@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/rail"
+)
+
+// achUsage describes the ach subcommand
+const achUsage = `Usage: server ach [-url URL] [-key KEY] file [-dir DIR]
+       server ach [-url URL] [-key KEY] returns FILE
+       server ach [-url URL] [-key KEY] settle FILE_ID
+
+Exchanges files with the ACH partner through a running server whose PAYOUT_RAIL is ach,
+authenticating with the API key of an administrator or of the ach rail.
+
+  file     puts the payouts submitted since the previous file in a new NACHA file and
+           saves it in DIR, printing its path; nothing is saved when no payout is waiting
+  returns  reads a NACHA return file, returns the payouts it sends back and prints the
+           returns as JSON; fails when any could not be applied
+  settle   marks the payouts of a file that were not returned as settled and prints the
+           number settled as JSON
+
+`
+
+// achClientTimeout bounds each request to the server
+const achClientTimeout = time.Minute
+
+// achClient calls the ACH endpoints of a running server
+type achClient struct {
+	baseURL string
+	key     string
+	http    *http.Client
+}
+
+// runACH runs the ach subcommand with its arguments, exiting with status 1 when it fails
+func runACH(args []string) {
+	flags := flag.NewFlagSet("ach", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), achUsage)
+		flags.PrintDefaults()
+	}
+	baseURL := flags.String("url", "http://localhost:8080/api/v1", "base URL of the API")
+	key := flags.String("key", os.Getenv("ACH_API_KEY"), "API key, ACH_API_KEY by default")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client := &achClient{baseURL: *baseURL, key: *key, http: &http.Client{Timeout: achClientTimeout}}
+	logger := slog.Default()
+	var err error
+	switch command, args := flags.Arg(0), flags.Args()[1:]; command {
+	case "file":
+		err = client.createFile(args)
+	case "returns":
+		err = client.processReturns(args)
+	case "settle":
+		err = client.settle(args)
+	default:
+		err = fmt.Errorf("unknown command %q", command)
+	}
+	if err != nil {
+		fatal(logger, "ach "+flags.Arg(0)+" failed", err)
+	}
+}
+
+// createFile creates a file and saves it in the directory given by the -dir flag of args
+func (c *achClient) createFile(args []string) error {
+	flags := flag.NewFlagSet("ach file", flag.ExitOnError)
+	dir := flags.String("dir", ".", "directory the file is saved in")
+	_ = flags.Parse(args)
+
+	var file models.ACHFile
+	created, err := c.call(http.MethodPost, "/files", nil, &file)
+	if err != nil {
+		return err
+	}
+	if !created {
+		slog.Info("no payout is waiting for an ACH file")
+		return nil
+	}
+
+	var content bytes.Buffer
+	if _, err := c.call(http.MethodGet, "/files/"+file.ID, nil, &content); err != nil {
+		return fmt.Errorf("downloading file %s: %w", file.ID, err)
+	}
+	path := filepath.Join(*dir, rail.FileName(&file))
+	if err := os.WriteFile(path, content.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("saving file %s: %w", file.ID, err)
+	}
+	slog.Info("ACH file saved", "file_id", file.ID, "entries", file.Entries, "amount", file.Amount)
+	fmt.Println(path)
+	return nil
+}
+
+// processReturns uploads the return file named by args and prints the returns
+func (c *achClient) processReturns(args []string) error {
+	if len(args) != 1 {
+		return errors.New("want the path of one return file")
+	}
+	content, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	var returns []models.ACHReturn
+	if _, err := c.call(http.MethodPost, "/returns", content, &returns); err != nil {
+		return err
+	}
+	if err := printJSON(returns); err != nil {
+		return err
+	}
+
+	for _, r := range returns {
+		if r.Error != "" {
+			return fmt.Errorf("return of trace number %s not applied: %s", r.TraceNumber, r.Error)
+		}
+	}
+	return nil
+}
+
+// settle settles the file whose ID args holds and prints the number of payouts settled
+func (c *achClient) settle(args []string) error {
+	if len(args) != 1 {
+		return errors.New("want the ID of one file")
+	}
+
+	var settlement models.ACHSettlement
+	if _, err := c.call(http.MethodPost, "/files/"+args[0]+"/settlement", nil, &settlement); err != nil {
+		return err
+	}
+	return printJSON(settlement)
+}
+
+// call sends body to the ACH endpoint at path and decodes the response into out, copying it
+// as is when out is a buffer; ok is false when the server answered 204 No Content
+func (c *achClient) call(method, path string, body []byte, out any) (ok bool, err error) {
+	req, err := http.NewRequest(method, c.baseURL+"/rails/"+rail.ACHName+path, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set(middleware.APIKeyHeader, c.key)
+	if body != nil {
+		req.Header.Set("Content-Type", "text/plain")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, nil
+	case resp.StatusCode >= 300:
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return false, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(detail))
+	}
+
+	if buf, isBuffer := out.(*bytes.Buffer); isBuffer {
+		_, err = buf.ReadFrom(resp.Body)
+		return err == nil, err
+	}
+	return true, json.NewDecoder(resp.Body).Decode(out)
+}
+
+// printJSON prints v to stdout as indented JSON
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
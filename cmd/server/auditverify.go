@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"money-transfer/config"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/service/audit"
+	"money-transfer/internal/storage/postgres"
+)
+
+// auditVerifyUsage describes the audit-verify subcommand
+const auditVerifyUsage = `Usage: server audit-verify
+
+Checks that the audit log has not been tampered with, without writing to the database.
+It walks the hash chain of sealed audit events from the first one, prints a report as JSON
+and exits with status 1 when an event was altered, removed or reordered.
+`
+
+// runAuditVerify runs the audit-verify subcommand with its arguments and exits with its status
+func runAuditVerify(args []string) {
+	os.Exit(auditVerify(args))
+}
+
+// auditVerify runs the audit-verify subcommand and returns its exit status
+// It returns instead of exiting so that the store is closed on every path.
+func auditVerify(args []string) int {
+	flags := flag.NewFlagSet("audit-verify", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), auditVerifyUsage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	// Logs go to stderr so that stdout carries the report only
+	logger, err := logging.New(os.Stderr, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		slog.Error("failed to configure logging", "error", err)
+		return 1
+	}
+
+	// The schema is not created or migrated: migrations replace the trigger keeping the audit
+	// log append-only, and the database being verified must not be changed by its verifier
+	store, err := postgres.Open(cfg.Database.GetDSN(), logger)
+	if err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+	if err := store.CheckSchema(context.Background()); err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+
+	report, err := audit.Verify(context.Background(), store.Audit())
+	if err != nil && !errors.Is(err, transfererrors.ErrAuditChainBroken) {
+		logger.Error("failed to read the audit log", "error", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil {
+		logger.Error("failed to write the report", "error", encodeErr)
+		return 1
+	}
+
+	if err != nil {
+		logger.Error("audit log verification failed", "error", err)
+		return 1
+	}
+	logger.Info("audit log verified", "events", report.Verified, "unsealed", report.Unsealed)
+	return 0
+}
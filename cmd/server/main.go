@@ -2,26 +2,49 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"money-transfer/config"
+	"money-transfer/internal/api/grpcapi"
 	"money-transfer/internal/api/handlers"
 	"money-transfer/internal/api/router"
+	"money-transfer/internal/api/server"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/events"
+	"money-transfer/internal/health"
+	"money-transfer/internal/identifier"
+	"money-transfer/internal/lifecycle"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/metrics"
+	"money-transfer/internal/rail"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/screening"
+	"money-transfer/internal/service"
+	"money-transfer/internal/service/audit"
 	"money-transfer/internal/service/bank"
+	"money-transfer/internal/service/batch"
+	"money-transfer/internal/service/funding"
+	"money-transfer/internal/service/payout"
+	"money-transfer/internal/service/reconcile"
+	"money-transfer/internal/service/webhook"
 	"money-transfer/internal/storage/postgres"
+	"money-transfer/internal/tracing"
 
 	"github.com/gin-gonic/gin"
 )
 
 // @title Money Transfer API
 // @version 1.0
-// @description API for money transfers between accounts
+// @description API for money transfers between accounts.
+// @description Errors are RFC 7807 problem details served as application/problem+json with a stable code.
 
 // @host localhost:8080
 // @BasePath /api/v1
@@ -29,67 +52,406 @@ import (
 // @schemes http
 // @produce json
 // @consumes json
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
+	// Subcommands run instead of the server
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "reconcile":
+			runReconcile(os.Args[2:])
+			return
+		case "ach":
+			runACH(os.Args[2:])
+			return
+		case "audit-verify":
+			runAuditVerify(os.Args[2:])
+			return
+		case "migrate-account-ids":
+			runMigrateAccounts(os.Args[2:])
+			return
+		}
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
+		fatal(slog.Default(), "failed to load configuration", err)
+	}
+
+	// Initialize logging; the standard logger is redirected for libraries that use it
+	logger, err := logging.New(os.Stdout, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		fatal(slog.Default(), "failed to configure logging", err)
+	}
+	slog.SetDefault(logger)
+
+	// Initialize tracing before anything that records spans
+	tracerProvider, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:     cfg.Tracing.Exporter,
+		FilePath:     cfg.Tracing.FilePath,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		fatal(logger, "failed to configure tracing", err)
 	}
 
 	gin.SetMode(gin.ReleaseMode)
 
 	// Initialize storage
-	store, err := postgres.NewStore(cfg.Database.GetDSN())
+	store, err := postgres.NewStore(cfg.Database.GetDSN(), logger)
 	if err != nil {
-		log.Fatal(err)
+		fatal(logger, "failed to open database", err)
 	}
 
-	if err := store.Account().InitializeTestData(context.Background()); err != nil {
-		log.Fatal(err)
+	// Demo accounts are only created for development; existing accounts are never reset
+	if cfg.Database.SeedDemoAccounts {
+		if err := store.Account().InitializeTestData(context.Background()); err != nil {
+			fatal(logger, "failed to create demo accounts", err)
+		}
+	}
+
+	// Initialize API key authentication
+	apiKeys, err := auth.ParseAPIKeys(cfg.Auth.APIKeys)
+	if err != nil {
+		fatal(logger, "failed to load API keys", err)
+	}
+
+	// Initialize metrics, including connection pool and business gauges read from the store
+	collector := metrics.New()
+	collector.RegisterStore(store)
+
+	// Initialize readiness checks of the database and its schema
+	checker := health.NewChecker(health.Config{
+		Timeout: cfg.Health.CheckTimeout,
+		DB:      store.DB(),
+		Checks:  map[string]health.Check{"schema": store.CheckSchema},
+	})
+
+	// Components register their start and stop hooks here; they stop in reverse order,
+	// so the store is closed only after everything using it has drained
+	lc := lifecycle.New(logger)
+	lc.Append(lifecycle.Hook{Name: "store", OnStop: func(context.Context) error { return store.Close() }})
+
+	// Screen the parties of transfers against the sanctions list, reloaded whenever its file changes
+	var screener bank.Screener
+	if cfg.Screening.ListPath != "" {
+		sanctions, err := screening.NewScreener(cfg.Screening.ListPath, screening.Config{
+			HoldScore:      cfg.Screening.HoldScore,
+			BlockScore:     cfg.Screening.BlockScore,
+			ReloadInterval: cfg.Screening.ReloadInterval,
+		}, logger)
+		if err != nil {
+			fatal(logger, "failed to load sanctions list", err)
+		}
+		lc.Append(lifecycle.WorkerHook("sanctions list", sanctions))
+		screener = sanctions
+	}
+
+	// Each system account has a single role, so that its balance means one thing
+	if err := checkSystemAccounts(cfg); err != nil {
+		fatal(logger, "invalid system accounts", err)
 	}
 
 	// Initialize services
-	bankService := bank.NewService(store)
+	bankService := bank.NewService(store, logger, collector, screener)
+	// Once customer accounts have UUIDs, only the system accounts keep their legacy IDs
+	if !cfg.Transfer.LegacyAccountIDs {
+		bankService.RestrictAccountIDs(identifier.RestrictLegacy(append(
+			[]string{cfg.Payout.ClearingAccount, cfg.Payout.SettlementAccount}, cfg.Funding.SystemAccounts...)...))
+	}
+	webhookService := webhook.NewService(store)
+	auditService := audit.NewService(store.Audit())
+	batchService := batch.NewService(bankService, store.PaymentMessage(), logger)
+	payoutService, achService, err := newPayoutService(cfg.Payout, store, bankService, lc, logger)
+	if err != nil {
+		fatal(logger, "failed to configure payouts", err)
+	}
+	fundingService, err := newFundingService(cfg.Funding, store, bankService, logger)
+	if err != nil {
+		fatal(logger, "failed to configure funding", err)
+	}
+
+	// Seal the audit events recorded with every change into the hash chain
+	lc.Append(lifecycle.WorkerHook("audit sealer",
+		audit.NewSealer(store.Audit(), cfg.Audit.SealBatchSize, cfg.Audit.SealInterval, logger)))
+
+	// Reconcile balances against the ledger every night
+	if cfg.Reconcile.Time != "" {
+		reconcileAt, err := reconcile.ParseTime(cfg.Reconcile.Time)
+		if err != nil {
+			fatal(logger, "failed to schedule reconciliation", err)
+		}
+		lc.Append(lifecycle.WorkerHook("reconciliation",
+			reconcile.NewJob(store.Ledger(), reconcileAt, cfg.Reconcile.ReportDir, collector, logger)))
+	}
+
+	// Relay domain events from the outbox and deliver the webhooks it queues
+	publisher, err := newPublisher(cfg.Outbox, logger)
+	if err != nil {
+		fatal(logger, "failed to create event publisher", err)
+	}
+	if closer, ok := publisher.(io.Closer); ok {
+		lc.Append(lifecycle.Hook{Name: "event publisher", OnStop: func(context.Context) error { return closer.Close() }})
+	}
+
+	deliverer := webhook.NewDeliverer(store, webhook.DelivererConfig{
+		Workers:      cfg.Webhook.Workers,
+		PollInterval: cfg.Webhook.PollInterval,
+		Timeout:      cfg.Webhook.Timeout,
+		MaxAttempts:  cfg.Webhook.MaxAttempts,
+		BackoffBase:  cfg.Webhook.BackoffBase,
+		BackoffMax:   cfg.Webhook.BackoffMax,
+		Logger:       logger,
+	})
+	lc.Append(lifecycle.WorkerHook("webhook deliverer", deliverer))
+
+	relay := events.NewRelay(store.Outbox(), events.NewMultiPublisher(publisher, webhookService),
+		cfg.Outbox.BatchSize, cfg.Outbox.PollInterval, logger)
+	lc.Append(lifecycle.WorkerHook("outbox relay", relay))
+
+	// Execute transfers in the background; on shutdown workers finish the transfers they have claimed,
+	// and transfers abandoned by a crashed replica are executed again once their lease expires
+	executor := bank.NewExecutor(bankService, cfg.Transfer.Workers, cfg.Transfer.PollInterval, cfg.Transfer.Lease)
+	lc.Append(lifecycle.WorkerHook("transfer executor", executor))
+
+	// Rate limit requests; the sweeper drops buckets that have refilled
+	limiter, err := newLimiter(cfg.RateLimit.Backend, store)
+	if err != nil {
+		fatal(logger, "failed to create rate limiter", err)
+	}
+	rateLimitRules, err := ratelimit.ParseRules(cfg.RateLimit.Rules)
+	if err != nil {
+		fatal(logger, "failed to load rate limit rules", err)
+	}
+	if limiter != nil {
+		lc.Append(lifecycle.WorkerHook("rate limit sweeper",
+			ratelimit.NewSweeper(limiter, cfg.RateLimit.SweepInterval, logger)))
+	}
+	rateLimitConfig := handlers.RateLimitConfig{Limiter: limiter, Rules: rateLimitRules}
 
 	// Create handlers using factory
-	handlersFactory := handlers.NewFactory(bankService)
+	handlersFactory := handlers.NewFactory(&handlers.HandlerConfig{
+		BankService:        bankService,
+		WebhookService:     webhookService,
+		AuditService:       auditService,
+		BatchService:       batchService,
+		PayoutService:      payoutService,
+		ACHService:         achService,
+		FundingService:     fundingService,
+		ScreeningService:   bankService,
+		Authenticator:      apiKeys,
+		Logger:             logger,
+		Metrics:            collector,
+		Health:             checker,
+		Stopping:           lc.Stopping(),
+		MaxBodySize:        cfg.Server.MaxBodySize,
+		StreamPollInterval: cfg.Stream.PollInterval,
+		StreamHeartbeat:    cfg.Stream.HeartbeatInterval,
+		WebSocket: handlers.WebSocketConfig{
+			PingInterval:  cfg.WebSocket.PingInterval,
+			PongTimeout:   cfg.WebSocket.PongTimeout,
+			SendQueueSize: cfg.WebSocket.SendQueueSize,
+			MaxInFlight:   cfg.WebSocket.MaxInFlight,
+		},
+		GraphQL: handlers.GraphQLConfig{
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		},
+		RateLimit: rateLimitConfig,
+	})
 	appHandlers := handlersFactory.CreateHandlers()
 
 	// Initialize router
-	r := router.NewRouter(appHandlers)
+	r := router.NewRouter(appHandlers, logger)
+
+	// Create gRPC server sharing the same services
+	grpcServer := grpcapi.NewServer(grpcapi.Config{
+		BankService:        bankService,
+		Authenticator:      apiKeys,
+		TransferThrottle:   handlers.NewTransferThrottle(rateLimitConfig),
+		Logger:             logger,
+		StreamPollInterval: cfg.Stream.PollInterval,
+		Stopping:           lc.Stopping(),
+	})
+	lc.Append(grpcapi.Hook(grpcServer, fmt.Sprintf(":%s", cfg.GRPC.Port), logger))
 
 	// Create HTTP server
-	srv := &http.Server{
+	httpServer := server.New(server.Config{
 		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
 		Handler:      r,
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
-	}
+	}, logger)
+	lc.Append(httpServer.Hook())
+
+	// Stop receiving new traffic from load balancers before anything else stops, and keep serving
+	// until they have seen the failing probe
+	lc.Append(lifecycle.Hook{Name: "readiness", OnStop: func(ctx context.Context) error {
+		checker.Drain()
+		logger.InfoContext(ctx, "draining before shutdown", "delay", cfg.Shutdown.DrainDelay.String())
+		select {
+		case <-time.After(cfg.Shutdown.DrainDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}})
 
 	// Channel for OS signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start server in goroutine
-	go func() {
-		log.Printf("Starting server on port %s", cfg.Server.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+	if err := lc.Start(context.Background()); err != nil {
+		fatal(logger, "failed to start server", err)
+	}
 
 	// Wait for termination signal
 	<-quit
-	log.Println("Shutting down server...")
+	logger.Info("shutting down server", "timeout", cfg.Shutdown.Timeout.String())
 
-	// Context with timeout for graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+	if err := lc.Stop(ctx); err != nil {
+		logger.Error("server did not shut down cleanly", "error", err)
+	}
+
+	// Export the spans of the work that has just finished
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		logger.Error("failed to flush traces", "error", err)
+	}
+
+	logger.Info("server exited properly")
+}
+
+// fatal logs err and exits the process
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// newPublisher creates the event publisher selected in configuration
+func newPublisher(cfg config.OutboxConfig, logger *slog.Logger) (events.Publisher, error) {
+	switch cfg.Publisher {
+	case "log":
+		return events.NewLogPublisher(logger), nil
+	case "file":
+		return events.NewFilePublisher(cfg.FilePath)
+	case "nats":
+		return events.NewNATSPublisher(cfg.NATSURL, cfg.NATSSubject)
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", cfg.Publisher)
 	}
+}
 
-	log.Println("Server exited properly")
+// newLimiter creates the rate limiter selected in configuration; none disables rate limiting
+func newLimiter(backend string, store *postgres.Store) (ratelimit.Limiter, error) {
+	switch backend {
+	case "none":
+		return nil, nil
+	case "memory":
+		return ratelimit.NewMemory(), nil
+	case "postgres":
+		return store.RateLimit(), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit backend %q", backend)
+	}
+}
+
+// newPayoutService creates the payout service sending payouts through the rail selected in
+// configuration, after creating its clearing and settlement accounts; none disables payouts
+// The ACH rail is returned as well when it is the one selected, so that its files are served.
+// The simulator is registered with lc, so that the outcomes it has yet to report stop with it.
+func newPayoutService(
+	cfg config.PayoutConfig, store *postgres.Store, bankService *bank.Service, lc lifecycle.Lifecycle, logger *slog.Logger,
+) (service.PayoutService, service.ACHService, error) {
+	var paymentRail rail.LocalRail
+	var achService service.ACHService
+	switch cfg.Rail {
+	case "none":
+		return nil, nil, nil
+	case rail.SimulatorName:
+		simulator, err := rail.NewSimulator(rail.Mode(cfg.SimulatorMode), cfg.SimulatorDelay, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		lc.Append(lifecycle.WorkerHook("payout simulator", simulator))
+		paymentRail = simulator
+	case rail.ACHName:
+		ach, err := rail.NewACH(rail.ACHConfig{
+			Destination:      cfg.ACHDestination,
+			DestinationName:  cfg.ACHDestinationName,
+			Origin:           cfg.ACHOrigin,
+			OriginName:       cfg.ACHOriginName,
+			CompanyName:      cfg.ACHCompanyName,
+			CompanyID:        cfg.ACHCompanyID,
+			ODFI:             cfg.ACHODFI,
+			EntryDescription: cfg.ACHEntryDescription,
+		}, store.ACH(), logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		paymentRail, achService = ach, ach
+	default:
+		return nil, nil, fmt.Errorf("unknown payment rail %q", cfg.Rail)
+	}
+
+	if err := store.Account().EnsureSystemAccounts(context.Background(), cfg.ClearingAccount, cfg.SettlementAccount); err != nil {
+		return nil, nil, err
+	}
+	payoutService := payout.NewService(bankService, store, []rail.PaymentRail{paymentRail}, payout.Config{
+		ClearingAccount:   cfg.ClearingAccount,
+		SettlementAccount: cfg.SettlementAccount,
+	}, logger)
+
+	// Payouts held for sanctions review go on once their transfer is reviewed
+	bankService.OnReviewed(func(ctx context.Context, transfer *models.Transfer) {
+		if err := payoutService.ResumeHeld(ctx, transfer); err != nil {
+			logger.ErrorContext(ctx, "failed to resume held payout", "transfer_id", transfer.ID, "error", err)
+		}
+	})
+	// The simulator and the ACH rail run in this process, so they report outcomes directly
+	// instead of on the API
+	paymentRail.OnOutcome(func(ctx context.Context, name string, callback models.RailCallback) error {
+		_, err := payoutService.HandleCallback(ctx, name, callback)
+		return err
+	})
+	return payoutService, achService, nil
+}
+
+// checkSystemAccounts returns an error if the payout clearing and settlement accounts are the
+// same account or one of them is a funding system account, which deposits could overdraw and
+// withdrawals could take payout funds from
+func checkSystemAccounts(cfg *config.Config) error {
+	if cfg.Payout.ClearingAccount == cfg.Payout.SettlementAccount {
+		return fmt.Errorf("PAYOUT_CLEARING_ACCOUNT and PAYOUT_SETTLEMENT_ACCOUNT are both %q", cfg.Payout.ClearingAccount)
+	}
+	for _, account := range cfg.Funding.SystemAccounts {
+		if account == cfg.Payout.ClearingAccount || account == cfg.Payout.SettlementAccount {
+			return fmt.Errorf("FUNDING_SYSTEM_ACCOUNTS lists the payout account %q", account)
+		}
+	}
+	return nil
+}
+
+// newFundingService creates the funding service after creating the system accounts deposits
+// and withdrawals may use; the first one is used by those naming none
+func newFundingService(
+	cfg config.FundingConfig, store *postgres.Store, bankService *bank.Service, logger *slog.Logger,
+) (service.FundingService, error) {
+	if len(cfg.SystemAccounts) == 0 {
+		return nil, errors.New("no funding system accounts configured")
+	}
+	if err := store.Account().EnsureSystemAccounts(context.Background(), cfg.SystemAccounts...); err != nil {
+		return nil, err
+	}
+	return funding.NewService(bankService, store, funding.Config{
+		SystemAccounts: cfg.SystemAccounts,
+		DefaultAccount: cfg.SystemAccounts[0],
+	}, logger), nil
 }
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"money-transfer/config"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/service/bank"
+	"money-transfer/internal/storage/postgres"
+)
+
+// migrateAccountsUsage describes the migrate-account-ids subcommand
+const migrateAccountsUsage = `Usage: server migrate-account-ids
+
+Gives every customer account still named by legacy ID, such as Mark, an account with a UUID
+held by the same holder, and moves its balance there, so that TRANSFER_LEGACY_ACCOUNT_IDS can
+be set to false. It prints each legacy ID with the UUID of its new account as JSON; point the
+API keys of their owners at the new IDs before legacy IDs are turned off. The UUIDs are
+derived from the legacy IDs, so running it again moves only the money that reached the legacy
+accounts since, and exits with status 1 when an account could not be migrated.
+`
+
+// runMigrateAccounts runs the migrate-account-ids subcommand with its arguments and exits with its status
+func runMigrateAccounts(args []string) {
+	os.Exit(migrateAccounts(args))
+}
+
+// migrateAccounts runs the migrate-account-ids subcommand and returns its exit status
+// It returns instead of exiting so that the store is closed on every path.
+func migrateAccounts(args []string) int {
+	flags := flag.NewFlagSet("migrate-account-ids", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), migrateAccountsUsage)
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(args)
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	// Logs go to stderr so that stdout carries the migrations only
+	logger, err := logging.New(os.Stderr, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		slog.Error("failed to configure logging", "error", err)
+		return 1
+	}
+
+	// The schema is migrated by the server, which must have run the current version before
+	store, err := postgres.Open(cfg.Database.GetDSN(), logger)
+	if err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+	if err := store.CheckSchema(context.Background()); err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+
+	// Balances move by transfers that are not screened, as they stay with the same holder
+	migrations, err := bank.NewService(store, logger, nil, nil).MigrateLegacyAccounts(context.Background())
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(migrations); encodeErr != nil {
+		logger.Error("failed to write the migrations", "error", encodeErr)
+		return 1
+	}
+
+	if err != nil {
+		logger.Error("legacy account migration failed", "error", err)
+		return 1
+	}
+	logger.Info("legacy accounts migrated", "accounts", len(migrations))
+	return 0
+}
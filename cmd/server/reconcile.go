@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"money-transfer/config"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/service/reconcile"
+	"money-transfer/internal/storage/postgres"
+)
+
+// reconcileUsage describes the reconcile subcommand
+const reconcileUsage = `Usage: server reconcile [-format json|csv]
+
+Checks every account balance against the postings ledger without writing to the database.
+It prints the report as JSON, or its discrepancies as CSV, writes both files to
+RECONCILE_REPORT_DIR when set, and exits with status 1 when any check fails, so that it
+can run from cron.
+`
+
+// runReconcile runs the reconcile subcommand with its arguments and exits with its status
+func runReconcile(args []string) {
+	os.Exit(reconcileLedger(args))
+}
+
+// reconcileLedger runs the reconcile subcommand and returns its exit status
+// It returns instead of exiting so that the store is closed on every path.
+func reconcileLedger(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), reconcileUsage)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", "json", "format of the report printed to stdout: json or csv")
+	_ = flags.Parse(args)
+	if *format != "json" && *format != "csv" {
+		slog.Error("invalid flag", "error", fmt.Errorf("unknown format %q", *format))
+		return 1
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", "error", err)
+		return 1
+	}
+
+	// Logs go to stderr so that stdout carries the report only
+	logger, err := logging.New(os.Stderr, logging.Config{Level: cfg.Log.Level, Format: cfg.Log.Format})
+	if err != nil {
+		slog.Error("failed to configure logging", "error", err)
+		return 1
+	}
+
+	// The schema is not created or migrated: migrations backfill the ledger, which would hide
+	// the discrepancies reconciliation is meant to report
+	store, err := postgres.Open(cfg.Database.GetDSN(), logger)
+	if err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error("failed to close database", "error", err)
+		}
+	}()
+	if err := store.CheckSchema(context.Background()); err != nil {
+		logger.Error("failed to open database", "error", err)
+		return 1
+	}
+
+	report, err := reconcile.Run(context.Background(), store.Ledger())
+	if err != nil {
+		logger.Error("failed to read the ledger", "error", err)
+		return 1
+	}
+
+	if cfg.Reconcile.ReportDir != "" {
+		paths, err := reconcile.WriteFiles(cfg.Reconcile.ReportDir, report)
+		if err != nil {
+			logger.Error("failed to write the report files", "error", err)
+			return 1
+		}
+		logger.Info("reconciliation report written", "reports", paths)
+	}
+
+	write := reconcile.WriteJSON
+	if *format == "csv" {
+		write = reconcile.WriteCSV
+	}
+	if err := write(os.Stdout, report); err != nil {
+		logger.Error("failed to write the report", "error", err)
+		return 1
+	}
+
+	if !report.Balanced {
+		logger.Error("reconciliation found discrepancies", "discrepancies", len(report.Discrepancies))
+		return 1
+	}
+	logger.Info("ledger reconciled", "accounts", report.Accounts, "postings", report.Postings)
+	return 0
+}
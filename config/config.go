@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,8 +12,26 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Transfer  TransferConfig
+	Outbox    OutboxConfig
+	Webhook   WebhookConfig
+	Auth      AuthConfig
+	Stream    StreamConfig
+	WebSocket WebSocketConfig
+	GRPC      GRPCConfig
+	GraphQL   GraphQLConfig
+	Log       LogConfig
+	Tracing   TracingConfig
+	Health    HealthConfig
+	Shutdown  ShutdownConfig
+	RateLimit RateLimitConfig
+	Audit     AuditConfig
+	Reconcile ReconcileConfig
+	Payout    PayoutConfig
+	Funding   FundingConfig
+	Screening ScreeningConfig
 }
 
 // ServerConfig holds all HTTP server related configuration
@@ -21,6 +40,8 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// MaxBodySize bounds the JSON request bodies the API reads, in bytes
+	MaxBodySize int64
 }
 
 // DatabaseConfig holds all database related configuration
@@ -31,6 +52,166 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// SeedDemoAccounts creates the demo accounts Mark, Jane and Adam at startup when they do not exist;
+	// for development only
+	SeedDemoAccounts bool
+}
+
+// TransferConfig holds configuration for transfers and their asynchronous execution
+type TransferConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Lease        time.Duration // How long a transfer may stay processing before it is executed again, or failed if executed inline
+	// LegacyAccountIDs allows transfers between customer accounts named by legacy ID rather than UUID;
+	// system accounts keep their legacy IDs either way
+	LegacyAccountIDs bool
+}
+
+// OutboxConfig holds configuration for relaying domain events from the outbox
+type OutboxConfig struct {
+	Publisher    string // log, file or nats
+	FilePath     string
+	NATSURL      string
+	NATSSubject  string
+	BatchSize    int
+	PollInterval time.Duration
+}
+
+// WebhookConfig holds configuration for outgoing webhook deliveries
+type WebhookConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+	BackoffBase  time.Duration
+	BackoffMax   time.Duration
+}
+
+// AuthConfig holds configuration for authenticating API callers
+type AuthConfig struct {
+	APIKeys string // comma-separated key:principal:role[:account|account...] entries
+}
+
+// StreamConfig holds configuration for server-pushed event streams
+type StreamConfig struct {
+	PollInterval      time.Duration
+	HeartbeatInterval time.Duration
+}
+
+// WebSocketConfig holds configuration for WebSocket API connections
+type WebSocketConfig struct {
+	PingInterval  time.Duration
+	PongTimeout   time.Duration
+	SendQueueSize int
+	MaxInFlight   int
+}
+
+// GRPCConfig holds configuration for the gRPC server
+type GRPCConfig struct {
+	Port string
+}
+
+// GraphQLConfig holds the limits applied to GraphQL queries
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// LogConfig holds configuration for application logs
+type LogConfig struct {
+	Level  string // debug, info, warn or error
+	Format string // json or text
+}
+
+// TracingConfig holds configuration for exporting OpenTelemetry traces
+type TracingConfig struct {
+	Exporter     string // none, stdout, file or otlp
+	FilePath     string
+	OTLPEndpoint string
+	SampleRatio  float64
+}
+
+// HealthConfig holds configuration for the readiness checks
+type HealthConfig struct {
+	CheckTimeout time.Duration
+}
+
+// ShutdownConfig holds configuration for graceful shutdown
+type ShutdownConfig struct {
+	// Timeout bounds draining requests, streams and background workers before the store is closed
+	Timeout time.Duration
+	// DrainDelay is how long the server keeps serving after readiness turns unavailable, so that
+	// load balancers see the failing probe and stop routing to it; it counts towards Timeout
+	DrainDelay time.Duration
+}
+
+// RateLimitConfig holds configuration for rate limiting
+type RateLimitConfig struct {
+	// Backend keeps the token buckets: none, memory or postgres
+	Backend string
+	// Rules lists the limits of each route, as parsed by ratelimit.ParseRules
+	Rules         string
+	SweepInterval time.Duration
+}
+
+// AuditConfig holds configuration for sealing the audit log into its hash chain
+type AuditConfig struct {
+	SealBatchSize int
+	SealInterval  time.Duration
+}
+
+// ReconcileConfig holds configuration for the nightly reconciliation of balances against the ledger
+type ReconcileConfig struct {
+	// Time is when reconciliation runs every day, as HH:MM in UTC; empty disables the schedule
+	Time string
+	// ReportDir receives the JSON and CSV report of every run; empty keeps reports in the logs only
+	ReportDir string
+}
+
+// PayoutConfig holds configuration for payouts to external accounts
+type PayoutConfig struct {
+	// Rail selects the payment rail payouts are sent through: simulator, ach, or none to disable payouts
+	Rail string
+	// ClearingAccount holds the funds of payouts until their rail settles or returns them
+	ClearingAccount string
+	// SettlementAccount receives the funds of settled payouts
+	SettlementAccount string
+	// SimulatorMode is the outcome the simulator rail gives every payout: settle, return, reject or hold
+	SimulatorMode string
+	// SimulatorDelay is how long the simulator rail takes to report the outcome of a payout
+	SimulatorDelay time.Duration
+	// ACHDestination is the routing number of the ACH partner the ACH rail sends files to
+	ACHDestination     string
+	ACHDestinationName string
+	// ACHOrigin identifies this bank to the ACH partner: a routing number or a 10 character company ID
+	ACHOrigin     string
+	ACHOriginName string
+	// ACHCompanyName and ACHCompanyID identify the originator of payouts to their receivers
+	ACHCompanyName string
+	ACHCompanyID   string
+	// ACHODFI is the routing number of this bank, which starts the trace numbers of ACH entries
+	ACHODFI string
+	// ACHEntryDescription tells receivers what ACH entries are for
+	ACHEntryDescription string
+}
+
+// FundingConfig holds configuration for deposits and withdrawals
+type FundingConfig struct {
+	// SystemAccounts lists the system accounts deposits and withdrawals may use; the first is
+	// used by those naming none
+	SystemAccounts []string
+}
+
+// ScreeningConfig holds configuration for screening transfers against a sanctions list
+type ScreeningConfig struct {
+	// ListPath is the OFAC SDN CSV file parties are screened against; empty disables screening
+	ListPath string
+	// HoldScore is the match score from which transfers are held for review
+	HoldScore float64
+	// BlockScore is the match score from which transfers are blocked
+	BlockScore float64
+	// ReloadInterval is how often the list file is checked for changes
+	ReloadInterval time.Duration
 }
 
 // Load reads configuration from environment files and environment variables
@@ -73,6 +254,68 @@ func Load() (*Config, error) {
 
 	viper.AutomaticEnv()
 
+	viper.SetDefault("SERVER_MAX_BODY_SIZE", 1<<20)
+	viper.SetDefault("DB_SEED_DEMO_ACCOUNTS", false)
+	viper.SetDefault("TRANSFER_WORKERS", 4)
+	viper.SetDefault("TRANSFER_POLL_INTERVAL", time.Second)
+	viper.SetDefault("TRANSFER_LEASE", 5*time.Minute)
+	viper.SetDefault("TRANSFER_LEGACY_ACCOUNT_IDS", true)
+	viper.SetDefault("OUTBOX_PUBLISHER", "log")
+	viper.SetDefault("OUTBOX_FILE_PATH", "events.jsonl")
+	viper.SetDefault("OUTBOX_NATS_URL", "nats://localhost:4222")
+	viper.SetDefault("OUTBOX_NATS_SUBJECT", "money-transfer.events")
+	viper.SetDefault("OUTBOX_BATCH_SIZE", 100)
+	viper.SetDefault("OUTBOX_POLL_INTERVAL", time.Second)
+	viper.SetDefault("WEBHOOK_WORKERS", 2)
+	viper.SetDefault("WEBHOOK_POLL_INTERVAL", time.Second)
+	viper.SetDefault("WEBHOOK_TIMEOUT", 10*time.Second)
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_BACKOFF_BASE", 10*time.Second)
+	viper.SetDefault("WEBHOOK_BACKOFF_MAX", time.Hour)
+	viper.SetDefault("STREAM_POLL_INTERVAL", time.Second)
+	viper.SetDefault("STREAM_HEARTBEAT_INTERVAL", 15*time.Second)
+	viper.SetDefault("WS_PING_INTERVAL", 30*time.Second)
+	viper.SetDefault("WS_PONG_TIMEOUT", 60*time.Second)
+	viper.SetDefault("WS_SEND_QUEUE_SIZE", 64)
+	viper.SetDefault("WS_MAX_IN_FLIGHT", 8)
+	viper.SetDefault("GRPC_PORT", "9090")
+	viper.SetDefault("GRAPHQL_MAX_DEPTH", 8)
+	viper.SetDefault("GRAPHQL_MAX_COMPLEXITY", 1000)
+	viper.SetDefault("LOG_LEVEL", "info")
+	viper.SetDefault("LOG_FORMAT", "json")
+	viper.SetDefault("TRACING_EXPORTER", "none")
+	viper.SetDefault("TRACING_FILE_PATH", "traces.jsonl")
+	viper.SetDefault("TRACING_OTLP_ENDPOINT", "http://localhost:4318")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", 2*time.Second)
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 30*time.Second)
+	viper.SetDefault("SHUTDOWN_DRAIN_DELAY", 5*time.Second)
+	viper.SetDefault("RATE_LIMIT_BACKEND", "memory")
+	viper.SetDefault("RATE_LIMIT_RULES", "POST /api/v1/transfer principal=60/m account=30/m ip=120/m; * ip=600/m")
+	viper.SetDefault("RATE_LIMIT_SWEEP_INTERVAL", time.Minute)
+	viper.SetDefault("AUDIT_SEAL_BATCH_SIZE", 500)
+	viper.SetDefault("AUDIT_SEAL_INTERVAL", time.Second)
+	viper.SetDefault("RECONCILE_TIME", "02:00")
+	viper.SetDefault("RECONCILE_REPORT_DIR", "reports")
+	viper.SetDefault("PAYOUT_RAIL", "none")
+	viper.SetDefault("PAYOUT_CLEARING_ACCOUNT", "clearing")
+	viper.SetDefault("PAYOUT_SETTLEMENT_ACCOUNT", "settlement")
+	viper.SetDefault("RAIL_SIMULATOR_MODE", "settle")
+	viper.SetDefault("RAIL_SIMULATOR_DELAY", 2*time.Second)
+	viper.SetDefault("RAIL_ACH_DESTINATION", "")
+	viper.SetDefault("RAIL_ACH_DESTINATION_NAME", "")
+	viper.SetDefault("RAIL_ACH_ORIGIN", "")
+	viper.SetDefault("RAIL_ACH_ORIGIN_NAME", "")
+	viper.SetDefault("RAIL_ACH_COMPANY_NAME", "")
+	viper.SetDefault("RAIL_ACH_COMPANY_ID", "")
+	viper.SetDefault("RAIL_ACH_ODFI", "")
+	viper.SetDefault("RAIL_ACH_ENTRY_DESCRIPTION", "PAYOUT")
+	viper.SetDefault("FUNDING_SYSTEM_ACCOUNTS", "cash,suspense")
+	viper.SetDefault("SCREENING_LIST_PATH", "")
+	viper.SetDefault("SCREENING_HOLD_SCORE", 0.85)
+	viper.SetDefault("SCREENING_BLOCK_SCORE", 0.95)
+	viper.SetDefault("SCREENING_RELOAD_INTERVAL", time.Minute)
+
 	var cfg Config
 
 	// Server configuration
@@ -81,21 +324,167 @@ func Load() (*Config, error) {
 		ReadTimeout:  viper.GetDuration("SERVER_READ_TIMEOUT"),
 		WriteTimeout: viper.GetDuration("SERVER_WRITE_TIMEOUT"),
 		IdleTimeout:  viper.GetDuration("SERVER_IDLE_TIMEOUT"),
+		MaxBodySize:  viper.GetInt64("SERVER_MAX_BODY_SIZE"),
 	}
 
 	// Database configuration
 	cfg.Database = DatabaseConfig{
-		Host:     viper.GetString("DB_HOST"),
-		Port:     viper.GetString("DB_PORT"),
-		User:     viper.GetString("DB_USER"),
-		Password: viper.GetString("DB_PASSWORD"),
-		DBName:   viper.GetString("DB_NAME"),
-		SSLMode:  viper.GetString("DB_SSLMODE"),
+		Host:             viper.GetString("DB_HOST"),
+		Port:             viper.GetString("DB_PORT"),
+		User:             viper.GetString("DB_USER"),
+		Password:         viper.GetString("DB_PASSWORD"),
+		DBName:           viper.GetString("DB_NAME"),
+		SSLMode:          viper.GetString("DB_SSLMODE"),
+		SeedDemoAccounts: viper.GetBool("DB_SEED_DEMO_ACCOUNTS"),
+	}
+
+	// Transfer execution configuration
+	cfg.Transfer = TransferConfig{
+		Workers:          viper.GetInt("TRANSFER_WORKERS"),
+		PollInterval:     viper.GetDuration("TRANSFER_POLL_INTERVAL"),
+		Lease:            viper.GetDuration("TRANSFER_LEASE"),
+		LegacyAccountIDs: viper.GetBool("TRANSFER_LEGACY_ACCOUNT_IDS"),
+	}
+
+	// Outbox relay configuration
+	cfg.Outbox = OutboxConfig{
+		Publisher:    viper.GetString("OUTBOX_PUBLISHER"),
+		FilePath:     viper.GetString("OUTBOX_FILE_PATH"),
+		NATSURL:      viper.GetString("OUTBOX_NATS_URL"),
+		NATSSubject:  viper.GetString("OUTBOX_NATS_SUBJECT"),
+		BatchSize:    viper.GetInt("OUTBOX_BATCH_SIZE"),
+		PollInterval: viper.GetDuration("OUTBOX_POLL_INTERVAL"),
+	}
+
+	// Webhook delivery configuration
+	cfg.Webhook = WebhookConfig{
+		Workers:      viper.GetInt("WEBHOOK_WORKERS"),
+		PollInterval: viper.GetDuration("WEBHOOK_POLL_INTERVAL"),
+		Timeout:      viper.GetDuration("WEBHOOK_TIMEOUT"),
+		MaxAttempts:  viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+		BackoffBase:  viper.GetDuration("WEBHOOK_BACKOFF_BASE"),
+		BackoffMax:   viper.GetDuration("WEBHOOK_BACKOFF_MAX"),
+	}
+
+	// Authentication configuration
+	cfg.Auth = AuthConfig{
+		APIKeys: viper.GetString("AUTH_API_KEYS"),
+	}
+
+	// Event stream configuration
+	cfg.Stream = StreamConfig{
+		PollInterval:      viper.GetDuration("STREAM_POLL_INTERVAL"),
+		HeartbeatInterval: viper.GetDuration("STREAM_HEARTBEAT_INTERVAL"),
+	}
+
+	// WebSocket API configuration
+	cfg.WebSocket = WebSocketConfig{
+		PingInterval:  viper.GetDuration("WS_PING_INTERVAL"),
+		PongTimeout:   viper.GetDuration("WS_PONG_TIMEOUT"),
+		SendQueueSize: viper.GetInt("WS_SEND_QUEUE_SIZE"),
+		MaxInFlight:   viper.GetInt("WS_MAX_IN_FLIGHT"),
+	}
+
+	// gRPC server configuration
+	cfg.GRPC = GRPCConfig{
+		Port: viper.GetString("GRPC_PORT"),
+	}
+
+	// GraphQL query limits
+	cfg.GraphQL = GraphQLConfig{
+		MaxDepth:      viper.GetInt("GRAPHQL_MAX_DEPTH"),
+		MaxComplexity: viper.GetInt("GRAPHQL_MAX_COMPLEXITY"),
+	}
+
+	// Logging configuration
+	cfg.Log = LogConfig{
+		Level:  viper.GetString("LOG_LEVEL"),
+		Format: viper.GetString("LOG_FORMAT"),
+	}
+
+	// Tracing configuration
+	cfg.Tracing = TracingConfig{
+		Exporter:     viper.GetString("TRACING_EXPORTER"),
+		FilePath:     viper.GetString("TRACING_FILE_PATH"),
+		OTLPEndpoint: viper.GetString("TRACING_OTLP_ENDPOINT"),
+		SampleRatio:  viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+	}
+
+	// Health check configuration
+	cfg.Health = HealthConfig{
+		CheckTimeout: viper.GetDuration("HEALTH_CHECK_TIMEOUT"),
+	}
+
+	// Graceful shutdown configuration
+	cfg.Shutdown = ShutdownConfig{
+		Timeout:    viper.GetDuration("SHUTDOWN_TIMEOUT"),
+		DrainDelay: viper.GetDuration("SHUTDOWN_DRAIN_DELAY"),
+	}
+
+	// Rate limiting configuration
+	cfg.RateLimit = RateLimitConfig{
+		Backend:       viper.GetString("RATE_LIMIT_BACKEND"),
+		Rules:         viper.GetString("RATE_LIMIT_RULES"),
+		SweepInterval: viper.GetDuration("RATE_LIMIT_SWEEP_INTERVAL"),
+	}
+
+	// Audit log configuration
+	cfg.Audit = AuditConfig{
+		SealBatchSize: viper.GetInt("AUDIT_SEAL_BATCH_SIZE"),
+		SealInterval:  viper.GetDuration("AUDIT_SEAL_INTERVAL"),
+	}
+
+	// Reconciliation configuration
+	cfg.Reconcile = ReconcileConfig{
+		Time:      viper.GetString("RECONCILE_TIME"),
+		ReportDir: viper.GetString("RECONCILE_REPORT_DIR"),
+	}
+
+	// Payout configuration
+	cfg.Payout = PayoutConfig{
+		Rail:              viper.GetString("PAYOUT_RAIL"),
+		ClearingAccount:   viper.GetString("PAYOUT_CLEARING_ACCOUNT"),
+		SettlementAccount: viper.GetString("PAYOUT_SETTLEMENT_ACCOUNT"),
+		SimulatorMode:     viper.GetString("RAIL_SIMULATOR_MODE"),
+		SimulatorDelay:    viper.GetDuration("RAIL_SIMULATOR_DELAY"),
+
+		ACHDestination:      viper.GetString("RAIL_ACH_DESTINATION"),
+		ACHDestinationName:  viper.GetString("RAIL_ACH_DESTINATION_NAME"),
+		ACHOrigin:           viper.GetString("RAIL_ACH_ORIGIN"),
+		ACHOriginName:       viper.GetString("RAIL_ACH_ORIGIN_NAME"),
+		ACHCompanyName:      viper.GetString("RAIL_ACH_COMPANY_NAME"),
+		ACHCompanyID:        viper.GetString("RAIL_ACH_COMPANY_ID"),
+		ACHODFI:             viper.GetString("RAIL_ACH_ODFI"),
+		ACHEntryDescription: viper.GetString("RAIL_ACH_ENTRY_DESCRIPTION"),
+	}
+
+	// Funding configuration
+	cfg.Funding = FundingConfig{
+		SystemAccounts: splitList(viper.GetString("FUNDING_SYSTEM_ACCOUNTS")),
+	}
+
+	// Screening configuration
+	cfg.Screening = ScreeningConfig{
+		ListPath:       viper.GetString("SCREENING_LIST_PATH"),
+		HoldScore:      viper.GetFloat64("SCREENING_HOLD_SCORE"),
+		BlockScore:     viper.GetFloat64("SCREENING_BLOCK_SCORE"),
+		ReloadInterval: viper.GetDuration("SCREENING_RELOAD_INTERVAL"),
 	}
 
 	return &cfg, nil
 }
 
+// splitList splits a comma-separated list, dropping blank entries
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // GetDSN returns database connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
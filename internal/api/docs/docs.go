@@ -18,9 +18,283 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/accounts/{id}/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Streams balance changes and incoming/outgoing transfers of an account as Server-Sent Events.\nEach event carries the activity sequence as its id and the event type as its name.\nReconnecting clients send Last-Event-ID to resume after the last event they received;\nwithout it the stream starts with new activity only.\nBrowser EventSource clients may pass the API key as the access_token query parameter.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Stream account activity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence of the last event received",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of activities",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountActivity"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed account ID or Last-Event-ID",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account belongs to another principal",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{id}/statements": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the opening balance, every posting with its running balance and the closing balance\nof an account over a period of up to 366 days. from and to are RFC 3339 times or calendar\ndays in UTC; a day given as to is included in full. The period defaults to the current month\nup to now. Every running balance is checked against the ledger, so the closing balance of a\nperiod ending now is the current balance. camt053 exports an ISO 20022 camt.053.001.08\nstatement.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/pdf",
+                    "application/xml"
+                ],
+                "tags": [
+                    "accounts"
+                ],
+                "summary": "Get account statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, inclusive (2024-01-01 or 2024-01-01T00:00:00Z)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period (2024-01-31 for the whole day, or an exclusive RFC 3339 time)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "pdf",
+                            "camt053"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Statement",
+                        "schema": {
+                            "$ref": "#/definitions/models.Statement"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed account ID, period or format",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account belongs to another principal",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the audit log of every change made to transfers and balances, oldest first.\nEach event records the actor, the action, the state before and after, the request ID and\nthe source IP. Sealed events carry their position in the hash chain and their hash.\nPages are requested by passing the next_after of the previous page as after.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "List audit events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Principal that made the change, anonymous or system",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "transfer.created",
+                            "transfer.status_changed",
+                            "account.debited",
+                            "account.credited",
+                            "transfer.screened",
+                            "transfer.reviewed"
+                        ],
+                        "type": "string",
+                        "description": "Kind of change",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Account involved in the change",
+                        "name": "account",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Transfer the change belongs to",
+                        "name": "transfer",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events that occurred at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Events that occurred before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Sequence of the last event of the previous page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit events",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an auditor or administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
         "/balance/{account}": {
             "get": {
-                "description": "Returns the current balance of the specified account",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the current balance of the specified account, or with as_of the balance it had\nat that time, after every posting made up to and including it. An account created after\nas_of had a balance of zero. The caller must be allowed to access the account.",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,42 +312,127 @@ const docTemplate = `{
                         "name": "account",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to return the balance at",
+                        "name": "as_of",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful response with balance",
+                        "description": "Successful response with balance, and as_of when given",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "number"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed account ID or as_of, or as_of in the future",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account owned by another principal",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
                         "description": "Account not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
         },
-        "/transfer": {
+        "/balances": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the balances of up to 100 accounts at the same point in time, by default now, for\nreporting such as month-end closing. Unknown accounts are left out of the result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "balance"
+                ],
+                "summary": "Get balances of many accounts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated account IDs",
+                        "name": "accounts",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 time to return the balances at (default now)",
+                        "name": "as_of",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Balances",
+                        "schema": {
+                            "$ref": "#/definitions/models.BalanceSnapshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed account IDs or as_of, or as_of in the future",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an auditor or administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/deposits": {
             "post": {
-                "description": "Transfers specified amount from one account to another",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Credits a customer account with funds received from outside the ledger, taken from a system\naccount such as cash, the default, suspense or settlement. Deposits are deduplicated by their\nreference: repeating a deposit returns the one made before with status 200. A deposit held\nfor sanctions review is answered with 202 and its transfer, and recorded once repeated after\nthe transfer was released.",
                 "consumes": [
                     "application/json"
                 ],
@@ -81,66 +440,2357 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "transfer"
+                    "funding"
                 ],
-                "summary": "Execute money transfer between accounts",
+                "summary": "Deposit funds",
                 "parameters": [
                     {
-                        "description": "Transfer details",
+                        "description": "Deposit details",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.TransferRequest"
+                            "$ref": "#/definitions/models.FundingRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Successful transfer",
+                        "description": "Deposit made before with this reference",
                         "schema": {
-                            "$ref": "#/definitions/models.TransferResponse"
+                            "$ref": "#/definitions/models.Funding"
+                        }
+                    },
+                    "201": {
+                        "description": "Deposit made",
+                        "schema": {
+                            "$ref": "#/definitions/models.Funding"
+                        }
+                    },
+                    "202": {
+                        "description": "Deposit held for sanctions review, not yet recorded",
+                        "schema": {
+                            "$ref": "#/definitions/models.Funding"
                         }
                     },
                     "400": {
-                        "description": "Validation error",
+                        "description": "Validation error or invalid funding account",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not a rail or an administrator, or blocked by sanctions screening",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "404": {
                         "description": "Account not found",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Reference already used with other details",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/problem.Problem"
                         }
                     }
                 }
             }
-        }
-    },
+        },
+        "/graphql": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queries accounts and transfers, or creates a transfer with the transfer mutation.\nQuery { account(id) { id balance transfers(limit) {...} } transfer(id) {...} }\nand Mutation { transfer(from, to, amount, async) {...} } are available.\nQueries nested too deeply or resolving too many fields are rejected before execution.\nErrors carry a code in extensions.code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "Execute a GraphQL query or mutation",
+                "parameters": [
+                    {
+                        "description": "GraphQL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/graphqlapi.Request"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Execution result, possibly with field errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid, malformed or too complex query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/payment-initiations": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reads an ISO 20022 pain.001 credit transfer initiation of any version, executes each of its\ntransactions as a transfer from the debtor account of its payment instruction and returns a\npain.002.001.11 status report. Accounts are identified by Othr/Id; the caller must own every\ndebtor account. A message or instruction whose NbOfTxs or CtrlSum does not match its\ntransactions is rejected as a whole, otherwise each transaction is reported on its own:\nACSC with the transfer ID without hyphens as AcctSvcrRef, or RJCT with a reason code.\nA message sent again with the same MsgId is answered with its first report without executing\nanything; a transaction whose debtor account and EndToEndId were executed before is reported as\nit was then, or rejected with AM05 while it still executes.",
+                "consumes": [
+                    "application/xml"
+                ],
+                "produces": [
+                    "application/xml"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Execute a pain.001 payment batch",
+                "parameters": [
+                    {
+                        "description": "pain.001 message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "pain.002 status report",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed message or more than 1000 transactions",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "MsgId already used for a message with other contents",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Moves the amount from an account of the caller into the clearing account and submits the\npayout to a payment rail, the default rail when none is named. The payout is returned\nsubmitted, or failed with its transfer reversed when the rail refuses it. The rail reports\nlater whether the payout settled or was returned, in which case the transfer is reversed.\nA payout whose counterparty resembles a sanctioned party is returned held until its transfer\nis reviewed; it is then submitted, or failed when the transfer is rejected.\nA request repeating the Idempotency-Key of an earlier payout request of the caller gets\nthe payout of that request as it is now instead of paying out again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Pay out to an external account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key making retries of the request pay out once, up to 128 characters",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Payout details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PayoutRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Submitted, held or failed payout",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payout"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error, insufficient funds or unknown rail",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account belongs to another principal or payout blocked by sanctions screening",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/payouts/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the current state of a payout from an account of the caller",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Get payout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Payout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payout",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payout"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Account belongs to another principal",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Payout not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rails/ach/files": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Puts the ACH payouts submitted since the previous file, at most 10000, in a new NACHA file to\nsend to the ACH partner. Each payout is a credit entry whose trace number is its rail reference.\nThe file is downloaded from the files endpoint.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ach"
+                ],
+                "summary": "Create an ACH file",
+                "responses": {
+                    "201": {
+                        "description": "File created",
+                        "schema": {
+                            "$ref": "#/definitions/models.ACHFile"
+                        }
+                    },
+                    "204": {
+                        "description": "No payout is waiting for a file"
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the ACH rail or an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rails/ach/files/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns an ACH file as NACHA records of 94 characters, the same each time it is downloaded",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "ach"
+                ],
+                "summary": "Download an ACH file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "NACHA file",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the ACH rail or an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rails/ach/files/{id}/settlement": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Marks the payouts of an ACH file that were not returned as settled, once the ACH partner\nconfirmed its settlement; their funds move from the clearing to the settlement account. Returns\narriving later move the funds back from the settlement account.\nSettling a file again settles only the payouts not settled yet.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ach"
+                ],
+                "summary": "Settle an ACH file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Payouts settled",
+                        "schema": {
+                            "$ref": "#/definitions/models.ACHSettlement"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the ACH rail or an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rails/ach/returns": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Reads a NACHA return file from the ACH partner and returns each payout it sends back, matched by\nthe original trace number, with the reason code and description as failure reason; the transfers\nof returned payouts are reversed. Every return is listed, with an error when it could not be\napplied, such as for an unknown trace number. Processing a file again is harmless.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ach"
+                ],
+                "summary": "Process an ACH return file",
+                "parameters": [
+                    {
+                        "description": "NACHA return file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Returns read from the file",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ACHReturn"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed file",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not the ACH rail or an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/rails/{rail}/callbacks": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Called by a payment rail when a payout it accepted settled or was returned. A rail may only\nreport on its own payouts: the ID of a rail principal must be the name of the rail.\nReporting the outcome a payout already has again returns it unchanged, so callbacks may be retried.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payouts"
+                ],
+                "summary": "Report the outcome of a payout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Rail name",
+                        "name": "rail",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Outcome",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RailCallback"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settled or returned payout",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payout"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error or unknown rail",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not this rail or an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "No payout has the reference",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Payout already has another outcome",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/screening/decisions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns how the parties of transfers were screened against the sanctions list, newest first.\nEach decision records the closest match, its score from 0 to 1 and the version of the list.\nPass unreviewed=true for the held transfers awaiting review.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "screening"
+                ],
+                "summary": "List screening decisions",
+                "parameters": [
+                    {
+                        "enum": [
+                            "clear",
+                            "hold",
+                            "block"
+                        ],
+                        "type": "string",
+                        "description": "Outcome of the screening",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only held transfers nobody reviewed yet",
+                        "name": "unreviewed",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of decisions (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Screening decisions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ScreeningDecision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an auditor or administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/screening/decisions/{id}/review": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Resolves a transfer held by sanctions screening. A released transfer is executed right away\nand returned completed, or failed when its funds cannot move; a rejected transfer is failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "screening"
+                ],
+                "summary": "Review a held transfer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Screening decision ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resolution",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScreeningReview"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transfer after the review",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Screening decision not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Decision held no transfer or was already reviewed",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Transfers specified amount from one account to another.\nWith async=true the transfer is queued and 202 is returned with its ID for polling.\nA transfer whose parties resemble a sanctioned party is held for review and also answered\nwith 202 and status held; one matching a sanctioned party is refused with transfer_blocked.\nThe caller must be allowed to access the source account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Execute money transfer between accounts",
+                "parameters": [
+                    {
+                        "description": "Transfer details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Queue the transfer instead of waiting for it",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Successful transfer",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Transfer queued or held for sanctions review",
+                        "schema": {
+                            "$ref": "#/definitions/models.TransferResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Source account owned by another principal, or transfer blocked by sanctions screening",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/transfers/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the transfer and its lifecycle status.\nWith wait set, the request is held until the transfer is final or the wait elapses (max 5s).\nThe caller must be allowed to access the source or the destination account.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transfer"
+                ],
+                "summary": "Get transfer status",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transfer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "How long to wait for a final status, e.g. 2s",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transfer details",
+                        "schema": {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid wait duration",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Transfer between accounts of other principals",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Transfer not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns all webhook subscriptions without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook subscriptions",
+                "responses": {
+                    "200": {
+                        "description": "Subscriptions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookSubscription"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Registers an endpoint that receives signed POSTs for the given event types (all when empty).\nThe signing secret is generated when omitted and is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook subscription",
+                "parameters": [
+                    {
+                        "description": "Subscription details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscriptionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created subscription, including its secret",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns a webhook subscription without its secret",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Subscription",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookSubscription"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Removes a webhook subscription together with its pending deliveries",
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook subscription",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Subscription deleted"
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Returns the most recent deliveries of a subscription with their attempt history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries (default 50, max 500)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deliveries, newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid limit",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Subscription not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}/deliveries/{delivery}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Queues a delivery for an immediate new attempt, including dead-lettered deliveries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Redeliver webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Subscription ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Delivery ID",
+                        "name": "delivery",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Delivery queued",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Delivery not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/withdrawals": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Debits a customer account for funds paid out of the ledger, moving them to a system account\nsuch as cash, the default, suspense or settlement. Withdrawals are deduplicated by their\nreference: repeating a withdrawal returns the one made before with status 200. A withdrawal\nheld for sanctions review is answered with 202 and its transfer, and recorded once repeated\nafter the transfer was released.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "funding"
+                ],
+                "summary": "Withdraw funds",
+                "parameters": [
+                    {
+                        "description": "Withdrawal details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.FundingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Withdrawal made before with this reference",
+                        "schema": {
+                            "$ref": "#/definitions/models.Funding"
+                        }
+                    },
+                    "201": {
+                        "description": "Withdrawal made",
+                        "schema": {
+                            "$ref": "#/definitions/models.Funding"
+                        }
+                    },
+                    "202": {
+                        "description": "Withdrawal held for sanctions review, not yet recorded",
+                        "schema": {
+                            "$ref": "#/definitions/models.Funding"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error, insufficient funds or invalid funding account",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Caller is not a rail or an administrator, or blocked by sanctions screening",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Reference already used with other details",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "413": {
+                        "description": "Request body too large",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/ws": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Upgrades to a WebSocket carrying JSON-RPC 2.0 messages.\nMethods: transfer, getBalance, subscribe and unsubscribe.\nSubscribed accounts push \"activity\" notifications; see the README for the message formats.\nThe API key is checked once, during the handshake.",
+                "tags": [
+                    "websocket"
+                ],
+                "summary": "Open a WebSocket connection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key for clients that cannot set headers",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching protocols"
+                    },
+                    "400": {
+                        "description": "Not a WebSocket handshake",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/problem.Problem"
+                        }
+                    }
+                }
+            }
+        }
+    },
     "definitions": {
-        "models.TransferRequest": {
+        "graphqlapi.Request": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "extensions": {
+                    "description": "accepted for client compatibility, unused",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "models.ACHFile": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Sum of the amounts of the payouts carried",
+                    "type": "number"
+                },
+                "created_at": {
+                    "description": "When the file was created",
+                    "type": "string"
+                },
+                "entries": {
+                    "description": "Number of payouts carried",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "Unique file identifier",
+                    "type": "string"
+                },
+                "id_modifier": {
+                    "description": "Tells apart files created on the same day, A to Z then 0 to 9",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ACHReturn": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Return reason code, such as R01",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Why the return could not be applied",
+                    "type": "string"
+                },
+                "reason": {
+                    "description": "Description of the return reason",
+                    "type": "string"
+                },
+                "trace_number": {
+                    "description": "Trace number of the entry returned, the rail reference of its payout",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ACHSettlement": {
+            "type": "object",
+            "properties": {
+                "file_id": {
+                    "description": "File settled",
+                    "type": "string"
+                },
+                "settled": {
+                    "description": "Number of payouts marked settled; returned payouts stay returned",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AccountActivity": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Account the activity belongs to",
+                    "type": "string"
+                },
+                "balance": {
+                    "description": "Balance after the event, set when it changed",
+                    "type": "number"
+                },
+                "direction": {
+                    "description": "Set for transfer events",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ActivityDirection"
+                        }
+                    ]
+                },
+                "occurred_at": {
+                    "description": "When the change was committed",
+                    "type": "string"
+                },
+                "sequence": {
+                    "description": "Position in the event log, usable as Last-Event-ID",
+                    "type": "integer"
+                },
+                "transfer": {
+                    "description": "Set for transfer events",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Transfer"
+                        }
+                    ]
+                },
+                "type": {
+                    "description": "Kind of event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.EventType"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.AccountBalance": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Account the balance is of",
+                    "type": "string"
+                },
+                "balance": {
+                    "description": "Balance after the last posting made at or before the time",
+                    "type": "number"
+                }
+            }
+        },
+        "models.ActivityDirection": {
+            "type": "string",
+            "enum": [
+                "incoming",
+                "outgoing"
+            ],
+            "x-enum-varnames": [
+                "DirectionIncoming",
+                "DirectionOutgoing"
+            ]
+        },
+        "models.AuditAction": {
+            "type": "string",
+            "enum": [
+                "transfer.created",
+                "transfer.status_changed",
+                "account.debited",
+                "account.credited",
+                "transfer.screened",
+                "transfer.reviewed"
+            ],
+            "x-enum-varnames": [
+                "AuditTransferCreated",
+                "AuditTransferStatusChanged",
+                "AuditAccountDebited",
+                "AuditAccountCredited",
+                "AuditTransferScreened",
+                "AuditTransferReviewed"
+            ]
+        },
+        "models.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "account_ids": {
+                    "description": "Accounts involved in the change",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "action": {
+                    "description": "Kind of change",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.AuditAction"
+                        }
+                    ]
+                },
+                "actor": {
+                    "description": "Principal, anonymous or system",
+                    "type": "string"
+                },
+                "after": {
+                    "description": "State after the change",
+                    "type": "object"
+                },
+                "before": {
+                    "description": "State before the change, null on creation",
+                    "type": "object"
+                },
+                "chain_index": {
+                    "description": "Position in the hash chain, nil until sealed",
+                    "type": "integer"
+                },
+                "hash": {
+                    "description": "Hash of this event, empty until sealed",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique event identifier",
+                    "type": "string"
+                },
+                "occurred_at": {
+                    "description": "When the change was committed",
+                    "type": "string"
+                },
+                "prev_hash": {
+                    "description": "Hash of the previous event in the chain",
+                    "type": "string"
+                },
+                "request_id": {
+                    "description": "Request that made the change",
+                    "type": "string"
+                },
+                "sequence": {
+                    "description": "Order in which events were recorded",
+                    "type": "integer"
+                },
+                "source_ip": {
+                    "description": "Client address of that request",
+                    "type": "string"
+                },
+                "target_id": {
+                    "description": "Identifier of that entity",
+                    "type": "string"
+                },
+                "target_type": {
+                    "description": "Kind of entity changed: transfer or account",
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "description": "Transfer the change belongs to",
+                    "type": "string"
+                }
+            }
+        },
+        "models.AuditPage": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AuditEvent"
+                    }
+                },
+                "next_after": {
+                    "description": "NextAfter is the after parameter of the next page; zero when this page is the last",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BalanceSnapshot": {
+            "type": "object",
+            "properties": {
+                "as_of": {
+                    "description": "Point in time the balances are at",
+                    "type": "string"
+                },
+                "balances": {
+                    "description": "Balances of the known accounts requested, by account ID",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccountBalance"
+                    }
+                }
+            }
+        },
+        "models.EventType": {
+            "type": "string",
+            "enum": [
+                "TransferCreated",
+                "TransferHeld",
+                "TransferPending",
+                "TransferProcessing",
+                "TransferCompleted",
+                "TransferFailed",
+                "TransferReversed",
+                "AccountCreated"
+            ],
+            "x-enum-varnames": [
+                "EventTransferCreated",
+                "EventTransferHeld",
+                "EventTransferPending",
+                "EventTransferProcessing",
+                "EventTransferCompleted",
+                "EventTransferFailed",
+                "EventTransferReversed",
+                "EventAccountCreated"
+            ]
+        },
+        "models.ExternalAccount": {
+            "type": "object",
+            "required": [
+                "identifier",
+                "scheme"
+            ],
+            "properties": {
+                "identifier": {
+                    "description": "Account identifier",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "Name of the account holder",
+                    "type": "string",
+                    "maxLength": 140
+                },
+                "scheme": {
+                    "description": "Scheme of the identifier",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.IdentifierScheme"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Funding": {
+            "type": "object",
+            "properties": {
+                "account": {
+                    "description": "Customer account credited or debited",
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "Amount moved",
+                    "type": "number"
+                },
+                "created_at": {
+                    "description": "When the funds moved",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique funding identifier",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Deposit or withdrawal",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.FundingKind"
+                        }
+                    ]
+                },
+                "reference": {
+                    "description": "External reference, unique per kind",
+                    "type": "string"
+                },
+                "system_account": {
+                    "description": "System account on the other side",
+                    "type": "string"
+                },
+                "transfer_id": {
+                    "description": "Transfer that moved the funds",
+                    "type": "string"
+                }
+            }
+        },
+        "models.FundingKind": {
+            "type": "string",
+            "enum": [
+                "deposit",
+                "withdrawal"
+            ],
+            "x-enum-varnames": [
+                "FundingDeposit",
+                "FundingWithdrawal"
+            ]
+        },
+        "models.FundingRequest": {
+            "type": "object",
+            "required": [
+                "account",
+                "amount",
+                "reference"
+            ],
+            "properties": {
+                "account": {
+                    "description": "Customer account",
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "Amount to move",
+                    "type": "number",
+                    "maximum": 1000000
+                },
+                "currency": {
+                    "description": "Currency of the amount",
+                    "type": "string"
+                },
+                "reference": {
+                    "description": "External reference",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "system_account": {
+                    "description": "Defaults to the cash account",
+                    "type": "string"
+                }
+            }
+        },
+        "models.IdentifierScheme": {
+            "type": "string",
+            "enum": [
+                "internal",
+                "legacy",
+                "iban",
+                "bban",
+                "sort_code",
+                "aba"
+            ],
+            "x-enum-varnames": [
+                "SchemeInternal",
+                "SchemeLegacy",
+                "SchemeIBAN",
+                "SchemeBBAN",
+                "SchemeSortCode",
+                "SchemeABA"
+            ]
+        },
+        "models.Payout": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount paid out",
+                    "type": "number"
+                },
+                "counterparty": {
+                    "description": "External account paid to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ExternalAccount"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "When the payout was made",
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "description": "Why the rail returned or refused it",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Account paid out from",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique payout identifier",
+                    "type": "string"
+                },
+                "rail": {
+                    "description": "Payment rail carrying the payout",
+                    "type": "string"
+                },
+                "rail_reference": {
+                    "description": "Identifier the rail assigned",
+                    "type": "string"
+                },
+                "settlement_transfer_id": {
+                    "description": "Transfer into the settlement account",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Current lifecycle stage",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PayoutStatus"
+                        }
+                    ]
+                },
+                "transfer_id": {
+                    "description": "Transfer into the clearing account",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "When the status last changed",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PayoutRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount to pay out",
+                    "type": "number",
+                    "maximum": 1000000
+                },
+                "counterparty": {
+                    "description": "External account to pay to",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ExternalAccount"
+                        }
+                    ]
+                },
+                "currency": {
+                    "description": "Currency of the amount",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Account to pay out from",
+                    "type": "string"
+                },
+                "rail": {
+                    "description": "Payment rail, defaults to the default rail",
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "models.PayoutStatus": {
+            "type": "string",
+            "enum": [
+                "held",
+                "pending",
+                "submitted",
+                "settled",
+                "returned",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "PayoutStatusHeld",
+                "PayoutStatusPending",
+                "PayoutStatusSubmitted",
+                "PayoutStatusSettled",
+                "PayoutStatusReturned",
+                "PayoutStatusFailed"
+            ]
+        },
+        "models.PostingKind": {
+            "type": "string",
+            "enum": [
+                "opening",
+                "adjustment",
+                "transfer"
+            ],
+            "x-enum-varnames": [
+                "PostingOpening",
+                "PostingAdjustment",
+                "PostingTransfer"
+            ]
+        },
+        "models.RailCallback": {
+            "type": "object",
+            "required": [
+                "reference",
+                "status"
+            ],
+            "properties": {
+                "reason": {
+                    "description": "Why the payout was returned",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "reference": {
+                    "description": "Identifier the rail assigned",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "status": {
+                    "description": "Outcome of the payout",
+                    "enum": [
+                        "settled",
+                        "returned"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PayoutStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.ScreeningDecision": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "When the transfer was screened",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique decision identifier",
+                    "type": "string"
+                },
+                "list_version": {
+                    "description": "Version of the list screened against",
+                    "type": "string"
+                },
+                "match_id": {
+                    "description": "Entry number of the listed party matched",
+                    "type": "string"
+                },
+                "match_name": {
+                    "description": "Name of the listed party matched",
+                    "type": "string"
+                },
+                "match_program": {
+                    "description": "Sanctions program that lists it",
+                    "type": "string"
+                },
+                "outcome": {
+                    "description": "Clear, hold or block",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScreeningOutcome"
+                        }
+                    ]
+                },
+                "party": {
+                    "description": "Party name of the closest match",
+                    "type": "string"
+                },
+                "resolution": {
+                    "description": "How a held transfer was resolved",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScreeningResolution"
+                        }
+                    ]
+                },
+                "review_note": {
+                    "description": "Why it was resolved that way",
+                    "type": "string"
+                },
+                "reviewed_at": {
+                    "description": "When it was resolved",
+                    "type": "string"
+                },
+                "reviewed_by": {
+                    "description": "Principal who resolved it",
+                    "type": "string"
+                },
+                "score": {
+                    "description": "Score of the closest match",
+                    "type": "number"
+                },
+                "transfer_id": {
+                    "description": "Transfer screened",
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScreeningOutcome": {
+            "type": "string",
+            "enum": [
+                "clear",
+                "hold",
+                "block"
+            ],
+            "x-enum-varnames": [
+                "ScreeningClear",
+                "ScreeningHold",
+                "ScreeningBlock"
+            ]
+        },
+        "models.ScreeningResolution": {
+            "type": "string",
+            "enum": [
+                "released",
+                "rejected"
+            ],
+            "x-enum-varnames": [
+                "ScreeningReleased",
+                "ScreeningRejected"
+            ]
+        },
+        "models.ScreeningReview": {
+            "type": "object",
+            "required": [
+                "resolution"
+            ],
+            "properties": {
+                "note": {
+                    "description": "Why",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "resolution": {
+                    "description": "Release or reject",
+                    "enum": [
+                        "released",
+                        "rejected"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ScreeningResolution"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.Statement": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "Account the statement is for",
+                    "type": "string"
+                },
+                "closing_balance": {
+                    "description": "Balance at the end of the period",
+                    "type": "number"
+                },
+                "currency": {
+                    "description": "ISO 4217 code of all amounts",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Start of the period, inclusive",
+                    "type": "string"
+                },
+                "generated_at": {
+                    "description": "When the statement was produced",
+                    "type": "string"
+                },
+                "lines": {
+                    "description": "Postings of the period, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.StatementLine"
+                    }
+                },
+                "opening_balance": {
+                    "description": "Balance at the start of the period",
+                    "type": "number"
+                },
+                "to": {
+                    "description": "End of the period, exclusive",
+                    "type": "string"
+                },
+                "total_credits": {
+                    "description": "Sum of the money received in the period",
+                    "type": "number"
+                },
+                "total_debits": {
+                    "description": "Sum of the money sent in the period, as a positive amount",
+                    "type": "number"
+                }
+            }
+        },
+        "models.StatementLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Credit when positive, debit when negative",
+                    "type": "number"
+                },
+                "balance": {
+                    "description": "Running balance after the line",
+                    "type": "number"
+                },
+                "counterparty": {
+                    "description": "Other account of the transfer",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "Human readable summary",
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Why the balance changed",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.PostingKind"
+                        }
+                    ]
+                },
+                "posted_at": {
+                    "description": "When the balance changed",
+                    "type": "string"
+                },
+                "posting_id": {
+                    "description": "Ledger posting the line shows",
+                    "type": "integer"
+                },
+                "transfer_id": {
+                    "description": "Transfer posted, for transfer lines",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Transfer": {
             "type": "object",
             "properties": {
                 "amount": {
                     "description": "Amount to transfer",
                     "type": "number"
                 },
+                "counterparty": {
+                    "description": "Counterparty is the account outside this ledger the funds are destined for, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ExternalAccount"
+                        }
+                    ]
+                },
+                "created_at": {
+                    "description": "When the transfer was created",
+                    "type": "string"
+                },
+                "failure_reason": {
+                    "description": "Why the transfer failed, if it did",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "Source account ID",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Unique transfer identifier",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Current lifecycle stage",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransferStatus"
+                        }
+                    ]
+                },
+                "to": {
+                    "description": "Destination account ID",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "When the status last changed",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransferRequest": {
+            "type": "object",
+            "required": [
+                "amount",
+                "from",
+                "to"
+            ],
+            "properties": {
+                "amount": {
+                    "description": "Amount to transfer",
+                    "type": "number",
+                    "maximum": 1000000
+                },
+                "counterparty": {
+                    "description": "Counterparty is the account outside this ledger the funds are destined for, if any",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ExternalAccount"
+                        }
+                    ]
+                },
+                "currency": {
+                    "description": "Currency of the amount, defaults to the ledger currency",
+                    "type": "string"
+                },
                 "from": {
                     "description": "Source account ID",
                     "type": "string"
@@ -158,12 +2808,244 @@ const docTemplate = `{
                     "description": "Optional error or success message",
                     "type": "string"
                 },
+                "status": {
+                    "description": "Lifecycle stage at the time of the response",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.TransferStatus"
+                        }
+                    ]
+                },
                 "success": {
                     "description": "Indicates if transfer was successful",
                     "type": "boolean"
+                },
+                "transfer_id": {
+                    "description": "Identifier for polling the transfer",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TransferStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "held",
+                "pending",
+                "processing",
+                "completed",
+                "failed",
+                "reversed"
+            ],
+            "x-enum-varnames": [
+                "TransferStatusCreated",
+                "TransferStatusHeld",
+                "TransferStatusPending",
+                "TransferStatusProcessing",
+                "TransferStatusCompleted",
+                "TransferStatusFailed",
+                "TransferStatusReversed"
+            ]
+        },
+        "models.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts made so far",
+                    "type": "integer"
+                },
+                "created_at": {
+                    "description": "When the delivery was queued",
+                    "type": "string"
+                },
+                "delivered_at": {
+                    "description": "When the endpoint acknowledged",
+                    "type": "string"
+                },
+                "event_id": {
+                    "description": "Delivered event, stable across retries",
+                    "type": "string"
+                },
+                "event_type": {
+                    "description": "Type of the delivered event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.EventType"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "Unique delivery identifier",
+                    "type": "string"
+                },
+                "last_error": {
+                    "description": "Why the last attempt failed",
+                    "type": "string"
+                },
+                "last_response_code": {
+                    "description": "HTTP status of the last attempt",
+                    "type": "integer"
+                },
+                "next_attempt_at": {
+                    "description": "When the next attempt is due",
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Current delivery state",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.WebhookDeliveryStatus"
+                        }
+                    ]
+                },
+                "subscription_id": {
+                    "description": "Receiving subscription",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookDeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "delivered",
+                "dead"
+            ],
+            "x-enum-varnames": [
+                "WebhookDeliveryPending",
+                "WebhookDeliveryDelivered",
+                "WebhookDeliveryDead"
+            ]
+        },
+        "models.WebhookSubscription": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "description": "When the subscription was created",
+                    "type": "string"
+                },
+                "event_types": {
+                    "description": "Event types to deliver",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.EventType"
+                    }
+                },
+                "id": {
+                    "description": "Unique subscription identifier",
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "HMAC key, only returned on creation",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "Endpoint deliveries are POSTed to",
+                    "type": "string"
+                }
+            }
+        },
+        "models.WebhookSubscriptionRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "event_types": {
+                    "description": "Event types to deliver, empty for all",
+                    "type": "array",
+                    "maxItems": 32,
+                    "items": {
+                        "$ref": "#/definitions/models.EventType"
+                    }
+                },
+                "secret": {
+                    "description": "HMAC key, generated when omitted",
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 16
+                },
+                "url": {
+                    "description": "Endpoint deliveries are POSTed to",
+                    "type": "string",
+                    "maxLength": 2048
+                }
+            }
+        },
+        "problem.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Rule the value breaks",
+                    "type": "string",
+                    "example": "decimals"
+                },
+                "field": {
+                    "description": "JSON name of the field",
+                    "type": "string",
+                    "example": "amount"
+                },
+                "message": {
+                    "description": "Human readable explanation",
+                    "type": "string",
+                    "example": "must have at most 2 decimal places"
+                }
+            }
+        },
+        "problem.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string",
+                    "example": "account_not_found"
+                },
+                "detail": {
+                    "description": "Explanation of this occurrence",
+                    "type": "string",
+                    "example": "account not found"
+                },
+                "errors": {
+                    "description": "Invalid fields of a validation_failed problem",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/problem.FieldError"
+                    }
+                },
+                "instance": {
+                    "description": "Request path that failed",
+                    "type": "string",
+                    "example": "/api/v1/balance/Bob"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 404
+                },
+                "title": {
+                    "description": "Short summary of the problem type",
+                    "type": "string",
+                    "example": "Account not found"
+                },
+                "trace_id": {
+                    "description": "Trace ID to quote when reporting the error",
+                    "type": "string",
+                    "example": "4bf92f3577b34da6a3ce929d0e0e4736"
+                },
+                "type": {
+                    "description": "URI identifying the problem type",
+                    "type": "string",
+                    "example": "urn:money-transfer:problem:account_not_found"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        }
     }
 }`
 
@@ -174,7 +3056,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/api/v1",
 	Schemes:          []string{"http"},
 	Title:            "Money Transfer API",
-	Description:      "API for money transfers between accounts",
+	Description:      "API for money transfers between accounts.\nErrors are RFC 7807 problem details served as application/problem+json with a stable code.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
@@ -0,0 +1,129 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/ratelimit"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Error codes reported in the extensions.code field of GraphQL errors
+const (
+	CodeParseFailed        = "GRAPHQL_PARSE_FAILED"
+	CodeValidationFailed   = "GRAPHQL_VALIDATION_FAILED"
+	CodeQueryTooComplex    = "QUERY_TOO_COMPLEX"
+	CodeBadUserInput       = "BAD_USER_INPUT"
+	CodeNotFound           = "NOT_FOUND"
+	CodeFailedPrecondition = "FAILED_PRECONDITION"
+	CodeUnauthenticated    = "UNAUTHENTICATED"
+	CodeForbidden          = "FORBIDDEN"
+	CodeRateLimited        = "RATE_LIMITED"
+	CodeInternal           = "INTERNAL_SERVER_ERROR"
+)
+
+// errorCodes maps transfer error sentinels to GraphQL error codes
+var errorCodes = []struct {
+	err  error
+	code string
+}{
+	{transfererrors.ErrAccountNotFound, CodeNotFound},
+	{transfererrors.ErrTransferNotFound, CodeNotFound},
+	{transfererrors.ErrInsufficientFunds, CodeFailedPrecondition},
+	{transfererrors.ErrInvalidStatusTransition, CodeFailedPrecondition},
+	{transfererrors.ErrInvalidAmount, CodeBadUserInput},
+	{transfererrors.ErrSameAccount, CodeBadUserInput},
+	{transfererrors.ErrInvalidAccountIdentifier, CodeBadUserInput},
+	{transfererrors.ErrSystemAccount, CodeForbidden},
+	{transfererrors.ErrTransferBlocked, CodeForbidden},
+	{transfererrors.ErrUnauthenticated, CodeUnauthenticated},
+	{transfererrors.ErrForbidden, CodeForbidden},
+}
+
+// inputError is returned by resolvers for invalid arguments; its message is shown to the client
+type inputError string
+
+func (e inputError) Error() string {
+	return string(e)
+}
+
+// invalidFields describes the fields that failed validation as an input error
+func invalidFields(errs []problem.FieldError) inputError {
+	messages := make([]string, len(errs))
+	for i, fe := range errs {
+		messages[i] = fe.Field + " " + fe.Message
+	}
+	return inputError(strings.Join(messages, "; "))
+}
+
+// withCode returns err formatted for the response with the given error code
+func withCode(err error, code string) gqlerrors.FormattedError {
+	formatted := gqlerrors.FormatError(err)
+	formatted.Extensions = map[string]interface{}{"code": code}
+	return formatted
+}
+
+// formatExecutionErrors assigns codes to errors raised while executing a request
+// Errors without a path concern the request itself, e.g. an unknown operation or
+// invalid variables. Resolver errors keep their message if they are known sentinels
+// or input errors; anything else is logged and reported without details.
+func (e *Executor) formatExecutionErrors(ctx context.Context, errs []gqlerrors.FormattedError) {
+	for i := range errs {
+		if len(errs[i].Path) == 0 {
+			errs[i].Extensions = map[string]interface{}{"code": CodeBadUserInput}
+			continue
+		}
+
+		cause := originalError(errs[i])
+		code, message := resolverErrorCode(cause)
+		if code == CodeInternal {
+			e.logger.ErrorContext(ctx, "graphql resolver failed", "path", errs[i].Path, "error", cause)
+		}
+		errs[i].Message = message
+		errs[i].Extensions = map[string]interface{}{"code": code}
+	}
+}
+
+// resolverErrorCode returns the code and client-facing message for a resolver error
+// Exceeded rate limits keep their message, which tells when to retry.
+func resolverErrorCode(err error) (string, string) {
+	var exceeded *ratelimit.ExceededError
+	if errors.As(err, &exceeded) {
+		return CodeRateLimited, exceeded.Error()
+	}
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			return mapping.code, mapping.err.Error()
+		}
+	}
+
+	var input inputError
+	if errors.As(err, &input) {
+		return CodeBadUserInput, input.Error()
+	}
+	return CodeInternal, "internal server error"
+}
+
+// originalError digs the error returned by a resolver out of the wrappers added by the executor
+func originalError(err error) error {
+	for {
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			if wrapped.OriginalError() == nil {
+				return err
+			}
+			err = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			if wrapped.OriginalError == nil {
+				return err
+			}
+			err = wrapped.OriginalError
+		default:
+			return err
+		}
+	}
+}
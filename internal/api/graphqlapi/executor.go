@@ -0,0 +1,130 @@
+// Package graphqlapi serves GraphQL queries over accounts and transfers
+package graphqlapi
+
+import (
+	"context"
+	"log/slog"
+
+	"money-transfer/internal/logging"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Defaults used when the configuration leaves the query limits unset
+const (
+	DefaultMaxDepth      = 8
+	DefaultMaxComplexity = 1000
+)
+
+// Config holds the dependencies and limits of the executor
+type Config struct {
+	BankService service.BankService
+	// Throttle limits the transfer mutation by caller and source account; nothing is limited when it is nil
+	Throttle *ratelimit.Throttle
+	// Logger receives resolver errors that are not reported to clients; nothing is logged when it is nil
+	Logger *slog.Logger
+	// MaxDepth is how deeply fields may be nested in a query
+	MaxDepth int
+	// MaxComplexity is how many fields a query may resolve at most
+	MaxComplexity int
+}
+
+// Request is a GraphQL request as sent over HTTP
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"` // accepted for client compatibility, unused
+}
+
+// Executor parses, checks and executes GraphQL requests
+type Executor struct {
+	bankService   service.BankService
+	throttle      *ratelimit.Throttle
+	logger        *slog.Logger
+	maxDepth      int
+	maxComplexity int
+}
+
+// NewExecutor creates a new executor
+func NewExecutor(cfg Config) *Executor {
+	e := &Executor{
+		bankService:   cfg.BankService,
+		throttle:      cfg.Throttle,
+		logger:        cfg.Logger,
+		maxDepth:      cfg.MaxDepth,
+		maxComplexity: cfg.MaxComplexity,
+	}
+	if e.logger == nil {
+		e.logger = logging.Discard()
+	}
+	if e.maxDepth <= 0 {
+		e.maxDepth = DefaultMaxDepth
+	}
+	if e.maxComplexity <= 0 {
+		e.maxComplexity = DefaultMaxComplexity
+	}
+	return e
+}
+
+// Execute runs the request for the principal authenticated in ctx
+// Queries that fail to parse or validate, or exceed the depth or complexity
+// limits, are rejected before any resolver runs.
+func (e *Executor) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{withCode(err, CodeParseFailed)}}
+	}
+
+	validation := graphql.ValidateDocument(&schema, doc, nil)
+	if !validation.IsValid {
+		for i := range validation.Errors {
+			validation.Errors[i].Extensions = map[string]interface{}{"code": CodeValidationFailed}
+		}
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	if err := checkLimits(doc, req.Variables, e.maxDepth, e.maxComplexity); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{withCode(err, CodeQueryTooComplex)}}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withState(ctx, e.bankService, e.throttle),
+	})
+	e.formatExecutionErrors(ctx, result.Errors)
+	return result
+}
+
+// requestState holds what resolvers of a single request share
+type requestState struct {
+	bankService service.BankService
+	throttle    *ratelimit.Throttle
+	accounts    *accountLoader
+}
+
+type stateKey struct{}
+
+// withState returns a copy of ctx carrying fresh request state
+func withState(ctx context.Context, bankService service.BankService, throttle *ratelimit.Throttle) context.Context {
+	return context.WithValue(ctx, stateKey{}, &requestState{
+		bankService: bankService,
+		throttle:    throttle,
+		accounts:    newAccountLoader(bankService),
+	})
+}
+
+// stateFrom returns the request state added by Execute
+func stateFrom(ctx context.Context) *requestState {
+	return ctx.Value(stateKey{}).(*requestState)
+}
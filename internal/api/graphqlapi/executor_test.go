@@ -0,0 +1,331 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/service/mocks"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// asPrincipal returns a context authenticated as a customer owning accounts
+func asPrincipal(accounts ...string) context.Context {
+	return auth.WithPrincipal(context.Background(), &models.Principal{
+		ID: "customer", Role: models.RoleCustomer, Accounts: accounts,
+	})
+}
+
+// resultCodes returns the extensions.code of every error in result
+func resultCodes(result *graphql.Result) []string {
+	var codes []string
+	for _, err := range result.Errors {
+		code, _ := err.Extensions["code"].(string)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// resultJSON returns the data of result re-encoded as JSON
+func resultJSON(t *testing.T, result *graphql.Result) string {
+	t.Helper()
+
+	data, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExecutor_BatchesAccountLoads(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetAccounts", mock.Anything, []string{"Mark"}).
+		Return([]*models.Account{{ID: "Mark", Balance: 60}}, nil).Once()
+	mockService.On("ListTransfers", mock.Anything, "Mark", 3).Return([]*models.Transfer{
+		{ID: "t1", From: "Mark", To: "Jane", Amount: 10, Status: models.TransferStatusCompleted},
+		{ID: "t2", From: "Jane", To: "Mark", Amount: 20, Status: models.TransferStatusCompleted},
+		{ID: "t3", From: "Mark", To: "Bob", Amount: 30, Status: models.TransferStatusFailed},
+	}, nil).Once()
+	// Accounts of every transfer are fetched together; Mark is already cached
+	mockService.On("GetAccounts", mock.Anything, []string{"Bob", "Jane"}).
+		Return([]*models.Account{{ID: "Jane", Balance: 120}, {ID: "Bob", Balance: 5}}, nil).Once()
+
+	executor := NewExecutor(Config{BankService: mockService})
+	result := executor.Execute(asPrincipal("Mark"), Request{
+		Query: `{ account(id: "Mark") { id balance transfers(limit: 3) { id status from { id } to { id } } } }`,
+	})
+
+	require.Empty(t, result.Errors)
+	assert.JSONEq(t, `{"account": {"id": "Mark", "balance": 60, "transfers": [
+		{"id": "t1", "status": "COMPLETED", "from": {"id": "Mark"}, "to": {"id": "Jane"}},
+		{"id": "t2", "status": "COMPLETED", "from": {"id": "Jane"}, "to": {"id": "Mark"}},
+		{"id": "t3", "status": "FAILED", "from": {"id": "Mark"}, "to": {"id": "Bob"}}
+	]}}`, resultJSON(t, result))
+	mockService.AssertExpectations(t)
+}
+
+func TestExecutor_TransferToUnknownAccount(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetAccounts", mock.Anything, []string{"Mark"}).
+		Return([]*models.Account{{ID: "Mark", Balance: 60}}, nil).Once()
+	mockService.On("ListTransfers", mock.Anything, "Mark", 2).Return([]*models.Transfer{
+		{ID: "t1", From: "Mark", To: "Ghost", Amount: 10, Status: models.TransferStatusFailed,
+			FailureReason: "account not found"},
+		{ID: "t2", From: "Mark", To: "Jane", Amount: 20, Status: models.TransferStatusCompleted},
+	}, nil).Once()
+	mockService.On("GetAccounts", mock.Anything, []string{"Ghost", "Jane"}).
+		Return([]*models.Account{{ID: "Jane", Balance: 120}}, nil).Once()
+
+	executor := NewExecutor(Config{BankService: mockService})
+	result := executor.Execute(asPrincipal("Mark"), Request{
+		Query: `{ account(id: "Mark") { transfers(limit: 2) { id status from { id } to { id } } } }`,
+	})
+
+	// The failed transfer is listed with no account to credit
+	require.Empty(t, result.Errors)
+	assert.JSONEq(t, `{"account": {"transfers": [
+		{"id": "t1", "status": "FAILED", "from": {"id": "Mark"}, "to": null},
+		{"id": "t2", "status": "COMPLETED", "from": {"id": "Mark"}, "to": {"id": "Jane"}}
+	]}}`, resultJSON(t, result))
+	mockService.AssertExpectations(t)
+}
+
+func TestAccountLoader_ClearBeforeResolve(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetAccounts", mock.Anything, []string{"Mark"}).
+		Return([]*models.Account{{ID: "Mark", Balance: 60}}, nil).Once()
+	mockService.On("GetAccounts", mock.Anything, []string{"Mark"}).
+		Return([]*models.Account{{ID: "Mark", Balance: 10}}, nil).Once()
+
+	loader := newAccountLoader(mockService)
+	ctx := context.Background()
+	_, err := loader.Load(ctx, "Mark")()
+	require.NoError(t, err)
+
+	// Mark is cached when loaded, then cleared by a transfer before the thunk is called
+	thunk := loader.Load(ctx, "Mark")
+	loader.Clear("Mark")
+	account, err := thunk()
+
+	require.NoError(t, err)
+	require.NotNil(t, account)
+	assert.Equal(t, 10.0, account.(*models.Account).Balance)
+	mockService.AssertExpectations(t)
+}
+
+func TestExecutor_Authorization(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		setupMock func(*mocks.BankServiceMock)
+		wantData  string
+		wantCodes []string
+	}{
+		{
+			name:      "account of another principal",
+			query:     `{ account(id: "Jane") { id } }`,
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantData:  `{"account": null}`,
+			wantCodes: []string{CodeForbidden},
+		},
+		{
+			name:  "balance of a counterparty",
+			query: `{ transfer(id: "t1") { amount from { id } to { id balance } } }`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t1").
+					Return(&models.Transfer{ID: "t1", From: "Mark", To: "Jane", Amount: 10}, nil)
+				m.On("GetAccounts", mock.Anything, []string{"Jane", "Mark"}).
+					Return([]*models.Account{{ID: "Mark"}, {ID: "Jane", Balance: 120}}, nil)
+			},
+			wantData:  `{"transfer": {"amount": 10, "from": {"id": "Mark"}, "to": {"id": "Jane", "balance": null}}}`,
+			wantCodes: []string{CodeForbidden},
+		},
+		{
+			name:  "transfer between other principals",
+			query: `{ transfer(id: "t2") { id } }`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t2").
+					Return(&models.Transfer{ID: "t2", From: "Jane", To: "Bob"}, nil)
+			},
+			wantData:  `{"transfer": null}`,
+			wantCodes: []string{CodeForbidden},
+		},
+		{
+			name:  "unknown account",
+			query: `{ account(id: "Mark") { id } }`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetAccounts", mock.Anything, []string{"Mark"}).Return([]*models.Account{}, nil)
+			},
+			wantData:  `{"account": null}`,
+			wantCodes: []string{CodeNotFound},
+		},
+		{
+			name:  "storage failure is not exposed",
+			query: `{ account(id: "Mark") { transfers { id } } }`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetAccounts", mock.Anything, []string{"Mark"}).
+					Return([]*models.Account{{ID: "Mark"}}, nil)
+				m.On("ListTransfers", mock.Anything, "Mark", defaultListLimit).
+					Return(nil, errors.New("connection refused"))
+			},
+			wantData:  `{"account": {"transfers": null}}`,
+			wantCodes: []string{CodeInternal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			result := NewExecutor(Config{BankService: mockService}).
+				Execute(asPrincipal("Mark"), Request{Query: tt.query})
+
+			assert.JSONEq(t, tt.wantData, resultJSON(t, result))
+			assert.Equal(t, tt.wantCodes, resultCodes(result))
+			for _, err := range result.Errors {
+				assert.NotContains(t, err.Message, "connection refused")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExecutor_TransferMutation(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 40}).
+		Return(&models.Transfer{ID: "t1", From: "Mark", To: "Jane", Amount: 40,
+			Status: models.TransferStatusCompleted}, nil).Once()
+	mockService.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 1000}).
+		Return(nil, transfererrors.ErrInsufficientFunds).Once()
+	mockService.On("SubmitTransfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 5}).
+		Return(&models.Transfer{ID: "t2", From: "Mark", To: "Jane", Amount: 5,
+			Status: models.TransferStatusPending}, nil).Once()
+	mockService.On("GetAccounts", mock.Anything, []string{"Mark"}).
+		Return([]*models.Account{{ID: "Mark", Balance: 60}}, nil).Once()
+
+	result := NewExecutor(Config{BankService: mockService}).Execute(asPrincipal("Mark"), Request{
+		Query: `mutation Pay($amount: Float!) {
+			paid: transfer(from: "Mark", to: "Jane", amount: $amount) { id status from { balance } }
+			rejected: transfer(from: "Mark", to: "Jane", amount: 1000) { id }
+			queued: transfer(from: "Mark", to: "Jane", amount: 5, async: true) { id status }
+			stolen: transfer(from: "Jane", to: "Mark", amount: 5) { id }
+		}`,
+		OperationName: "Pay",
+		Variables:     map[string]interface{}{"amount": 40.0},
+	})
+
+	assert.JSONEq(t, `{
+		"paid": {"id": "t1", "status": "COMPLETED", "from": {"balance": 60}},
+		"rejected": null,
+		"queued": {"id": "t2", "status": "PENDING"},
+		"stolen": null
+	}`, resultJSON(t, result))
+	assert.ElementsMatch(t, []string{CodeFailedPrecondition, CodeForbidden}, resultCodes(result))
+	mockService.AssertExpectations(t)
+}
+
+func TestExecutor_RejectsBeforeExecution(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  Request
+		wantCode string
+	}{
+		{
+			name:     "syntax error",
+			request:  Request{Query: `{ account(id: "Mark") { id }`},
+			wantCode: CodeParseFailed,
+		},
+		{
+			name:     "unknown field",
+			request:  Request{Query: `{ account(id: "Mark") { owner } }`},
+			wantCode: CodeValidationFailed,
+		},
+		{
+			name: "too deep",
+			request: Request{Query: `{ account(id: "Mark") { transfers(limit: 1) { from { transfers(limit: 1) {
+				to { transfers(limit: 1) { from { transfers(limit: 1) { id } } } } } } } } }`},
+			wantCode: CodeQueryTooComplex,
+		},
+		{
+			name: "too complex through fragments and variables",
+			request: Request{
+				Query: `query Wide($n: Int) { account(id: "Mark") { transfers(limit: $n) { ...Parties } } }
+					fragment Parties on Transfer { from { transfers(limit: 100) { id amount } } }`,
+				Variables: map[string]interface{}{"n": 100.0},
+			},
+			wantCode: CodeQueryTooComplex,
+		},
+		{
+			name:     "unknown operation",
+			request:  Request{Query: `query A { account(id: "Mark") { id } }`, OperationName: "B"},
+			wantCode: CodeBadUserInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+
+			result := NewExecutor(Config{BankService: mockService, MaxDepth: 6, MaxComplexity: 1000}).
+				Execute(asPrincipal("Mark"), tt.request)
+
+			assert.Nil(t, result.Data)
+			assert.Equal(t, []string{tt.wantCode}, resultCodes(result))
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckLimits(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		wantDepth      int
+		wantComplexity int
+	}{
+		{
+			name:           "flat query",
+			query:          `{ account(id: "Mark") { id balance } }`,
+			wantDepth:      2,
+			wantComplexity: 3,
+		},
+		{
+			name:           "list field multiplied by its limit",
+			query:          `{ account(id: "Mark") { transfers(limit: 10) { id from { id } } } }`,
+			wantDepth:      4,
+			wantComplexity: 1 + 1 + 10*(1+2),
+		},
+		{
+			name:           "list field without limit uses the default",
+			query:          `{ account(id: "Mark") { transfers { id } } }`,
+			wantDepth:      3,
+			wantComplexity: 1 + 1 + defaultListLimit,
+		},
+		{
+			name: "fragments are expanded",
+			query: `{ a: account(id: "Mark") { ...Fields } b: account(id: "Jane") { ...Fields } }
+				fragment Fields on Account { id ... on Account { balance } }`,
+			wantDepth:      2,
+			wantComplexity: 2 * 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse(parser.ParseParams{Source: tt.query})
+			require.NoError(t, err)
+
+			assert.NoError(t, checkLimits(doc, nil, tt.wantDepth, tt.wantComplexity))
+			assert.Error(t, checkLimits(doc, nil, tt.wantDepth-1, tt.wantComplexity))
+			assert.Error(t, checkLimits(doc, nil, tt.wantDepth, tt.wantComplexity-1))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package graphqlapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// limitArgument is the argument that bounds the length of list fields
+const limitArgument = "limit"
+
+// queryCost is the depth and complexity of a selection
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+// limitError is returned when a query exceeds the configured depth or complexity
+type limitError struct {
+	limit string
+	value int
+	max   int
+}
+
+func (e *limitError) Error() string {
+	return fmt.Sprintf("query %s %d exceeds the maximum of %d", e.limit, e.value, e.max)
+}
+
+// checkLimits rejects documents whose operations nest deeper than maxDepth or
+// could resolve more than maxComplexity fields
+// Every field costs one; the cost of the selections below a list field is
+// multiplied by its limit argument, so the complexity is an upper bound of the
+// number of fields the executor may resolve.
+func checkLimits(doc *ast.Document, variables map[string]interface{}, maxDepth, maxComplexity int) error {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	costs := &costCalculator{fragments: fragments, variables: variables}
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		cost := costs.selectionSet(operation.SelectionSet, nil)
+		if cost.depth > maxDepth {
+			return &limitError{limit: "depth", value: cost.depth, max: maxDepth}
+		}
+		if cost.complexity > maxComplexity {
+			return &limitError{limit: "complexity", value: cost.complexity, max: maxComplexity}
+		}
+	}
+	return nil
+}
+
+// costCalculator walks selection sets, expanding fragment spreads
+type costCalculator struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selectionSet returns the cost of a selection set; visiting holds the fragments
+// being expanded so that cyclic spreads are not followed forever
+func (c *costCalculator) selectionSet(set *ast.SelectionSet, visiting []string) queryCost {
+	var total queryCost
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var cost queryCost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			children := c.selectionSet(selection.SelectionSet, visiting)
+			cost = queryCost{
+				depth:      children.depth + 1,
+				complexity: 1 + c.multiplier(selection)*children.complexity,
+			}
+		case *ast.InlineFragment:
+			cost = c.selectionSet(selection.SelectionSet, visiting)
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := c.fragments[name]
+			if !ok || slices.Contains(visiting, name) {
+				continue
+			}
+			cost = c.selectionSet(fragment.SelectionSet, append(visiting, name))
+		}
+
+		total.depth = max(total.depth, cost.depth)
+		total.complexity += cost.complexity
+	}
+	return total
+}
+
+// multiplier returns how many times the selections of a field may be resolved
+func (c *costCalculator) multiplier(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != limitArgument {
+			continue
+		}
+		if limit, ok := c.intValue(argument.Value); ok {
+			return min(max(limit, 1), maxListLimit)
+		}
+		return maxListLimit
+	}
+	if listFields[field.Name.Value] {
+		return defaultListLimit
+	}
+	return 1
+}
+
+// intValue evaluates an integer literal or variable
+func (c *costCalculator) intValue(value ast.Value) (int, bool) {
+	switch value := value.(type) {
+	case *ast.IntValue:
+		n, err := strconv.Atoi(value.Value)
+		return n, err == nil
+	case *ast.Variable:
+		switch n := c.variables[value.Name.Value].(type) {
+		case int:
+			return n, true
+		case float64:
+			return int(n), true
+		case json.Number:
+			i, err := n.Int64()
+			return int(i), err == nil
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package graphqlapi
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/service"
+)
+
+// accountResult is the outcome of loading a single account
+type accountResult struct {
+	account *models.Account
+	err     error
+}
+
+// accountLoader batches account lookups made while resolving one request
+// Load only queues the ID; the first returned thunk that is called fetches every
+// queued ID with a single GetAccounts call. The executor calls thunks breadth-first,
+// so all accounts referenced at one level of the response are loaded together.
+type accountLoader struct {
+	bankService service.BankService
+
+	mu      sync.Mutex
+	pending []string
+	results map[string]accountResult
+}
+
+// newAccountLoader creates a loader with an empty cache
+func newAccountLoader(bankService service.BankService) *accountLoader {
+	return &accountLoader{
+		bankService: bankService,
+		results:     make(map[string]accountResult),
+	}
+}
+
+// Load queues the account for the next batch and returns a thunk resolving to it
+func (l *accountLoader) Load(ctx context.Context, id string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok && !slices.Contains(l.pending, id) {
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, ok := l.results[id]; !ok {
+			// The account may have been cached at Load and cleared since; it is read again
+			if !slices.Contains(l.pending, id) {
+				l.pending = append(l.pending, id)
+			}
+			l.dispatch(ctx)
+		}
+		result := l.results[id]
+		if result.err != nil {
+			return nil, result.err
+		}
+		return result.account, nil
+	}
+}
+
+// Clear drops cached accounts so that later loads, and thunks not yet called, read them again,
+// e.g. after a transfer
+func (l *accountLoader) Clear(ids ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range ids {
+		delete(l.results, id)
+	}
+}
+
+// dispatch fetches all pending accounts; the caller must hold l.mu
+// The IDs are sorted, since the executor resolves the fields of an object in no set order.
+func (l *accountLoader) dispatch(ctx context.Context) {
+	ids := l.pending
+	l.pending = nil
+	slices.Sort(ids)
+
+	accounts, err := l.bankService.GetAccounts(ctx, ids)
+	if err != nil {
+		for _, id := range ids {
+			l.results[id] = accountResult{err: err}
+		}
+		return
+	}
+
+	for _, id := range ids {
+		l.results[id] = accountResult{err: transfererrors.ErrAccountNotFound}
+	}
+	for _, account := range accounts {
+		l.results[account.ID] = accountResult{account: account}
+	}
+}
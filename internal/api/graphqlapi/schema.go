@@ -0,0 +1,249 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Bounds of the limit argument of list fields
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// listFields names the fields that return lists bounded by a limit argument
+var listFields = map[string]bool{"transfers": true}
+
+// schema is the GraphQL schema over accounts and transfers
+// Resolvers take the bank service and the account loader from the request state
+// that Executor adds to the context.
+var schema = mustSchema()
+
+var transferStatusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "TransferStatus",
+	Description: "Lifecycle status of a transfer",
+	Values: graphql.EnumValueConfigMap{
+		"CREATED":    {Value: models.TransferStatusCreated},
+		"HELD":       {Value: models.TransferStatusHeld},
+		"PENDING":    {Value: models.TransferStatusPending},
+		"PROCESSING": {Value: models.TransferStatusProcessing},
+		"COMPLETED":  {Value: models.TransferStatusCompleted},
+		"FAILED":     {Value: models.TransferStatusFailed},
+		"REVERSED":   {Value: models.TransferStatusReversed},
+	},
+})
+
+// mustSchema builds the schema; it only fails if the type definitions are invalid
+func mustSchema() graphql.Schema {
+	accountType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Account",
+		Description: "A bank account; fields other than id are only visible to its owner",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		},
+	})
+
+	transferType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transfer",
+		Description: "A money transfer between two accounts",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"from": &graphql.Field{
+				Type:        accountType,
+				Description: "Account debited; null if it does not exist, as for transfers failed for naming it",
+				Resolve:     resolveTransferFrom,
+			},
+			"to": &graphql.Field{
+				Type:        accountType,
+				Description: "Account credited; null if it does not exist, as for transfers failed for naming it",
+				Resolve:     resolveTransferTo,
+			},
+			"amount":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"status":        &graphql.Field{Type: graphql.NewNonNull(transferStatusEnum)},
+			"failureReason": &graphql.Field{Type: graphql.String, Resolve: resolveFailureReason},
+			"createdAt":     &graphql.Field{Type: graphql.DateTime},
+			"updatedAt":     &graphql.Field{Type: graphql.DateTime},
+		},
+	})
+
+	accountType.AddFieldConfig("balance", &graphql.Field{
+		Type:    graphql.Float,
+		Resolve: resolveAccountBalance,
+	})
+	accountType.AddFieldConfig("transfers", &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(transferType)),
+		Description: "Most recent transfers from or to the account, newest first",
+		Args: graphql.FieldConfigArgument{
+			limitArgument: &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListLimit},
+		},
+		Resolve: resolveAccountTransfers,
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"account": &graphql.Field{
+				Type: accountType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: resolveAccount,
+			},
+			"transfer": &graphql.Field{
+				Type: transferType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: resolveTransfer,
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"transfer": &graphql.Field{
+				Type:        transferType,
+				Description: "Transfers money from an account owned by the caller; with async the transfer is only queued",
+				Args: graphql.FieldConfigArgument{
+					"from":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"to":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"amount": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+					"async":  &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: resolveTransferMutation,
+			},
+		},
+	})
+
+	s, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+	if err != nil {
+		panic(fmt.Sprintf("graphqlapi: invalid schema: %v", err))
+	}
+	return s
+}
+
+// authorize checks that the authenticated caller may see or act on the account
+func authorize(ctx context.Context, accountID string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(accountID) {
+		return transfererrors.ErrForbidden
+	}
+	return nil
+}
+
+func resolveAccount(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	if err := authorize(p.Context, id); err != nil {
+		return nil, err
+	}
+	return stateFrom(p.Context).accounts.Load(p.Context, id), nil
+}
+
+func resolveTransfer(p graphql.ResolveParams) (interface{}, error) {
+	id, _ := p.Args["id"].(string)
+	transfer, err := stateFrom(p.Context).bankService.GetTransfer(p.Context, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if authorize(p.Context, transfer.From) != nil && authorize(p.Context, transfer.To) != nil {
+		return nil, transfererrors.ErrForbidden
+	}
+	return transfer, nil
+}
+
+func resolveTransferMutation(p graphql.ResolveParams) (interface{}, error) {
+	req := models.TransferRequest{}
+	req.From, _ = p.Args["from"].(string)
+	req.To, _ = p.Args["to"].(string)
+	req.Amount, _ = p.Args["amount"].(float64)
+	async, _ := p.Args["async"].(bool)
+
+	if errs := validation.Struct(req); len(errs) > 0 {
+		return nil, invalidFields(errs)
+	}
+	if err := authorize(p.Context, req.From); err != nil {
+		return nil, err
+	}
+
+	state := stateFrom(p.Context)
+	if err := state.throttle.Allow(p.Context, req.From); err != nil {
+		return nil, err
+	}
+
+	var transfer *models.Transfer
+	var err error
+	if async {
+		transfer, err = state.bankService.SubmitTransfer(p.Context, req)
+	} else {
+		transfer, err = state.bankService.Transfer(p.Context, req)
+	}
+	// A held transfer was accepted; it waits for sanctions review
+	if err != nil && !errors.Is(err, transfererrors.ErrTransferHeld) {
+		return nil, err
+	}
+
+	state.accounts.Clear(req.From, req.To)
+	return transfer, nil
+}
+
+func resolveTransferFrom(p graphql.ResolveParams) (interface{}, error) {
+	transfer, _ := p.Source.(*models.Transfer)
+	return loadTransferAccount(p, transfer.From), nil
+}
+
+func resolveTransferTo(p graphql.ResolveParams) (interface{}, error) {
+	transfer, _ := p.Source.(*models.Transfer)
+	return loadTransferAccount(p, transfer.To), nil
+}
+
+// loadTransferAccount returns a thunk resolving to an account of a transfer, or to null if the
+// account does not exist, so that transfers failed for naming an unknown account can be listed
+func loadTransferAccount(p graphql.ResolveParams, id string) func() (interface{}, error) {
+	load := stateFrom(p.Context).accounts.Load(p.Context, id)
+	return func() (interface{}, error) {
+		account, err := load()
+		if errors.Is(err, transfererrors.ErrAccountNotFound) {
+			return nil, nil
+		}
+		return account, err
+	}
+}
+
+func resolveFailureReason(p graphql.ResolveParams) (interface{}, error) {
+	transfer, _ := p.Source.(*models.Transfer)
+	if transfer.FailureReason == "" {
+		return nil, nil
+	}
+	return transfer.FailureReason, nil
+}
+
+func resolveAccountBalance(p graphql.ResolveParams) (interface{}, error) {
+	account, _ := p.Source.(*models.Account)
+	if err := authorize(p.Context, account.ID); err != nil {
+		return nil, err
+	}
+	return account.Balance, nil
+}
+
+func resolveAccountTransfers(p graphql.ResolveParams) (interface{}, error) {
+	account, _ := p.Source.(*models.Account)
+	if err := authorize(p.Context, account.ID); err != nil {
+		return nil, err
+	}
+
+	limit, _ := p.Args[limitArgument].(int)
+	if limit < 1 || limit > maxListLimit {
+		return nil, inputError(fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	return stateFrom(p.Context).bankService.ListTransfers(p.Context, account.ID, limit)
+}
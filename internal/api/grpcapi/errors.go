@@ -0,0 +1,76 @@
+package grpcapi
+
+import (
+	"errors"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/ratelimit"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorCodes maps transfer error sentinels to gRPC status codes
+var errorCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{transfererrors.ErrAccountNotFound, codes.NotFound},
+	{transfererrors.ErrTransferNotFound, codes.NotFound},
+	{transfererrors.ErrInsufficientFunds, codes.FailedPrecondition},
+	{transfererrors.ErrInvalidStatusTransition, codes.FailedPrecondition},
+	{transfererrors.ErrInvalidAmount, codes.InvalidArgument},
+	{transfererrors.ErrSameAccount, codes.InvalidArgument},
+	{transfererrors.ErrInvalidAccountIdentifier, codes.InvalidArgument},
+	{transfererrors.ErrSystemAccount, codes.PermissionDenied},
+	{transfererrors.ErrTransferBlocked, codes.PermissionDenied},
+	{transfererrors.ErrSerializationFailure, codes.Aborted},
+	{transfererrors.ErrUnauthenticated, codes.Unauthenticated},
+	{transfererrors.ErrForbidden, codes.PermissionDenied},
+}
+
+// toStatus converts a service error to a gRPC status error
+// Exceeded rate limits are reported with when to retry; errors other than the known
+// sentinels are reported as Internal without details
+func toStatus(err error) error {
+	var exceeded *ratelimit.ExceededError
+	if errors.As(err, &exceeded) {
+		return resourceExhausted(exceeded)
+	}
+	for _, mapping := range errorCodes {
+		if errors.Is(err, mapping.err) {
+			return status.Error(mapping.code, mapping.err.Error())
+		}
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+// resourceExhausted reports an exceeded rate limit as a ResourceExhausted status with a
+// RetryInfo detail telling when the call may be retried
+func resourceExhausted(exceeded *ratelimit.ExceededError) error {
+	st, err := status.New(codes.ResourceExhausted, exceeded.Error()).
+		WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(exceeded.Result.RetryAfter)})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, exceeded.Error())
+	}
+	return st.Err()
+}
+
+// invalidArgument reports the fields that failed validation as an InvalidArgument status
+// with a BadRequest detail listing every violation
+func invalidArgument(errs []problem.FieldError) error {
+	violations := make([]*errdetails.BadRequest_FieldViolation, len(errs))
+	for i, fe := range errs {
+		violations[i] = &errdetails.BadRequest_FieldViolation{Field: fe.Field, Description: fe.Message}
+	}
+
+	st, err := status.New(codes.InvalidArgument, "request has invalid fields").
+		WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "request has invalid fields")
+	}
+	return st.Err()
+}
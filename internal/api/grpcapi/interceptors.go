@@ -0,0 +1,150 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// apiKeyMetadata is the metadata key carrying an API key when no bearer token is sent
+const apiKeyMetadata = "x-api-key"
+
+// requestIDMetadata is the metadata key carrying the ID correlating a call with its log lines
+const requestIDMetadata = "x-request-id"
+
+// AuthUnaryInterceptor rejects unary calls without a valid API key and stores the principal in the context
+func AuthUnaryInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx, authenticator)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamInterceptor rejects streaming calls without a valid API key and stores the principal in the context
+func AuthStreamInterceptor(authenticator auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), authenticator)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// LoggingUnaryInterceptor assigns every unary call a request ID and logs it with its status code and duration
+func LoggingUnaryInterceptor(logger *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx = withRequestID(ctx)
+		resp, err := handler(ctx, req)
+		logCall(ctx, logger, info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+// LoggingStreamInterceptor assigns every streaming call a request ID and logs it with its status code and duration
+func LoggingStreamInterceptor(logger *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := withRequestID(stream.Context())
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		logCall(ctx, logger, info.FullMethod, err, start)
+		return err
+	}
+}
+
+// withRequestID returns a context carrying the request ID sent by the client, or a new one,
+// and the client address; the request ID is returned to the client in the response header
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDMetadata); len(ids) > 0 && len(ids[0]) <= 128 {
+			requestID = ids[0]
+		}
+	}
+	if requestID == "" {
+		requestID = logging.NewRequestID()
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		ctx = auth.WithClientIP(ctx, host)
+	}
+	return logging.WithRequestID(ctx, requestID)
+}
+
+// logCall writes the access log line of a finished call
+func logCall(ctx context.Context, logger *slog.Logger, method string, err error, start time.Time) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	if code == codes.Internal || code == codes.Unknown {
+		level = slog.LevelError
+	}
+	logger.LogAttrs(ctx, level, "call served",
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	)
+}
+
+// authenticate resolves the API key in the call metadata and returns a context carrying the principal
+func authenticate(ctx context.Context, authenticator auth.Authenticator) (context.Context, error) {
+	credential := credentialFrom(ctx)
+	if credential == "" {
+		return nil, toStatus(transfererrors.ErrUnauthenticated)
+	}
+
+	principal, err := authenticator.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, toStatus(transfererrors.ErrUnauthenticated)
+	}
+
+	return auth.WithPrincipal(ctx, principal), nil
+}
+
+// credentialFrom extracts the API key from the call metadata, preferring a bearer token
+func credentialFrom(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get("authorization") {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return strings.TrimSpace(token)
+		}
+	}
+	if keys := md.Get(apiKeyMetadata); len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+// contextStream overrides the context of a server stream, e.g. with one carrying the principal
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the overriding context
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
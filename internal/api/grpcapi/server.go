@@ -0,0 +1,257 @@
+// Package grpcapi serves the bank over gRPC, alongside the HTTP API
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"time"
+
+	"money-transfer/internal/api/grpcapi/bankv1"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/lifecycle"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Config holds the dependencies and settings of the gRPC server
+type Config struct {
+	BankService   service.BankService
+	Authenticator auth.Authenticator
+	// TransferThrottle limits transfers by caller and source account; nothing is limited when it is nil
+	TransferThrottle *ratelimit.Throttle
+	// Logger receives access logs and errors; nothing is logged when it is nil
+	Logger *slog.Logger
+	// StreamPollInterval is how often event streams check for new activity
+	StreamPollInterval time.Duration
+	// Stopping ends event streams when closed, so that graceful stop does not wait for them
+	Stopping <-chan struct{}
+}
+
+// NewServer creates a gRPC server exposing the bank service with auth and logging interceptors
+func NewServer(cfg Config) *grpc.Server {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = logging.Discard()
+	}
+
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			LoggingUnaryInterceptor(logger),
+			AuthUnaryInterceptor(cfg.Authenticator),
+		),
+		grpc.ChainStreamInterceptor(
+			LoggingStreamInterceptor(logger),
+			AuthStreamInterceptor(cfg.Authenticator),
+		),
+	)
+	bankv1.RegisterBankServiceServer(server, &bankServer{
+		bankService:  cfg.BankService,
+		throttle:     cfg.TransferThrottle,
+		logger:       logger,
+		pollInterval: cfg.StreamPollInterval,
+		stopping:     cfg.Stopping,
+	})
+	return server
+}
+
+// Hook returns the lifecycle hook serving gRPC on addr between start and stop
+// Stopping waits for the calls in flight until its context is done, then closes the
+// remaining connections.
+func Hook(server *grpc.Server, addr string, logger *slog.Logger) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: "grpc server",
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+
+			logger.InfoContext(ctx, "starting gRPC server", "addr", listener.Addr().String())
+			go func() {
+				if err := server.Serve(listener); err != nil {
+					logger.Error("gRPC server failed", "error", err)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			err := lifecycle.Wait(ctx, server.GracefulStop)
+			if err != nil {
+				server.Stop()
+			}
+			return err
+		},
+	}
+}
+
+// bankServer implements bankv1.BankServiceServer on top of service.BankService
+type bankServer struct {
+	bankv1.UnimplementedBankServiceServer
+
+	bankService  service.BankService
+	throttle     *ratelimit.Throttle
+	logger       *slog.Logger
+	pollInterval time.Duration
+	stopping     <-chan struct{}
+}
+
+// Transfer moves money from an account owned by the caller
+func (s *bankServer) Transfer(ctx context.Context, req *bankv1.TransferRequest) (*bankv1.TransferResponse, error) {
+	if err := authorize(ctx, req.GetFrom()); err != nil {
+		return nil, err
+	}
+
+	transferReq := models.TransferRequest{From: req.GetFrom(), To: req.GetTo(), Amount: req.GetAmount()}
+	if errs := validation.Struct(transferReq); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+	if err := s.throttle.Allow(ctx, transferReq.From); err != nil {
+		return nil, toStatus(err)
+	}
+
+	var transfer *models.Transfer
+	var err error
+	if req.GetAsync() {
+		transfer, err = s.bankService.SubmitTransfer(ctx, transferReq)
+	} else {
+		transfer, err = s.bankService.Transfer(ctx, transferReq)
+	}
+	// A held transfer was accepted; it waits for sanctions review
+	if err != nil && !errors.Is(err, transfererrors.ErrTransferHeld) {
+		return nil, toStatus(err)
+	}
+
+	return &bankv1.TransferResponse{Transfer: toProtoTransfer(transfer)}, nil
+}
+
+// GetBalance returns the balance of an account owned by the caller
+func (s *bankServer) GetBalance(ctx context.Context, req *bankv1.GetBalanceRequest) (*bankv1.GetBalanceResponse, error) {
+	if err := authorize(ctx, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+	if errs := validation.Value("account_id", req.GetAccountId(), "required,account_id"); len(errs) > 0 {
+		return nil, invalidArgument(errs)
+	}
+
+	balance, err := s.bankService.GetBalance(ctx, req.GetAccountId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bankv1.GetBalanceResponse{AccountId: req.GetAccountId(), Balance: balance}, nil
+}
+
+// StreamAccountEvents streams activity of an account owned by the caller until the client cancels
+// or the server stops, in which case it fails with Unavailable so that the client resumes
+// from the last sequence it received.
+func (s *bankServer) StreamAccountEvents(
+	req *bankv1.StreamAccountEventsRequest, stream bankv1.BankService_StreamAccountEventsServer,
+) error {
+	ctx, cancel := lifecycle.Until(stream.Context(), s.stopping)
+	defer cancel()
+	accountID := req.GetAccountId()
+	if err := authorize(ctx, accountID); err != nil {
+		return err
+	}
+	if errs := validation.Value("account_id", accountID, "required,account_id"); len(errs) > 0 {
+		return invalidArgument(errs)
+	}
+
+	if _, err := s.bankService.GetBalance(ctx, accountID); err != nil {
+		return toStatus(err)
+	}
+
+	after := req.GetAfterSequence()
+	if req.AfterSequence == nil {
+		latest, err := s.bankService.LatestActivitySequence(ctx)
+		if err != nil {
+			return toStatus(err)
+		}
+		after = latest
+	}
+
+	err := service.FollowAccountActivity(ctx, s.logger, s.bankService, accountID, after, s.pollInterval,
+		func(activity *models.AccountActivity) error {
+			return stream.Send(&bankv1.StreamAccountEventsResponse{Event: toProtoEvent(activity)})
+		})
+	if ctx.Err() != nil {
+		if stream.Context().Err() == nil {
+			return status.Error(codes.Unavailable, "server is shutting down")
+		}
+		return nil
+	}
+	return err
+}
+
+// authorize checks that the authenticated caller may act on the account
+func authorize(ctx context.Context, accountID string) error {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(accountID) {
+		return toStatus(transfererrors.ErrForbidden)
+	}
+	return nil
+}
+
+// protoStatuses maps transfer statuses to their protobuf enum values
+var protoStatuses = map[models.TransferStatus]bankv1.TransferStatus{
+	models.TransferStatusCreated:    bankv1.TransferStatus_TRANSFER_STATUS_CREATED,
+	models.TransferStatusHeld:       bankv1.TransferStatus_TRANSFER_STATUS_HELD,
+	models.TransferStatusPending:    bankv1.TransferStatus_TRANSFER_STATUS_PENDING,
+	models.TransferStatusProcessing: bankv1.TransferStatus_TRANSFER_STATUS_PROCESSING,
+	models.TransferStatusCompleted:  bankv1.TransferStatus_TRANSFER_STATUS_COMPLETED,
+	models.TransferStatusFailed:     bankv1.TransferStatus_TRANSFER_STATUS_FAILED,
+	models.TransferStatusReversed:   bankv1.TransferStatus_TRANSFER_STATUS_REVERSED,
+}
+
+// protoDirections maps activity directions to their protobuf enum values
+var protoDirections = map[models.ActivityDirection]bankv1.Direction{
+	models.DirectionIncoming: bankv1.Direction_DIRECTION_INCOMING,
+	models.DirectionOutgoing: bankv1.Direction_DIRECTION_OUTGOING,
+}
+
+// toProtoTransfer converts a transfer to its protobuf message
+func toProtoTransfer(transfer *models.Transfer) *bankv1.Transfer {
+	if transfer == nil {
+		return nil
+	}
+
+	message := &bankv1.Transfer{
+		Id:            transfer.ID,
+		From:          transfer.From,
+		To:            transfer.To,
+		Amount:        transfer.Amount,
+		Status:        protoStatuses[transfer.Status],
+		FailureReason: transfer.FailureReason,
+	}
+	if !transfer.CreatedAt.IsZero() {
+		message.CreatedAt = timestamppb.New(transfer.CreatedAt)
+	}
+	if !transfer.UpdatedAt.IsZero() {
+		message.UpdatedAt = timestamppb.New(transfer.UpdatedAt)
+	}
+	return message
+}
+
+// toProtoEvent converts an account activity to its protobuf message
+func toProtoEvent(activity *models.AccountActivity) *bankv1.AccountEvent {
+	return &bankv1.AccountEvent{
+		Sequence:   activity.Sequence,
+		Type:       string(activity.Type),
+		AccountId:  activity.AccountID,
+		Direction:  protoDirections[activity.Direction],
+		Transfer:   toProtoTransfer(activity.Transfer),
+		Balance:    activity.Balance,
+		OccurredAt: timestamppb.New(activity.OccurredAt),
+	}
+}
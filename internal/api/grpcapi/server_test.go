@@ -0,0 +1,303 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"money-transfer/internal/api/grpcapi/bankv1"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
+	"money-transfer/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// setupClient serves bankService over an in-memory connection and returns a client for it
+func setupClient(t *testing.T, bankService *mocks.BankServiceMock) bankv1.BankServiceClient {
+	t.Helper()
+	return setupStoppingClient(t, bankService, nil)
+}
+
+// setupStoppingClient is setupClient with event streams ending when stopping is closed
+func setupStoppingClient(
+	t *testing.T, bankService *mocks.BankServiceMock, stopping <-chan struct{},
+) bankv1.BankServiceClient {
+	t.Helper()
+	return setupConfiguredClient(t, Config{BankService: bankService, Stopping: stopping})
+}
+
+// setupConfiguredClient serves cfg, completed with an API key of a customer owning Mark,
+// over an in-memory connection and returns a client for it
+func setupConfiguredClient(t *testing.T, cfg Config) bankv1.BankServiceClient {
+	t.Helper()
+
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+	cfg.Authenticator = apiKeys
+	cfg.StreamPollInterval = 10 * time.Millisecond
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(cfg)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return bankv1.NewBankServiceClient(conn)
+}
+
+// withAPIKey returns a context sending key as call metadata
+func withAPIKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+}
+
+func TestBankServer_Transfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		ctx        context.Context
+		request    *bankv1.TransferRequest
+		setupMock  func(*mocks.BankServiceMock)
+		wantCode   codes.Code
+		wantStatus bankv1.TransferStatus
+	}{
+		{
+			name:    "successful transfer",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 50},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 50}).
+					Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil)
+			},
+			wantCode:   codes.OK,
+			wantStatus: bankv1.TransferStatus_TRANSFER_STATUS_COMPLETED,
+		},
+		{
+			name:    "async transfer",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 5, Async: true},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("SubmitTransfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 5}).
+					Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusPending}, nil)
+			},
+			wantCode:   codes.OK,
+			wantStatus: bankv1.TransferStatus_TRANSFER_STATUS_PENDING,
+		},
+		{
+			name:    "insufficient funds",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 500},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, mock.Anything).Return(nil, transfererrors.ErrInsufficientFunds)
+			},
+			wantCode: codes.FailedPrecondition,
+		},
+		{
+			name:    "held for sanctions review",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 50},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, mock.Anything).
+					Return(&models.Transfer{ID: "t-3", Status: models.TransferStatusHeld}, transfererrors.ErrTransferHeld)
+			},
+			wantCode:   codes.OK,
+			wantStatus: bankv1.TransferStatus_TRANSFER_STATUS_HELD,
+		},
+		{
+			name:    "blocked by sanctions screening",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 50},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, mock.Anything).
+					Return(&models.Transfer{ID: "t-4", Status: models.TransferStatusFailed}, transfererrors.ErrTransferBlocked)
+			},
+			wantCode: codes.PermissionDenied,
+		},
+		{
+			name:    "account not found",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Nobody", Amount: 5},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, mock.Anything).Return(nil, transfererrors.ErrAccountNotFound)
+			},
+			wantCode: codes.NotFound,
+		},
+		{
+			name:      "invalid amount",
+			ctx:       withAPIKey("mark-key"),
+			request:   &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: -5},
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  codes.InvalidArgument,
+		},
+		{
+			name:    "internal error",
+			ctx:     withAPIKey("mark-key"),
+			request: &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 5},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, mock.Anything).Return(nil, assert.AnError)
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name:      "another principal's account",
+			ctx:       withAPIKey("mark-key"),
+			request:   &bankv1.TransferRequest{From: "Jane", To: "Mark", Amount: 5},
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  codes.PermissionDenied,
+		},
+		{
+			name:      "missing api key",
+			ctx:       context.Background(),
+			request:   &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 5},
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  codes.Unauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+			client := setupClient(t, mockService)
+
+			resp, err := client.Transfer(tt.ctx, tt.request)
+
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantCode == codes.OK {
+				assert.Equal(t, tt.wantStatus, resp.GetTransfer().GetStatus())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBankServer_TransferValidation(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	client := setupClient(t, mockService)
+
+	_, err := client.Transfer(withAPIKey("mark-key"), &bankv1.TransferRequest{From: "Mark", To: "Mark", Amount: 0.001})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+	require.Len(t, st.Details(), 1)
+	badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+	require.True(t, ok)
+
+	fields := make([]string, 0, len(badRequest.GetFieldViolations()))
+	for _, violation := range badRequest.GetFieldViolations() {
+		fields = append(fields, violation.GetField())
+	}
+	assert.Equal(t, []string{"to", "amount"}, fields)
+	mockService.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything)
+}
+
+func TestBankServer_TransferRateLimited(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 10}).
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+	rules, err := ratelimit.ParseRules("POST /api/v1/transfer principal=1/m")
+	require.NoError(t, err)
+	client := setupConfiguredClient(t, Config{
+		BankService:      mockService,
+		TransferThrottle: ratelimit.NewThrottle(ratelimit.NewMemory(), rules, "POST", "/api/v1/transfer"),
+	})
+
+	req := &bankv1.TransferRequest{From: "Mark", To: "Jane", Amount: 10}
+	_, err = client.Transfer(withAPIKey("mark-key"), req)
+	require.NoError(t, err)
+
+	_, err = client.Transfer(withAPIKey("mark-key"), req)
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 1)
+	retryInfo, ok := st.Details()[0].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.InDelta(t, time.Minute.Seconds(), retryInfo.GetRetryDelay().AsDuration().Seconds(), 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestBankServer_GetBalance(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+	client := setupClient(t, mockService)
+
+	resp, err := client.GetBalance(withAPIKey("mark-key"), &bankv1.GetBalanceRequest{AccountId: "Mark"})
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, resp.GetBalance())
+
+	_, err = client.GetBalance(withAPIKey("mark-key"), &bankv1.GetBalanceRequest{AccountId: "Jane"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestBankServer_StreamAccountEvents(t *testing.T) {
+	balance := 60.0
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(5), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{
+			{Sequence: 6, Type: models.EventTransferCompleted, AccountID: "Mark",
+				Direction: models.DirectionOutgoing, Balance: &balance,
+				Transfer: &models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}},
+		}, nil).Once()
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(6), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{}, nil).Maybe()
+	client := setupClient(t, mockService)
+
+	ctx, cancel := context.WithCancel(withAPIKey("mark-key"))
+	defer cancel()
+	after := int64(5)
+	stream, err := client.StreamAccountEvents(ctx, &bankv1.StreamAccountEventsRequest{
+		AccountId:     "Mark",
+		AfterSequence: &after,
+	})
+	require.NoError(t, err)
+
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	event := resp.GetEvent()
+	assert.Equal(t, int64(6), event.GetSequence())
+	assert.Equal(t, string(models.EventTransferCompleted), event.GetType())
+	assert.Equal(t, bankv1.Direction_DIRECTION_OUTGOING, event.GetDirection())
+	assert.Equal(t, 60.0, event.GetBalance())
+	assert.Equal(t, "t-1", event.GetTransfer().GetId())
+}
+
+func TestBankServer_StreamAccountEventsEndsWhenStopping(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(5), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{}, nil)
+	stopping := make(chan struct{})
+	client := setupStoppingClient(t, mockService, stopping)
+
+	after := int64(5)
+	stream, err := client.StreamAccountEvents(withAPIKey("mark-key"), &bankv1.StreamAccountEventsRequest{
+		AccountId:     "Mark",
+		AfterSequence: &after,
+	})
+	require.NoError(t, err)
+
+	close(stopping)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/service"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// Defaults used when the handler configuration leaves stream intervals unset
+const (
+	defaultStreamPollInterval = time.Second
+	defaultStreamHeartbeat    = 15 * time.Second
+)
+
+// AccountEventsHandler streams account activity as Server-Sent Events
+type AccountEventsHandler struct {
+	bankService   service.BankService
+	authenticator auth.Authenticator
+	logger        *slog.Logger
+	stopping      <-chan struct{}
+	pollInterval  time.Duration
+	heartbeat     time.Duration
+}
+
+// NewAccountEventsHandler creates a new account events handler
+func NewAccountEventsHandler(cfg *HandlerConfig) *AccountEventsHandler {
+	h := &AccountEventsHandler{
+		bankService:   cfg.BankService,
+		authenticator: cfg.Authenticator,
+		logger:        cfg.Logger,
+		stopping:      cfg.Stopping,
+		pollInterval:  cfg.StreamPollInterval,
+		heartbeat:     cfg.StreamHeartbeat,
+	}
+	if h.logger == nil {
+		h.logger = logging.Discard()
+	}
+	if h.pollInterval <= 0 {
+		h.pollInterval = defaultStreamPollInterval
+	}
+	if h.heartbeat <= 0 {
+		h.heartbeat = defaultStreamHeartbeat
+	}
+	return h
+}
+
+// Register registers handler routes
+func (h *AccountEventsHandler) Register(group *gin.RouterGroup) {
+	group.GET("/accounts/:id/events", middleware.RequireAuth(h.authenticator, middleware.AllowQueryToken()),
+		h.StreamEvents)
+}
+
+// StreamEvents godoc
+// @Summary Stream account activity
+// @Description Streams balance changes and incoming/outgoing transfers of an account as Server-Sent Events.
+// @Description Each event carries the activity sequence as its id and the event type as its name.
+// @Description Reconnecting clients send Last-Event-ID to resume after the last event they received;
+// @Description without it the stream starts with new activity only.
+// @Description Browser EventSource clients may pass the API key as the access_token query parameter.
+// @Tags accounts
+// @Produce text/event-stream
+// @Param id path string true "Account ID"
+// @Param Last-Event-ID header int false "Sequence of the last event received"
+// @Success 200 {object} models.AccountActivity "Stream of activities"
+// @Failure 400 {object} problem.Problem "Malformed account ID or Last-Event-ID"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Account belongs to another principal"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /accounts/{id}/events [get]
+func (h *AccountEventsHandler) StreamEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	accountID, ok := validation.Param(c, "id", "account_id")
+	if !ok {
+		return
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(accountID) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	after, err := strconv.ParseInt(lastEventID, 10, 64)
+	if lastEventID != "" && (err != nil || after < 0) {
+		problem.Invalid(c, problem.FieldError{
+			Field: "Last-Event-ID", Code: "type", Message: "must be a non-negative integer",
+		})
+		return
+	}
+
+	if _, err := h.bankService.GetBalance(ctx, accountID); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	// Without Last-Event-ID only activity recorded from now on is streamed
+	if lastEventID == "" {
+		if after, err = h.bankService.LatestActivitySequence(ctx); err != nil {
+			problem.Error(c, err)
+			return
+		}
+	}
+
+	// The server write timeout would otherwise cut every stream short
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", sse.ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	poll := time.NewTicker(h.pollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		activities, err := h.bankService.AccountActivity(ctx, accountID, after, service.ActivityBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				h.logger.ErrorContext(ctx, "failed to read account activity", "account", accountID, "error", err)
+			}
+			return
+		}
+
+		for _, activity := range activities {
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(activity.Sequence, 10),
+				Event: string(activity.Type),
+				Data:  activity,
+			})
+			after = activity.Sequence
+		}
+		if len(activities) > 0 {
+			c.Writer.Flush()
+			heartbeat.Reset(h.heartbeat)
+		}
+		if len(activities) == service.ActivityBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.stopping:
+			// Clients resume from the last event ID they received on reconnecting
+			return
+		case <-poll.C:
+		case <-heartbeat.C:
+			// Comment lines keep proxies from closing an idle connection
+			if _, err := c.Writer.WriteString(": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
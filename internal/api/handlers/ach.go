@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/rail"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxReturnFileSize caps the size of ACH return files, about 20,000 returned entries
+const maxReturnFileSize = 4 << 20
+
+// ACHHandler handles the files exchanged with the ACH partner
+type ACHHandler struct {
+	achService    service.ACHService
+	authenticator auth.Authenticator
+}
+
+// NewACHHandler creates a new ACH handler
+func NewACHHandler(cfg *HandlerConfig) *ACHHandler {
+	return &ACHHandler{
+		achService:    cfg.ACHService,
+		authenticator: cfg.Authenticator,
+	}
+}
+
+// Register registers handler routes
+func (h *ACHHandler) Register(group *gin.RouterGroup) {
+	ach := group.Group("/rails/"+rail.ACHName, middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin, models.RoleRail), requireACH)
+	ach.POST("/files", h.CreateFile)
+	ach.GET("/files/:id", h.GetFile)
+	ach.POST("/files/:id/settlement", h.SettleFile)
+	ach.POST("/returns", h.ProcessReturns)
+}
+
+// requireACH lets rail principals through only if they are the ACH rail
+func requireACH(c *gin.Context) {
+	principal, ok := auth.PrincipalFromContext(c.Request.Context())
+	if !ok || (principal.Role == models.RoleRail && principal.ID != rail.ACHName) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+	c.Next()
+}
+
+// CreateFile godoc
+// @Summary Create an ACH file
+// @Description Puts the ACH payouts submitted since the previous file, at most 10000, in a new NACHA file to
+// @Description send to the ACH partner. Each payout is a credit entry whose trace number is its rail reference.
+// @Description The file is downloaded from the files endpoint.
+// @Tags ach
+// @Produce json
+// @Success 201 {object} models.ACHFile "File created"
+// @Success 204 "No payout is waiting for a file"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not the ACH rail or an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /rails/ach/files [post]
+func (h *ACHHandler) CreateFile(c *gin.Context) {
+	file, err := h.achService.CreateFile(c.Request.Context())
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	if file == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("%s/%s", c.Request.URL.Path, file.ID))
+	c.JSON(http.StatusCreated, file)
+}
+
+// GetFile godoc
+// @Summary Download an ACH file
+// @Description Returns an ACH file as NACHA records of 94 characters, the same each time it is downloaded
+// @Tags ach
+// @Produce plain
+// @Param id path string true "File ID"
+// @Success 200 {string} string "NACHA file"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not the ACH rail or an administrator"
+// @Failure 404 {object} problem.Problem "File not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /rails/ach/files/{id} [get]
+func (h *ACHHandler) GetFile(c *gin.Context) {
+	var out bytes.Buffer
+	if err := h.achService.WriteFile(c.Request.Context(), &out, c.Param("id")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "text/plain; charset=us-ascii", out.Bytes())
+}
+
+// SettleFile godoc
+// @Summary Settle an ACH file
+// @Description Marks the payouts of an ACH file that were not returned as settled, once the ACH partner
+// @Description confirmed its settlement; their funds move from the clearing to the settlement account. Returns
+// @Description arriving later move the funds back from the settlement account.
+// @Description Settling a file again settles only the payouts not settled yet.
+// @Tags ach
+// @Produce json
+// @Param id path string true "File ID"
+// @Success 200 {object} models.ACHSettlement "Payouts settled"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not the ACH rail or an administrator"
+// @Failure 404 {object} problem.Problem "File not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /rails/ach/files/{id}/settlement [post]
+func (h *ACHHandler) SettleFile(c *gin.Context) {
+	settlement, err := h.achService.Settle(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, settlement)
+}
+
+// ProcessReturns godoc
+// @Summary Process an ACH return file
+// @Description Reads a NACHA return file from the ACH partner and returns each payout it sends back, matched by
+// @Description the original trace number, with the reason code and description as failure reason; the transfers
+// @Description of returned payouts are reversed. Every return is listed, with an error when it could not be
+// @Description applied, such as for an unknown trace number. Processing a file again is harmless.
+// @Tags ach
+// @Accept plain
+// @Produce json
+// @Param file body string true "NACHA return file"
+// @Success 200 {array} models.ACHReturn "Returns read from the file"
+// @Failure 400 {object} problem.Problem "Malformed file"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not the ACH rail or an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /rails/ach/returns [post]
+func (h *ACHHandler) ProcessReturns(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxReturnFileSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.BadRequest(c, fmt.Sprintf("return file must not exceed %d bytes", maxReturnFileSize))
+			return
+		}
+		problem.BadRequest(c, "request body could not be read")
+		return
+	}
+
+	returns, err := h.achService.ProcessReturns(c.Request.Context(), bytes.NewReader(body))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	if returns == nil {
+		returns = []models.ACHReturn{}
+	}
+	c.JSON(http.StatusOK, returns)
+}
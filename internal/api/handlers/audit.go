@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits for the number of events returned by ListEvents
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the audit log to auditors and administrators
+type AuditHandler struct {
+	auditService  service.AuditService
+	authenticator auth.Authenticator
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(cfg *HandlerConfig) *AuditHandler {
+	return &AuditHandler{
+		auditService:  cfg.AuditService,
+		authenticator: cfg.Authenticator,
+	}
+}
+
+// Register registers handler routes
+func (h *AuditHandler) Register(group *gin.RouterGroup) {
+	group.GET("/audit", middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin, models.RoleAuditor), h.ListEvents)
+}
+
+// ListEvents godoc
+// @Summary List audit events
+// @Description Returns the audit log of every change made to transfers and balances, oldest first.
+// @Description Each event records the actor, the action, the state before and after, the request ID and
+// @Description the source IP. Sealed events carry their position in the hash chain and their hash.
+// @Description Pages are requested by passing the next_after of the previous page as after.
+// @Tags audit
+// @Produce json
+// @Param actor query string false "Principal that made the change, anonymous or system"
+// @Param action query models.AuditAction false "Kind of change"
+// @Param account query string false "Account involved in the change"
+// @Param transfer query string false "Transfer the change belongs to"
+// @Param since query string false "Events that occurred at or after this RFC 3339 time"
+// @Param until query string false "Events that occurred before this RFC 3339 time"
+// @Param after query int false "Sequence of the last event of the previous page"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Success 200 {object} models.AuditPage "Audit events"
+// @Failure 400 {object} problem.Problem "Invalid filter"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an auditor or administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *AuditHandler) ListEvents(c *gin.Context) {
+	filter, errs := auditFilter(c)
+	if len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	page, err := h.auditService.ListEvents(c.Request.Context(), filter)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// auditFilter reads the filter of ListEvents from the query, reporting every invalid parameter
+func auditFilter(c *gin.Context) (models.AuditFilter, []problem.FieldError) {
+	filter := models.AuditFilter{
+		Actor:      c.Query("actor"),
+		Action:     models.AuditAction(c.Query("action")),
+		AccountID:  c.Query("account"),
+		TransferID: c.Query("transfer"),
+		Limit:      defaultAuditLimit,
+	}
+	var errs []problem.FieldError
+
+	if filter.Action != "" && !filter.Action.IsValid() {
+		errs = append(errs, problem.FieldError{Field: "action", Code: "oneof", Message: "is not an audited action"})
+	}
+
+	for _, bound := range []struct {
+		field string
+		at    *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		value := c.Query(bound.field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			errs = append(errs, problem.FieldError{Field: bound.field, Code: "type", Message: "must be an RFC 3339 time"})
+			continue
+		}
+		*bound.at = parsed
+	}
+
+	if value := c.Query("after"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || after < 0 {
+			errs = append(errs, problem.FieldError{Field: "after", Code: "type", Message: "must be a non-negative integer"})
+		}
+		filter.AfterSequence = after
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			errs = append(errs, problem.FieldError{Field: "limit", Code: "type", Message: "must be a positive integer"})
+		}
+		filter.Limit = min(limit, maxAuditLimit)
+	}
+
+	return filter, errs
+}
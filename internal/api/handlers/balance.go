@@ -1,55 +1,167 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
 	"money-transfer/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxBalanceAccounts bounds the number of accounts of a single bulk balance query
+const maxBalanceAccounts = 100
+
 // BalanceHandler handles balance-related requests
 type BalanceHandler struct {
-	bankService service.BankService
+	bankService   service.BankService
+	authenticator auth.Authenticator
 }
 
 // NewBalanceHandler creates a new balance handler
 func NewBalanceHandler(cfg *HandlerConfig) *BalanceHandler {
 	return &BalanceHandler{
-		bankService: cfg.BankService,
+		bankService:   cfg.BankService,
+		authenticator: cfg.Authenticator,
 	}
 }
 
 // Register registers handler routes
 func (h *BalanceHandler) Register(group *gin.RouterGroup) {
-	group.GET("/balance/:account", h.GetBalance)
+	group.GET("/balance/:account", middleware.RequireAuth(h.authenticator), h.GetBalance)
+	group.GET("/balances", middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin, models.RoleAuditor), h.GetBalances)
 }
 
 // GetBalance godoc
 // @Summary Get account balance
-// @Description Returns the current balance of the specified account
+// @Description Returns the current balance of the specified account, or with as_of the balance it had
+// @Description at that time, after every posting made up to and including it. An account created after
+// @Description as_of had a balance of zero. The caller must be allowed to access the account.
 // @Tags balance
 // @Accept json
 // @Produce json
 // @Param account path string true "Account ID"
-// @Success 200 {object} map[string]float64 "Successful response with balance"
-// @Failure 404 {object} map[string]string "Account not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Param as_of query string false "RFC 3339 time to return the balance at"
+// @Success 200 {object} map[string]any "Successful response with balance, and as_of when given"
+// @Failure 400 {object} problem.Problem "Malformed account ID or as_of, or as_of in the future"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Account owned by another principal"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
 // @Router /balance/{account} [get]
 func (h *BalanceHandler) GetBalance(c *gin.Context) {
-	accountID := c.Param("account")
+	accountID, ok := validation.Param(c, "account", "account_id")
+	if !ok {
+		return
+	}
 
-	balance, err := h.bankService.GetBalance(c.Request.Context(), accountID)
-	if err != nil {
-		switch err {
-		case transfererrors.ErrAccountNotFound:
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	principal, ok := auth.PrincipalFromContext(c.Request.Context())
+	if !ok || !principal.CanAccessAccount(accountID) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+
+	if c.Query("as_of") == "" {
+		balance, err := h.bankService.GetBalance(c.Request.Context(), accountID)
+		if err != nil {
+			problem.Error(c, err)
+			return
 		}
+
+		c.JSON(http.StatusOK, gin.H{"balance": balance})
+		return
+	}
+
+	asOf, errs := asOfQuery(c)
+	if len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	balance, err := h.bankService.BalanceAt(c.Request.Context(), accountID, asOf)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"balance": balance, "as_of": asOf.UTC()})
+}
+
+// GetBalances godoc
+// @Summary Get balances of many accounts
+// @Description Returns the balances of up to 100 accounts at the same point in time, by default now, for
+// @Description reporting such as month-end closing. Unknown accounts are left out of the result.
+// @Tags balance
+// @Produce json
+// @Param accounts query string true "Comma separated account IDs"
+// @Param as_of query string false "RFC 3339 time to return the balances at (default now)"
+// @Success 200 {object} models.BalanceSnapshot "Balances"
+// @Failure 400 {object} problem.Problem "Malformed account IDs or as_of, or as_of in the future"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an auditor or administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /balances [get]
+func (h *BalanceHandler) GetBalances(c *gin.Context) {
+	accountIDs, errs := accountsQuery(c)
+	asOf := time.Now()
+	if c.Query("as_of") != "" {
+		var asOfErrs []problem.FieldError
+		asOf, asOfErrs = asOfQuery(c)
+		errs = append(errs, asOfErrs...)
+	}
+	if len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	snapshot, err := h.bankService.BalancesAt(c.Request.Context(), accountIDs, asOf)
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"balance": balance})
+	c.JSON(http.StatusOK, snapshot)
+}
+
+// asOfQuery reads the as_of parameter of the balance queries
+func asOfQuery(c *gin.Context) (time.Time, []problem.FieldError) {
+	asOf, err := time.Parse(time.RFC3339, c.Query("as_of"))
+	if err != nil {
+		return time.Time{}, []problem.FieldError{{Field: "as_of", Code: "type", Message: "must be an RFC 3339 time"}}
+	}
+	return asOf, nil
+}
+
+// accountsQuery reads the comma separated account IDs of GetBalances, reporting every invalid one
+func accountsQuery(c *gin.Context) ([]string, []problem.FieldError) {
+	value := c.Query("accounts")
+	if value == "" {
+		return nil, []problem.FieldError{{Field: "accounts", Code: "required", Message: "is required"}}
+	}
+
+	accountIDs := strings.Split(value, ",")
+	if len(accountIDs) > maxBalanceAccounts {
+		return nil, []problem.FieldError{{
+			Field:   "accounts",
+			Code:    "max",
+			Message: fmt.Sprintf("must contain at most %d accounts", maxBalanceAccounts),
+		}}
+	}
+
+	var errs []problem.FieldError
+	for i, accountID := range accountIDs {
+		errs = append(errs, validation.Value(fmt.Sprintf("accounts[%d]", i), accountID, "account_id")...)
+	}
+	return accountIDs, errs
 }
@@ -1,21 +1,61 @@
 package handlers
 
 import (
+	"log/slog"
+	"time"
+
 	"money-transfer/internal/api/interfaces"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/health"
+	"money-transfer/internal/metrics"
 	"money-transfer/internal/service"
 )
 
 // HandlerConfig contains configuration for all handlers
 type HandlerConfig struct {
-	BankService service.BankService
+	BankService    service.BankService
+	WebhookService service.WebhookService
+	// AuditService serves the audit log; the log is not served when it is nil
+	AuditService service.AuditService
+	// BatchService executes pain.001 payment batches; they are not accepted when it is nil
+	BatchService service.BatchService
+	// PayoutService pays out to external accounts; payouts are not accepted when it is nil
+	PayoutService service.PayoutService
+	// ACHService exchanges files with the ACH partner; they are not served when it is nil
+	ACHService service.ACHService
+	// FundingService deposits and withdraws funds; deposits and withdrawals are not accepted when it is nil
+	FundingService service.FundingService
+	// ScreeningService lists sanctions screening decisions and reviews held transfers; they are not
+	// served when it is nil
+	ScreeningService service.ScreeningService
+	Authenticator    auth.Authenticator
+	// Logger receives the logs of handlers; nothing is logged when it is nil
+	Logger *slog.Logger
+	// Metrics records request metrics served on /metrics; no metrics are served when it is nil
+	Metrics *metrics.Collector
+	// Health serves the liveness and readiness probes; no probes are served when it is nil
+	Health *health.Checker
+
+	// Stopping is closed when the server starts shutting down; event streams and WebSocket
+	// connections end when it is, so that clients reconnect to another replica
+	Stopping <-chan struct{}
+
+	// MaxBodySize bounds the JSON request bodies read by handlers, in bytes;
+	// validation.DefaultMaxBodySize applies when it is not positive
+	MaxBodySize int64
+
+	// StreamPollInterval is how often event streams check for new activity
+	StreamPollInterval time.Duration
+	// StreamHeartbeat is how long an event stream may stay silent before a keep-alive is sent
+	StreamHeartbeat time.Duration
+
+	// WebSocket holds the settings of WebSocket connections
+	WebSocket WebSocketConfig
+	// GraphQL holds the limits applied to GraphQL queries
+	GraphQL GraphQLConfig
+	// RateLimit holds the limits of each route
+	RateLimit RateLimitConfig
 }
 
 // Handler represents a common interface for all handlers
 type Handler = interfaces.Handler
-
-// NewHandlerConfig creates a new handler configuration
-func NewHandlerConfig(bankService service.BankService) *HandlerConfig {
-	return &HandlerConfig{
-		BankService: bankService,
-	}
-}
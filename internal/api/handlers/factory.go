@@ -1,23 +1,55 @@
 package handlers
 
-import "money-transfer/internal/service"
-
 // Factory creates and manages all handlers
 type Factory struct {
 	config *HandlerConfig
 }
 
 // NewFactory creates a new handler factory
-func NewFactory(bankService service.BankService) *Factory {
+func NewFactory(cfg *HandlerConfig) *Factory {
 	return &Factory{
-		config: NewHandlerConfig(bankService),
+		config: cfg,
 	}
 }
 
 // CreateHandlers creates all application handlers
 func (f *Factory) CreateHandlers() []Handler {
-	return []Handler{
+	handlers := []Handler{
 		NewTransferHandler(f.config),
 		NewBalanceHandler(f.config),
+		NewWebhookHandler(f.config),
+		NewAccountEventsHandler(f.config),
+		NewStatementHandler(f.config),
+		NewWebSocketHandler(f.config),
+		NewGraphQLHandler(f.config),
+	}
+	if f.config.AuditService != nil {
+		handlers = append(handlers, NewAuditHandler(f.config))
+	}
+	if f.config.BatchService != nil {
+		handlers = append(handlers, NewPaymentInitiationHandler(f.config))
+	}
+	if f.config.PayoutService != nil {
+		handlers = append(handlers, NewPayoutHandler(f.config))
+	}
+	if f.config.ACHService != nil {
+		handlers = append(handlers, NewACHHandler(f.config))
+	}
+	if f.config.FundingService != nil {
+		handlers = append(handlers, NewFundingHandler(f.config))
+	}
+	if f.config.ScreeningService != nil {
+		handlers = append(handlers, NewScreeningHandler(f.config))
+	}
+	if f.config.Metrics != nil {
+		handlers = append(handlers, NewMetricsHandler(f.config))
+	}
+	if f.config.Health != nil {
+		handlers = append(handlers, NewHealthHandler(f.config))
+	}
+	// Installed after metrics, so that rejected requests are counted
+	if f.config.RateLimit.Limiter != nil {
+		handlers = append(handlers, NewRateLimitHandler(f.config))
 	}
+	return handlers
 }
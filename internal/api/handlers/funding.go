@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FundingHandler handles deposits into and withdrawals from customer accounts
+type FundingHandler struct {
+	fundingService service.FundingService
+	authenticator  auth.Authenticator
+	maxBodySize    int64
+}
+
+// NewFundingHandler creates a new funding handler
+func NewFundingHandler(cfg *HandlerConfig) *FundingHandler {
+	return &FundingHandler{
+		fundingService: cfg.FundingService,
+		authenticator:  cfg.Authenticator,
+		maxBodySize:    cfg.MaxBodySize,
+	}
+}
+
+// Register registers handler routes
+func (h *FundingHandler) Register(group *gin.RouterGroup) {
+	requireFunder := middleware.RequireRole(models.RoleAdmin, models.RoleRail)
+	group.POST("/deposits", middleware.RequireAuth(h.authenticator), requireFunder, h.Deposit)
+	group.POST("/withdrawals", middleware.RequireAuth(h.authenticator), requireFunder, h.Withdraw)
+}
+
+// Deposit godoc
+// @Summary Deposit funds
+// @Description Credits a customer account with funds received from outside the ledger, taken from a system
+// @Description account such as cash, the default, suspense or settlement. Deposits are deduplicated by their
+// @Description reference: repeating a deposit returns the one made before with status 200. A deposit held
+// @Description for sanctions review is answered with 202 and its transfer, and recorded once repeated after
+// @Description the transfer was released.
+// @Tags funding
+// @Accept json
+// @Produce json
+// @Param request body models.FundingRequest true "Deposit details"
+// @Success 201 {object} models.Funding "Deposit made"
+// @Success 200 {object} models.Funding "Deposit made before with this reference"
+// @Success 202 {object} models.Funding "Deposit held for sanctions review, not yet recorded"
+// @Failure 400 {object} problem.Problem "Validation error or invalid funding account"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not a rail or an administrator, or blocked by sanctions screening"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 409 {object} problem.Problem "Reference already used with other details"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /deposits [post]
+func (h *FundingHandler) Deposit(c *gin.Context) {
+	h.fund(c, h.fundingService.Deposit)
+}
+
+// Withdraw godoc
+// @Summary Withdraw funds
+// @Description Debits a customer account for funds paid out of the ledger, moving them to a system account
+// @Description such as cash, the default, suspense or settlement. Withdrawals are deduplicated by their
+// @Description reference: repeating a withdrawal returns the one made before with status 200. A withdrawal
+// @Description held for sanctions review is answered with 202 and its transfer, and recorded once repeated
+// @Description after the transfer was released.
+// @Tags funding
+// @Accept json
+// @Produce json
+// @Param request body models.FundingRequest true "Withdrawal details"
+// @Success 201 {object} models.Funding "Withdrawal made"
+// @Success 200 {object} models.Funding "Withdrawal made before with this reference"
+// @Success 202 {object} models.Funding "Withdrawal held for sanctions review, not yet recorded"
+// @Failure 400 {object} problem.Problem "Validation error, insufficient funds or invalid funding account"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not a rail or an administrator, or blocked by sanctions screening"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 409 {object} problem.Problem "Reference already used with other details"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /withdrawals [post]
+func (h *FundingHandler) Withdraw(c *gin.Context) {
+	h.fund(c, h.fundingService.Withdraw)
+}
+
+func (h *FundingHandler) fund(
+	c *gin.Context, fund func(context.Context, models.FundingRequest) (*models.Funding, bool, error),
+) {
+	var req models.FundingRequest
+	if !validation.BindJSON(c, &req, h.maxBodySize) {
+		return
+	}
+
+	funding, created, err := fund(c.Request.Context(), req)
+	if errors.Is(err, transfererrors.ErrTransferHeld) {
+		c.JSON(http.StatusAccepted, funding)
+		return
+	}
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, funding)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+
+	"money-transfer/internal/api/graphqlapi"
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLConfig holds the limits applied to GraphQL queries
+type GraphQLConfig struct {
+	MaxDepth      int // how deeply fields may be nested
+	MaxComplexity int // how many fields a query may resolve at most
+}
+
+// GraphQLHandler serves GraphQL queries and mutations over accounts and transfers
+type GraphQLHandler struct {
+	authenticator auth.Authenticator
+	executor      *graphqlapi.Executor
+	maxBodySize   int64
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+func NewGraphQLHandler(cfg *HandlerConfig) *GraphQLHandler {
+	return &GraphQLHandler{
+		authenticator: cfg.Authenticator,
+		executor: graphqlapi.NewExecutor(graphqlapi.Config{
+			BankService:   cfg.BankService,
+			Throttle:      NewTransferThrottle(cfg.RateLimit),
+			Logger:        cfg.Logger,
+			MaxDepth:      cfg.GraphQL.MaxDepth,
+			MaxComplexity: cfg.GraphQL.MaxComplexity,
+		}),
+		maxBodySize: cfg.MaxBodySize,
+	}
+}
+
+// Register registers handler routes
+func (h *GraphQLHandler) Register(group *gin.RouterGroup) {
+	group.POST("/graphql", middleware.RequireAuth(h.authenticator), h.Query)
+}
+
+// Query godoc
+// @Summary Execute a GraphQL query or mutation
+// @Description Queries accounts and transfers, or creates a transfer with the transfer mutation.
+// @Description Query { account(id) { id balance transfers(limit) {...} } transfer(id) {...} }
+// @Description and Mutation { transfer(from, to, amount, async) {...} } are available.
+// @Description Queries nested too deeply or resolving too many fields are rejected before execution.
+// @Description Errors carry a code in extensions.code.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body graphqlapi.Request true "GraphQL request"
+// @Success 200 {object} map[string]interface{} "Execution result, possibly with field errors"
+// @Failure 400 {object} map[string]interface{} "Invalid, malformed or too complex query"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Security ApiKeyAuth
+// @Router /graphql [post]
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphqlapi.Request
+	if !validation.BindJSON(c, &req, h.maxBodySize) {
+		return
+	}
+
+	result := h.executor.Execute(c.Request.Context(), req)
+	if result.Data == nil && result.HasErrors() {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -1,25 +1,43 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"money-transfer/internal/api/problem"
 	"money-transfer/internal/api/testutil"
+	"money-transfer/internal/auth"
 	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/health"
+	"money-transfer/internal/iso20022"
+	"money-transfer/internal/metrics"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
 	"money-transfer/internal/service/mocks"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
-func setupRouter(bankService *mocks.BankServiceMock) *gin.Engine {
-	handlersFactory := NewFactory(bankService)
+// setupRouter sets up the handlers with an API key of a customer owning Mark and one of an administrator
+func setupRouter(t *testing.T, bankService *mocks.BankServiceMock) *gin.Engine {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin")
+	require.NoError(t, err)
+
+	handlersFactory := NewFactory(&HandlerConfig{BankService: bankService, Authenticator: apiKeys})
 	appHandlers := handlersFactory.CreateHandlers()
 	return testutil.SetupTestRouter(appHandlers)
 }
@@ -28,9 +46,10 @@ func TestTransferHandler_Transfer(t *testing.T) {
 	tests := []struct {
 		name       string
 		request    models.TransferRequest
+		apiKey     string
 		setupMock  func(*mocks.BankServiceMock)
 		wantStatus int
-		wantError  string
+		wantCode   string
 	}{
 		{
 			name: "successful transfer",
@@ -42,7 +61,7 @@ func TestTransferHandler_Transfer(t *testing.T) {
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("Transfer", mock.Anything, models.TransferRequest{
 					From: "Mark", To: "Jane", Amount: 50,
-				}).Return(nil)
+				}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil)
 			},
 			wantStatus: http.StatusOK,
 		},
@@ -53,13 +72,14 @@ func TestTransferHandler_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: 100,
 			},
+			apiKey: "admin-key",
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("Transfer", mock.Anything, models.TransferRequest{
 					From: "Adam", To: "Jane", Amount: 100,
-				}).Return(transfererrors.ErrInsufficientFunds)
+				}).Return(nil, transfererrors.ErrInsufficientFunds)
 			},
 			wantStatus: http.StatusBadRequest,
-			wantError:  transfererrors.ErrInsufficientFunds.Error(),
+			wantCode:   problem.CodeInsufficientFunds,
 		},
 		{
 			name: "account not found",
@@ -68,28 +88,41 @@ func TestTransferHandler_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: 50,
 			},
+			apiKey: "admin-key",
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("Transfer", mock.Anything, models.TransferRequest{
 					From: "NonExistent", To: "Jane", Amount: 50,
-				}).Return(transfererrors.ErrAccountNotFound)
+				}).Return(nil, transfererrors.ErrAccountNotFound)
 			},
 			wantStatus: http.StatusNotFound,
-			wantError:  transfererrors.ErrAccountNotFound.Error(),
+			wantCode:   problem.CodeAccountNotFound,
 		},
 		{
-			name: "same account",
+			name: "system account as source",
 			request: models.TransferRequest{
-				From:   "Mark",
+				From:   "cash",
 				To:     "Mark",
-				Amount: 50,
+				Amount: 1000,
 			},
+			apiKey: "admin-key",
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("Transfer", mock.Anything, models.TransferRequest{
-					From: "Mark", To: "Mark", Amount: 50,
-				}).Return(transfererrors.ErrSameAccount)
+					From: "cash", To: "Mark", Amount: 1000,
+				}).Return(nil, transfererrors.ErrSystemAccount)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeSystemAccount,
+		},
+		{
+			name: "same account",
+			request: models.TransferRequest{
+				From:   "Mark",
+				To:     "Mark",
+				Amount: 50,
 			},
+			setupMock:  func(m *mocks.BankServiceMock) {},
 			wantStatus: http.StatusBadRequest,
-			wantError:  transfererrors.ErrSameAccount.Error(),
+			wantCode:   problem.CodeValidationFailed,
 		},
 		{
 			name: "invalid amount",
@@ -98,13 +131,9 @@ func TestTransferHandler_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: -50,
 			},
-			setupMock: func(m *mocks.BankServiceMock) {
-				m.On("Transfer", mock.Anything, models.TransferRequest{
-					From: "Mark", To: "Jane", Amount: -50,
-				}).Return(transfererrors.ErrInvalidAmount)
-			},
+			setupMock:  func(m *mocks.BankServiceMock) {},
 			wantStatus: http.StatusBadRequest,
-			wantError:  transfererrors.ErrInvalidAmount.Error(),
+			wantCode:   problem.CodeValidationFailed,
 		},
 		{
 			name: "internal error",
@@ -116,10 +145,62 @@ func TestTransferHandler_Transfer(t *testing.T) {
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("Transfer", mock.Anything, models.TransferRequest{
 					From: "Mark", To: "Jane", Amount: 50,
-				}).Return(assert.AnError)
+				}).Return(nil, assert.AnError)
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantError:  "internal server error",
+			wantCode:   problem.CodeInternal,
+		},
+		{
+			name: "held for sanctions review",
+			request: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{
+					From: "Mark", To: "Jane", Amount: 50,
+				}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusHeld}, transfererrors.ErrTransferHeld)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "blocked by sanctions screening",
+			request: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{
+					From: "Mark", To: "Jane", Amount: 50,
+				}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusFailed}, transfererrors.ErrTransferBlocked)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeTransferBlocked,
+		},
+		{
+			name: "missing api key",
+			request: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			apiKey:     "-",
+			setupMock:  func(m *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name: "source account of another principal",
+			request: models.TransferRequest{
+				From:   "Jane",
+				To:     "Mark",
+				Amount: 50,
+			},
+			setupMock:  func(m *mocks.BankServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
 		},
 	}
 
@@ -129,13 +210,20 @@ func TestTransferHandler_Transfer(t *testing.T) {
 			mockService := new(mocks.BankServiceMock)
 			tt.setupMock(mockService)
 
-			router := setupRouter(mockService)
+			router := setupRouter(t, mockService)
 
 			body, err := json.Marshal(tt.request)
 			require.NoError(t, err)
 
 			req := httptest.NewRequest("POST", "/api/v1/transfer", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
+			switch tt.apiKey {
+			case "":
+				req.Header.Set("X-API-Key", "mark-key")
+			case "-":
+			default:
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
 			w := httptest.NewRecorder()
 
 			router.ServeHTTP(w, req)
@@ -146,8 +234,9 @@ func TestTransferHandler_Transfer(t *testing.T) {
 			err = json.NewDecoder(w.Body).Decode(&response)
 			require.NoError(t, err)
 
-			if tt.wantError != "" {
-				assert.Equal(t, tt.wantError, response["error"])
+			if tt.wantCode != "" {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantCode, response["code"])
 			} else {
 				assert.Equal(t, true, response["success"])
 			}
@@ -158,14 +247,328 @@ func TestTransferHandler_Transfer(t *testing.T) {
 	}
 }
 
+func TestTransferHandler_TransferValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantCode   string
+		wantErrors []problem.FieldError
+	}{
+		{
+			name:     "missing fields are reported together",
+			body:     `{}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "from", Code: "required", Message: "is required"},
+				{Field: "to", Code: "required", Message: "is required"},
+				{Field: "amount", Code: "required", Message: "is required"},
+			},
+		},
+		{
+			name:     "malformed account id and too many decimals",
+			body:     `{"from":"Mark Smith","to":"Jane","amount":10.005}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "from", Code: "account_id", Message: "must be a UUID in lower case, or a legacy account ID " +
+					"of 1-64 letters, digits, '-' or '_' starting with a letter or digit"},
+				{Field: "amount", Code: "decimals", Message: "must have at most 2 decimal places"},
+			},
+		},
+		{
+			name:     "amount above the maximum and unsupported currency",
+			body:     `{"from":"Mark","to":"Jane","amount":1000000.01,"currency":"EUR"}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "amount", Code: "lte", Message: "must be at most 1000000"},
+				{Field: "currency", Code: "currency", Message: "must be a supported ISO 4217 currency code (USD)"},
+			},
+		},
+		{
+			name:     "unknown field",
+			body:     `{"from":"Mark","to":"Jane","amount":50,"memo":"rent"}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "memo", Code: "unknown", Message: "is not a known field"},
+			},
+		},
+		{
+			name:     "wrong type",
+			body:     `{"from":"Mark","to":"Jane","amount":"50"}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "amount", Code: "type", Message: "must be a number"},
+			},
+		},
+		{
+			name:     "counterparty identifier of another scheme",
+			body:     `{"from":"Mark","to":"settlement","amount":50,"counterparty":{"scheme":"sort_code","identifier":"GB82WEST12345698765432"}}`,
+			wantCode: problem.CodeValidationFailed,
+			wantErrors: []problem.FieldError{
+				{Field: "counterparty.identifier", Code: "account_identifier", Message: "is not a valid account identifier of its scheme"},
+			},
+		},
+		{
+			name:     "malformed json",
+			body:     `{"from":`,
+			wantCode: problem.CodeInvalidRequest,
+		},
+		{
+			name:     "trailing data",
+			body:     `{"from":"Mark","to":"Jane","amount":50} {}`,
+			wantCode: problem.CodeInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			router := setupRouter(t, mockService)
+
+			req := httptest.NewRequest("POST", "/api/v1/transfer", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "mark-key")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+			var response problem.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.wantCode, response.Code)
+			assert.Equal(t, tt.wantErrors, response.Errors)
+
+			mockService.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTransferHandler_TransferBodyTooLarge(t *testing.T) {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+	mockService := new(mocks.BankServiceMock)
+	router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+		BankService: mockService, Authenticator: apiKeys, MaxBodySize: 64,
+	}).CreateHandlers())
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "within the limit",
+			body:       `{"from":"Mark","to":"Jane","amount":50,"currency":"EUR"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:       "over the limit",
+			body:       `{"from":"Mark","to":"Jane","amount":50,"counterparty":{"scheme":"iban","identifier":"GB82WEST12345698765432"}}`,
+			wantStatus: http.StatusRequestEntityTooLarge,
+			wantCode:   problem.CodeRequestTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/transfer", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "mark-key")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var response problem.Problem
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, tt.wantCode, response.Code)
+		})
+	}
+	mockService.AssertNotCalled(t, "Transfer", mock.Anything, mock.Anything)
+}
+
+func TestTransferHandler_TransferAsync(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("SubmitTransfer", mock.Anything, models.TransferRequest{
+		From: "Mark", To: "Jane", Amount: 50,
+	}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusPending}, nil)
+
+	router := setupRouter(t, mockService)
+
+	body, err := json.Marshal(models.TransferRequest{From: "Mark", To: "Jane", Amount: 50})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "/api/v1/transfer?async=true", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", "mark-key")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "/api/v1/transfers/t-1", w.Header().Get("Location"))
+
+	var response models.TransferResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.True(t, response.Success)
+	assert.Equal(t, "t-1", response.TransferID)
+	assert.Equal(t, models.TransferStatusPending, response.Status)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestTransferHandler_GetTransfer(t *testing.T) {
+	completed := &models.Transfer{ID: "t-1", From: "Mark", To: "Jane", Amount: 50, Status: models.TransferStatusCompleted}
+	pending := &models.Transfer{ID: "t-1", From: "Mark", To: "Jane", Amount: 50, Status: models.TransferStatusPending}
+	received := &models.Transfer{ID: "t-1", From: "Jane", To: "Mark", Amount: 50, Status: models.TransferStatusCompleted}
+	others := &models.Transfer{ID: "t-1", From: "Jane", To: "Adam", Amount: 50, Status: models.TransferStatusPending}
+
+	tests := []struct {
+		name       string
+		query      string
+		apiKey     string
+		setupMock  func(*mocks.BankServiceMock)
+		wantStatus int
+		wantState  models.TransferStatus
+		wantCode   string
+	}{
+		{
+			name: "existing transfer",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(completed, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusCompleted,
+		},
+		{
+			name:  "wait for final status",
+			query: "?wait=1s",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(pending, nil)
+				m.On("AwaitTransfer", mock.Anything, "t-1").Return(completed, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusCompleted,
+		},
+		{
+			name:  "wait elapses before final status",
+			query: "?wait=1s",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(pending, nil).Twice()
+				m.On("AwaitTransfer", mock.Anything, "t-1").Return(nil, context.DeadlineExceeded)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusPending,
+		},
+		{
+			name:  "wait for a final transfer",
+			query: "?wait=1s",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(completed, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusCompleted,
+		},
+		{
+			name:       "invalid wait",
+			query:      "?wait=soon",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name: "transfer not found",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(nil, transfererrors.ErrTransferNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeTransferNotFound,
+		},
+		{
+			name:       "missing api key",
+			apiKey:     "-",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name: "transfer to an account of the principal",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(received, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusCompleted,
+		},
+		{
+			name:  "transfer between accounts of other principals",
+			query: "?wait=1s",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(others, nil)
+			},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:   "administrator",
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetTransfer", mock.Anything, "t-1").Return(others, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantState:  models.TransferStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupRouter(t, mockService)
+
+			req := httptest.NewRequest("GET", "/api/v1/transfers/t-1"+tt.query, nil)
+			switch tt.apiKey {
+			case "":
+				req.Header.Set("X-API-Key", "mark-key")
+			case "-":
+			default:
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.NewDecoder(w.Body).Decode(&response)
+			require.NoError(t, err)
+
+			if tt.wantCode != "" {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				assert.Equal(t, string(tt.wantState), response["status"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestBalanceHandler_GetBalance(t *testing.T) {
 	tests := []struct {
 		name        string
 		accountID   string
+		query       string
+		apiKey      string
 		setupMock   func(*mocks.BankServiceMock)
 		wantStatus  int
 		wantBalance float64
-		wantError   string
+		wantCode    string
 	}{
 		{
 			name:      "get existing account",
@@ -179,11 +582,74 @@ func TestBalanceHandler_GetBalance(t *testing.T) {
 		{
 			name:      "get non-existing account",
 			accountID: "NonExistent",
+			apiKey:    "admin-key",
 			setupMock: func(m *mocks.BankServiceMock) {
 				m.On("GetBalance", mock.Anything, "NonExistent").Return(0.0, transfererrors.ErrAccountNotFound)
 			},
 			wantStatus: http.StatusNotFound,
-			wantError:  transfererrors.ErrAccountNotFound.Error(),
+			wantCode:   problem.CodeAccountNotFound,
+		},
+		{
+			name:       "malformed account id",
+			accountID:  "Mark.Smith",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:       "missing api key",
+			accountID:  "Mark",
+			query:      "?as_of=2024-01-31T23:59:59Z",
+			apiKey:     "-",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "account of another principal",
+			accountID:  "Jane",
+			query:      "?as_of=2024-01-31T23:59:59Z",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:      "administrator",
+			accountID: "Jane",
+			apiKey:    "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetBalance", mock.Anything, "Jane").Return(50.0, nil)
+			},
+			wantStatus:  http.StatusOK,
+			wantBalance: 50.0,
+		},
+		{
+			name:      "balance as of a past time",
+			accountID: "Mark",
+			query:     "?as_of=2024-01-31T23:59:59Z",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("BalanceAt", mock.Anything, "Mark", time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)).Return(60.0, nil)
+			},
+			wantStatus:  http.StatusOK,
+			wantBalance: 60.0,
+		},
+		{
+			name:       "malformed as of",
+			accountID:  "Mark",
+			query:      "?as_of=2024-01-31",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:      "as of in the future",
+			accountID: "Mark",
+			query:     "?as_of=2999-01-01T00:00:00Z",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("BalanceAt", mock.Anything, "Mark", mock.Anything).Return(0.0, transfererrors.ErrFutureTime)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeFutureTime,
 		},
 		{
 			name:      "internal error",
@@ -192,7 +658,7 @@ func TestBalanceHandler_GetBalance(t *testing.T) {
 				m.On("GetBalance", mock.Anything, "Mark").Return(0.0, assert.AnError)
 			},
 			wantStatus: http.StatusInternalServerError,
-			wantError:  "internal server error",
+			wantCode:   problem.CodeInternal,
 		},
 	}
 
@@ -202,9 +668,16 @@ func TestBalanceHandler_GetBalance(t *testing.T) {
 			mockService := new(mocks.BankServiceMock)
 			tt.setupMock(mockService)
 
-			router := setupRouter(mockService)
+			router := setupRouter(t, mockService)
 
-			req := httptest.NewRequest("GET", "/api/v1/balance/"+tt.accountID, nil)
+			req := httptest.NewRequest("GET", "/api/v1/balance/"+tt.accountID+tt.query, nil)
+			switch tt.apiKey {
+			case "":
+				req.Header.Set("X-API-Key", "mark-key")
+			case "-":
+			default:
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
 			w := httptest.NewRecorder()
 
 			router.ServeHTTP(w, req)
@@ -215,8 +688,9 @@ func TestBalanceHandler_GetBalance(t *testing.T) {
 			err := json.NewDecoder(w.Body).Decode(&response)
 			require.NoError(t, err)
 
-			if tt.wantError != "" {
-				assert.Equal(t, tt.wantError, response["error"])
+			if tt.wantCode != "" {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantCode, response["code"])
 			} else {
 				assert.Equal(t, tt.wantBalance, response["balance"])
 			}
@@ -226,3 +700,2129 @@ func TestBalanceHandler_GetBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestBalanceHandler_GetBalances(t *testing.T) {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin,audit-key:ada:auditor")
+	require.NoError(t, err)
+	monthEnd := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		query      string
+		apiKey     string
+		setupMock  func(*mocks.BankServiceMock)
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name:   "balances of many accounts at once",
+			query:  "?accounts=Mark,Jane,Ghost&as_of=2024-02-01T00:00:00Z",
+			apiKey: "audit-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("BalancesAt", mock.Anything, []string{"Mark", "Jane", "Ghost"}, monthEnd).Return(&models.BalanceSnapshot{
+					AsOf:     monthEnd,
+					Balances: []models.AccountBalance{{AccountID: "Jane", Balance: 90}, {AccountID: "Mark", Balance: 60}},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "current balances by default",
+			query:  "?accounts=Mark",
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("BalancesAt", mock.Anything, []string{"Mark"}, mock.AnythingOfType("time.Time")).
+					Return(&models.BalanceSnapshot{Balances: []models.AccountBalance{}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing accounts and malformed as of",
+			query:      "?as_of=yesterday",
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"accounts", "as_of"},
+		},
+		{
+			name:       "malformed account ids",
+			query:      "?accounts=Mark,,Jane.Doe",
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"accounts[1]", "accounts[2]"},
+		},
+		{
+			name:       "too many accounts",
+			query:      "?accounts=" + strings.Repeat("Mark,", maxBalanceAccounts) + "Jane",
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"accounts"},
+		},
+		{
+			name:       "customer",
+			query:      "?accounts=Mark",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "missing api key",
+			query:      "?accounts=Mark",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:   mockService,
+				Authenticator: apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest("GET", "/api/v1/balances"+tt.query, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantFields != nil {
+				var response problem.Problem
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				var fields []string
+				for _, fieldErr := range response.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				assert.Equal(t, tt.wantFields, fields)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+	handlersFactory := NewFactory(&HandlerConfig{BankService: mockService, Metrics: metrics.New(), Authenticator: apiKeys})
+	router := testutil.SetupTestRouter(handlersFactory.CreateHandlers())
+
+	for _, path := range []string{"/api/v1/balance/Mark", "/api/v1/balance/Mark", "/nowhere"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-API-Key", "mark-key")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `money_transfer_http_requests_total{method="GET",route="/api/v1/balance/:account",status="200"} 2`)
+	assert.Contains(t, body, `money_transfer_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `money_transfer_http_request_duration_seconds_count{method="GET",route="/api/v1/balance/:account",status="200"} 2`)
+	mockService.AssertExpectations(t)
+}
+
+func TestRateLimitHandler(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 10}).
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+
+	rules, err := ratelimit.ParseRules("POST /api/v1/transfer account=1/m")
+	require.NoError(t, err)
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+	collector := metrics.New()
+	handlersFactory := NewFactory(&HandlerConfig{
+		BankService:   mockService,
+		Metrics:       collector,
+		Authenticator: apiKeys,
+		RateLimit:     RateLimitConfig{Limiter: ratelimit.NewMemory(), Rules: rules},
+	})
+	router := testutil.SetupTestRouter(handlersFactory.CreateHandlers())
+
+	for _, wantStatus := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("POST", "/api/v1/transfer", strings.NewReader(`{"from":"Mark","to":"Jane","amount":10}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "mark-key")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, wantStatus, w.Code)
+	}
+
+	// Rejected requests are counted by the metrics middleware installed before rate limiting
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, w.Body.String(),
+		`money_transfer_http_requests_total{method="POST",route="/api/v1/transfer",status="429"} 1`)
+	mockService.AssertExpectations(t)
+}
+
+func TestTransferThrottle(t *testing.T) {
+	transferReq := models.TransferRequest{From: "Mark", To: "Jane", Amount: 10}
+
+	// setup serves the API with one transfer from Mark allowed per minute
+	setup := func(t *testing.T) (*gin.Engine, *mocks.BankServiceMock) {
+		mockService := new(mocks.BankServiceMock)
+		rules, err := ratelimit.ParseRules("POST /api/v1/transfer account=1/m")
+		require.NoError(t, err)
+		apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+		require.NoError(t, err)
+		router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+			BankService:   mockService,
+			Authenticator: apiKeys,
+			RateLimit:     RateLimitConfig{Limiter: ratelimit.NewMemory(), Rules: rules},
+		}).CreateHandlers())
+		return router, mockService
+	}
+
+	// transferOverGraphQL runs the transfer mutation and returns the code of its error, if any
+	transferOverGraphQL := func(t *testing.T, router *gin.Engine) string {
+		body := `{"query": "mutation { transfer(from: \"Mark\", to: \"Jane\", amount: 10) { id } }"}`
+		req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "mark-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var result struct {
+			Errors []struct {
+				Extensions map[string]any `json:"extensions"`
+			} `json:"errors"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		if len(result.Errors) == 0 {
+			return ""
+		}
+		return result.Errors[0].Extensions["code"].(string)
+	}
+
+	t.Run("graphql", func(t *testing.T) {
+		router, mockService := setup(t)
+		mockService.On("Transfer", mock.Anything, transferReq).
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+
+		assert.Empty(t, transferOverGraphQL(t, router))
+		assert.Equal(t, "RATE_LIMITED", transferOverGraphQL(t, router))
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		router, mockService := setup(t)
+		mockService.On("Transfer", mock.Anything, transferReq).
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+		conn := dialWebSocketRouter(t, router, "mark-key")
+		request := `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":10}}`
+
+		response := callWebSocket(t, conn, request)
+		assert.Nil(t, response["error"])
+
+		response = callWebSocket(t, conn, request)
+		rpcErr := response["error"].(map[string]any)
+		assert.Equal(t, float64(rpcRateLimited), rpcErr["code"])
+		assert.Equal(t, 60.0, rpcErr["data"].(map[string]any)["retry_after"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("shares the budget of the transfer route", func(t *testing.T) {
+		router, mockService := setup(t)
+		mockService.On("Transfer", mock.Anything, transferReq).
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+
+		req := httptest.NewRequest("POST", "/api/v1/transfer", strings.NewReader(`{"from":"Mark","to":"Jane","amount":10}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-API-Key", "mark-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		assert.Equal(t, "RATE_LIMITED", transferOverGraphQL(t, router))
+		mockService.AssertExpectations(t)
+	})
+}
+
+func TestHealthHandler(t *testing.T) {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin")
+	require.NoError(t, err)
+
+	var schemaErr error
+	checker := health.NewChecker(health.Config{
+		Timeout: 50 * time.Millisecond,
+		Checks: map[string]health.Check{
+			"schema": func(context.Context) error { return schemaErr },
+			"slow": func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+	})
+	router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+		Authenticator: apiKeys,
+		Health:        health.NewChecker(health.Config{}),
+	}).CreateHandlers())
+	failingRouter := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+		Authenticator: apiKeys,
+		Health:        checker,
+	}).CreateHandlers())
+
+	serve := func(router *gin.Engine, path, apiKey string) (*httptest.ResponseRecorder, map[string]any) {
+		req := httptest.NewRequest("GET", path, nil)
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+		return w, body
+	}
+
+	t.Run("liveness ignores dependencies", func(t *testing.T) {
+		w, body := serve(failingRouter, "/healthz", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", body["status"])
+	})
+
+	t.Run("ready", func(t *testing.T) {
+		w, body := serve(router, "/readyz", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", body["status"])
+	})
+
+	t.Run("failing and timed out checks", func(t *testing.T) {
+		schemaErr = errors.New("schema not applied, missing tables: transfers")
+		w, body := serve(failingRouter, "/readyz", "")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, "unavailable", body["status"])
+
+		checks := body["checks"].(map[string]any)
+		assert.Equal(t, schemaErr.Error(), checks["schema"].(map[string]any)["error"])
+		assert.Equal(t, context.DeadlineExceeded.Error(), checks["slow"].(map[string]any)["error"])
+	})
+
+	t.Run("details require admin", func(t *testing.T) {
+		w, _ := serve(router, "/health/details", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w, body := serve(router, "/health/details", "mark-key")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, problem.CodeForbidden, body["code"])
+
+		w, body = serve(router, "/health/details", "admin-key")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "ok", body["status"])
+		assert.Contains(t, body, "uptime_seconds")
+		assert.Equal(t, "dev", body["build"].(map[string]any)["version"])
+	})
+
+	t.Run("draining", func(t *testing.T) {
+		checker := health.NewChecker(health.Config{})
+		router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{Health: checker}).CreateHandlers())
+		checker.Drain()
+
+		w, body := serve(router, "/readyz", "")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, true, body["draining"])
+
+		w, _ = serve(router, "/healthz", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func setupWebhookRouter(t *testing.T, webhookService *mocks.WebhookServiceMock) *gin.Engine {
+	t.Helper()
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin")
+	require.NoError(t, err)
+	handlersFactory := NewFactory(&HandlerConfig{WebhookService: webhookService, Authenticator: apiKeys})
+	appHandlers := handlersFactory.CreateHandlers()
+	return testutil.SetupTestRouter(appHandlers)
+}
+
+func TestWebhookHandler_CreateSubscription(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    models.WebhookSubscriptionRequest
+		setupMock  func(*mocks.WebhookServiceMock)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:    "subscription created",
+			request: models.WebhookSubscriptionRequest{URL: "https://partner.example/hooks"},
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("CreateSubscription", mock.Anything, models.WebhookSubscriptionRequest{
+					URL: "https://partner.example/hooks",
+				}).Return(&models.WebhookSubscription{ID: "w-1", Secret: "whsec_1"}, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:    "invalid url",
+			request: models.WebhookSubscriptionRequest{URL: "/hooks"},
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("CreateSubscription", mock.Anything, models.WebhookSubscriptionRequest{
+					URL: "/hooks",
+				}).Return(nil, transfererrors.ErrInvalidWebhookURL)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidWebhookURL,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.WebhookServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupWebhookRouter(t, mockService)
+
+			body, err := json.Marshal(tt.request)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("POST", "/api/v1/webhooks", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", "admin-key")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+
+			if tt.wantCode != "" {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				assert.Equal(t, "whsec_1", response["secret"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_Deliveries(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		setupMock  func(*mocks.WebhookServiceMock)
+		wantStatus int
+	}{
+		{
+			name:   "list deliveries",
+			method: "GET",
+			path:   "/api/v1/webhooks/w-1/deliveries?limit=10",
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("ListDeliveries", mock.Anything, "w-1", 10).
+					Return([]*models.WebhookDelivery{{ID: "d-1"}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid limit",
+			method:     "GET",
+			path:       "/api/v1/webhooks/w-1/deliveries?limit=0",
+			setupMock:  func(_ *mocks.WebhookServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown subscription",
+			method: "GET",
+			path:   "/api/v1/webhooks/w-9/deliveries",
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("ListDeliveries", mock.Anything, "w-9", defaultDeliveryLimit).
+					Return(nil, transfererrors.ErrWebhookNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:   "redeliver",
+			method: "POST",
+			path:   "/api/v1/webhooks/w-1/deliveries/d-1/redeliver",
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("Redeliver", mock.Anything, "w-1", "d-1").
+					Return(&models.WebhookDelivery{ID: "d-1", Status: models.WebhookDeliveryPending}, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:   "delete subscription",
+			method: "DELETE",
+			path:   "/api/v1/webhooks/w-1",
+			setupMock: func(m *mocks.WebhookServiceMock) {
+				m.On("DeleteSubscription", mock.Anything, "w-1").Return(nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.WebhookServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupWebhookRouter(t, mockService)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-API-Key", "admin-key")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebhookHandler_RequiresAdmin(t *testing.T) {
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{"POST", "/api/v1/webhooks"},
+		{"GET", "/api/v1/webhooks"},
+		{"GET", "/api/v1/webhooks/w-1"},
+		{"DELETE", "/api/v1/webhooks/w-1"},
+		{"GET", "/api/v1/webhooks/w-1/deliveries"},
+		{"POST", "/api/v1/webhooks/w-1/deliveries/d-1/redeliver"},
+	}
+
+	for _, route := range routes {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			// The service mock fails the test if any call reaches it
+			router := setupWebhookRouter(t, new(mocks.WebhookServiceMock))
+
+			for apiKey, want := range map[string]struct {
+				status int
+				code   string
+			}{
+				"":         {http.StatusUnauthorized, problem.CodeUnauthenticated},
+				"bad-key":  {http.StatusUnauthorized, problem.CodeUnauthenticated},
+				"mark-key": {http.StatusForbidden, problem.CodeForbidden},
+			} {
+				req := httptest.NewRequest(route.method, route.path, strings.NewReader(`{"url":"https://partner.example/hooks"}`))
+				req.Header.Set("Content-Type", "application/json")
+				if apiKey != "" {
+					req.Header.Set("X-API-Key", apiKey)
+				}
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, want.status, w.Code, "api key %q", apiKey)
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, want.code, response["code"], "api key %q", apiKey)
+			}
+		})
+	}
+}
+
+func TestAuditHandler_ListEvents(t *testing.T) {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin,audit-key:ada:auditor")
+	require.NoError(t, err)
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		query      string
+		setupMock  func(*mocks.AuditServiceMock)
+		wantStatus int
+		wantFields []string
+	}{
+		{
+			name:   "auditor with filters",
+			apiKey: "audit-key",
+			query:  "?actor=mark&action=transfer.created&account=Mark&transfer=t-1&since=2024-01-01T00:00:00Z&after=7&limit=20",
+			setupMock: func(m *mocks.AuditServiceMock) {
+				m.On("ListEvents", mock.Anything, models.AuditFilter{
+					Actor: "mark", Action: models.AuditTransferCreated, AccountID: "Mark", TransferID: "t-1",
+					Since: since, AfterSequence: 7, Limit: 20,
+				}).Return(&models.AuditPage{Events: []*models.AuditEvent{{Sequence: 8}}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "admin with defaults",
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.AuditServiceMock) {
+				m.On("ListEvents", mock.Anything, models.AuditFilter{Limit: defaultAuditLimit}).
+					Return(&models.AuditPage{Events: []*models.AuditEvent{}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "limit capped",
+			apiKey: "audit-key",
+			query:  "?limit=100000",
+			setupMock: func(m *mocks.AuditServiceMock) {
+				m.On("ListEvents", mock.Anything, models.AuditFilter{Limit: maxAuditLimit}).
+					Return(&models.AuditPage{Events: []*models.AuditEvent{}}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid filters",
+			apiKey:     "audit-key",
+			query:      "?action=account.deleted&since=yesterday&after=-1&limit=0",
+			setupMock:  func(_ *mocks.AuditServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"action", "since", "after", "limit"},
+		},
+		{
+			name:       "customer",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.AuditServiceMock) {},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "anonymous",
+			setupMock:  func(_ *mocks.AuditServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.AuditServiceMock)
+			tt.setupMock(mockService)
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				AuditService:  mockService,
+				Authenticator: apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest("GET", "/api/v1/audit"+tt.query, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantFields != nil {
+				var response problem.Problem
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				var fields []string
+				for _, fieldErr := range response.Errors {
+					fields = append(fields, fieldErr.Field)
+				}
+				assert.Equal(t, tt.wantFields, fields)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func setupEventsRouter(t *testing.T, bankService *mocks.BankServiceMock) *gin.Engine {
+	return setupStoppingEventsRouter(t, bankService, nil)
+}
+
+// setupStoppingEventsRouter sets up the streaming handlers to end their streams when stopping is closed
+func setupStoppingEventsRouter(t *testing.T, bankService *mocks.BankServiceMock, stopping <-chan struct{}) *gin.Engine {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin")
+	require.NoError(t, err)
+
+	handlersFactory := NewFactory(&HandlerConfig{
+		BankService:        bankService,
+		Authenticator:      apiKeys,
+		Stopping:           stopping,
+		StreamPollInterval: 10 * time.Millisecond,
+		StreamHeartbeat:    time.Minute,
+	})
+	return testutil.SetupTestRouter(handlersFactory.CreateHandlers())
+}
+
+func TestAccountEventsHandler_Rejects(t *testing.T) {
+	tests := []struct {
+		name        string
+		accountID   string
+		apiKey      string
+		lastEventID string
+		setupMock   func(*mocks.BankServiceMock)
+		wantStatus  int
+		wantCode    string
+	}{
+		{
+			name:       "missing api key",
+			accountID:  "Mark",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "unknown api key",
+			accountID:  "Mark",
+			apiKey:     "stolen-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "account of another principal",
+			accountID:  "Jane",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:        "invalid last event id",
+			accountID:   "Mark",
+			apiKey:      "mark-key",
+			lastEventID: "abc",
+			setupMock:   func(_ *mocks.BankServiceMock) {},
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    problem.CodeValidationFailed,
+		},
+		{
+			name:      "account not found",
+			accountID: "NonExistent",
+			apiKey:    "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetBalance", mock.Anything, "NonExistent").Return(0.0, transfererrors.ErrAccountNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupEventsRouter(t, mockService)
+
+			req := httptest.NewRequest("GET", "/api/v1/accounts/"+tt.accountID+"/events", nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantCode, response["code"])
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountEventsHandler_Stream(t *testing.T) {
+	balance := 60.0
+	activities := []*models.AccountActivity{
+		{Sequence: 6, Type: models.EventTransferCreated, AccountID: "Mark", Direction: models.DirectionOutgoing},
+		{Sequence: 7, Type: models.EventTransferCompleted, AccountID: "Mark", Direction: models.DirectionOutgoing,
+			Balance: &balance},
+	}
+
+	tests := []struct {
+		name      string
+		setHeader func(*http.Request)
+		setupMock func(*mocks.BankServiceMock)
+	}{
+		{
+			name: "resumes after Last-Event-ID",
+			setHeader: func(req *http.Request) {
+				req.Header.Set("Authorization", "Bearer mark-key")
+				req.Header.Set("Last-Event-ID", "5")
+			},
+			setupMock: func(_ *mocks.BankServiceMock) {},
+		},
+		{
+			name: "starts at the latest event",
+			setHeader: func(req *http.Request) {
+				req.Header.Set("X-API-Key", "mark-key")
+			},
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("LatestActivitySequence", mock.Anything).Return(int64(5), nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+			mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+			mockService.On("AccountActivity", mock.Anything, "Mark", int64(5), service.ActivityBatchSize).
+				Return(activities, nil).Once()
+			mockService.On("AccountActivity", mock.Anything, "Mark", int64(7), service.ActivityBatchSize).
+				Return([]*models.AccountActivity{}, nil).Maybe()
+
+			server := httptest.NewServer(setupEventsRouter(t, mockService))
+			defer server.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			req, err := http.NewRequestWithContext(ctx, "GET", server.URL+"/api/v1/accounts/Mark/events", nil)
+			require.NoError(t, err)
+			tt.setHeader(req)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+			// Read until the blank line closing the second event
+			var lines []string
+			scanner := bufio.NewScanner(resp.Body)
+			for blanks := 0; blanks < 2 && scanner.Scan(); {
+				if scanner.Text() == "" {
+					blanks++
+					continue
+				}
+				lines = append(lines, scanner.Text())
+			}
+			require.NoError(t, scanner.Err())
+
+			require.Len(t, lines, 6)
+			assert.Equal(t, "id:6", lines[0])
+			assert.Equal(t, "event:TransferCreated", lines[1])
+			assert.Equal(t, "id:7", lines[3])
+			assert.Equal(t, "event:TransferCompleted", lines[4])
+
+			var activity models.AccountActivity
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(lines[5], "data:")), &activity))
+			assert.Equal(t, models.DirectionOutgoing, activity.Direction)
+			require.NotNil(t, activity.Balance)
+			assert.Equal(t, 60.0, *activity.Balance)
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccountEventsHandler_EndsWhenStopping(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(5), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{}, nil)
+
+	stopping := make(chan struct{})
+	server := httptest.NewServer(setupStoppingEventsRouter(t, mockService, stopping))
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/api/v1/accounts/Mark/events", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-API-Key", "mark-key")
+	req.Header.Set("Last-Event-ID", "5")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	close(stopping)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, resp.Body)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream did not end after the server started stopping")
+	}
+}
+
+func TestStatementHandler_GetStatement(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	statement := &models.Statement{
+		AccountID:      "Mark",
+		Currency:       models.Currency,
+		From:           jan,
+		To:             feb,
+		OpeningBalance: 100,
+		ClosingBalance: 100,
+		Lines:          []models.StatementLine{},
+	}
+
+	tests := []struct {
+		name            string
+		accountID       string
+		query           string
+		apiKey          string
+		setupMock       func(*mocks.BankServiceMock)
+		wantStatus      int
+		wantCode        string
+		wantContentType string
+		wantFilename    string
+	}{
+		{
+			name:      "json with a day ending the period included in full",
+			accountID: "Mark",
+			query:     "?from=2024-01-01&to=2024-01-31",
+			apiKey:    "mark-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "Mark", jan, feb).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/json; charset=utf-8",
+		},
+		{
+			name:      "csv with rfc 3339 bounds",
+			accountID: "Mark",
+			query:     "?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&format=csv",
+			apiKey:    "mark-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "Mark", jan, feb).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/csv; charset=utf-8",
+			wantFilename:    `attachment; filename="statement-Mark-20240101-20240201.csv"`,
+		},
+		{
+			name:      "pdf of any account for admins",
+			accountID: "Mark",
+			query:     "?from=2024-01-01&to=2024-01-31&format=pdf",
+			apiKey:    "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "Mark", jan, feb).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/pdf",
+			wantFilename:    `attachment; filename="statement-Mark-20240101-20240201.pdf"`,
+		},
+		{
+			name:      "camt.053",
+			accountID: "Mark",
+			query:     "?from=2024-01-01&to=2024-01-31&format=camt053",
+			apiKey:    "mark-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "Mark", jan, feb).Return(statement, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/xml; charset=utf-8",
+			wantFilename:    `attachment; filename="statement-Mark-20240101-20240201.xml"`,
+		},
+		{
+			name:       "missing api key",
+			accountID:  "Mark",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "account of another principal",
+			accountID:  "Jane",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "malformed bounds and format",
+			accountID:  "Mark",
+			query:      "?from=yesterday&format=xlsx",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:      "reversed period",
+			accountID: "Mark",
+			query:     "?from=2024-02-01&to=2024-01-01T00:00:00Z",
+			apiKey:    "mark-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "Mark", feb, jan).Return(nil, transfererrors.ErrInvalidPeriod)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidPeriod,
+		},
+		{
+			name:      "account not found",
+			accountID: "NonExistent",
+			query:     "?from=2024-01-01&to=2024-01-31",
+			apiKey:    "admin-key",
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Statement", mock.Anything, "NonExistent", jan, feb).Return(nil, transfererrors.ErrAccountNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupEventsRouter(t, mockService)
+
+			req := httptest.NewRequest("GET", "/api/v1/accounts/"+tt.accountID+"/statements"+tt.query, nil)
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+				assert.Equal(t, tt.wantFilename, w.Header().Get("Content-Disposition"))
+				assert.NotEmpty(t, w.Body.Bytes())
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// dialWebSocket opens a WebSocket connection to the API with the given API key
+func dialWebSocket(t *testing.T, bankService *mocks.BankServiceMock, apiKey string) *websocket.Conn {
+	t.Helper()
+	return dialWebSocketRouter(t, setupEventsRouter(t, bankService), apiKey)
+}
+
+// dialWebSocketRouter opens a WebSocket connection to router with the given API key
+func dialWebSocketRouter(t *testing.T, router *gin.Engine, apiKey string) *websocket.Conn {
+	t.Helper()
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	header := http.Header{}
+	header.Set("X-API-Key", apiKey)
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/ws", header)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// callWebSocket sends a JSON-RPC request and returns the decoded response
+func callWebSocket(t *testing.T, conn *websocket.Conn, request string) map[string]any {
+	t.Helper()
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(request)))
+
+	var response map[string]any
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&response))
+	return response
+}
+
+func TestWebSocketHandler_Calls(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    string
+		setupMock  func(*mocks.BankServiceMock)
+		wantResult map[string]any
+		wantCode   float64
+	}{
+		{
+			name:    "get balance",
+			request: `{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"account":"Mark"}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+			},
+			wantResult: map[string]any{"balance": 100.0},
+		},
+		{
+			name:    "transfer",
+			request: `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":50}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 50}).
+					Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil)
+			},
+			wantResult: map[string]any{"success": true, "transfer_id": "t-1", "status": "completed"},
+		},
+		{
+			name:    "async transfer",
+			request: `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":5,"async":true}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("SubmitTransfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 5}).
+					Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusPending}, nil)
+			},
+			wantResult: map[string]any{"success": true, "transfer_id": "t-2", "status": "pending"},
+		},
+		{
+			name:    "insufficient funds",
+			request: `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":500}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 500}).
+					Return(nil, transfererrors.ErrInsufficientFunds)
+			},
+			wantCode: rpcRejected,
+		},
+		{
+			name:    "held transfer",
+			request: `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":60}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 60}).
+					Return(&models.Transfer{ID: "t-3", Status: models.TransferStatusHeld}, transfererrors.ErrTransferHeld)
+			},
+			wantResult: map[string]any{
+				"success": true, "transfer_id": "t-3", "status": "held", "message": transfererrors.ErrTransferHeld.Error(),
+			},
+		},
+		{
+			name:    "blocked transfer",
+			request: `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":70}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 70}).
+					Return(&models.Transfer{ID: "t-4", Status: models.TransferStatusFailed}, transfererrors.ErrTransferBlocked)
+			},
+			wantCode: rpcForbidden,
+		},
+		{
+			name:      "transfer from another principal's account",
+			request:   `{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Jane","to":"Mark","amount":5}}`,
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  rpcForbidden,
+		},
+		{
+			name:      "unknown method",
+			request:   `{"jsonrpc":"2.0","id":1,"method":"withdraw","params":{}}`,
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  rpcMethodNotFound,
+		},
+		{
+			name:      "invalid params",
+			request:   `{"jsonrpc":"2.0","id":1,"method":"getBalance","params":{"acount":"Mark"}}`,
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  rpcInvalidParams,
+		},
+		{
+			name:      "malformed message",
+			request:   `{"jsonrpc":`,
+			setupMock: func(_ *mocks.BankServiceMock) {},
+			wantCode:  rpcParseError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			conn := dialWebSocket(t, mockService, "mark-key")
+			response := callWebSocket(t, conn, tt.request)
+
+			if tt.wantCode != 0 {
+				rpcErr, ok := response["error"].(map[string]any)
+				require.True(t, ok, "expected an error response, got %v", response)
+				assert.Equal(t, tt.wantCode, rpcErr["code"])
+			} else {
+				assert.Equal(t, tt.wantResult, response["result"])
+			}
+
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// paymentInitiation is a pain.001 message in which Mark pays Jane 40.5
+const paymentInitiation = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"><CstmrCdtTrfInitn>
+	<GrpHdr><MsgId>PAYROLL-2024-02</MsgId><CreDtTm>2024-02-01T09:30:00Z</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>
+	<PmtInf><PmtInfId>P1</PmtInfId><PmtMtd>TRF</PmtMtd><ReqdExctnDt><Dt>2024-02-01</Dt></ReqdExctnDt>
+		<DbtrAcct><Id><Othr><Id>Mark</Id></Othr></Id></DbtrAcct>
+		<CdtTrfTxInf><PmtId><EndToEndId>SALARY-JANE</EndToEndId></PmtId>
+			<Amt><InstdAmt Ccy="USD">40.50</InstdAmt></Amt><CdtrAcct><Id><Othr><Id>Jane</Id></Othr></Id></CdtrAcct>
+		</CdtTrfTxInf>
+	</PmtInf>
+</CstmrCdtTrfInitn></Document>`
+
+func TestPaymentInitiationHandler_InitiatePayments(t *testing.T) {
+	report := &iso20022.PaymentStatusReport{
+		MessageID:                    "7c9e6679742540de944be07fc1f90ae7",
+		OriginalMessageID:            "PAYROLL-2024-02",
+		OriginalNumberOfTransactions: 1,
+		GroupStatus:                  iso20022.StatusSettled,
+		Payments: []iso20022.PaymentStatus{{
+			OriginalPaymentID: "P1",
+			Status:            iso20022.StatusSettled,
+			Transactions: []iso20022.TransactionStatus{{
+				OriginalEndToEndID: "SALARY-JANE",
+				Status:             iso20022.StatusSettled,
+				TransferID:         "0b7ce4a4-5f4e-4d8c-9a41-3bd1a1b2c3d4",
+			}},
+		}},
+	}
+	isPayroll := mock.MatchedBy(func(msg *iso20022.CreditTransferInitiation) bool {
+		return msg.MessageID == "PAYROLL-2024-02" && msg.Payments[0].Transactions[0].Amount == 40.5
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		apiKey     string
+		setupMock  func(*mocks.BatchServiceMock)
+		wantStatus int
+		wantCode   string
+		wantBody   string
+	}{
+		{
+			name:   "executed",
+			body:   paymentInitiation,
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.BatchServiceMock) {
+				m.On("Execute", mock.Anything, isPayroll).Return(report, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "<AcctSvcrRef>0b7ce4a45f4e4d8c9a413bd1a1b2c3d4</AcctSvcrRef>",
+		},
+		{
+			name:       "missing api key",
+			body:       paymentInitiation,
+			setupMock:  func(_ *mocks.BatchServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "not a pain.001 message",
+			body:       `{"from":"Mark","to":"Jane","amount":40.5}`,
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.BatchServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidPaymentMessage,
+		},
+		{
+			name:   "too many transactions",
+			body:   paymentInitiation,
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.BatchServiceMock) {
+				m.On("Execute", mock.Anything, isPayroll).Return(nil, transfererrors.ErrInvalidPaymentMessage)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidPaymentMessage,
+		},
+		{
+			name:   "message ID reused",
+			body:   paymentInitiation,
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.BatchServiceMock) {
+				m.On("Execute", mock.Anything, isPayroll).Return(nil, transfererrors.ErrReferenceConflict)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   problem.CodeReferenceConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			batchService := new(mocks.BatchServiceMock)
+			tt.setupMock(batchService)
+
+			apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+			require.NoError(t, err)
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:   new(mocks.BankServiceMock),
+				BatchService:  batchService,
+				Authenticator: apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest("POST", "/api/v1/payment-initiations", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/xml")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			batchService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPayoutHandler(t *testing.T) {
+	payout := &models.Payout{
+		ID:           "p-1",
+		TransferID:   "t-1",
+		From:         "Mark",
+		Amount:       25,
+		Counterparty: models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "GB82WEST12345698765432"},
+		Rail:         "simulator",
+		Status:       models.PayoutStatusSubmitted,
+	}
+	payoutRequest := `{"from":"Mark","amount":25,"counterparty":{"scheme":"iban","identifier":"GB82 WEST 1234 5698 7654 32"}}`
+	settled := models.RailCallback{Reference: "SIM1", Status: models.PayoutStatusSettled}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		apiKey     string
+		key        string
+		setupMock  func(*mocks.PayoutServiceMock)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:   "pay",
+			method: "POST",
+			path:   "/api/v1/payouts",
+			body:   payoutRequest,
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("Pay", mock.Anything, mock.MatchedBy(func(req models.PayoutRequest) bool {
+					return req.From == "Mark" && req.Counterparty.Scheme == models.SchemeIBAN && req.IdempotencyKey == ""
+				})).Return(payout, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:   "pay with an idempotency key",
+			method: "POST",
+			path:   "/api/v1/payouts",
+			body:   payoutRequest,
+			apiKey: "mark-key",
+			key:    "payroll-2024-02-mark",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("Pay", mock.Anything, mock.MatchedBy(func(req models.PayoutRequest) bool {
+					return req.IdempotencyKey == "payroll-2024-02-mark"
+				})).Return(payout, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "pay with a too long idempotency key",
+			method:     "POST",
+			path:       "/api/v1/payouts",
+			body:       payoutRequest,
+			apiKey:     "mark-key",
+			key:        strings.Repeat("k", 129),
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:       "pay without api key",
+			method:     "POST",
+			path:       "/api/v1/payouts",
+			body:       payoutRequest,
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+		{
+			name:       "pay with invalid counterparty",
+			method:     "POST",
+			path:       "/api/v1/payouts",
+			body:       `{"from":"Mark","amount":25,"counterparty":{"scheme":"iban","identifier":"GB83WEST12345698765432"}}`,
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:   "pay through unknown rail",
+			method: "POST",
+			path:   "/api/v1/payouts",
+			body:   payoutRequest,
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("Pay", mock.Anything, mock.Anything).Return(nil, transfererrors.ErrUnknownRail)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeUnknownRail,
+		},
+		{
+			name:   "get payout",
+			method: "GET",
+			path:   "/api/v1/payouts/p-1",
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("GetPayout", mock.Anything, "p-1").Return(payout, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "get missing payout",
+			method: "GET",
+			path:   "/api/v1/payouts/p-2",
+			apiKey: "mark-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("GetPayout", mock.Anything, "p-2").Return(nil, transfererrors.ErrPayoutNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodePayoutNotFound,
+		},
+		{
+			name:   "callback from the rail",
+			method: "POST",
+			path:   "/api/v1/rails/simulator/callbacks",
+			body:   `{"reference":"SIM1","status":"settled"}`,
+			apiKey: "sim-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("HandleCallback", mock.Anything, "simulator", settled).Return(payout, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "callback from an administrator",
+			method: "POST",
+			path:   "/api/v1/rails/simulator/callbacks",
+			body:   `{"reference":"SIM1","status":"settled"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("HandleCallback", mock.Anything, "simulator", settled).Return(payout, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "callback from another rail",
+			method:     "POST",
+			path:       "/api/v1/rails/swift/callbacks",
+			body:       `{"reference":"SIM1","status":"settled"}`,
+			apiKey:     "sim-key",
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "callback from a customer",
+			method:     "POST",
+			path:       "/api/v1/rails/simulator/callbacks",
+			body:       `{"reference":"SIM1","status":"settled"}`,
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "callback with unknown outcome",
+			method:     "POST",
+			path:       "/api/v1/rails/simulator/callbacks",
+			body:       `{"reference":"SIM1","status":"pending"}`,
+			apiKey:     "sim-key",
+			setupMock:  func(_ *mocks.PayoutServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:   "callback contradicting the outcome",
+			method: "POST",
+			path:   "/api/v1/rails/simulator/callbacks",
+			body:   `{"reference":"SIM1","status":"settled"}`,
+			apiKey: "sim-key",
+			setupMock: func(m *mocks.PayoutServiceMock) {
+				m.On("HandleCallback", mock.Anything, "simulator", settled).Return(nil, transfererrors.ErrInvalidStatusTransition)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   problem.CodeInvalidStatusTransition,
+		},
+	}
+
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin,sim-key:simulator:rail")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payoutService := new(mocks.PayoutServiceMock)
+			tt.setupMock(payoutService)
+
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:   new(mocks.BankServiceMock),
+				PayoutService: payoutService,
+				Authenticator: apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			if tt.key != "" {
+				req.Header.Set("Idempotency-Key", tt.key)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				var response models.Payout
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, "p-1", response.ID)
+			}
+			payoutService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestACHHandler(t *testing.T) {
+	file := &models.ACHFile{ID: "f-1", IDModifier: "A", Entries: 2, Amount: 125.5}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		apiKey     string
+		setupMock  func(*mocks.ACHServiceMock)
+		wantStatus int
+		wantCode   string
+		wantBody   string
+	}{
+		{
+			name:   "create file",
+			method: "POST",
+			path:   "/api/v1/rails/ach/files",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("CreateFile", mock.Anything).Return(file, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantBody:   `"id":"f-1"`,
+		},
+		{
+			name:   "create file with nothing waiting",
+			method: "POST",
+			path:   "/api/v1/rails/ach/files",
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("CreateFile", mock.Anything).Return(nil, nil)
+			},
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:   "download file",
+			method: "GET",
+			path:   "/api/v1/rails/ach/files/f-1",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("WriteFile", mock.Anything, mock.Anything, "f-1").Return(nil).Run(func(args mock.Arguments) {
+					_, _ = args.Get(1).(io.Writer).Write([]byte("101 021000021"))
+				})
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "101 021000021",
+		},
+		{
+			name:   "download missing file",
+			method: "GET",
+			path:   "/api/v1/rails/ach/files/f-2",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("WriteFile", mock.Anything, mock.Anything, "f-2").Return(transfererrors.ErrACHFileNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeACHFileNotFound,
+		},
+		{
+			name:   "settle file",
+			method: "POST",
+			path:   "/api/v1/rails/ach/files/f-1/settlement",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("Settle", mock.Anything, "f-1").Return(&models.ACHSettlement{FileID: "f-1", Settled: 2}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"settled":2`,
+		},
+		{
+			name:   "process returns",
+			method: "POST",
+			path:   "/api/v1/rails/ach/returns",
+			body:   "101 011000015",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("ProcessReturns", mock.Anything, mock.Anything).Return([]models.ACHReturn{
+					{TraceNumber: "011000010000001", Code: "R01", Reason: "Insufficient funds"},
+				}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `"code":"R01"`,
+		},
+		{
+			name:   "process malformed returns",
+			method: "POST",
+			path:   "/api/v1/rails/ach/returns",
+			body:   "not a NACHA file",
+			apiKey: "ach-key",
+			setupMock: func(m *mocks.ACHServiceMock) {
+				m.On("ProcessReturns", mock.Anything, mock.Anything).Return(nil, transfererrors.ErrInvalidPaymentMessage)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidPaymentMessage,
+		},
+		{
+			name:       "create file as another rail",
+			method:     "POST",
+			path:       "/api/v1/rails/ach/files",
+			apiKey:     "sim-key",
+			setupMock:  func(_ *mocks.ACHServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "create file as a customer",
+			method:     "POST",
+			path:       "/api/v1/rails/ach/files",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.ACHServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "create file without api key",
+			method:     "POST",
+			path:       "/api/v1/rails/ach/files",
+			setupMock:  func(_ *mocks.ACHServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   problem.CodeUnauthenticated,
+		},
+	}
+
+	apiKeys, err := auth.ParseAPIKeys(
+		"mark-key:mark:customer:Mark,admin-key:admin:admin,sim-key:simulator:rail,ach-key:ach:rail")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			achService := new(mocks.ACHServiceMock)
+			tt.setupMock(achService)
+
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:   new(mocks.BankServiceMock),
+				PayoutService: new(mocks.PayoutServiceMock),
+				ACHService:    achService,
+				Authenticator: apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "text/plain")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+			achService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestWebSocketHandler_Subscribe(t *testing.T) {
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("GetBalance", mock.Anything, "Mark").Return(100.0, nil)
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(5), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{
+			{Sequence: 6, Type: models.EventTransferCompleted, AccountID: "Mark", Direction: models.DirectionIncoming},
+		}, nil).Once()
+	mockService.On("AccountActivity", mock.Anything, "Mark", int64(6), service.ActivityBatchSize).
+		Return([]*models.AccountActivity{}, nil).Maybe()
+
+	conn := dialWebSocket(t, mockService, "mark-key")
+
+	response := callWebSocket(t, conn,
+		`{"jsonrpc":"2.0","id":"s1","method":"subscribe","params":{"account":"Mark","last_event_id":5}}`)
+	assert.Equal(t, "s1", response["id"])
+	assert.Equal(t, map[string]any{"account": "Mark", "after": 5.0}, response["result"])
+
+	var notification struct {
+		Method string                 `json:"method"`
+		Params models.AccountActivity `json:"params"`
+	}
+	require.NoError(t, conn.ReadJSON(&notification))
+	assert.Equal(t, "activity", notification.Method)
+	assert.Equal(t, int64(6), notification.Params.Sequence)
+	assert.Equal(t, models.DirectionIncoming, notification.Params.Direction)
+
+	response = callWebSocket(t, conn, `{"jsonrpc":"2.0","id":"s2","method":"subscribe","params":{"account":"Mark"}}`)
+	rpcErr, ok := response["error"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, float64(rpcInvalidParams), rpcErr["code"])
+
+	response = callWebSocket(t, conn, `{"jsonrpc":"2.0","id":"s3","method":"unsubscribe","params":{"account":"Mark"}}`)
+	assert.Equal(t, map[string]any{"unsubscribed": true}, response["result"])
+}
+
+func TestWebSocketHandler_RequiresAuth(t *testing.T) {
+	server := httptest.NewServer(setupEventsRouter(t, new(mocks.BankServiceMock)))
+	defer server.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/ws", nil)
+	require.ErrorIs(t, err, websocket.ErrBadHandshake)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
+
+func TestWebSocketHandler_DrainsWhenStopping(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mockService := new(mocks.BankServiceMock)
+	mockService.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 50}).
+		Run(func(mock.Arguments) {
+			close(started)
+			<-release
+		}).
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil)
+
+	stopping := make(chan struct{})
+	conn := dialWebSocketRouter(t, setupStoppingEventsRouter(t, mockService, stopping), "mark-key")
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage,
+		[]byte(`{"jsonrpc":"2.0","id":1,"method":"transfer","params":{"from":"Mark","to":"Jane","amount":50}}`)))
+	<-started
+	close(stopping)
+	close(release)
+
+	// The transfer in flight is answered before the connection closes
+	var response map[string]any
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&response))
+	assert.Equal(t, map[string]any{"success": true, "transfer_id": "t-1", "status": "completed"}, response["result"])
+
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error %v", err)
+}
+
+func TestWebSocketSession_DisconnectsSlowClient(t *testing.T) {
+	session := &wsSession{send: make(chan []byte, 1)}
+	session.ctx, session.cancel = context.WithCancel(context.Background())
+
+	assert.True(t, session.notify("activity", &models.AccountActivity{Sequence: 1}))
+	assert.False(t, session.notify("activity", &models.AccountActivity{Sequence: 2}))
+
+	assert.Error(t, session.ctx.Err())
+	code, _ := session.closeStatus()
+	assert.Equal(t, websocket.CloseTryAgainLater, code)
+}
+
+func TestGraphQLHandler_Query(t *testing.T) {
+	tests := []struct {
+		name       string
+		apiKey     string
+		body       string
+		setupMock  func(*mocks.BankServiceMock)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "missing api key",
+			body:       `{"query": "{ account(id: \"Mark\") { balance } }"}`,
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "missing query",
+			apiKey:     "mark-key",
+			body:       `{"variables": {}}`,
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid query",
+			apiKey:     "mark-key",
+			body:       `{"query": "{ account(id: \"Mark\") { owner } }"}`,
+			setupMock:  func(_ *mocks.BankServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "successful query",
+			apiKey: "mark-key",
+			body:   `{"query": "query Balance($id: ID!) { account(id: $id) { id balance } }", "variables": {"id": "Mark"}}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("GetAccounts", mock.Anything, []string{"Mark"}).
+					Return([]*models.Account{{ID: "Mark", Balance: 100}}, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"data": {"account": {"id": "Mark", "balance": 100}}}`,
+		},
+		{
+			name:   "field errors are returned with partial data",
+			apiKey: "mark-key",
+			body:   `{"query": "mutation { transfer(from: \"Mark\", to: \"Jane\", amount: 1000) { id } }"}`,
+			setupMock: func(m *mocks.BankServiceMock) {
+				m.On("Transfer", mock.Anything, models.TransferRequest{From: "Mark", To: "Jane", Amount: 1000}).
+					Return(nil, transfererrors.ErrInsufficientFunds)
+			},
+			wantStatus: http.StatusOK,
+			wantBody: `{"data": {"transfer": null}, "errors": [{"message": "insufficient funds",
+				"locations": [{"line": 1, "column": 12}], "path": ["transfer"],
+				"extensions": {"code": "FAILED_PRECONDITION"}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(mocks.BankServiceMock)
+			tt.setupMock(mockService)
+
+			router := setupEventsRouter(t, mockService)
+
+			req := httptest.NewRequest("POST", "/api/v1/graphql", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFundingHandler(t *testing.T) {
+	deposit := &models.Funding{
+		ID: "f-1", Kind: models.FundingDeposit, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 40, TransferID: "t-1",
+	}
+	depositRequest := models.FundingRequest{Account: "Mark", Amount: 40, Reference: "dep-1"}
+
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		apiKey     string
+		setupMock  func(*mocks.FundingServiceMock)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:   "deposit",
+			path:   "/api/v1/deposits",
+			body:   `{"account":"Mark","amount":40,"reference":"dep-1"}`,
+			apiKey: "sim-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Deposit", mock.Anything, depositRequest).Return(deposit, true, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:   "replayed deposit",
+			path:   "/api/v1/deposits",
+			body:   `{"account":"Mark","amount":40,"reference":"dep-1"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Deposit", mock.Anything, depositRequest).Return(deposit, false, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "deposit held for sanctions review",
+			path:   "/api/v1/deposits",
+			body:   `{"account":"Mark","amount":40,"reference":"dep-1"}`,
+			apiKey: "sim-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				held := *deposit
+				held.ID = ""
+				m.On("Deposit", mock.Anything, depositRequest).Return(&held, true, transfererrors.ErrTransferHeld)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name:   "deposit reusing a reference",
+			path:   "/api/v1/deposits",
+			body:   `{"account":"Mark","amount":50,"reference":"dep-1"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Deposit", mock.Anything, mock.Anything).Return(nil, false, transfererrors.ErrReferenceConflict)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   problem.CodeReferenceConflict,
+		},
+		{
+			name:       "deposit by a customer",
+			path:       "/api/v1/deposits",
+			body:       `{"account":"Mark","amount":40,"reference":"dep-1"}`,
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.FundingServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "deposit without reference",
+			path:       "/api/v1/deposits",
+			body:       `{"account":"Mark","amount":40}`,
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.FundingServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:   "withdraw",
+			path:   "/api/v1/withdrawals",
+			body:   `{"account":"Mark","amount":40,"reference":"wd-1","system_account":"suspense"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Withdraw", mock.Anything, models.FundingRequest{
+					Account: "Mark", Amount: 40, Reference: "wd-1", SystemAccount: "suspense",
+				}).Return(deposit, true, nil)
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:   "withdraw into a customer account",
+			path:   "/api/v1/withdrawals",
+			body:   `{"account":"Mark","amount":40,"reference":"wd-1","system_account":"Jane"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Withdraw", mock.Anything, mock.Anything).Return(nil, false, transfererrors.ErrInvalidFundingAccount)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInvalidFundingAccount,
+		},
+		{
+			name:   "withdraw beyond the balance",
+			path:   "/api/v1/withdrawals",
+			body:   `{"account":"Mark","amount":400,"reference":"wd-2"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.FundingServiceMock) {
+				m.On("Withdraw", mock.Anything, mock.Anything).Return(nil, false, transfererrors.ErrInsufficientFunds)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeInsufficientFunds,
+		},
+	}
+
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin,sim-key:simulator:rail")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fundingService := new(mocks.FundingServiceMock)
+			tt.setupMock(fundingService)
+
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:    new(mocks.BankServiceMock),
+				FundingService: fundingService,
+				Authenticator:  apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", tt.apiKey)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else {
+				var response models.Funding
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, "t-1", response.TransferID)
+			}
+			fundingService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestScreeningHandler(t *testing.T) {
+	held := []*models.ScreeningDecision{{ID: "d-1", TransferID: "t-1", Outcome: models.ScreeningHold, Score: 0.9}}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		apiKey     string
+		setupMock  func(*mocks.ScreeningServiceMock)
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:   "list unreviewed decisions",
+			method: "GET",
+			path:   "/api/v1/screening/decisions?unreviewed=true&limit=10",
+			apiKey: "audit-key",
+			setupMock: func(m *mocks.ScreeningServiceMock) {
+				m.On("ListScreeningDecisions", mock.Anything, models.ScreeningFilter{Unreviewed: true, Limit: 10}).
+					Return(held, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "list blocked decisions",
+			method: "GET",
+			path:   "/api/v1/screening/decisions?outcome=block",
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.ScreeningServiceMock) {
+				m.On("ListScreeningDecisions", mock.Anything,
+					models.ScreeningFilter{Outcome: models.ScreeningBlock, Limit: defaultScreeningLimit}).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "invalid filter",
+			method:     "GET",
+			path:       "/api/v1/screening/decisions?outcome=maybe&unreviewed=sometimes&limit=0",
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.ScreeningServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:       "list by a customer",
+			method:     "GET",
+			path:       "/api/v1/screening/decisions",
+			apiKey:     "mark-key",
+			setupMock:  func(_ *mocks.ScreeningServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:   "release",
+			method: "POST",
+			path:   "/api/v1/screening/decisions/d-1/review",
+			body:   `{"resolution":"released","note":"different date of birth"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.ScreeningServiceMock) {
+				m.On("ReviewTransfer", mock.Anything, "d-1", models.ScreeningReview{
+					Resolution: models.ScreeningReleased, Note: "different date of birth",
+				}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "review by an auditor",
+			method:     "POST",
+			path:       "/api/v1/screening/decisions/d-1/review",
+			body:       `{"resolution":"released"}`,
+			apiKey:     "audit-key",
+			setupMock:  func(_ *mocks.ScreeningServiceMock) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   problem.CodeForbidden,
+		},
+		{
+			name:       "unknown resolution",
+			method:     "POST",
+			path:       "/api/v1/screening/decisions/d-1/review",
+			body:       `{"resolution":"ignored"}`,
+			apiKey:     "admin-key",
+			setupMock:  func(_ *mocks.ScreeningServiceMock) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   problem.CodeValidationFailed,
+		},
+		{
+			name:   "unknown decision",
+			method: "POST",
+			path:   "/api/v1/screening/decisions/d-9/review",
+			body:   `{"resolution":"rejected"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.ScreeningServiceMock) {
+				m.On("ReviewTransfer", mock.Anything, "d-9", mock.Anything).
+					Return(nil, transfererrors.ErrScreeningDecisionNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeScreeningDecisionNotFound,
+		},
+		{
+			name:   "already reviewed",
+			method: "POST",
+			path:   "/api/v1/screening/decisions/d-1/review",
+			body:   `{"resolution":"rejected"}`,
+			apiKey: "admin-key",
+			setupMock: func(m *mocks.ScreeningServiceMock) {
+				m.On("ReviewTransfer", mock.Anything, "d-1", mock.Anything).
+					Return(nil, transfererrors.ErrInvalidStatusTransition)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   problem.CodeInvalidStatusTransition,
+		},
+	}
+
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark,admin-key:admin:admin,audit-key:audit:auditor")
+	require.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			screeningService := new(mocks.ScreeningServiceMock)
+			tt.setupMock(screeningService)
+
+			router := testutil.SetupTestRouter(NewFactory(&HandlerConfig{
+				BankService:      new(mocks.BankServiceMock),
+				ScreeningService: screeningService,
+				Authenticator:    apiKeys,
+			}).CreateHandlers())
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-API-Key", tt.apiKey)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var response map[string]interface{}
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.Equal(t, tt.wantCode, response["code"])
+			} else if tt.method == "GET" {
+				var response []models.ScreeningDecision
+				require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+				assert.NotNil(t, response)
+			}
+			screeningService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler serves the liveness and readiness probes and the health details for operators
+type HealthHandler struct {
+	checker       *health.Checker
+	authenticator auth.Authenticator
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(cfg *HandlerConfig) *HealthHandler {
+	return &HealthHandler{
+		checker:       cfg.Health,
+		authenticator: cfg.Authenticator,
+	}
+}
+
+// Register registers handler routes; probes are served outside the API group
+func (h *HealthHandler) Register(_ *gin.RouterGroup) {}
+
+// RegisterRoot serves the probes at the paths orchestrators expect
+func (h *HealthHandler) RegisterRoot(router *gin.Engine) {
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+	router.GET("/health/details",
+		middleware.RequireAuth(h.authenticator), middleware.RequireRole(models.RoleAdmin), h.Details)
+}
+
+// Live reports that the process is up and serving HTTP; it checks no dependency,
+// so that a database outage does not get the service restarted
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Ready reports whether the service can take traffic: the database answers within the
+// timeout, the schema is applied and the service is not draining. Returns 503 otherwise.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+	c.JSON(statusOf(report), report)
+}
+
+// Details reports readiness along with uptime, build information and database pool statistics
+// Only admins may see it.
+func (h *HealthHandler) Details(c *gin.Context) {
+	details := h.checker.Details(c.Request.Context())
+	c.JSON(statusOf(details.Report), details)
+}
+
+// statusOf maps a readiness report to its HTTP status
+func statusOf(report health.Report) int {
+	if report.Status != health.StatusOK {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler instruments every request and serves the collected metrics to Prometheus
+type MetricsHandler struct {
+	collector *metrics.Collector
+}
+
+// NewMetricsHandler creates a new metrics handler
+func NewMetricsHandler(cfg *HandlerConfig) *MetricsHandler {
+	return &MetricsHandler{
+		collector: cfg.Metrics,
+	}
+}
+
+// Register registers handler routes; metrics are served outside the API group
+func (h *MetricsHandler) Register(_ *gin.RouterGroup) {}
+
+// Middleware records every request served
+func (h *MetricsHandler) Middleware() gin.HandlerFunc {
+	return middleware.Metrics(h.collector)
+}
+
+// RegisterRoot serves GET /metrics
+func (h *MetricsHandler) RegisterRoot(router *gin.Engine) {
+	router.GET("/metrics", gin.WrapH(h.collector.Handler()))
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/iso20022"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPaymentInitiationSize caps the size of pain.001 messages, which hold up to 1000 transactions
+const maxPaymentInitiationSize = 4 << 20
+
+// PaymentInitiationHandler executes ISO 20022 pain.001 payment batches
+type PaymentInitiationHandler struct {
+	batchService  service.BatchService
+	authenticator auth.Authenticator
+}
+
+// NewPaymentInitiationHandler creates a new payment initiation handler
+func NewPaymentInitiationHandler(cfg *HandlerConfig) *PaymentInitiationHandler {
+	return &PaymentInitiationHandler{
+		batchService:  cfg.BatchService,
+		authenticator: cfg.Authenticator,
+	}
+}
+
+// Register registers handler routes
+func (h *PaymentInitiationHandler) Register(group *gin.RouterGroup) {
+	group.POST("/payment-initiations", middleware.RequireAuth(h.authenticator), h.InitiatePayments)
+}
+
+// InitiatePayments godoc
+// @Summary Execute a pain.001 payment batch
+// @Description Reads an ISO 20022 pain.001 credit transfer initiation of any version, executes each of its
+// @Description transactions as a transfer from the debtor account of its payment instruction and returns a
+// @Description pain.002.001.11 status report. Accounts are identified by Othr/Id; the caller must own every
+// @Description debtor account. A message or instruction whose NbOfTxs or CtrlSum does not match its
+// @Description transactions is rejected as a whole, otherwise each transaction is reported on its own:
+// @Description ACSC with the transfer ID without hyphens as AcctSvcrRef, or RJCT with a reason code.
+// @Description A message sent again with the same MsgId is answered with its first report without executing
+// @Description anything; a transaction whose debtor account and EndToEndId were executed before is reported as
+// @Description it was then, or rejected with AM05 while it still executes.
+// @Tags transfer
+// @Accept application/xml
+// @Produce application/xml
+// @Param message body string true "pain.001 message"
+// @Success 200 {string} string "pain.002 status report"
+// @Failure 400 {object} problem.Problem "Malformed message or more than 1000 transactions"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 409 {object} problem.Problem "MsgId already used for a message with other contents"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /payment-initiations [post]
+func (h *PaymentInitiationHandler) InitiatePayments(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxPaymentInitiationSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.BadRequest(c, fmt.Sprintf("payment message must not exceed %d bytes", maxPaymentInitiationSize))
+			return
+		}
+		problem.BadRequest(c, "request body could not be read")
+		return
+	}
+
+	msg, err := iso20022.ParseCreditTransferInitiation(bytes.NewReader(body))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	report, err := h.batchService.Execute(c.Request.Context(), msg)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	var out bytes.Buffer
+	if err := iso20022.WritePaymentStatusReport(&out, report); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", out.Bytes())
+}
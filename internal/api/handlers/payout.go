@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayoutHandler handles payouts to external accounts and the callbacks of payment rails
+type PayoutHandler struct {
+	payoutService service.PayoutService
+	authenticator auth.Authenticator
+	maxBodySize   int64
+}
+
+// NewPayoutHandler creates a new payout handler
+func NewPayoutHandler(cfg *HandlerConfig) *PayoutHandler {
+	return &PayoutHandler{
+		payoutService: cfg.PayoutService,
+		authenticator: cfg.Authenticator,
+		maxBodySize:   cfg.MaxBodySize,
+	}
+}
+
+// Register registers handler routes
+func (h *PayoutHandler) Register(group *gin.RouterGroup) {
+	group.POST("/payouts", middleware.RequireAuth(h.authenticator), h.Pay)
+	group.GET("/payouts/:id", middleware.RequireAuth(h.authenticator), h.GetPayout)
+	group.POST("/rails/:rail/callbacks", middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin, models.RoleRail), h.Callback)
+}
+
+// Pay godoc
+// @Summary Pay out to an external account
+// @Description Moves the amount from an account of the caller into the clearing account and submits the
+// @Description payout to a payment rail, the default rail when none is named. The payout is returned
+// @Description submitted, or failed with its transfer reversed when the rail refuses it. The rail reports
+// @Description later whether the payout settled or was returned, in which case the transfer is reversed.
+// @Description A payout whose counterparty resembles a sanctioned party is returned held until its transfer
+// @Description is reviewed; it is then submitted, or failed when the transfer is rejected.
+// @Description A request repeating the Idempotency-Key of an earlier payout request of the caller gets
+// @Description the payout of that request as it is now instead of paying out again.
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "Key making retries of the request pay out once, up to 128 characters"
+// @Param request body models.PayoutRequest true "Payout details"
+// @Success 201 {object} models.Payout "Submitted, held or failed payout"
+// @Failure 400 {object} problem.Problem "Validation error, insufficient funds or unknown rail"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Account belongs to another principal or payout blocked by sanctions screening"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /payouts [post]
+func (h *PayoutHandler) Pay(c *gin.Context) {
+	var req models.PayoutRequest
+	if !validation.BindJSON(c, &req, h.maxBodySize) {
+		return
+	}
+	req.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	if errs := validation.Value("Idempotency-Key", req.IdempotencyKey, "omitempty,max=128,printascii"); len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	payout, err := h.payoutService.Pay(c.Request.Context(), req)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, payout)
+}
+
+// GetPayout godoc
+// @Summary Get payout
+// @Description Returns the current state of a payout from an account of the caller
+// @Tags payouts
+// @Produce json
+// @Param id path string true "Payout ID"
+// @Success 200 {object} models.Payout "Payout"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Account belongs to another principal"
+// @Failure 404 {object} problem.Problem "Payout not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /payouts/{id} [get]
+func (h *PayoutHandler) GetPayout(c *gin.Context) {
+	payout, err := h.payoutService.GetPayout(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
+
+// Callback godoc
+// @Summary Report the outcome of a payout
+// @Description Called by a payment rail when a payout it accepted settled or was returned. A rail may only
+// @Description report on its own payouts: the ID of a rail principal must be the name of the rail.
+// @Description Reporting the outcome a payout already has again returns it unchanged, so callbacks may be retried.
+// @Tags payouts
+// @Accept json
+// @Produce json
+// @Param rail path string true "Rail name"
+// @Param request body models.RailCallback true "Outcome"
+// @Success 200 {object} models.Payout "Settled or returned payout"
+// @Failure 400 {object} problem.Problem "Validation error or unknown rail"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not this rail or an administrator"
+// @Failure 404 {object} problem.Problem "No payout has the reference"
+// @Failure 409 {object} problem.Problem "Payout already has another outcome"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /rails/{rail}/callbacks [post]
+func (h *PayoutHandler) Callback(c *gin.Context) {
+	ctx := c.Request.Context()
+	railName := c.Param("rail")
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || (principal.Role == models.RoleRail && principal.ID != railName) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+
+	var callback models.RailCallback
+	if !validation.BindJSON(c, &callback, h.maxBodySize) {
+		return
+	}
+
+	payout, err := h.payoutService.HandleCallback(ctx, railName, callback)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payout)
+}
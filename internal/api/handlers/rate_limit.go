@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitConfig holds the rate limits applied to every route
+type RateLimitConfig struct {
+	Limiter ratelimit.Limiter // keeps the token buckets; nothing is limited when it is nil
+	Rules   *ratelimit.Rules  // limits of each route
+}
+
+// transferPath is the path of the REST transfer route, whose limits apply to transfers made over any API
+const transferPath = "/api/v1/transfer"
+
+// RateLimitHandler rejects requests over the limits of their route
+type RateLimitHandler struct {
+	cfg           RateLimitConfig
+	authenticator auth.Authenticator
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(cfg *HandlerConfig) *RateLimitHandler {
+	return &RateLimitHandler{
+		cfg:           cfg.RateLimit,
+		authenticator: cfg.Authenticator,
+	}
+}
+
+// Register registers handler routes; rate limiting serves none
+func (h *RateLimitHandler) Register(_ *gin.RouterGroup) {}
+
+// Middleware limits every request, including those of routes registered outside the API group
+func (h *RateLimitHandler) Middleware() gin.HandlerFunc {
+	return middleware.RateLimit(h.cfg.Limiter, h.cfg.Rules, h.authenticator)
+}
+
+// NewTransferThrottle creates the throttle counting transfers made over gRPC, GraphQL and
+// WebSocket against the limits of POST /api/v1/transfer; it is nil when nothing is limited
+func NewTransferThrottle(cfg RateLimitConfig) *ratelimit.Throttle {
+	return ratelimit.NewThrottle(cfg.Limiter, cfg.Rules, http.MethodPost, transferPath)
+}
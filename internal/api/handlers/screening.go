@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits for the number of decisions returned by ListDecisions
+const (
+	defaultScreeningLimit = 100
+	maxScreeningLimit     = 1000
+)
+
+// ScreeningHandler serves sanctions screening decisions and the review of held transfers
+type ScreeningHandler struct {
+	screeningService service.ScreeningService
+	authenticator    auth.Authenticator
+	maxBodySize      int64
+}
+
+// NewScreeningHandler creates a new screening handler
+func NewScreeningHandler(cfg *HandlerConfig) *ScreeningHandler {
+	return &ScreeningHandler{
+		screeningService: cfg.ScreeningService,
+		authenticator:    cfg.Authenticator,
+		maxBodySize:      cfg.MaxBodySize,
+	}
+}
+
+// Register registers handler routes
+func (h *ScreeningHandler) Register(group *gin.RouterGroup) {
+	group.GET("/screening/decisions", middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin, models.RoleAuditor), h.ListDecisions)
+	group.POST("/screening/decisions/:id/review", middleware.RequireAuth(h.authenticator),
+		middleware.RequireRole(models.RoleAdmin), h.Review)
+}
+
+// ListDecisions godoc
+// @Summary List screening decisions
+// @Description Returns how the parties of transfers were screened against the sanctions list, newest first.
+// @Description Each decision records the closest match, its score from 0 to 1 and the version of the list.
+// @Description Pass unreviewed=true for the held transfers awaiting review.
+// @Tags screening
+// @Produce json
+// @Param outcome query models.ScreeningOutcome false "Outcome of the screening"
+// @Param unreviewed query bool false "Only held transfers nobody reviewed yet"
+// @Param limit query int false "Maximum number of decisions (default 100, max 1000)"
+// @Success 200 {array} models.ScreeningDecision "Screening decisions"
+// @Failure 400 {object} problem.Problem "Invalid filter"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an auditor or administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /screening/decisions [get]
+func (h *ScreeningHandler) ListDecisions(c *gin.Context) {
+	filter, errs := screeningFilter(c)
+	if len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	decisions, err := h.screeningService.ListScreeningDecisions(c.Request.Context(), filter)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	if decisions == nil {
+		decisions = []*models.ScreeningDecision{}
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// Review godoc
+// @Summary Review a held transfer
+// @Description Resolves a transfer held by sanctions screening. A released transfer is executed right away
+// @Description and returned completed, or failed when its funds cannot move; a rejected transfer is failed.
+// @Tags screening
+// @Accept json
+// @Produce json
+// @Param id path string true "Screening decision ID"
+// @Param request body models.ScreeningReview true "Resolution"
+// @Success 200 {object} models.Transfer "Transfer after the review"
+// @Failure 400 {object} problem.Problem "Validation error"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 404 {object} problem.Problem "Screening decision not found"
+// @Failure 409 {object} problem.Problem "Decision held no transfer or was already reviewed"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /screening/decisions/{id}/review [post]
+func (h *ScreeningHandler) Review(c *gin.Context) {
+	var review models.ScreeningReview
+	if !validation.BindJSON(c, &review, h.maxBodySize) {
+		return
+	}
+
+	transfer, err := h.screeningService.ReviewTransfer(c.Request.Context(), c.Param("id"), review)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// screeningFilter reads the filter of ListDecisions from the query, reporting every invalid parameter
+func screeningFilter(c *gin.Context) (models.ScreeningFilter, []problem.FieldError) {
+	filter := models.ScreeningFilter{
+		Outcome: models.ScreeningOutcome(c.Query("outcome")),
+		Limit:   defaultScreeningLimit,
+	}
+	var errs []problem.FieldError
+
+	if filter.Outcome != "" && !filter.Outcome.IsValid() {
+		errs = append(errs, problem.FieldError{Field: "outcome", Code: "oneof", Message: "must be clear, hold or block"})
+	}
+
+	if value := c.Query("unreviewed"); value != "" {
+		unreviewed, err := strconv.ParseBool(value)
+		if err != nil {
+			errs = append(errs, problem.FieldError{Field: "unreviewed", Code: "type", Message: "must be a boolean"})
+		}
+		filter.Unreviewed = unreviewed
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			errs = append(errs, problem.FieldError{Field: "limit", Code: "type", Message: "must be a positive integer"})
+		}
+		filter.Limit = min(limit, maxScreeningLimit)
+	}
+
+	return filter, errs
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/iso20022"
+	"money-transfer/internal/service"
+	"money-transfer/internal/statement"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateLayout is the format of period bounds given as calendar days
+const dateLayout = "2006-01-02"
+
+// StatementHandler serves account statements
+type StatementHandler struct {
+	bankService   service.BankService
+	authenticator auth.Authenticator
+	now           func() time.Time
+}
+
+// NewStatementHandler creates a new statement handler
+func NewStatementHandler(cfg *HandlerConfig) *StatementHandler {
+	return &StatementHandler{
+		bankService:   cfg.BankService,
+		authenticator: cfg.Authenticator,
+		now:           time.Now,
+	}
+}
+
+// Register registers handler routes
+func (h *StatementHandler) Register(group *gin.RouterGroup) {
+	group.GET("/accounts/:id/statements", middleware.RequireAuth(h.authenticator), h.GetStatement)
+}
+
+// GetStatement godoc
+// @Summary Get account statement
+// @Description Returns the opening balance, every posting with its running balance and the closing balance
+// @Description of an account over a period of up to 366 days. from and to are RFC 3339 times or calendar
+// @Description days in UTC; a day given as to is included in full. The period defaults to the current month
+// @Description up to now. Every running balance is checked against the ledger, so the closing balance of a
+// @Description period ending now is the current balance. camt053 exports an ISO 20022 camt.053.001.08
+// @Description statement.
+// @Tags accounts
+// @Produce json
+// @Produce text/csv
+// @Produce application/pdf
+// @Produce application/xml
+// @Param id path string true "Account ID"
+// @Param from query string false "Start of the period, inclusive (2024-01-01 or 2024-01-01T00:00:00Z)"
+// @Param to query string false "End of the period (2024-01-31 for the whole day, or an exclusive RFC 3339 time)"
+// @Param format query string false "Export format" Enums(json, csv, pdf, camt053) default(json)
+// @Success 200 {object} models.Statement "Statement"
+// @Failure 400 {object} problem.Problem "Malformed account ID, period or format"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Account belongs to another principal"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /accounts/{id}/statements [get]
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	ctx := c.Request.Context()
+	accountID, ok := validation.Param(c, "id", "account_id")
+	if !ok {
+		return
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(accountID) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+
+	from, to, format, errs := h.statementQuery(c)
+	if len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return
+	}
+
+	stmt, err := h.bankService.Statement(ctx, accountID, from, to)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	if format == models.StatementJSON {
+		c.JSON(http.StatusOK, stmt)
+		return
+	}
+
+	// Rendered in full first, so that a failure is still reported as a problem
+	var body bytes.Buffer
+	switch format {
+	case models.StatementCSV:
+		err = statement.WriteCSV(&body, stmt)
+	case models.StatementCamt053:
+		err = writeCamt053(&body, stmt)
+	default:
+		err = statement.WritePDF(&body, stmt)
+	}
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statement.Filename(stmt, format)))
+	c.Data(http.StatusOK, statement.ContentType(format), body.Bytes())
+}
+
+// writeCamt053 writes stmt as a camt.053 message with a new message ID
+func writeCamt053(w io.Writer, stmt *models.Statement) error {
+	messageID, err := iso20022.NewMessageID()
+	if err != nil {
+		return err
+	}
+	return iso20022.WriteBankToCustomerStatement(w, stmt, messageID)
+}
+
+// statementQuery reads the period and format of GetStatement, reporting every invalid parameter
+func (h *StatementHandler) statementQuery(c *gin.Context) (from, to time.Time, format models.StatementFormat, errs []problem.FieldError) {
+	now := h.now().UTC()
+	from = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to = now
+
+	if value := c.Query("from"); value != "" {
+		parsed, ok := parsePeriodBound(value, false)
+		if !ok {
+			errs = append(errs, problem.FieldError{Field: "from", Code: "type", Message: "must be a date or an RFC 3339 time"})
+		}
+		from = parsed
+	}
+	if value := c.Query("to"); value != "" {
+		parsed, ok := parsePeriodBound(value, true)
+		if !ok {
+			errs = append(errs, problem.FieldError{Field: "to", Code: "type", Message: "must be a date or an RFC 3339 time"})
+		}
+		to = parsed
+	}
+
+	format = models.StatementFormat(c.DefaultQuery("format", string(models.StatementJSON)))
+	if !format.IsValid() {
+		errs = append(errs, problem.FieldError{Field: "format", Code: "oneof", Message: "must be one of json, csv, pdf or camt053"})
+	}
+
+	return from, to, format, errs
+}
+
+// parsePeriodBound parses a period bound given as an RFC 3339 time or a calendar day in UTC
+// A day ending a period is included in full, so it stands for the start of the next day.
+func parsePeriodBound(value string, end bool) (time.Time, bool) {
+	if day, err := time.Parse(dateLayout, value); err == nil {
+		if end {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day, true
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, err == nil
+}
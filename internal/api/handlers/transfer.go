@@ -1,9 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"net/http"
+	"path"
+	"strconv"
+	"time"
 
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
 	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
 	"money-transfer/internal/service"
@@ -11,55 +19,164 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxTransferWait caps how long GetTransfer may hold a request open
+const maxTransferWait = 5 * time.Second
+
 // TransferHandler handles money transfer requests
 type TransferHandler struct {
-	bankService service.BankService
+	bankService   service.BankService
+	authenticator auth.Authenticator
+	maxBodySize   int64
 }
 
 // NewTransferHandler creates a new transfer handler
 func NewTransferHandler(cfg *HandlerConfig) *TransferHandler {
 	return &TransferHandler{
-		bankService: cfg.BankService,
+		bankService:   cfg.BankService,
+		authenticator: cfg.Authenticator,
+		maxBodySize:   cfg.MaxBodySize,
 	}
 }
 
 // Register registers handler routes
 func (h *TransferHandler) Register(group *gin.RouterGroup) {
-	group.POST("/transfer", h.Transfer)
+	group.POST("/transfer", middleware.RequireAuth(h.authenticator), h.Transfer)
+	group.GET("/transfers/:id", middleware.RequireAuth(h.authenticator), h.GetTransfer)
 }
 
 // Transfer godoc
 // @Summary Execute money transfer between accounts
-// @Description Transfers specified amount from one account to another
+// @Description Transfers specified amount from one account to another.
+// @Description With async=true the transfer is queued and 202 is returned with its ID for polling.
+// @Description A transfer whose parties resemble a sanctioned party is held for review and also answered
+// @Description with 202 and status held; one matching a sanctioned party is refused with transfer_blocked.
+// @Description The caller must be allowed to access the source account.
 // @Tags transfer
 // @Accept json
 // @Produce json
 // @Param request body models.TransferRequest true "Transfer details"
+// @Param async query bool false "Queue the transfer instead of waiting for it"
 // @Success 200 {object} models.TransferResponse "Successful transfer"
-// @Failure 400 {object} map[string]string "Validation error"
-// @Failure 404 {object} map[string]string "Account not found"
-// @Failure 500 {object} map[string]string "Internal server error"
+// @Success 202 {object} models.TransferResponse "Transfer queued or held for sanctions review"
+// @Failure 400 {object} problem.Problem "Validation error"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Source account owned by another principal, or transfer blocked by sanctions screening"
+// @Failure 404 {object} problem.Problem "Account not found"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 429 {object} problem.Problem "Rate limit exceeded"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
 // @Router /transfer [post]
 func (h *TransferHandler) Transfer(c *gin.Context) {
 	var req models.TransferRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !validation.BindJSON(c, &req, h.maxBodySize) {
+		return
+	}
+
+	principal, ok := auth.PrincipalFromContext(c.Request.Context())
+	if !ok || !principal.CanAccessAccount(req.From) {
+		problem.Error(c, transfererrors.ErrForbidden)
 		return
 	}
 
-	if err := h.bankService.Transfer(c.Request.Context(), req); err != nil {
-		switch {
-		case errors.Is(err, transfererrors.ErrAccountNotFound):
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		case errors.Is(err, transfererrors.ErrInsufficientFunds),
-			errors.Is(err, transfererrors.ErrInvalidAmount),
-			errors.Is(err, transfererrors.ErrSameAccount):
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		problem.Invalid(c, problem.FieldError{Field: "async", Code: "type", Message: "must be a boolean"})
+		return
+	}
+
+	if async {
+		transfer, err := h.bankService.SubmitTransfer(c.Request.Context(), req)
+		if err != nil {
+			problem.Error(c, err)
+			return
 		}
+
+		c.Header("Location", path.Join(path.Dir(c.FullPath()), "transfers", transfer.ID))
+		c.JSON(http.StatusAccepted, models.TransferResponse{
+			Success:    true,
+			TransferID: transfer.ID,
+			Status:     transfer.Status,
+		})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.TransferResponse{Success: true})
+	transfer, err := h.bankService.Transfer(c.Request.Context(), req)
+	if errors.Is(err, transfererrors.ErrTransferHeld) {
+		c.Header("Location", path.Join(path.Dir(c.FullPath()), "transfers", transfer.ID))
+		c.JSON(http.StatusAccepted, models.TransferResponse{
+			Success:    true,
+			TransferID: transfer.ID,
+			Status:     transfer.Status,
+			Message:    err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.TransferResponse{
+		Success:    true,
+		TransferID: transfer.ID,
+		Status:     transfer.Status,
+	})
+}
+
+// GetTransfer godoc
+// @Summary Get transfer status
+// @Description Returns the transfer and its lifecycle status.
+// @Description With wait set, the request is held until the transfer is final or the wait elapses (max 5s).
+// @Description The caller must be allowed to access the source or the destination account.
+// @Tags transfer
+// @Produce json
+// @Param id path string true "Transfer ID"
+// @Param wait query string false "How long to wait for a final status, e.g. 2s"
+// @Success 200 {object} models.Transfer "Transfer details"
+// @Failure 400 {object} problem.Problem "Invalid wait duration"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Transfer between accounts of other principals"
+// @Failure 404 {object} problem.Problem "Transfer not found"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /transfers/{id} [get]
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	id := c.Param("id")
+
+	wait, err := time.ParseDuration(c.DefaultQuery("wait", "0s"))
+	if err != nil || wait < 0 {
+		problem.Invalid(c, problem.FieldError{Field: "wait", Code: "type", Message: "must be a non-negative duration"})
+		return
+	}
+	wait = min(wait, maxTransferWait)
+
+	transfer, err := h.bankService.GetTransfer(c.Request.Context(), id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	// Checked before waiting, so a request for another principal's transfer is not held open
+	principal, ok := auth.PrincipalFromContext(c.Request.Context())
+	if !ok || !(principal.CanAccessAccount(transfer.From) || principal.CanAccessAccount(transfer.To)) {
+		problem.Error(c, transfererrors.ErrForbidden)
+		return
+	}
+
+	if wait > 0 && !transfer.Status.IsFinal() {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+		defer cancel()
+		awaited, err := h.bankService.AwaitTransfer(ctx, id)
+		if errors.Is(err, context.DeadlineExceeded) {
+			awaited, err = h.bankService.GetTransfer(c.Request.Context(), id)
+		}
+		if err != nil {
+			problem.Error(c, err)
+			return
+		}
+		transfer = awaited
+	}
+
+	c.JSON(http.StatusOK, transfer)
 }
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits for the number of deliveries returned by ListDeliveries
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// WebhookHandler handles webhook subscription and delivery requests
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	authenticator  auth.Authenticator
+	maxBodySize    int64
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(cfg *HandlerConfig) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: cfg.WebhookService,
+		authenticator:  cfg.Authenticator,
+		maxBodySize:    cfg.MaxBodySize,
+	}
+}
+
+// Register registers handler routes
+// Subscriptions receive the events of every account, so only administrators manage them.
+func (h *WebhookHandler) Register(group *gin.RouterGroup) {
+	webhooks := group.Group("/webhooks", middleware.RequireAuth(h.authenticator), middleware.RequireRole(models.RoleAdmin))
+	webhooks.POST("", h.CreateSubscription)
+	webhooks.GET("", h.ListSubscriptions)
+	webhooks.GET("/:id", h.GetSubscription)
+	webhooks.DELETE("/:id", h.DeleteSubscription)
+	webhooks.GET("/:id/deliveries", h.ListDeliveries)
+	webhooks.POST("/:id/deliveries/:delivery/redeliver", h.Redeliver)
+}
+
+// CreateSubscription godoc
+// @Summary Create webhook subscription
+// @Description Registers an endpoint that receives signed POSTs for the given event types (all when empty).
+// @Description The signing secret is generated when omitted and is only returned in this response.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body models.WebhookSubscriptionRequest true "Subscription details"
+// @Success 201 {object} models.WebhookSubscription "Created subscription, including its secret"
+// @Failure 400 {object} problem.Problem "Validation error"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 413 {object} problem.Problem "Request body too large"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req models.WebhookSubscriptionRequest
+	if !validation.BindJSON(c, &req, h.maxBodySize) {
+		return
+	}
+
+	subscription, err := h.webhookService.CreateSubscription(c.Request.Context(), req)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, subscription)
+}
+
+// ListSubscriptions godoc
+// @Summary List webhook subscriptions
+// @Description Returns all webhook subscriptions without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.WebhookSubscription "Subscriptions"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subscriptions, err := h.webhookService.ListSubscriptions(c.Request.Context())
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscriptions)
+}
+
+// GetSubscription godoc
+// @Summary Get webhook subscription
+// @Description Returns a webhook subscription without its secret
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} models.WebhookSubscription "Subscription"
+// @Failure 404 {object} problem.Problem "Subscription not found"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	subscription, err := h.webhookService.GetSubscription(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, subscription)
+}
+
+// DeleteSubscription godoc
+// @Summary Delete webhook subscription
+// @Description Removes a webhook subscription together with its pending deliveries
+// @Tags webhooks
+// @Param id path string true "Subscription ID"
+// @Success 204 "Subscription deleted"
+// @Failure 404 {object} problem.Problem "Subscription not found"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	if err := h.webhookService.DeleteSubscription(c.Request.Context(), c.Param("id")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Returns the most recent deliveries of a subscription with their attempt history
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 500)"
+// @Success 200 {array} models.WebhookDelivery "Deliveries, newest first"
+// @Failure 400 {object} problem.Problem "Invalid limit"
+// @Failure 404 {object} problem.Problem "Subscription not found"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultDeliveryLimit)))
+	if err != nil || limit <= 0 {
+		problem.Invalid(c, problem.FieldError{Field: "limit", Code: "type", Message: "must be a positive integer"})
+		return
+	}
+	limit = min(limit, maxDeliveryLimit)
+
+	deliveries, err := h.webhookService.ListDeliveries(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook
+// @Description Queues a delivery for an immediate new attempt, including dead-lettered deliveries
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param delivery path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery "Delivery queued"
+// @Failure 404 {object} problem.Problem "Delivery not found"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Failure 403 {object} problem.Problem "Caller is not an administrator"
+// @Failure 500 {object} problem.Problem "Internal server error"
+// @Security ApiKeyAuth
+// @Router /webhooks/{id}/deliveries/{delivery}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	delivery, err := h.webhookService.Redeliver(c.Request.Context(), c.Param("id"), c.Param("delivery"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
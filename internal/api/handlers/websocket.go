@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// Defaults used when the handler configuration leaves WebSocket settings unset
+const (
+	defaultWebSocketPingInterval = 30 * time.Second
+	defaultWebSocketPongTimeout  = 60 * time.Second
+	defaultWebSocketSendQueue    = 64
+	defaultWebSocketMaxInFlight  = 8
+)
+
+// WebSocketConfig holds the settings of WebSocket connections
+type WebSocketConfig struct {
+	PingInterval  time.Duration // how often the server pings an open connection
+	PongTimeout   time.Duration // how long a connection may stay silent before it is dropped
+	SendQueueSize int           // outgoing messages buffered before a client is considered too slow
+	MaxInFlight   int           // requests of one connection handled concurrently
+}
+
+// WebSocketHandler serves the JSON-RPC API over WebSocket connections
+type WebSocketHandler struct {
+	bankService   service.BankService
+	throttle      *ratelimit.Throttle
+	authenticator auth.Authenticator
+	logger        *slog.Logger
+	stopping      <-chan struct{}
+	pollInterval  time.Duration
+	cfg           WebSocketConfig
+	upgrader      websocket.Upgrader
+}
+
+// NewWebSocketHandler creates a new WebSocket handler
+func NewWebSocketHandler(cfg *HandlerConfig) *WebSocketHandler {
+	h := &WebSocketHandler{
+		bankService:   cfg.BankService,
+		throttle:      NewTransferThrottle(cfg.RateLimit),
+		authenticator: cfg.Authenticator,
+		logger:        cfg.Logger,
+		stopping:      cfg.Stopping,
+		pollInterval:  cfg.StreamPollInterval,
+		cfg:           cfg.WebSocket,
+	}
+	if h.logger == nil {
+		h.logger = logging.Discard()
+	}
+	if h.pollInterval <= 0 {
+		h.pollInterval = defaultStreamPollInterval
+	}
+	if h.cfg.PingInterval <= 0 {
+		h.cfg.PingInterval = defaultWebSocketPingInterval
+	}
+	if h.cfg.PongTimeout <= 0 {
+		h.cfg.PongTimeout = defaultWebSocketPongTimeout
+	}
+	if h.cfg.SendQueueSize <= 0 {
+		h.cfg.SendQueueSize = defaultWebSocketSendQueue
+	}
+	if h.cfg.MaxInFlight <= 0 {
+		h.cfg.MaxInFlight = defaultWebSocketMaxInFlight
+	}
+	return h
+}
+
+// Register registers handler routes
+func (h *WebSocketHandler) Register(group *gin.RouterGroup) {
+	group.GET("/ws", middleware.RequireAuth(h.authenticator, middleware.AllowQueryToken()), h.Serve)
+}
+
+// Serve godoc
+// @Summary Open a WebSocket connection
+// @Description Upgrades to a WebSocket carrying JSON-RPC 2.0 messages.
+// @Description Methods: transfer, getBalance, subscribe and unsubscribe.
+// @Description Subscribed accounts push "activity" notifications; see the README for the message formats.
+// @Description The API key is checked once, during the handshake.
+// @Tags websocket
+// @Param access_token query string false "API key for clients that cannot set headers"
+// @Success 101 "Switching protocols"
+// @Failure 400 {object} problem.Problem "Not a WebSocket handshake"
+// @Failure 401 {object} problem.Problem "Missing or invalid credentials"
+// @Security ApiKeyAuth
+// @Router /ws [get]
+func (h *WebSocketHandler) Serve(c *gin.Context) {
+	principal, _ := auth.PrincipalFromContext(c.Request.Context())
+
+	upgrader := h.upgrader
+	upgrader.Error = func(_ http.ResponseWriter, _ *http.Request, status int, reason error) {
+		problem.Status(c, status, reason.Error())
+	}
+
+	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already written an error response
+		h.logger.WarnContext(c.Request.Context(), "websocket upgrade failed", "error", err)
+		return
+	}
+
+	newWSSession(conn, h, principal).run(c.Request.Context())
+}
+
+// newWSSession prepares a session for an upgraded connection of principal
+func newWSSession(conn *websocket.Conn, h *WebSocketHandler, principal *models.Principal) *wsSession {
+	return &wsSession{
+		conn:          conn,
+		bankService:   h.bankService,
+		throttle:      h.throttle,
+		logger:        h.logger,
+		stopping:      h.stopping,
+		principal:     principal,
+		pollInterval:  h.pollInterval,
+		cfg:           h.cfg,
+		send:          make(chan []byte, h.cfg.SendQueueSize),
+		inFlight:      make(chan struct{}, h.cfg.MaxInFlight),
+		subscriptions: make(map[string]*wsSubscription),
+	}
+}
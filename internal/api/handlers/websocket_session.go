@@ -0,0 +1,533 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/ratelimit"
+	"money-transfer/internal/service"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsWriteTimeout bounds how long a single write to a client may block
+const wsWriteTimeout = 10 * time.Second
+
+// wsMaxMessageSize is the largest message accepted from a client
+const wsMaxMessageSize = 64 << 10
+
+// wsMaxSubscriptions caps the accounts one connection may follow
+const wsMaxSubscriptions = 32
+
+// errSessionClosed stops subscriptions of a session that has ended
+var errSessionClosed = errors.New("websocket session closed")
+
+// JSON-RPC error codes; the -3200x range is reserved for application errors
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcForbidden      = -32001
+	rpcNotFound       = -32002
+	rpcRejected       = -32003
+	rpcRateLimited    = -32004
+)
+
+// rpcRequest is a JSON-RPC request; requests without an id are notifications and get no response
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcResponse is the response to a request, or a server notification when Method is set
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  any             `json:"params,omitempty"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError describes why a request failed
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// transferParams are the parameters of the transfer method
+type transferParams struct {
+	models.TransferRequest
+	Async bool `json:"async"` // queue the transfer instead of waiting for it
+}
+
+// accountParams are the parameters of methods acting on a single account
+type accountParams struct {
+	Account string `json:"account" binding:"required,account_id"`
+}
+
+// subscribeParams are the parameters of the subscribe method
+type subscribeParams struct {
+	Account     string `json:"account" binding:"required,account_id"`
+	LastEventID *int64 `json:"last_event_id,omitempty" binding:"omitempty,gte=0"` // resume after this sequence instead of now
+}
+
+// subscribeResult is returned by subscribe
+type subscribeResult struct {
+	Account string `json:"account"`
+	After   int64  `json:"after"` // notifications follow this sequence
+
+	subscription *wsSubscription
+}
+
+// wsSubscription is an account followed by a connection
+type wsSubscription struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	after  int64
+}
+
+// wsSession serves the JSON-RPC API over one WebSocket connection
+// A single writer goroutine owns the connection's write side; everything else queues
+// messages on send. Requests are handled concurrently up to cfg.MaxInFlight, after which
+// reading stops so that TCP flow control pushes back on the client.
+// When the server stops, reading stops and the session closes once the requests already
+// received have been answered.
+type wsSession struct {
+	conn         *websocket.Conn
+	bankService  service.BankService
+	throttle     *ratelimit.Throttle
+	logger       *slog.Logger
+	stopping     <-chan struct{}
+	draining     atomic.Bool
+	principal    *models.Principal
+	pollInterval time.Duration
+	cfg          WebSocketConfig
+
+	send     chan []byte
+	inFlight chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu            sync.Mutex
+	subscriptions map[string]*wsSubscription
+	closeCode     int
+	closeText     string
+}
+
+// run serves the connection until the client leaves or the session is closed
+func (s *wsSession) run(ctx context.Context) {
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	defer s.cancel()
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		s.writeLoop()
+	}()
+
+	go s.drainOnStop()
+	s.readLoop()
+	if s.draining.Load() {
+		s.awaitInFlight()
+		s.closeWith(websocket.CloseGoingAway, "server shutting down")
+	}
+	s.cancel()
+
+	// Requests and subscriptions only enqueue messages, so they finish once ctx is canceled
+	s.wg.Wait()
+	<-writerDone
+}
+
+// readLoop dispatches incoming requests until the connection fails or the session ends
+func (s *wsSession) readLoop() {
+	s.conn.SetReadLimit(wsMaxMessageSize)
+	_ = s.conn.SetReadDeadline(time.Now().Add(s.cfg.PongTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		if s.draining.Load() {
+			return nil
+		}
+		return s.conn.SetReadDeadline(time.Now().Add(s.cfg.PongTimeout))
+	})
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if s.draining.Load() {
+			return
+		}
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) &&
+				s.ctx.Err() == nil {
+				s.logger.WarnContext(s.ctx, "websocket read failed", "error", err)
+			}
+			return
+		}
+		_ = s.conn.SetReadDeadline(time.Now().Add(s.cfg.PongTimeout))
+
+		var req rpcRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			s.reply(rpcRequest{ID: json.RawMessage("null")}, nil,
+				&rpcError{Code: rpcParseError, Message: "parse error"})
+			continue
+		}
+
+		select {
+		case s.inFlight <- struct{}{}:
+		case <-s.ctx.Done():
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() { <-s.inFlight }()
+			s.handle(req)
+		}()
+	}
+}
+
+// drainOnStop stops reading requests once the server starts stopping
+func (s *wsSession) drainOnStop() {
+	select {
+	case <-s.stopping:
+		s.draining.Store(true)
+		// Wakes the read loop blocked waiting for the next message
+		_ = s.conn.SetReadDeadline(time.Now())
+	case <-s.ctx.Done():
+	}
+}
+
+// awaitInFlight blocks until every request being handled has been answered, or the session fails
+func (s *wsSession) awaitInFlight() {
+	for i := 0; i < cap(s.inFlight); i++ {
+		select {
+		case s.inFlight <- struct{}{}:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// writeLoop writes queued messages and keep-alive pings until the session ends
+func (s *wsSession) writeLoop() {
+	defer s.conn.Close()
+
+	ping := time.NewTicker(s.cfg.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case message := <-s.send:
+			_ = s.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			if err := s.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				s.cancel()
+				return
+			}
+		case <-ping.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				s.cancel()
+				return
+			}
+		case <-s.ctx.Done():
+			code, text := s.closeStatus()
+			_ = s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text),
+				time.Now().Add(wsWriteTimeout))
+			return
+		}
+	}
+}
+
+// closeWith ends the session, telling the client why
+func (s *wsSession) closeWith(code int, text string) {
+	s.mu.Lock()
+	if s.closeCode == 0 {
+		s.closeCode, s.closeText = code, text
+	}
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// closeStatus returns the close frame sent when the session ends
+func (s *wsSession) closeStatus() (int, string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closeCode == 0 {
+		return websocket.CloseNormalClosure, ""
+	}
+	return s.closeCode, s.closeText
+}
+
+// handle executes a request and sends its response
+func (s *wsSession) handle(req rpcRequest) {
+	result, err := s.call(req)
+	s.reply(req, result, err)
+
+	// Notifications start only once the subscribe response is queued ahead of them
+	if subscribed, ok := result.(*subscribeResult); ok {
+		s.follow(subscribed.Account, subscribed.subscription)
+	}
+}
+
+// call dispatches a request to its method
+func (s *wsSession) call(req rpcRequest) (any, error) {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return nil, &rpcError{Code: rpcInvalidRequest, Message: "invalid request"}
+	}
+
+	switch req.Method {
+	case "transfer":
+		var params transferParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.transfer(params)
+	case "getBalance":
+		var params accountParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.getBalance(params)
+	case "subscribe":
+		var params subscribeParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.subscribe(params)
+	case "unsubscribe":
+		var params accountParams
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.unsubscribe(params)
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: "method not found"}
+	}
+}
+
+// transfer moves money from an account owned by the caller
+func (s *wsSession) transfer(params transferParams) (any, error) {
+	if !s.principal.CanAccessAccount(params.From) {
+		return nil, transfererrors.ErrForbidden
+	}
+	if err := s.throttle.Allow(s.ctx, params.From); err != nil {
+		return nil, err
+	}
+
+	if params.Async {
+		transfer, err := s.bankService.SubmitTransfer(s.ctx, params.TransferRequest)
+		if err != nil {
+			return nil, err
+		}
+		return models.TransferResponse{Success: true, TransferID: transfer.ID, Status: transfer.Status}, nil
+	}
+
+	// A held transfer was accepted; it waits for sanctions review
+	transfer, err := s.bankService.Transfer(s.ctx, params.TransferRequest)
+	if errors.Is(err, transfererrors.ErrTransferHeld) {
+		return models.TransferResponse{
+			Success: true, TransferID: transfer.ID, Status: transfer.Status, Message: err.Error(),
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return models.TransferResponse{Success: true, TransferID: transfer.ID, Status: transfer.Status}, nil
+}
+
+// getBalance returns the balance of an account owned by the caller
+func (s *wsSession) getBalance(params accountParams) (any, error) {
+	if !s.principal.CanAccessAccount(params.Account) {
+		return nil, transfererrors.ErrForbidden
+	}
+
+	balance, err := s.bankService.GetBalance(s.ctx, params.Account)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{"balance": balance}, nil
+}
+
+// subscribe registers an account whose activity is pushed to the client
+func (s *wsSession) subscribe(params subscribeParams) (any, error) {
+	if !s.principal.CanAccessAccount(params.Account) {
+		return nil, transfererrors.ErrForbidden
+	}
+
+	if s.subscribed(params.Account) {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "account is already subscribed"}
+	}
+
+	if _, err := s.bankService.GetBalance(s.ctx, params.Account); err != nil {
+		return nil, err
+	}
+
+	var after int64
+	if params.LastEventID != nil {
+		after = *params.LastEventID
+	} else {
+		latest, err := s.bankService.LatestActivitySequence(s.ctx)
+		if err != nil {
+			return nil, err
+		}
+		after = latest
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[params.Account]; ok {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "account is already subscribed"}
+	}
+	if len(s.subscriptions) >= wsMaxSubscriptions {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "too many subscriptions"}
+	}
+	ctx, cancel := context.WithCancel(s.ctx)
+	sub := &wsSubscription{ctx: ctx, cancel: cancel, after: after}
+	s.subscriptions[params.Account] = sub
+
+	return &subscribeResult{Account: params.Account, After: after, subscription: sub}, nil
+}
+
+// subscribed reports whether the account is already followed
+func (s *wsSession) subscribed(accountID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.subscriptions[accountID]
+	return ok
+}
+
+// unsubscribe stops pushing activity of an account
+func (s *wsSession) unsubscribe(params accountParams) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub, ok := s.subscriptions[params.Account]
+	if !ok {
+		return nil, &rpcError{Code: rpcInvalidParams, Message: "account is not subscribed"}
+	}
+	sub.cancel()
+	delete(s.subscriptions, params.Account)
+
+	return map[string]bool{"unsubscribed": true}, nil
+}
+
+// follow pushes activity of a subscribed account until it is unsubscribed or the session ends
+func (s *wsSession) follow(accountID string, sub *wsSubscription) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		_ = service.FollowAccountActivity(sub.ctx, s.logger, s.bankService, accountID, sub.after, s.pollInterval,
+			func(activity *models.AccountActivity) error {
+				if !s.notify("activity", activity) {
+					return errSessionClosed
+				}
+				return nil
+			})
+	}()
+}
+
+// reply queues the response to req; notifications from the client get none
+func (s *wsSession) reply(req rpcRequest, result any, err error) {
+	if len(req.ID) == 0 {
+		return
+	}
+
+	response := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		response.Error = toRPCError(err)
+	} else {
+		response.Result = result
+	}
+
+	message, err := json.Marshal(response)
+	if err != nil {
+		s.logger.ErrorContext(s.ctx, "failed to encode websocket response", "error", err)
+		return
+	}
+
+	// Responses wait for room in the queue; in-flight requests are bounded by cfg.MaxInFlight
+	select {
+	case s.send <- message:
+	case <-s.ctx.Done():
+	}
+}
+
+// notify queues a server notification and reports whether the session is still open
+// A client that lets the queue fill up is disconnected rather than slowing down the server;
+// it can reconnect and resume from the last sequence it received
+func (s *wsSession) notify(method string, params any) bool {
+	message, err := json.Marshal(rpcResponse{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		s.logger.ErrorContext(s.ctx, "failed to encode websocket notification", "method", method, "error", err)
+		return s.ctx.Err() == nil
+	}
+
+	select {
+	case s.send <- message:
+		return true
+	case <-s.ctx.Done():
+		return false
+	default:
+		s.closeWith(websocket.CloseTryAgainLater, "client is not reading fast enough")
+		return false
+	}
+}
+
+// decodeParams reads method parameters, rejecting unknown fields
+func decodeParams(data json.RawMessage, params any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(params); err != nil {
+		return &rpcError{Code: rpcInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	if errs := validation.Struct(params); len(errs) > 0 {
+		return &rpcError{Code: rpcInvalidParams, Message: "invalid params", Data: errs}
+	}
+	return nil
+}
+
+// toRPCError maps service errors to JSON-RPC errors
+func toRPCError(err error) *rpcError {
+	var rpcErr *rpcError
+	var exceeded *ratelimit.ExceededError
+	switch {
+	case errors.As(err, &rpcErr):
+		return rpcErr
+	case errors.As(err, &exceeded):
+		return &rpcError{Code: rpcRateLimited, Message: exceeded.Error(),
+			Data: map[string]int{"retry_after": ratelimit.CeilSeconds(exceeded.Result.RetryAfter)}}
+	case errors.Is(err, transfererrors.ErrForbidden),
+		errors.Is(err, transfererrors.ErrSystemAccount),
+		errors.Is(err, transfererrors.ErrTransferBlocked):
+		return &rpcError{Code: rpcForbidden, Message: err.Error()}
+	case errors.Is(err, transfererrors.ErrAccountNotFound),
+		errors.Is(err, transfererrors.ErrTransferNotFound):
+		return &rpcError{Code: rpcNotFound, Message: err.Error()}
+	case errors.Is(err, transfererrors.ErrInsufficientFunds),
+		errors.Is(err, transfererrors.ErrInvalidAmount),
+		errors.Is(err, transfererrors.ErrSameAccount),
+		errors.Is(err, transfererrors.ErrTransferHeld):
+		return &rpcError{Code: rpcRejected, Message: err.Error()}
+	default:
+		return &rpcError{Code: rpcInternalError, Message: "internal error"}
+	}
+}
@@ -6,3 +6,16 @@ import "github.com/gin-gonic/gin"
 type Handler interface {
 	Register(group *gin.RouterGroup)
 }
+
+// RootHandler is a handler that also serves routes outside the versioned API group
+type RootHandler interface {
+	Handler
+	RegisterRoot(router *gin.Engine)
+}
+
+// MiddlewareHandler is a handler that installs middleware which must see every request
+// The router installs it before any route is registered and outside panic recovery.
+type MiddlewareHandler interface {
+	Handler
+	Middleware() gin.HandlerFunc
+}
@@ -0,0 +1,108 @@
+// Package middleware provides gin middleware shared by API handlers
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the header carrying an API key when no bearer token is sent
+const APIKeyHeader = "X-API-Key"
+
+// accessTokenParam carries the API key for clients that cannot set headers,
+// such as browser EventSource and WebSocket connections
+const accessTokenParam = "access_token"
+
+// AuthOption configures RequireAuth
+type AuthOption func(*authOptions)
+
+// authOptions holds the settings of RequireAuth
+type authOptions struct {
+	queryToken bool
+}
+
+// AllowQueryToken makes RequireAuth accept the API key in the access_token query parameter
+// URLs end up in access and proxy logs, so only the routes opened by clients that cannot set
+// headers, such as the SSE stream and the WebSocket handshake, accept it.
+func AllowQueryToken() AuthOption {
+	return func(o *authOptions) {
+		o.queryToken = true
+	}
+}
+
+// RequireAuth rejects requests without a valid API key with 401 and stores the
+// authenticated principal in the request context
+// The key is read from the Authorization or X-API-Key header, and from the query only
+// with AllowQueryToken.
+func RequireAuth(authenticator auth.Authenticator, opts ...AuthOption) gin.HandlerFunc {
+	var options authOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(c *gin.Context) {
+		credential := credentialFrom(c, options.queryToken)
+		if credential == "" {
+			abortUnauthenticated(c)
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), credential)
+		if err != nil {
+			abortUnauthenticated(c)
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		c.Next()
+	}
+}
+
+// ClientIP stores the address of the caller in the request context, as resolved by gin
+// from the trusted proxies' forwarding headers
+func ClientIP() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithClientIP(c.Request.Context(), c.ClientIP()))
+		c.Next()
+	}
+}
+
+// RequireRole rejects requests whose principal, stored by RequireAuth, has none of the roles with 403
+func RequireRole(roles ...models.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := auth.PrincipalFromContext(c.Request.Context())
+		if !ok || !slices.Contains(roles, principal.Role) {
+			problem.Error(c, transfererrors.ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// credentialFrom extracts the API key from the request headers, or from the query when
+// queryToken is set and no header carries one
+func credentialFrom(c *gin.Context, queryToken bool) string {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return key
+	}
+	if queryToken {
+		return c.Query(accessTokenParam)
+	}
+	return ""
+}
+
+// abortUnauthenticated ends the request with a 401 challenge
+func abortUnauthenticated(c *gin.Context) {
+	c.Header("WWW-Authenticate", "Bearer")
+	problem.Error(c, transfererrors.ErrUnauthenticated)
+}
@@ -0,0 +1,53 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireAuth(t *testing.T) {
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/balance", middleware.RequireAuth(apiKeys), func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/events", middleware.RequireAuth(apiKeys, middleware.AllowQueryToken()),
+		func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		name       string
+		target     string
+		header     string
+		value      string
+		wantStatus int
+	}{
+		{name: "bearer token", target: "/balance", header: "Authorization", value: "Bearer mark-key", wantStatus: http.StatusOK},
+		{name: "api key header", target: "/balance", header: middleware.APIKeyHeader, value: "mark-key", wantStatus: http.StatusOK},
+		{name: "invalid key", target: "/balance", header: middleware.APIKeyHeader, value: "stolen-key", wantStatus: http.StatusUnauthorized},
+		{name: "no key", target: "/balance", wantStatus: http.StatusUnauthorized},
+		{name: "query token refused", target: "/balance?access_token=mark-key", wantStatus: http.StatusUnauthorized},
+		{name: "query token allowed", target: "/events?access_token=mark-key", wantStatus: http.StatusOK},
+		{name: "invalid query token", target: "/events?access_token=stolen-key", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDAndAccessLog(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestID     string
+		wantRequestID string
+	}{
+		{name: "propagated", requestID: "client-req-42", wantRequestID: "client-req-42"},
+		{name: "generated when missing"},
+		{name: "generated when malformed", requestID: "bad id\twith spaces"},
+		{name: "generated when too long", requestID: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var buf bytes.Buffer
+			logger, err := logging.New(&buf, logging.Config{Level: "info", Format: "json"})
+			require.NoError(t, err)
+
+			var handlerRequestID string
+			router := gin.New()
+			router.Use(middleware.RequestID(), middleware.AccessLog(logger))
+			router.GET("/accounts/:id", func(c *gin.Context) {
+				handlerRequestID = logging.RequestIDFromContext(c.Request.Context())
+				c.String(http.StatusTeapot, "short and stout")
+			})
+
+			req := httptest.NewRequest("GET", "/accounts/Mark", nil)
+			if tt.requestID != "" {
+				req.Header.Set(middleware.RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(middleware.RequestIDHeader)
+			if tt.wantRequestID != "" {
+				assert.Equal(t, tt.wantRequestID, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+			}
+			assert.Equal(t, requestID, handlerRequestID)
+
+			var record map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+			assert.Equal(t, "request served", record["msg"])
+			assert.Equal(t, requestID, record["request_id"])
+			assert.Equal(t, "GET", record["method"])
+			assert.Equal(t, "/accounts/Mark", record["path"])
+			assert.Equal(t, "/accounts/:id", record["route"])
+			assert.EqualValues(t, http.StatusTeapot, record["status"])
+			assert.EqualValues(t, len("short and stout"), record["bytes"])
+			assert.Contains(t, record, "latency_ms")
+		})
+	}
+}
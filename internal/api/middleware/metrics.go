@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"time"
+
+	"money-transfer/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records every request in collector once it is served
+// Requests matching no route share the "unmatched" route label to bound cardinality.
+func Metrics(collector *metrics.Collector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		collector.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
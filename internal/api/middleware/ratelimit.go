@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAccountPeek bounds how much of a request body is read to find its source account
+const maxAccountPeek = 64 << 10
+
+// RateLimit rejects requests over the limits of their route with 429
+// Requests are counted by the principal of the API key they carry, by client IP and by the
+// account they act on: the account in the path, or the source account of a transfer body.
+// Limits whose key a request lacks, such as principal for anonymous requests, do not apply.
+// The RateLimit-* headers describe the most constrained limit; when limiter fails the
+// request is let through, so that an outage of the limiter does not take the API down.
+// Transfers made over gRPC, GraphQL and WebSocket are counted by a ratelimit.Throttle instead.
+func RateLimit(limiter ratelimit.Limiter, rules *ratelimit.Rules, authenticator auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, limits := rules.For(c.Request.Method, c.FullPath())
+
+		tightest, ok := ratelimit.Take(c.Request.Context(), limiter, route, limits, func(key ratelimit.Key) string {
+			return identify(c, key, authenticator)
+		})
+		if !ok {
+			c.Next()
+			return
+		}
+
+		setRateLimitHeaders(c, tightest)
+		if !tightest.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ratelimit.CeilSeconds(tightest.RetryAfter)))
+			problem.Status(c, http.StatusTooManyRequests, (&ratelimit.ExceededError{Result: tightest}).Error())
+			return
+		}
+		c.Next()
+	}
+}
+
+// identify returns the value of key for the request, or "" when the request has none
+func identify(c *gin.Context, key ratelimit.Key, authenticator auth.Authenticator) string {
+	switch key {
+	case ratelimit.KeyIP:
+		return c.ClientIP()
+	case ratelimit.KeyPrincipal:
+		return principalOf(c, authenticator)
+	case ratelimit.KeyAccount:
+		return accountOf(c)
+	default:
+		return ""
+	}
+}
+
+// principalOf returns the ID of the principal the request authenticates as
+// Rate limiting runs before the routes that require authentication, so the credential is
+// checked here too; invalid credentials are left for RequireAuth to reject. A key in the query
+// is counted too, as the routes accepting one are not known here; counting grants no access.
+func principalOf(c *gin.Context, authenticator auth.Authenticator) string {
+	if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+		return principal.ID
+	}
+
+	credential := credentialFrom(c, true)
+	if credential == "" || authenticator == nil {
+		return ""
+	}
+	principal, err := authenticator.Authenticate(c.Request.Context(), credential)
+	if err != nil {
+		return ""
+	}
+	return principal.ID
+}
+
+// accountOf returns the account the request acts on: the account of the path, or the
+// source account of a JSON body, which is left in place for the handler to read
+func accountOf(c *gin.Context) string {
+	if account := c.Param("account"); account != "" {
+		return account
+	}
+	if strings.Contains(c.FullPath(), "/accounts/:id") {
+		return c.Param("id")
+	}
+	if c.Request.Body == nil || c.Request.ContentLength == 0 {
+		return ""
+	}
+
+	peeked, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAccountPeek))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(peeked), c.Request.Body), c.Request.Body}
+	if err != nil {
+		return ""
+	}
+
+	var body struct {
+		From string `json:"from"`
+	}
+	if json.Unmarshal(peeked, &body) != nil {
+		return ""
+	}
+	return body.From
+}
+
+// readCloser reads a request body partly consumed already and closes the original
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// setRateLimitHeaders describes result in the RateLimit headers of the IETF draft
+func setRateLimitHeaders(c *gin.Context, result ratelimit.Result) {
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit.Count))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ratelimit.CeilSeconds(result.Reset)))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", result.Limit.Count, ratelimit.CeilSeconds(result.Limit.Period)))
+}
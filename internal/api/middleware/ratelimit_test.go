@@ -0,0 +1,182 @@
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/auth"
+	"money-transfer/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingLimiter fails every call, like a limiter whose database is down
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func (failingLimiter) Sweep(context.Context) (int, error) {
+	return 0, errors.New("connection refused")
+}
+
+func setupRateLimitRouter(t *testing.T, limiter ratelimit.Limiter, spec string) *gin.Engine {
+	t.Helper()
+
+	rules, err := ratelimit.ParseRules(spec)
+	require.NoError(t, err)
+	apiKeys, err := auth.ParseAPIKeys("mark-key:mark:customer:Mark")
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(middleware.RateLimit(limiter, rules, apiKeys))
+	router.POST("/transfer", func(c *gin.Context) {
+		// The handler still reads the whole body
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusOK, string(body))
+	})
+	router.GET("/balance/:account", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func TestRateLimit(t *testing.T) {
+	type request struct {
+		path   string
+		body   string
+		apiKey string
+		ip     string
+	}
+	tests := []struct {
+		name       string
+		spec       string
+		requests   []request
+		wantStatus []int
+	}{
+		{
+			name: "by principal",
+			spec: "POST /transfer principal=1/m",
+			requests: []request{
+				{path: "/transfer", apiKey: "mark-key", ip: "10.0.0.1"},
+				{path: "/transfer", apiKey: "mark-key", ip: "10.0.0.2"},
+				// Anonymous requests and invalid keys are not counted by principal
+				{path: "/transfer", ip: "10.0.0.1"},
+				{path: "/transfer", apiKey: "stolen-key", ip: "10.0.0.1"},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK, http.StatusOK},
+		},
+		{
+			name: "by ip",
+			spec: "* ip=1/m",
+			requests: []request{
+				{path: "/balance/Mark", ip: "10.0.0.1"},
+				{path: "/balance/Jane", ip: "10.0.0.1"},
+				{path: "/balance/Mark", ip: "10.0.0.2"},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "by source account of the body",
+			spec: "POST /transfer account=1/m",
+			requests: []request{
+				{path: "/transfer", body: `{"from":"Mark","to":"Jane","amount":1}`, ip: "10.0.0.1"},
+				{path: "/transfer", body: `{"from":"Mark","to":"Bob","amount":1}`, ip: "10.0.0.2"},
+				{path: "/transfer", body: `{"from":"Jane","to":"Mark","amount":1}`, ip: "10.0.0.1"},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "by account of the path",
+			spec: "* account=1/m",
+			requests: []request{
+				{path: "/balance/Mark", ip: "10.0.0.1"},
+				{path: "/balance/Mark", ip: "10.0.0.2"},
+				{path: "/balance/Jane", ip: "10.0.0.1"},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusTooManyRequests, http.StatusOK},
+		},
+		{
+			name: "routes with a rule of their own skip the fallback",
+			spec: "POST /transfer ip=5/m; * ip=1/m",
+			requests: []request{
+				{path: "/balance/Mark", ip: "10.0.0.1"},
+				{path: "/transfer", ip: "10.0.0.1"},
+				{path: "/balance/Mark", ip: "10.0.0.1"},
+			},
+			wantStatus: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := setupRateLimitRouter(t, ratelimit.NewMemory(), tt.spec)
+
+			for i, r := range tt.requests {
+				method := http.MethodGet
+				if r.path == "/transfer" {
+					method = http.MethodPost
+				}
+				req := httptest.NewRequest(method, r.path, strings.NewReader(r.body))
+				req.RemoteAddr = r.ip + ":1234"
+				if r.apiKey != "" {
+					req.Header.Set(middleware.APIKeyHeader, r.apiKey)
+				}
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.wantStatus[i], w.Code, "request %d", i)
+				if w.Code == http.StatusOK && r.body != "" {
+					assert.Equal(t, r.body, w.Body.String(), "request %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	router := setupRateLimitRouter(t, ratelimit.NewMemory(), "* ip=2/m principal=1/m")
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/balance/Mark", nil)
+		req.Header.Set(middleware.APIKeyHeader, "mark-key")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The most constrained limit is described
+	w := serve()
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", w.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "1;w=60", w.Header().Get("RateLimit-Policy"))
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	w = serve()
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `"code":"too_many_requests"`)
+}
+
+func TestRateLimit_LetsRequestsThroughWhenLimiterFails(t *testing.T) {
+	router := setupRateLimitRouter(t, failingLimiter{}, "* ip=1/m")
+
+	for range 3 {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/balance/Mark", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+
+	"money-transfer/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDHeader returns the trace ID of a request to the client
+const TraceIDHeader = "X-Trace-ID"
+
+var tracer = otel.Tracer("money-transfer/internal/api")
+
+// Trace records every request as a server span, continuing the trace of an incoming
+// W3C traceparent header when present, and echoes its trace ID in the X-Trace-ID header
+func Trace() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := tracing.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+			))
+		defer span.End()
+
+		// Without a tracer provider a new trace has no ID, but clients still get one to quote
+		traceID := tracing.TraceIDFromContext(ctx)
+		if traceID == "" {
+			traceID = tracing.NewTraceID()
+			ctx = tracing.WithTraceID(ctx, traceID)
+		}
+
+		c.Header(TraceIDHeader, traceID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
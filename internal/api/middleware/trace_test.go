@@ -0,0 +1,107 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTrace(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	tests := []struct {
+		name        string
+		traceparent string
+		path        string
+		wantTraceID string
+		wantName    string
+		wantStatus  int
+	}{
+		{
+			name:        "continues incoming trace",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			path:        "/accounts/Mark",
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantName:    "GET /accounts/:id",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "starts new trace when header is malformed",
+			traceparent: "00-not-a-trace-01",
+			path:        "/accounts/Mark",
+			wantName:    "GET /accounts/:id",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:       "server errors mark the span",
+			path:       "/fail",
+			wantName:   "GET /fail",
+			wantStatus: http.StatusInternalServerError,
+		},
+		{
+			name:       "unmatched route",
+			path:       "/nowhere",
+			wantName:   "GET unmatched",
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+
+			var handlerTraceID string
+			router := gin.New()
+			router.Use(middleware.Trace())
+			router.GET("/accounts/:id", func(c *gin.Context) {
+				handlerTraceID = tracing.TraceIDFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+			router.GET("/fail", func(c *gin.Context) {
+				c.Status(http.StatusInternalServerError)
+			})
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			traceID := w.Header().Get(middleware.TraceIDHeader)
+			if tt.wantTraceID != "" {
+				assert.Equal(t, tt.wantTraceID, traceID)
+			} else {
+				assert.Len(t, traceID, 32)
+			}
+			if tt.wantStatus == http.StatusOK {
+				assert.Equal(t, traceID, handlerTraceID)
+			}
+
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			span := spans[len(spans)-1]
+			assert.Equal(t, tt.wantName, span.Name())
+			assert.Equal(t, traceID, span.SpanContext().TraceID().String())
+			assert.Contains(t, span.Attributes(), attribute.Int("http.response.status_code", tt.wantStatus))
+			if tt.wantStatus >= http.StatusInternalServerError {
+				assert.Equal(t, codes.Error, span.Status().Code)
+			} else {
+				assert.Equal(t, codes.Unset, span.Status().Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package problem
+
+import (
+	"errors"
+	"net/http"
+
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// Kind describes one type of problem: its stable code, HTTP status and title
+type Kind struct {
+	Code   string
+	Status int
+	Title  string
+}
+
+// Stable error codes; clients may rely on them not changing
+const (
+	CodeInvalidRequest            = "invalid_request"
+	CodeValidationFailed          = "validation_failed"
+	CodeUnauthenticated           = "unauthenticated"
+	CodeForbidden                 = "forbidden"
+	CodeNotFound                  = "not_found"
+	CodeMethodNotAllowed          = "method_not_allowed"
+	CodeConflict                  = "conflict"
+	CodeTooManyRequests           = "too_many_requests"
+	CodeRequestTooLarge           = "request_too_large"
+	CodeInternal                  = "internal_error"
+	CodeUnavailable               = "service_unavailable"
+	CodeConcurrentUpdate          = "concurrent_update"
+	CodeAccountNotFound           = "account_not_found"
+	CodeTransferNotFound          = "transfer_not_found"
+	CodeInsufficientFunds         = "insufficient_funds"
+	CodeInvalidAmount             = "invalid_amount"
+	CodeSameAccount               = "same_account"
+	CodeSystemAccount             = "system_account"
+	CodeInvalidAccountIdentifier  = "invalid_account_identifier"
+	CodeInvalidStatusTransition   = "invalid_status_transition"
+	CodeWebhookNotFound           = "webhook_not_found"
+	CodeWebhookDeliveryNotFound   = "webhook_delivery_not_found"
+	CodeInvalidWebhookURL         = "invalid_webhook_url"
+	CodeInvalidEventType          = "invalid_event_type"
+	CodeInvalidPeriod             = "invalid_period"
+	CodeFutureTime                = "future_time"
+	CodeInvalidPaymentMessage     = "invalid_payment_message"
+	CodePayoutNotFound            = "payout_not_found"
+	CodeUnknownRail               = "unknown_rail"
+	CodeACHFileNotFound           = "ach_file_not_found"
+	CodeInvalidFundingAccount     = "invalid_funding_account"
+	CodeReferenceConflict         = "reference_conflict"
+	CodeTransferBlocked           = "transfer_blocked"
+	CodeScreeningDecisionNotFound = "screening_decision_not_found"
+)
+
+// Internal is the kind reported for errors that are not domain errors
+var Internal = Kind{CodeInternal, http.StatusInternalServerError, "Internal server error"}
+
+// ValidationFailed is the kind reported for requests with invalid fields
+var ValidationFailed = Kind{CodeValidationFailed, http.StatusBadRequest, "Validation failed"}
+
+// kinds maps domain error sentinels to the problems they are reported as
+var kinds = []struct {
+	err error
+	Kind
+}{
+	{transfererrors.ErrAccountNotFound, Kind{CodeAccountNotFound, http.StatusNotFound, "Account not found"}},
+	{transfererrors.ErrTransferNotFound, Kind{CodeTransferNotFound, http.StatusNotFound, "Transfer not found"}},
+	{transfererrors.ErrInsufficientFunds, Kind{CodeInsufficientFunds, http.StatusBadRequest, "Insufficient funds"}},
+	{transfererrors.ErrInvalidAmount, Kind{CodeInvalidAmount, http.StatusBadRequest, "Invalid amount"}},
+	{transfererrors.ErrSameAccount, Kind{CodeSameAccount, http.StatusBadRequest, "Transfer to the same account"}},
+	{transfererrors.ErrSystemAccount, Kind{CodeSystemAccount, http.StatusForbidden, "Transfer from a system account"}},
+	{transfererrors.ErrInvalidAccountIdentifier,
+		Kind{CodeInvalidAccountIdentifier, http.StatusBadRequest, "Invalid account identifier"}},
+	{transfererrors.ErrInvalidStatusTransition,
+		Kind{CodeInvalidStatusTransition, http.StatusConflict, "Invalid transfer status transition"}},
+	{transfererrors.ErrInvalidPeriod, Kind{CodeInvalidPeriod, http.StatusBadRequest, "Invalid period"}},
+	{transfererrors.ErrFutureTime, Kind{CodeFutureTime, http.StatusBadRequest, "Time in the future"}},
+	{transfererrors.ErrInvalidPaymentMessage,
+		Kind{CodeInvalidPaymentMessage, http.StatusBadRequest, "Invalid payment message"}},
+	{transfererrors.ErrPayoutNotFound, Kind{CodePayoutNotFound, http.StatusNotFound, "Payout not found"}},
+	{transfererrors.ErrUnknownRail, Kind{CodeUnknownRail, http.StatusBadRequest, "Unknown payment rail"}},
+	{transfererrors.ErrACHFileNotFound, Kind{CodeACHFileNotFound, http.StatusNotFound, "ACH file not found"}},
+	{transfererrors.ErrInvalidFundingAccount,
+		Kind{CodeInvalidFundingAccount, http.StatusBadRequest, "Invalid funding account"}},
+	{transfererrors.ErrReferenceConflict,
+		Kind{CodeReferenceConflict, http.StatusConflict, "Reference already used with other details"}},
+	{transfererrors.ErrTransferBlocked, Kind{CodeTransferBlocked, http.StatusForbidden, "Transfer blocked by sanctions screening"}},
+	{transfererrors.ErrScreeningDecisionNotFound,
+		Kind{CodeScreeningDecisionNotFound, http.StatusNotFound, "Screening decision not found"}},
+	{transfererrors.ErrWebhookNotFound, Kind{CodeWebhookNotFound, http.StatusNotFound, "Webhook subscription not found"}},
+	{transfererrors.ErrWebhookDeliveryNotFound,
+		Kind{CodeWebhookDeliveryNotFound, http.StatusNotFound, "Webhook delivery not found"}},
+	{transfererrors.ErrInvalidWebhookURL, Kind{CodeInvalidWebhookURL, http.StatusBadRequest, "Invalid webhook URL"}},
+	{transfererrors.ErrInvalidEventType, Kind{CodeInvalidEventType, http.StatusBadRequest, "Unknown event type"}},
+	{transfererrors.ErrSerializationFailure,
+		Kind{CodeConcurrentUpdate, http.StatusServiceUnavailable, "Conflicted with a concurrent update, retry"}},
+	{transfererrors.ErrUnauthenticated, Kind{CodeUnauthenticated, http.StatusUnauthorized, "Unauthenticated"}},
+	{transfererrors.ErrForbidden, Kind{CodeForbidden, http.StatusForbidden, "Forbidden"}},
+	{transfererrors.ErrRateLimited, Kind{CodeTooManyRequests, http.StatusTooManyRequests, "Too many requests"}},
+}
+
+// statusKinds holds the generic problem kind of each HTTP status used without a domain error
+var statusKinds = map[int]Kind{
+	http.StatusBadRequest:            {CodeInvalidRequest, http.StatusBadRequest, "Invalid request"},
+	http.StatusUnauthorized:          {CodeUnauthenticated, http.StatusUnauthorized, "Unauthenticated"},
+	http.StatusForbidden:             {CodeForbidden, http.StatusForbidden, "Forbidden"},
+	http.StatusNotFound:              {CodeNotFound, http.StatusNotFound, "Not found"},
+	http.StatusMethodNotAllowed:      {CodeMethodNotAllowed, http.StatusMethodNotAllowed, "Method not allowed"},
+	http.StatusConflict:              {CodeConflict, http.StatusConflict, "Conflict"},
+	http.StatusRequestEntityTooLarge: {CodeRequestTooLarge, http.StatusRequestEntityTooLarge, "Request body too large"},
+	http.StatusTooManyRequests:       {CodeTooManyRequests, http.StatusTooManyRequests, "Too many requests"},
+	http.StatusInternalServerError:   Internal,
+	http.StatusServiceUnavailable:    {CodeUnavailable, http.StatusServiceUnavailable, "Service unavailable"},
+}
+
+// KindOf returns the problem kind err is reported as
+func KindOf(err error) Kind {
+	for _, kind := range kinds {
+		if errors.Is(err, kind.err) {
+			return kind.Kind
+		}
+	}
+	return Internal
+}
@@ -0,0 +1,100 @@
+// Package problem writes API errors as RFC 7807 problem details
+package problem
+
+import (
+	"net/http"
+
+	"money-transfer/internal/logging"
+	"money-transfer/internal/tracing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem detail responses
+const ContentType = "application/problem+json"
+
+// typePrefix makes error codes into the URIs identifying problem types
+const typePrefix = "urn:money-transfer:problem:"
+
+// Problem is an RFC 7807 problem details object extended with a stable error code and trace ID
+type Problem struct {
+	Type     string       `json:"type" example:"urn:money-transfer:problem:account_not_found"`   // URI identifying the problem type
+	Title    string       `json:"title" example:"Account not found"`                             // Short summary of the problem type
+	Status   int          `json:"status" example:"404"`                                          // HTTP status code
+	Detail   string       `json:"detail,omitempty" example:"account not found"`                  // Explanation of this occurrence
+	Instance string       `json:"instance,omitempty" example:"/api/v1/balance/Bob"`              // Request path that failed
+	Code     string       `json:"code" example:"account_not_found"`                              // Stable machine-readable error code
+	TraceID  string       `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"` // Trace ID to quote when reporting the error
+	Errors   []FieldError `json:"errors,omitempty"`                                              // Invalid fields of a validation_failed problem
+}
+
+// FieldError describes why a single request field is invalid
+type FieldError struct {
+	Field   string `json:"field" example:"amount"`                               // JSON name of the field
+	Code    string `json:"code" example:"decimals"`                              // Rule the value breaks
+	Message string `json:"message" example:"must have at most 2 decimal places"` // Human readable explanation
+}
+
+// Error writes the problem for err and aborts the request
+// Known domain errors are reported with their code and message; any other error
+// is logged with the trace ID and reported as an internal error without details.
+func Error(c *gin.Context, err error) {
+	if kind := KindOf(err); kind != Internal {
+		write(c, kind, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	logging.FromContext(ctx).ErrorContext(ctx, "internal error",
+		"method", c.Request.Method, "path", c.Request.URL.Path, "error", err)
+	write(c, Internal, "")
+}
+
+// Status writes a problem with the generic code of an HTTP status and aborts the request
+// It reports errors that are not domain errors, such as malformed query parameters.
+func Status(c *gin.Context, status int, detail string) {
+	kind, ok := statusKinds[status]
+	if !ok {
+		kind = Kind{Code: CodeInvalidRequest, Status: status, Title: http.StatusText(status)}
+		if status >= http.StatusInternalServerError {
+			kind.Code = CodeInternal
+		}
+	}
+	write(c, kind, detail)
+}
+
+// BadRequest writes an invalid_request problem and aborts the request
+func BadRequest(c *gin.Context, detail string) {
+	Status(c, http.StatusBadRequest, detail)
+}
+
+// Invalid writes a validation_failed problem listing the invalid fields and aborts the request
+func Invalid(c *gin.Context, errs ...FieldError) {
+	p := newProblem(c, ValidationFailed, "request has invalid fields")
+	p.Errors = errs
+	render(c, p)
+}
+
+// write renders the problem of the given kind
+func write(c *gin.Context, kind Kind, detail string) {
+	render(c, newProblem(c, kind, detail))
+}
+
+// newProblem returns the problem of the given kind for the current request
+func newProblem(c *gin.Context, kind Kind, detail string) Problem {
+	return Problem{
+		Type:     typePrefix + kind.Code,
+		Title:    kind.Title,
+		Status:   kind.Status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Code:     kind.Code,
+		TraceID:  tracing.TraceIDFromContext(c.Request.Context()),
+	}
+}
+
+// render writes p as application/problem+json
+func render(c *gin.Context, p Problem) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
@@ -0,0 +1,110 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve runs handler behind the trace middleware and decodes the problem it writes
+func serve(t *testing.T, req *http.Request, handler gin.HandlerFunc) (*httptest.ResponseRecorder, problem.Problem) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(middleware.Trace())
+	router.GET("/resource", handler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var body problem.Problem
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	return w, body
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{
+			name:       "sentinel",
+			err:        transfererrors.ErrAccountNotFound,
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeAccountNotFound,
+			wantDetail: "account not found",
+		},
+		{
+			name:       "wrapped sentinel",
+			err:        fmt.Errorf("get balance of Bob: %w", transfererrors.ErrAccountNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   problem.CodeAccountNotFound,
+			wantDetail: "get balance of Bob: account not found",
+		},
+		{
+			name:       "status transition conflict",
+			err:        transfererrors.ErrInvalidStatusTransition,
+			wantStatus: http.StatusConflict,
+			wantCode:   problem.CodeInvalidStatusTransition,
+			wantDetail: "invalid transfer status transition",
+		},
+		{
+			name:       "concurrent update may be retried",
+			err:        fmt.Errorf("%w: pq: could not serialize access", transfererrors.ErrSerializationFailure),
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   problem.CodeConcurrentUpdate,
+			wantDetail: "transaction conflicted with a concurrent transaction: pq: could not serialize access",
+		},
+		{
+			name:       "unknown error is not exposed",
+			err:        errors.New("pq: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   problem.CodeInternal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/resource", nil)
+			w, body := serve(t, req, func(c *gin.Context) { problem.Error(c, tt.err) })
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantStatus, body.Status)
+			assert.Equal(t, tt.wantCode, body.Code)
+			assert.Equal(t, "urn:money-transfer:problem:"+tt.wantCode, body.Type)
+			assert.NotEmpty(t, body.Title)
+			assert.Equal(t, tt.wantDetail, body.Detail)
+			assert.Equal(t, "/resource", body.Instance)
+			assert.Len(t, body.TraceID, 32)
+			assert.Equal(t, w.Header().Get(middleware.TraceIDHeader), body.TraceID)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+	req := httptest.NewRequest("GET", "/resource", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	w, body := serve(t, req, func(c *gin.Context) { problem.BadRequest(c, "limit must be a positive integer") })
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, problem.CodeInvalidRequest, body.Code)
+	assert.Equal(t, "limit must be a positive integer", body.Detail)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", body.TraceID)
+}
@@ -1,7 +1,14 @@
 package router
 
 import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
 	"money-transfer/internal/api/interfaces"
+	"money-transfer/internal/api/middleware"
+	"money-transfer/internal/api/problem"
 
 	"github.com/gin-gonic/gin"
 
@@ -11,14 +18,40 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-// NewRouter creates and configures a new router
-func NewRouter(handlers []interfaces.Handler) *gin.Engine {
+// NewRouter creates and configures a new router logging requests to logger
+func NewRouter(handlers []interfaces.Handler, logger *slog.Logger) *gin.Engine {
 	router := gin.New()
-	router.Use(gin.Recovery())
+	router.Use(middleware.RequestID())
+	router.Use(middleware.ClientIP())
+	router.Use(middleware.Trace())
+	router.Use(middleware.AccessLog(logger))
+
+	// Handler middleware is installed before any route exists, so it applies to all of them,
+	// and outside recovery, so it sees the status of requests that panicked
+	for _, h := range handlers {
+		if m, ok := h.(interfaces.MiddlewareHandler); ok {
+			router.Use(m.Middleware())
+		}
+	}
+
+	router.Use(gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		logger.ErrorContext(c.Request.Context(), "panic while serving request", "error", fmt.Sprint(err))
+		problem.Status(c, http.StatusInternalServerError, "")
+	}))
+	router.NoRoute(func(c *gin.Context) {
+		problem.Status(c, http.StatusNotFound, "no route matches "+c.Request.URL.Path)
+	})
 
 	// Swagger route
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Routes outside the versioned API, such as probes and metrics
+	for _, h := range handlers {
+		if root, ok := h.(interfaces.RootHandler); ok {
+			root.RegisterRoot(router)
+		}
+	}
+
 	// API v1 group
 	v1 := router.Group("/api/v1")
 
@@ -0,0 +1,86 @@
+// Package server runs the HTTP API and shuts it down without cutting off requests in flight
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"money-transfer/internal/lifecycle"
+)
+
+// Config holds the settings of the HTTP server
+type Config struct {
+	Addr    string
+	Handler http.Handler
+	// Timeouts of the underlying http.Server; zero means none
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+// Server serves HTTP and tracks every handler still running, including those of
+// hijacked connections such as WebSockets, which http.Server.Shutdown does not wait for
+type Server struct {
+	srv    *http.Server
+	logger *slog.Logger
+	active sync.WaitGroup
+}
+
+// New creates a server logging serve failures to logger
+func New(cfg Config, logger *slog.Logger) *Server {
+	s := &Server{logger: logger}
+	s.srv = &http.Server{
+		Addr:         cfg.Addr,
+		Handler:      s.track(cfg.Handler),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	return s
+}
+
+// Hook returns the lifecycle hook serving HTTP between start and stop
+func (s *Server) Hook() lifecycle.Hook {
+	return lifecycle.Hook{Name: "http server", OnStart: s.Start, OnStop: s.Stop}
+}
+
+// Start listens on the configured address and serves in the background
+// Listen errors, such as a port in use, are returned.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "starting HTTP server", "addr", listener.Addr().String())
+	go func() {
+		if err := s.srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("HTTP server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Stop stops accepting connections and waits until ctx is done for the requests in flight
+// Long-lived streams must end on their own, e.g. when the lifecycle starts stopping.
+func (s *Server) Stop(ctx context.Context) error {
+	if err := s.srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	// No handler can start once Shutdown has returned, so waiting here is safe
+	return lifecycle.Wait(ctx, s.active.Wait)
+}
+
+// track counts the handlers running in next
+func (s *Server) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.active.Add(1)
+		defer s.active.Done()
+		next.ServeHTTP(w, r)
+	})
+}
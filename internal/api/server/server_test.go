@@ -0,0 +1,106 @@
+package server_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"money-transfer/internal/api/server"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// freeAddr returns a local address nothing listens on
+func freeAddr(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	return addr
+}
+
+func TestServer_StopWaitsForRequestsInFlight(t *testing.T) {
+	tests := []struct {
+		name    string
+		handle  time.Duration
+		timeout time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "request finishes before the deadline",
+			handle:  50 * time.Millisecond,
+			timeout: 2 * time.Second,
+		},
+		{
+			name:    "request outlives the deadline",
+			handle:  2 * time.Second,
+			timeout: 50 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			started := make(chan struct{})
+			addr := freeAddr(t)
+			srv := server.New(server.Config{
+				Addr: addr,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					close(started)
+					time.Sleep(tt.handle)
+					w.WriteHeader(http.StatusNoContent)
+				}),
+			}, logging.Discard())
+			require.NoError(t, srv.Start(context.Background()))
+
+			type result struct {
+				status int
+				err    error
+			}
+			results := make(chan result, 1)
+			go func() {
+				resp, err := http.Get("http://" + addr)
+				if err != nil {
+					results <- result{err: err}
+					return
+				}
+				resp.Body.Close()
+				results <- result{status: resp.StatusCode}
+			}()
+			<-started
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			err := srv.Stop(ctx)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+				return
+			}
+			require.NoError(t, err)
+			res := <-results
+			require.NoError(t, res.err)
+			assert.Equal(t, http.StatusNoContent, res.status)
+
+			// New connections are refused once stopped
+			_, err = http.Get("http://" + addr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestServer_StartFailsOnAddressInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	srv := server.New(server.Config{Addr: listener.Addr().String(), Handler: http.NotFoundHandler()}, logging.Discard())
+
+	assert.Error(t, srv.Start(context.Background()))
+}
@@ -3,6 +3,7 @@ package testutil
 import (
 	"money-transfer/internal/api/interfaces"
 	"money-transfer/internal/api/router"
+	"money-transfer/internal/logging"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,5 +11,5 @@ import (
 // SetupTestRouter creates a router for testing
 func SetupTestRouter(handlers []interfaces.Handler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
-	return router.NewRouter(handlers)
+	return router.NewRouter(handlers, logging.Discard())
 }
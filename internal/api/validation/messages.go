@@ -0,0 +1,67 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/identifier"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// message explains a failed rule to the client
+func message(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "account_id":
+		return identifier.AccountIDRule
+	case "decimals":
+		return fmt.Sprintf("must have at most %s decimal places", fe.Param())
+	case "currency":
+		return fmt.Sprintf("must be a supported ISO 4217 currency code (%s)", models.Currency)
+	case "external_scheme":
+		return fmt.Sprintf("must be one of %s", joinSchemes(identifier.ExternalSchemes()))
+	case "account_identifier":
+		return "is not a valid account identifier of its scheme"
+	case "nefield":
+		return fmt.Sprintf("must differ from %s", strings.ToLower(fe.Param()))
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", fe.Param())
+	case "gte", "min":
+		return fmt.Sprintf("must be at least %s%s", fe.Param(), unit(fe))
+	case "lt":
+		return fmt.Sprintf("must be less than %s", fe.Param())
+	case "lte", "max":
+		return fmt.Sprintf("must be at most %s%s", fe.Param(), unit(fe))
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", fe.Param())
+	case "printascii":
+		return "must consist of printable ASCII characters"
+	default:
+		return fmt.Sprintf("does not satisfy %s", fe.Tag())
+	}
+}
+
+// unit is appended to the bound of length rules on strings and collections
+func unit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.String:
+		return " characters long"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return " items long"
+	default:
+		return ""
+	}
+}
+
+// joinSchemes lists scheme names separated by spaces, as oneof parameters are
+func joinSchemes(schemes []models.IdentifierScheme) string {
+	names := make([]string, len(schemes))
+	for i, scheme := range schemes {
+		names[i] = string(scheme)
+	}
+	return strings.Join(names, " ")
+}
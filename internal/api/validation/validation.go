@@ -0,0 +1,243 @@
+// Package validation checks API requests against the rules declared in their binding tags
+//
+// Besides the built-in rules of go-playground/validator it provides:
+//   - account_id: a UUID, or a legacy account ID of 1-64 letters, digits, '-' or '_' starting with a letter or digit
+//   - decimals=N: a number with at most N decimal places
+//   - currency: a supported ISO 4217 currency code
+//   - external_scheme: a registered identifier scheme of accounts held at other banks
+//   - account_identifier: an identifier of the scheme named by the Scheme field next to it
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/identifier"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// DefaultMaxBodySize bounds the JSON bodies read by BindJSON when no limit is given
+const DefaultMaxBodySize = 1 << 20
+
+var registerOnce sync.Once
+
+// engine returns gin's validator with the custom rules registered
+func engine() *validator.Validate {
+	v := binding.Validator.Engine().(*validator.Validate)
+	registerOnce.Do(func() {
+		v.RegisterTagNameFunc(fieldName)
+		_ = v.RegisterValidation("account_id", isAccountID)
+		_ = v.RegisterValidation("decimals", hasDecimals)
+		_ = v.RegisterValidation("currency", isCurrency)
+		_ = v.RegisterValidation("external_scheme", isExternalScheme)
+		_ = v.RegisterValidation("account_identifier", isAccountIdentifier)
+	})
+	return v
+}
+
+// BindJSON decodes the JSON body into obj, rejecting unknown fields, and validates it
+// Bodies over maxSize bytes, DefaultMaxBodySize when it is not positive, are rejected with 413
+// before they are read in full. On failure it writes the problem and returns false.
+func BindJSON(c *gin.Context, obj any, maxSize int64) bool {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxBodySize
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			problem.Status(c, http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("request body must not exceed %d bytes", maxSize))
+			return false
+		}
+		problem.BadRequest(c, "request body could not be read")
+		return false
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		writeDecodeError(c, err)
+		return false
+	}
+	if decoder.More() {
+		problem.BadRequest(c, "request body must contain a single JSON object")
+		return false
+	}
+
+	if errs := Struct(obj); len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return false
+	}
+	return true
+}
+
+// Param validates the path parameter against the rules in tag and returns its value
+// On failure it writes the problem and returns false.
+func Param(c *gin.Context, name, tag string) (string, bool) {
+	value := c.Param(name)
+	if errs := Value(name, value, tag); len(errs) > 0 {
+		problem.Invalid(c, errs...)
+		return "", false
+	}
+	return value, true
+}
+
+// Value validates a single value, reported as field, against the rules in tag
+func Value(field string, value any, tag string) []problem.FieldError {
+	err := engine().Var(value, tag)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return []problem.FieldError{{Field: field, Code: "invalid", Message: err.Error()}}
+	}
+	return []problem.FieldError{{Field: field, Code: invalid[0].Tag(), Message: message(invalid[0])}}
+}
+
+// Struct validates obj and returns an error for every invalid field
+func Struct(obj any) []problem.FieldError {
+	err := engine().Struct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return []problem.FieldError{{Code: "invalid", Message: err.Error()}}
+	}
+
+	errs := make([]problem.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		errs = append(errs, problem.FieldError{
+			Field:   fieldPath(fe),
+			Code:    fe.Tag(),
+			Message: message(fe),
+		})
+	}
+	return errs
+}
+
+// writeDecodeError reports why the body could not be decoded
+func writeDecodeError(c *gin.Context, err error) {
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		problem.BadRequest(c, "request body is required")
+	case errors.As(err, &typeErr):
+		problem.Invalid(c, problem.FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: "must be " + jsonType(typeErr.Type),
+		})
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field, _ := strconv.Unquote(strings.TrimPrefix(err.Error(), "json: unknown field "))
+		problem.Invalid(c, problem.FieldError{Field: field, Code: "unknown", Message: "is not a known field"})
+	default:
+		problem.BadRequest(c, "request body is not valid JSON")
+	}
+}
+
+// embeddedPrefix marks embedded structs, whose fields JSON flattens into the parent
+const embeddedPrefix = "~"
+
+// fieldName names struct fields after their json, form or uri tag
+func fieldName(field reflect.StructField) string {
+	if field.Anonymous && field.Tag.Get("json") == "" {
+		return embeddedPrefix + field.Name
+	}
+	for _, tag := range []string{"json", "form", "uri"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// fieldPath returns the dotted JSON path of the field below the validated struct
+func fieldPath(fe validator.FieldError) string {
+	segments := strings.Split(fe.Namespace(), ".")[1:]
+	path := segments[:0]
+	for _, segment := range segments {
+		if !strings.HasPrefix(segment, embeddedPrefix) {
+			path = append(path, segment)
+		}
+	}
+	return strings.Join(path, ".")
+}
+
+// jsonType names the JSON type that decodes into t
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
+
+func isAccountID(fl validator.FieldLevel) bool {
+	return identifier.ValidateAccountID(fl.Field().String()) == nil
+}
+
+func hasDecimals(fl validator.FieldLevel) bool {
+	places, err := strconv.Atoi(fl.Param())
+	if err != nil {
+		return false
+	}
+
+	formatted := strconv.FormatFloat(fl.Field().Float(), 'f', -1, 64)
+	_, fraction, _ := strings.Cut(formatted, ".")
+	return len(fraction) <= places
+}
+
+func isCurrency(fl validator.FieldLevel) bool {
+	return fl.Field().String() == models.Currency
+}
+
+func isExternalScheme(fl validator.FieldLevel) bool {
+	scheme := models.IdentifierScheme(fl.Field().String())
+	_, ok := identifier.Lookup(scheme)
+	return ok && !identifier.IsLedgerScheme(scheme)
+}
+
+// isAccountIdentifier validates the field against the scheme in the Scheme field of the same struct
+// Identifiers of unknown schemes pass, leaving the Scheme field to be reported.
+func isAccountIdentifier(fl validator.FieldLevel) bool {
+	scheme := fl.Parent().FieldByName("Scheme")
+	if !scheme.IsValid() || scheme.Kind() != reflect.String {
+		return false
+	}
+	if _, ok := identifier.Lookup(models.IdentifierScheme(scheme.String())); !ok {
+		return true
+	}
+	_, err := identifier.Normalize(models.IdentifierScheme(scheme.String()), fl.Field().String())
+	return err == nil
+}
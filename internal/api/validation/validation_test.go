@@ -0,0 +1,106 @@
+package validation_test
+
+import (
+	"testing"
+
+	"money-transfer/internal/api/problem"
+	"money-transfer/internal/api/validation"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStruct(t *testing.T) {
+	type transferParams struct {
+		models.TransferRequest
+		Async bool `json:"async"`
+	}
+
+	tests := []struct {
+		name string
+		obj  any
+		want []problem.FieldError
+	}{
+		{
+			name: "valid",
+			obj:  models.TransferRequest{From: "Mark", To: "Jane", Amount: 10.5, Currency: "USD"},
+		},
+		{
+			name: "same account",
+			obj:  models.TransferRequest{From: "Mark", To: "Mark", Amount: 10},
+			want: []problem.FieldError{{Field: "to", Code: "nefield", Message: "must differ from from"}},
+		},
+		{
+			name: "non-positive amount",
+			obj:  models.TransferRequest{From: "Mark", To: "Jane", Amount: -1},
+			want: []problem.FieldError{{Field: "amount", Code: "gt", Message: "must be greater than 0"}},
+		},
+		{
+			name: "embedded struct fields keep their json path",
+			obj:  &transferParams{TransferRequest: models.TransferRequest{From: "Mark", To: "Jane", Amount: 0.125}},
+			want: []problem.FieldError{{Field: "amount", Code: "decimals", Message: "must have at most 2 decimal places"}},
+		},
+		{
+			name: "counterparty",
+			obj: models.TransferRequest{From: "Mark", To: "settlement", Amount: 10, Counterparty: &models.ExternalAccount{
+				Scheme: models.SchemeIBAN, Identifier: "GB82 WEST 1234 5698 7654 32", Name: "Jane Doe",
+			}},
+		},
+		{
+			name: "invalid counterparty",
+			obj: models.TransferRequest{From: "Mark", To: "settlement", Amount: 10, Counterparty: &models.ExternalAccount{
+				Scheme: models.SchemeIBAN, Identifier: "GB83WEST12345698765432",
+			}},
+			want: []problem.FieldError{{
+				Field:   "counterparty.identifier",
+				Code:    "account_identifier",
+				Message: "is not a valid account identifier of its scheme",
+			}},
+		},
+		{
+			name: "internal counterparty",
+			obj: models.TransferRequest{From: "Mark", To: "settlement", Amount: 10, Counterparty: &models.ExternalAccount{
+				Scheme: models.SchemeInternal, Identifier: "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30",
+			}},
+			want: []problem.FieldError{{Field: "counterparty.scheme", Code: "external_scheme", Message: "must be one of aba bban iban sort_code"}},
+		},
+		{
+			name: "legacy counterparty",
+			obj: models.TransferRequest{From: "Mark", To: "settlement", Amount: 10, Counterparty: &models.ExternalAccount{
+				Scheme: models.SchemeLegacy, Identifier: "Jane",
+			}},
+			want: []problem.FieldError{{Field: "counterparty.scheme", Code: "external_scheme", Message: "must be one of aba bban iban sort_code"}},
+		},
+		{
+			name: "UUID account IDs",
+			obj: models.TransferRequest{
+				From: "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30", To: "3F0C2B8E-9D4A-4C1E-8F5B-2A7D6E1C9B31", Amount: 10,
+			},
+			want: []problem.FieldError{{
+				Field: "to",
+				Code:  "account_id",
+				Message: "must be a UUID in lower case, or a legacy account ID of 1-64 letters, digits, '-' or '_' " +
+					"starting with a letter or digit",
+			}},
+		},
+		{
+			name: "short webhook secret",
+			obj:  models.WebhookSubscriptionRequest{URL: "https://example.com/hook", Secret: "short"},
+			want: []problem.FieldError{{Field: "secret", Code: "min", Message: "must be at least 16 characters long"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validation.Struct(tt.obj))
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	assert.Empty(t, validation.Value("account", "Mark_1-a", "required,account_id"))
+	assert.Equal(t,
+		[]problem.FieldError{{Field: "account", Code: "required", Message: "is required"}},
+		validation.Value("account", "", "required,account_id"))
+	assert.Equal(t, "account_id", validation.Value("account", "-Mark", "required,account_id")[0].Code)
+}
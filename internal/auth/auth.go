@@ -0,0 +1,42 @@
+// Package auth identifies API callers and carries them through request contexts
+package auth
+
+import (
+	"context"
+
+	"money-transfer/internal/domain/models"
+)
+
+// Authenticator resolves a credential presented by a caller to a principal
+type Authenticator interface {
+	// Authenticate returns ErrUnauthenticated when the credential is unknown
+	Authenticate(ctx context.Context, credential string) (*models.Principal, error)
+}
+
+// principalKey is the context key under which the authenticated principal is stored
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*models.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*models.Principal)
+	return principal, ok
+}
+
+// clientIPKey is the context key under which the address of the caller is stored
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the network address the caller connected from
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// ClientIPFromContext returns the caller address stored in ctx, or an empty string
+func ClientIPFromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(clientIPKey{}).(string)
+	return ip
+}
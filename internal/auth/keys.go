@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// APIKeys authenticates callers by static API keys
+// Keys are held as SHA-256 digests so lookups do not compare secrets byte by byte
+type APIKeys struct {
+	principals map[[sha256.Size]byte]*models.Principal
+}
+
+// ParseAPIKeys builds an APIKeys authenticator from a comma-separated list of
+// key:principal:role[:account|account...] entries, for example
+// "k1:mark:customer:Mark,k2:ops:admin"
+func ParseAPIKeys(spec string) (*APIKeys, error) {
+	keys := &APIKeys{principals: make(map[[sha256.Size]byte]*models.Principal)}
+
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		fields := strings.Split(entry, ":")
+		if len(fields) < 3 || len(fields) > 4 || fields[0] == "" || fields[1] == "" {
+			// The entry itself is not echoed since it contains the key
+			return nil, fmt.Errorf("invalid api key entry #%d: want key:principal:role[:accounts]", i+1)
+		}
+
+		principal := &models.Principal{ID: fields[1], Role: models.Role(fields[2]), Accounts: []string{}}
+		if !principal.Role.IsValid() {
+			return nil, fmt.Errorf("invalid role %q for principal %q", fields[2], principal.ID)
+		}
+		if len(fields) == 4 && fields[3] != "" {
+			principal.Accounts = strings.Split(fields[3], "|")
+		}
+
+		digest := sha256.Sum256([]byte(fields[0]))
+		if _, ok := keys.principals[digest]; ok {
+			return nil, fmt.Errorf("duplicate api key for principal %q", principal.ID)
+		}
+		keys.principals[digest] = principal
+	}
+
+	return keys, nil
+}
+
+// Authenticate returns the principal the key was issued to
+func (k *APIKeys) Authenticate(_ context.Context, credential string) (*models.Principal, error) {
+	principal, ok := k.principals[sha256.Sum256([]byte(credential))]
+	if !ok {
+		return nil, transfererrors.ErrUnauthenticated
+	}
+	return principal, nil
+}
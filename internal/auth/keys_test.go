@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{name: "empty", spec: ""},
+		{name: "customer and admin", spec: "k1:mark:customer:Mark|Adam, k2:ops:admin"},
+		{name: "missing role", spec: "k1:mark", wantErr: true},
+		{name: "unknown role", spec: "k1:mark:owner:Mark", wantErr: true},
+		{name: "empty key", spec: ":mark:customer:Mark", wantErr: true},
+		{name: "duplicate key", spec: "k1:mark:customer:Mark,k1:jane:customer:Jane", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAPIKeys(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestAPIKeys_Authenticate(t *testing.T) {
+	keys, err := ParseAPIKeys("k1:mark:customer:Mark|Adam,k2:ops:admin")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	principal, err := keys.Authenticate(ctx, "k1")
+	require.NoError(t, err)
+	assert.Equal(t, "mark", principal.ID)
+	assert.Equal(t, models.RoleCustomer, principal.Role)
+	assert.True(t, principal.CanAccessAccount("Adam"))
+	assert.False(t, principal.CanAccessAccount("Jane"))
+
+	principal, err = keys.Authenticate(ctx, "k2")
+	require.NoError(t, err)
+	assert.True(t, principal.CanAccessAccount("Jane"))
+
+	_, err = keys.Authenticate(ctx, "unknown")
+	assert.ErrorIs(t, err, transfererrors.ErrUnauthenticated)
+}
@@ -3,7 +3,26 @@ package models
 // Account represents a bank account entity
 // ID is a unique identifier for the account
 // Balance represents the current monetary amount in the account
+// HolderName is the name of the customer owning the account, screened against sanctions lists
+// System is set on the accounts standing for money held outside the ledger, such as cash or
+// clearing, which only the services of this ledger move money out of; they have no holder
 type Account struct {
-	ID      string  `json:"id"`
-	Balance float64 `json:"balance"`
+	ID         string  `json:"id"`
+	Balance    float64 `json:"balance"`
+	HolderName string  `json:"holder_name,omitempty"`
+	System     bool    `json:"system,omitempty"`
+}
+
+// AccountTotals summarizes the customer accounts of the bank
+type AccountTotals struct {
+	Accounts int     `json:"accounts"` // Number of customer accounts
+	Balance  float64 `json:"balance"`  // Sum of all balances under management
+}
+
+// AccountMigration records a customer account named by legacy ID given an account with a UUID
+type AccountMigration struct {
+	LegacyID   string  `json:"legacy_id"`             // Legacy ID of the account migrated
+	ID         string  `json:"id"`                    // UUID of the account it moved to
+	Moved      float64 `json:"moved"`                 // Balance moved to the new account by this migration
+	TransferID string  `json:"transfer_id,omitempty"` // Transfer moving the balance, if there was one
 }
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ACHFile is a NACHA file carrying ACH payouts to the ACH operator
+// A file carries the payouts submitted to the ACH rail since the previous file, each as a credit
+// entry whose trace number is the rail reference of the payout.
+type ACHFile struct {
+	ID         string    `json:"id"`          // Unique file identifier
+	IDModifier string    `json:"id_modifier"` // Tells apart files created on the same day, A to Z then 0 to 9
+	Entries    int       `json:"entries"`     // Number of payouts carried
+	Amount     float64   `json:"amount"`      // Sum of the amounts of the payouts carried
+	CreatedAt  time.Time `json:"created_at"`  // When the file was created
+}
+
+// ACHReturn reports what became of an entry of a return file
+type ACHReturn struct {
+	TraceNumber string `json:"trace_number"`    // Trace number of the entry returned, the rail reference of its payout
+	Code        string `json:"code"`            // Return reason code, such as R01
+	Reason      string `json:"reason"`          // Description of the return reason
+	Error       string `json:"error,omitempty"` // Why the return could not be applied
+}
+
+// ACHSettlement reports the payouts of a file marked settled
+type ACHSettlement struct {
+	FileID  string `json:"file_id"` // File settled
+	Settled int    `json:"settled"` // Number of payouts marked settled; returned payouts stay returned
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ActivityDirection tells whether a transfer moves money into or out of an account
+type ActivityDirection string
+
+// Activity directions
+const (
+	DirectionIncoming ActivityDirection = "incoming"
+	DirectionOutgoing ActivityDirection = "outgoing"
+)
+
+// AccountActivity is a domain event seen from the point of view of a single account
+type AccountActivity struct {
+	Sequence   int64             `json:"sequence"`            // Position in the event log, usable as Last-Event-ID
+	Type       EventType         `json:"type"`                // Kind of event
+	AccountID  string            `json:"account_id"`          // Account the activity belongs to
+	Direction  ActivityDirection `json:"direction,omitempty"` // Set for transfer events
+	Transfer   *Transfer         `json:"transfer,omitempty"`  // Set for transfer events
+	Balance    *float64          `json:"balance,omitempty"`   // Balance after the event, set when it changed
+	OccurredAt time.Time         `json:"occurred_at"`         // When the change was committed
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditAction identifies the kind of change an audit event records
+type AuditAction string
+
+// Audited actions
+const (
+	AuditTransferCreated       AuditAction = "transfer.created"
+	AuditTransferStatusChanged AuditAction = "transfer.status_changed"
+	AuditAccountDebited        AuditAction = "account.debited"
+	AuditAccountCredited       AuditAction = "account.credited"
+	AuditTransferScreened      AuditAction = "transfer.screened"
+	AuditTransferReviewed      AuditAction = "transfer.reviewed"
+)
+
+// AuditActions lists every audited action
+var AuditActions = []AuditAction{
+	AuditTransferCreated,
+	AuditTransferStatusChanged,
+	AuditAccountDebited,
+	AuditAccountCredited,
+	AuditTransferScreened,
+	AuditTransferReviewed,
+}
+
+// IsValid reports whether a is a known audited action
+func (a AuditAction) IsValid() bool {
+	for _, known := range AuditActions {
+		if a == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Actors of changes not made on behalf of an API caller
+const (
+	// ActorAnonymous made a change through an API request without credentials
+	ActorAnonymous = "anonymous"
+	// ActorSystem made a change in the background, such as the transfer executor
+	ActorSystem = "system"
+)
+
+// AuditEvent records who changed what and when
+// Events are recorded with the change they describe and later sealed into a hash chain:
+// each sealed event carries the hash of the one before it, so altering or removing a
+// sealed event breaks every hash after it.
+type AuditEvent struct {
+	Sequence   int64           `json:"sequence"`                    // Order in which events were recorded
+	ID         string          `json:"id"`                          // Unique event identifier
+	OccurredAt time.Time       `json:"occurred_at"`                 // When the change was committed
+	Actor      string          `json:"actor"`                       // Principal, anonymous or system
+	Action     AuditAction     `json:"action"`                      // Kind of change
+	TargetType string          `json:"target_type"`                 // Kind of entity changed: transfer or account
+	TargetID   string          `json:"target_id"`                   // Identifier of that entity
+	TransferID string          `json:"transfer_id,omitempty"`       // Transfer the change belongs to
+	AccountIDs []string        `json:"account_ids"`                 // Accounts involved in the change
+	Before     json.RawMessage `json:"before" swaggertype:"object"` // State before the change, null on creation
+	After      json.RawMessage `json:"after" swaggertype:"object"`  // State after the change
+	RequestID  string          `json:"request_id,omitempty"`        // Request that made the change
+	SourceIP   string          `json:"source_ip,omitempty"`         // Client address of that request
+	ChainIndex *int64          `json:"chain_index,omitempty"`       // Position in the hash chain, nil until sealed
+	PrevHash   string          `json:"prev_hash,omitempty"`         // Hash of the previous event in the chain
+	Hash       string          `json:"hash,omitempty"`              // Hash of this event, empty until sealed
+}
+
+// AuditFilter selects audit events; zero fields match every event
+type AuditFilter struct {
+	Actor         string
+	Action        AuditAction
+	AccountID     string
+	TransferID    string
+	Since         time.Time // Events that occurred at or after
+	Until         time.Time // Events that occurred before
+	AfterSequence int64     // Events recorded after this sequence, for paging
+	Limit         int
+}
+
+// AuditPage is one page of audit events, oldest first
+type AuditPage struct {
+	Events []*AuditEvent `json:"events"`
+	// NextAfter is the after parameter of the next page; zero when this page is the last
+	NextAfter int64 `json:"next_after,omitempty"`
+}
+
+// AuditChainReport describes the result of verifying the audit hash chain
+type AuditChainReport struct {
+	Verified int    `json:"verified"`       // Sealed events whose hashes match
+	Head     string `json:"head,omitempty"` // Hash of the last sealed event
+	Unsealed int    `json:"unsealed"`       // Events recorded but not sealed yet
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType identifies the kind of domain event
+type EventType string
+
+// Domain event types
+const (
+	EventTransferCreated    EventType = "TransferCreated"
+	EventTransferHeld       EventType = "TransferHeld"
+	EventTransferPending    EventType = "TransferPending"
+	EventTransferProcessing EventType = "TransferProcessing"
+	EventTransferCompleted  EventType = "TransferCompleted"
+	EventTransferFailed     EventType = "TransferFailed"
+	EventTransferReversed   EventType = "TransferReversed"
+	EventAccountCreated     EventType = "AccountCreated"
+)
+
+// EventTypes lists every domain event type
+var EventTypes = []EventType{
+	EventTransferCreated,
+	EventTransferHeld,
+	EventTransferPending,
+	EventTransferProcessing,
+	EventTransferCompleted,
+	EventTransferFailed,
+	EventTransferReversed,
+	EventAccountCreated,
+}
+
+// IsValid reports whether t is a known event type
+func (t EventType) IsValid() bool {
+	for _, known := range EventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Aggregate types that domain events refer to
+const (
+	AggregateTransfer = "transfer"
+	AggregateAccount  = "account"
+)
+
+// transferEventTypes maps each transfer status to the event emitted on entering it
+var transferEventTypes = map[TransferStatus]EventType{
+	TransferStatusCreated:    EventTransferCreated,
+	TransferStatusHeld:       EventTransferHeld,
+	TransferStatusPending:    EventTransferPending,
+	TransferStatusProcessing: EventTransferProcessing,
+	TransferStatusCompleted:  EventTransferCompleted,
+	TransferStatusFailed:     EventTransferFailed,
+	TransferStatusReversed:   EventTransferReversed,
+}
+
+// TransferEventType returns the event emitted when a transfer enters status
+func TransferEventType(status TransferStatus) EventType {
+	return transferEventTypes[status]
+}
+
+// Event represents a domain event recorded in the outbox
+// Sequence orders events globally; ID is stable across redeliveries for deduplication
+type Event struct {
+	Sequence      int64           `json:"sequence"`       // Position in the outbox
+	ID            string          `json:"id"`             // Deduplication identifier
+	Type          EventType       `json:"type"`           // Kind of event
+	AggregateType string          `json:"aggregate_type"` // Kind of entity the event is about
+	AggregateID   string          `json:"aggregate_id"`   // Identifier of that entity
+	Payload       json.RawMessage `json:"payload"`        // Event-specific data
+	CreatedAt     time.Time       `json:"created_at"`     // When the change was committed
+}
+
+// TransferEventPayload is the payload of every transfer event
+// Balances are only set on TransferCompleted and TransferReversed, after the funds have moved
+type TransferEventPayload struct {
+	Transfer       Transfer       `json:"transfer"`
+	PreviousStatus TransferStatus `json:"previous_status,omitempty"`
+	FromBalance    *float64       `json:"from_balance,omitempty"`
+	ToBalance      *float64       `json:"to_balance,omitempty"`
+}
+
+// AccountEventPayload is the payload of every account event
+type AccountEventPayload struct {
+	Account Account `json:"account"`
+}
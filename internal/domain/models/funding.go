@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// FundingKind tells in which direction a funding moves money
+type FundingKind string
+
+// Funding kinds
+const (
+	// FundingDeposit credits a customer account with money received from outside the ledger
+	FundingDeposit FundingKind = "deposit"
+	// FundingWithdrawal debits a customer account for money paid out of the ledger
+	FundingWithdrawal FundingKind = "withdrawal"
+)
+
+// Funding moves money into or out of the ledger between a customer account and a system account
+// System accounts such as cash stand for money held outside the ledger, so a deposit may take
+// their balance below zero: a cash account at -100 means 100 was deposited in cash. Each
+// funding is executed as a completed transfer and carries the external reference it is
+// deduplicated by, such as the bank's reference of a deposit.
+type Funding struct {
+	ID            string      `json:"id"`             // Unique funding identifier
+	Kind          FundingKind `json:"kind"`           // Deposit or withdrawal
+	Reference     string      `json:"reference"`      // External reference, unique per kind
+	Account       string      `json:"account"`        // Customer account credited or debited
+	SystemAccount string      `json:"system_account"` // System account on the other side
+	Amount        float64     `json:"amount"`         // Amount moved
+	TransferID    string      `json:"transfer_id"`    // Transfer that moved the funds
+	CreatedAt     time.Time   `json:"created_at"`     // When the funds moved
+}
+
+// From returns the account a funding debits
+func (f *Funding) From() string {
+	if f.Kind == FundingDeposit {
+		return f.SystemAccount
+	}
+	return f.Account
+}
+
+// To returns the account a funding credits
+func (f *Funding) To() string {
+	if f.Kind == FundingDeposit {
+		return f.Account
+	}
+	return f.SystemAccount
+}
+
+// FundingRequest represents the input data for a deposit or withdrawal
+type FundingRequest struct {
+	Account       string  `json:"account" binding:"required,account_id"`                   // Customer account
+	Amount        float64 `json:"amount" binding:"required,gt=0,lte=1000000,decimals=2"`   // Amount to move
+	Currency      string  `json:"currency,omitempty" binding:"omitempty,currency"`         // Currency of the amount
+	Reference     string  `json:"reference" binding:"required,max=64"`                     // External reference
+	SystemAccount string  `json:"system_account,omitempty" binding:"omitempty,account_id"` // Defaults to the cash account
+}
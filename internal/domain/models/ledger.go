@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// PostingKind tells why a balance changed
+type PostingKind string
+
+// Posting kinds
+const (
+	// PostingOpening records the balance an account was created with
+	PostingOpening PostingKind = "opening"
+	// PostingAdjustment records a balance set outside of transfers, as resets of test data once did
+	PostingAdjustment PostingKind = "adjustment"
+	// PostingTransfer records one side of a completed transfer, or of its reversal
+	PostingTransfer PostingKind = "transfer"
+)
+
+// Posting is an entry of the ledger: a change to the balance of one account
+// Every balance change is posted in the transaction that makes it, so the balance after the
+// latest posting of an account is always its current balance.
+type Posting struct {
+	ID           int64       `json:"id"`                     // Order in which postings were made
+	AccountID    string      `json:"account_id"`             // Account whose balance changed
+	Kind         PostingKind `json:"kind"`                   // Why the balance changed
+	TransferID   string      `json:"transfer_id,omitempty"`  // Transfer posted, for transfer postings
+	Counterparty string      `json:"counterparty,omitempty"` // Other account of the transfer
+	Amount       float64     `json:"amount"`                 // Credit when positive, debit when negative
+	BalanceAfter float64     `json:"balance_after"`          // Balance of the account after the posting
+	PostedAt     time.Time   `json:"posted_at"`              // When the balance changed
+}
+
+// AccountLedger is the part of the ledger of one account covering a period, read in one snapshot
+type AccountLedger struct {
+	AccountID string
+	// OpeningBalance is the balance after the last posting before the period, zero when there is none
+	OpeningBalance float64
+	// Postings are the postings of the period, oldest first
+	Postings []*Posting
+	// HeadBalance is the balance after the latest posting of the account, whenever it was made
+	HeadBalance float64
+	// Balance is the current balance stored with the account
+	Balance float64
+}
+
+// AccountBalance is the balance of an account at a point in time
+type AccountBalance struct {
+	AccountID string  `json:"account_id"` // Account the balance is of
+	Balance   float64 `json:"balance"`    // Balance after the last posting made at or before the time
+}
+
+// BalanceSnapshot are the balances of several accounts at the same point in time
+type BalanceSnapshot struct {
+	AsOf     time.Time        `json:"as_of"`    // Point in time the balances are at
+	Balances []AccountBalance `json:"balances"` // Balances of the known accounts requested, by account ID
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PaymentMessage is a pain.001 message executed for a sender, kept so that a repeat of it is
+// answered with the status report it was first answered with
+type PaymentMessage struct {
+	Sender    string          `json:"sender"`     // Principal that sent the message
+	MessageID string          `json:"message_id"` // MsgId of the message, unique per sender
+	Digest    string          `json:"digest"`     // SHA-256 of the message, telling repeats from reused IDs
+	Report    json.RawMessage `json:"report"`     // Status report the message was answered with
+	CreatedAt time.Time       `json:"created_at"` // When the message was executed
+}
+
+// PaymentTransaction is a credit transfer of a pain.001 message, claimed by its debtor account
+// and end-to-end ID before it executes so that it executes once whichever message repeats it
+type PaymentTransaction struct {
+	Debtor     string          `json:"debtor"`        // Account debited
+	EndToEndID string          `json:"end_to_end_id"` // EndToEndId of the transaction, unique per debtor
+	MessageID  string          `json:"message_id"`    // MsgId of the message that first carried it
+	Result     json.RawMessage `json:"result"`        // Status it was reported with, empty while it executes
+	CreatedAt  time.Time       `json:"created_at"`    // When it was claimed
+}
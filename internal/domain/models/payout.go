@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+// PayoutStatus represents a stage in the lifecycle of a payout
+type PayoutStatus string
+
+// Payout lifecycle stages
+const (
+	// PayoutStatusHeld marks a payout whose transfer is held for sanctions review; it is
+	// submitted once the transfer is released
+	PayoutStatusHeld PayoutStatus = "held"
+	// PayoutStatusPending marks a payout whose funds are in the clearing account but not yet
+	// accepted by its rail
+	PayoutStatusPending PayoutStatus = "pending"
+	// PayoutStatusSubmitted marks a payout accepted by its rail, awaiting the outcome
+	PayoutStatusSubmitted PayoutStatus = "submitted"
+	// PayoutStatusSettled marks a payout the rail paid out to the counterparty
+	PayoutStatusSettled PayoutStatus = "settled"
+	// PayoutStatusReturned marks a payout the rail sent back, whose funds went back to its source
+	// account; settled payouts may still be returned
+	PayoutStatusReturned PayoutStatus = "returned"
+	// PayoutStatusFailed marks a payout the rail refused, whose transfer was reversed, or whose
+	// transfer was rejected in sanctions review
+	PayoutStatusFailed PayoutStatus = "failed"
+)
+
+// payoutTransitions lists the statuses each payout status may move to
+var payoutTransitions = map[PayoutStatus][]PayoutStatus{
+	PayoutStatusHeld:      {PayoutStatusPending, PayoutStatusFailed},
+	PayoutStatusPending:   {PayoutStatusSubmitted, PayoutStatusFailed},
+	PayoutStatusSubmitted: {PayoutStatusSettled, PayoutStatusReturned},
+	PayoutStatusSettled:   {PayoutStatusReturned},
+}
+
+// CanTransitionTo reports whether a payout may move from s to next
+func (s PayoutStatus) CanTransitionTo(next PayoutStatus) bool {
+	for _, allowed := range payoutTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether the rail will not report on the payout anymore
+// Settled payouts are not final, since the counterparty bank may still return them.
+func (s PayoutStatus) IsFinal() bool {
+	return s == PayoutStatusReturned || s == PayoutStatusFailed
+}
+
+// Payout sends money from an account of this ledger to an external account through a payment rail
+// The source account is debited by a transfer into the clearing account when the payout is
+// made. Once the rail settles it, the funds move on to the settlement account; when the rail
+// returns or refuses it, the transfer is reversed, or the funds move back from the settlement
+// account when it was settled already.
+type Payout struct {
+	ID                   string          `json:"id"`                               // Unique payout identifier
+	TransferID           string          `json:"transfer_id"`                      // Transfer into the clearing account
+	From                 string          `json:"from"`                             // Account paid out from
+	Amount               float64         `json:"amount"`                           // Amount paid out
+	Counterparty         ExternalAccount `json:"counterparty"`                     // External account paid to
+	Rail                 string          `json:"rail"`                             // Payment rail carrying the payout
+	Status               PayoutStatus    `json:"status"`                           // Current lifecycle stage
+	RailReference        string          `json:"rail_reference,omitempty"`         // Identifier the rail assigned
+	SettlementTransferID string          `json:"settlement_transfer_id,omitempty"` // Transfer into the settlement account
+	FailureReason        string          `json:"failure_reason,omitempty"`         // Why the rail returned or refused it
+	CreatedAt            time.Time       `json:"created_at"`                       // When the payout was made
+	UpdatedAt            time.Time       `json:"updated_at"`                       // When the status last changed
+}
+
+// PayoutRequest represents the input data for a payout
+type PayoutRequest struct {
+	From         string          `json:"from" binding:"required,account_id"`                    // Account to pay out from
+	Amount       float64         `json:"amount" binding:"required,gt=0,lte=1000000,decimals=2"` // Amount to pay out
+	Currency     string          `json:"currency,omitempty" binding:"omitempty,currency"`       // Currency of the amount
+	Counterparty ExternalAccount `json:"counterparty"`                                          // External account to pay to
+	Rail         string          `json:"rail,omitempty" binding:"omitempty,max=32"`             // Payment rail, defaults to the default rail
+	// IdempotencyKey is the Idempotency-Key header of the request, so that a retried request
+	// gets the payout of its first attempt instead of paying out again
+	IdempotencyKey string `json:"-"`
+}
+
+// RailCallback reports the outcome of a payout submitted to a payment rail
+type RailCallback struct {
+	Reference string       `json:"reference" binding:"required,max=64"`              // Identifier the rail assigned
+	Status    PayoutStatus `json:"status" binding:"required,oneof=settled returned"` // Outcome of the payout
+	Reason    string       `json:"reason,omitempty" binding:"max=255"`               // Why the payout was returned
+}
@@ -0,0 +1,36 @@
+package models
+
+import "slices"
+
+// Role determines what a principal is allowed to do
+type Role string
+
+// Principal roles
+const (
+	// RoleCustomer may only act on the accounts it owns
+	RoleCustomer Role = "customer"
+	// RoleAdmin may act on every account
+	RoleAdmin Role = "admin"
+	// RoleAuditor may only read the audit log
+	RoleAuditor Role = "auditor"
+	// RoleRail is a payment rail, which may only report the outcome of payouts and move funds
+	// into and out of the ledger
+	RoleRail Role = "rail"
+)
+
+// IsValid reports whether r is a known role
+func (r Role) IsValid() bool {
+	return r == RoleCustomer || r == RoleAdmin || r == RoleAuditor || r == RoleRail
+}
+
+// Principal is the authenticated caller of the API
+type Principal struct {
+	ID       string   `json:"id"`
+	Role     Role     `json:"role"`
+	Accounts []string `json:"accounts"` // Accounts owned by the principal
+}
+
+// CanAccessAccount reports whether the principal may see or act on the account
+func (p *Principal) CanAccessAccount(accountID string) bool {
+	return p.Role == RoleAdmin || slices.Contains(p.Accounts, accountID)
+}
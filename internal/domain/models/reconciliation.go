@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// DiscrepancyKind tells which reconciliation check failed
+type DiscrepancyKind string
+
+// Discrepancy kinds
+const (
+	// DiscrepancyBalance is a stored balance that differs from the sum of the account's postings
+	DiscrepancyBalance DiscrepancyKind = "balance"
+	// DiscrepancyRunningBalance is a balance recorded with the latest posting of an account that
+	// differs from the sum of its postings
+	DiscrepancyRunningBalance DiscrepancyKind = "running_balance"
+	// DiscrepancyTransfer is a transfer whose postings do not match its status and amount
+	DiscrepancyTransfer DiscrepancyKind = "transfer"
+	// DiscrepancyConservation is money created or destroyed other than by opening or adjusting balances
+	DiscrepancyConservation DiscrepancyKind = "conservation"
+)
+
+// DiscrepancyKinds lists every discrepancy kind
+var DiscrepancyKinds = []DiscrepancyKind{
+	DiscrepancyBalance,
+	DiscrepancyRunningBalance,
+	DiscrepancyTransfer,
+	DiscrepancyConservation,
+}
+
+// Discrepancy is a failed reconciliation check
+type Discrepancy struct {
+	Kind       DiscrepancyKind `json:"kind"`                  // Check that failed
+	AccountID  string          `json:"account_id,omitempty"`  // Account checked, for account checks
+	TransferID string          `json:"transfer_id,omitempty"` // Transfer checked, for transfer checks
+	Expected   float64         `json:"expected"`              // Amount recomputed from the ledger
+	Actual     float64         `json:"actual"`                // Amount found
+	Detail     string          `json:"detail"`                // Human readable explanation
+}
+
+// ReconciliationReport is the outcome of checking balances against the ledger
+type ReconciliationReport struct {
+	StartedAt     time.Time     `json:"started_at"`    // When the ledger snapshot was read
+	FinishedAt    time.Time     `json:"finished_at"`   // When the checks completed
+	Accounts      int           `json:"accounts"`      // Accounts checked
+	Postings      int           `json:"postings"`      // Postings summed
+	StoredTotal   float64       `json:"stored_total"`  // Sum of the stored balances of all accounts
+	FundedTotal   float64       `json:"funded_total"`  // Money that entered through opening balances and adjustments
+	TransferNet   float64       `json:"transfer_net"`  // Sum of all transfer postings, zero when money is conserved
+	Discrepancies []Discrepancy `json:"discrepancies"` // Failed checks, empty when everything reconciles
+	Balanced      bool          `json:"balanced"`      // Whether no check failed
+}
+
+// LedgerSummary aggregates the ledger for reconciliation, read in one snapshot
+type LedgerSummary struct {
+	// Accounts holds every account with the sums of its postings, ordered by ID
+	Accounts []AccountSummary
+	// Transfers holds only the transfers whose postings do not match their status and amount
+	Transfers []TransferPostings
+	// Postings is the number of postings in the ledger
+	Postings int
+	// FundedTotal is the sum of all opening and adjustment postings
+	FundedTotal float64
+	// TransferNet is the sum of all transfer postings
+	TransferNet float64
+}
+
+// AccountSummary is the stored balance of an account and what its postings add up to
+type AccountSummary struct {
+	AccountID     string
+	StoredBalance float64
+	// PostedBalance is the sum of the amounts of all postings of the account
+	PostedBalance float64
+	// HeadBalance is the balance recorded with the latest posting of the account, zero when there is none
+	HeadBalance float64
+	Postings    int
+}
+
+// TransferPostings is a transfer with the postings made for it
+type TransferPostings struct {
+	TransferID string
+	Status     TransferStatus
+	Amount     float64
+	// Postings is the number of postings made for the transfer
+	Postings int
+	// Net is the sum of the amounts of the postings made for the transfer
+	Net float64
+}
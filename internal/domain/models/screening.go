@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// ScreeningOutcome is what sanctions screening decided to do with a transfer
+type ScreeningOutcome string
+
+// Screening outcomes
+const (
+	// ScreeningClear lets a transfer whose parties resemble no listed party proceed
+	ScreeningClear ScreeningOutcome = "clear"
+	// ScreeningHold holds a transfer whose parties resemble a listed party until it is reviewed
+	ScreeningHold ScreeningOutcome = "hold"
+	// ScreeningBlock refuses a transfer whose parties match a listed party
+	ScreeningBlock ScreeningOutcome = "block"
+)
+
+// IsValid reports whether o is a known screening outcome
+func (o ScreeningOutcome) IsValid() bool {
+	return o == ScreeningClear || o == ScreeningHold || o == ScreeningBlock
+}
+
+// ScreeningResolution is how a reviewer resolved a held transfer
+type ScreeningResolution string
+
+// Review resolutions
+const (
+	// ScreeningReleased executes a held transfer the reviewer found to be a false positive
+	ScreeningReleased ScreeningResolution = "released"
+	// ScreeningRejected fails a held transfer the reviewer confirmed to involve a listed party
+	ScreeningRejected ScreeningResolution = "rejected"
+)
+
+// ScreeningDecision records how the parties of a transfer were screened against the sanctions list
+// Score is that of the closest match between a party and a listed name, from 0 for nothing
+// alike to 1 for the same name; the match is kept even when it was too weak to hold the transfer.
+type ScreeningDecision struct {
+	ID           string              `json:"id"`                      // Unique decision identifier
+	TransferID   string              `json:"transfer_id"`             // Transfer screened
+	Outcome      ScreeningOutcome    `json:"outcome"`                 // Clear, hold or block
+	Score        float64             `json:"score"`                   // Score of the closest match
+	Party        string              `json:"party,omitempty"`         // Party name of the closest match
+	MatchID      string              `json:"match_id,omitempty"`      // Entry number of the listed party matched
+	MatchName    string              `json:"match_name,omitempty"`    // Name of the listed party matched
+	MatchProgram string              `json:"match_program,omitempty"` // Sanctions program that lists it
+	ListVersion  string              `json:"list_version"`            // Version of the list screened against
+	Resolution   ScreeningResolution `json:"resolution,omitempty"`    // How a held transfer was resolved
+	ReviewedBy   string              `json:"reviewed_by,omitempty"`   // Principal who resolved it
+	ReviewNote   string              `json:"review_note,omitempty"`   // Why it was resolved that way
+	ReviewedAt   *time.Time          `json:"reviewed_at,omitempty"`   // When it was resolved
+	CreatedAt    time.Time           `json:"created_at"`              // When the transfer was screened
+}
+
+// ScreeningReview resolves a held transfer
+type ScreeningReview struct {
+	Resolution ScreeningResolution `json:"resolution" binding:"required,oneof=released rejected"` // Release or reject
+	Note       string              `json:"note,omitempty" binding:"max=255"`                      // Why
+}
+
+// ScreeningFilter selects screening decisions; zero fields match every decision
+type ScreeningFilter struct {
+	Outcome    ScreeningOutcome
+	Unreviewed bool // Only held transfers nobody resolved yet
+	Limit      int
+}
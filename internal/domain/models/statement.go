@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// StatementFormat is a file format statements are exported in
+type StatementFormat string
+
+// Statement formats
+const (
+	StatementJSON StatementFormat = "json"
+	StatementCSV  StatementFormat = "csv"
+	StatementPDF  StatementFormat = "pdf"
+	// StatementCamt053 is an ISO 20022 camt.053 bank-to-customer statement
+	StatementCamt053 StatementFormat = "camt053"
+)
+
+// IsValid reports whether f is a supported statement format
+func (f StatementFormat) IsValid() bool {
+	return f == StatementJSON || f == StatementCSV || f == StatementPDF || f == StatementCamt053
+}
+
+// Statement lists the postings of an account over a period with their running balance
+// The opening balance plus the postings always add up to the closing balance.
+type Statement struct {
+	AccountID      string          `json:"account_id"`      // Account the statement is for
+	Currency       string          `json:"currency"`        // ISO 4217 code of all amounts
+	From           time.Time       `json:"from"`            // Start of the period, inclusive
+	To             time.Time       `json:"to"`              // End of the period, exclusive
+	OpeningBalance float64         `json:"opening_balance"` // Balance at the start of the period
+	TotalCredits   float64         `json:"total_credits"`   // Sum of the money received in the period
+	TotalDebits    float64         `json:"total_debits"`    // Sum of the money sent in the period, as a positive amount
+	ClosingBalance float64         `json:"closing_balance"` // Balance at the end of the period
+	Lines          []StatementLine `json:"lines"`           // Postings of the period, oldest first
+	GeneratedAt    time.Time       `json:"generated_at"`    // When the statement was produced
+}
+
+// StatementLine is one posting of a statement
+type StatementLine struct {
+	PostingID    int64       `json:"posting_id"`             // Ledger posting the line shows
+	PostedAt     time.Time   `json:"posted_at"`              // When the balance changed
+	Kind         PostingKind `json:"kind"`                   // Why the balance changed
+	Description  string      `json:"description"`            // Human readable summary
+	TransferID   string      `json:"transfer_id,omitempty"`  // Transfer posted, for transfer lines
+	Counterparty string      `json:"counterparty,omitempty"` // Other account of the transfer
+	Amount       float64     `json:"amount"`                 // Credit when positive, debit when negative
+	Balance      float64     `json:"balance"`                // Running balance after the line
+}
@@ -1,14 +1,124 @@
 package models
 
+import "time"
+
+// TransferStatus represents a stage in the lifecycle of a transfer
+type TransferStatus string
+
+// Transfer lifecycle stages
+const (
+	// TransferStatusCreated is assigned when a transfer is first persisted
+	TransferStatusCreated TransferStatus = "created"
+	// TransferStatusHeld marks a transfer whose parties resemble a sanctioned party, awaiting
+	// manual review
+	TransferStatusHeld TransferStatus = "held"
+	// TransferStatusPending marks a transfer queued for asynchronous execution
+	TransferStatusPending TransferStatus = "pending"
+	// TransferStatusProcessing marks a transfer whose funds are being moved
+	TransferStatusProcessing TransferStatus = "processing"
+	// TransferStatusCompleted marks a transfer whose funds were moved successfully
+	TransferStatusCompleted TransferStatus = "completed"
+	// TransferStatusFailed marks a transfer that could not be executed
+	TransferStatusFailed TransferStatus = "failed"
+	// TransferStatusReversed marks a completed transfer whose funds were returned
+	TransferStatusReversed TransferStatus = "reversed"
+)
+
+// transferTransitions lists the statuses each status may move to
+var transferTransitions = map[TransferStatus][]TransferStatus{
+	TransferStatusCreated:    {TransferStatusHeld, TransferStatusPending, TransferStatusProcessing, TransferStatusFailed},
+	TransferStatusHeld:       {TransferStatusProcessing, TransferStatusFailed},
+	TransferStatusPending:    {TransferStatusProcessing, TransferStatusFailed},
+	TransferStatusProcessing: {TransferStatusCompleted, TransferStatusFailed, TransferStatusPending},
+	TransferStatusCompleted:  {TransferStatusReversed},
+}
+
+// CanTransitionTo reports whether a transfer may move from s to next
+func (s TransferStatus) CanTransitionTo(next TransferStatus) bool {
+	for _, allowed := range transferTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal reports whether no further processing is expected for the status
+func (s TransferStatus) IsFinal() bool {
+	switch s {
+	case TransferStatusCompleted, TransferStatusFailed, TransferStatusReversed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transfer represents a persisted money transfer and its current lifecycle stage
+type Transfer struct {
+	ID            string         `json:"id"`                       // Unique transfer identifier
+	From          string         `json:"from"`                     // Source account ID
+	To            string         `json:"to"`                       // Destination account ID
+	Amount        float64        `json:"amount"`                   // Amount to transfer
+	Status        TransferStatus `json:"status"`                   // Current lifecycle stage
+	FailureReason string         `json:"failure_reason,omitempty"` // Why the transfer failed, if it did
+	CreatedAt     time.Time      `json:"created_at"`               // When the transfer was created
+	UpdatedAt     time.Time      `json:"updated_at"`               // When the status last changed
+	// Counterparty is the account outside this ledger the funds are destined for, if any
+	Counterparty *ExternalAccount `json:"counterparty,omitempty"`
+	// IdempotencyKey is the key of the request that made the transfer, if any
+	IdempotencyKey string `json:"-"`
+}
+
+// IdentifierScheme names a way of identifying accounts
+type IdentifierScheme string
+
+// Account identifier schemes
+const (
+	// SchemeInternal identifies the accounts of this ledger by their UUID
+	SchemeInternal IdentifierScheme = "internal"
+	// SchemeLegacy identifies the accounts of this ledger opened before account IDs were UUIDs,
+	// such as Mark or the system accounts, by their name
+	SchemeLegacy IdentifierScheme = "legacy"
+	// SchemeIBAN identifies accounts by International Bank Account Number
+	SchemeIBAN IdentifierScheme = "iban"
+	// SchemeBBAN identifies accounts by country code and Basic Bank Account Number
+	SchemeBBAN IdentifierScheme = "bban"
+	// SchemeSortCode identifies UK accounts by sort code and account number
+	SchemeSortCode IdentifierScheme = "sort_code"
+	// SchemeABA identifies US accounts by ABA routing number and account number
+	SchemeABA IdentifierScheme = "aba"
+)
+
+// ExternalAccount identifies an account held at another bank
+// Transfers to external accounts move the funds to an account of this ledger, such as a
+// settlement account, from where they are paid out to the counterparty.
+type ExternalAccount struct {
+	Scheme     IdentifierScheme `json:"scheme" binding:"required,external_scheme"`        // Scheme of the identifier
+	Identifier string           `json:"identifier" binding:"required,account_identifier"` // Account identifier
+	Name       string           `json:"name,omitempty" binding:"max=140"`                 // Name of the account holder
+}
+
+// Currency is the ISO 4217 code of the currency all accounts are held in
+const Currency = "USD"
+
 // TransferRequest represents the input data for a money transfer operation
+// Amounts are limited to 1,000,000 with at most 2 decimal places.
 type TransferRequest struct {
-	From   string  `json:"from"`   // Source account ID
-	To     string  `json:"to"`     // Destination account ID
-	Amount float64 `json:"amount"` // Amount to transfer
+	From     string  `json:"from" binding:"required,account_id"`                    // Source account ID
+	To       string  `json:"to" binding:"required,account_id,nefield=From"`         // Destination account ID
+	Amount   float64 `json:"amount" binding:"required,gt=0,lte=1000000,decimals=2"` // Amount to transfer
+	Currency string  `json:"currency,omitempty" binding:"omitempty,currency"`       // Currency of the amount, defaults to the ledger currency
+	// Counterparty is the account outside this ledger the funds are destined for, if any
+	Counterparty *ExternalAccount `json:"counterparty,omitempty"`
+	// IdempotencyKey is set by the services of this ledger so that a retried transfer moves the
+	// funds once: the transfer that did not fail with the same key is returned instead
+	IdempotencyKey string `json:"-"`
 }
 
 // TransferResponse represents the result of a transfer operation
 type TransferResponse struct {
-	Success bool   `json:"success"`           // Indicates if transfer was successful
-	Message string `json:"message,omitempty"` // Optional error or success message
+	Success    bool           `json:"success"`               // Indicates if transfer was successful
+	TransferID string         `json:"transfer_id,omitempty"` // Identifier for polling the transfer
+	Status     TransferStatus `json:"status,omitempty"`      // Lifecycle stage at the time of the response
+	Message    string         `json:"message,omitempty"`     // Optional error or success message
 }
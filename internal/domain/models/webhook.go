@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// WebhookSubscription represents a partner endpoint receiving domain events
+// An empty EventTypes list subscribes to every event type
+type WebhookSubscription struct {
+	ID         string      `json:"id"`               // Unique subscription identifier
+	URL        string      `json:"url"`              // Endpoint deliveries are POSTed to
+	EventTypes []EventType `json:"event_types"`      // Event types to deliver
+	Secret     string      `json:"secret,omitempty"` // HMAC key, only returned on creation
+	CreatedAt  time.Time   `json:"created_at"`       // When the subscription was created
+}
+
+// WebhookSubscriptionRequest represents the input for creating a webhook subscription
+type WebhookSubscriptionRequest struct {
+	URL        string      `json:"url" binding:"required,max=2048"`                     // Endpoint deliveries are POSTed to
+	EventTypes []EventType `json:"event_types" binding:"max=32"`                        // Event types to deliver, empty for all
+	Secret     string      `json:"secret,omitempty" binding:"omitempty,min=16,max=256"` // HMAC key, generated when omitted
+}
+
+// WebhookDeliveryStatus represents the state of a single webhook delivery
+type WebhookDeliveryStatus string
+
+// Webhook delivery states
+const (
+	// WebhookDeliveryPending is waiting for its first or next attempt
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliveryDelivered was acknowledged with a 2xx response
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "delivered"
+	// WebhookDeliveryDead exhausted its attempts and will only be retried manually
+	WebhookDeliveryDead WebhookDeliveryStatus = "dead"
+)
+
+// WebhookDelivery represents one event queued for one subscription
+type WebhookDelivery struct {
+	ID               string                `json:"id"`                           // Unique delivery identifier
+	SubscriptionID   string                `json:"subscription_id"`              // Receiving subscription
+	EventID          string                `json:"event_id"`                     // Delivered event, stable across retries
+	EventType        EventType             `json:"event_type"`                   // Type of the delivered event
+	Payload          []byte                `json:"-"`                            // Request body sent to the endpoint
+	Status           WebhookDeliveryStatus `json:"status"`                       // Current delivery state
+	Attempts         int                   `json:"attempts"`                     // Attempts made so far
+	NextAttemptAt    time.Time             `json:"next_attempt_at"`              // When the next attempt is due
+	LastResponseCode int                   `json:"last_response_code,omitempty"` // HTTP status of the last attempt
+	LastError        string                `json:"last_error,omitempty"`         // Why the last attempt failed
+	DeliveredAt      *time.Time            `json:"delivered_at,omitempty"`       // When the endpoint acknowledged
+	CreatedAt        time.Time             `json:"created_at"`                   // When the delivery was queued
+}
+
+// WebhookAttempt records the outcome of a delivery attempt
+type WebhookAttempt struct {
+	Status        WebhookDeliveryStatus
+	ResponseCode  int
+	Error         string
+	NextAttemptAt time.Time
+}
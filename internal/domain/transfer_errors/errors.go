@@ -16,4 +16,110 @@ var (
 
 	// ErrSameAccount is returned when trying to transfer money to the same account
 	ErrSameAccount = errors.New("cannot transfer to same account")
+
+	// ErrSystemAccount is returned when a transfer would move money out of a system account, such as
+	// cash or clearing, which only the services of this ledger may do
+	ErrSystemAccount = errors.New("system accounts cannot be the source of transfers")
+
+	// ErrInvalidAccountIdentifier is returned when an account identifier does not fit its scheme
+	ErrInvalidAccountIdentifier = errors.New("invalid account identifier")
+
+	// ErrTransferNotFound is returned when the specified transfer doesn't exist
+	ErrTransferNotFound = errors.New("transfer not found")
+
+	// ErrInvalidStatusTransition is returned when a transfer cannot move to the requested status
+	ErrInvalidStatusTransition = errors.New("invalid transfer status transition")
+
+	// ErrInvalidPeriod is returned when a reporting period is empty, reversed or too long
+	ErrInvalidPeriod = errors.New("invalid period")
+
+	// ErrFutureTime is returned when a balance is requested at a time that has not come yet
+	ErrFutureTime = errors.New("time is in the future")
+
+	// ErrInvalidPaymentMessage is returned when an ISO 20022 payment message cannot be read
+	ErrInvalidPaymentMessage = errors.New("invalid payment message")
+
+	// ErrPaymentMessageNotFound is returned when no payment message was executed with the given ID
+	ErrPaymentMessageNotFound = errors.New("payment message not found")
+
+	// ErrLedgerMismatch is returned when balances and the postings recorded for them disagree
+	ErrLedgerMismatch = errors.New("balances disagree with the ledger")
+
+	// ErrSerializationFailure is returned when a transaction conflicted with a concurrent one and may be retried
+	ErrSerializationFailure = errors.New("transaction conflicted with a concurrent transaction")
+)
+
+// Errors that can occur while screening transfers against the sanctions list
+var (
+	// ErrTransferBlocked is returned when a party of a transfer matches a sanctioned party
+	ErrTransferBlocked = errors.New("transfer blocked by sanctions screening")
+
+	// ErrTransferHeld is returned when a transfer is held for review because a party resembles a sanctioned party
+	ErrTransferHeld = errors.New("transfer held for sanctions review")
+
+	// ErrScreeningDecisionNotFound is returned when the specified screening decision doesn't exist
+	ErrScreeningDecisionNotFound = errors.New("screening decision not found")
+)
+
+// Errors that can occur while paying out to external accounts
+var (
+	// ErrPayoutNotFound is returned when the specified payout doesn't exist
+	ErrPayoutNotFound = errors.New("payout not found")
+
+	// ErrUnknownRail is returned when a payout names a payment rail that is not configured
+	ErrUnknownRail = errors.New("unknown payment rail")
+
+	// ErrACHFileNotFound is returned when the specified ACH file doesn't exist
+	ErrACHFileNotFound = errors.New("ACH file not found")
+)
+
+// Errors that can occur while depositing and withdrawing funds
+var (
+	// ErrInvalidFundingAccount is returned when a deposit or withdrawal does not move money
+	// between a customer account and a system account
+	ErrInvalidFundingAccount = errors.New("funds must move between a customer account and a system account")
+
+	// ErrReferenceConflict is returned when an external reference, such as the reference of a funding
+	// or the ID of a payment message, was already used with other details
+	ErrReferenceConflict = errors.New("reference was already used with other details")
+
+	// ErrTransferExists is returned when a transfer is created with the idempotency key of a
+	// transfer that did not fail
+	ErrTransferExists = errors.New("a transfer with this idempotency key exists")
+
+	// ErrPayoutExists is returned when a payout is created for a transfer that made one already
+	ErrPayoutExists = errors.New("a payout of this transfer exists")
+)
+
+// Errors that can occur while managing webhooks
+var (
+	// ErrWebhookNotFound is returned when the specified webhook subscription doesn't exist
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+
+	// ErrWebhookDeliveryNotFound is returned when the specified webhook delivery doesn't exist
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrInvalidWebhookURL is returned when a webhook URL is not an absolute http(s) URL
+	ErrInvalidWebhookURL = errors.New("webhook url must be an absolute http or https url")
+
+	// ErrInvalidEventType is returned when a webhook subscribes to an unknown event type
+	ErrInvalidEventType = errors.New("unknown event type")
+)
+
+// Errors that can occur while authenticating and authorizing callers
+var (
+	// ErrUnauthenticated is returned when a request carries no valid credentials
+	ErrUnauthenticated = errors.New("missing or invalid credentials")
+
+	// ErrForbidden is returned when a caller may not access the requested resource
+	ErrForbidden = errors.New("access to this resource is forbidden")
+
+	// ErrRateLimited is returned when a caller made more requests than its rate limits allow
+	ErrRateLimited = errors.New("rate limit exceeded")
+)
+
+// Errors that can occur while reading and verifying the audit log
+var (
+	// ErrAuditChainBroken is returned when a sealed audit event was altered, removed or reordered
+	ErrAuditChainBroken = errors.New("audit hash chain is broken")
 )
@@ -0,0 +1,49 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+
+	"money-transfer/internal/domain/models"
+)
+
+// FilePublisher appends events to a file as JSON lines
+type FilePublisher struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFilePublisher opens path for appending, creating it if needed
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilePublisher{
+		file: file,
+	}, nil
+}
+
+// Publish writes the event as one line and syncs it to disk before acknowledging
+func (p *FilePublisher) Publish(_ context.Context, event *models.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return p.file.Sync()
+}
+
+// Close closes the underlying file
+func (p *FilePublisher) Close() error {
+	return p.file.Close()
+}
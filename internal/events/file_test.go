@@ -0,0 +1,46 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilePublisher_Publish(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	publisher, err := NewFilePublisher(path)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, publisher.Publish(ctx, &models.Event{
+		Sequence: 1, ID: "e-1", Type: models.EventTransferCreated, Payload: json.RawMessage(`{"a":1}`),
+	}))
+	require.NoError(t, publisher.Publish(ctx, &models.Event{
+		Sequence: 2, ID: "e-2", Type: models.EventTransferCompleted, Payload: json.RawMessage(`{"b":2}`),
+	}))
+	require.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.ID)
+	}
+	require.NoError(t, scanner.Err())
+
+	assert.Equal(t, []string{"e-1", "e-2"}, ids)
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+
+	"money-transfer/internal/domain/models"
+
+	"github.com/nats-io/nats.go"
+)
+
+// NATSPublisher publishes events to a NATS server
+// Each event goes to <prefix>.<event type> with a Nats-Msg-Id header set to the event ID,
+// which JetStream uses to drop redelivered duplicates
+type NATSPublisher struct {
+	conn   *nats.Conn
+	prefix string
+}
+
+// NewNATSPublisher connects to the NATS server at url
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url, nats.Name("money-transfer outbox relay"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &NATSPublisher{
+		conn:   conn,
+		prefix: prefix,
+	}, nil
+}
+
+// Publish sends the event and waits until the server has received it
+func (p *NATSPublisher) Publish(ctx context.Context, event *models.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(p.prefix + "." + string(event.Type))
+	msg.Header.Set(nats.MsgIdHdr, event.ID)
+	msg.Data = data
+
+	if err := p.conn.PublishMsg(msg); err != nil {
+		return err
+	}
+	return p.conn.FlushWithContext(ctx)
+}
+
+// Close drains pending messages and closes the connection
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,90 @@
+// Package events delivers domain events recorded in the transactional outbox
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"money-transfer/internal/domain/models"
+)
+
+// Publisher delivers a single event to downstream consumers
+// Delivery is at-least-once: the same event may be published again after a failure,
+// so consumers deduplicate by Event.ID
+type Publisher interface {
+	Publish(ctx context.Context, event *models.Event) error
+}
+
+// LogPublisher writes events to a logger
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+// NewLogPublisher creates a publisher that only logs events to logger
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+// Publish logs the event
+func (p *LogPublisher) Publish(ctx context.Context, event *models.Event) error {
+	p.logger.InfoContext(ctx, "event published",
+		"sequence", event.Sequence,
+		"type", event.Type,
+		"event_id", event.ID,
+		"aggregate_type", event.AggregateType,
+		"aggregate_id", event.AggregateID,
+		"payload", event.Payload,
+	)
+	return nil
+}
+
+// ChannelPublisher delivers events to an in-process channel
+// Intended for tests and for consumers running in the same process
+type ChannelPublisher struct {
+	events chan *models.Event
+}
+
+// NewChannelPublisher creates a publisher whose channel buffers up to size events
+func NewChannelPublisher(size int) *ChannelPublisher {
+	return &ChannelPublisher{
+		events: make(chan *models.Event, size),
+	}
+}
+
+// Events returns the channel events are delivered to
+func (p *ChannelPublisher) Events() <-chan *models.Event {
+	return p.events
+}
+
+// Publish sends the event to the channel, blocking until there is room or ctx is done
+func (p *ChannelPublisher) Publish(ctx context.Context, event *models.Event) error {
+	select {
+	case p.events <- event:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MultiPublisher delivers every event to several publishers in order
+// An event counts as published only once all of them accepted it
+type MultiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher creates a publisher fanning out to publishers
+func NewMultiPublisher(publishers ...Publisher) *MultiPublisher {
+	return &MultiPublisher{
+		publishers: publishers,
+	}
+}
+
+// Publish hands the event to each publisher, stopping at the first failure
+func (p *MultiPublisher) Publish(ctx context.Context, event *models.Event) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"money-transfer/internal/storage"
+)
+
+// Relay moves events from the transactional outbox to a Publisher
+// Events are published in outbox order and marked delivered only after Publish succeeds
+type Relay struct {
+	outbox       storage.OutboxRepository
+	publisher    Publisher
+	batchSize    int
+	pollInterval time.Duration
+	logger       *slog.Logger
+	wg           sync.WaitGroup
+}
+
+// NewRelay creates a relay publishing up to batchSize events per poll and logging failures to logger
+func NewRelay(
+	outbox storage.OutboxRepository, publisher Publisher, batchSize int, pollInterval time.Duration, logger *slog.Logger,
+) *Relay {
+	return &Relay{
+		outbox:       outbox,
+		publisher:    publisher,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		logger:       logger,
+	}
+}
+
+// Start launches the relay loop; it stops once ctx is canceled
+func (r *Relay) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(ctx)
+	}()
+}
+
+// Wait blocks until the relay loop has returned
+func (r *Relay) Wait() {
+	r.wg.Wait()
+}
+
+// run polls the outbox until ctx is canceled, draining full batches without waiting
+func (r *Relay) run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.RelayOnce(ctx) == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes a single batch and returns how many events were delivered
+func (r *Relay) RelayOnce(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	published, err := r.outbox.Relay(ctx, r.batchSize, r.publisher.Publish)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "outbox relay failed", "published", published, "error", err)
+	}
+
+	return published
+}
@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// relayEvents makes the outbox mock hand events to the publish callback until one fails
+func relayEvents(events ...*models.Event) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		ctx := args.Get(0).(context.Context)
+		publish := args.Get(2).(func(context.Context, *models.Event) error)
+		for _, event := range events {
+			if publish(ctx, event) != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestRelay_RelayOnce(t *testing.T) {
+	first := &models.Event{Sequence: 1, ID: "e-1", Type: models.EventTransferCreated}
+	second := &models.Event{Sequence: 2, ID: "e-2", Type: models.EventTransferCompleted}
+
+	outbox := mocks.NewOutboxRepository(t)
+	outbox.On("Relay", mock.Anything, 10, mock.Anything).
+		Run(relayEvents(first, second)).
+		Return(2, nil)
+
+	publisher := NewChannelPublisher(10)
+	relay := NewRelay(outbox, publisher, 10, time.Second, logging.Discard())
+
+	assert.Equal(t, 2, relay.RelayOnce(context.Background()))
+	assert.Equal(t, first, <-publisher.Events())
+	assert.Equal(t, second, <-publisher.Events())
+}
+
+func TestRelay_RelayOnceFailure(t *testing.T) {
+	outbox := mocks.NewOutboxRepository(t)
+	outbox.On("Relay", mock.Anything, 10, mock.Anything).Return(0, assert.AnError)
+
+	relay := NewRelay(outbox, NewChannelPublisher(1), 10, time.Second, logging.Discard())
+
+	assert.Equal(t, 0, relay.RelayOnce(context.Background()))
+}
+
+func TestRelay_StartStop(t *testing.T) {
+	event := &models.Event{Sequence: 1, ID: "e-1", Type: models.EventAccountCreated}
+
+	outbox := mocks.NewOutboxRepository(t)
+	outbox.On("Relay", mock.Anything, 5, mock.Anything).
+		Run(relayEvents(event)).
+		Return(1, nil).Once()
+	outbox.On("Relay", mock.Anything, 5, mock.Anything).Return(0, nil).Maybe()
+
+	publisher := NewChannelPublisher(1)
+	relay := NewRelay(outbox, publisher, 5, 10*time.Millisecond, logging.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	relay.Start(ctx)
+
+	select {
+	case got := <-publisher.Events():
+		assert.Equal(t, event, got)
+	case <-time.After(time.Second):
+		t.Fatal("event was not relayed")
+	}
+
+	cancel()
+	relay.Wait()
+}
+
+func TestChannelPublisher_ContextCanceled(t *testing.T) {
+	publisher := NewChannelPublisher(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := publisher.Publish(ctx, &models.Event{ID: "e-1"})
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,187 @@
+// Package health reports whether the service is alive and ready to serve traffic
+package health
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultTimeout bounds each readiness check when no timeout is configured
+const defaultTimeout = 2 * time.Second
+
+// Statuses of the service and of each of its checks
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Check reports whether a dependency is usable
+type Check func(ctx context.Context) error
+
+// Config holds the dependencies whose health decides readiness
+type Config struct {
+	// Timeout bounds each check
+	Timeout time.Duration
+	// DB is pinged for readiness and its connection pool is shown in the details
+	DB *sql.DB
+	// Checks are further checks that must pass for readiness, by name
+	Checks map[string]Check
+}
+
+// Checker runs the readiness checks and tracks whether the service is draining
+type Checker struct {
+	timeout  time.Duration
+	db       *sql.DB
+	checks   map[string]Check
+	started  time.Time
+	draining atomic.Bool
+}
+
+// CheckResult is the outcome of a single check
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+// Report is the readiness of the service with the result of every check
+type Report struct {
+	Status   string                 `json:"status"`
+	Draining bool                   `json:"draining"`
+	Checks   map[string]CheckResult `json:"checks"`
+}
+
+// Details extends the readiness report with what operators need to diagnose the service
+type Details struct {
+	Report
+	UptimeSeconds float64    `json:"uptime_seconds"`
+	Build         BuildInfo  `json:"build"`
+	DatabasePool  *PoolStats `json:"database_pool,omitempty"`
+}
+
+// PoolStats describes the database connection pool
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMS     float64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// NewChecker creates a checker for the configured dependencies
+func NewChecker(cfg Config) *Checker {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+
+	checks := make(map[string]Check, len(cfg.Checks)+1)
+	for name, check := range cfg.Checks {
+		checks[name] = check
+	}
+	if cfg.DB != nil {
+		checks["database"] = cfg.DB.PingContext
+	}
+
+	return &Checker{
+		timeout: cfg.Timeout,
+		db:      cfg.DB,
+		checks:  checks,
+		started: time.Now(),
+	}
+}
+
+// Drain makes the service report itself unavailable from now on, so that load
+// balancers stop routing to it before it shuts down
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Draining reports whether Drain has been called
+func (c *Checker) Draining() bool {
+	return c.draining.Load()
+}
+
+// Ready runs all checks concurrently, each bounded by the timeout
+// The service is ready when every check passes and it is not draining.
+func (c *Checker) Ready(ctx context.Context) Report {
+	report := Report{
+		Status:   StatusOK,
+		Draining: c.Draining(),
+		Checks:   make(map[string]CheckResult, len(c.checks)),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := c.run(ctx, check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	if report.Draining {
+		report.Status = StatusUnavailable
+	}
+
+	return report
+}
+
+// Details returns the readiness report along with uptime, build and pool information
+func (c *Checker) Details(ctx context.Context) Details {
+	details := Details{
+		Report:        c.Ready(ctx),
+		UptimeSeconds: time.Since(c.started).Seconds(),
+		Build:         ReadBuildInfo(),
+	}
+	if c.db != nil {
+		stats := c.db.Stats()
+		details.DatabasePool = &PoolStats{
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMS:     float64(stats.WaitDuration) / float64(time.Millisecond),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+		}
+	}
+	return details
+}
+
+// run executes a single check within the timeout
+func (c *Checker) run(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check(ctx)
+	result := CheckResult{
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start)) / float64(time.Millisecond),
+	}
+	if err != nil {
+		result.Status = StatusUnavailable
+		result.Error = err.Error()
+	}
+	return result
+}
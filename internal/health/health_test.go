@@ -0,0 +1,98 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"money-transfer/internal/health"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     map[string]health.Check
+		drain      bool
+		wantStatus string
+		wantFailed []string
+	}{
+		{
+			name:       "no checks",
+			wantStatus: health.StatusOK,
+		},
+		{
+			name: "all checks pass",
+			checks: map[string]health.Check{
+				"schema": func(context.Context) error { return nil },
+				"cache":  func(context.Context) error { return nil },
+			},
+			wantStatus: health.StatusOK,
+		},
+		{
+			name: "failing check",
+			checks: map[string]health.Check{
+				"schema": func(context.Context) error { return errors.New("missing tables: transfers") },
+				"cache":  func(context.Context) error { return nil },
+			},
+			wantStatus: health.StatusUnavailable,
+			wantFailed: []string{"schema"},
+		},
+		{
+			name: "check exceeding the timeout",
+			checks: map[string]health.Check{
+				"database": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			wantStatus: health.StatusUnavailable,
+			wantFailed: []string{"database"},
+		},
+		{
+			name: "draining",
+			checks: map[string]health.Check{
+				"schema": func(context.Context) error { return nil },
+			},
+			drain:      true,
+			wantStatus: health.StatusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := health.NewChecker(health.Config{Timeout: 20 * time.Millisecond, Checks: tt.checks})
+			if tt.drain {
+				checker.Drain()
+			}
+
+			report := checker.Ready(context.Background())
+
+			assert.Equal(t, tt.wantStatus, report.Status)
+			assert.Equal(t, tt.drain, report.Draining)
+			assert.Len(t, report.Checks, len(tt.checks))
+			for name, result := range report.Checks {
+				if assert.Contains(t, tt.checks, name) && slices.Contains(tt.wantFailed, name) {
+					assert.Equal(t, health.StatusUnavailable, result.Status)
+					assert.NotEmpty(t, result.Error)
+				} else {
+					assert.Equal(t, health.StatusOK, result.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestChecker_Details(t *testing.T) {
+	checker := health.NewChecker(health.Config{})
+
+	details := checker.Details(context.Background())
+
+	assert.Equal(t, health.StatusOK, details.Status)
+	assert.Equal(t, health.Version, details.Build.Version)
+	assert.NotEmpty(t, details.Build.GoVersion)
+	assert.Nil(t, details.DatabasePool)
+}
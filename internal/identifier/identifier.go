@@ -0,0 +1,138 @@
+// Package identifier validates account identifiers in the schemes accounts can be addressed by:
+// the account IDs of this ledger and the identifiers of accounts held at other banks
+//
+// Accounts of this ledger are identified by UUID (the internal scheme), except the accounts
+// opened before account IDs were UUIDs, such as Mark or the system accounts, which keep their
+// names (the legacy scheme). ValidateAccountID accepts the IDs of either; the AccountIDPolicy
+// made by RestrictLegacy limits the legacy scheme to the system accounts once customer accounts
+// have been given UUIDs.
+//
+// Schemes are looked up by name in a registry holding the built-in schemes; more can be added
+// with Register.
+package identifier
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// Scheme is a way of identifying accounts
+type Scheme interface {
+	// Name is the name the scheme is selected by
+	Name() models.IdentifierScheme
+	// Normalize returns value in the canonical form it is validated and stored in,
+	// such as without the spaces used to print it
+	Normalize(value string) string
+	// Validate returns an error explaining why a normalized value is not an identifier of the scheme
+	Validate(value string) error
+}
+
+var (
+	mu      sync.RWMutex
+	schemes = map[models.IdentifierScheme]Scheme{}
+)
+
+func init() {
+	for _, scheme := range []Scheme{Internal, Legacy, IBAN, BBAN, SortCode, ABA} {
+		Register(scheme)
+	}
+}
+
+// Register makes scheme available by its name, replacing a scheme registered under the same name
+func Register(scheme Scheme) {
+	mu.Lock()
+	defer mu.Unlock()
+	schemes[scheme.Name()] = scheme
+}
+
+// Lookup returns the scheme registered under name
+func Lookup(name models.IdentifierScheme) (Scheme, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	scheme, ok := schemes[name]
+	return scheme, ok
+}
+
+// ExternalSchemes lists the names of the registered schemes of accounts held outside this ledger, sorted
+func ExternalSchemes() []models.IdentifierScheme {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]models.IdentifierScheme, 0, len(schemes))
+	for name := range schemes {
+		if !IsLedgerScheme(name) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Normalize returns value in the canonical form of the scheme named name
+// Returns an error wrapping ErrInvalidAccountIdentifier if the scheme is unknown or value is not
+// one of its identifiers.
+func Normalize(name models.IdentifierScheme, value string) (string, error) {
+	scheme, ok := Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown scheme %q", transfererrors.ErrInvalidAccountIdentifier, name)
+	}
+	normalized := scheme.Normalize(value)
+	if err := scheme.Validate(normalized); err != nil {
+		return "", fmt.Errorf("%w: %s: %v", transfererrors.ErrInvalidAccountIdentifier, name, err)
+	}
+	return normalized, nil
+}
+
+// IsLedgerScheme reports whether the scheme named name identifies the accounts of this ledger
+func IsLedgerScheme(name models.IdentifierScheme) bool {
+	return name == models.SchemeInternal || name == models.SchemeLegacy
+}
+
+// AccountIDRule describes the account IDs of this ledger
+const AccountIDRule = "must be a UUID in lower case, or a legacy account ID of 1-64 letters, digits, '-' or '_' " +
+	"starting with a letter or digit"
+
+// ValidateAccountID returns an error explaining why id is the ID of no account of this ledger,
+// neither a UUID nor a legacy account ID
+func ValidateAccountID(id string) error {
+	if Internal.Validate(id) == nil || Legacy.Validate(id) == nil {
+		return nil
+	}
+	return errors.New(AccountIDRule)
+}
+
+// AccountIDPolicy decides which account IDs of this ledger transfers may name
+// The zero value accepts every ID ValidateAccountID accepts.
+type AccountIDPolicy struct {
+	// legacyIDs holds the legacy account IDs still accepted; nil accepts all
+	legacyIDs map[string]bool
+}
+
+// RestrictLegacy returns a policy accepting no legacy account IDs other than ids, the names of
+// the system accounts, ending the legacy scheme for customers once their accounts have UUIDs
+func RestrictLegacy(ids ...string) AccountIDPolicy {
+	policy := AccountIDPolicy{legacyIDs: make(map[string]bool, len(ids))}
+	for _, id := range ids {
+		policy.legacyIDs[id] = true
+	}
+	return policy
+}
+
+// Validate returns an error explaining why id is the ID of no account of this ledger, or of
+// one no longer accepted by its legacy ID
+func (p AccountIDPolicy) Validate(id string) error {
+	if err := ValidateAccountID(id); err != nil {
+		return err
+	}
+	if p.legacyIDs == nil || Internal.Validate(id) == nil || p.legacyIDs[id] {
+		return nil
+	}
+	return errors.New(legacyRestrictedRule)
+}
+
+// legacyRestrictedRule describes the account IDs accepted by a policy made by RestrictLegacy
+const legacyRestrictedRule = "must be a UUID in lower case; legacy account IDs are only accepted for system accounts"
@@ -0,0 +1,129 @@
+package identifier
+
+import (
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  models.IdentifierScheme
+		value   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "internal UUID",
+			scheme: models.SchemeInternal,
+			value:  "3F0C2B8E-9D4A-4C1E-8F5B-2A7D6E1C9B30",
+			want:   "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30",
+		},
+		{name: "internal name", scheme: models.SchemeInternal, value: "Mark", wantErr: "must be a UUID"},
+		{
+			name:    "internal UUID with other letters",
+			scheme:  models.SchemeInternal,
+			value:   "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9bzz",
+			wantErr: "must consist of lower case hexadecimal digits",
+		},
+		{name: "legacy account ID", scheme: models.SchemeLegacy, value: "Mark", want: "Mark"},
+		{name: "legacy ID with spaces", scheme: models.SchemeLegacy, value: "Mark Smith", wantErr: "must be 1-64 letters"},
+		{
+			name:    "legacy UUID",
+			scheme:  models.SchemeLegacy,
+			value:   "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30",
+			wantErr: "must not have the layout of a UUID",
+		},
+		{name: "IBAN", scheme: models.SchemeIBAN, value: "GB82WEST12345698765432", want: "GB82WEST12345698765432"},
+		{name: "printed IBAN", scheme: models.SchemeIBAN, value: "de89 3704 0044 0532 0130 00", want: "DE89370400440532013000"},
+		{
+			name:   "IBAN with letters in the BBAN",
+			scheme: models.SchemeIBAN,
+			value:  "FR1420041010050500013M02606",
+			want:   "FR1420041010050500013M02606",
+		},
+		{name: "shortest IBAN", scheme: models.SchemeIBAN, value: "NO9386011117947", want: "NO9386011117947"},
+		{name: "IBAN check digits", scheme: models.SchemeIBAN, value: "GB83WEST12345698765432", wantErr: "check digits do not match"},
+		{name: "IBAN length", scheme: models.SchemeIBAN, value: "GB82WEST1234569876543", wantErr: "must be 22 characters long in GB"},
+		{name: "IBAN country", scheme: models.SchemeIBAN, value: "US82WEST12345698765432", wantErr: `unknown country code "US"`},
+		{name: "IBAN characters", scheme: models.SchemeIBAN, value: "GB82-WEST-1234-5698-7654-32", wantErr: "must consist of letters and digits"},
+		{name: "BBAN", scheme: models.SchemeBBAN, value: "gb west 1234 5698 7654 32", want: "GBWEST12345698765432"},
+		{name: "BBAN length", scheme: models.SchemeBBAN, value: "GB82WEST12345698765432", wantErr: "BBAN must be 18 characters long in GB"},
+		{name: "BBAN country", scheme: models.SchemeBBAN, value: "US021000021", wantErr: `unknown country code "US"`},
+		{name: "BBAN characters", scheme: models.SchemeBBAN, value: "GB-WEST-1234-5698-7654", wantErr: "must consist of letters and digits"},
+		{name: "sort code", scheme: models.SchemeSortCode, value: "12-34-56 12345678", want: "12345612345678"},
+		{name: "sort code without account number", scheme: models.SchemeSortCode, value: "12-34-56", wantErr: "6 digit sort code"},
+		{name: "sort code letters", scheme: models.SchemeSortCode, value: "12345612345a78", wantErr: "must consist of digits"},
+		{name: "ABA", scheme: models.SchemeABA, value: "021000021 1234-5678", want: "02100002112345678"},
+		{name: "ABA without account number", scheme: models.SchemeABA, value: "021000021", wantErr: "9 digit routing number"},
+		{name: "ABA check digit", scheme: models.SchemeABA, value: "021000022 12345678", wantErr: "check digit does not match"},
+		{name: "ABA letters", scheme: models.SchemeABA, value: "021000021 1234567A", wantErr: "must consist of digits"},
+		{name: "unknown scheme", scheme: "bsb", value: "062000 12345678", wantErr: `unknown scheme "bsb"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.scheme, tt.value)
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, transfererrors.ErrInvalidAccountIdentifier)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateAccountID(t *testing.T) {
+	for _, id := range []string{"3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30", "Mark", "settlement", "account_42"} {
+		assert.NoError(t, ValidateAccountID(id), id)
+	}
+	for _, id := range []string{"", "Mark Smith", "-Mark", "3F0C2B8E-9D4A-4C1E-8F5B-2A7D6E1C9B30", "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9bzz"} {
+		assert.EqualError(t, ValidateAccountID(id), AccountIDRule, id)
+	}
+}
+
+func TestRestrictLegacy(t *testing.T) {
+	policy := RestrictLegacy("settlement")
+
+	assert.NoError(t, policy.Validate("3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30"))
+	assert.NoError(t, policy.Validate("settlement"))
+	assert.ErrorContains(t, policy.Validate("Mark"), "legacy account IDs are only accepted for system accounts")
+	assert.EqualError(t, policy.Validate("Mark Smith"), AccountIDRule)
+	assert.NoError(t, AccountIDPolicy{}.Validate("Mark"), "the zero policy accepts every legacy account ID")
+	assert.NoError(t, ValidateAccountID("Mark"), "validation alone does not restrict legacy account IDs")
+	got, err := Normalize(models.SchemeLegacy, "Mark")
+	require.NoError(t, err, "normalizing identifies accounts, whether or not new transfers may name them")
+	assert.Equal(t, "Mark", got)
+}
+
+// bsbScheme identifies Australian accounts by BSB number and account number
+type bsbScheme struct{}
+
+func (bsbScheme) Name() models.IdentifierScheme { return "bsb" }
+func (bsbScheme) Normalize(value string) string { return value }
+func (bsbScheme) Validate(string) error         { return nil }
+
+func TestRegister(t *testing.T) {
+	assert.Equal(t, []models.IdentifierScheme{models.SchemeABA, models.SchemeBBAN, models.SchemeIBAN, models.SchemeSortCode},
+		ExternalSchemes())
+
+	Register(bsbScheme{})
+	t.Cleanup(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		delete(schemes, "bsb")
+	})
+
+	assert.Equal(t, []models.IdentifierScheme{models.SchemeABA, models.SchemeBBAN, "bsb", models.SchemeIBAN, models.SchemeSortCode},
+		ExternalSchemes())
+	got, err := Normalize("bsb", "062000 12345678")
+	require.NoError(t, err)
+	assert.Equal(t, "062000 12345678", got)
+}
@@ -0,0 +1,234 @@
+package identifier
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/nacha"
+)
+
+// Built-in schemes
+var (
+	// Internal identifies the accounts of this ledger by their UUID
+	Internal Scheme = internalScheme{}
+	// Legacy identifies the accounts of this ledger opened before account IDs were UUIDs by their
+	// name, such as Mark or the system accounts; names never have the layout of a UUID
+	Legacy Scheme = legacyScheme{}
+	// IBAN identifies accounts by International Bank Account Number (ISO 13616)
+	IBAN Scheme = ibanScheme{}
+	// BBAN identifies accounts by the Basic Bank Account Number of a country of the IBAN registry,
+	// preceded by its country code; it is the IBAN without check digits, for counterparties that
+	// only give the national account number
+	BBAN Scheme = bbanScheme{}
+	// SortCode identifies UK accounts by the 6 digit sort code of the branch followed by
+	// the 8 digit account number
+	SortCode Scheme = sortCodeScheme{}
+	// ABA identifies US accounts by the 9 digit ABA routing number of the bank followed by
+	// the account number, as ACH entries do
+	ABA Scheme = abaScheme{}
+)
+
+// legacyIDPattern is the format of legacy account IDs: 1-64 letters, digits, '-' or '_', starting with a letter or digit
+var legacyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// uuidLayout is the layout of UUIDs in their textual form, x standing for a hexadecimal digit
+const uuidLayout = "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx"
+
+type internalScheme struct{}
+
+func (internalScheme) Name() models.IdentifierScheme { return models.SchemeInternal }
+
+// Normalize lower-cases the hexadecimal digits, since UUIDs are case-insensitive
+func (internalScheme) Normalize(value string) string { return strings.ToLower(value) }
+
+// Validate checks that value is a UUID in lower case, as account IDs are generated
+func (internalScheme) Validate(value string) error {
+	if !hasUUIDLayout(value) {
+		return errors.New("must be a UUID such as 3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30")
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] != '-' && !isDigit(value[i]) && (value[i] < 'a' || value[i] > 'f') {
+			return errors.New("must consist of lower case hexadecimal digits and hyphens")
+		}
+	}
+	return nil
+}
+
+type legacyScheme struct{}
+
+func (legacyScheme) Name() models.IdentifierScheme { return models.SchemeLegacy }
+
+// Normalize returns value unchanged, since legacy account IDs are case-sensitive
+func (legacyScheme) Normalize(value string) string { return value }
+
+// Validate checks the format of legacy account IDs, refusing UUIDs, which are internal account IDs
+func (legacyScheme) Validate(value string) error {
+	if !legacyIDPattern.MatchString(value) {
+		return errors.New("must be 1-64 letters, digits, '-' or '_' starting with a letter or digit")
+	}
+	if hasUUIDLayout(value) {
+		return errors.New("must not have the layout of a UUID")
+	}
+	return nil
+}
+
+// hasUUIDLayout reports whether value has the length and hyphens of a UUID
+func hasUUIDLayout(value string) bool {
+	if len(value) != len(uuidLayout) {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if (uuidLayout[i] == '-') != (value[i] == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+type ibanScheme struct{}
+
+func (ibanScheme) Name() models.IdentifierScheme { return models.SchemeIBAN }
+
+// Normalize removes the spaces IBANs are printed with and upper-cases the letters
+func (ibanScheme) Normalize(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+}
+
+// Validate checks the country, the length used in that country and the check digits
+func (ibanScheme) Validate(value string) error {
+	if len(value) < 5 {
+		return errors.New("too short")
+	}
+	for i := 0; i < len(value); i++ {
+		if !isUpper(value[i]) && !isDigit(value[i]) {
+			return errors.New("must consist of letters and digits")
+		}
+	}
+	country := value[:2]
+	length, ok := ibanLengths[country]
+	if !ok {
+		return fmt.Errorf("unknown country code %q", country)
+	}
+	if len(value) != length {
+		return fmt.Errorf("must be %d characters long in %s", length, country)
+	}
+	if !isDigit(value[2]) || !isDigit(value[3]) {
+		return errors.New("check digits must be digits")
+	}
+	if mod97(value[4:]+value[:4]) != 1 {
+		return errors.New("check digits do not match")
+	}
+	return nil
+}
+
+// mod97 returns the remainder of dividing value, letters counting as 10 to 35, by 97
+func mod97(value string) int {
+	remainder := 0
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if isDigit(c) {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		} else {
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return remainder
+}
+
+// ibanLengths is the length of IBANs in each country of the IBAN registry
+var ibanLengths = map[string]int{
+	"AD": 24, "AE": 23, "AL": 28, "AT": 20, "AZ": 28, "BA": 20, "BE": 16, "BG": 22, "BH": 22, "BI": 27,
+	"BR": 29, "BY": 28, "CH": 21, "CR": 22, "CY": 28, "CZ": 24, "DE": 22, "DJ": 27, "DK": 18, "DO": 28,
+	"EE": 20, "EG": 29, "ES": 24, "FI": 18, "FK": 18, "FO": 18, "FR": 27, "GB": 22, "GE": 22, "GI": 23,
+	"GL": 18, "GR": 27, "GT": 28, "HR": 21, "HU": 28, "IE": 22, "IL": 23, "IQ": 23, "IS": 26, "IT": 27,
+	"JO": 30, "KW": 30, "KZ": 20, "LB": 28, "LC": 32, "LI": 21, "LT": 20, "LU": 20, "LV": 21, "LY": 25,
+	"MC": 27, "MD": 24, "ME": 22, "MK": 19, "MN": 20, "MR": 27, "MT": 31, "MU": 30, "NI": 28, "NL": 18,
+	"NO": 15, "OM": 23, "PK": 24, "PL": 28, "PS": 29, "PT": 25, "QA": 29, "RO": 24, "RS": 22, "RU": 33,
+	"SA": 24, "SC": 31, "SD": 18, "SE": 24, "SI": 19, "SK": 24, "SM": 27, "SO": 23, "ST": 25, "SV": 28,
+	"TL": 23, "TN": 24, "TR": 26, "UA": 29, "VA": 22, "VG": 24, "XK": 20, "YE": 30,
+}
+
+type bbanScheme struct{}
+
+func (bbanScheme) Name() models.IdentifierScheme { return models.SchemeBBAN }
+
+// Normalize removes the spaces BBANs are printed with and upper-cases the letters
+func (bbanScheme) Normalize(value string) string {
+	return strings.ToUpper(strings.ReplaceAll(value, " ", ""))
+}
+
+// Validate checks the country and the length of BBANs in that country, four less than of its IBANs
+// BBANs carry no check digits common to all countries, so those of the national schemes are not checked.
+func (bbanScheme) Validate(value string) error {
+	if len(value) < 3 {
+		return errors.New("must be a country code followed by the BBAN")
+	}
+	for i := 0; i < len(value); i++ {
+		if !isUpper(value[i]) && !isDigit(value[i]) {
+			return errors.New("must consist of letters and digits")
+		}
+	}
+	country := value[:2]
+	length, ok := ibanLengths[country]
+	if !ok {
+		return fmt.Errorf("unknown country code %q", country)
+	}
+	if len(value)-2 != length-4 {
+		return fmt.Errorf("BBAN must be %d characters long in %s", length-4, country)
+	}
+	return nil
+}
+
+type sortCodeScheme struct{}
+
+func (sortCodeScheme) Name() models.IdentifierScheme { return models.SchemeSortCode }
+
+// Normalize removes the spaces and hyphens sort codes are printed with, as in 12-34-56 12345678
+func (sortCodeScheme) Normalize(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+func (sortCodeScheme) Validate(value string) error {
+	if len(value) != 14 {
+		return errors.New("must be a 6 digit sort code followed by an 8 digit account number")
+	}
+	for i := 0; i < len(value); i++ {
+		if !isDigit(value[i]) {
+			return errors.New("must consist of digits")
+		}
+	}
+	return nil
+}
+
+type abaScheme struct{}
+
+func (abaScheme) Name() models.IdentifierScheme { return models.SchemeABA }
+
+// Normalize removes the spaces and hyphens routing and account numbers are printed with
+func (abaScheme) Normalize(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+// Validate checks the check digit of the routing number and the length of the account number,
+// which ACH entries hold in 17 characters
+func (abaScheme) Validate(value string) error {
+	if len(value) < 10 || len(value) > 26 {
+		return errors.New("must be a 9 digit routing number followed by an account number of 1 to 17 digits")
+	}
+	for i := 0; i < len(value); i++ {
+		if !isDigit(value[i]) {
+			return errors.New("must consist of digits")
+		}
+	}
+	if !nacha.ValidRoutingNumber(value[:9]) {
+		return errors.New("routing number check digit does not match")
+	}
+	return nil
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isUpper(c byte) bool { return c >= 'A' && c <= 'Z' }
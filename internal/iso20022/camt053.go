@@ -0,0 +1,227 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+
+	"money-transfer/internal/domain/models"
+)
+
+// Limits of the camt.053 elements statements are written to
+const (
+	// maxAccountID is the length of a generic account identifier, a Max34Text
+	maxAccountID = 34
+	// maxEntryInfo is the length of the additional entry information, a Max500Text
+	maxEntryInfo = 500
+)
+
+// Credit and debit indicators of amounts
+const (
+	credit = "CRDT"
+	debit  = "DBIT"
+)
+
+// camt053Document is the layout of a camt.053.001.08 document
+// Fields follow the element order of the schema, which is significant.
+type camt053Document struct {
+	XMLName   xml.Name `xml:"Document"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Statement struct {
+		GroupHeader struct {
+			MessageID string `xml:"MsgId"`
+			CreatedAt string `xml:"CreDtTm"`
+		} `xml:"GrpHdr"`
+		Statement camt053Statement `xml:"Stmt"`
+	} `xml:"BkToCstmrStmt"`
+}
+
+type camt053Statement struct {
+	ID        string `xml:"Id"`
+	CreatedAt string `xml:"CreDtTm"`
+	Period    struct {
+		From string `xml:"FrDtTm"`
+		To   string `xml:"ToDtTm"`
+	} `xml:"FrToDt"`
+	Account  camt053Account   `xml:"Acct"`
+	Balances []camt053Balance `xml:"Bal"`
+	Summary  struct {
+		Total struct {
+			Count string          `xml:"NbOfNtries"`
+			Sum   string          `xml:"Sum"`
+			Net   camt053NetEntry `xml:"TtlNetNtry"`
+		} `xml:"TtlNtries"`
+		Credits camt053Totals `xml:"TtlCdtNtries"`
+		Debits  camt053Totals `xml:"TtlDbtNtries"`
+	} `xml:"TxsSummry"`
+	Entries []camt053Entry `xml:"Ntry"`
+}
+
+type camt053Account struct {
+	ID       string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy,omitempty"`
+}
+
+type camt053Amount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type camt053Balance struct {
+	Type      string        `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    camt053Amount `xml:"Amt"`
+	Indicator string        `xml:"CdtDbtInd"`
+	DateTime  string        `xml:"Dt>DtTm"`
+}
+
+type camt053NetEntry struct {
+	Amount    string `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"`
+}
+
+type camt053Totals struct {
+	Count string `xml:"NbOfNtries"`
+	Sum   string `xml:"Sum"`
+}
+
+type camt053Entry struct {
+	Reference         string        `xml:"NtryRef"`
+	Amount            camt053Amount `xml:"Amt"`
+	Indicator         string        `xml:"CdtDbtInd"`
+	Status            string        `xml:"Sts>Cd"`
+	BookedAt          string        `xml:"BookgDt>DtTm"`
+	ValueAt           string        `xml:"ValDt>DtTm"`
+	ServicerReference string        `xml:"AcctSvcrRef,omitempty"`
+	TransactionCode   struct {
+		Domain    string `xml:"Domn>Cd"`
+		Family    string `xml:"Domn>Fmly>Cd"`
+		SubFamily string `xml:"Domn>Fmly>SubFmlyCd"`
+	} `xml:"BkTxCd"`
+	Details *camt053Details `xml:"NtryDtls>TxDtls"`
+	Info    string          `xml:"AddtlNtryInf,omitempty"`
+}
+
+type camt053Details struct {
+	ServicerReference string          `xml:"Refs>AcctSvcrRef"`
+	DebtorAccount     *camt053Account `xml:"RltdPties>DbtrAcct,omitempty"`
+	CreditorAccount   *camt053Account `xml:"RltdPties>CdtrAcct,omitempty"`
+}
+
+// WriteBankToCustomerStatement writes s as a camt.053.001.08 document identified by messageID
+// Every line is a booked entry; transfer entries carry the Reference of the transfer and the
+// counterparty account. Returns an error if an account ID is longer than the 34 characters
+// camt.053 allows.
+func WriteBankToCustomerStatement(w io.Writer, s *models.Statement, messageID string) error {
+	if err := checkAccountID(s.AccountID); err != nil {
+		return err
+	}
+
+	var doc camt053Document
+	doc.Xmlns = NamespaceCamt053
+	doc.Statement.GroupHeader.MessageID = messageID
+	doc.Statement.GroupHeader.CreatedAt = formatDateTime(s.GeneratedAt)
+
+	stmt := &doc.Statement.Statement
+	stmt.ID = messageID
+	stmt.CreatedAt = formatDateTime(s.GeneratedAt)
+	stmt.Period.From = formatDateTime(s.From)
+	stmt.Period.To = formatDateTime(s.To)
+	stmt.Account = camt053Account{ID: s.AccountID, Currency: s.Currency}
+	stmt.Balances = []camt053Balance{
+		balance("OPBD", s.OpeningBalance, s.Currency, formatDateTime(s.From)),
+		balance("CLBD", s.ClosingBalance, s.Currency, formatDateTime(s.To)),
+	}
+
+	var credits, debits int
+	for _, line := range s.Lines {
+		e, err := entry(line, s.AccountID, s.Currency)
+		if err != nil {
+			return err
+		}
+		if line.Amount < 0 {
+			debits++
+		} else {
+			credits++
+		}
+		stmt.Entries = append(stmt.Entries, e)
+	}
+
+	summary := &stmt.Summary
+	net := s.TotalCredits - s.TotalDebits
+	summary.Total.Count = strconv.Itoa(len(s.Lines))
+	summary.Total.Sum = formatAmount(s.TotalCredits + s.TotalDebits)
+	summary.Total.Net = camt053NetEntry{Amount: formatAmount(math.Abs(net)), Indicator: indicator(net)}
+	summary.Credits = camt053Totals{Count: strconv.Itoa(credits), Sum: formatAmount(s.TotalCredits)}
+	summary.Debits = camt053Totals{Count: strconv.Itoa(debits), Sum: formatAmount(s.TotalDebits)}
+
+	return writeDocument(w, &doc)
+}
+
+// entry converts a statement line into a booked entry of the account accountID
+func entry(line models.StatementLine, accountID, currency string) (camt053Entry, error) {
+	booked := formatDateTime(line.PostedAt)
+	e := camt053Entry{
+		Reference: strconv.FormatInt(line.PostingID, 10),
+		Amount:    camt053Amount{Currency: currency, Value: formatAmount(math.Abs(line.Amount))},
+		Indicator: indicator(line.Amount),
+		Status:    "BOOK",
+		BookedAt:  booked,
+		ValueAt:   booked,
+		Info:      truncate(line.Description, maxEntryInfo),
+	}
+
+	if line.Kind != models.PostingTransfer {
+		// Balances set outside of transfers are manual account management operations
+		e.TransactionCode.Domain, e.TransactionCode.Family, e.TransactionCode.SubFamily = "ACMT", "MCOP", "ADJT"
+		if line.Amount < 0 {
+			e.TransactionCode.Family = "MDOP"
+		}
+		return e, nil
+	}
+
+	if err := checkAccountID(line.Counterparty); err != nil {
+		return e, err
+	}
+	e.ServicerReference = Reference(line.TransferID)
+	details := &camt053Details{ServicerReference: e.ServicerReference}
+	own, other := &camt053Account{ID: accountID}, &camt053Account{ID: line.Counterparty}
+	// Transfers between accounts of the bank are book transfers, issued or received
+	e.TransactionCode.Domain, e.TransactionCode.SubFamily = "PMNT", "BOOK"
+	if line.Amount < 0 {
+		e.TransactionCode.Family = "ICDT"
+		details.DebtorAccount, details.CreditorAccount = own, other
+	} else {
+		e.TransactionCode.Family = "RCDT"
+		details.DebtorAccount, details.CreditorAccount = other, own
+	}
+	e.Details = details
+	return e, nil
+}
+
+// balance returns a balance of type code at the time at, as a non-negative amount and its direction
+func balance(code string, amount float64, currency, at string) camt053Balance {
+	return camt053Balance{
+		Type:      code,
+		Amount:    camt053Amount{Currency: currency, Value: formatAmount(math.Abs(amount))},
+		Indicator: indicator(amount),
+		DateTime:  at,
+	}
+}
+
+// indicator returns whether amount is a credit or a debit
+func indicator(amount float64) string {
+	if amount < 0 {
+		return debit
+	}
+	return credit
+}
+
+// checkAccountID returns an error if id does not fit a generic account identifier
+func checkAccountID(id string) error {
+	if len(id) > maxAccountID {
+		return fmt.Errorf("account ID %q is longer than the %d characters camt.053 allows", id, maxAccountID)
+	}
+	return nil
+}
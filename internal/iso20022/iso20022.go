@@ -0,0 +1,97 @@
+// Package iso20022 reads and writes the ISO 20022 messages exchanged with corporate clients:
+// pain.001 credit transfer initiations, pain.002 payment status reports and camt.053
+// bank-to-customer statements
+package iso20022
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Namespaces of the message versions written
+const (
+	NamespacePain002 = "urn:iso:std:iso:20022:tech:xsd:pain.002.001.11"
+	NamespaceCamt053 = "urn:iso:std:iso:20022:tech:xsd:camt.053.001.08"
+)
+
+// messageIDBytes is the length of generated message IDs before hex encoding
+const messageIDBytes = 16
+
+// namespacePain001 prefixes the namespaces of every pain.001 version read
+const namespacePain001 = "urn:iso:std:iso:20022:tech:xsd:pain.001.001."
+
+// Status is a payment status code of the ExternalPaymentTransactionStatus1Code and
+// ExternalPaymentGroupStatus1Code sets
+type Status string
+
+// Statuses reported for messages, payment instructions and transactions
+const (
+	// StatusSettled reports transactions booked to both accounts
+	StatusSettled Status = "ACSC"
+	// StatusPending reports transactions held for review, or groups of them with none rejected
+	StatusPending Status = "PDNG"
+	// StatusPartiallyAccepted reports groups with some transactions settled or pending and some rejected
+	StatusPartiallyAccepted Status = "PART"
+	// StatusRejected reports transactions, or groups of them, that were not executed
+	StatusRejected Status = "RJCT"
+)
+
+// Reason codes of the ExternalStatusReason1Code set explaining rejections and pending transactions
+const (
+	ReasonIncorrectAccount     = "AC01" // Account unknown or not identified by a supported scheme
+	ReasonTransactionForbidden = "AG01" // Debtor account may not be debited by the sender
+	ReasonZeroAmount           = "AM01" // Amount not greater than zero
+	ReasonNotAllowedAmount     = "AM02" // Amount above the limit of a single transfer
+	ReasonNotAllowedCurrency   = "AM03" // Currency other than the one accounts are held in
+	ReasonInsufficientFunds    = "AM04" // Debtor account balance too low
+	ReasonDuplication          = "AM05" // Transaction sent before and still executing
+	ReasonInvalidControlSum    = "AM10" // Control sum differs from the sum of the amounts
+	ReasonInvalidAmount        = "AM12" // Amount with more than two decimals
+	ReasonInvalidNumberOfTxs   = "AM18" // Number of transactions differs from the transactions given
+	ReasonInvalidDate          = "DT01" // Requested execution date in the future
+	ReasonNarrative            = "NARR" // Reason given in the additional information only
+	ReasonRegulatory           = "RR04" // Refused or held by sanctions screening
+)
+
+// NotProvided is the EndToEndId of transactions the sender gave no end-to-end reference to
+const NotProvided = "NOTPROVIDED"
+
+// StatusReason explains a status with a reason code and free text
+type StatusReason struct {
+	Code string
+	Info string
+}
+
+// Account identifies an account by IBAN or by another identifier, such as an account ID
+type Account struct {
+	IBAN  string
+	Other string
+}
+
+// Reference shortens a transfer ID to fit the 35 characters of ISO 20022 references
+// Transfer IDs are UUIDs; their hyphens are dropped.
+func Reference(transferID string) string {
+	return strings.ReplaceAll(transferID, "-", "")
+}
+
+// NewMessageID returns a random ID for a message sent, fitting ISO 20022 references
+func NewMessageID() (string, error) {
+	buf := make([]byte, messageIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// formatAmount writes an amount with two decimals, as ISO 20022 decimal numbers
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// formatDateTime writes t as an ISO 20022 date and time in UTC
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,270 @@
+package iso20022
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validateSchema checks document against the schema in testdata with xmllint
+// The test is skipped where xmllint is not installed, except in CI, where it fails.
+func validateSchema(t *testing.T, schema string, document []byte) {
+	t.Helper()
+
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		if os.Getenv("CI") != "" {
+			t.Fatal("xmllint is not installed; CI must validate documents against their schema")
+		}
+		t.Skip("xmllint is not installed")
+	}
+	path := filepath.Join(t.TempDir(), "document.xml")
+	require.NoError(t, os.WriteFile(path, document, 0o600))
+
+	out, err := exec.Command(xmllint, "--noout", "--schema", filepath.Join("testdata", schema), path).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestParseCreditTransferInitiation(t *testing.T) {
+	file, err := os.Open("testdata/pain.001.001.10.xml")
+	require.NoError(t, err)
+	defer file.Close()
+
+	msg, err := ParseCreditTransferInitiation(file)
+	require.NoError(t, err)
+
+	controlSum, count := 75.5, 2
+	paymentSum := 50.5
+	assert.Equal(t, &CreditTransferInitiation{
+		MessageID:            "PAYROLL-2024-02",
+		CreatedAt:            time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC),
+		NumberOfTransactions: 3,
+		ControlSum:           &controlSum,
+		Payments: []PaymentInstruction{
+			{
+				ID:                   "PAYROLL-2024-02-1",
+				DebtorName:           "Mark",
+				DebtorAccount:        Account{Other: "Mark"},
+				ExecutionDate:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				NumberOfTransactions: &count,
+				ControlSum:           &paymentSum,
+				Transactions: []CreditTransfer{
+					{
+						InstructionID:         "1",
+						EndToEndID:            "SALARY-JANE",
+						Amount:                40.5,
+						Currency:              "USD",
+						CreditorName:          "Jane",
+						CreditorAccount:       Account{Other: "Jane"},
+						RemittanceInformation: "Salary February",
+					},
+					{EndToEndID: "BONUS-ADAM", Amount: 10, Currency: "USD", CreditorAccount: Account{Other: "Adam"}},
+				},
+			},
+			{
+				ID:            "PAYROLL-2024-02-2",
+				DebtorAccount: Account{IBAN: "DE89370400440532013000"},
+				ExecutionDate: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+				Transactions: []CreditTransfer{
+					{EndToEndID: "RENT-2024-02", Amount: 25, Currency: "EUR", CreditorAccount: Account{Other: "Jane"}},
+				},
+			},
+		},
+	}, msg)
+}
+
+func TestCreditTransferInitiationSample(t *testing.T) {
+	document, err := os.ReadFile("testdata/pain.001.001.10.xml")
+	require.NoError(t, err)
+	validateSchema(t, "pain.001.001.10.xsd", document)
+}
+
+func TestParseCreditTransferInitiation_Versions(t *testing.T) {
+	// Up to version 8 the execution date is a plain date
+	doc := `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn>
+		<GrpHdr><MsgId>M1</MsgId><CreDtTm>2024-02-01T09:30:00.123+01:00</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>
+		<PmtInf><PmtInfId>P1</PmtInfId><ReqdExctnDt>2024-02-01</ReqdExctnDt>
+			<DbtrAcct><Id><Othr><Id>Mark</Id></Othr></Id></DbtrAcct>
+			<CdtTrfTxInf><PmtId><EndToEndId>E1</EndToEndId></PmtId><Amt><InstdAmt Ccy="USD">1.5</InstdAmt></Amt></CdtTrfTxInf>
+		</PmtInf></CstmrCdtTrfInitn></Document>`
+
+	msg, err := ParseCreditTransferInitiation(strings.NewReader(doc))
+	require.NoError(t, err)
+	assert.True(t, msg.CreatedAt.Equal(time.Date(2024, 2, 1, 8, 30, 0, 123000000, time.UTC)))
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), msg.Payments[0].ExecutionDate)
+	assert.Nil(t, msg.Payments[0].NumberOfTransactions)
+	assert.Equal(t, 1.5, msg.Payments[0].Transactions[0].Amount)
+}
+
+func TestParseCreditTransferInitiation_Invalid(t *testing.T) {
+	const header = `<GrpHdr><MsgId>M1</MsgId><CreDtTm>2024-02-01T09:30:00</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>`
+	const account = `<DbtrAcct><Id><Othr><Id>Mark</Id></Othr></Id></DbtrAcct>`
+	const transaction = `<CdtTrfTxInf><PmtId><EndToEndId>E1</EndToEndId></PmtId>` +
+		`<Amt><InstdAmt Ccy="USD">1</InstdAmt></Amt></CdtTrfTxInf>`
+	document := func(body string) string {
+		return `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.10"><CstmrCdtTrfInitn>` +
+			body + `</CstmrCdtTrfInitn></Document>`
+	}
+
+	tests := []struct {
+		name       string
+		doc        string
+		wantDetail string
+	}{
+		{
+			name:       "malformed XML",
+			doc:        "<Document>",
+			wantDetail: "malformed XML",
+		},
+		{
+			name:       "other message",
+			doc:        `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.008.001.08"></Document>`,
+			wantDetail: "not a pain.001 document",
+		},
+		{
+			name:       "no message ID",
+			doc:        document(`<GrpHdr><CreDtTm>2024-02-01T09:30:00</CreDtTm><NbOfTxs>1</NbOfTxs></GrpHdr>`),
+			wantDetail: "GrpHdr/MsgId is missing",
+		},
+		{
+			name:       "no payment",
+			doc:        document(header),
+			wantDetail: "no PmtInf given",
+		},
+		{
+			name: "malformed execution date",
+			doc: document(header + `<PmtInf><PmtInfId>P1</PmtInfId><ReqdExctnDt><Dt>01.02.2024</Dt></ReqdExctnDt>` +
+				account + transaction + `</PmtInf>`),
+			wantDetail: "ReqdExctnDt of payment P1 is not a date",
+		},
+		{
+			name: "malformed amount",
+			doc: document(header + `<PmtInf><PmtInfId>P1</PmtInfId><ReqdExctnDt><Dt>2024-02-01</Dt></ReqdExctnDt>` +
+				account + strings.Replace(transaction, ">1<", ">1,5<", 1) + `</PmtInf>`),
+			wantDetail: `InstdAmt "1,5" of transaction E1 is not a decimal number`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseCreditTransferInitiation(strings.NewReader(tt.doc))
+			assert.ErrorIs(t, err, transfererrors.ErrInvalidPaymentMessage)
+			assert.ErrorContains(t, err, tt.wantDetail)
+			assert.Nil(t, msg)
+		})
+	}
+}
+
+func TestWritePaymentStatusReport(t *testing.T) {
+	report := &PaymentStatusReport{
+		MessageID:                    "7c9e6679742540de944be07fc1f90ae7",
+		CreatedAt:                    time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+		OriginalMessageID:            "PAYROLL-2024-02",
+		OriginalCreatedAt:            time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC),
+		OriginalNumberOfTransactions: 3,
+		GroupStatus:                  StatusPartiallyAccepted,
+		Payments: []PaymentStatus{
+			{
+				OriginalPaymentID: "PAYROLL-2024-02-1",
+				Status:            StatusPartiallyAccepted,
+				Transactions: []TransactionStatus{
+					{
+						OriginalInstructionID: "1",
+						OriginalEndToEndID:    "SALARY-JANE",
+						Status:                StatusSettled,
+						TransferID:            "0b7ce4a4-5f4e-4d8c-9a41-3bd1a1b2c3d4",
+						AcceptedAt:            time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC),
+					},
+					{
+						OriginalEndToEndID: "BONUS-ADAM",
+						Status:             StatusRejected,
+						Reason:             &StatusReason{Code: ReasonNarrative, Info: strings.Repeat("x", 200)},
+					},
+				},
+			},
+			{
+				OriginalPaymentID: "PAYROLL-2024-02-2",
+				Status:            StatusRejected,
+				Reason:            &StatusReason{Code: ReasonIncorrectAccount, Info: "debtor account must be given as Othr/Id"},
+				Transactions:      []TransactionStatus{{OriginalEndToEndID: "RENT-2024-02", Status: StatusRejected}},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePaymentStatusReport(&buf, report))
+	out := buf.String()
+	assert.Contains(t, out, "<GrpSts>PART</GrpSts>")
+	assert.Contains(t, out, "<AcctSvcrRef>0b7ce4a45f4e4d8c9a413bd1a1b2c3d4</AcctSvcrRef>")
+	assert.Contains(t, out, "<AddtlInf>"+strings.Repeat("x", maxReasonInfo)+"</AddtlInf>")
+	assert.Contains(t, out, "<Cd>AC01</Cd>")
+	validateSchema(t, "pain.002.001.11.xsd", buf.Bytes())
+}
+
+func TestWriteBankToCustomerStatement(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	stmt := &models.Statement{
+		AccountID:      "Mark",
+		Currency:       "USD",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		TotalCredits:   10,
+		TotalDebits:    40.1,
+		ClosingBalance: 69.9,
+		Lines: []models.StatementLine{
+			{
+				PostingID: 3, PostedAt: from.Add(time.Hour), Kind: models.PostingTransfer, Description: "Transfer to Jane",
+				TransferID: "0b7ce4a4-5f4e-4d8c-9a41-3bd1a1b2c3d4", Counterparty: "Jane", Amount: -40.1, Balance: 59.9,
+			},
+			{
+				PostingID: 7, PostedAt: from.Add(2 * time.Hour), Kind: models.PostingAdjustment, Description: "Balance adjustment",
+				Amount: 10, Balance: 69.9,
+			},
+		},
+		GeneratedAt: from.AddDate(0, 1, 1),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBankToCustomerStatement(&buf, stmt, "STMT-1"))
+	out := buf.String()
+	assert.Contains(t, out, `<Amt Ccy="USD">100.00</Amt>`)
+	assert.Contains(t, out, `<Amt Ccy="USD">69.90</Amt>`)
+	assert.Contains(t, out, "<Amt>30.10</Amt>")
+	assert.Contains(t, out, "<Cd>ICDT</Cd>")
+	assert.Contains(t, out, "<Cd>MCOP</Cd>")
+	assert.Contains(t, out, "<AcctSvcrRef>0b7ce4a45f4e4d8c9a413bd1a1b2c3d4</AcctSvcrRef>")
+	validateSchema(t, "camt.053.001.08.xsd", buf.Bytes())
+}
+
+func TestWriteBankToCustomerStatement_Empty(t *testing.T) {
+	from := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	stmt := &models.Statement{AccountID: "Jane", Currency: "USD", From: from, To: from.AddDate(0, 0, 7), GeneratedAt: from}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteBankToCustomerStatement(&buf, stmt, "STMT-2"))
+	validateSchema(t, "camt.053.001.08.xsd", buf.Bytes())
+}
+
+func TestWriteBankToCustomerStatement_LongAccountID(t *testing.T) {
+	stmt := &models.Statement{AccountID: strings.Repeat("a", maxAccountID+1), Currency: "USD"}
+
+	err := WriteBankToCustomerStatement(&bytes.Buffer{}, stmt, "STMT-3")
+	assert.ErrorContains(t, err, "longer than the 34 characters")
+}
+
+func TestReference(t *testing.T) {
+	reference := Reference("0b7ce4a4-5f4e-4d8c-9a41-3bd1a1b2c3d4")
+	assert.Equal(t, "0b7ce4a45f4e4d8c9a413bd1a1b2c3d4", reference)
+	assert.LessOrEqual(t, len(reference), 35)
+}
@@ -0,0 +1,244 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// CreditTransferInitiation is a pain.001 message: payment instructions, each debiting one
+// account for any number of credit transfers
+type CreditTransferInitiation struct {
+	MessageID string
+	CreatedAt time.Time
+	// NumberOfTransactions is the number of transactions the sender declared for the message
+	NumberOfTransactions int
+	// ControlSum is the sum of the amounts the sender declared, if any
+	ControlSum *float64
+	Payments   []PaymentInstruction
+}
+
+// PaymentInstruction is a group of credit transfers debited from the same account
+type PaymentInstruction struct {
+	ID            string
+	DebtorName    string
+	DebtorAccount Account
+	// ExecutionDate is the day, in UTC, the transfers are requested to be executed on
+	ExecutionDate time.Time
+	// NumberOfTransactions and ControlSum are the totals the sender declared for the instruction, if any
+	NumberOfTransactions *int
+	ControlSum           *float64
+	Transactions         []CreditTransfer
+}
+
+// CreditTransfer is a single transaction of a payment instruction
+type CreditTransfer struct {
+	InstructionID         string
+	EndToEndID            string
+	Amount                float64
+	Currency              string
+	CreditorName          string
+	CreditorAccount       Account
+	RemittanceInformation string
+}
+
+// pain001Document mirrors the parts of a pain.001 document that are read
+// Elements are matched by local name, so that every pain.001 version is accepted.
+type pain001Document struct {
+	XMLName    xml.Name
+	Initiation struct {
+		GroupHeader struct {
+			MessageID            string `xml:"MsgId"`
+			CreatedAt            string `xml:"CreDtTm"`
+			NumberOfTransactions string `xml:"NbOfTxs"`
+			ControlSum           string `xml:"CtrlSum"`
+		} `xml:"GrpHdr"`
+		Payments []pain001Payment `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+type pain001Payment struct {
+	ID                   string `xml:"PmtInfId"`
+	NumberOfTransactions string `xml:"NbOfTxs"`
+	ControlSum           string `xml:"CtrlSum"`
+	// ExecutionDate holds the date as text up to version 8 and in Dt or DtTm since
+	ExecutionDate struct {
+		Text     string `xml:",chardata"`
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"ReqdExctnDt"`
+	DebtorName    string               `xml:"Dbtr>Nm"`
+	DebtorAccount pain001Account       `xml:"DbtrAcct>Id"`
+	Transactions  []pain001Transaction `xml:"CdtTrfTxInf"`
+}
+
+type pain001Transaction struct {
+	InstructionID string `xml:"PmtId>InstrId"`
+	EndToEndID    string `xml:"PmtId>EndToEndId"`
+	Amount        struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt>InstdAmt"`
+	CreditorName    string         `xml:"Cdtr>Nm"`
+	CreditorAccount pain001Account `xml:"CdtrAcct>Id"`
+	Remittance      []string       `xml:"RmtInf>Ustrd"`
+}
+
+type pain001Account struct {
+	IBAN  string `xml:"IBAN"`
+	Other string `xml:"Othr>Id"`
+}
+
+// ParseCreditTransferInitiation reads a pain.001 message of any version
+// Returns an error wrapping ErrInvalidPaymentMessage when the document is not a pain.001
+// message or misses or malforms an element the message cannot be processed without.
+// Whether the transactions can be executed, and whether the declared totals match them,
+// is left for the caller to decide and report on.
+func ParseCreditTransferInitiation(r io.Reader) (*CreditTransferInitiation, error) {
+	var doc pain001Document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, invalidMessage("malformed XML: %v", err)
+	}
+	if doc.XMLName.Local != "Document" || !strings.HasPrefix(doc.XMLName.Space, namespacePain001) {
+		return nil, invalidMessage("not a pain.001 document: %s %s", doc.XMLName.Space, doc.XMLName.Local)
+	}
+
+	header := doc.Initiation.GroupHeader
+	if header.MessageID == "" {
+		return nil, invalidMessage("GrpHdr/MsgId is missing")
+	}
+	msg := &CreditTransferInitiation{MessageID: strings.TrimSpace(header.MessageID)}
+
+	var err error
+	if msg.CreatedAt, err = time.Parse(time.RFC3339, withZone(header.CreatedAt)); err != nil {
+		return nil, invalidMessage("GrpHdr/CreDtTm %q is not a date and time", header.CreatedAt)
+	}
+	if msg.NumberOfTransactions, err = strconv.Atoi(strings.TrimSpace(header.NumberOfTransactions)); err != nil {
+		return nil, invalidMessage("GrpHdr/NbOfTxs %q is not a number", header.NumberOfTransactions)
+	}
+	if msg.ControlSum, err = parseOptionalDecimal(header.ControlSum); err != nil {
+		return nil, invalidMessage("GrpHdr/CtrlSum %q is not a decimal number", header.ControlSum)
+	}
+
+	if len(doc.Initiation.Payments) == 0 {
+		return nil, invalidMessage("no PmtInf given")
+	}
+	for _, raw := range doc.Initiation.Payments {
+		payment, err := parsePayment(raw)
+		if err != nil {
+			return nil, err
+		}
+		msg.Payments = append(msg.Payments, payment)
+	}
+
+	return msg, nil
+}
+
+// parsePayment converts a payment instruction, reporting the first element it cannot read
+func parsePayment(raw pain001Payment) (PaymentInstruction, error) {
+	payment := PaymentInstruction{
+		ID:            strings.TrimSpace(raw.ID),
+		DebtorName:    strings.TrimSpace(raw.DebtorName),
+		DebtorAccount: raw.DebtorAccount.account(),
+	}
+	if payment.ID == "" {
+		return payment, invalidMessage("PmtInf/PmtInfId is missing")
+	}
+
+	date, err := parseExecutionDate(raw)
+	if err != nil {
+		return payment, invalidMessage("ReqdExctnDt of payment %s is not a date", payment.ID)
+	}
+	payment.ExecutionDate = date
+
+	if value := strings.TrimSpace(raw.NumberOfTransactions); value != "" {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return payment, invalidMessage("NbOfTxs %q of payment %s is not a number", value, payment.ID)
+		}
+		payment.NumberOfTransactions = &count
+	}
+	if payment.ControlSum, err = parseOptionalDecimal(raw.ControlSum); err != nil {
+		return payment, invalidMessage("CtrlSum %q of payment %s is not a decimal number", raw.ControlSum, payment.ID)
+	}
+
+	if len(raw.Transactions) == 0 {
+		return payment, invalidMessage("payment %s has no CdtTrfTxInf", payment.ID)
+	}
+	for _, tx := range raw.Transactions {
+		transfer := CreditTransfer{
+			InstructionID:         strings.TrimSpace(tx.InstructionID),
+			EndToEndID:            strings.TrimSpace(tx.EndToEndID),
+			Currency:              strings.TrimSpace(tx.Amount.Currency),
+			CreditorName:          strings.TrimSpace(tx.CreditorName),
+			CreditorAccount:       tx.CreditorAccount.account(),
+			RemittanceInformation: strings.TrimSpace(strings.Join(tx.Remittance, " ")),
+		}
+		if transfer.EndToEndID == "" {
+			return payment, invalidMessage("a transaction of payment %s has no EndToEndId", payment.ID)
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(tx.Amount.Value), 64)
+		if err != nil {
+			return payment, invalidMessage("InstdAmt %q of transaction %s is not a decimal number",
+				tx.Amount.Value, transfer.EndToEndID)
+		}
+		transfer.Amount = amount
+		payment.Transactions = append(payment.Transactions, transfer)
+	}
+
+	return payment, nil
+}
+
+// parseExecutionDate returns the requested execution day of a payment instruction in UTC
+func parseExecutionDate(raw pain001Payment) (time.Time, error) {
+	if value := strings.TrimSpace(raw.ExecutionDate.DateTime); value != "" {
+		t, err := time.Parse(time.RFC3339, withZone(value))
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	value := strings.TrimSpace(raw.ExecutionDate.Date)
+	if value == "" {
+		value = strings.TrimSpace(raw.ExecutionDate.Text)
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseOptionalDecimal parses a decimal number that may be left out
+func parseOptionalDecimal(value string) (*float64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+// withZone reads ISO date times without a zone, which ISO 20022 allows, as UTC
+func withZone(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= len("2006-01-02T15:04:05") && !strings.ContainsAny(value[len("2006-01-02T15:04:05"):], "Z+-") {
+		return value + "Z"
+	}
+	return value
+}
+
+func (a pain001Account) account() Account {
+	return Account{IBAN: strings.TrimSpace(a.IBAN), Other: strings.TrimSpace(a.Other)}
+}
+
+// invalidMessage returns an error wrapping ErrInvalidPaymentMessage
+func invalidMessage(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", transfererrors.ErrInvalidPaymentMessage, fmt.Sprintf(format, args...))
+}
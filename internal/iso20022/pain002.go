@@ -0,0 +1,166 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// maxReasonInfo is the length of the free text of a status reason, a Max105Text
+const maxReasonInfo = 105
+
+// PaymentStatusReport is a pain.002 message reporting what became of a pain.001 message
+type PaymentStatusReport struct {
+	MessageID                    string
+	CreatedAt                    time.Time
+	OriginalMessageID            string
+	OriginalCreatedAt            time.Time
+	OriginalNumberOfTransactions int
+	GroupStatus                  Status
+	// GroupReason explains the group status when the whole message was rejected
+	GroupReason *StatusReason
+	Payments    []PaymentStatus
+}
+
+// PaymentStatus reports on one payment instruction of the original message
+type PaymentStatus struct {
+	OriginalPaymentID string
+	Status            Status
+	// Reason explains the status when the whole instruction was rejected
+	Reason       *StatusReason
+	Transactions []TransactionStatus
+}
+
+// TransactionStatus reports on one credit transfer of the original message
+type TransactionStatus struct {
+	OriginalInstructionID string
+	OriginalEndToEndID    string
+	Status                Status
+	Reason                *StatusReason
+	// TransferID is the transfer the transaction was executed as, if it got that far
+	TransferID string
+	// AcceptedAt is when the transfer was created, if it was
+	AcceptedAt time.Time
+}
+
+// pain002Document is the layout of a pain.002.001.11 document
+// Fields follow the element order of the schema, which is significant.
+type pain002Document struct {
+	XMLName xml.Name `xml:"Document"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	Report  struct {
+		GroupHeader struct {
+			MessageID string `xml:"MsgId"`
+			CreatedAt string `xml:"CreDtTm"`
+		} `xml:"GrpHdr"`
+		Group struct {
+			OriginalMessageID            string          `xml:"OrgnlMsgId"`
+			OriginalMessageName          string          `xml:"OrgnlMsgNmId"`
+			OriginalCreatedAt            string          `xml:"OrgnlCreDtTm,omitempty"`
+			OriginalNumberOfTransactions string          `xml:"OrgnlNbOfTxs"`
+			Status                       Status          `xml:"GrpSts"`
+			Reasons                      []pain002Reason `xml:"StsRsnInf"`
+		} `xml:"OrgnlGrpInfAndSts"`
+		Payments []pain002Payment `xml:"OrgnlPmtInfAndSts"`
+	} `xml:"CstmrPmtStsRpt"`
+}
+
+type pain002Payment struct {
+	OriginalPaymentID            string               `xml:"OrgnlPmtInfId"`
+	OriginalNumberOfTransactions string               `xml:"OrgnlNbOfTxs"`
+	Status                       Status               `xml:"PmtInfSts"`
+	Reasons                      []pain002Reason      `xml:"StsRsnInf"`
+	Transactions                 []pain002Transaction `xml:"TxInfAndSts"`
+}
+
+type pain002Transaction struct {
+	OriginalInstructionID string          `xml:"OrgnlInstrId,omitempty"`
+	OriginalEndToEndID    string          `xml:"OrgnlEndToEndId"`
+	Status                Status          `xml:"TxSts"`
+	Reasons               []pain002Reason `xml:"StsRsnInf"`
+	AcceptedAt            string          `xml:"AccptncDtTm,omitempty"`
+	ServicerReference     string          `xml:"AcctSvcrRef,omitempty"`
+}
+
+type pain002Reason struct {
+	Code string `xml:"Rsn>Cd"`
+	Info string `xml:"AddtlInf,omitempty"`
+}
+
+// WritePaymentStatusReport writes r as a pain.002.001.11 document
+// Transactions executed as transfers carry the Reference of the transfer as AcctSvcrRef.
+func WritePaymentStatusReport(w io.Writer, r *PaymentStatusReport) error {
+	var doc pain002Document
+	doc.Xmlns = NamespacePain002
+	doc.Report.GroupHeader.MessageID = r.MessageID
+	doc.Report.GroupHeader.CreatedAt = formatDateTime(r.CreatedAt)
+
+	group := &doc.Report.Group
+	group.OriginalMessageID = r.OriginalMessageID
+	group.OriginalMessageName = "pain.001"
+	if !r.OriginalCreatedAt.IsZero() {
+		group.OriginalCreatedAt = formatDateTime(r.OriginalCreatedAt)
+	}
+	group.OriginalNumberOfTransactions = strconv.Itoa(r.OriginalNumberOfTransactions)
+	group.Status = r.GroupStatus
+	group.Reasons = reasons(r.GroupReason)
+
+	for _, payment := range r.Payments {
+		out := pain002Payment{
+			OriginalPaymentID:            payment.OriginalPaymentID,
+			OriginalNumberOfTransactions: strconv.Itoa(len(payment.Transactions)),
+			Status:                       payment.Status,
+			Reasons:                      reasons(payment.Reason),
+		}
+		for _, tx := range payment.Transactions {
+			status := pain002Transaction{
+				OriginalInstructionID: tx.OriginalInstructionID,
+				OriginalEndToEndID:    tx.OriginalEndToEndID,
+				Status:                tx.Status,
+				Reasons:               reasons(tx.Reason),
+			}
+			if tx.TransferID != "" {
+				status.ServicerReference = Reference(tx.TransferID)
+			}
+			if !tx.AcceptedAt.IsZero() {
+				status.AcceptedAt = formatDateTime(tx.AcceptedAt)
+			}
+			out.Transactions = append(out.Transactions, status)
+		}
+		doc.Report.Payments = append(doc.Report.Payments, out)
+	}
+
+	return writeDocument(w, &doc)
+}
+
+// reasons converts an optional status reason into the status reason elements written
+func reasons(reason *StatusReason) []pain002Reason {
+	if reason == nil {
+		return nil
+	}
+	return []pain002Reason{{Code: reason.Code, Info: truncate(reason.Info, maxReasonInfo)}}
+}
+
+// truncate shortens s to at most n characters
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// writeDocument writes doc as an indented XML document with its declaration
+func writeDocument(w io.Writer, doc any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,165 @@
+// Package lifecycle starts the components of the service in order and stops them in
+// reverse order, so that each component outlives everything that depends on it
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Hook starts and stops a component; either function may be nil
+// OnStart must not tie the component's lifetime to its context, which only bounds startup.
+// OnStop must return once its context is done, even if the component has not drained.
+type Hook struct {
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Lifecycle is what components register their hooks with
+type Lifecycle interface {
+	Append(hook Hook)
+}
+
+// Worker is a background component that runs until the context it was started with is
+// canceled, like the transfer executor and the outbox relay
+type Worker interface {
+	Start(ctx context.Context)
+	Wait()
+}
+
+// Manager runs the hooks registered by the components of the service
+type Manager struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	hooks    []Hook
+	started  int
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a manager logging the progress of startup and shutdown to logger
+func New(logger *slog.Logger) *Manager {
+	return &Manager{
+		logger:   logger,
+		stopping: make(chan struct{}),
+	}
+}
+
+// Append registers a hook; hooks start in the order they are appended and stop in reverse
+func (m *Manager) Append(hook Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Start runs the OnStart hooks in order
+// If one fails, the components already started are stopped and its error is returned.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks[m.started:]
+	m.mu.Unlock()
+
+	for _, hook := range hooks {
+		if hook.OnStart != nil {
+			if err := hook.OnStart(ctx); err != nil {
+				err = fmt.Errorf("failed to start %s: %w", hook.Name, err)
+				return errors.Join(err, m.Stop(ctx))
+			}
+			m.logger.DebugContext(ctx, "component started", "component", hook.Name)
+		}
+
+		m.mu.Lock()
+		m.started++
+		m.mu.Unlock()
+	}
+	return nil
+}
+
+// Stopping is closed once Stop is called; long-lived streams end when it is
+func (m *Manager) Stopping() <-chan struct{} {
+	return m.stopping
+}
+
+// Stop runs the OnStop hooks of the started components in reverse order
+// All hooks run even when ctx expires, so that resources such as the database are
+// always released; the errors of the hooks that failed are returned together.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.stopOnce.Do(func() { close(m.stopping) })
+
+	m.mu.Lock()
+	hooks := m.hooks[:m.started]
+	m.started = 0
+	m.mu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hook := hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := hook.OnStop(ctx); err != nil {
+			m.logger.ErrorContext(ctx, "failed to stop component", "component", hook.Name, "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", hook.Name, err))
+			continue
+		}
+		m.logger.InfoContext(ctx, "component stopped", "component", hook.Name,
+			"duration_ms", time.Since(start).Milliseconds())
+	}
+	return errors.Join(errs...)
+}
+
+// WorkerHook returns a hook that starts w and, on stop, cancels it and waits for it
+// to finish the work it has already taken on
+func WorkerHook(name string, w Worker) Hook {
+	var cancel context.CancelFunc
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.WithoutCancel(ctx))
+			w.Start(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			return Wait(ctx, w.Wait)
+		},
+	}
+}
+
+// Wait calls wait and returns once it does, or with the error of ctx once ctx is done
+func Wait(ctx context.Context, wait func()) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("gave up waiting: %w", ctx.Err())
+	}
+}
+
+// Until returns a copy of ctx that is also canceled once stopping is closed
+func Until(ctx context.Context, stopping <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-stopping:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
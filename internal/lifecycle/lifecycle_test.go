@@ -0,0 +1,197 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"money-transfer/internal/lifecycle"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the order in which hooks run
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string, startErr error) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			r.record("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.record("stop " + name)
+			return nil
+		},
+	}
+}
+
+func (r *recorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		name         string
+		startErrs    map[string]error
+		wantStartErr bool
+		wantCalls    []string
+	}{
+		{
+			name: "starts in order and stops in reverse",
+			wantCalls: []string{
+				"start store", "start relay", "start http",
+				"stop http", "stop relay", "stop store",
+			},
+		},
+		{
+			name:         "failed start stops the components already started",
+			startErrs:    map[string]error{"relay": errors.New("broker unreachable")},
+			wantStartErr: true,
+			wantCalls:    []string{"start store", "start relay", "stop store"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recorder{}
+			lc := lifecycle.New(logging.Discard())
+			for _, name := range []string{"store", "relay", "http"} {
+				lc.Append(rec.hook(name, tt.startErrs[name]))
+			}
+
+			err := lc.Start(context.Background())
+			if tt.wantStartErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, lc.Stop(context.Background()))
+			}
+
+			assert.Equal(t, tt.wantCalls, rec.calls)
+
+			// Stopping twice does not run the hooks again
+			require.NoError(t, lc.Stop(context.Background()))
+			assert.Equal(t, tt.wantCalls, rec.calls)
+		})
+	}
+}
+
+func TestManager_StopRunsEveryHook(t *testing.T) {
+	closed := false
+	lc := lifecycle.New(logging.Discard())
+	lc.Append(lifecycle.Hook{Name: "store", OnStop: func(context.Context) error {
+		closed = true
+		return nil
+	}})
+	lc.Append(lifecycle.Hook{Name: "http", OnStop: func(context.Context) error {
+		return errors.New("connections left open")
+	}})
+	require.NoError(t, lc.Start(context.Background()))
+
+	select {
+	case <-lc.Stopping():
+		t.Fatal("stopping closed before Stop")
+	default:
+	}
+
+	err := lc.Stop(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to stop http")
+	assert.True(t, closed)
+	_, open := <-lc.Stopping()
+	assert.False(t, open)
+}
+
+// worker runs until canceled, then takes drain to finish its work
+type worker struct {
+	drain time.Duration
+	done  chan struct{}
+}
+
+func (w *worker) Start(ctx context.Context) {
+	w.done = make(chan struct{})
+	go func() {
+		defer close(w.done)
+		<-ctx.Done()
+		time.Sleep(w.drain)
+	}()
+}
+
+func (w *worker) Wait() {
+	<-w.done
+}
+
+func TestWorkerHook(t *testing.T) {
+	tests := []struct {
+		name    string
+		drain   time.Duration
+		timeout time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "waits for the worker to drain",
+			drain:   10 * time.Millisecond,
+			timeout: time.Second,
+		},
+		{
+			name:    "gives up at the deadline",
+			drain:   time.Second,
+			timeout: 10 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &worker{drain: tt.drain}
+			hook := lifecycle.WorkerHook("worker", w)
+
+			// The worker outlives the context it was started with
+			startCtx, cancelStart := context.WithCancel(context.Background())
+			require.NoError(t, hook.OnStart(startCtx))
+			cancelStart()
+			select {
+			case <-w.done:
+				t.Fatal("worker stopped with its start context")
+			case <-time.After(20 * time.Millisecond):
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+			err := hook.OnStop(ctx)
+
+			if tt.wantErr {
+				assert.ErrorIs(t, err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestUntil(t *testing.T) {
+	stopping := make(chan struct{})
+	ctx, cancel := lifecycle.Until(context.Background(), stopping)
+	defer cancel()
+
+	assert.NoError(t, ctx.Err())
+	close(stopping)
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("context not canceled after stopping was closed")
+	}
+}
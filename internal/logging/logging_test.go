@@ -0,0 +1,84 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     logging.Config
+		wantErr bool
+	}{
+		{name: "json", cfg: logging.Config{Level: "info", Format: "json"}},
+		{name: "text", cfg: logging.Config{Level: "debug", Format: "text"}},
+		{name: "default format", cfg: logging.Config{Level: "warn"}},
+		{name: "unknown level", cfg: logging.Config{Level: "verbose"}, wantErr: true},
+		{name: "unknown format", cfg: logging.Config{Level: "info", Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := logging.New(&bytes.Buffer{}, tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotNil(t, logger)
+		})
+	}
+}
+
+func TestNew_AddsCorrelationIDs(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Level: "info", Format: "json"})
+	require.NoError(t, err)
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}))
+	logger.With("component", "test").InfoContext(ctx, "transfer requested", "amount", 50)
+	logger.Debug("dropped below the configured level")
+	logger.Info("no request")
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &record))
+	assert.Equal(t, "transfer requested", record["msg"])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+
+	record = nil
+	require.NoError(t, json.Unmarshal(lines[1], &record))
+	assert.NotContains(t, record, "request_id")
+	assert.NotContains(t, record, "trace_id")
+	assert.NotContains(t, record, "span_id")
+}
+
+func TestFromContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Config{Level: "info"})
+	require.NoError(t, err)
+
+	logging.FromContext(context.Background()).Error("discarded")
+	logging.FromContext(logging.WithLogger(context.Background(), logger)).Error("kept")
+
+	assert.NotContains(t, buf.String(), "discarded")
+	assert.Contains(t, buf.String(), "kept")
+}
@@ -0,0 +1,173 @@
+// Package metrics collects the Prometheus metrics served on /metrics
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes the names of all application metrics
+const namespace = "money_transfer"
+
+// Transfer outcomes counted by the transfers_total metric
+const (
+	OutcomeSuccess            = "success"
+	OutcomeInsufficientFunds  = "insufficient_funds"
+	OutcomeNotFound           = "not_found"
+	OutcomeSerializationRetry = "serialization_retry"
+	OutcomeRejected           = "rejected"
+	OutcomeBlocked            = "blocked"
+	OutcomeHeld               = "held"
+	OutcomeError              = "error"
+)
+
+// Reconciliation results counted by the reconciliation_runs_total metric
+const (
+	ReconciliationBalanced      = "balanced"
+	ReconciliationDiscrepancies = "discrepancies"
+	ReconciliationError         = "error"
+)
+
+// defaultStatsTimeout bounds the queries run for business gauges on each scrape
+const defaultStatsTimeout = 5 * time.Second
+
+// Collector records application metrics in its own registry
+// A nil *Collector records nothing, so components may be built without metrics.
+type Collector struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	transfers       *prometheus.CounterVec
+	transferAmounts prometheus.Histogram
+
+	reconciliations             *prometheus.CounterVec
+	reconciliationDiscrepancies *prometheus.GaugeVec
+	reconciliationLastRun       prometheus.Gauge
+}
+
+// New creates a collector with the Go runtime and process metrics already registered
+func New() *Collector {
+	c := &Collector{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests served, by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time taken to serve HTTP requests, by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		transfers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "transfers_total",
+			Help:      "Transfer executions by outcome; serialization_retry counts retried attempts.",
+		}, []string{"outcome"}),
+		transferAmounts: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "transfer_amount",
+			Help:      "Amounts of completed transfers.",
+			Buckets:   []float64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 50000, 100000, 1000000},
+		}),
+		reconciliations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "reconciliation_runs_total",
+			Help:      "Reconciliation runs by result: balanced, discrepancies or error.",
+		}, []string{"result"}),
+		reconciliationDiscrepancies: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "reconciliation_discrepancies",
+			Help:      "Discrepancies found by the last completed reconciliation, by kind.",
+		}, []string{"kind"}),
+		reconciliationLastRun: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "reconciliation_last_run_timestamp_seconds",
+			Help:      "Unix time the last completed reconciliation finished at.",
+		}),
+	}
+
+	c.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		c.httpRequests,
+		c.httpDuration,
+		c.transfers,
+		c.transferAmounts,
+		c.reconciliations,
+		c.reconciliationDiscrepancies,
+		c.reconciliationLastRun,
+	)
+	return c
+}
+
+// RegisterStore adds the connection pool statistics of the store's database and
+// business gauges queried from the store on every scrape
+func (c *Collector) RegisterStore(store storage.Store) {
+	c.registry.MustRegister(
+		collectors.NewDBStatsCollector(store.DB(), "postgres"),
+		newStoreCollector(store, defaultStatsTimeout),
+	)
+}
+
+// Handler serves the collected metrics in the Prometheus exposition format
+func (c *Collector) Handler() http.Handler {
+	return promhttp.HandlerFor(c.registry, promhttp.HandlerOpts{ErrorHandling: promhttp.ContinueOnError})
+}
+
+// ObserveRequest records a served HTTP request
+func (c *Collector) ObserveRequest(method, route string, status int, duration time.Duration) {
+	if c == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	c.httpRequests.WithLabelValues(method, route, code).Inc()
+	c.httpDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+// ObserveTransfer records the outcome of a transfer execution and, for successful ones, its amount
+func (c *Collector) ObserveTransfer(outcome string, amount float64) {
+	if c == nil {
+		return
+	}
+	c.transfers.WithLabelValues(outcome).Inc()
+	if outcome == OutcomeSuccess {
+		c.transferAmounts.Observe(amount)
+	}
+}
+
+// ObserveReconciliation records a reconciliation run that produced report or failed with err
+// Failed runs leave the discrepancies of the last completed run in place.
+func (c *Collector) ObserveReconciliation(report *models.ReconciliationReport, err error) {
+	if c == nil {
+		return
+	}
+	if err != nil {
+		c.reconciliations.WithLabelValues(ReconciliationError).Inc()
+		return
+	}
+
+	if report.Balanced {
+		c.reconciliations.WithLabelValues(ReconciliationBalanced).Inc()
+	} else {
+		c.reconciliations.WithLabelValues(ReconciliationDiscrepancies).Inc()
+	}
+
+	counts := make(map[models.DiscrepancyKind]int, len(models.DiscrepancyKinds))
+	for _, discrepancy := range report.Discrepancies {
+		counts[discrepancy.Kind]++
+	}
+	for _, kind := range models.DiscrepancyKinds {
+		c.reconciliationDiscrepancies.WithLabelValues(string(kind)).Set(float64(counts[kind]))
+	}
+	c.reconciliationLastRun.Set(float64(report.FinishedAt.Unix()))
+}
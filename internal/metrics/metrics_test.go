@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_ObserveRequest(t *testing.T) {
+	c := New()
+	c.ObserveRequest(http.MethodPost, "/api/v1/transfer", http.StatusOK, 20*time.Millisecond)
+	c.ObserveRequest(http.MethodPost, "/api/v1/transfer", http.StatusOK, 30*time.Millisecond)
+	c.ObserveRequest(http.MethodPost, "/api/v1/transfer", http.StatusBadRequest, time.Millisecond)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.httpRequests.WithLabelValues("POST", "/api/v1/transfer", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.httpRequests.WithLabelValues("POST", "/api/v1/transfer", "400")))
+	assert.Equal(t, 2, testutil.CollectAndCount(c.httpDuration))
+}
+
+func TestCollector_ObserveTransfer(t *testing.T) {
+	c := New()
+	c.ObserveTransfer(OutcomeSuccess, 50)
+	c.ObserveTransfer(OutcomeSerializationRetry, 75)
+	c.ObserveTransfer(OutcomeSuccess, 75)
+	c.ObserveTransfer(OutcomeInsufficientFunds, 1000)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.transfers.WithLabelValues(OutcomeSuccess)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.transfers.WithLabelValues(OutcomeSerializationRetry)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.transfers.WithLabelValues(OutcomeInsufficientFunds)))
+
+	// Only completed transfers contribute their amount
+	err := testutil.CollectAndCompare(c.transferAmounts, strings.NewReader(`
+# HELP money_transfer_transfer_amount Amounts of completed transfers.
+# TYPE money_transfer_transfer_amount histogram
+money_transfer_transfer_amount_bucket{le="1"} 0
+money_transfer_transfer_amount_bucket{le="5"} 0
+money_transfer_transfer_amount_bucket{le="10"} 0
+money_transfer_transfer_amount_bucket{le="50"} 1
+money_transfer_transfer_amount_bucket{le="100"} 2
+money_transfer_transfer_amount_bucket{le="500"} 2
+money_transfer_transfer_amount_bucket{le="1000"} 2
+money_transfer_transfer_amount_bucket{le="5000"} 2
+money_transfer_transfer_amount_bucket{le="10000"} 2
+money_transfer_transfer_amount_bucket{le="50000"} 2
+money_transfer_transfer_amount_bucket{le="100000"} 2
+money_transfer_transfer_amount_bucket{le="1e+06"} 2
+money_transfer_transfer_amount_bucket{le="+Inf"} 2
+money_transfer_transfer_amount_sum 125
+money_transfer_transfer_amount_count 2
+`))
+	assert.NoError(t, err)
+}
+
+func TestCollector_ObserveReconciliation(t *testing.T) {
+	c := New()
+	finished := time.Unix(1700000000, 0)
+	c.ObserveReconciliation(&models.ReconciliationReport{
+		FinishedAt: finished,
+		Discrepancies: []models.Discrepancy{
+			{Kind: models.DiscrepancyBalance, AccountID: "Mark"},
+			{Kind: models.DiscrepancyBalance, AccountID: "Jane"},
+			{Kind: models.DiscrepancyConservation},
+		},
+	}, nil)
+	c.ObserveReconciliation(nil, assert.AnError)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.reconciliations.WithLabelValues(ReconciliationDiscrepancies)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.reconciliations.WithLabelValues(ReconciliationError)))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.reconciliationDiscrepancies.WithLabelValues("balance")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.reconciliationDiscrepancies.WithLabelValues("transfer")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.reconciliationDiscrepancies.WithLabelValues("conservation")))
+	assert.Equal(t, 1700000000.0, testutil.ToFloat64(c.reconciliationLastRun))
+
+	// A balanced run clears the discrepancies of the run before it
+	c.ObserveReconciliation(&models.ReconciliationReport{FinishedAt: finished, Balanced: true}, nil)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.reconciliations.WithLabelValues(ReconciliationBalanced)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(c.reconciliationDiscrepancies.WithLabelValues("balance")))
+}
+
+func TestCollector_NilRecordsNothing(t *testing.T) {
+	var c *Collector
+	assert.NotPanics(t, func() {
+		c.ObserveRequest(http.MethodGet, "/", http.StatusOK, time.Millisecond)
+		c.ObserveTransfer(OutcomeSuccess, 10)
+		c.ObserveReconciliation(nil, assert.AnError)
+	})
+}
+
+func TestStoreCollector(t *testing.T) {
+	store := mocks.NewStore(t)
+	accounts := mocks.NewAccountRepository(t)
+	transfers := mocks.NewTransferRepository(t)
+	store.On("Account").Return(accounts)
+	store.On("Transfer").Return(transfers)
+	accounts.On("Totals", mock.Anything).Return(&models.AccountTotals{Accounts: 3, Balance: 150}, nil)
+	transfers.On("CountByStatus", mock.Anything).Return(map[models.TransferStatus]int{
+		models.TransferStatusCompleted: 7,
+		models.TransferStatusPending:   2,
+	}, nil)
+
+	err := testutil.CollectAndCompare(newStoreCollector(store, time.Second), strings.NewReader(`
+# HELP money_transfer_accounts Number of accounts.
+# TYPE money_transfer_accounts gauge
+money_transfer_accounts 3
+# HELP money_transfer_balance_under_management Sum of the balances of all accounts.
+# TYPE money_transfer_balance_under_management gauge
+money_transfer_balance_under_management 150
+# HELP money_transfer_transfers Number of transfers in each status.
+# TYPE money_transfer_transfers gauge
+money_transfer_transfers{status="completed"} 7
+money_transfer_transfers{status="created"} 0
+money_transfer_transfers{status="failed"} 0
+money_transfer_transfers{status="held"} 0
+money_transfer_transfers{status="pending"} 2
+money_transfer_transfers{status="processing"} 0
+money_transfer_transfers{status="reversed"} 0
+`))
+	require.NoError(t, err)
+}
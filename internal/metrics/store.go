@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	accountsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "accounts"),
+		"Number of accounts.", nil, nil)
+	balanceDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "balance_under_management"),
+		"Sum of the balances of all accounts.", nil, nil)
+	transfersByStatusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "transfers"),
+		"Number of transfers in each status.", []string{"status"}, nil)
+)
+
+// transferStatuses are reported on every scrape, with zero when no transfer is in them
+var transferStatuses = []models.TransferStatus{
+	models.TransferStatusCreated,
+	models.TransferStatusHeld,
+	models.TransferStatusPending,
+	models.TransferStatusProcessing,
+	models.TransferStatusCompleted,
+	models.TransferStatusFailed,
+	models.TransferStatusReversed,
+}
+
+// storeCollector queries business gauges from storage when scraped
+type storeCollector struct {
+	store   storage.Store
+	timeout time.Duration
+}
+
+func newStoreCollector(store storage.Store, timeout time.Duration) *storeCollector {
+	return &storeCollector{store: store, timeout: timeout}
+}
+
+// Describe sends the descriptors of the business gauges
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- accountsDesc
+	ch <- balanceDesc
+	ch <- transfersByStatusDesc
+}
+
+// Collect queries the store; a failed query is reported as an invalid metric without
+// hiding the gauges that could be read
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	totals, err := c.store.Account().Totals(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(accountsDesc, err)
+	} else {
+		ch <- prometheus.MustNewConstMetric(accountsDesc, prometheus.GaugeValue, float64(totals.Accounts))
+		ch <- prometheus.MustNewConstMetric(balanceDesc, prometheus.GaugeValue, totals.Balance)
+	}
+
+	counts, err := c.store.Transfer().CountByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(transfersByStatusDesc, err)
+		return
+	}
+	for _, status := range transferStatuses {
+		ch <- prometheus.MustNewConstMetric(transfersByStatusDesc, prometheus.GaugeValue,
+			float64(counts[status]), string(status))
+	}
+}
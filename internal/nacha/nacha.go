@@ -0,0 +1,192 @@
+// Package nacha writes and reads NACHA ACH files: fixed-width records of 94 characters,
+// grouped into batches of entries and framed by control records carrying their totals
+package nacha
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout of NACHA files
+const (
+	// recordLength is the length of every record, without its line break
+	recordLength = 94
+	// blockingFactor is the number of records per block; the last block is filled with 9s
+	blockingFactor = 10
+	// maxAmount is the largest amount, in cents, an entry's 10 digit amount field holds
+	maxAmount = 99_999_999_99
+)
+
+// Record type codes, the first character of each record
+const (
+	recordFileHeader   = '1'
+	recordBatchHeader  = '5'
+	recordEntry        = '6'
+	recordAddenda      = '7'
+	recordBatchControl = '8'
+	recordFileControl  = '9'
+)
+
+// Addenda type codes
+const (
+	addendaPaymentInfo = "05"
+	addendaReturn      = "99"
+)
+
+// Service class codes of batches, derived from their entries
+const (
+	ServiceClassMixed   = 200
+	ServiceClassCredits = 220
+	ServiceClassDebits  = 225
+)
+
+// TransactionCode tells the account type and direction of an entry
+type TransactionCode int
+
+// Transaction codes of checking and savings accounts
+const (
+	CheckingReturnCredit TransactionCode = 21 // Return or notification of change of a credit
+	CheckingCredit       TransactionCode = 22
+	CheckingReturnDebit  TransactionCode = 26 // Return or notification of change of a debit
+	CheckingDebit        TransactionCode = 27
+	SavingsReturnCredit  TransactionCode = 31
+	SavingsCredit        TransactionCode = 32
+	SavingsReturnDebit   TransactionCode = 36
+	SavingsDebit         TransactionCode = 37
+)
+
+// IsCredit reports whether the entry moves money into the receiver's account
+// Codes ending in 1 to 4 are credits and codes ending in 6 to 9 debits.
+func (c TransactionCode) IsCredit() bool {
+	return c%10 >= 1 && c%10 <= 4
+}
+
+// File is an ACH file sent to or received from the ACH operator
+type File struct {
+	Header  FileHeader
+	Batches []Batch
+}
+
+// FileHeader identifies the sender and receiver of a file
+type FileHeader struct {
+	// ImmediateDestination is the routing number of the bank or operator the file is sent to
+	ImmediateDestination string
+	// ImmediateOrigin identifies the sender: a routing number, or a 10 character company ID
+	ImmediateOrigin string
+	DestinationName string
+	OriginName      string
+	CreatedAt       time.Time
+	// IDModifier tells apart files created on the same day, from A to Z then 0 to 9
+	IDModifier    byte
+	ReferenceCode string
+}
+
+// Batch groups entries of the same originator, entry class and effective date
+type Batch struct {
+	CompanyName string
+	// CompanyID identifies the originator to the receivers, usually 1 followed by the EIN
+	CompanyID string
+	// SECCode is the standard entry class of the entries, such as PPD or CCD
+	SECCode          string
+	EntryDescription string
+	EffectiveDate    time.Time
+	// ODFI is the 8 digit identification of the originating bank, the routing number without its check digit
+	ODFI    string
+	Number  int
+	Entries []Entry
+}
+
+// Entry moves money between the originator and one receiver account
+type Entry struct {
+	TransactionCode TransactionCode
+	// RDFI is the 9 digit routing number of the receiving bank
+	RDFI           string
+	AccountNumber  string
+	Amount         int64 // Amount in cents
+	IndividualID   string
+	IndividualName string
+	// TraceNumber identifies the entry: the ODFI followed by a 7 digit sequence number
+	TraceNumber string
+	// PaymentInfo is written as a payment related information addenda when not empty
+	PaymentInfo string
+	// Return is the return addenda of an entry sent back by the receiving bank
+	Return *Return
+}
+
+// Return explains why the receiving bank sent an entry back
+type Return struct {
+	// Code is the return reason code, such as R01
+	Code                string
+	OriginalTraceNumber string
+	// OriginalRDFI is the 8 digit identification of the bank the original entry was sent to
+	OriginalRDFI string
+	Info         string
+}
+
+// Returns lists the returned entries of f
+func (f *File) Returns() []Entry {
+	var returns []Entry
+	for _, batch := range f.Batches {
+		for _, entry := range batch.Entries {
+			if entry.Return != nil {
+				returns = append(returns, entry)
+			}
+		}
+	}
+	return returns
+}
+
+// returnReasons describes the return reason codes most often received
+var returnReasons = map[string]string{
+	"R01": "Insufficient funds",
+	"R02": "Account closed",
+	"R03": "No account or unable to locate account",
+	"R04": "Invalid account number",
+	"R06": "Returned per ODFI request",
+	"R07": "Authorization revoked by customer",
+	"R08": "Payment stopped",
+	"R10": "Customer advises not authorized",
+	"R16": "Account frozen",
+	"R17": "File record edit criteria",
+	"R20": "Non-transaction account",
+	"R23": "Credit entry refused by receiver",
+	"R29": "Corporate customer advises not authorized",
+}
+
+// ReturnReason describes a return reason code
+func ReturnReason(code string) string {
+	if reason, ok := returnReasons[code]; ok {
+		return reason
+	}
+	return fmt.Sprintf("Return reason %s", code)
+}
+
+// TraceNumber returns the trace number of the entry numbered sequence in a file sent by odfi
+func TraceNumber(odfi string, sequence int) string {
+	return fmt.Sprintf("%s%07d", odfi, sequence)
+}
+
+// ValidRoutingNumber reports whether routing is 9 digits whose check digit matches
+// The check digit is the last digit; 3, 7 and 1 times the digits, in turn, must add up to a
+// multiple of 10.
+func ValidRoutingNumber(routing string) bool {
+	if len(routing) != 9 || !isDigits(routing) {
+		return false
+	}
+	weights := [9]int{3, 7, 1, 3, 7, 1, 3, 7, 1}
+	sum := 0
+	for i := range routing {
+		sum += int(routing[i]-'0') * weights[i]
+	}
+	return sum%10 == 0
+}
+
+// isDigits reports whether s consists of ASCII digits only
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
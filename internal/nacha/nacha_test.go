@@ -0,0 +1,228 @@
+package nacha
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFile() *File {
+	return &File{
+		Header: FileHeader{
+			ImmediateDestination: "011000015",
+			ImmediateOrigin:      "1234567890",
+			DestinationName:      "FEDERAL RESERVE BANK",
+			OriginName:           "MONEY TRANSFER",
+			CreatedAt:            time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC),
+			IDModifier:           'A',
+			ReferenceCode:        "PAYOUTS",
+		},
+		Batches: []Batch{{
+			CompanyName:      "MONEY TRANSFER",
+			CompanyID:        "1234567890",
+			SECCode:          "PPD",
+			EntryDescription: "PAYOUT",
+			EffectiveDate:    time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+			ODFI:             "02100002",
+			Number:           1,
+			Entries: []Entry{
+				{
+					TransactionCode: CheckingCredit,
+					RDFI:            "021000021",
+					AccountNumber:   "123456789",
+					Amount:          10050,
+					IndividualID:    "Mark",
+					IndividualName:  "MARK SMITH",
+					TraceNumber:     TraceNumber("02100002", 1),
+					PaymentInfo:     "Invoice 42",
+				},
+				{
+					TransactionCode: SavingsCredit,
+					RDFI:            "111000025",
+					AccountNumber:   "987654321",
+					Amount:          2500,
+					IndividualID:    "Jane",
+					IndividualName:  "JANE DOE",
+					TraceNumber:     TraceNumber("02100002", 2),
+				},
+			},
+		}},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, testFile()))
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, 10)
+	for _, line := range lines {
+		assert.Len(t, line, recordLength)
+	}
+
+	assert.Equal(t, "101 01100001512345678902402010930A094101", lines[0][:40])
+	assert.Equal(t, "5220MONEY TRANSFER", lines[1][:18])
+	assert.Equal(t, "622021000021123456789        0000010050Mark", lines[2][:43])
+	assert.Equal(t, "1021000020000001", lines[2][78:])
+	assert.Equal(t, "705Invoice 42", lines[3][:13])
+	assert.Equal(t, "00010000001", lines[3][83:])
+	assert.Equal(t, "0021000020000002", lines[4][78:])
+	// 3 records, a hash of 02100002 + 11100002 and 125.50 of credits
+	assert.Equal(t, "82200000030013200004000000000000000000012550", lines[5][:44])
+	assert.Equal(t, "9000001000001000000030013200004000000000000000000012550", lines[6][:55])
+	assert.Equal(t, strings.Repeat("9", recordLength), lines[9])
+}
+
+func TestWriteInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(f *File)
+		want   string
+	}{
+		{
+			name:   "routing number check digit",
+			modify: func(f *File) { f.Batches[0].Entries[0].RDFI = "021000022" },
+			want:   "batch 1: entry 1: invalid RDFI",
+		},
+		{
+			name:   "immediate destination",
+			modify: func(f *File) { f.Header.ImmediateDestination = "12345" },
+			want:   "invalid immediate destination",
+		},
+		{
+			name:   "amount too large",
+			modify: func(f *File) { f.Batches[0].Entries[1].Amount = maxAmount + 1 },
+			want:   "batch 1: entry 2: invalid amount",
+		},
+		{
+			name:   "empty batch",
+			modify: func(f *File) { f.Batches[0].Entries = nil },
+			want:   "batch 1: no entries",
+		},
+		{
+			name:   "ODFI",
+			modify: func(f *File) { f.Batches[0].ODFI = "021000021" },
+			want:   "batch 1: invalid ODFI",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testFile()
+			tt.modify(f)
+
+			err := Write(&bytes.Buffer{}, f)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParseRoundTrip(t *testing.T) {
+	f := testFile()
+	f.Batches[0].Entries[0].TransactionCode = CheckingDebit
+	f.Batches = append(f.Batches, f.Batches[0])
+	f.Batches[1].Number = 2
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, f))
+
+	parsed, err := Parse(bytes.NewReader(out.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, f, parsed)
+
+	// Files without line breaks are read as a sequence of 94 character records
+	unbroken := strings.ReplaceAll(out.String(), "\n", "")
+	parsed, err = Parse(strings.NewReader(unbroken))
+	require.NoError(t, err)
+	assert.Equal(t, f, parsed)
+}
+
+func TestParseReturns(t *testing.T) {
+	f := testFile()
+	f.Header.ImmediateDestination, f.Header.ImmediateOrigin = "021000021", "011000015"
+	f.Batches[0].ODFI = "01100001"
+	f.Batches[0].Entries = []Entry{{
+		TransactionCode: CheckingReturnCredit,
+		RDFI:            "021000021",
+		AccountNumber:   "123456789",
+		Amount:          10050,
+		IndividualID:    "Mark",
+		IndividualName:  "MARK SMITH",
+		TraceNumber:     TraceNumber("01100001", 1),
+		Return: &Return{
+			Code:                "R03",
+			OriginalTraceNumber: TraceNumber("02100002", 1),
+			OriginalRDFI:        "02100002",
+			Info:                "No account",
+		},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, f))
+
+	parsed, err := Parse(&out)
+	require.NoError(t, err)
+	returns := parsed.Returns()
+	require.Len(t, returns, 1)
+	assert.Equal(t, f.Batches[0].Entries[0].Return, returns[0].Return)
+	assert.Equal(t, "No account or unable to locate account", ReturnReason(returns[0].Return.Code))
+	assert.Equal(t, "Return reason R99", ReturnReason("R99"))
+}
+
+func TestParseInvalid(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, testFile()))
+	valid := strings.Split(out.String(), "\n")
+
+	replace := func(line int, start int, value string) string {
+		lines := append([]string(nil), valid...)
+		lines[line] = lines[line][:start] + value + lines[line][start+len(value):]
+		return strings.Join(lines, "\n")
+	}
+
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{name: "empty", file: "", want: "empty file"},
+		{name: "short record", file: "101 011000015", want: "record 1: 13 characters long"},
+		{
+			name: "altered amount",
+			file: replace(2, 29, "0000010051"),
+			want: "record 6: total credit amount 12550, but the entries add up to 12551",
+		},
+		{name: "altered routing number", file: replace(4, 3, "111000035"), want: "record 6: entry hash"},
+		{
+			name: "missing batch control",
+			file: strings.Join(append(valid[:5:5], valid[6:]...), "\n"),
+			want: "record 5: record of type 9, want 8",
+		},
+		{name: "truncated", file: strings.Join(valid[:5], "\n"), want: "file ends before a record of type 8"},
+		{name: "batch count", file: replace(6, 1, "000002"), want: "batch count 2"},
+		{name: "record after control", file: replace(8, 0, "1"), want: "record after the file control record"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.file))
+			require.ErrorIs(t, err, transfererrors.ErrInvalidPaymentMessage)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestValidRoutingNumber(t *testing.T) {
+	assert.True(t, ValidRoutingNumber("011000015"))
+	assert.True(t, ValidRoutingNumber("021000021"))
+	assert.False(t, ValidRoutingNumber("021000022"))
+	assert.False(t, ValidRoutingNumber("02100002"))
+	assert.False(t, ValidRoutingNumber("02100002a"))
+}
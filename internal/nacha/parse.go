@@ -0,0 +1,259 @@
+package nacha
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// Parse reads a NACHA file, such as a return file received from the ACH operator
+// Records may be separated by line breaks or not. The batch and file control records are
+// checked against the entries, so that a truncated or altered file is refused. Returns an
+// error wrapping ErrInvalidPaymentMessage naming the first record that is malformed.
+func Parse(r io.Reader) (*File, error) {
+	records, err := splitRecords(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{records: records}
+	return p.file()
+}
+
+// parser reads the records of a file in order
+type parser struct {
+	records []string
+	next    int
+}
+
+// file reads the file header, the batches and the file control record
+func (p *parser) file() (*File, error) {
+	header, err := p.expect(recordFileHeader)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{Header: FileHeader{
+		ImmediateDestination: strings.TrimSpace(header[3:13]),
+		ImmediateOrigin:      strings.TrimSpace(header[13:23]),
+		IDModifier:           header[33],
+		DestinationName:      strings.TrimSpace(header[40:63]),
+		OriginName:           strings.TrimSpace(header[63:86]),
+		ReferenceCode:        strings.TrimSpace(header[86:94]),
+	}}
+	if f.Header.CreatedAt, err = time.Parse("0601021504", header[23:33]); err != nil {
+		return nil, p.invalid("file creation date and time %q", header[23:33])
+	}
+
+	var fileTotals totals
+	for p.peek() == recordBatchHeader {
+		batch, batchTotals, err := p.batch()
+		if err != nil {
+			return nil, err
+		}
+		f.Batches = append(f.Batches, batch)
+		fileTotals.add(batchTotals)
+	}
+
+	control, err := p.expect(recordFileControl)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkTotals(control, 13, 21, 31, 43, 55, fileTotals); err != nil {
+		return nil, err
+	}
+	if count, _ := strconv.Atoi(control[1:7]); count != len(f.Batches) {
+		return nil, p.invalid("batch count %d, but the file has %d batches", count, len(f.Batches))
+	}
+
+	// Whatever follows the file control record must be padding
+	for ; p.next < len(p.records); p.next++ {
+		if strings.Trim(p.records[p.next], "9") != "" {
+			return nil, p.invalid("record after the file control record")
+		}
+	}
+	return f, nil
+}
+
+// batch reads a batch header, its entries and the batch control record
+func (p *parser) batch() (Batch, totals, error) {
+	var t totals
+	header, _ := p.expect(recordBatchHeader)
+	batch := Batch{
+		CompanyName:      strings.TrimSpace(header[4:20]),
+		CompanyID:        strings.TrimSpace(header[40:50]),
+		SECCode:          strings.TrimSpace(header[50:53]),
+		EntryDescription: strings.TrimSpace(header[53:63]),
+		ODFI:             header[79:87],
+	}
+	var err error
+	if effective := strings.TrimSpace(header[69:75]); effective != "" {
+		if batch.EffectiveDate, err = time.Parse("060102", effective); err != nil {
+			return batch, t, p.invalid("effective entry date %q", effective)
+		}
+	}
+	if batch.Number, err = strconv.Atoi(header[87:94]); err != nil {
+		return batch, t, p.invalid("batch number %q", header[87:94])
+	}
+
+	for p.peek() == recordEntry {
+		entry, records, err := p.entry()
+		if err != nil {
+			return batch, t, err
+		}
+		batch.Entries = append(batch.Entries, entry)
+
+		t.records += records
+		t.hash += routingIdentification(entry.RDFI)
+		if entry.TransactionCode.IsCredit() {
+			t.credit += entry.Amount
+		} else {
+			t.debit += entry.Amount
+		}
+	}
+
+	control, err := p.expect(recordBatchControl)
+	if err != nil {
+		return batch, t, err
+	}
+	if err := p.checkTotals(control, 4, 10, 20, 32, 44, t); err != nil {
+		return batch, t, err
+	}
+	return batch, t, nil
+}
+
+// entry reads an entry detail record and its addenda, returning the number of records read
+func (p *parser) entry() (Entry, int, error) {
+	r, _ := p.expect(recordEntry)
+	code, err := strconv.Atoi(r[1:3])
+	if err != nil {
+		return Entry{}, 0, p.invalid("transaction code %q", r[1:3])
+	}
+	amount, err := strconv.ParseInt(r[29:39], 10, 64)
+	if err != nil {
+		return Entry{}, 0, p.invalid("amount %q", r[29:39])
+	}
+	entry := Entry{
+		TransactionCode: TransactionCode(code),
+		RDFI:            r[3:12],
+		AccountNumber:   strings.TrimSpace(r[12:29]),
+		Amount:          amount,
+		IndividualID:    strings.TrimSpace(r[39:54]),
+		IndividualName:  strings.TrimSpace(r[54:76]),
+		TraceNumber:     r[79:94],
+	}
+	if !isDigits(entry.RDFI) {
+		return entry, 0, p.invalid("RDFI %q", entry.RDFI)
+	}
+
+	records := 1
+	for p.peek() == recordAddenda {
+		addenda, _ := p.expect(recordAddenda)
+		records++
+		switch addenda[1:3] {
+		case addendaReturn:
+			entry.Return = &Return{
+				Code:                addenda[3:6],
+				OriginalTraceNumber: strings.TrimSpace(addenda[6:21]),
+				OriginalRDFI:        strings.TrimSpace(addenda[27:35]),
+				Info:                strings.TrimSpace(addenda[35:79]),
+			}
+		case addendaPaymentInfo:
+			entry.PaymentInfo = strings.TrimSpace(entry.PaymentInfo + " " + strings.TrimSpace(addenda[3:83]))
+		}
+	}
+	if hasAddenda := r[78] == '1'; hasAddenda != (records > 1) {
+		return entry, 0, p.invalid("addenda record indicator %q of entry %s", r[78], entry.TraceNumber)
+	}
+	return entry, records, nil
+}
+
+// checkTotals compares the totals of a control record, found at the given 0-based offsets,
+// with the totals of the entries read
+func (p *parser) checkTotals(control string, records, hash, debit, credit, end int, t totals) error {
+	fields := []struct {
+		name  string
+		value string
+		want  int64
+	}{
+		{"entry and addenda count", control[records:hash], int64(t.records)},
+		{"entry hash", control[hash : hash+10], t.hash % 10_000_000_000},
+		{"total debit amount", control[debit:credit], t.debit},
+		{"total credit amount", control[credit:end], t.credit},
+	}
+	for _, field := range fields {
+		value, err := strconv.ParseInt(field.value, 10, 64)
+		if err != nil {
+			return p.invalid("%s %q", field.name, field.value)
+		}
+		if value != field.want {
+			return p.invalid("%s %d, but the entries add up to %d", field.name, value, field.want)
+		}
+	}
+	return nil
+}
+
+// peek returns the type of the next record, or 0 at the end of the file
+func (p *parser) peek() byte {
+	if p.next >= len(p.records) {
+		return 0
+	}
+	return p.records[p.next][0]
+}
+
+// expect returns the next record, which must be of type kind
+func (p *parser) expect(kind byte) (string, error) {
+	if p.peek() != kind {
+		if p.next >= len(p.records) {
+			return "", p.invalid("file ends before a record of type %c", kind)
+		}
+		return "", p.invalid("record of type %c, want %c", p.peek(), kind)
+	}
+	p.next++
+	return p.records[p.next-1], nil
+}
+
+// invalid returns an error wrapping ErrInvalidPaymentMessage about the record last read
+func (p *parser) invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: record %d: %s",
+		transfererrors.ErrInvalidPaymentMessage, max(p.next, 1), fmt.Sprintf(format, args...))
+}
+
+// splitRecords splits a file into its records of 94 characters
+func splitRecords(r io.Reader) ([]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []string
+	if bytes.ContainsAny(data, "\r\n") {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+				records = append(records, line)
+			}
+		}
+	} else {
+		for len(data) > 0 {
+			n := min(recordLength, len(data))
+			records = append(records, string(data[:n]))
+			data = data[n:]
+		}
+	}
+
+	for i, record := range records {
+		if len(record) != recordLength {
+			return nil, fmt.Errorf("%w: record %d: %d characters long, want %d",
+				transfererrors.ErrInvalidPaymentMessage, i+1, len(record), recordLength)
+		}
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: empty file", transfererrors.ErrInvalidPaymentMessage)
+	}
+	return records, nil
+}
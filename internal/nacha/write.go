@@ -0,0 +1,273 @@
+package nacha
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// totals are the control totals of a batch or file
+type totals struct {
+	// records counts entries and addenda
+	records int
+	// hash is the sum of the RDFI identifications of the entries, cut to its last 10 digits when written
+	hash   int64
+	debit  int64
+	credit int64
+}
+
+func (t *totals) add(other totals) {
+	t.records += other.records
+	t.hash += other.hash
+	t.debit += other.debit
+	t.credit += other.credit
+}
+
+// Write writes f as a NACHA file, filling in the control records and the blocking
+// Service class codes are derived from the entries of each batch. Returns an error naming the
+// first field that does not fit the format, such as a routing number with a wrong check digit.
+func Write(w io.Writer, f *File) error {
+	header, err := fileHeaderRecord(f.Header)
+	if err != nil {
+		return err
+	}
+	records := []record{header}
+
+	var fileTotals totals
+	for i, batch := range f.Batches {
+		batchRecords, batchTotals, err := batchRecords(batch)
+		if err != nil {
+			return fmt.Errorf("batch %d: %w", i+1, err)
+		}
+		records = append(records, batchRecords...)
+		fileTotals.add(batchTotals)
+	}
+
+	blocks := (len(records) + 1 + blockingFactor - 1) / blockingFactor
+	control := newRecord(recordFileControl)
+	control.numeric(2, 6, int64(len(f.Batches)))
+	control.numeric(8, 6, int64(blocks))
+	control.numeric(14, 8, int64(fileTotals.records))
+	control.numeric(22, 10, fileTotals.hash%10_000_000_000)
+	control.numeric(32, 12, fileTotals.debit)
+	control.numeric(44, 12, fileTotals.credit)
+	records = append(records, control)
+
+	padding := record(strings.Repeat("9", recordLength))
+	for len(records)%blockingFactor != 0 {
+		records = append(records, padding)
+	}
+
+	out := bufio.NewWriter(w)
+	for _, r := range records {
+		if _, err := out.Write(r); err != nil {
+			return err
+		}
+		if err := out.WriteByte('\n'); err != nil {
+			return err
+		}
+	}
+	return out.Flush()
+}
+
+// fileHeaderRecord returns the file header record of h
+func fileHeaderRecord(h FileHeader) (record, error) {
+	if !ValidRoutingNumber(h.ImmediateDestination) {
+		return nil, fmt.Errorf("invalid immediate destination %q: want a routing number", h.ImmediateDestination)
+	}
+	if h.ImmediateOrigin == "" || len(h.ImmediateOrigin) > 10 {
+		return nil, fmt.Errorf("invalid immediate origin %q: want 1 to 10 characters", h.ImmediateOrigin)
+	}
+	modifier := h.IDModifier
+	if modifier == 0 {
+		modifier = 'A'
+	}
+	if !(modifier >= 'A' && modifier <= 'Z' || modifier >= '0' && modifier <= '9') {
+		return nil, fmt.Errorf("invalid file ID modifier %q: want A-Z or 0-9", modifier)
+	}
+
+	r := newRecord(recordFileHeader)
+	r.alpha(2, 2, "01")
+	// Routing numbers are right-justified in their 10 characters, after a space
+	r.alpha(4, 10, fmt.Sprintf("%10s", h.ImmediateDestination))
+	r.alpha(14, 10, fmt.Sprintf("%10s", h.ImmediateOrigin))
+	r.alpha(24, 6, h.CreatedAt.Format("060102"))
+	r.alpha(30, 4, h.CreatedAt.Format("1504"))
+	r[33] = modifier
+	r.alpha(35, 3, "094")
+	r.alpha(38, 2, "10")
+	r.alpha(40, 1, "1")
+	r.alpha(41, 23, h.DestinationName)
+	r.alpha(64, 23, h.OriginName)
+	r.alpha(87, 8, h.ReferenceCode)
+	return r, nil
+}
+
+// batchRecords returns the header, entry, addenda and control records of b with its totals
+func batchRecords(b Batch) ([]record, totals, error) {
+	var t totals
+	if len(b.Entries) == 0 {
+		return nil, t, fmt.Errorf("no entries")
+	}
+	if len(b.ODFI) != 8 || !isDigits(b.ODFI) {
+		return nil, t, fmt.Errorf("invalid ODFI %q: want 8 digits", b.ODFI)
+	}
+	if len(b.SECCode) != 3 {
+		return nil, t, fmt.Errorf("invalid SEC code %q: want 3 characters", b.SECCode)
+	}
+	if b.CompanyID == "" || len(b.CompanyID) > 10 {
+		return nil, t, fmt.Errorf("invalid company ID %q: want 1 to 10 characters", b.CompanyID)
+	}
+
+	class := serviceClass(b.Entries)
+	header := newRecord(recordBatchHeader)
+	header.numeric(2, 3, int64(class))
+	header.alpha(5, 16, b.CompanyName)
+	header.alpha(41, 10, b.CompanyID)
+	header.alpha(51, 3, b.SECCode)
+	header.alpha(54, 10, b.EntryDescription)
+	header.alpha(70, 6, b.EffectiveDate.Format("060102"))
+	header.alpha(79, 1, "1")
+	header.alpha(80, 8, b.ODFI)
+	header.numeric(88, 7, int64(b.Number))
+	records := []record{header}
+
+	for i, entry := range b.Entries {
+		entryRecords, err := entryRecords(entry)
+		if err != nil {
+			return nil, t, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		records = append(records, entryRecords...)
+
+		t.records += len(entryRecords)
+		t.hash += routingIdentification(entry.RDFI)
+		if entry.TransactionCode.IsCredit() {
+			t.credit += entry.Amount
+		} else {
+			t.debit += entry.Amount
+		}
+	}
+
+	control := newRecord(recordBatchControl)
+	control.numeric(2, 3, int64(class))
+	control.numeric(5, 6, int64(t.records))
+	control.numeric(11, 10, t.hash%10_000_000_000)
+	control.numeric(21, 12, t.debit)
+	control.numeric(33, 12, t.credit)
+	control.alpha(45, 10, b.CompanyID)
+	control.alpha(80, 8, b.ODFI)
+	control.numeric(88, 7, int64(b.Number))
+	records = append(records, control)
+
+	return records, t, nil
+}
+
+// entryRecords returns the entry detail record of e followed by its addenda
+func entryRecords(e Entry) ([]record, error) {
+	if !ValidRoutingNumber(e.RDFI) {
+		return nil, fmt.Errorf("invalid RDFI %q: want a routing number", e.RDFI)
+	}
+	if e.AccountNumber == "" || len(e.AccountNumber) > 17 {
+		return nil, fmt.Errorf("invalid account number %q: want 1 to 17 characters", e.AccountNumber)
+	}
+	if e.Amount < 0 || e.Amount > maxAmount {
+		return nil, fmt.Errorf("invalid amount %d cents", e.Amount)
+	}
+	if len(e.TraceNumber) != 15 || !isDigits(e.TraceNumber) {
+		return nil, fmt.Errorf("invalid trace number %q: want 15 digits", e.TraceNumber)
+	}
+
+	r := newRecord(recordEntry)
+	r.numeric(2, 2, int64(e.TransactionCode))
+	r.alpha(4, 9, e.RDFI)
+	r.alpha(13, 17, e.AccountNumber)
+	r.numeric(30, 10, e.Amount)
+	r.alpha(40, 15, e.IndividualID)
+	r.alpha(55, 22, e.IndividualName)
+	r.alpha(80, 15, e.TraceNumber)
+	records := []record{r}
+
+	// The entry detail sequence number of addenda is the sequence part of the trace number
+	sequence := e.TraceNumber[8:]
+	switch {
+	case e.Return != nil:
+		addenda := newRecord(recordAddenda)
+		addenda.alpha(2, 2, addendaReturn)
+		addenda.alpha(4, 3, e.Return.Code)
+		addenda.alpha(7, 15, e.Return.OriginalTraceNumber)
+		addenda.alpha(28, 8, e.Return.OriginalRDFI)
+		addenda.alpha(36, 44, e.Return.Info)
+		addenda.alpha(80, 15, e.TraceNumber)
+		records = append(records, addenda)
+	case e.PaymentInfo != "":
+		addenda := newRecord(recordAddenda)
+		addenda.alpha(2, 2, addendaPaymentInfo)
+		addenda.alpha(4, 80, e.PaymentInfo)
+		addenda.numeric(84, 4, 1)
+		addenda.alpha(88, 7, sequence)
+		records = append(records, addenda)
+	}
+	if len(records) > 1 {
+		r[78] = '1'
+	} else {
+		r[78] = '0'
+	}
+	return records, nil
+}
+
+// serviceClass returns the service class code of a batch holding entries
+func serviceClass(entries []Entry) int {
+	var credits, debits bool
+	for _, entry := range entries {
+		if entry.TransactionCode.IsCredit() {
+			credits = true
+		} else {
+			debits = true
+		}
+	}
+	switch {
+	case credits && debits:
+		return ServiceClassMixed
+	case debits:
+		return ServiceClassDebits
+	default:
+		return ServiceClassCredits
+	}
+}
+
+// routingIdentification returns the first 8 digits of a routing number, as added to entry hashes
+func routingIdentification(routing string) int64 {
+	var id int64
+	for i := 0; i < 8 && i < len(routing); i++ {
+		id = id*10 + int64(routing[i]-'0')
+	}
+	return id
+}
+
+// record is a fixed-width record being written
+type record []byte
+
+// newRecord returns a record of type kind filled with spaces
+func newRecord(kind byte) record {
+	r := record(strings.Repeat(" ", recordLength))
+	r[0] = kind
+	return r
+}
+
+// alpha writes value left-justified into the width characters starting at the 1-based position start
+// Values are cut to fit, and characters NACHA does not allow are replaced with spaces.
+func (r record) alpha(start, width int, value string) {
+	field := r[start-1 : start-1+width]
+	for i := range field {
+		field[i] = ' '
+		if i < len(value) && value[i] >= 0x20 && value[i] < 0x7f {
+			field[i] = value[i]
+		}
+	}
+}
+
+// numeric writes value zero-padded into the width digits starting at the 1-based position start
+func (r record) numeric(start, width int, value int64) {
+	r.alpha(start, width, fmt.Sprintf("%0*d", width, value))
+}
@@ -0,0 +1,257 @@
+package rail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/nacha"
+	"money-transfer/internal/storage"
+)
+
+// ACHName is the name of the ACH rail
+const ACHName = "ach"
+
+// maxFileEntries is the most payouts one ACH file carries; the rest go in the next file
+const maxFileEntries = 10000
+
+// secCode is the standard entry class of payouts: prearranged payments to consumer accounts
+const secCode = "PPD"
+
+// individualIDLength is the length of the individual ID of entries, the start of their payout ID
+const individualIDLength = 15
+
+// ACHConfig identifies this bank and the ACH operator in the files the ACH rail writes
+type ACHConfig struct {
+	// Destination is the routing number of the ACH operator or partner bank files are sent to
+	Destination     string
+	DestinationName string
+	// Origin identifies this bank to the destination: a routing number or a 10 character company ID
+	Origin     string
+	OriginName string
+	// CompanyName and CompanyID identify the originator of the entries to their receivers
+	CompanyName string
+	CompanyID   string
+	// ODFI is the routing number of this bank, the originating bank, whose first 8 digits start
+	// the trace numbers of entries
+	ODFI string
+	// EntryDescription tells receivers what the entries are for, such as PAYOUT
+	EntryDescription string
+}
+
+// ACH is a payment rail paying out to US accounts through NACHA files exchanged with an ACH partner
+// Submitted payouts are given the trace number and individual ID of their entry as their reference
+// and wait for CreateFile to put them in a file, each as a credit entry. The partner reports on the entries out of band: entries
+// it sends back arrive in return files read by ProcessReturns, and the entries of a file it
+// settled are marked settled by Settle. Both are reported through the callback set with OnOutcome.
+type ACH struct {
+	config ACHConfig
+	repo   storage.ACHRepository
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	callback Callback
+}
+
+// NewACH creates an ACH rail writing files as configured in cfg and keeping them in repo
+// Returns an error naming the first setting that does not fit the NACHA format.
+func NewACH(cfg ACHConfig, repo storage.ACHRepository, logger *slog.Logger) (*ACH, error) {
+	switch {
+	case !nacha.ValidRoutingNumber(cfg.Destination):
+		return nil, fmt.Errorf("invalid ACH destination %q: want a routing number", cfg.Destination)
+	case !nacha.ValidRoutingNumber(cfg.ODFI):
+		return nil, fmt.Errorf("invalid ACH ODFI %q: want a routing number", cfg.ODFI)
+	case cfg.Origin == "" || len(cfg.Origin) > 10:
+		return nil, fmt.Errorf("invalid ACH origin %q: want 1 to 10 characters", cfg.Origin)
+	case cfg.CompanyID == "" || len(cfg.CompanyID) > 10:
+		return nil, fmt.Errorf("invalid ACH company ID %q: want 1 to 10 characters", cfg.CompanyID)
+	}
+	return &ACH{
+		config: cfg,
+		repo:   repo,
+		logger: logger,
+	}, nil
+}
+
+// OnOutcome sets the callback outcomes are reported to
+func (a *ACH) OnOutcome(callback Callback) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.callback = callback
+}
+
+// Name returns ACHName
+func (a *ACH) Name() string {
+	return ACHName
+}
+
+// Submit accepts payouts to ABA routing and account numbers and returns the reference of their
+// entry; the entry is written by the next CreateFile
+func (a *ACH) Submit(ctx context.Context, payout *models.Payout) (string, error) {
+	if payout.Counterparty.Scheme != models.SchemeABA {
+		return "", fmt.Errorf("ACH pays out to %s accounts only, not %s", models.SchemeABA, payout.Counterparty.Scheme)
+	}
+
+	sequence, err := a.repo.NextSequence(ctx)
+	if err != nil {
+		return "", err
+	}
+	return entryReference(nacha.TraceNumber(a.config.ODFI[:8], sequence), individualID(payout)), nil
+}
+
+// CreateFile puts the payouts submitted since the previous file in a new file and returns it
+// Returns a nil file when no payout is waiting. The file is written by WriteFile.
+func (a *ACH) CreateFile(ctx context.Context) (*models.ACHFile, error) {
+	file, _, err := a.repo.CreateFile(ctx, ACHName, maxFileEntries)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		a.logger.InfoContext(ctx, "ACH file created", "file_id", file.ID, "entries", file.Entries, "amount", file.Amount)
+	}
+	return file, nil
+}
+
+// WriteFile writes the file with the given ID as a NACHA file, the same each time it is written
+// Returns ErrACHFileNotFound if there is no such file.
+func (a *ACH) WriteFile(ctx context.Context, w io.Writer, id string) error {
+	file, payouts, err := a.repo.GetFile(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	batch := nacha.Batch{
+		CompanyName:      a.config.CompanyName,
+		CompanyID:        a.config.CompanyID,
+		SECCode:          secCode,
+		EntryDescription: a.config.EntryDescription,
+		EffectiveDate:    file.CreatedAt.UTC().AddDate(0, 0, 1),
+		ODFI:             a.config.ODFI[:8],
+		Number:           1,
+	}
+	for _, payout := range payouts {
+		batch.Entries = append(batch.Entries, nacha.Entry{
+			TransactionCode: nacha.CheckingCredit,
+			RDFI:            payout.Counterparty.Identifier[:9],
+			AccountNumber:   payout.Counterparty.Identifier[9:],
+			Amount:          int64(math.Round(payout.Amount * 100)),
+			IndividualID:    individualID(payout),
+			IndividualName:  strings.ToUpper(payout.Counterparty.Name),
+			TraceNumber:     traceNumber(payout.RailReference),
+		})
+	}
+
+	return nacha.Write(w, &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination: a.config.Destination,
+			ImmediateOrigin:      a.config.Origin,
+			DestinationName:      a.config.DestinationName,
+			OriginName:           a.config.OriginName,
+			CreatedAt:            file.CreatedAt.UTC(),
+			IDModifier:           file.IDModifier[0],
+		},
+		Batches: []nacha.Batch{batch},
+	})
+}
+
+// Settle reports the payouts of the file with the given ID that are still submitted as settled,
+// once the ACH partner confirmed the settlement of the file, and returns how many it reported
+// Payouts returned meanwhile stay returned. Stops at the first outcome the callback does not
+// accept; settling a file again reports only the payouts not settled yet.
+func (a *ACH) Settle(ctx context.Context, id string) (*models.ACHSettlement, error) {
+	_, payouts, err := a.repo.GetFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	settlement := &models.ACHSettlement{FileID: id}
+	for _, payout := range payouts {
+		if payout.Status != models.PayoutStatusSubmitted {
+			continue
+		}
+		err := a.report(ctx, models.RailCallback{Reference: payout.RailReference, Status: models.PayoutStatusSettled})
+		if err != nil {
+			return settlement, fmt.Errorf("settling payout %s: %w", payout.ID, err)
+		}
+		settlement.Settled++
+	}
+
+	a.logger.InfoContext(ctx, "ACH file settled", "file_id", id, "settled", settlement.Settled)
+	return settlement, nil
+}
+
+// ProcessReturns reads a return file and reports each returned entry as a returned payout
+// Returned entries are matched by their original trace number and the individual ID they carry.
+// Every entry is reported, whether or not the callback accepts the ones before it, and the
+// returns are listed in order with the error of those not applied. Reading a file again
+// reports its returns again, which is harmless for returns already applied. Returns an error
+// wrapping ErrInvalidPaymentMessage when the file cannot be read.
+func (a *ACH) ProcessReturns(ctx context.Context, r io.Reader) ([]models.ACHReturn, error) {
+	file, err := nacha.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var returns []models.ACHReturn
+	for _, entry := range file.Returns() {
+		result := models.ACHReturn{
+			TraceNumber: entry.Return.OriginalTraceNumber,
+			Code:        entry.Return.Code,
+			Reason:      nacha.ReturnReason(entry.Return.Code),
+		}
+		err := a.report(ctx, models.RailCallback{
+			Reference: entryReference(result.TraceNumber, entry.IndividualID),
+			Status:    models.PayoutStatusReturned,
+			Reason:    result.Code + ": " + result.Reason,
+		})
+		if err != nil {
+			a.logger.WarnContext(ctx, "ACH return not applied",
+				"trace_number", result.TraceNumber, "code", result.Code, "error", err)
+			result.Error = err.Error()
+		}
+		returns = append(returns, result)
+	}
+	return returns, nil
+}
+
+// entryReference returns the rail reference of the entry with the given trace number and individual ID
+// Trace numbers start over after 9999999 entries, so the reference adds the individual ID, which
+// comes from the payout ID and is copied by receiving banks into the entries they return.
+func entryReference(traceNumber, individualID string) string {
+	return traceNumber + "-" + individualID
+}
+
+// traceNumber returns the trace number of the entry with the given rail reference
+func traceNumber(reference string) string {
+	trace, _, _ := strings.Cut(reference, "-")
+	return trace
+}
+
+// individualID returns the individual ID of the entry of payout: its ID without dashes, cut to
+// the 15 characters of the field
+func individualID(payout *models.Payout) string {
+	id := strings.ReplaceAll(payout.ID, "-", "")
+	return id[:min(len(id), individualIDLength)]
+}
+
+// report delivers callback to the callback set with OnOutcome
+func (a *ACH) report(ctx context.Context, callback models.RailCallback) error {
+	a.mu.Lock()
+	deliver := a.callback
+	a.mu.Unlock()
+	if deliver == nil {
+		return errors.New("no callback is set")
+	}
+	return deliver(ctx, ACHName, callback)
+}
+
+// FileName returns the name an ACH file is saved under, such as ACH-20240201-A.txt
+func FileName(file *models.ACHFile) string {
+	return fmt.Sprintf("ACH-%s-%s.txt", file.CreatedAt.UTC().Format("20060102"), file.IDModifier)
+}
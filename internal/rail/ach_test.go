@@ -0,0 +1,232 @@
+package rail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/nacha"
+	storagemocks "money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testACHConfig = ACHConfig{
+	Destination:      "021000021",
+	DestinationName:  "PARTNER BANK",
+	Origin:           "1234567890",
+	OriginName:       "MONEY TRANSFER",
+	CompanyName:      "MONEY TRANSFER",
+	CompanyID:        "1234567890",
+	ODFI:             "011000015",
+	EntryDescription: "PAYOUT",
+}
+
+// achFile returns a file of two payouts, the second already returned
+func achFile() (*models.ACHFile, []*models.Payout) {
+	file := &models.ACHFile{
+		ID:         "f-1",
+		IDModifier: "A",
+		Entries:    2,
+		Amount:     125.5,
+		CreatedAt:  time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC),
+	}
+	payouts := []*models.Payout{
+		{
+			ID:     "0b7c6a4e-8f1d-4c3a-9e2b-5d6f7a8b9c0d",
+			Amount: 100.5,
+			Counterparty: models.ExternalAccount{
+				Scheme:     models.SchemeABA,
+				Identifier: "021000021123456789",
+				Name:       "Mark Smith",
+			},
+			Rail:          ACHName,
+			Status:        models.PayoutStatusSubmitted,
+			RailReference: entryReference(nacha.TraceNumber("01100001", 1), "0b7c6a4e8f1d4c3"),
+		},
+		{
+			ID:     "1c8d7b5f-9a2e-4d4b-8f3c-6e7a8b9c0d1e",
+			Amount: 25,
+			Counterparty: models.ExternalAccount{
+				Scheme:     models.SchemeABA,
+				Identifier: "111000025987654321",
+				Name:       "Jane Doe",
+			},
+			Rail:          ACHName,
+			Status:        models.PayoutStatusReturned,
+			RailReference: entryReference(nacha.TraceNumber("01100001", 2), "1c8d7b5f9a2e4d4"),
+		},
+	}
+	return file, payouts
+}
+
+// achOutcomes collects the callbacks of an ACH rail, refusing those of the given reference
+func achOutcomes(a *ACH, refused string) *[]models.RailCallback {
+	var delivered []models.RailCallback
+	a.OnOutcome(func(_ context.Context, rail string, callback models.RailCallback) error {
+		if rail != ACHName {
+			return errors.New("unexpected rail " + rail)
+		}
+		if callback.Reference == refused {
+			return transfererrors.ErrPayoutNotFound
+		}
+		delivered = append(delivered, callback)
+		return nil
+	})
+	return &delivered
+}
+
+func TestNewACH(t *testing.T) {
+	cfg := testACHConfig
+	cfg.ODFI = "011000016"
+	_, err := NewACH(cfg, &storagemocks.ACHRepository{}, logging.Discard())
+	assert.ErrorContains(t, err, "invalid ACH ODFI")
+
+	cfg = testACHConfig
+	cfg.CompanyID = ""
+	_, err = NewACH(cfg, &storagemocks.ACHRepository{}, logging.Discard())
+	assert.ErrorContains(t, err, "invalid ACH company ID")
+}
+
+func TestACHSubmit(t *testing.T) {
+	repo := &storagemocks.ACHRepository{}
+	repo.On("NextSequence", mock.Anything).Return(42, nil).Once()
+	repo.On("NextSequence", mock.Anything).Return(42, nil).Once()
+	a, err := NewACH(testACHConfig, repo, logging.Discard())
+	require.NoError(t, err)
+
+	_, payouts := achFile()
+	reference, err := a.Submit(context.Background(), payouts[0])
+	require.NoError(t, err)
+	assert.Equal(t, "011000010000042-0b7c6a4e8f1d4c3", reference)
+
+	// Once trace numbers start over, another payout given the same trace number gets another reference
+	again, err := a.Submit(context.Background(), payouts[1])
+	require.NoError(t, err)
+	assert.Equal(t, "011000010000042-1c8d7b5f9a2e4d4", again)
+
+	iban := &models.Payout{Counterparty: models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "DE89370400440532013000"}}
+	_, err = a.Submit(context.Background(), iban)
+	assert.ErrorContains(t, err, "ACH pays out to aba accounts only")
+	repo.AssertNumberOfCalls(t, "NextSequence", 2)
+}
+
+func TestACHWriteFile(t *testing.T) {
+	file, payouts := achFile()
+	repo := &storagemocks.ACHRepository{}
+	repo.On("GetFile", mock.Anything, file.ID).Return(file, payouts, nil)
+	repo.On("GetFile", mock.Anything, "f-2").Return(nil, nil, transfererrors.ErrACHFileNotFound)
+	a, err := NewACH(testACHConfig, repo, logging.Discard())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, a.WriteFile(context.Background(), &out, file.ID))
+	parsed, err := nacha.Parse(&out)
+	require.NoError(t, err)
+
+	assert.Equal(t, "021000021", parsed.Header.ImmediateDestination)
+	assert.Equal(t, byte('A'), parsed.Header.IDModifier)
+	require.Len(t, parsed.Batches, 1)
+	batch := parsed.Batches[0]
+	assert.Equal(t, "PPD", batch.SECCode)
+	assert.Equal(t, "01100001", batch.ODFI)
+	assert.Equal(t, time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC), batch.EffectiveDate)
+	assert.Equal(t, nacha.Entry{
+		TransactionCode: nacha.CheckingCredit,
+		RDFI:            "021000021",
+		AccountNumber:   "123456789",
+		Amount:          10050,
+		IndividualID:    "0b7c6a4e8f1d4c3",
+		IndividualName:  "MARK SMITH",
+		TraceNumber:     "011000010000001",
+	}, batch.Entries[0])
+	assert.Len(t, batch.Entries, 2)
+
+	err = a.WriteFile(context.Background(), &out, "f-2")
+	assert.ErrorIs(t, err, transfererrors.ErrACHFileNotFound)
+}
+
+func TestACHSettle(t *testing.T) {
+	file, payouts := achFile()
+	repo := &storagemocks.ACHRepository{}
+	repo.On("GetFile", mock.Anything, file.ID).Return(file, payouts, nil)
+	a, err := NewACH(testACHConfig, repo, logging.Discard())
+	require.NoError(t, err)
+	delivered := achOutcomes(a, "")
+
+	settlement, err := a.Settle(context.Background(), file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &models.ACHSettlement{FileID: file.ID, Settled: 1}, settlement)
+	assert.Equal(t, []models.RailCallback{
+		{Reference: payouts[0].RailReference, Status: models.PayoutStatusSettled},
+	}, *delivered)
+}
+
+func TestACHProcessReturns(t *testing.T) {
+	_, payouts := achFile()
+	a, err := NewACH(testACHConfig, &storagemocks.ACHRepository{}, logging.Discard())
+	require.NoError(t, err)
+	unknown := nacha.TraceNumber("01100001", 9)
+	delivered := achOutcomes(a, entryReference(unknown, "0b7c6a4e8f1d4c3"))
+
+	returned := func(sequence int, code, original, individualID string) nacha.Entry {
+		return nacha.Entry{
+			TransactionCode: nacha.CheckingReturnCredit,
+			RDFI:            "011000015",
+			AccountNumber:   "123456789",
+			Amount:          10050,
+			IndividualID:    individualID,
+			IndividualName:  "MARK SMITH",
+			TraceNumber:     nacha.TraceNumber("02100002", sequence),
+			Return:          &nacha.Return{Code: code, OriginalTraceNumber: original, OriginalRDFI: "02100002"},
+		}
+	}
+	var returnFile bytes.Buffer
+	require.NoError(t, nacha.Write(&returnFile, &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination: "011000015",
+			ImmediateOrigin:      "021000021",
+			CreatedAt:            time.Date(2024, 2, 5, 6, 0, 0, 0, time.UTC),
+			IDModifier:           'A',
+		},
+		Batches: []nacha.Batch{{
+			CompanyName:   "MONEY TRANSFER",
+			CompanyID:     "1234567890",
+			SECCode:       "PPD",
+			EffectiveDate: time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+			ODFI:          "02100002",
+			Number:        1,
+			Entries: []nacha.Entry{
+				returned(1, "R03", unknown, "0b7c6a4e8f1d4c3"),
+				returned(2, "R01", "011000010000001", "0b7c6a4e8f1d4c3"),
+			},
+		}},
+	}))
+
+	returns, err := a.ProcessReturns(context.Background(), &returnFile)
+	require.NoError(t, err)
+	assert.Equal(t, []models.ACHReturn{
+		{
+			TraceNumber: unknown,
+			Code:        "R03",
+			Reason:      "No account or unable to locate account",
+			Error:       transfererrors.ErrPayoutNotFound.Error(),
+		},
+		{TraceNumber: "011000010000001", Code: "R01", Reason: "Insufficient funds"},
+	}, returns)
+	assert.Equal(t, []models.RailCallback{{
+		Reference: payouts[0].RailReference,
+		Status:    models.PayoutStatusReturned,
+		Reason:    "R01: Insufficient funds",
+	}}, *delivered)
+
+	_, err = a.ProcessReturns(context.Background(), bytes.NewBufferString("not a NACHA file"))
+	assert.ErrorIs(t, err, transfererrors.ErrInvalidPaymentMessage)
+}
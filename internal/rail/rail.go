@@ -0,0 +1,31 @@
+// Package rail defines the payment rails payouts to accounts held at other banks are sent through
+//
+// A rail accepts a payout, returning the reference it will report on the payout under, and later
+// reports whether the payout settled or came back. Outcomes arrive as callbacks, either on the
+// HTTP API or, for rails running in this process such as the Simulator and ACH, through a Callback.
+package rail
+
+import (
+	"context"
+
+	"money-transfer/internal/domain/models"
+)
+
+// PaymentRail sends payouts to accounts held at other banks
+type PaymentRail interface {
+	// Name is the name payouts select the rail by and callbacks are reported under
+	Name() string
+	// Submit hands the payout to the rail and returns the reference the rail assigned to it
+	// An error means the rail refused the payout, which will not be reported on.
+	Submit(ctx context.Context, payout *models.Payout) (string, error)
+}
+
+// Callback receives the outcome of a payout reported by the rail named rail
+type Callback func(ctx context.Context, rail string, callback models.RailCallback) error
+
+// LocalRail is a payment rail running in this process, which reports outcomes through a Callback
+type LocalRail interface {
+	PaymentRail
+	// OnOutcome sets the callback outcomes are reported to
+	OnOutcome(callback Callback)
+}
@@ -0,0 +1,223 @@
+package rail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"money-transfer/internal/domain/models"
+)
+
+// SimulatorName is the name of the simulator rail
+const SimulatorName = "simulator"
+
+// Mode is the outcome the simulator gives every payout
+type Mode string
+
+// Simulator modes
+const (
+	// ModeSettle settles every payout after the delay
+	ModeSettle Mode = "settle"
+	// ModeReturn returns every payout after the delay, as if the counterparty account was closed
+	ModeReturn Mode = "return"
+	// ModeReject refuses every payout when it is submitted
+	ModeReject Mode = "reject"
+	// ModeHold accepts every payout and never reports on it; outcomes are reported on the API instead
+	ModeHold Mode = "hold"
+)
+
+// IsValid reports whether m is a known mode
+func (m Mode) IsValid() bool {
+	switch m {
+	case ModeSettle, ModeReturn, ModeReject, ModeHold:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reasons the simulator gives for the payouts it does not settle
+const (
+	rejectReason = "simulated rejection"
+	returnReason = "simulated return: account closed"
+)
+
+// maxDeliveries is how often the simulator reports an outcome until the callback accepts it
+const maxDeliveries = 5
+
+// minRedeliveryDelay is the least time between two reports of an outcome
+const minRedeliveryDelay = 100 * time.Millisecond
+
+// Simulator is a payment rail that runs in this process, for development and tests
+// It gives every payout the outcome of its mode after a delay. Outcomes are reported through
+// the callback set with OnOutcome and reported again, like a real rail would, while the
+// callback returns an error, since it may run before the payout was recorded as submitted.
+// Simulator is a lifecycle.Worker: once the context it was started with is canceled, the
+// outcomes not reported yet are dropped and Wait returns when those being reported are.
+type Simulator struct {
+	mode   Mode
+	delay  time.Duration
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	callback Callback
+	ctx      context.Context
+	stopped  bool
+	timers   map[uint64]*time.Timer
+	next     uint64
+	wg       sync.WaitGroup
+}
+
+// NewSimulator creates a simulator rail giving payouts the outcome of mode after delay
+func NewSimulator(mode Mode, delay time.Duration, logger *slog.Logger) (*Simulator, error) {
+	if !mode.IsValid() {
+		return nil, fmt.Errorf("unknown simulator mode %q", mode)
+	}
+	return &Simulator{
+		mode:   mode,
+		delay:  delay,
+		logger: logger,
+		ctx:    context.Background(),
+		timers: make(map[uint64]*time.Timer),
+	}, nil
+}
+
+// Start reports outcomes with ctx until it is canceled, then drops the outcomes not reported yet
+func (s *Simulator) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.stop()
+	}()
+}
+
+// Wait blocks until the outcomes being reported are
+func (s *Simulator) Wait() {
+	s.wg.Wait()
+}
+
+// stop cancels the pending reports; reports already running finish
+func (s *Simulator) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	dropped := 0
+	for id, timer := range s.timers {
+		if timer.Stop() {
+			delete(s.timers, id)
+			s.wg.Done()
+			dropped++
+		}
+	}
+	if dropped > 0 {
+		s.logger.Warn("simulated payout outcomes dropped, the simulator stopped", "dropped", dropped)
+	}
+}
+
+// OnOutcome sets the callback outcomes are reported to
+func (s *Simulator) OnOutcome(callback Callback) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callback = callback
+}
+
+// Name returns SimulatorName
+func (s *Simulator) Name() string {
+	return SimulatorName
+}
+
+// Submit accepts the payout unless the simulator rejects every payout, and schedules its outcome
+func (s *Simulator) Submit(_ context.Context, _ *models.Payout) (string, error) {
+	if s.mode == ModeReject {
+		return "", errors.New(rejectReason)
+	}
+
+	reference, err := newReference()
+	if err != nil {
+		return "", err
+	}
+
+	var callback models.RailCallback
+	switch s.mode {
+	case ModeSettle:
+		callback = models.RailCallback{Reference: reference, Status: models.PayoutStatusSettled}
+	case ModeReturn:
+		callback = models.RailCallback{Reference: reference, Status: models.PayoutStatusReturned, Reason: returnReason}
+	default:
+		return reference, nil
+	}
+	s.schedule(s.delay, callback, 1)
+
+	return reference, nil
+}
+
+// schedule reports callback after delay, unless the simulator stops first
+func (s *Simulator) schedule(delay time.Duration, callback models.RailCallback, delivery int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		s.logger.Warn("simulated payout outcome dropped, the simulator stopped", "reference", callback.Reference)
+		return
+	}
+
+	id := s.next
+	s.next++
+	s.wg.Add(1)
+	// The timer cannot fire before it is recorded, since it takes s.mu first
+	s.timers[id] = time.AfterFunc(delay, func() {
+		s.mu.Lock()
+		delete(s.timers, id)
+		stopped, ctx := s.stopped, s.ctx
+		s.mu.Unlock()
+		defer s.wg.Done()
+
+		if stopped {
+			s.logger.Warn("simulated payout outcome dropped, the simulator stopped", "reference", callback.Reference)
+			return
+		}
+		s.report(context.WithoutCancel(ctx), callback, delivery)
+	})
+}
+
+// report delivers callback, scheduling another delivery if it is not accepted
+// Deliveries run to completion when the simulator stops, so ctx is not canceled with it.
+func (s *Simulator) report(ctx context.Context, callback models.RailCallback, delivery int) {
+	s.mu.Lock()
+	deliver := s.callback
+	s.mu.Unlock()
+	if deliver == nil {
+		s.logger.Warn("simulated payout outcome dropped, no callback is set", "reference", callback.Reference)
+		return
+	}
+
+	err := deliver(ctx, SimulatorName, callback)
+	if err == nil {
+		return
+	}
+	if delivery == maxDeliveries {
+		s.logger.Error("simulated payout outcome not accepted, giving up",
+			"reference", callback.Reference, "status", callback.Status, "error", err)
+		return
+	}
+	s.logger.Warn("simulated payout outcome not accepted, reporting again",
+		"reference", callback.Reference, "delivery", delivery, "error", err)
+	s.schedule(max(s.delay, minRedeliveryDelay), callback, delivery+1)
+}
+
+// newReference returns a random reference prefixed with SIM
+func newReference() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "SIM" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,161 @@
+package rail
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outcomes collects the callbacks of a simulator, refusing the first refusals of them
+func outcomes(s *Simulator, refusals int) <-chan models.RailCallback {
+	delivered := make(chan models.RailCallback, 10)
+	s.OnOutcome(func(_ context.Context, rail string, callback models.RailCallback) error {
+		if rail != SimulatorName {
+			return errors.New("unexpected rail " + rail)
+		}
+		if refusals > 0 {
+			refusals--
+			return errors.New("payout not recorded yet")
+		}
+		delivered <- callback
+		return nil
+	})
+	return delivered
+}
+
+func TestSimulator(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		refusals int
+		want     *models.RailCallback
+		wantErr  string
+	}{
+		{name: "settle", mode: ModeSettle, want: &models.RailCallback{Status: models.PayoutStatusSettled}},
+		{
+			name: "return",
+			mode: ModeReturn,
+			want: &models.RailCallback{Status: models.PayoutStatusReturned, Reason: returnReason},
+		},
+		{
+			name:     "redelivered until accepted",
+			mode:     ModeSettle,
+			refusals: 2,
+			want:     &models.RailCallback{Status: models.PayoutStatusSettled},
+		},
+		{name: "reject", mode: ModeReject, wantErr: rejectReason},
+		{name: "hold", mode: ModeHold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSimulator(tt.mode, 0, logging.Discard())
+			require.NoError(t, err)
+			delivered := outcomes(s, tt.refusals)
+
+			reference, err := s.Submit(context.Background(), &models.Payout{ID: "p-1"})
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Regexp(t, "^SIM[0-9a-f]{16}$", reference)
+
+			if tt.want == nil {
+				select {
+				case callback := <-delivered:
+					t.Fatalf("unexpected outcome %+v", callback)
+				case <-time.After(50 * time.Millisecond):
+				}
+				return
+			}
+
+			want := *tt.want
+			want.Reference = reference
+			select {
+			case callback := <-delivered:
+				assert.Equal(t, want, callback)
+			case <-time.After(time.Second):
+				t.Fatal("no outcome reported")
+			}
+		})
+	}
+}
+
+func TestNewSimulatorUnknownMode(t *testing.T) {
+	_, err := NewSimulator("bounce", time.Second, logging.Discard())
+	assert.EqualError(t, err, `unknown simulator mode "bounce"`)
+}
+
+func TestSimulatorStop(t *testing.T) {
+	s, err := NewSimulator(ModeSettle, 50*time.Millisecond, logging.Discard())
+	require.NoError(t, err)
+	delivered := outcomes(s, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	_, err = s.Submit(context.Background(), &models.Payout{ID: "p-1"})
+	require.NoError(t, err)
+	cancel()
+
+	waited := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return")
+	}
+
+	select {
+	case callback := <-delivered:
+		t.Fatalf("outcome %+v reported after stopping", callback)
+	case <-time.After(100 * time.Millisecond):
+	}
+	_, err = s.Submit(context.Background(), &models.Payout{ID: "p-2"})
+	require.NoError(t, err, "payouts are still accepted, their outcomes reported on the API")
+}
+
+func TestSimulatorWaitsForDelivery(t *testing.T) {
+	s, err := NewSimulator(ModeSettle, 0, logging.Discard())
+	require.NoError(t, err)
+	delivering, release := make(chan struct{}), make(chan struct{})
+	var deliveryErr error
+	s.OnOutcome(func(ctx context.Context, _ string, _ models.RailCallback) error {
+		close(delivering)
+		<-release
+		deliveryErr = ctx.Err()
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+
+	_, err = s.Submit(context.Background(), &models.Payout{ID: "p-1"})
+	require.NoError(t, err)
+	<-delivering
+	cancel()
+
+	waited := make(chan struct{})
+	go func() {
+		s.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+		t.Fatal("Wait returned while an outcome was being reported")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	<-waited
+	assert.NoError(t, deliveryErr, "deliveries under way are not canceled")
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Memory keeps token buckets in process memory
+// Each replica limits on its own, so the effective limit grows with the number of replicas.
+type Memory struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+// bucket holds the tokens left at the last request
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// NewMemory creates an empty in-memory limiter
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Allow takes a token from the bucket identified by key
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Count)}
+		m.buckets[key] = b
+	} else {
+		b.tokens = limit.Refill(b.tokens, now.Sub(b.updated))
+	}
+	b.updated = now
+	b.limit = limit
+
+	if b.tokens < 1 {
+		return NewResult(limit, b.tokens, false), nil
+	}
+	b.tokens--
+	return NewResult(limit, b.tokens, true), nil
+}
+
+// Sweep drops the buckets that have refilled
+func (m *Memory) Sweep(_ context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	swept := 0
+	for key, b := range m.buckets {
+		if b.limit.Refill(b.tokens, now.Sub(b.updated)) >= float64(b.limit.Count) {
+			delete(m.buckets, key)
+			swept++
+		}
+	}
+	return swept, nil
+}
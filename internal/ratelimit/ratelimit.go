@@ -0,0 +1,72 @@
+// Package ratelimit limits how often callers may use the API with token buckets
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limit allows Count requests per Period; a bucket holds up to Count tokens and refills
+// them evenly over Period, so a caller idle for a whole period may send Count at once
+type Limit struct {
+	Count  int
+	Period time.Duration
+}
+
+// Rate returns how many tokens the bucket regains per second
+func (l Limit) Rate() float64 {
+	return float64(l.Count) / l.Period.Seconds()
+}
+
+// Refill returns the tokens of a bucket holding tokens after elapsed has passed
+func (l Limit) Refill(tokens float64, elapsed time.Duration) float64 {
+	return math.Min(float64(l.Count), tokens+elapsed.Seconds()*l.Rate())
+}
+
+// String formats the limit as in configuration, such as 60/1m0s
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Count, l.Period)
+}
+
+// Result is the outcome of taking a token from a bucket
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is how many whole tokens are left in the bucket
+	Remaining int
+	// RetryAfter is how long until the next token is available; zero when allowed
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// NewResult describes a bucket left with tokens after a request was allowed or denied
+func NewResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(limit.Count) - tokens) / limit.Rate()),
+	}
+	if !allowed {
+		result.RetryAfter = seconds((1 - tokens) / limit.Rate())
+	}
+	return result
+}
+
+// seconds converts a number of seconds to a duration
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(0, s) * float64(time.Second))
+}
+
+// Limiter keeps the token buckets of the callers being limited
+type Limiter interface {
+	// Allow takes a token from the bucket identified by key, which starts full
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+
+	// Sweep drops the buckets that have refilled, which behave as buckets never used,
+	// and returns how many were dropped
+	Sweep(ctx context.Context) (int, error)
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemory_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemory()
+	limiter.now = func() time.Time { return now }
+	limit := Limit{Count: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	// A new bucket allows a burst of Count requests
+	for _, wantRemaining := range []int{1, 0} {
+		result, err := limiter.Allow(ctx, "ip=10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, wantRemaining, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "ip=10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 30*time.Second, result.RetryAfter)
+	assert.Equal(t, time.Minute, result.Reset)
+
+	// Other keys have their own bucket
+	result, err = limiter.Allow(ctx, "ip=10.0.0.2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// One token is regained every Period/Count
+	now = now.Add(30 * time.Second)
+	result, err = limiter.Allow(ctx, "ip=10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+}
+
+func TestMemory_Sweep(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemory()
+	limiter.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := limiter.Allow(ctx, "short", Limit{Count: 10, Period: time.Second})
+	require.NoError(t, err)
+	_, err = limiter.Allow(ctx, "long", Limit{Count: 10, Period: time.Hour})
+	require.NoError(t, err)
+
+	now = now.Add(time.Second)
+	swept, err := limiter.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, swept)
+	assert.Contains(t, limiter.buckets, "long")
+	assert.NotContains(t, limiter.buckets, "short")
+}
+
+func TestParseRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		spec       string
+		method     string
+		path       string
+		wantRoute  string
+		wantLimits []KeyLimit
+		wantErr    bool
+	}{
+		{
+			name:      "route rule",
+			spec:      "POST /api/v1/transfer principal=60/m account=5/30s; * ip=600/m",
+			method:    "POST",
+			path:      "/api/v1/transfer",
+			wantRoute: "POST /api/v1/transfer",
+			wantLimits: []KeyLimit{
+				{Key: KeyPrincipal, Limit: Limit{Count: 60, Period: time.Minute}},
+				{Key: KeyAccount, Limit: Limit{Count: 5, Period: 30 * time.Second}},
+			},
+		},
+		{
+			name:       "fallback rule",
+			spec:       "post /api/v1/transfer principal=60/m; * ip=600/m",
+			method:     "GET",
+			path:       "/api/v1/balance/:account",
+			wantRoute:  "*",
+			wantLimits: []KeyLimit{{Key: KeyIP, Limit: Limit{Count: 600, Period: time.Minute}}},
+		},
+		{
+			name:      "no rule",
+			spec:      "POST /api/v1/transfer ip=1/h",
+			method:    "GET",
+			path:      "/healthz",
+			wantRoute: "*",
+		},
+		{
+			name:      "empty spec",
+			spec:      "",
+			method:    "GET",
+			path:      "/healthz",
+			wantRoute: "*",
+		},
+		{name: "unknown key", spec: "* user=10/m", wantErr: true},
+		{name: "missing path", spec: "POST principal=10/m", wantErr: true},
+		{name: "no limits", spec: "POST /api/v1/transfer", wantErr: true},
+		{name: "zero count", spec: "* ip=0/m", wantErr: true},
+		{name: "invalid period", spec: "* ip=10/week", wantErr: true},
+		{name: "duplicate route", spec: "* ip=10/m; * ip=20/m", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseRules(tt.spec)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			route, limits := rules.For(tt.method, tt.path)
+			assert.Equal(t, tt.wantRoute, route)
+			assert.Equal(t, tt.wantLimits, limits)
+		})
+	}
+}
+
+func TestThrottle_Allow(t *testing.T) {
+	rules, err := ParseRules("POST /api/v1/transfer principal=3/m account=1/m; * ip=1/m")
+	require.NoError(t, err)
+	throttle := NewThrottle(NewMemory(), rules, "POST", "/api/v1/transfer")
+	ctx := auth.WithClientIP(auth.WithPrincipal(context.Background(), &models.Principal{ID: "mark"}), "10.0.0.1")
+
+	// The route's limits apply, not those of *, and each account has its own bucket
+	require.NoError(t, throttle.Allow(ctx, "Mark"))
+	require.NoError(t, throttle.Allow(ctx, "Savings"))
+
+	err = throttle.Allow(ctx, "Mark")
+	require.ErrorIs(t, err, transfererrors.ErrRateLimited)
+	var exceeded *ExceededError
+	require.ErrorAs(t, err, &exceeded)
+	assert.Equal(t, Limit{Count: 1, Period: time.Minute}, exceeded.Result.Limit)
+	assert.Equal(t, "rate limit of 1/1m0s exceeded, retry in 60s", err.Error())
+
+	// The principal's bucket is shared across accounts
+	require.ErrorIs(t, throttle.Allow(ctx, "Holiday"), transfererrors.ErrRateLimited)
+
+	// Without a limiter nothing is limited
+	throttle = NewThrottle(nil, rules, "POST", "/api/v1/transfer")
+	assert.Nil(t, throttle)
+	assert.NoError(t, throttle.Allow(ctx, "Mark"))
+}
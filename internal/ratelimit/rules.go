@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key names what a limit counts requests by
+type Key string
+
+// Keys requests are counted by
+const (
+	// KeyPrincipal counts the requests of an authenticated caller
+	KeyPrincipal Key = "principal"
+	// KeyIP counts the requests from a client IP address
+	KeyIP Key = "ip"
+	// KeyAccount counts the requests acting on an account, such as transfers from it
+	KeyAccount Key = "account"
+)
+
+// anyRoute is the route of the rule applied to routes without one of their own
+const anyRoute = "*"
+
+// KeyLimit limits the requests sharing a key
+type KeyLimit struct {
+	Key   Key
+	Limit Limit
+}
+
+// Rules holds the limits of each route
+type Rules struct {
+	routes map[string][]KeyLimit
+}
+
+// ParseRules builds rules from a semicolon-separated list of routes, each followed by
+// key=count/period limits separated by spaces, for example
+// "POST /api/v1/transfer principal=60/m ip=120/m; * ip=600/m"
+// A route is a method and a gin path template, or * for every route without a rule of its own.
+// Periods are s, m, h or a Go duration such as 30s.
+func ParseRules(spec string) (*Rules, error) {
+	rules := &Rules{routes: make(map[string][]KeyLimit)}
+
+	for i, entry := range strings.Split(spec, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+
+		route, limitFields := fields[0], fields[1:]
+		if route != anyRoute {
+			if len(fields) < 2 || !strings.HasPrefix(fields[1], "/") {
+				return nil, fmt.Errorf("rate limit rule %d: route must be * or a method and a path", i+1)
+			}
+			route, limitFields = strings.ToUpper(fields[0])+" "+fields[1], fields[2:]
+		}
+		if _, ok := rules.routes[route]; ok {
+			return nil, fmt.Errorf("rate limit rule %d: duplicate route %s", i+1, route)
+		}
+		if len(limitFields) == 0 {
+			return nil, fmt.Errorf("rate limit rule %d: no limits for %s", i+1, route)
+		}
+
+		limits := make([]KeyLimit, 0, len(limitFields))
+		for _, field := range limitFields {
+			limit, err := parseKeyLimit(field)
+			if err != nil {
+				return nil, fmt.Errorf("rate limit rule %d: %w", i+1, err)
+			}
+			limits = append(limits, limit)
+		}
+		rules.routes[route] = limits
+	}
+
+	return rules, nil
+}
+
+// parseKeyLimit parses a key=count/period limit
+func parseKeyLimit(field string) (KeyLimit, error) {
+	name, value, ok := strings.Cut(field, "=")
+	if !ok {
+		return KeyLimit{}, fmt.Errorf("limit %q must be key=count/period", field)
+	}
+
+	key := Key(name)
+	if key != KeyPrincipal && key != KeyIP && key != KeyAccount {
+		return KeyLimit{}, fmt.Errorf("unknown key %q, want principal, ip or account", name)
+	}
+
+	countText, periodText, ok := strings.Cut(value, "/")
+	if !ok {
+		return KeyLimit{}, fmt.Errorf("limit %q must be key=count/period", field)
+	}
+	count, err := strconv.Atoi(countText)
+	if err != nil || count < 1 {
+		return KeyLimit{}, fmt.Errorf("count of %q must be a positive integer", field)
+	}
+	if periodText == "s" || periodText == "m" || periodText == "h" {
+		periodText = "1" + periodText
+	}
+	period, err := time.ParseDuration(periodText)
+	if err != nil || period <= 0 {
+		return KeyLimit{}, fmt.Errorf("period of %q must be s, m, h or a positive duration", field)
+	}
+
+	return KeyLimit{Key: key, Limit: Limit{Count: count, Period: period}}, nil
+}
+
+// For returns the limits of the route matched by method and path, with the route they are
+// counted under; routes without a rule share the limits of the * rule, if any
+func (r *Rules) For(method, path string) (string, []KeyLimit) {
+	route := method + " " + path
+	if limits, ok := r.routes[route]; ok {
+		return route, limits
+	}
+	return anyRoute, r.routes[anyRoute]
+}
@@ -0,0 +1,61 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sweeper periodically drops the buckets of a Limiter that have refilled, so that
+// callers seen once do not hold memory or rows forever
+type Sweeper struct {
+	limiter  Limiter
+	interval time.Duration
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+// NewSweeper creates a sweeper running every interval and logging failures to logger
+func NewSweeper(limiter Limiter, interval time.Duration, logger *slog.Logger) *Sweeper {
+	return &Sweeper{
+		limiter:  limiter,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start launches the sweep loop; it stops once ctx is canceled
+func (s *Sweeper) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Wait blocks until the sweep loop has returned
+func (s *Sweeper) Wait() {
+	s.wg.Wait()
+}
+
+// run sweeps every interval until ctx is canceled
+func (s *Sweeper) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		swept, err := s.limiter.Sweep(ctx)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "rate limit sweep failed", "error", err)
+			continue
+		}
+		s.logger.DebugContext(ctx, "swept rate limit buckets", "swept", swept)
+	}
+}
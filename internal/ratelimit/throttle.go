@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+)
+
+// Take takes a token from the bucket of each limit counted under route, identified by the value
+// identify returns for its key, and returns the most constrained result
+// Limits whose key identify returns "" for do not apply. When limiter fails the limit is
+// skipped, so that an outage of the limiter does not take the API down; ok is false when no
+// limit was counted. Taking stops at the first limit exceeded.
+func Take(
+	ctx context.Context, limiter Limiter, route string, limits []KeyLimit, identify func(Key) string,
+) (tightest Result, ok bool) {
+	for _, kl := range limits {
+		id := identify(kl.Key)
+		if id == "" {
+			continue
+		}
+
+		result, err := limiter.Allow(ctx, route+" "+string(kl.Key)+"="+id, kl.Limit)
+		if err != nil {
+			logging.FromContext(ctx).WarnContext(ctx, "rate limiter failed, letting request through",
+				"key", kl.Key, "error", err)
+			continue
+		}
+		if !ok || !result.Allowed || result.Remaining < tightest.Remaining {
+			tightest, ok = result, true
+		}
+		if !result.Allowed {
+			break
+		}
+	}
+	return tightest, ok
+}
+
+// ExceededError is returned by a Throttle for a request over one of its limits
+type ExceededError struct {
+	Result Result
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("rate limit of %s exceeded, retry in %ds", e.Result.Limit, CeilSeconds(e.Result.RetryAfter))
+}
+
+// Unwrap reports the error as ErrRateLimited
+func (e *ExceededError) Unwrap() error {
+	return transfererrors.ErrRateLimited
+}
+
+// Throttle counts requests served outside the route they are limited by against the buckets
+// of that route, such as transfers made over gRPC, GraphQL or WebSocket against those of the
+// REST transfer route, so that callers have one budget whichever API they use
+// A nil Throttle limits nothing.
+type Throttle struct {
+	limiter Limiter
+	route   string
+	limits  []KeyLimit
+}
+
+// NewThrottle creates a throttle applying the limits of the route matched by method and path;
+// it returns nil when limiter is nil
+func NewThrottle(limiter Limiter, rules *Rules, method, path string) *Throttle {
+	if limiter == nil {
+		return nil
+	}
+	route, limits := rules.For(method, path)
+	return &Throttle{limiter: limiter, route: route, limits: limits}
+}
+
+// Allow takes a token for a request acting on account by the principal and from the client IP
+// stored in ctx, and returns an *ExceededError if a limit is exceeded
+func (t *Throttle) Allow(ctx context.Context, account string) error {
+	if t == nil {
+		return nil
+	}
+
+	result, ok := Take(ctx, t.limiter, t.route, t.limits, func(key Key) string {
+		switch key {
+		case KeyIP:
+			return auth.ClientIPFromContext(ctx)
+		case KeyPrincipal:
+			if principal, ok := auth.PrincipalFromContext(ctx); ok {
+				return principal.ID
+			}
+			return ""
+		case KeyAccount:
+			return account
+		default:
+			return ""
+		}
+	})
+	if ok && !result.Allowed {
+		return &ExceededError{Result: result}
+	}
+	return nil
+}
+
+// CeilSeconds rounds d up to whole seconds, as durations are reported to clients
+func CeilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,132 @@
+// Package screening screens the parties of transfers against a sanctions list
+package screening
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// sdnNull is how the OFAC SDN files mark an empty field
+const sdnNull = "-0-"
+
+// Columns of the OFAC SDN file sdn.csv; it has no header row
+const (
+	sdnColumnID      = 0
+	sdnColumnName    = 1
+	sdnColumnType    = 2
+	sdnColumnProgram = 3
+	sdnColumnRemarks = 11
+)
+
+// aliasPattern finds the aliases given in the remarks of an entry, as in a.k.a. 'AERO-CARIBBEAN'
+var aliasPattern = regexp.MustCompile(`[af]\.k\.a\. '([^']+)'`)
+
+// Entry is a sanctioned party of the list
+type Entry struct {
+	ID      string   // Entry number, unique within the list
+	Names   []string // Name the party is listed under, followed by its aliases
+	Type    string   // individual, vessel or aircraft; empty for entities
+	Program string   // Sanctions programs listing the party, such as SDGT
+	// tokens holds the normalized tokens of each name
+	tokens [][][]rune
+}
+
+// List is a sanctions list loaded from a file
+type List struct {
+	entries []Entry
+	// Version identifies the content of the file the list was loaded from
+	Version string
+}
+
+// Len returns the number of listed parties
+func (l *List) Len() int {
+	return len(l.entries)
+}
+
+// ParseSDN reads a list in the CSV format of the OFAC Specially Designated Nationals list (sdn.csv)
+// Rows that do not start with an entry number, such as a header or the end-of-file marker of
+// the published file, are skipped. Aliases are taken from the a.k.a. and f.k.a. remarks.
+func ParseSDN(r io.Reader) (*List, error) {
+	hash := sha256.New()
+	reader := csv.NewReader(io.TeeReader(r, hash))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	list := &List{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sdn list: %w", err)
+		}
+
+		entry, ok := parseEntry(record)
+		if ok {
+			list.entries = append(list.entries, entry)
+		}
+	}
+	if len(list.entries) == 0 {
+		return nil, errors.New("sdn list has no entries")
+	}
+
+	list.Version = hex.EncodeToString(hash.Sum(nil))[:12]
+	return list, nil
+}
+
+// parseEntry reads an entry from a row of sdn.csv; ok is false for rows that hold none
+func parseEntry(record []string) (_ Entry, ok bool) {
+	if len(record) <= sdnColumnName || !isNumber(strings.TrimSpace(record[sdnColumnID])) {
+		return Entry{}, false
+	}
+	name := field(record, sdnColumnName)
+	if name == "" {
+		return Entry{}, false
+	}
+
+	entry := Entry{
+		ID:      strings.TrimSpace(record[sdnColumnID]),
+		Names:   []string{name},
+		Type:    field(record, sdnColumnType),
+		Program: field(record, sdnColumnProgram),
+	}
+	for _, match := range aliasPattern.FindAllStringSubmatch(field(record, sdnColumnRemarks), -1) {
+		entry.Names = append(entry.Names, match[1])
+	}
+	for _, name := range entry.Names {
+		entry.tokens = append(entry.tokens, tokenize(name))
+	}
+	return entry, true
+}
+
+// field returns a column of record without surrounding spaces, or empty when it is missing or null
+func field(record []string, column int) string {
+	if column >= len(record) {
+		return ""
+	}
+	value := strings.TrimSpace(record[column])
+	if value == sdnNull {
+		return ""
+	}
+	return value
+}
+
+func isNumber(value string) bool {
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
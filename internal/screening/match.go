@@ -0,0 +1,141 @@
+package screening
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Names are compared by their first maxWords words, and words by their first maxWordLength letters
+const (
+	maxWords      = 16
+	maxWordLength = 32
+)
+
+// tokenThreshold is how alike two tokens must be to count as the same word, allowing for
+// transliteration variants such as Mohammed and Muhammad
+const tokenThreshold = 0.85
+
+// noiseWords are left out of names: legal forms and connectives that many listed names share,
+// and the Arabic article, which is written joined, hyphenated or not at all
+var noiseWords = map[string]bool{
+	"al": true, "el": true, "the": true, "of": true, "and": true,
+	"co": true, "company": true, "corp": true, "corporation": true, "inc": true,
+	"llc": true, "ltd": true, "limited": true, "sa": true, "gmbh": true,
+}
+
+// tokenize normalizes a name into its words
+// Accents are removed, letters lower-cased, apostrophes dropped and any other punctuation
+// separates words, so that "AL-ZAWAHIRI, Ayman" becomes zawahiri and ayman.
+func tokenize(name string) [][]rune {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r), r == '\'', r == '’':
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	var tokens [][]rune
+	for _, word := range strings.Fields(b.String()) {
+		if noiseWords[word] {
+			continue
+		}
+		if len(tokens) == maxWords {
+			break
+		}
+		token := []rune(word)
+		tokens = append(tokens, token[:min(len(token), maxWordLength)])
+	}
+	return tokens
+}
+
+// score rates how alike two tokenized names of at most maxWords words are, from 0 for nothing alike to 1 for the same words
+// Each word of the shorter name is paired with the most similar word of the longer one that is
+// not paired yet. The score is the Dice coefficient of the pairs weighted by their similarity,
+// so word order does not matter but every word one name lacks lowers it.
+func score(a, b [][]rune) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	var paired [maxWords]bool
+	var sum float64
+	for _, word := range a {
+		best, at := 0.0, -1
+		for i, other := range b {
+			if paired[i] {
+				continue
+			}
+			if similarity := jaroWinkler(word, other); similarity > best {
+				best, at = similarity, i
+			}
+		}
+		if best >= tokenThreshold {
+			paired[at] = true
+			sum += best
+		}
+	}
+	return 2 * sum / float64(len(a)+len(b))
+}
+
+// maxScore is the highest score names of na and nb words can reach
+func maxScore(na, nb int) float64 {
+	return 2 * float64(min(na, nb)) / float64(na+nb)
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two words of at most maxWordLength letters,
+// from 0 to 1
+func jaroWinkler(a, b []rune) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	window = max(window, 0)
+	var matchedA, matchedB [maxWordLength]bool
+
+	matches := 0
+	for i, r := range a {
+		for j := max(0, i-window); j <= min(len(b)-1, i+window); j++ {
+			if !matchedB[j] && b[j] == r {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(a), len(b)) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,65 @@
+package screening
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func words(tokens [][]rune) []string {
+	result := make([]string, len(tokens))
+	for i, token := range tokens {
+		result[i] = string(token)
+	}
+	return result
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{name: "AL-ZAWAHIRI, Ayman", want: []string{"zawahiri", "ayman"}},
+		{name: "MÜLLER, Jürgen", want: []string{"muller", "jurgen"}},
+		{name: "O'Brien  Trading Co., Ltd.", want: []string{"obrien", "trading"}},
+		{name: "Société Générale S.A.", want: []string{"societe", "generale", "s", "a"}},
+		{name: "-", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, words(tokenize(tt.name)))
+		})
+	}
+}
+
+func TestJaroWinkler(t *testing.T) {
+	assert.Equal(t, 1.0, jaroWinkler([]rune("ivan"), []rune("ivan")))
+	assert.InDelta(t, 0.961, jaroWinkler([]rune("martha"), []rune("marhta")), 0.001)
+	assert.InDelta(t, 0.840, jaroWinkler([]rune("dwayne"), []rune("duane")), 0.001)
+	assert.Equal(t, 0.0, jaroWinkler([]rune("abc"), []rune("xyz")))
+}
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		least float64
+		most  float64
+	}{
+		{name: "same name in another order", a: "Ivan Sergeyevich Petrov", b: "PETROV, Ivan Sergeyevich", least: 1, most: 1},
+		{name: "transliteration", a: "Muhammad Al Rashid", b: "RASHID, Mohammed", least: 0.9, most: 0.99},
+		{name: "missing middle name", a: "Ivan Petrov", b: "PETROV, Ivan Sergeyevich", least: 0.75, most: 0.85},
+		{name: "shared first name", a: "Ivan Smith", b: "PETROV, Ivan Sergeyevich", least: 0.35, most: 0.45},
+		{name: "unrelated", a: "Jane Doe", b: "PETROV, Ivan Sergeyevich", least: 0, most: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := score(tokenize(tt.a), tokenize(tt.b))
+			assert.GreaterOrEqual(t, got, tt.least)
+			assert.LessOrEqual(t, got, tt.most)
+			assert.Equal(t, got, score(tokenize(tt.b), tokenize(tt.a)))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package screening
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"money-transfer/internal/domain/models"
+)
+
+// Config sets how closely a party must match a listed name to stop a transfer
+type Config struct {
+	// HoldScore is the score from which transfers are held for review
+	HoldScore float64
+	// BlockScore is the score from which transfers are blocked
+	BlockScore float64
+	// ReloadInterval is how often the list file is checked for changes
+	ReloadInterval time.Duration
+}
+
+// Screener screens the parties of transfers against a sanctions list read from an OFAC SDN CSV file
+// Once started, it reads the file again whenever it changes, so the list is updated without a
+// restart. A changed file that cannot be read is logged and the previous list kept.
+type Screener struct {
+	path   string
+	config Config
+	logger *slog.Logger
+	list   atomic.Pointer[List]
+
+	// mu serializes reloads and guards the state of the file last read
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+
+	wg sync.WaitGroup
+}
+
+// NewScreener creates a screener and loads the list from the file at path
+func NewScreener(path string, cfg Config, logger *slog.Logger) (*Screener, error) {
+	if cfg.HoldScore <= 0 || cfg.HoldScore > cfg.BlockScore || cfg.BlockScore > 1 {
+		return nil, fmt.Errorf("screening scores must satisfy 0 < hold (%g) <= block (%g) <= 1", cfg.HoldScore, cfg.BlockScore)
+	}
+
+	s := &Screener{path: path, config: cfg, logger: logger}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// List returns the list transfers are currently screened against
+func (s *Screener) List() *List {
+	return s.list.Load()
+}
+
+// Screen matches the names of the parties of a transfer against the list
+// The decision reports the closest match of any party; it holds the transfer when the match
+// scores at least the hold score and blocks it from the block score.
+func (s *Screener) Screen(parties ...string) models.ScreeningDecision {
+	list := s.list.Load()
+	decision := models.ScreeningDecision{Outcome: models.ScreeningClear, ListVersion: list.Version}
+
+	for _, party := range parties {
+		query := tokenize(party)
+		if len(query) == 0 {
+			continue
+		}
+		for i := range list.entries {
+			entry := &list.entries[i]
+			for n, name := range entry.tokens {
+				if maxScore(len(query), len(name)) <= decision.Score {
+					continue
+				}
+				if matched := score(query, name); matched > decision.Score {
+					decision.Score = matched
+					decision.Party = party
+					decision.MatchID = entry.ID
+					decision.MatchName = entry.Names[n]
+					decision.MatchProgram = entry.Program
+				}
+			}
+		}
+	}
+
+	switch {
+	case decision.Score >= s.config.BlockScore:
+		decision.Outcome = models.ScreeningBlock
+	case decision.Score >= s.config.HoldScore:
+		decision.Outcome = models.ScreeningHold
+	}
+	return decision
+}
+
+// Reload reads the list file again if it changed since it was last read and reports whether it did
+func (s *Screener) Reload() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return false, err
+	}
+	if s.list.Load() != nil && info.ModTime().Equal(s.modTime) && info.Size() == s.size {
+		return false, nil
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	list, err := ParseSDN(file)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", s.path, err)
+	}
+
+	s.list.Store(list)
+	s.modTime, s.size = info.ModTime(), info.Size()
+	s.logger.Info("sanctions list loaded", "path", s.path, "entries", list.Len(), "version", list.Version)
+	return true, nil
+}
+
+// Start launches the loop reloading the list when its file changes; it stops once ctx is canceled
+func (s *Screener) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Wait blocks until the reload loop has returned
+func (s *Screener) Wait() {
+	s.wg.Wait()
+}
+
+// run checks the list file for changes every reload interval until ctx is canceled
+func (s *Screener) run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := s.Reload(); err != nil {
+			s.logger.ErrorContext(ctx, "failed to reload sanctions list, screening against the previous one",
+				"path", s.path, "error", err)
+		}
+	}
+}
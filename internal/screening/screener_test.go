@@ -0,0 +1,178 @@
+package screening
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{HoldScore: 0.85, BlockScore: 0.95, ReloadInterval: 10 * time.Millisecond}
+
+func TestParseSDN(t *testing.T) {
+	file, err := os.Open("testdata/sdn.csv")
+	require.NoError(t, err)
+	defer file.Close()
+
+	list, err := ParseSDN(file)
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, list.Len())
+	assert.Len(t, list.Version, 12)
+	assert.Equal(t, Entry{ID: "36", Names: []string{"AEROCARIBBEAN AIRLINES"}, Program: "CUBA"}, withoutTokens(list.entries[0]))
+	assert.Equal(t, Entry{
+		ID:      "1002",
+		Names:   []string{"AL-RASHID, Mohammed Abdullah", "RASHID, Muhammad", "ABU YUSUF"},
+		Type:    "individual",
+		Program: "SDGT",
+	}, withoutTokens(list.entries[2]))
+	assert.Equal(t, []string{"GOLDEN CRESCENT TRADING CO., LTD.", "CRESCENT GENERAL TRADING"}, list.entries[3].Names)
+}
+
+func TestParseSDNErrors(t *testing.T) {
+	_, err := ParseSDN(strings.NewReader("ent_num,SDN_Name,SDN_Type\r\n\x1a\r\n"))
+	assert.EqualError(t, err, "sdn list has no entries")
+
+	_, err = ParseSDN(strings.NewReader(""))
+	assert.EqualError(t, err, "sdn list has no entries")
+}
+
+func TestParseSDNVersion(t *testing.T) {
+	a, err := ParseSDN(strings.NewReader(`1,"DOE, John",individual,SDGT`))
+	require.NoError(t, err)
+	b, err := ParseSDN(strings.NewReader(`1,"DOE, Jane",individual,SDGT`))
+	require.NoError(t, err)
+	c, err := ParseSDN(strings.NewReader(`1,"DOE, John",individual,SDGT`))
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a.Version, b.Version)
+	assert.Equal(t, a.Version, c.Version)
+}
+
+func withoutTokens(entry Entry) Entry {
+	entry.tokens = nil
+	return entry
+}
+
+func TestScreen(t *testing.T) {
+	screener, err := NewScreener("testdata/sdn.csv", testConfig, logging.Discard())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		parties   []string
+		outcome   models.ScreeningOutcome
+		matchID   string
+		matchName string
+		party     string
+	}{
+		{name: "clear", parties: []string{"Mark", "Jane"}, outcome: models.ScreeningClear},
+		{
+			name:      "exact name",
+			parties:   []string{"Mark", "Ivan Sergeyevich Petrov"},
+			outcome:   models.ScreeningBlock,
+			matchID:   "1001",
+			matchName: "PETROV, Ivan Sergeyevich",
+			party:     "Ivan Sergeyevich Petrov",
+		},
+		{
+			name:      "alias",
+			parties:   []string{"Ivan Petroff"},
+			outcome:   models.ScreeningBlock,
+			matchID:   "1001",
+			matchName: "PETROFF, Ivan",
+			party:     "Ivan Petroff",
+		},
+		{
+			name:      "transliterated name",
+			parties:   []string{"Mohamad Al Rashid"},
+			outcome:   models.ScreeningHold,
+			matchID:   "1002",
+			matchName: "RASHID, Muhammad",
+			party:     "Mohamad Al Rashid",
+		},
+		{
+			name:      "accents and company suffixes",
+			parties:   []string{"Juergen Muller", "Golden Crescent Trading Company"},
+			outcome:   models.ScreeningBlock,
+			matchID:   "1003",
+			matchName: "GOLDEN CRESCENT TRADING CO., LTD.",
+			party:     "Golden Crescent Trading Company",
+		},
+		{name: "shared first name", parties: []string{"Ivan Smith"}, outcome: models.ScreeningClear},
+		{name: "no parties", outcome: models.ScreeningClear},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := screener.Screen(tt.parties...)
+
+			assert.Equal(t, tt.outcome, decision.Outcome)
+			assert.Equal(t, screener.List().Version, decision.ListVersion)
+			if tt.outcome == models.ScreeningClear {
+				assert.Less(t, decision.Score, testConfig.HoldScore)
+				return
+			}
+			assert.Equal(t, tt.matchID, decision.MatchID)
+			assert.Equal(t, tt.matchName, decision.MatchName)
+			assert.Equal(t, tt.party, decision.Party)
+		})
+	}
+}
+
+func TestNewScreenerErrors(t *testing.T) {
+	_, err := NewScreener("testdata/sdn.csv", Config{HoldScore: 0.95, BlockScore: 0.85}, logging.Discard())
+	assert.ErrorContains(t, err, "screening scores must satisfy")
+
+	_, err = NewScreener("testdata/sdn.csv", Config{HoldScore: 0, BlockScore: 0.85}, logging.Discard())
+	assert.ErrorContains(t, err, "screening scores must satisfy")
+
+	_, err = NewScreener(filepath.Join(t.TempDir(), "missing.csv"), testConfig, logging.Discard())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestScreenerReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdn.csv")
+	writeList(t, path, `1,"DOE, John",individual,SDGT`, time.Now().Add(-time.Hour))
+
+	screener, err := NewScreener(path, testConfig, logging.Discard())
+	require.NoError(t, err)
+	assert.Equal(t, models.ScreeningBlock, screener.Screen("John Doe").Outcome)
+
+	reloaded, err := screener.Reload()
+	require.NoError(t, err)
+	assert.False(t, reloaded, "an unchanged file is not read again")
+
+	writeList(t, path, "not a list", time.Now().Add(-time.Minute))
+	_, err = screener.Reload()
+	assert.EqualError(t, err, path+": sdn list has no entries")
+	assert.Equal(t, models.ScreeningBlock, screener.Screen("John Doe").Outcome, "the previous list is kept")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	screener.Start(ctx)
+	defer func() {
+		cancel()
+		screener.Wait()
+	}()
+
+	writeList(t, path, `2,"ROE, Richard",individual,SDGT`, time.Now())
+	assert.Eventually(t, func() bool {
+		return screener.Screen("John Doe").Outcome == models.ScreeningClear &&
+			screener.Screen("Richard Roe").Outcome == models.ScreeningBlock
+	}, time.Second, 5*time.Millisecond)
+}
+
+// writeList writes a list file to path and sets its modification time
+func writeList(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
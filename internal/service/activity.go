@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"money-transfer/internal/domain/models"
+)
+
+// ActivityBatchSize is how many activities are read per poll of the event log
+const ActivityBatchSize = 100
+
+// FollowAccountActivity passes new activity of the account recorded after afterSequence to emit, in order
+// The event log is polled every pollInterval; read errors are logged to logger and retried on the next poll.
+// It returns when ctx is done or emit fails.
+func FollowAccountActivity(
+	ctx context.Context, logger *slog.Logger, bankService BankService, accountID string, afterSequence int64,
+	pollInterval time.Duration, emit func(*models.AccountActivity) error,
+) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		activities, err := bankService.AccountActivity(ctx, accountID, afterSequence, ActivityBatchSize)
+		if err != nil && ctx.Err() == nil {
+			logger.ErrorContext(ctx, "failed to read account activity", "account", accountID, "error", err)
+		}
+
+		for _, activity := range activities {
+			if err := emit(activity); err != nil {
+				return err
+			}
+			afterSequence = activity.Sequence
+		}
+		if len(activities) == ActivityBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"money-transfer/internal/storage"
+)
+
+// Sealer links newly recorded audit events into the hash chain in the background
+// Events are recorded with the changes they describe and sealed shortly after they commit,
+// so concurrent transactions never race for the end of the chain.
+type Sealer struct {
+	repo         storage.AuditRepository
+	batchSize    int
+	pollInterval time.Duration
+	logger       *slog.Logger
+	wg           sync.WaitGroup
+}
+
+// NewSealer creates a sealer sealing up to batchSize events per poll and logging failures to logger
+func NewSealer(repo storage.AuditRepository, batchSize int, pollInterval time.Duration, logger *slog.Logger) *Sealer {
+	return &Sealer{
+		repo:         repo,
+		batchSize:    batchSize,
+		pollInterval: pollInterval,
+		logger:       logger,
+	}
+}
+
+// Start launches the sealing loop; it stops once ctx is canceled
+func (s *Sealer) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.run(ctx)
+	}()
+}
+
+// Wait blocks until the sealing loop has returned
+func (s *Sealer) Wait() {
+	s.wg.Wait()
+}
+
+// run seals events until ctx is canceled, sealing full batches without waiting
+func (s *Sealer) run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if s.SealOnce(ctx) == s.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SealOnce seals a single batch and returns how many events were sealed
+func (s *Sealer) SealOnce(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	sealed, err := s.repo.Seal(ctx, s.batchSize, Hash)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "audit sealing failed", "error", err)
+	}
+
+	return sealed
+}
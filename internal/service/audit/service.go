@@ -0,0 +1,152 @@
+// Package audit reads the audit log of bank mutations and keeps its hash chain sealed and verifiable
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/storage"
+)
+
+// verifyBatchSize is how many sealed events Verify reads at once
+const verifyBatchSize = 1000
+
+// Service serves the audit log to auditors
+type Service struct {
+	repo storage.AuditRepository
+}
+
+// NewService creates a new instance of audit service
+func NewService(repo storage.AuditRepository) *Service {
+	return &Service{
+		repo: repo,
+	}
+}
+
+// ListEvents returns one page of the events matching filter, oldest first
+func (s *Service) ListEvents(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	// One event more than requested tells whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	events, err := s.repo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.AuditPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextAfter = page.Events[limit-1].Sequence
+	}
+	return page, nil
+}
+
+// Verify walks the hash chain from its first event and checks that every sealed event is
+// where its chain index says, links to the event before it and still hashes to its hash
+// Returns an error wrapping ErrAuditChainBroken that names the first event failing a check;
+// the report then counts the events verified before it.
+func Verify(ctx context.Context, repo storage.AuditRepository) (models.AuditChainReport, error) {
+	var report models.AuditChainReport
+	var index int64
+
+	for {
+		events, err := repo.Chain(ctx, index, verifyBatchSize)
+		if err != nil {
+			return report, err
+		}
+
+		for _, event := range events {
+			index++
+			switch {
+			case event.ChainIndex == nil || *event.ChainIndex != index:
+				return report, fmt.Errorf("%w: event %s found where chain index %d was expected",
+					transfererrors.ErrAuditChainBroken, event.ID, index)
+			case event.PrevHash != report.Head:
+				return report, fmt.Errorf("%w: event %s at chain index %d does not link to the event before it",
+					transfererrors.ErrAuditChainBroken, event.ID, index)
+			case Hash(event.PrevHash, event) != event.Hash:
+				return report, fmt.Errorf("%w: event %s at chain index %d was altered",
+					transfererrors.ErrAuditChainBroken, event.ID, index)
+			}
+			report.Verified++
+			report.Head = event.Hash
+		}
+
+		if len(events) < verifyBatchSize {
+			break
+		}
+	}
+
+	unsealed, err := repo.CountUnsealed(ctx)
+	if err != nil {
+		return report, err
+	}
+	report.Unsealed = unsealed
+
+	return report, nil
+}
+
+// hashedEvent lists the fields of an event covered by its hash, in a fixed order
+type hashedEvent struct {
+	ChainIndex int64           `json:"chain_index"`
+	PrevHash   string          `json:"prev_hash"`
+	ID         string          `json:"id"`
+	OccurredAt string          `json:"occurred_at"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	TransferID string          `json:"transfer_id"`
+	AccountIDs []string        `json:"account_ids"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	RequestID  string          `json:"request_id"`
+	SourceIP   string          `json:"source_ip"`
+}
+
+// Hash returns the hex SHA-256 of event chained to prevHash, the hash of the event before it
+// The hash covers every recorded field and the chain index, so moving an event breaks it too.
+func Hash(prevHash string, event *models.AuditEvent) string {
+	var chainIndex int64
+	if event.ChainIndex != nil {
+		chainIndex = *event.ChainIndex
+	}
+	accountIDs := event.AccountIDs
+	if accountIDs == nil {
+		accountIDs = []string{}
+	}
+
+	// Marshaling a struct cannot fail, and its fields are always written in the same order
+	data, _ := json.Marshal(hashedEvent{
+		ChainIndex: chainIndex,
+		PrevHash:   prevHash,
+		ID:         event.ID,
+		OccurredAt: event.OccurredAt.UTC().Format(time.RFC3339Nano),
+		Actor:      event.Actor,
+		Action:     string(event.Action),
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		TransferID: event.TransferID,
+		AccountIDs: accountIDs,
+		Before:     rawOrNull(event.Before),
+		After:      rawOrNull(event.After),
+		RequestID:  event.RequestID,
+		SourceIP:   event.SourceIP,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// rawOrNull returns raw, or a JSON null when it is empty
+func rawOrNull(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 {
+		return json.RawMessage("null")
+	}
+	return raw
+}
@@ -0,0 +1,162 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// sealedChain returns n events sealed into a valid hash chain
+func sealedChain(n int) []*models.AuditEvent {
+	occurredAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	events := make([]*models.AuditEvent, n)
+	prevHash := ""
+	for i := range events {
+		index := int64(i + 1)
+		event := &models.AuditEvent{
+			Sequence:   index,
+			ID:         "event-" + string(rune('a'+i)),
+			OccurredAt: occurredAt.Add(time.Duration(i) * time.Second),
+			Actor:      "mark",
+			Action:     models.AuditTransferStatusChanged,
+			TargetType: models.AggregateTransfer,
+			TargetID:   "t-1",
+			TransferID: "t-1",
+			AccountIDs: []string{"Mark", "Jane"},
+			Before:     json.RawMessage(`{"status": "pending"}`),
+			After:      json.RawMessage(`{"status": "processing"}`),
+			RequestID:  "req-1",
+			SourceIP:   "10.0.0.1",
+			ChainIndex: &index,
+			PrevHash:   prevHash,
+		}
+		event.Hash = Hash(prevHash, event)
+		prevHash = event.Hash
+		events[i] = event
+	}
+	return events
+}
+
+func TestHash(t *testing.T) {
+	event := sealedChain(1)[0]
+	hash := Hash("", event)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, Hash("", event), "hashes must be deterministic")
+
+	// Every field is covered, including the link to the previous event
+	assert.NotEqual(t, hash, Hash("other", event))
+	altered := *event
+	altered.After = json.RawMessage(`{"status": "completed"}`)
+	assert.NotEqual(t, hash, Hash("", &altered))
+	altered = *event
+	altered.SourceIP = "10.0.0.2"
+	assert.NotEqual(t, hash, Hash("", &altered))
+
+	// The time zone an event is read in does not matter
+	altered = *event
+	altered.OccurredAt = event.OccurredAt.In(time.FixedZone("CET", 3600))
+	assert.Equal(t, hash, Hash("", &altered))
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name         string
+		tamper       func(events []*models.AuditEvent) []*models.AuditEvent
+		wantErr      error
+		wantVerified int
+	}{
+		{
+			name:         "intact chain",
+			tamper:       func(events []*models.AuditEvent) []*models.AuditEvent { return events },
+			wantVerified: 3,
+		},
+		{
+			name: "altered event",
+			tamper: func(events []*models.AuditEvent) []*models.AuditEvent {
+				events[1].Actor = "jane"
+				return events
+			},
+			wantErr:      transfererrors.ErrAuditChainBroken,
+			wantVerified: 1,
+		},
+		{
+			name: "removed event",
+			tamper: func(events []*models.AuditEvent) []*models.AuditEvent {
+				return append(events[:1], events[2:]...)
+			},
+			wantErr:      transfererrors.ErrAuditChainBroken,
+			wantVerified: 1,
+		},
+		{
+			name: "rehashed event",
+			tamper: func(events []*models.AuditEvent) []*models.AuditEvent {
+				events[0].Actor = "jane"
+				events[0].Hash = Hash("", events[0])
+				return events
+			},
+			wantErr:      transfererrors.ErrAuditChainBroken,
+			wantVerified: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := new(mocks.AuditRepository)
+			repo.On("Chain", mock.Anything, int64(0), verifyBatchSize).Return(tt.tamper(sealedChain(3)), nil)
+			repo.On("CountUnsealed", mock.Anything).Return(2, nil)
+
+			report, err := Verify(context.Background(), repo)
+
+			assert.Equal(t, tt.wantVerified, report.Verified)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, report.Unsealed)
+			assert.NotEmpty(t, report.Head)
+		})
+	}
+}
+
+func TestService_ListEvents(t *testing.T) {
+	events := sealedChain(3)
+	repo := new(mocks.AuditRepository)
+	repo.On("List", mock.Anything, models.AuditFilter{Actor: "mark", Limit: 3}).Return(events, nil)
+	repo.On("List", mock.Anything, models.AuditFilter{Actor: "mark", Limit: 4}).Return(events, nil)
+	service := NewService(repo)
+
+	// One event more than requested is read to know whether another page follows
+	page, err := service.ListEvents(context.Background(), models.AuditFilter{Actor: "mark", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 2)
+	assert.Equal(t, int64(2), page.NextAfter)
+
+	page, err = service.ListEvents(context.Background(), models.AuditFilter{Actor: "mark", Limit: 3})
+	require.NoError(t, err)
+	assert.Len(t, page.Events, 3)
+	assert.Zero(t, page.NextAfter)
+}
+
+func TestSealer_SealOnce(t *testing.T) {
+	repo := new(mocks.AuditRepository)
+	repo.On("Seal", mock.Anything, 10, mock.Anything).Return(4, nil)
+	sealer := NewSealer(repo, 10, time.Second, logging.Discard())
+
+	assert.Equal(t, 4, sealer.SealOnce(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Zero(t, sealer.SealOnce(ctx))
+	repo.AssertNumberOfCalls(t, "Seal", 1)
+}
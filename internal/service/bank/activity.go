@@ -0,0 +1,70 @@
+package bank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"money-transfer/internal/domain/models"
+)
+
+// AccountActivity returns up to limit activities of the account recorded after afterSequence
+func (s *Service) AccountActivity(
+	ctx context.Context, accountID string, afterSequence int64, limit int,
+) ([]*models.AccountActivity, error) {
+	events, err := s.store.Outbox().AccountEvents(ctx, accountID, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	activities := make([]*models.AccountActivity, 0, len(events))
+	for _, event := range events {
+		activity, err := toAccountActivity(accountID, event)
+		if err != nil {
+			return nil, err
+		}
+		activities = append(activities, activity)
+	}
+
+	return activities, nil
+}
+
+// LatestActivitySequence returns the sequence a new activity stream starts after
+func (s *Service) LatestActivitySequence(ctx context.Context) (int64, error) {
+	return s.store.Outbox().LatestSequence(ctx)
+}
+
+// toAccountActivity projects a domain event onto the account it is read for
+func toAccountActivity(accountID string, event *models.Event) (*models.AccountActivity, error) {
+	activity := &models.AccountActivity{
+		Sequence:   event.Sequence,
+		Type:       event.Type,
+		AccountID:  accountID,
+		OccurredAt: event.CreatedAt,
+	}
+
+	switch event.AggregateType {
+	case models.AggregateTransfer:
+		var payload models.TransferEventPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("decode event %d: %w", event.Sequence, err)
+		}
+
+		activity.Transfer = &payload.Transfer
+		activity.Direction = models.DirectionIncoming
+		activity.Balance = payload.ToBalance
+		if payload.Transfer.From == accountID {
+			activity.Direction = models.DirectionOutgoing
+			activity.Balance = payload.FromBalance
+		}
+	case models.AggregateAccount:
+		var payload models.AccountEventPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("decode event %d: %w", event.Sequence, err)
+		}
+
+		activity.Balance = &payload.Account.Balance
+	}
+
+	return activity, nil
+}
@@ -0,0 +1,61 @@
+package bank
+
+import (
+	"context"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BalanceAt returns the balance the account had at the time, read from the ledger
+// An account created after the time had a balance of zero. Returns ErrFutureTime if the time
+// has not come yet and ErrAccountNotFound if the account does not exist.
+func (s *Service) BalanceAt(ctx context.Context, accountID string, at time.Time) (_ float64, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.BalanceAt", trace.WithAttributes(
+		attribute.String("account.id", accountID),
+		attribute.String("balance.as_of", at.Format(time.RFC3339)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if at.After(time.Now()) {
+		return 0, transfererrors.ErrFutureTime
+	}
+
+	balances, err := s.store.Ledger().BalancesAt(ctx, []string{accountID}, at)
+	if err != nil {
+		return 0, err
+	}
+	if len(balances) == 0 {
+		return 0, transfererrors.ErrAccountNotFound
+	}
+
+	return balances[0].Balance, nil
+}
+
+// BalancesAt returns the balances the accounts had at the same time, ordered by account ID
+// Unknown IDs are skipped. Returns ErrFutureTime if the time has not come yet.
+func (s *Service) BalancesAt(
+	ctx context.Context, accountIDs []string, at time.Time,
+) (_ *models.BalanceSnapshot, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.BalancesAt", trace.WithAttributes(
+		attribute.Int("account.count", len(accountIDs)),
+		attribute.String("balance.as_of", at.Format(time.RFC3339)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if at.After(time.Now()) {
+		return nil, transfererrors.ErrFutureTime
+	}
+
+	balances, err := s.store.Ledger().BalancesAt(ctx, accountIDs, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BalanceSnapshot{AsOf: at.UTC(), Balances: balances}, nil
+}
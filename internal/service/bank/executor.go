@@ -0,0 +1,164 @@
+package bank
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"money-transfer/internal/metrics"
+)
+
+// requeueBatchSize is how many stale transfers are requeued or failed at a time
+const requeueBatchSize = 100
+
+// Executor runs queued transfers on a pool of background workers
+// Pending transfers are claimed from storage, so any replica may execute them. Transfers left
+// processing by a process that died before executing them are requeued once their lease expires;
+// transfers it was executing for their caller are marked failed instead, as the caller never
+// learned their outcome.
+type Executor struct {
+	service      *Service
+	workers      int
+	pollInterval time.Duration
+	lease        time.Duration
+	wg           sync.WaitGroup
+}
+
+// NewExecutor creates a worker pool executing transfers submitted to service
+// Transfers processing for longer than lease are considered abandoned and executed again.
+func NewExecutor(service *Service, workers int, pollInterval, lease time.Duration) *Executor {
+	return &Executor{
+		service:      service,
+		workers:      workers,
+		pollInterval: pollInterval,
+		lease:        lease,
+	}
+}
+
+// Start launches the workers and the loop requeueing abandoned transfers; they stop once ctx is canceled
+func (e *Executor) Start(ctx context.Context) {
+	for i := 0; i < e.workers; i++ {
+		e.wg.Add(1)
+		go func() {
+			defer e.wg.Done()
+			e.work(ctx)
+		}()
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		e.sweep(ctx)
+	}()
+}
+
+// Wait blocks until all workers have returned
+func (e *Executor) Wait() {
+	e.wg.Wait()
+}
+
+// work executes pending transfers until ctx is canceled
+// Idle workers sleep until a submission wakes them or the poll interval elapses
+func (e *Executor) work(ctx context.Context) {
+	ticker := time.NewTicker(e.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if e.runNext(ctx) {
+			// There may be more work queued; let another idle worker help
+			e.service.notifyQueued()
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.service.queued:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runNext claims and executes a single pending transfer
+// Returns false when there was nothing to run
+func (e *Executor) runNext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	transfers, err := e.service.store.Transfer().ClaimPending(ctx, 1)
+	if err != nil {
+		e.service.logger.ErrorContext(ctx, "failed to claim pending transfers", "error", err)
+		return false
+	}
+	if len(transfers) == 0 {
+		return false
+	}
+
+	// Failures are recorded on the transfer and logged by execute
+	for _, transfer := range transfers {
+		_ = e.service.execute(context.WithoutCancel(ctx), transfer)
+	}
+
+	return true
+}
+
+// sweep requeues or fails abandoned transfers every lease until ctx is canceled
+func (e *Executor) sweep(ctx context.Context) {
+	ticker := time.NewTicker(e.lease)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.requeueStale(ctx)
+			e.failStale(ctx)
+		}
+	}
+}
+
+// requeueStale moves transfers processing for longer than the lease back to pending and wakes
+// a worker to execute them
+func (e *Executor) requeueStale(ctx context.Context) {
+	for {
+		transfers, err := e.service.store.Transfer().RequeueStale(ctx, e.lease, requeueBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				e.service.logger.ErrorContext(ctx, "failed to requeue stale transfers", "error", err)
+			}
+			return
+		}
+		for _, transfer := range transfers {
+			e.service.logger.WarnContext(ctx, "requeued transfer abandoned while processing", "transfer_id", transfer.ID)
+		}
+		if len(transfers) > 0 {
+			e.service.notifyQueued()
+		}
+		if len(transfers) < requeueBatchSize {
+			return
+		}
+	}
+}
+
+// failStale marks failed the transfers executed by their caller that have been processing for
+// longer than the lease
+func (e *Executor) failStale(ctx context.Context) {
+	for {
+		transfers, err := e.service.store.Transfer().FailStale(ctx, e.lease, requeueBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				e.service.logger.ErrorContext(ctx, "failed to fail stale transfers", "error", err)
+			}
+			return
+		}
+		for _, transfer := range transfers {
+			e.service.metrics.ObserveTransfer(metrics.OutcomeError, transfer.Amount)
+			e.service.logger.WarnContext(ctx, "failed transfer abandoned while processing", "transfer_id", transfer.ID)
+		}
+		if len(transfers) < requeueBatchSize {
+			return
+		}
+	}
+}
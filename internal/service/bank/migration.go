@@ -0,0 +1,62 @@
+package bank
+
+import (
+	"context"
+	"fmt"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/identifier"
+	"money-transfer/internal/tracing"
+
+	"github.com/google/uuid"
+)
+
+// legacyAccountNamespace is the namespace the UUIDs of migrated legacy accounts are derived in
+var legacyAccountNamespace = uuid.MustParse("5b1e9a4c-2f7d-4e83-9c61-0d8a3f6b7e24")
+
+// MigrateLegacyAccounts gives every customer account still named by legacy ID an account with
+// a UUID held by the same holder, and moves its balance there by an internal transfer
+// The UUID is derived from the legacy ID, so that migrating again finds the same account and
+// only moves the money that reached the legacy account since. Stops at the first account that
+// cannot be migrated; the migrations made before it are returned with the error.
+func (s *Service) MigrateLegacyAccounts(ctx context.Context) (_ []models.AccountMigration, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.MigrateLegacyAccounts")
+	defer func() { tracing.End(span, err) }()
+
+	accounts, err := s.store.Account().CustomerAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []models.AccountMigration
+	for _, account := range accounts {
+		if identifier.Internal.Validate(account.ID) == nil {
+			continue
+		}
+
+		migration := models.AccountMigration{
+			LegacyID: account.ID,
+			ID:       uuid.NewSHA1(legacyAccountNamespace, []byte(account.ID)).String(),
+		}
+		if err := s.store.Account().OpenAccount(ctx, migration.ID, account.HolderName); err != nil {
+			return migrations, fmt.Errorf("opening the account of %s: %w", account.ID, err)
+		}
+		if account.Balance > 0 {
+			transfer, err := s.Transfer(Internal(ctx), models.TransferRequest{
+				From:   account.ID,
+				To:     migration.ID,
+				Amount: account.Balance,
+			})
+			if err != nil {
+				return migrations, fmt.Errorf("moving the balance of %s: %w", account.ID, err)
+			}
+			migration.Moved = transfer.Amount
+			migration.TransferID = transfer.ID
+		}
+
+		s.logger.InfoContext(ctx, "legacy account migrated", "legacy_id", migration.LegacyID, "account_id", migration.ID,
+			"moved", migration.Moved)
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
@@ -0,0 +1,135 @@
+package bank
+
+import (
+	"context"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/metrics"
+	"money-transfer/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ListScreeningDecisions returns up to filter.Limit screening decisions matching filter, newest first
+func (s *Service) ListScreeningDecisions(
+	ctx context.Context, filter models.ScreeningFilter,
+) ([]*models.ScreeningDecision, error) {
+	return s.store.Screening().List(ctx, filter)
+}
+
+// ReviewTransfer resolves the transfer held by a screening decision and returns it
+// A released transfer is executed right away, so it is returned completed or, when its funds
+// cannot move, failed; a rejected transfer is returned failed. Returns
+// ErrScreeningDecisionNotFound if there is no such decision and ErrInvalidStatusTransition if it
+// holds no transfer or was already reviewed.
+func (s *Service) ReviewTransfer(
+	ctx context.Context, decisionID string, review models.ScreeningReview,
+) (_ *models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.ReviewTransfer", trace.WithAttributes(
+		attribute.String("screening.decision_id", decisionID),
+		attribute.String("screening.resolution", string(review.Resolution)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	transfer, err := s.store.Screening().Review(ctx, decisionID, review)
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(attribute.String("transfer.id", transfer.ID))
+	s.logger.InfoContext(ctx, "held transfer reviewed",
+		"transfer_id", transfer.ID, "decision_id", decisionID, "resolution", review.Resolution)
+
+	if transfer.Status == models.TransferStatusProcessing {
+		// The outcome of the execution is recorded on the transfer; the review itself succeeded
+		_ = s.execute(ctx, transfer)
+	}
+
+	for _, hook := range s.onReviewed {
+		hook(ctx, transfer)
+	}
+
+	return transfer, nil
+}
+
+// OnReviewed registers hook to be called with every transfer resolved in sanctions review,
+// once released transfers have been executed
+// Hooks must be registered before the service is used.
+func (s *Service) OnReviewed(hook func(ctx context.Context, transfer *models.Transfer)) {
+	s.onReviewed = append(s.onReviewed, hook)
+}
+
+// screen matches the parties of a created transfer against the sanctions list and records the decision
+// Returns ErrTransferBlocked or ErrTransferHeld when the transfer was stopped; it is then marked
+// failed or held. A transfer from or to a customer account without a holder name cannot be
+// screened and is held for review unless it is blocked.
+func (s *Service) screen(ctx context.Context, transfer *models.Transfer) error {
+	if s.screener == nil {
+		return nil
+	}
+
+	names, unnamed, err := s.parties(ctx, transfer)
+	if err != nil {
+		return err
+	}
+	decision := s.screener.Screen(names...)
+	if len(unnamed) > 0 && decision.Outcome == models.ScreeningClear {
+		s.logger.WarnContext(ctx, "accounts without a holder name cannot be screened",
+			"transfer_id", transfer.ID, "accounts", unnamed)
+		decision.Outcome = models.ScreeningHold
+	}
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.String("screening.outcome", string(decision.Outcome)),
+		attribute.Float64("screening.score", decision.Score),
+	)
+	if err := s.store.Screening().Record(ctx, transfer, &decision); err != nil {
+		return err
+	}
+
+	switch decision.Outcome {
+	case models.ScreeningBlock:
+		s.metrics.ObserveTransfer(metrics.OutcomeBlocked, transfer.Amount)
+		s.logger.WarnContext(ctx, "transfer blocked by sanctions screening", "transfer_id", transfer.ID,
+			"match_id", decision.MatchID, "score", decision.Score)
+		return transfererrors.ErrTransferBlocked
+	case models.ScreeningHold:
+		s.metrics.ObserveTransfer(metrics.OutcomeHeld, transfer.Amount)
+		s.logger.WarnContext(ctx, "transfer held for sanctions review", "transfer_id", transfer.ID,
+			"match_id", decision.MatchID, "score", decision.Score, "decision_id", decision.ID)
+		return transfererrors.ErrTransferHeld
+	}
+	return nil
+}
+
+// parties lists the names screened for a transfer: the holders of both accounts and the
+// external counterparty if there is one, along with the customer accounts that have no holder
+// name. System accounts have no holder; the counterparty stands for the other side of their
+// transfers.
+func (s *Service) parties(ctx context.Context, transfer *models.Transfer) (names, unnamed []string, err error) {
+	accounts, err := s.store.Account().GetAccounts(ctx, []string{transfer.From, transfer.To})
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]*models.Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.ID] = account
+	}
+
+	for _, id := range []string{transfer.From, transfer.To} {
+		account, ok := byID[id]
+		switch {
+		case !ok:
+			// The transfer fails once executed; there is no one to screen
+		case account.System:
+		case account.HolderName == "":
+			unnamed = append(unnamed, id)
+		default:
+			names = append(names, account.HolderName)
+		}
+	}
+	if transfer.Counterparty != nil && transfer.Counterparty.Name != "" {
+		names = append(names, transfer.Counterparty.Name)
+	}
+	return names, unnamed, nil
+}
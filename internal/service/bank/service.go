@@ -2,49 +2,182 @@ package bank
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
 
 	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/identifier"
+	"money-transfer/internal/metrics"
 	"money-transfer/internal/storage"
+	"money-transfer/internal/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// awaitPollInterval is how often AwaitTransfer re-reads a transfer's status
+const awaitPollInterval = 100 * time.Millisecond
+
+// maxExecuteAttempts is how often a transfer is tried when it conflicts with concurrent transfers
+const maxExecuteAttempts = 3
+
+// screeningFailedReason is the failure reason of transfers whose screening decision was not recorded
+const screeningFailedReason = "sanctions screening failed"
+
+var tracer = otel.Tracer("money-transfer/internal/service/bank")
+
+// Screener screens the parties of transfers against a sanctions list
+type Screener interface {
+	// Screen matches the names of the parties of a transfer against the list
+	Screen(parties ...string) models.ScreeningDecision
+}
+
 // Service handles all banking operations
 type Service struct {
-	store storage.Store
+	store    storage.Store
+	logger   *slog.Logger
+	metrics  *metrics.Collector
+	screener Screener
+	// accountIDs decides which account IDs transfers may name
+	accountIDs identifier.AccountIDPolicy
+	// queued wakes an idle executor worker when a transfer is submitted
+	queued chan struct{}
+	// onReviewed is called with every transfer resolved in sanctions review
+	onReviewed []func(ctx context.Context, transfer *models.Transfer)
 }
 
-// NewService creates a new instance of banking service
-func NewService(store storage.Store) *Service {
+// NewService creates a new instance of banking service logging to logger
+// Transfer outcomes are recorded in collector, which may be nil. The parties of new transfers
+// are screened by screener; a nil screener lets every transfer through unscreened.
+func NewService(store storage.Store, logger *slog.Logger, collector *metrics.Collector, screener Screener) *Service {
 	return &Service{
-		store: store,
+		store:    store,
+		logger:   logger,
+		metrics:  collector,
+		screener: screener,
+		queued:   make(chan struct{}, 1),
 	}
 }
 
-// Transfer performs a money transfer between two accounts
-// Returns error if transfer cannot be completed
-func (s *Service) Transfer(ctx context.Context, req models.TransferRequest) error {
-	log.Printf("Transfer request: %+v", req)
-	if req.From == req.To {
-		return transfererrors.ErrSameAccount
+// RestrictAccountIDs makes transfers naming account IDs policy does not accept fail with
+// ErrInvalidAccountIdentifier; by default every UUID and legacy account ID is accepted
+// The policy must be set before the service is used.
+func (s *Service) RestrictAccountIDs(policy identifier.AccountIDPolicy) {
+	s.accountIDs = policy
+}
+
+// internalKey marks the contexts of transfers made by the services of this ledger
+type internalKey struct{}
+
+// Internal returns a copy of ctx whose transfers may move money out of system accounts
+// Services of this ledger, such as payouts settling through the clearing account, move money
+// between system accounts this way; transfers requested on the APIs never may.
+func Internal(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalKey{}, true)
+}
+
+// Transfer performs a money transfer between two accounts and waits for the outcome
+// Returns the persisted transfer, which is marked failed when err is not nil, except for
+// ErrTransferHeld: the transfer is then held until it is resolved in sanctions review. A
+// request whose idempotency key is held by a transfer that did not fail returns that transfer
+// as it is.
+func (s *Service) Transfer(ctx context.Context, req models.TransferRequest) (_ *models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.Transfer", transferAttributes(req))
+	defer func() { tracing.End(span, err) }()
+
+	s.logger.InfoContext(ctx, "transfer requested", "from", req.From, "to", req.To, "amount", req.Amount)
+	if err := s.checkTransfer(ctx, &req); err != nil {
+		s.metrics.ObserveTransfer(metrics.OutcomeRejected, req.Amount)
+		return nil, err
 	}
 
-	if req.Amount <= 0 {
-		return transfererrors.ErrInvalidAmount
+	transfer, err := s.createTransfer(ctx, req, models.TransferStatusProcessing)
+	if errors.Is(err, transfererrors.ErrTransferExists) {
+		return transfer, nil
+	}
+	if err != nil {
+		return transfer, err
+	}
+	span.SetAttributes(attribute.String("transfer.id", transfer.ID))
+
+	if err := s.execute(ctx, transfer); err != nil {
+		return transfer, err
+	}
+
+	return transfer, nil
+}
+
+// SubmitTransfer queues a money transfer for asynchronous execution
+// Returns the pending transfer without waiting for funds to move, or the held transfer when
+// sanctions screening holds it for review
+func (s *Service) SubmitTransfer(ctx context.Context, req models.TransferRequest) (_ *models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.SubmitTransfer", transferAttributes(req))
+	defer func() { tracing.End(span, err) }()
+
+	s.logger.InfoContext(ctx, "transfer submitted", "from", req.From, "to", req.To, "amount", req.Amount)
+	if err := s.checkTransfer(ctx, &req); err != nil {
+		s.metrics.ObserveTransfer(metrics.OutcomeRejected, req.Amount)
+		return nil, err
 	}
 
-	err := s.store.Account().TransferWithinTx(ctx, req.From, req.To, req.Amount)
+	transfer, err := s.createTransfer(ctx, req, models.TransferStatusPending)
+	if errors.Is(err, transfererrors.ErrTransferHeld) || errors.Is(err, transfererrors.ErrTransferExists) {
+		return transfer, nil
+	}
 	if err != nil {
-		log.Printf("Transfer failed: %v", err)
-		return err
+		return transfer, err
 	}
+	span.SetAttributes(attribute.String("transfer.id", transfer.ID))
 
-	return nil
+	s.notifyQueued()
+
+	return transfer, nil
+}
+
+// GetTransfer returns the current state of a transfer
+// Returns error if transfer cannot be found
+func (s *Service) GetTransfer(ctx context.Context, id string) (_ *models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.GetTransfer", trace.WithAttributes(attribute.String("transfer.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.Transfer().GetTransfer(ctx, id)
+}
+
+// AwaitTransfer blocks until the transfer reaches a final status or ctx is done
+func (s *Service) AwaitTransfer(ctx context.Context, id string) (_ *models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.AwaitTransfer", trace.WithAttributes(attribute.String("transfer.id", id)))
+	defer func() { tracing.End(span, err) }()
+
+	ticker := time.NewTicker(awaitPollInterval)
+	defer ticker.Stop()
+
+	for {
+		transfer, err := s.store.Transfer().GetTransfer(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if transfer.Status.IsFinal() {
+			return transfer, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
 
 // GetBalance returns the current balance for the specified account
 // Returns error if account cannot be found
-func (s *Service) GetBalance(ctx context.Context, accountID string) (float64, error) {
+func (s *Service) GetBalance(ctx context.Context, accountID string) (_ float64, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.GetBalance", trace.WithAttributes(attribute.String("account.id", accountID)))
+	defer func() { tracing.End(span, err) }()
+
 	account, err := s.store.Account().GetAccount(ctx, accountID)
 	if err != nil {
 		return 0, err
@@ -52,3 +185,228 @@ func (s *Service) GetBalance(ctx context.Context, accountID string) (float64, er
 
 	return account.Balance, nil
 }
+
+// GetAccounts returns the accounts with the given IDs; unknown IDs are skipped
+func (s *Service) GetAccounts(ctx context.Context, ids []string) (_ []*models.Account, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.GetAccounts", trace.WithAttributes(attribute.Int("account.count", len(ids))))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.Account().GetAccounts(ctx, ids)
+}
+
+// ListTransfers returns the most recent transfers from or to the account, newest first
+func (s *Service) ListTransfers(
+	ctx context.Context, accountID string, limit int,
+) (_ []*models.Transfer, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.ListTransfers", trace.WithAttributes(
+		attribute.String("account.id", accountID),
+		attribute.Int("limit", limit),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	return s.store.Transfer().ListByAccount(ctx, accountID, limit)
+}
+
+// createTransfer persists a new transfer, screens its parties and moves it from created to next
+// A transfer stopped by screening is returned with ErrTransferBlocked, marked failed, or with
+// ErrTransferHeld, marked held. A transfer whose screening or move to next fails is marked
+// failed, so that it is not left created forever. The transfer
+// already holding the idempotency key of req is returned with ErrTransferExists, or
+// ErrReferenceConflict if it moves another amount or between other accounts. Creation is retried
+// up to maxExecuteAttempts times when that transfer fails while the key is being claimed.
+func (s *Service) createTransfer(
+	ctx context.Context, req models.TransferRequest, next models.TransferStatus,
+) (*models.Transfer, error) {
+	transfer := &models.Transfer{
+		From:           req.From,
+		To:             req.To,
+		Amount:         req.Amount,
+		Status:         models.TransferStatusCreated,
+		Counterparty:   req.Counterparty,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	err := s.store.Transfer().Create(ctx, transfer)
+	for attempt := 1; attempt < maxExecuteAttempts && errors.Is(err, transfererrors.ErrSerializationFailure); attempt++ {
+		s.logger.DebugContext(ctx, "retrying transfer creation after the idempotency key was released",
+			"idempotency_key", req.IdempotencyKey, "attempt", attempt+1)
+		err = s.store.Transfer().Create(ctx, transfer)
+	}
+	if errors.Is(err, transfererrors.ErrTransferExists) {
+		if transfer.From != req.From || transfer.To != req.To || transfer.Amount != req.Amount {
+			return nil, transfererrors.ErrReferenceConflict
+		}
+		s.logger.InfoContext(ctx, "transfer already made", "transfer_id", transfer.ID, "status", transfer.Status)
+		return transfer, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.screen(ctx, transfer); err != nil {
+		if transfer.Status == models.TransferStatusCreated {
+			s.logger.ErrorContext(ctx, "transfer could not be screened", "transfer_id", transfer.ID, "error", err)
+			_ = s.markFailed(ctx, transfer, screeningFailedReason)
+			return nil, err
+		}
+		return transfer, err
+	}
+
+	if err := s.store.Transfer().UpdateStatus(ctx, transfer.ID, transfer.Status, next, ""); err != nil {
+		s.logger.ErrorContext(ctx, "screened transfer could not be moved on",
+			"transfer_id", transfer.ID, "status", next, "error", err)
+		_ = s.markFailed(ctx, transfer, failureReason(err))
+		return nil, err
+	}
+	transfer.Status = next
+
+	return transfer, nil
+}
+
+// markFailed marks failed a transfer still in the status it has, recording reason on it
+// The caller may have gone away; the failure must still be recorded.
+func (s *Service) markFailed(ctx context.Context, transfer *models.Transfer, reason string) error {
+	err := s.store.Transfer().UpdateStatus(context.WithoutCancel(ctx),
+		transfer.ID, transfer.Status, models.TransferStatusFailed, reason)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to mark transfer as failed", "transfer_id", transfer.ID, "error", err)
+		return err
+	}
+	transfer.Status = models.TransferStatusFailed
+	transfer.FailureReason = reason
+
+	return nil
+}
+
+// execute moves the funds of a processing transfer and records the outcome on it
+// Attempts that conflict with concurrent transfers are retried up to maxExecuteAttempts times.
+func (s *Service) execute(ctx context.Context, transfer *models.Transfer) (err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.execute", trace.WithAttributes(
+		attribute.String("transfer.id", transfer.ID),
+		attribute.Float64("transfer.amount", transfer.Amount),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	err = s.store.Transfer().Execute(ctx, transfer.ID)
+	for attempt := 1; attempt < maxExecuteAttempts && errors.Is(err, transfererrors.ErrSerializationFailure); attempt++ {
+		s.metrics.ObserveTransfer(metrics.OutcomeSerializationRetry, transfer.Amount)
+		span.AddEvent("serialization failure, retrying", trace.WithAttributes(attribute.Int("attempt", attempt+1)))
+		s.logger.DebugContext(ctx, "retrying transfer after serialization failure",
+			"transfer_id", transfer.ID, "attempt", attempt+1)
+		err = s.store.Transfer().Execute(ctx, transfer.ID)
+	}
+
+	s.metrics.ObserveTransfer(outcome(err), transfer.Amount)
+	if err == nil {
+		transfer.Status = models.TransferStatusCompleted
+		s.logger.InfoContext(ctx, "transfer completed", "transfer_id", transfer.ID)
+		return nil
+	}
+
+	if s.markFailed(ctx, transfer, failureReason(err)) != nil {
+		return err
+	}
+	s.logger.WarnContext(ctx, "transfer failed", "transfer_id", transfer.ID, "reason", transfer.FailureReason)
+
+	return err
+}
+
+// notifyQueued wakes an executor worker without blocking if one is already signaled
+func (s *Service) notifyQueued() {
+	select {
+	case s.queued <- struct{}{}:
+	default:
+	}
+}
+
+// transferAttributes describes a transfer request on its span
+func transferAttributes(req models.TransferRequest) trace.SpanStartEventOption {
+	return trace.WithAttributes(
+		attribute.String("transfer.from", req.From),
+		attribute.String("transfer.to", req.To),
+		attribute.Float64("transfer.amount", req.Amount),
+	)
+}
+
+// checkTransfer validates the request and returns ErrSystemAccount if its source is a system
+// account and ctx was not made by Internal
+// Unknown accounts pass, so that the transfer is recorded as failed when it is executed.
+func (s *Service) checkTransfer(ctx context.Context, req *models.TransferRequest) error {
+	if err := validateTransfer(req, s.accountIDs); err != nil {
+		return err
+	}
+	if internal, _ := ctx.Value(internalKey{}).(bool); internal {
+		return nil
+	}
+
+	source, err := s.store.Account().GetAccount(ctx, req.From)
+	if errors.Is(err, transfererrors.ErrAccountNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if source.System {
+		return fmt.Errorf("%w: %s", transfererrors.ErrSystemAccount, req.From)
+	}
+	return nil
+}
+
+// validateTransfer checks the request before anything is persisted, its account IDs against accountIDs
+// The counterparty identifier is replaced with its normalized form, in a copy of the counterparty.
+func validateTransfer(req *models.TransferRequest, accountIDs identifier.AccountIDPolicy) error {
+	for _, id := range []string{req.From, req.To} {
+		if err := accountIDs.Validate(id); err != nil {
+			return fmt.Errorf("%w: %q %v", transfererrors.ErrInvalidAccountIdentifier, id, err)
+		}
+	}
+
+	if req.From == req.To {
+		return transfererrors.ErrSameAccount
+	}
+
+	if req.Amount <= 0 {
+		return transfererrors.ErrInvalidAmount
+	}
+
+	if req.Counterparty != nil {
+		counterparty := *req.Counterparty
+		if identifier.IsLedgerScheme(counterparty.Scheme) {
+			return fmt.Errorf("%w: accounts of this ledger are not counterparties", transfererrors.ErrInvalidAccountIdentifier)
+		}
+		normalized, err := identifier.Normalize(counterparty.Scheme, counterparty.Identifier)
+		if err != nil {
+			return err
+		}
+		counterparty.Identifier = normalized
+		req.Counterparty = &counterparty
+	}
+
+	return nil
+}
+
+// outcome classifies the result of executing a transfer for metrics
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.Is(err, transfererrors.ErrInsufficientFunds):
+		return metrics.OutcomeInsufficientFunds
+	case errors.Is(err, transfererrors.ErrAccountNotFound):
+		return metrics.OutcomeNotFound
+	default:
+		return metrics.OutcomeError
+	}
+}
+
+// failureReason returns a message safe to store and show to API clients
+func failureReason(err error) string {
+	for _, known := range []error{
+		transfererrors.ErrAccountNotFound,
+		transfererrors.ErrInsufficientFunds,
+	} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return "internal error"
+}
@@ -3,11 +3,17 @@ package bank
 import (
 	"context"
 	"fmt"
+	"math"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"money-transfer/config"
 	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
 	"money-transfer/internal/storage/postgres"
 
 	"github.com/stretchr/testify/assert"
@@ -25,7 +31,7 @@ func TestMain(m *testing.M) {
 	}
 
 	// Connect to test database
-	testStore, err = postgres.NewStore(cfg.Database.GetDSN())
+	testStore, err = postgres.NewStore(cfg.Database.GetDSN(), logging.Discard())
 	if err != nil {
 		fmt.Printf("Failed to connect to test database: %v\n", err)
 		os.Exit(1)
@@ -52,7 +58,9 @@ func cleanupTestDB() error {
 		return err
 	}
 
-	_, err = testStore.DB().Exec("TRUNCATE accounts")
+	_, err = testStore.DB().Exec(`TRUNCATE TABLE accounts, transfers, postings, outbox_events, webhook_subscriptions,
+		webhook_deliveries, rate_limit_buckets, audit_events, payouts, fundings, screening_decisions, payment_messages,
+		payment_transactions, ach_files`)
 	return err
 }
 
@@ -66,7 +74,7 @@ func setupTest(t *testing.T) *Service {
 	ctx := context.Background()
 	require.NoError(t, testStore.Account().InitializeTestData(ctx))
 
-	return NewService(testStore)
+	return NewService(testStore, logging.Discard(), nil, nil)
 }
 
 func TestBankService_Integration(t *testing.T) {
@@ -78,12 +86,13 @@ func TestBankService_Integration(t *testing.T) {
 	ctx := context.Background()
 
 	// Test successful transfer
-	err := service.Transfer(ctx, models.TransferRequest{
+	transfer, err := service.Transfer(ctx, models.TransferRequest{
 		From:   "Mark",
 		To:     "Jane",
 		Amount: 50,
 	})
 	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
 
 	// Verify balances after transfer
 	markBalance, err := service.GetBalance(ctx, "Mark")
@@ -93,4 +102,174 @@ func TestBankService_Integration(t *testing.T) {
 	janeBalance, err := service.GetBalance(ctx, "Jane")
 	require.NoError(t, err)
 	assert.Equal(t, 100.0, janeBalance)
+
+	// Only the services of the ledger move money out of system accounts
+	require.NoError(t, testStore.Account().EnsureSystemAccounts(ctx, "clearing"))
+	_, err = service.Transfer(ctx, models.TransferRequest{From: "Jane", To: "clearing", Amount: 10})
+	require.NoError(t, err)
+	_, err = service.Transfer(ctx, models.TransferRequest{From: "clearing", To: "Adam", Amount: 10})
+	require.ErrorIs(t, err, transfererrors.ErrSystemAccount)
+	transfer, err = service.Transfer(Internal(ctx), models.TransferRequest{From: "clearing", To: "Adam", Amount: 10})
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+}
+
+func TestBankService_AsyncIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	service := setupTest(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	executor := NewExecutor(service, 2, 50*time.Millisecond, time.Minute)
+	executor.Start(ctx)
+	defer func() {
+		cancel()
+		executor.Wait()
+	}()
+
+	ok, err := service.SubmitTransfer(ctx, models.TransferRequest{From: "Mark", To: "Jane", Amount: 30})
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusPending, ok.Status)
+
+	overdraft, err := service.SubmitTransfer(ctx, models.TransferRequest{From: "Adam", To: "Jane", Amount: 30})
+	require.NoError(t, err)
+
+	ok, err = service.AwaitTransfer(ctx, ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, ok.Status)
+
+	overdraft, err = service.AwaitTransfer(ctx, overdraft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusFailed, overdraft.Status)
+	assert.Equal(t, "insufficient funds", overdraft.FailureReason)
+
+	markBalance, err := service.GetBalance(ctx, "Mark")
+	require.NoError(t, err)
+	assert.Equal(t, 70.0, markBalance)
+}
+
+func TestBankService_TransferBalances(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	service := setupTest(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		fromID        string
+		toID          string
+		amount        float64
+		expectedError error
+	}{
+		{
+			name:   "successful transfer",
+			fromID: "Mark",
+			toID:   "Jane",
+			amount: 50,
+		},
+		{
+			name:          "insufficient funds",
+			fromID:        "Adam",
+			toID:          "Jane",
+			amount:        50,
+			expectedError: transfererrors.ErrInsufficientFunds,
+		},
+		{
+			name:          "sender does not exist",
+			fromID:        "NonExistent",
+			toID:          "Jane",
+			amount:        50,
+			expectedError: transfererrors.ErrAccountNotFound,
+		},
+		{
+			name:          "recipient does not exist",
+			fromID:        "Mark",
+			toID:          "NonExistent",
+			amount:        50,
+			expectedError: transfererrors.ErrAccountNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fromBefore, _ := service.GetBalance(ctx, tt.fromID)
+			toBefore, _ := service.GetBalance(ctx, tt.toID)
+
+			transfer, err := service.Transfer(ctx, models.TransferRequest{From: tt.fromID, To: tt.toID, Amount: tt.amount})
+
+			fromAfter, _ := service.GetBalance(ctx, tt.fromID)
+			toAfter, _ := service.GetBalance(ctx, tt.toID)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				assert.Equal(t, models.TransferStatusFailed, transfer.Status)
+				assert.Equal(t, fromBefore, fromAfter)
+				assert.Equal(t, toBefore, toAfter)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+			assert.Equal(t, fromBefore-tt.amount, fromAfter)
+			assert.Equal(t, toBefore+tt.amount, toAfter)
+		})
+	}
+}
+
+func TestBankService_ConcurrentTransfers(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	service := setupTest(t)
+	ctx := context.Background()
+
+	numTransfers := 10
+	transferAmount := 1.0
+
+	initialMarkBalance, err := service.GetBalance(ctx, "Mark")
+	require.NoError(t, err)
+	initialJaneBalance, err := service.GetBalance(ctx, "Jane")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var successfulTransfers atomic.Int64
+	transfer := func(from, to string) {
+		defer wg.Done()
+		_, err := service.Transfer(ctx, models.TransferRequest{From: from, To: to, Amount: transferAmount})
+		if err == nil {
+			successfulTransfers.Add(1)
+		}
+	}
+
+	for i := 0; i < numTransfers; i++ {
+		wg.Add(2)
+		go transfer("Mark", "Jane")
+		go transfer("Jane", "Mark")
+	}
+	wg.Wait()
+
+	markBalance, err := service.GetBalance(ctx, "Mark")
+	require.NoError(t, err)
+	janeBalance, err := service.GetBalance(ctx, "Jane")
+	require.NoError(t, err)
+
+	assert.Equal(t, initialMarkBalance+initialJaneBalance, markBalance+janeBalance, "total balance changed")
+
+	maxBalanceChange := float64(successfulTransfers.Load()) * transferAmount
+	assert.LessOrEqual(t, math.Abs(markBalance-initialMarkBalance), maxBalanceChange)
+	assert.LessOrEqual(t, math.Abs(janeBalance-initialJaneBalance), maxBalanceChange)
+
+	// Every balance change is recorded by a transfer and its postings
+	summary, err := testStore.Ledger().Summarize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, summary.TransferNet)
+	assert.Empty(t, summary.Transfers)
+	for _, account := range summary.Accounts {
+		assert.Equal(t, account.StoredBalance, account.PostedBalance, account.AccountID)
+	}
 }
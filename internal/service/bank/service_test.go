@@ -2,22 +2,71 @@ package bank
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 
 	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/identifier"
+	"money-transfer/internal/logging"
 	"money-transfer/internal/storage/mocks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
+// expectCreate makes the transfer repository accept a new transfer and assign it id
+func expectCreate(tr *mocks.TransferRepository, id string, next models.TransferStatus) {
+	tr.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+		Run(func(args mock.Arguments) {
+			args.Get(1).(*models.Transfer).ID = id
+		}).
+		Return(nil)
+	tr.On("UpdateStatus", mock.Anything, id, models.TransferStatusCreated, next, "").Return(nil)
+}
+
+// expectAccounts makes the account repository report the given accounts as system accounts
+// and any other as a customer account whose holder is named "<ID> Holder"
+func expectAccounts(s *mocks.Store, system ...string) {
+	ar := &mocks.AccountRepository{}
+	s.On("Account").Return(ar).Maybe()
+	account := func(id string) *models.Account {
+		if slices.Contains(system, id) {
+			return &models.Account{ID: id, System: true}
+		}
+		return &models.Account{ID: id, HolderName: id + " Holder"}
+	}
+	ar.On("GetAccount", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, id string) (*models.Account, error) { return account(id), nil }).
+		Maybe()
+	ar.On("GetAccounts", mock.Anything, mock.Anything).
+		Return(func(_ context.Context, ids []string) ([]*models.Account, error) {
+			accounts := make([]*models.Account, 0, len(ids))
+			for _, id := range ids {
+				accounts = append(accounts, account(id))
+			}
+			return accounts, nil
+		}).
+		Maybe()
+}
+
 func TestBankService_Transfer(t *testing.T) {
 	tests := []struct {
-		name    string
-		req     models.TransferRequest
-		mock    func(*mocks.Store, *mocks.AccountRepository)
-		wantErr error
+		name       string
+		req        models.TransferRequest
+		mock       func(*mocks.Store, *mocks.TransferRepository)
+		wantErr    error
+		wantStatus models.TransferStatus
+		wantReason string
 	}{
 		{
 			name: "successful transfer",
@@ -26,16 +75,13 @@ func TestBankService_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: 50,
 			},
-			mock: func(s *mocks.Store, ar *mocks.AccountRepository) {
-				s.On("Account").Return(ar)
-				ar.On("TransferWithinTx",
-					mock.Anything,
-					"Mark",
-					"Jane",
-					float64(50),
-				).Return(nil)
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(nil)
 			},
-			wantErr: nil,
+			wantErr:    nil,
+			wantStatus: models.TransferStatusCompleted,
 		},
 		{
 			name: "same account transfer",
@@ -44,9 +90,30 @@ func TestBankService_Transfer(t *testing.T) {
 				To:     "Mark",
 				Amount: 50,
 			},
-			mock:    func(_ *mocks.Store, _ *mocks.AccountRepository) {},
+			mock:    func(_ *mocks.Store, _ *mocks.TransferRepository) {},
 			wantErr: transfererrors.ErrSameAccount,
 		},
+		{
+			name: "invalid account ID",
+			req: models.TransferRequest{
+				From:   "Mark Smith",
+				To:     "Jane",
+				Amount: 50,
+			},
+			mock:    func(_ *mocks.Store, _ *mocks.TransferRepository) {},
+			wantErr: transfererrors.ErrInvalidAccountIdentifier,
+		},
+		{
+			name: "invalid counterparty",
+			req: models.TransferRequest{
+				From:         "Mark",
+				To:           "settlement",
+				Amount:       50,
+				Counterparty: &models.ExternalAccount{Scheme: models.SchemeSortCode, Identifier: "12-34-56"},
+			},
+			mock:    func(_ *mocks.Store, _ *mocks.TransferRepository) {},
+			wantErr: transfererrors.ErrInvalidAccountIdentifier,
+		},
 		{
 			name: "negative amount",
 			req: models.TransferRequest{
@@ -54,7 +121,7 @@ func TestBankService_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: -50,
 			},
-			mock:    func(_ *mocks.Store, _ *mocks.AccountRepository) {},
+			mock:    func(_ *mocks.Store, _ *mocks.TransferRepository) {},
 			wantErr: transfererrors.ErrInvalidAmount,
 		},
 		{
@@ -64,16 +131,16 @@ func TestBankService_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: 50,
 			},
-			mock: func(s *mocks.Store, ar *mocks.AccountRepository) {
-				s.On("Account").Return(ar)
-				ar.On("TransferWithinTx",
-					mock.Anything,
-					"Mark",
-					"Jane",
-					float64(50),
-				).Return(transfererrors.ErrInsufficientFunds)
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrInsufficientFunds)
+				tr.On("UpdateStatus", mock.Anything, "t-1",
+					models.TransferStatusProcessing, models.TransferStatusFailed, "insufficient funds").Return(nil)
 			},
-			wantErr: transfererrors.ErrInsufficientFunds,
+			wantErr:    transfererrors.ErrInsufficientFunds,
+			wantStatus: models.TransferStatusFailed,
+			wantReason: "insufficient funds",
 		},
 		{
 			name: "account not found",
@@ -82,16 +149,68 @@ func TestBankService_Transfer(t *testing.T) {
 				To:     "Jane",
 				Amount: 50,
 			},
-			mock: func(s *mocks.Store, ar *mocks.AccountRepository) {
-				s.On("Account").Return(ar)
-				ar.On("TransferWithinTx",
-					mock.Anything,
-					"NonExistent",
-					"Jane",
-					float64(50),
-				).Return(transfererrors.ErrAccountNotFound)
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrAccountNotFound)
+				tr.On("UpdateStatus", mock.Anything, "t-1",
+					models.TransferStatusProcessing, models.TransferStatusFailed, "account not found").Return(nil)
 			},
-			wantErr: transfererrors.ErrAccountNotFound,
+			wantErr:    transfererrors.ErrAccountNotFound,
+			wantStatus: models.TransferStatusFailed,
+			wantReason: "account not found",
+		},
+		{
+			name: "serialization failure is retried",
+			req: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrSerializationFailure).Once()
+				tr.On("Execute", mock.Anything, "t-1").Return(nil).Once()
+			},
+			wantErr:    nil,
+			wantStatus: models.TransferStatusCompleted,
+		},
+		{
+			name: "serialization retries are bounded",
+			req: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrSerializationFailure).Times(maxExecuteAttempts)
+				tr.On("UpdateStatus", mock.Anything, "t-1",
+					models.TransferStatusProcessing, models.TransferStatusFailed, "internal error").Return(nil)
+			},
+			wantErr:    transfererrors.ErrSerializationFailure,
+			wantStatus: models.TransferStatusFailed,
+			wantReason: "internal error",
+		},
+		{
+			name: "database error hides details",
+			req: models.TransferRequest{
+				From:   "Mark",
+				To:     "Jane",
+				Amount: 50,
+			},
+			mock: func(s *mocks.Store, tr *mocks.TransferRepository) {
+				s.On("Transfer").Return(tr)
+				expectCreate(tr, "t-1", models.TransferStatusProcessing)
+				tr.On("Execute", mock.Anything, "t-1").Return(assert.AnError)
+				tr.On("UpdateStatus", mock.Anything, "t-1",
+					models.TransferStatusProcessing, models.TransferStatusFailed, "internal error").Return(nil)
+			},
+			wantErr:    assert.AnError,
+			wantStatus: models.TransferStatusFailed,
+			wantReason: "internal error",
 		},
 	}
 
@@ -99,19 +218,758 @@ func TestBankService_Transfer(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create mocks
 			mockStore := mocks.NewStore(t)
-			mockAccountRepo := mocks.NewAccountRepository(t)
-			tt.mock(mockStore, mockAccountRepo)
+			expectAccounts(mockStore)
+			mockTransferRepo := mocks.NewTransferRepository(t)
+			tt.mock(mockStore, mockTransferRepo)
 
 			// Create service with mock
-			service := NewService(mockStore)
+			service := NewService(mockStore, logging.Discard(), nil, nil)
 
 			// Execute test
-			err := service.Transfer(context.Background(), tt.req)
+			transfer, err := service.Transfer(context.Background(), tt.req)
 
 			// Check results
 			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantStatus != "" {
+				require.NotNil(t, transfer)
+				assert.Equal(t, "t-1", transfer.ID)
+				assert.Equal(t, tt.wantStatus, transfer.Status)
+				assert.Equal(t, tt.wantReason, transfer.FailureReason)
+			}
 			mockStore.AssertExpectations(t)
-			mockAccountRepo.AssertExpectations(t)
+			mockTransferRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBankService_TransferCounterparty(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	expectAccounts(mockStore)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+	expectCreate(mockTransferRepo, "t-1", models.TransferStatusPending)
+
+	counterparty := &models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "gb82 west 1234 5698 7654 32", Name: "Jane Doe"}
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	transfer, err := service.SubmitTransfer(context.Background(), models.TransferRequest{
+		From:         "Mark",
+		To:           "settlement",
+		Amount:       50,
+		Counterparty: counterparty,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, &models.ExternalAccount{
+		Scheme:     models.SchemeIBAN,
+		Identifier: "GB82WEST12345698765432",
+		Name:       "Jane Doe",
+	}, transfer.Counterparty)
+	assert.Equal(t, "gb82 west 1234 5698 7654 32", counterparty.Identifier, "the request is left unchanged")
+}
+
+func TestBankService_TransferLegacyAccountIDs(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	expectAccounts(mockStore)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+	expectCreate(mockTransferRepo, "t-1", models.TransferStatusPending)
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	service.RestrictAccountIDs(identifier.RestrictLegacy("settlement"))
+
+	_, err := service.SubmitTransfer(context.Background(), models.TransferRequest{From: "Mark", To: "settlement", Amount: 50})
+	require.ErrorIs(t, err, transfererrors.ErrInvalidAccountIdentifier)
+	assert.ErrorContains(t, err, "legacy account IDs are only accepted for system accounts")
+
+	_, err = service.SubmitTransfer(context.Background(), models.TransferRequest{
+		From: "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30", To: "settlement", Amount: 50,
+	})
+	require.NoError(t, err)
+}
+
+func TestBankService_MigrateLegacyAccounts(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	mockAccountRepo := mocks.NewAccountRepository(t)
+	mockStore.On("Account").Return(mockAccountRepo)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+
+	migrated := "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30"
+	mockAccountRepo.On("CustomerAccounts", mock.Anything).Return([]*models.Account{
+		{ID: migrated, Balance: 20, HolderName: "Mark Smith"},
+		{ID: "Adam", HolderName: "Adam Brown"},
+		{ID: "Mark", Balance: 100, HolderName: "Mark Smith"},
+	}, nil).Once()
+	var opened []string
+	mockAccountRepo.On("OpenAccount", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { opened = append(opened, args.String(1)) }).
+		Return(nil)
+	// The balance moves in an internal transfer, whose source is not looked up
+	mockTransferRepo.On("Create", mock.Anything, mock.MatchedBy(func(transfer *models.Transfer) bool {
+		return transfer.From == "Mark" && transfer.Amount == 100
+	})).Run(func(args mock.Arguments) { args.Get(1).(*models.Transfer).ID = "t-1" }).Return(nil).Once()
+	mockTransferRepo.On("UpdateStatus", mock.Anything, "t-1", models.TransferStatusCreated, models.TransferStatusProcessing, "").
+		Return(nil).Once()
+	mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(nil).Once()
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	migrations, err := service.MigrateLegacyAccounts(context.Background())
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, models.AccountMigration{LegacyID: "Adam", ID: opened[0]}, migrations[0])
+	assert.Equal(t, models.AccountMigration{LegacyID: "Mark", ID: opened[1], Moved: 100, TransferID: "t-1"}, migrations[1])
+	for _, migration := range migrations {
+		assert.NoError(t, identifier.Internal.Validate(migration.ID))
+	}
+
+	// Migrating again finds the same accounts, with nothing left to move
+	mockAccountRepo.On("CustomerAccounts", mock.Anything).Return([]*models.Account{
+		{ID: "Adam", HolderName: "Adam Brown"},
+		{ID: "Mark", HolderName: "Mark Smith"},
+	}, nil).Once()
+	again, err := service.MigrateLegacyAccounts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []models.AccountMigration{{LegacyID: "Adam", ID: opened[0]}, {LegacyID: "Mark", ID: opened[1]}}, again)
+	assert.Equal(t, opened[:2], opened[2:])
+}
+
+func TestBankService_TransferFromSystemAccount(t *testing.T) {
+	req := models.TransferRequest{From: "clearing", To: "settlement", Amount: 50}
+
+	t.Run("refused", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore, "clearing", "settlement")
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(context.Background(), req)
+		require.ErrorIs(t, err, transfererrors.ErrSystemAccount)
+		assert.Nil(t, transfer)
+
+		_, err = service.SubmitTransfer(context.Background(), req)
+		require.ErrorIs(t, err, transfererrors.ErrSystemAccount)
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		expectCreate(mockTransferRepo, "t-1", models.TransferStatusProcessing)
+		mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(nil)
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(Internal(context.Background()), req)
+		require.NoError(t, err)
+		assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+	})
+
+	t.Run("to a system account", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore, "clearing", "settlement")
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		expectCreate(mockTransferRepo, "t-1", models.TransferStatusPending)
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		_, err := service.SubmitTransfer(context.Background(), models.TransferRequest{From: "Mark", To: "settlement", Amount: 50})
+		require.NoError(t, err)
+	})
+
+	t.Run("source not read", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		mockAccountRepo := mocks.NewAccountRepository(t)
+		mockStore.On("Account").Return(mockAccountRepo)
+		mockAccountRepo.On("GetAccount", mock.Anything, "clearing").Return(nil, assert.AnError)
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		_, err := service.Transfer(context.Background(), req)
+		require.ErrorIs(t, err, assert.AnError)
+	})
+}
+
+func TestBankService_TransferIdempotencyKey(t *testing.T) {
+	req := models.TransferRequest{From: "Mark", To: "Jane", Amount: 50, IdempotencyKey: "k-1"}
+
+	// expectExisting makes the transfer repository report that existing holds the key
+	expectExisting := func(tr *mocks.TransferRepository, existing models.Transfer) {
+		tr.On("Create", mock.Anything, mock.MatchedBy(func(t *models.Transfer) bool { return t.IdempotencyKey == "k-1" })).
+			Run(func(args mock.Arguments) { *args.Get(1).(*models.Transfer) = existing }).
+			Return(transfererrors.ErrTransferExists)
+	}
+
+	t.Run("made before", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		expectExisting(mockTransferRepo, models.Transfer{
+			ID: "t-1", From: "Mark", To: "Jane", Amount: 50, Status: models.TransferStatusCompleted,
+		})
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "t-1", transfer.ID)
+		assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+
+		transfer, err = service.SubmitTransfer(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "t-1", transfer.ID)
+	})
+
+	t.Run("made before with other details", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		expectExisting(mockTransferRepo, models.Transfer{
+			ID: "t-1", From: "Mark", To: "Jane", Amount: 20, Status: models.TransferStatusCompleted,
+		})
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(context.Background(), req)
+		require.ErrorIs(t, err, transfererrors.ErrReferenceConflict)
+		assert.Nil(t, transfer)
+	})
+
+	t.Run("holder failed meanwhile", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+			Return(transfererrors.ErrSerializationFailure).Once()
+		expectCreate(mockTransferRepo, "t-2", models.TransferStatusProcessing)
+		mockTransferRepo.On("Execute", mock.Anything, "t-2").Return(nil)
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "t-2", transfer.ID)
+		assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+		mockTransferRepo.AssertNumberOfCalls(t, "Create", 2)
+	})
+
+	t.Run("holder keeps failing", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+			Return(transfererrors.ErrSerializationFailure).Times(maxExecuteAttempts)
+		service := NewService(mockStore, logging.Discard(), nil, nil)
+
+		transfer, err := service.Transfer(context.Background(), req)
+		require.ErrorIs(t, err, transfererrors.ErrSerializationFailure)
+		assert.Nil(t, transfer)
+	})
+}
+
+func TestBankService_TransferSpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	mockStore := mocks.NewStore(t)
+	expectAccounts(mockStore)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+	expectCreate(mockTransferRepo, "t-1", models.TransferStatusProcessing)
+	mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrSerializationFailure).Once()
+	mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrInsufficientFunds).Once()
+	mockTransferRepo.On("UpdateStatus", mock.Anything, "t-1",
+		models.TransferStatusProcessing, models.TransferStatusFailed, "insufficient funds").Return(nil)
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	_, err := service.Transfer(context.Background(), models.TransferRequest{From: "Adam", To: "Jane", Amount: 50})
+	require.ErrorIs(t, err, transfererrors.ErrInsufficientFunds)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	execute, transfer := spans[0], spans[1]
+
+	assert.Equal(t, "bank.Service.Transfer", transfer.Name())
+	assert.Equal(t, codes.Error, transfer.Status().Code)
+	assert.Contains(t, transfer.Attributes(), attribute.String("transfer.id", "t-1"))
+
+	assert.Equal(t, "bank.Service.execute", execute.Name())
+	assert.Equal(t, transfer.SpanContext().SpanID(), execute.Parent().SpanID())
+	require.Len(t, execute.Events(), 2)
+	assert.Equal(t, "serialization failure, retrying", execute.Events()[0].Name)
+	assert.Equal(t, "exception", execute.Events()[1].Name)
+}
+
+func TestBankService_SubmitTransfer(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	expectAccounts(mockStore)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+	expectCreate(mockTransferRepo, "t-1", models.TransferStatusPending)
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+
+	transfer, err := service.SubmitTransfer(context.Background(), models.TransferRequest{
+		From:   "Mark",
+		To:     "Jane",
+		Amount: 50,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "t-1", transfer.ID)
+	assert.Equal(t, models.TransferStatusPending, transfer.Status)
+
+	// Submission must wake an executor worker without executing anything itself
+	select {
+	case <-service.queued:
+	default:
+		t.Fatal("expected submission to signal the executor")
+	}
+}
+
+func TestExecutor_RequeuesStaleTransfers(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+
+	// The transfer was left processing by a worker that died; once requeued it is claimed again
+	requeued := make(chan struct{})
+	mockTransferRepo.On("RequeueStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).
+		Return([]*models.Transfer{{ID: "t-1", Status: models.TransferStatusPending}}, nil).Once().
+		Run(func(mock.Arguments) { close(requeued) })
+	mockTransferRepo.On("RequeueStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).Return(nil, nil).Maybe()
+	mockTransferRepo.On("FailStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).Return(nil, nil).Maybe()
+	var claim sync.Once
+	mockTransferRepo.On("ClaimPending", mock.Anything, 1).Return(
+		func(context.Context, int) ([]*models.Transfer, error) {
+			select {
+			case <-requeued:
+			default:
+				return nil, nil
+			}
+			var claimed []*models.Transfer
+			claim.Do(func() {
+				claimed = []*models.Transfer{{ID: "t-1", Amount: 50, Status: models.TransferStatusProcessing}}
+			})
+			return claimed, nil
+		})
+	executed := make(chan struct{})
+	mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(nil).Once().
+		Run(func(mock.Arguments) { close(executed) })
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	executor := NewExecutor(service, 1, time.Hour, 20*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	executor.Start(ctx)
+	defer func() {
+		cancel()
+		executor.Wait()
+	}()
+
+	select {
+	case <-executed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the requeued transfer to be executed")
+	}
+}
+
+func TestExecutor_FailsStaleInlineTransfers(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+
+	// The transfer was left processing by a replica that died while its caller waited; it is not requeued
+	failed := make(chan struct{})
+	mockTransferRepo.On("RequeueStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).Return(nil, nil)
+	mockTransferRepo.On("FailStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).
+		Return([]*models.Transfer{{ID: "t-1", Status: models.TransferStatusFailed}}, nil).Once().
+		Run(func(mock.Arguments) { close(failed) })
+	mockTransferRepo.On("FailStale", mock.Anything, 20*time.Millisecond, requeueBatchSize).Return(nil, nil).Maybe()
+	mockTransferRepo.On("ClaimPending", mock.Anything, 1).Return(nil, nil).Maybe()
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+	executor := NewExecutor(service, 1, time.Hour, 20*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	executor.Start(ctx)
+	defer func() {
+		cancel()
+		executor.Wait()
+	}()
+
+	select {
+	case <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the stale transfer to be failed")
+	}
+}
+
+// screenerFunc screens parties with a function
+type screenerFunc func(parties ...string) models.ScreeningDecision
+
+func (f screenerFunc) Screen(parties ...string) models.ScreeningDecision {
+	return f(parties...)
+}
+
+// screenAs returns a screener deciding outcome for every transfer and recording the parties screened
+func screenAs(outcome models.ScreeningOutcome, screened *[]string) screenerFunc {
+	return func(parties ...string) models.ScreeningDecision {
+		*screened = parties
+		return models.ScreeningDecision{Outcome: outcome, Score: 0.9, MatchID: "1001", ListVersion: "abc"}
+	}
+}
+
+// expectRecord makes the screening repository record decisions, moving the transfer to status
+// the way storage does
+func expectRecord(sr *mocks.ScreeningRepository, status models.TransferStatus, reason string) {
+	sr.On("Record", mock.Anything, mock.AnythingOfType("*models.Transfer"), mock.AnythingOfType("*models.ScreeningDecision")).
+		Run(func(args mock.Arguments) {
+			transfer := args.Get(1).(*models.Transfer)
+			transfer.Status = status
+			transfer.FailureReason = reason
+			args.Get(2).(*models.ScreeningDecision).ID = "d-1"
+		}).
+		Return(nil)
+}
+
+func TestBankService_TransferScreening(t *testing.T) {
+	counterparty := &models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "GB82WEST12345698765432", Name: "Ivan Petrov"}
+	req := models.TransferRequest{From: "Mark", To: "clearing", Amount: 50, Counterparty: counterparty}
+
+	t.Run("clear", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore, "clearing")
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		expectCreate(mockTransferRepo, "t-1", models.TransferStatusProcessing)
+		expectRecord(mockScreeningRepo, models.TransferStatusCreated, "")
+		mockTransferRepo.On("Execute", mock.Anything, "t-1").Return(nil)
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningClear, &screened))
+		transfer, err := service.Transfer(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+		assert.Equal(t, []string{"Mark Holder", "Ivan Petrov"}, screened)
+	})
+
+	t.Run("account without a holder name", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		mockAccountRepo := mocks.NewAccountRepository(t)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Account").Return(mockAccountRepo)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockAccountRepo.On("GetAccount", mock.Anything, "Mark").Return(&models.Account{ID: "Mark"}, nil)
+		mockAccountRepo.On("GetAccounts", mock.Anything, []string{"Mark", "Jane"}).
+			Return([]*models.Account{{ID: "Jane", HolderName: "Jane Doe"}, {ID: "Mark"}}, nil)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).Return(nil)
+		mockScreeningRepo.On("Record", mock.Anything, mock.AnythingOfType("*models.Transfer"),
+			mock.MatchedBy(func(d *models.ScreeningDecision) bool { return d.Outcome == models.ScreeningHold })).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Transfer).Status = models.TransferStatusHeld }).
+			Return(nil)
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningClear, &screened))
+		transfer, err := service.Transfer(context.Background(), models.TransferRequest{From: "Mark", To: "Jane", Amount: 5})
+
+		require.ErrorIs(t, err, transfererrors.ErrTransferHeld)
+		assert.Equal(t, models.TransferStatusHeld, transfer.Status)
+		assert.Equal(t, []string{"Jane Doe"}, screened)
+	})
+
+	t.Run("held", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Transfer).ID = "t-1" }).
+			Return(nil)
+		expectRecord(mockScreeningRepo, models.TransferStatusHeld, "")
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningHold, &screened))
+		transfer, err := service.Transfer(context.Background(), req)
+
+		require.ErrorIs(t, err, transfererrors.ErrTransferHeld)
+		require.NotNil(t, transfer)
+		assert.Equal(t, "t-1", transfer.ID)
+		assert.Equal(t, models.TransferStatusHeld, transfer.Status)
+	})
+
+	t.Run("held on submission", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).Return(nil)
+		expectRecord(mockScreeningRepo, models.TransferStatusHeld, "")
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningHold, &screened))
+		transfer, err := service.SubmitTransfer(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.TransferStatusHeld, transfer.Status)
+		select {
+		case <-service.queued:
+			t.Fatal("a held transfer must not be queued")
+		default:
+		}
+	})
+
+	t.Run("blocked", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).Return(nil)
+		expectRecord(mockScreeningRepo, models.TransferStatusFailed, "blocked by sanctions screening")
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningBlock, &screened))
+		transfer, err := service.SubmitTransfer(context.Background(), req)
+
+		require.ErrorIs(t, err, transfererrors.ErrTransferBlocked)
+		require.NotNil(t, transfer)
+		assert.Equal(t, models.TransferStatusFailed, transfer.Status)
+		assert.Equal(t, "blocked by sanctions screening", transfer.FailureReason)
+	})
+
+	t.Run("decision not recorded", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).Return(nil)
+		mockScreeningRepo.On("Record", mock.Anything, mock.Anything, mock.Anything).Return(assert.AnError)
+		mockTransferRepo.On("UpdateStatus", mock.Anything, mock.Anything,
+			models.TransferStatusCreated, models.TransferStatusFailed, screeningFailedReason).Return(nil)
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningClear, &screened))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		transfer, err := service.Transfer(ctx, req)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, transfer)
+		// The transfer is not left created, even once the caller has gone away
+		updateCtx := mockTransferRepo.Calls[len(mockTransferRepo.Calls)-1].Arguments.Get(0).(context.Context)
+		assert.NoError(t, updateCtx.Err())
+	})
+
+	t.Run("not moved on after screening", func(t *testing.T) {
+		mockStore := mocks.NewStore(t)
+		expectAccounts(mockStore)
+		mockTransferRepo := mocks.NewTransferRepository(t)
+		mockScreeningRepo := mocks.NewScreeningRepository(t)
+		mockStore.On("Transfer").Return(mockTransferRepo)
+		mockStore.On("Screening").Return(mockScreeningRepo)
+		mockTransferRepo.On("Create", mock.Anything, mock.AnythingOfType("*models.Transfer")).
+			Run(func(args mock.Arguments) { args.Get(1).(*models.Transfer).ID = "t-1" }).
+			Return(nil)
+		expectRecord(mockScreeningRepo, models.TransferStatusCreated, "")
+		mockTransferRepo.On("UpdateStatus", mock.Anything, "t-1",
+			models.TransferStatusCreated, models.TransferStatusProcessing, "").Return(assert.AnError)
+		mockTransferRepo.On("UpdateStatus", mock.Anything, "t-1",
+			models.TransferStatusCreated, models.TransferStatusFailed, "internal error").Return(nil)
+
+		var screened []string
+		service := NewService(mockStore, logging.Discard(), nil, screenAs(models.ScreeningClear, &screened))
+		transfer, err := service.Transfer(context.Background(), req)
+
+		require.ErrorIs(t, err, assert.AnError)
+		assert.Nil(t, transfer)
+	})
+}
+
+func TestBankService_ReviewTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		review     models.ScreeningReview
+		mock       func(*mocks.ScreeningRepository, *mocks.TransferRepository)
+		wantErr    error
+		wantStatus models.TransferStatus
+		wantHook   bool
+	}{
+		{
+			name:   "released transfer is executed",
+			review: models.ScreeningReview{Resolution: models.ScreeningReleased},
+			mock: func(sr *mocks.ScreeningRepository, tr *mocks.TransferRepository) {
+				sr.On("Review", mock.Anything, "d-1", models.ScreeningReview{Resolution: models.ScreeningReleased}).
+					Return(&models.Transfer{ID: "t-1", Amount: 50, Status: models.TransferStatusProcessing}, nil)
+				tr.On("Execute", mock.Anything, "t-1").Return(nil)
+			},
+			wantStatus: models.TransferStatusCompleted,
+			wantHook:   true,
+		},
+		{
+			name:   "released transfer without funds",
+			review: models.ScreeningReview{Resolution: models.ScreeningReleased},
+			mock: func(sr *mocks.ScreeningRepository, tr *mocks.TransferRepository) {
+				sr.On("Review", mock.Anything, "d-1", mock.Anything).
+					Return(&models.Transfer{ID: "t-1", Amount: 50, Status: models.TransferStatusProcessing}, nil)
+				tr.On("Execute", mock.Anything, "t-1").Return(transfererrors.ErrInsufficientFunds)
+				tr.On("UpdateStatus", mock.Anything, "t-1",
+					models.TransferStatusProcessing, models.TransferStatusFailed, "insufficient funds").Return(nil)
+			},
+			wantStatus: models.TransferStatusFailed,
+			wantHook:   true,
+		},
+		{
+			name:   "rejected transfer",
+			review: models.ScreeningReview{Resolution: models.ScreeningRejected, Note: "confirmed match"},
+			mock: func(sr *mocks.ScreeningRepository, _ *mocks.TransferRepository) {
+				sr.On("Review", mock.Anything, "d-1", mock.Anything).
+					Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusFailed}, nil)
+			},
+			wantStatus: models.TransferStatusFailed,
+			wantHook:   true,
+		},
+		{
+			name:   "already reviewed",
+			review: models.ScreeningReview{Resolution: models.ScreeningRejected},
+			mock: func(sr *mocks.ScreeningRepository, _ *mocks.TransferRepository) {
+				sr.On("Review", mock.Anything, "d-1", mock.Anything).Return(nil, transfererrors.ErrInvalidStatusTransition)
+			},
+			wantErr: transfererrors.ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewStore(t)
+			mockTransferRepo := mocks.NewTransferRepository(t)
+			mockScreeningRepo := mocks.NewScreeningRepository(t)
+			mockStore.On("Screening").Return(mockScreeningRepo)
+			mockStore.On("Transfer").Return(mockTransferRepo).Maybe()
+			tt.mock(mockScreeningRepo, mockTransferRepo)
+
+			service := NewService(mockStore, logging.Discard(), nil, nil)
+			var reviewed *models.Transfer
+			service.OnReviewed(func(_ context.Context, transfer *models.Transfer) { reviewed = transfer })
+
+			transfer, err := service.ReviewTransfer(context.Background(), "d-1", tt.review)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				assert.Nil(t, reviewed)
+				return
+			}
+			assert.Equal(t, tt.wantStatus, transfer.Status)
+			if tt.wantHook {
+				assert.Same(t, transfer, reviewed)
+			}
+		})
+	}
+}
+
+func TestBankService_AwaitTransfer(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	mockTransferRepo := mocks.NewTransferRepository(t)
+	mockStore.On("Transfer").Return(mockTransferRepo)
+	mockTransferRepo.On("GetTransfer", mock.Anything, "t-1").
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusProcessing}, nil).Once()
+	mockTransferRepo.On("GetTransfer", mock.Anything, "t-1").
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+
+	service := NewService(mockStore, logging.Discard(), nil, nil)
+
+	transfer, err := service.AwaitTransfer(context.Background(), "t-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+}
+
+func TestBankService_AccountActivity(t *testing.T) {
+	fromBalance, toBalance := 60.0, 90.0
+	transfer := models.Transfer{ID: "t-1", From: "Mark", To: "Jane", Amount: 40, Status: models.TransferStatusCompleted}
+	transferPayload, err := json.Marshal(models.TransferEventPayload{
+		Transfer:    transfer,
+		FromBalance: &fromBalance,
+		ToBalance:   &toBalance,
+	})
+	require.NoError(t, err)
+	accountPayload, err := json.Marshal(models.AccountEventPayload{Account: models.Account{ID: "Jane", Balance: 50}})
+	require.NoError(t, err)
+
+	events := []*models.Event{
+		{Sequence: 2, Type: models.EventAccountCreated, AggregateType: models.AggregateAccount, Payload: accountPayload},
+		{Sequence: 7, Type: models.EventTransferCompleted, AggregateType: models.AggregateTransfer, Payload: transferPayload},
+	}
+
+	tests := []struct {
+		name          string
+		accountID     string
+		wantDirection models.ActivityDirection
+		wantBalance   float64
+	}{
+		{name: "source account", accountID: "Mark", wantDirection: models.DirectionOutgoing, wantBalance: 60},
+		{name: "destination account", accountID: "Jane", wantDirection: models.DirectionIncoming, wantBalance: 90},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewStore(t)
+			mockOutbox := mocks.NewOutboxRepository(t)
+			mockStore.On("Outbox").Return(mockOutbox)
+			mockOutbox.On("AccountEvents", mock.Anything, tt.accountID, int64(1), 10).Return(events, nil)
+
+			service := NewService(mockStore, logging.Discard(), nil, nil)
+
+			activities, err := service.AccountActivity(context.Background(), tt.accountID, 1, 10)
+			require.NoError(t, err)
+			require.Len(t, activities, 2)
+
+			assert.Equal(t, int64(2), activities[0].Sequence)
+			assert.Empty(t, activities[0].Direction)
+			require.NotNil(t, activities[0].Balance)
+			assert.Equal(t, 50.0, *activities[0].Balance)
+
+			completed := activities[1]
+			assert.Equal(t, models.EventTransferCompleted, completed.Type)
+			assert.Equal(t, tt.accountID, completed.AccountID)
+			assert.Equal(t, tt.wantDirection, completed.Direction)
+			assert.Equal(t, "t-1", completed.Transfer.ID)
+			require.NotNil(t, completed.Balance)
+			assert.Equal(t, tt.wantBalance, *completed.Balance)
+		})
+	}
+}
+
+func TestTransferStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from models.TransferStatus
+		to   models.TransferStatus
+		want bool
+	}{
+		{models.TransferStatusCreated, models.TransferStatusPending, true},
+		{models.TransferStatusCreated, models.TransferStatusProcessing, true},
+		{models.TransferStatusPending, models.TransferStatusProcessing, true},
+		{models.TransferStatusProcessing, models.TransferStatusCompleted, true},
+		{models.TransferStatusProcessing, models.TransferStatusFailed, true},
+		{models.TransferStatusProcessing, models.TransferStatusPending, true},
+		{models.TransferStatusCompleted, models.TransferStatusReversed, true},
+		{models.TransferStatusCreated, models.TransferStatusHeld, true},
+		{models.TransferStatusHeld, models.TransferStatusProcessing, true},
+		{models.TransferStatusHeld, models.TransferStatusFailed, true},
+		{models.TransferStatusHeld, models.TransferStatusCompleted, false},
+		{models.TransferStatusPending, models.TransferStatusCompleted, false},
+		{models.TransferStatusCompleted, models.TransferStatusFailed, false},
+		{models.TransferStatusFailed, models.TransferStatusProcessing, false},
+		{models.TransferStatusReversed, models.TransferStatusCompleted, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.CanTransitionTo(tt.to))
 		})
 	}
 }
@@ -176,7 +1034,7 @@ func TestBankService_GetBalance(t *testing.T) {
 			tt.mock(mockStore, mockAccountRepo)
 
 			// Create service with mock
-			service := NewService(mockStore)
+			service := NewService(mockStore, logging.Discard(), nil, nil)
 
 			// Execute test
 			balance, err := service.GetBalance(context.Background(), tt.accountID)
@@ -189,3 +1047,132 @@ func TestBankService_GetBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestBankService_Statement(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	postings := func() []*models.Posting {
+		return []*models.Posting{
+			{ID: 7, Kind: models.PostingTransfer, TransferID: "t-1", Counterparty: "Jane", Amount: -40.1, BalanceAfter: 59.9},
+			{ID: 9, Kind: models.PostingTransfer, TransferID: "t-2", Counterparty: "Adam", Amount: 0.2, BalanceAfter: 60.1},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		from    time.Time
+		to      time.Time
+		ledger  func() *models.AccountLedger
+		wantErr error
+	}{
+		{
+			name: "balances add up",
+			from: from,
+			to:   to,
+			ledger: func() *models.AccountLedger {
+				return &models.AccountLedger{OpeningBalance: 100, Postings: postings(), HeadBalance: 75.1, Balance: 75.1}
+			},
+		},
+		{
+			name: "running balance disagrees with a posting",
+			from: from,
+			to:   to,
+			ledger: func() *models.AccountLedger {
+				ledger := &models.AccountLedger{OpeningBalance: 100, Postings: postings(), HeadBalance: 75.1, Balance: 75.1}
+				ledger.Postings[1].BalanceAfter = 61
+				return ledger
+			},
+			wantErr: transfererrors.ErrLedgerMismatch,
+		},
+		{
+			name: "stored balance disagrees with the ledger",
+			from: from,
+			to:   to,
+			ledger: func() *models.AccountLedger {
+				return &models.AccountLedger{OpeningBalance: 100, Postings: postings(), HeadBalance: 75.1, Balance: 80}
+			},
+			wantErr: transfererrors.ErrLedgerMismatch,
+		},
+		{name: "reversed period", from: to, to: from, wantErr: transfererrors.ErrInvalidPeriod},
+		{name: "period too long", from: from, to: from.AddDate(2, 0, 0), wantErr: transfererrors.ErrInvalidPeriod},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewStore(t)
+			mockLedger := mocks.NewLedgerRepository(t)
+			if tt.ledger != nil {
+				mockStore.On("Ledger").Return(mockLedger)
+				mockLedger.On("AccountLedger", mock.Anything, "Mark", tt.from, tt.to).Return(tt.ledger(), nil)
+			}
+			service := NewService(mockStore, logging.Discard(), nil, nil)
+
+			statement, err := service.Statement(context.Background(), "Mark", tt.from, tt.to)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 100.0, statement.OpeningBalance)
+			assert.Equal(t, 60.1, statement.ClosingBalance)
+			assert.Equal(t, 0.2, statement.TotalCredits)
+			assert.Equal(t, 40.1, statement.TotalDebits)
+			require.Len(t, statement.Lines, 2)
+			assert.Equal(t, "Transfer to Jane", statement.Lines[0].Description)
+			assert.Equal(t, 59.9, statement.Lines[0].Balance)
+			assert.Equal(t, "Transfer from Adam", statement.Lines[1].Description)
+		})
+	}
+}
+
+func TestBankService_BalanceAt(t *testing.T) {
+	at := time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		at          time.Time
+		balances    []models.AccountBalance
+		wantBalance float64
+		wantErr     error
+	}{
+		{
+			name:        "balance at a past time",
+			at:          at,
+			balances:    []models.AccountBalance{{AccountID: "Mark", Balance: 60}},
+			wantBalance: 60,
+		},
+		{
+			name:     "account not found",
+			at:       at,
+			balances: []models.AccountBalance{},
+			wantErr:  transfererrors.ErrAccountNotFound,
+		},
+		{
+			name:    "time in the future",
+			at:      time.Now().Add(time.Hour),
+			wantErr: transfererrors.ErrFutureTime,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewStore(t)
+			mockLedger := mocks.NewLedgerRepository(t)
+			if tt.balances != nil {
+				mockStore.On("Ledger").Return(mockLedger)
+				mockLedger.On("BalancesAt", mock.Anything, []string{"Mark"}, tt.at).Return(tt.balances, nil)
+			}
+			service := NewService(mockStore, logging.Discard(), nil, nil)
+
+			balance, err := service.BalanceAt(context.Background(), "Mark", tt.at)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantBalance, balance)
+		})
+	}
+}
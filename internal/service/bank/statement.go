@@ -0,0 +1,104 @@
+package bank
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxStatementPeriod bounds the period a single statement may cover
+const MaxStatementPeriod = 366 * 24 * time.Hour
+
+// Statement returns the statement of the account for the period [from, to)
+// The running balance of every line is recomputed from the opening balance and checked
+// against the balance the ledger recorded for it, and the ledger is checked against the
+// stored balance, so a statement can never disagree with GetBalance; ErrLedgerMismatch is
+// returned when either check fails.
+func (s *Service) Statement(ctx context.Context, accountID string, from, to time.Time) (_ *models.Statement, err error) {
+	ctx, span := tracer.Start(ctx, "bank.Service.Statement", trace.WithAttributes(
+		attribute.String("account.id", accountID),
+		attribute.String("statement.from", from.Format(time.RFC3339)),
+		attribute.String("statement.to", to.Format(time.RFC3339)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if !from.Before(to) || to.Sub(from) > MaxStatementPeriod {
+		return nil, transfererrors.ErrInvalidPeriod
+	}
+
+	ledger, err := s.store.Ledger().AccountLedger(ctx, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if cents(ledger.HeadBalance) != cents(ledger.Balance) {
+		return nil, fmt.Errorf("%w: account %s holds %.2f but its postings add up to %.2f",
+			transfererrors.ErrLedgerMismatch, accountID, ledger.Balance, ledger.HeadBalance)
+	}
+
+	statement := &models.Statement{
+		AccountID:      accountID,
+		Currency:       models.Currency,
+		From:           from.UTC(),
+		To:             to.UTC(),
+		OpeningBalance: cents(ledger.OpeningBalance),
+		Lines:          make([]models.StatementLine, 0, len(ledger.Postings)),
+		GeneratedAt:    time.Now().UTC(),
+	}
+
+	balance := statement.OpeningBalance
+	for _, posting := range ledger.Postings {
+		balance = cents(balance + posting.Amount)
+		if balance != cents(posting.BalanceAfter) {
+			return nil, fmt.Errorf("%w: posting %d of account %s leaves %.2f but the running balance is %.2f",
+				transfererrors.ErrLedgerMismatch, posting.ID, accountID, posting.BalanceAfter, balance)
+		}
+
+		if posting.Amount > 0 {
+			statement.TotalCredits = cents(statement.TotalCredits + posting.Amount)
+		} else {
+			statement.TotalDebits = cents(statement.TotalDebits - posting.Amount)
+		}
+		statement.Lines = append(statement.Lines, models.StatementLine{
+			PostingID:    posting.ID,
+			PostedAt:     posting.PostedAt,
+			Kind:         posting.Kind,
+			Description:  describePosting(posting),
+			TransferID:   posting.TransferID,
+			Counterparty: posting.Counterparty,
+			Amount:       posting.Amount,
+			Balance:      balance,
+		})
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// describePosting summarizes a posting for the reader of a statement
+func describePosting(posting *models.Posting) string {
+	switch {
+	case posting.Kind == models.PostingOpening:
+		return "Opening balance"
+	case posting.Kind == models.PostingAdjustment:
+		return "Balance adjustment"
+	case posting.Counterparty == "":
+		return "Transfer"
+	case posting.Amount < 0:
+		return "Transfer to " + posting.Counterparty
+	default:
+		return "Transfer from " + posting.Counterparty
+	}
+}
+
+// cents rounds an amount to whole cents, dropping the error of adding binary fractions
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
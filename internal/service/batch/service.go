@@ -0,0 +1,466 @@
+// Package batch executes ISO 20022 credit transfer initiations as transfers
+package batch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/iso20022"
+	"money-transfer/internal/service"
+	"money-transfer/internal/storage"
+)
+
+// Limits of a single message
+const (
+	// MaxTransactions is the most transactions a message may hold; larger ones are refused unread
+	MaxTransactions = 1000
+	// maxAmount is the largest amount of a single transfer, as accepted by the transfer API
+	maxAmount = 1000000
+)
+
+// staleClaimAge is how long a transaction may stay claimed without a result before a repeat of
+// it resolves the claim from its transfer; claims run to the end once made, so only a crash
+// leaves one without a result for long
+const staleClaimAge = 5 * time.Minute
+
+// Service executes the transactions of pain.001 messages one by one and reports their outcome
+type Service struct {
+	bankService service.BankService
+	repo        storage.PaymentMessageRepository
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// NewService creates a new instance of batch service executing transfers through bankService
+// and recording the messages it executed in repo
+func NewService(bankService service.BankService, repo storage.PaymentMessageRepository, logger *slog.Logger) *Service {
+	return &Service{
+		bankService: bankService,
+		repo:        repo,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// Execute executes every transaction of msg that passes its checks and reports on each
+// The caller, read from ctx, must be allowed to access the debtor account of a payment
+// instruction for its transactions to run. A message or instruction whose declared number
+// of transactions or control sum does not match is rejected as a whole; otherwise each
+// transaction stands alone, so that one failing does not hold up the others.
+// A message its sender executed before is not executed again: the report it was answered with
+// is returned. A transaction whose debtor account and EndToEndId were executed before, by this
+// message or another, is reported as it was then, or rejected with AM05 while it still executes.
+// Returns an error wrapping ErrInvalidPaymentMessage when msg holds more than
+// MaxTransactions transactions, and ErrReferenceConflict when its sender executed another
+// message with the same ID.
+func (s *Service) Execute(
+	ctx context.Context, msg *iso20022.CreditTransferInitiation,
+) (*iso20022.PaymentStatusReport, error) {
+	var transactions []iso20022.CreditTransfer
+	for _, payment := range msg.Payments {
+		transactions = append(transactions, payment.Transactions...)
+	}
+	if len(transactions) > MaxTransactions {
+		return nil, fmt.Errorf("%w: message has %d transactions, at most %d are accepted",
+			transfererrors.ErrInvalidPaymentMessage, len(transactions), MaxTransactions)
+	}
+
+	var sender string
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		sender = principal.ID
+	}
+	digest, err := digestMessage(msg)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.repo.GetMessage(ctx, sender, msg.MessageID)
+	switch {
+	case err == nil:
+		return s.repeatedMessage(ctx, msg, previous, digest)
+	case !errors.Is(err, transfererrors.ErrPaymentMessageNotFound):
+		return nil, err
+	}
+
+	messageID, err := iso20022.NewMessageID()
+	if err != nil {
+		return nil, err
+	}
+	report := &iso20022.PaymentStatusReport{
+		MessageID:                    messageID,
+		CreatedAt:                    s.now(),
+		OriginalMessageID:            msg.MessageID,
+		OriginalCreatedAt:            msg.CreatedAt,
+		OriginalNumberOfTransactions: msg.NumberOfTransactions,
+	}
+
+	report.GroupReason = checkTotals(msg.NumberOfTransactions, msg.ControlSum, transactions)
+	for _, payment := range msg.Payments {
+		var status iso20022.PaymentStatus
+		if report.GroupReason != nil {
+			status = reject(payment, nil)
+		} else if status, err = s.executePayment(ctx, msg.MessageID, payment); err != nil {
+			return nil, err
+		}
+		report.Payments = append(report.Payments, status)
+	}
+
+	var settled int
+	statuses := make([]iso20022.Status, 0, len(report.Payments))
+	for _, payment := range report.Payments {
+		statuses = append(statuses, payment.Status)
+		for _, tx := range payment.Transactions {
+			if tx.Status == iso20022.StatusSettled {
+				settled++
+			}
+		}
+	}
+	report.GroupStatus = combine(statuses)
+
+	encoded, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	message := &models.PaymentMessage{Sender: sender, MessageID: msg.MessageID, Digest: digest, Report: encoded}
+	if err := s.repo.SaveMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "payment initiation executed", "message_id", msg.MessageID,
+		"transactions", len(transactions), "settled", settled, "status", report.GroupStatus)
+	return report, nil
+}
+
+// repeatedMessage returns the report a message executed before was answered with, or an error
+// wrapping ErrReferenceConflict if the earlier message had other contents
+func (s *Service) repeatedMessage(
+	ctx context.Context, msg *iso20022.CreditTransferInitiation, previous *models.PaymentMessage, digest string,
+) (*iso20022.PaymentStatusReport, error) {
+	if previous.Digest != digest {
+		return nil, fmt.Errorf("%w: message %s was executed before with other contents",
+			transfererrors.ErrReferenceConflict, msg.MessageID)
+	}
+
+	var report iso20022.PaymentStatusReport
+	if err := json.Unmarshal(previous.Report, &report); err != nil {
+		return nil, fmt.Errorf("reading report of message %s: %w", msg.MessageID, err)
+	}
+	s.logger.InfoContext(ctx, "payment initiation repeated", "message_id", msg.MessageID,
+		"executed_at", previous.CreatedAt, "status", report.GroupStatus)
+	return &report, nil
+}
+
+// digestMessage returns the SHA-256 of a message as read, whatever version it was written in
+func digestMessage(msg *iso20022.CreditTransferInitiation) (string, error) {
+	encoded, err := json.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// executePayment checks a payment instruction and executes its transactions if it passes
+// Returns an error if the execution of a transaction could not be recorded.
+func (s *Service) executePayment(
+	ctx context.Context, messageID string, payment iso20022.PaymentInstruction,
+) (iso20022.PaymentStatus, error) {
+	if reason := s.checkPayment(ctx, payment); reason != nil {
+		return reject(payment, reason), nil
+	}
+
+	status := iso20022.PaymentStatus{OriginalPaymentID: payment.ID}
+	statuses := make([]iso20022.Status, 0, len(payment.Transactions))
+	for _, tx := range payment.Transactions {
+		result, err := s.executeTransaction(ctx, messageID, payment.DebtorAccount.Other, tx)
+		if err != nil {
+			return status, err
+		}
+		status.Transactions = append(status.Transactions, result)
+		statuses = append(statuses, result.Status)
+	}
+	status.Status = combine(statuses)
+	return status, nil
+}
+
+// checkPayment returns why a payment instruction must be rejected as a whole, or nil
+func (s *Service) checkPayment(ctx context.Context, payment iso20022.PaymentInstruction) *iso20022.StatusReason {
+	if payment.NumberOfTransactions != nil || payment.ControlSum != nil {
+		count := len(payment.Transactions)
+		if payment.NumberOfTransactions != nil {
+			count = *payment.NumberOfTransactions
+		}
+		if reason := checkTotals(count, payment.ControlSum, payment.Transactions); reason != nil {
+			return reason
+		}
+	}
+
+	debtor := payment.DebtorAccount
+	if debtor.Other == "" {
+		return &iso20022.StatusReason{Code: iso20022.ReasonIncorrectAccount, Info: "debtor account must be given as Othr/Id"}
+	}
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(debtor.Other) {
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonTransactionForbidden,
+			Info: fmt.Sprintf("account %s may not be debited by the sender", debtor.Other),
+		}
+	}
+
+	today := s.now().UTC().Truncate(24 * time.Hour)
+	if payment.ExecutionDate.After(today) {
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonInvalidDate,
+			Info: "execution dates in the future are not supported",
+		}
+	}
+	return nil
+}
+
+// executeTransaction checks a credit transfer and executes it as a transfer from debtor
+// The transaction is claimed by debtor and its EndToEndId first, unless it has none, so that
+// it executes once however often it is sent. Once claimed, it runs to the end even if the
+// caller goes away, so that its claim gets a result. A claim left without a result for
+// staleClaimAge is resolved from the transfer keyed by the transaction, which is made if it
+// never was. Returns an error if the claim or the result could not be recorded, or if ctx is
+// done before the transaction is claimed.
+func (s *Service) executeTransaction(
+	ctx context.Context, messageID, debtor string, tx iso20022.CreditTransfer,
+) (iso20022.TransactionStatus, error) {
+	status := iso20022.TransactionStatus{
+		OriginalInstructionID: tx.InstructionID,
+		OriginalEndToEndID:    tx.EndToEndID,
+		Status:                iso20022.StatusRejected,
+	}
+	if status.Reason = checkTransaction(tx); status.Reason != nil {
+		return status, nil
+	}
+	if tx.EndToEndID == iso20022.NotProvided {
+		return s.transfer(ctx, debtor, tx, status), nil
+	}
+
+	if err := ctx.Err(); err != nil {
+		return status, err
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	claim := &models.PaymentTransaction{Debtor: debtor, EndToEndID: tx.EndToEndID, MessageID: messageID}
+	claimed, err := s.repo.ClaimTransaction(ctx, claim)
+	if err != nil {
+		return status, err
+	}
+	if !claimed {
+		if len(claim.Result) > 0 || s.now().Sub(claim.CreatedAt) < staleClaimAge {
+			return repeatedTransaction(claim, status)
+		}
+		s.logger.WarnContext(ctx, "resolving payment initiation transaction left without a result",
+			"end_to_end_id", tx.EndToEndID, "message_id", claim.MessageID, "claimed_at", claim.CreatedAt)
+	}
+
+	status = s.transfer(ctx, debtor, tx, status)
+	if claim.Result, err = json.Marshal(status); err != nil {
+		return status, err
+	}
+	return status, s.repo.CompleteTransaction(ctx, claim)
+}
+
+// transactionKey returns the idempotency key of the transfer made for a transaction of debtor
+func transactionKey(debtor, endToEndID string) string {
+	sum := sha256.Sum256([]byte(debtor + "\x00" + endToEndID))
+	return "pain001:" + hex.EncodeToString(sum[:])
+}
+
+// repeatedTransaction returns the status a transaction claimed before was reported with, or
+// rejects it as a duplicate if the earlier one has not finished
+func repeatedTransaction(
+	claim *models.PaymentTransaction, status iso20022.TransactionStatus,
+) (iso20022.TransactionStatus, error) {
+	if len(claim.Result) == 0 {
+		status.Reason = &iso20022.StatusReason{
+			Code: iso20022.ReasonDuplication,
+			Info: fmt.Sprintf("transaction %s of message %s has not finished", claim.EndToEndID, claim.MessageID),
+		}
+		return status, nil
+	}
+
+	instructionID := status.OriginalInstructionID
+	if err := json.Unmarshal(claim.Result, &status); err != nil {
+		return status, fmt.Errorf("reading result of transaction %s: %w", claim.EndToEndID, err)
+	}
+	status.OriginalInstructionID = instructionID
+	return status, nil
+}
+
+// transfer executes a credit transfer that passed its checks and fills in status accordingly
+// A transaction with an EndToEndId is keyed by it, so that the transfer made for it before, if
+// any, is reported as it is instead of being made again.
+func (s *Service) transfer(
+	ctx context.Context, debtor string, tx iso20022.CreditTransfer, status iso20022.TransactionStatus,
+) iso20022.TransactionStatus {
+	req := models.TransferRequest{
+		From:     debtor,
+		To:       tx.CreditorAccount.Other,
+		Amount:   tx.Amount,
+		Currency: tx.Currency,
+	}
+	if tx.EndToEndID != iso20022.NotProvided {
+		req.IdempotencyKey = transactionKey(debtor, tx.EndToEndID)
+	}
+	transfer, err := s.bankService.Transfer(ctx, req)
+	if transfer != nil {
+		status.TransferID = transfer.ID
+		status.AcceptedAt = transfer.CreatedAt
+	}
+	if errors.Is(err, transfererrors.ErrTransferHeld) {
+		status.Status = iso20022.StatusPending
+		status.Reason = transferReason(err)
+		return status
+	}
+	if err != nil {
+		s.logger.WarnContext(ctx, "payment initiation transaction failed", "end_to_end_id", tx.EndToEndID, "error", err)
+		status.Reason = transferReason(err)
+		return status
+	}
+
+	// A transfer made before is returned in whatever status it has reached
+	switch transfer.Status {
+	case models.TransferStatusCompleted:
+		status.Status = iso20022.StatusSettled
+	case models.TransferStatusFailed, models.TransferStatusReversed:
+		status.Reason = &iso20022.StatusReason{
+			Code: iso20022.ReasonNarrative,
+			Info: fmt.Sprintf("transfer %s is %s", transfer.ID, transfer.Status),
+		}
+	default:
+		status.Status = iso20022.StatusPending
+	}
+	return status
+}
+
+// checkTransaction returns why a credit transfer cannot be executed, or nil
+// The checks are those of the transfer API, reported as reason codes.
+func checkTransaction(tx iso20022.CreditTransfer) *iso20022.StatusReason {
+	switch {
+	case tx.Currency != models.Currency:
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonNotAllowedCurrency,
+			Info: fmt.Sprintf("currency %q is not supported, accounts are held in %s", tx.Currency, models.Currency),
+		}
+	case tx.Amount <= 0:
+		return &iso20022.StatusReason{Code: iso20022.ReasonZeroAmount, Info: "amount must be greater than zero"}
+	case !hasCents(tx.Amount):
+		return &iso20022.StatusReason{Code: iso20022.ReasonInvalidAmount, Info: "amount must have at most 2 decimal places"}
+	case tx.Amount > maxAmount:
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonNotAllowedAmount,
+			Info: fmt.Sprintf("amount must be at most %d", maxAmount),
+		}
+	case tx.CreditorAccount.Other == "":
+		return &iso20022.StatusReason{Code: iso20022.ReasonIncorrectAccount, Info: "creditor account must be given as Othr/Id"}
+	default:
+		return nil
+	}
+}
+
+// transferReason returns the reason a transfer that failed with err is reported with
+func transferReason(err error) *iso20022.StatusReason {
+	code := iso20022.ReasonNarrative
+	switch {
+	case errors.Is(err, transfererrors.ErrInsufficientFunds):
+		code = iso20022.ReasonInsufficientFunds
+	case errors.Is(err, transfererrors.ErrAccountNotFound), errors.Is(err, transfererrors.ErrInvalidAccountIdentifier),
+		errors.Is(err, transfererrors.ErrSystemAccount):
+		code = iso20022.ReasonIncorrectAccount
+	case errors.Is(err, transfererrors.ErrTransferBlocked), errors.Is(err, transfererrors.ErrTransferHeld):
+		code = iso20022.ReasonRegulatory
+	}
+	return &iso20022.StatusReason{Code: code, Info: err.Error()}
+}
+
+// checkTotals returns why the declared number of transactions or control sum of a group
+// differs from its transactions, or nil
+func checkTotals(count int, controlSum *float64, transactions []iso20022.CreditTransfer) *iso20022.StatusReason {
+	if count != len(transactions) {
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonInvalidNumberOfTxs,
+			Info: fmt.Sprintf("NbOfTxs is %d but %d transactions were given", count, len(transactions)),
+		}
+	}
+	if controlSum == nil {
+		return nil
+	}
+
+	var sum float64
+	for _, tx := range transactions {
+		sum += tx.Amount
+	}
+	if math.Round(sum*100) != math.Round(*controlSum*100) {
+		return &iso20022.StatusReason{
+			Code: iso20022.ReasonInvalidControlSum,
+			Info: fmt.Sprintf("CtrlSum is %.2f but the amounts add up to %.2f", *controlSum, sum),
+		}
+	}
+	return nil
+}
+
+// reject reports a payment instruction and all its transactions as rejected for reason
+// The reason is given once, for the instruction; it is nil when the whole message was rejected.
+func reject(payment iso20022.PaymentInstruction, reason *iso20022.StatusReason) iso20022.PaymentStatus {
+	status := iso20022.PaymentStatus{
+		OriginalPaymentID: payment.ID,
+		Status:            iso20022.StatusRejected,
+		Reason:            reason,
+	}
+	for _, tx := range payment.Transactions {
+		status.Transactions = append(status.Transactions, iso20022.TransactionStatus{
+			OriginalInstructionID: tx.InstructionID,
+			OriginalEndToEndID:    tx.EndToEndID,
+			Status:                iso20022.StatusRejected,
+		})
+	}
+	return status
+}
+
+// combine returns the status of a group made of parts with the given statuses
+// A group with parts pending and none rejected is pending as a whole.
+func combine(statuses []iso20022.Status) iso20022.Status {
+	var settled, pending, rejected int
+	for _, status := range statuses {
+		switch status {
+		case iso20022.StatusSettled:
+			settled++
+		case iso20022.StatusPending:
+			pending++
+		case iso20022.StatusRejected:
+			rejected++
+		}
+	}
+
+	switch {
+	case settled == len(statuses):
+		return iso20022.StatusSettled
+	case rejected == len(statuses):
+		return iso20022.StatusRejected
+	case pending > 0 && rejected == 0:
+		return iso20022.StatusPending
+	default:
+		return iso20022.StatusPartiallyAccepted
+	}
+}
+
+// hasCents reports whether amount has at most two decimal places
+func hasCents(amount float64) bool {
+	_, fraction, _ := strings.Cut(strconv.FormatFloat(amount, 'f', -1, 64), ".")
+	return len(fraction) <= 2
+}
@@ -0,0 +1,505 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/iso20022"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/service/mocks"
+	storagemocks "money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// today is the day the tests run the service on
+var today = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+// payroll is a message of one instruction in which Mark pays Jane and Adam
+func payroll() *iso20022.CreditTransferInitiation {
+	return &iso20022.CreditTransferInitiation{
+		MessageID:            "PAYROLL-2024-02",
+		CreatedAt:            today.Add(9 * time.Hour),
+		NumberOfTransactions: 2,
+		Payments: []iso20022.PaymentInstruction{
+			{
+				ID:            "PAYROLL-2024-02-1",
+				DebtorAccount: iso20022.Account{Other: "Mark"},
+				ExecutionDate: today,
+				Transactions: []iso20022.CreditTransfer{
+					{EndToEndID: "SALARY-JANE", Amount: 40.5, Currency: "USD", CreditorAccount: iso20022.Account{Other: "Jane"}},
+					{EndToEndID: "BONUS-ADAM", Amount: 10, Currency: "USD", CreditorAccount: iso20022.Account{Other: "Adam"}},
+				},
+			},
+		},
+	}
+}
+
+// transferred expects a transfer from Mark to the account to, completing with err
+// The idempotency key of the transfer is not checked.
+func transferred(bankService *mocks.BankServiceMock, to string, amount float64, err error) {
+	req := models.TransferRequest{From: "Mark", To: to, Amount: amount, Currency: "USD"}
+	status := models.TransferStatusCompleted
+	switch {
+	case errors.Is(err, transfererrors.ErrTransferHeld):
+		status = models.TransferStatusHeld
+	case err != nil:
+		status = models.TransferStatusFailed
+	}
+	transfer := &models.Transfer{
+		ID: fmt.Sprintf("00000000-0000-4000-8000-%012d", int(amount*100)), From: "Mark", To: to, Amount: amount,
+		Status: status, CreatedAt: today.Add(10 * time.Hour),
+	}
+	bankService.On("Transfer", mock.Anything, mock.MatchedBy(func(r models.TransferRequest) bool {
+		r.IdempotencyKey = ""
+		return r == req
+	})).Return(transfer, err).Once()
+}
+
+// newPaymentRepo returns a repository holding no messages and accepting every claim
+func newPaymentRepo(t *testing.T) *storagemocks.PaymentMessageRepository {
+	repo := storagemocks.NewPaymentMessageRepository(t)
+	repo.On("GetMessage", mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, transfererrors.ErrPaymentMessageNotFound).Maybe()
+	repo.On("SaveMessage", mock.Anything, mock.Anything).Return(nil).Maybe()
+	repo.On("ClaimTransaction", mock.Anything, mock.Anything).Return(true, nil).Maybe()
+	repo.On("CompleteTransaction", mock.Anything, mock.Anything).Return(nil).Maybe()
+	return repo
+}
+
+func TestBatchService_Execute(t *testing.T) {
+	mark := &models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}}
+
+	tests := []struct {
+		name            string
+		msg             func() *iso20022.CreditTransferInitiation
+		principal       *models.Principal
+		mock            func(*mocks.BankServiceMock)
+		wantGroup       iso20022.Status
+		wantGroupReason string
+		wantPayment     iso20022.Status
+		wantPaymentCode string
+		wantTxs         []iso20022.Status
+		wantTxCodes     []string
+	}{
+		{
+			name:      "all settled",
+			msg:       payroll,
+			principal: mark,
+			mock: func(b *mocks.BankServiceMock) {
+				transferred(b, "Jane", 40.5, nil)
+				transferred(b, "Adam", 10, nil)
+			},
+			wantGroup:   iso20022.StatusSettled,
+			wantPayment: iso20022.StatusSettled,
+			wantTxs:     []iso20022.Status{iso20022.StatusSettled, iso20022.StatusSettled},
+			wantTxCodes: []string{"", ""},
+		},
+		{
+			name:      "one transfer fails",
+			msg:       payroll,
+			principal: mark,
+			mock: func(b *mocks.BankServiceMock) {
+				transferred(b, "Jane", 40.5, nil)
+				transferred(b, "Adam", 10, transfererrors.ErrInsufficientFunds)
+			},
+			wantGroup:   iso20022.StatusPartiallyAccepted,
+			wantPayment: iso20022.StatusPartiallyAccepted,
+			wantTxs:     []iso20022.Status{iso20022.StatusSettled, iso20022.StatusRejected},
+			wantTxCodes: []string{"", iso20022.ReasonInsufficientFunds},
+		},
+		{
+			name:      "one transfer held for sanctions review",
+			msg:       payroll,
+			principal: mark,
+			mock: func(b *mocks.BankServiceMock) {
+				transferred(b, "Jane", 40.5, nil)
+				transferred(b, "Adam", 10, transfererrors.ErrTransferHeld)
+			},
+			wantGroup:   iso20022.StatusPending,
+			wantPayment: iso20022.StatusPending,
+			wantTxs:     []iso20022.Status{iso20022.StatusSettled, iso20022.StatusPending},
+			wantTxCodes: []string{"", iso20022.ReasonRegulatory},
+		},
+		{
+			name:      "one transfer blocked by sanctions screening",
+			msg:       payroll,
+			principal: mark,
+			mock: func(b *mocks.BankServiceMock) {
+				transferred(b, "Jane", 40.5, transfererrors.ErrTransferHeld)
+				transferred(b, "Adam", 10, transfererrors.ErrTransferBlocked)
+			},
+			wantGroup:   iso20022.StatusPartiallyAccepted,
+			wantPayment: iso20022.StatusPartiallyAccepted,
+			wantTxs:     []iso20022.Status{iso20022.StatusPending, iso20022.StatusRejected},
+			wantTxCodes: []string{iso20022.ReasonRegulatory, iso20022.ReasonRegulatory},
+		},
+		{
+			name: "transactions failing their checks",
+			msg: func() *iso20022.CreditTransferInitiation {
+				msg := payroll()
+				msg.Payments[0].Transactions[0].Currency = "EUR"
+				msg.Payments[0].Transactions[1].Amount = 10.005
+				return msg
+			},
+			principal:   mark,
+			mock:        func(_ *mocks.BankServiceMock) {},
+			wantGroup:   iso20022.StatusRejected,
+			wantPayment: iso20022.StatusRejected,
+			wantTxs:     []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes: []string{iso20022.ReasonNotAllowedCurrency, iso20022.ReasonInvalidAmount},
+		},
+		{
+			name: "unknown creditor",
+			msg: func() *iso20022.CreditTransferInitiation {
+				msg := payroll()
+				msg.Payments[0].Transactions = msg.Payments[0].Transactions[1:]
+				msg.NumberOfTransactions = 1
+				return msg
+			},
+			principal: mark,
+			mock: func(b *mocks.BankServiceMock) {
+				transferred(b, "Adam", 10, transfererrors.ErrAccountNotFound)
+			},
+			wantGroup:   iso20022.StatusRejected,
+			wantPayment: iso20022.StatusRejected,
+			wantTxs:     []iso20022.Status{iso20022.StatusRejected},
+			wantTxCodes: []string{iso20022.ReasonIncorrectAccount},
+		},
+		{
+			name:            "number of transactions mismatch",
+			msg:             func() *iso20022.CreditTransferInitiation { msg := payroll(); msg.NumberOfTransactions = 3; return msg },
+			principal:       mark,
+			mock:            func(_ *mocks.BankServiceMock) {},
+			wantGroup:       iso20022.StatusRejected,
+			wantGroupReason: iso20022.ReasonInvalidNumberOfTxs,
+			wantPayment:     iso20022.StatusRejected,
+			wantTxs:         []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes:     []string{"", ""},
+		},
+		{
+			name: "payment control sum mismatch",
+			msg: func() *iso20022.CreditTransferInitiation {
+				msg := payroll()
+				controlSum := 50.49
+				msg.Payments[0].ControlSum = &controlSum
+				return msg
+			},
+			principal:       mark,
+			mock:            func(_ *mocks.BankServiceMock) {},
+			wantGroup:       iso20022.StatusRejected,
+			wantPayment:     iso20022.StatusRejected,
+			wantPaymentCode: iso20022.ReasonInvalidControlSum,
+			wantTxs:         []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes:     []string{"", ""},
+		},
+		{
+			name:            "debtor account of someone else",
+			msg:             payroll,
+			principal:       &models.Principal{ID: "jane", Role: models.RoleCustomer, Accounts: []string{"Jane"}},
+			mock:            func(_ *mocks.BankServiceMock) {},
+			wantGroup:       iso20022.StatusRejected,
+			wantPayment:     iso20022.StatusRejected,
+			wantPaymentCode: iso20022.ReasonTransactionForbidden,
+			wantTxs:         []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes:     []string{"", ""},
+		},
+		{
+			name: "debtor account given as IBAN",
+			msg: func() *iso20022.CreditTransferInitiation {
+				msg := payroll()
+				msg.Payments[0].DebtorAccount = iso20022.Account{IBAN: "DE89370400440532013000"}
+				return msg
+			},
+			principal:       &models.Principal{ID: "admin", Role: models.RoleAdmin},
+			mock:            func(_ *mocks.BankServiceMock) {},
+			wantGroup:       iso20022.StatusRejected,
+			wantPayment:     iso20022.StatusRejected,
+			wantPaymentCode: iso20022.ReasonIncorrectAccount,
+			wantTxs:         []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes:     []string{"", ""},
+		},
+		{
+			name: "future execution date",
+			msg: func() *iso20022.CreditTransferInitiation {
+				msg := payroll()
+				msg.Payments[0].ExecutionDate = today.AddDate(0, 0, 1)
+				return msg
+			},
+			principal:       mark,
+			mock:            func(_ *mocks.BankServiceMock) {},
+			wantGroup:       iso20022.StatusRejected,
+			wantPayment:     iso20022.StatusRejected,
+			wantPaymentCode: iso20022.ReasonInvalidDate,
+			wantTxs:         []iso20022.Status{iso20022.StatusRejected, iso20022.StatusRejected},
+			wantTxCodes:     []string{"", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bankService := new(mocks.BankServiceMock)
+			tt.mock(bankService)
+			s := NewService(bankService, newPaymentRepo(t), logging.Discard())
+			s.now = func() time.Time { return today.Add(10 * time.Hour) }
+
+			ctx := auth.WithPrincipal(context.Background(), tt.principal)
+			report, err := s.Execute(ctx, tt.msg())
+			require.NoError(t, err)
+			bankService.AssertExpectations(t)
+
+			assert.Len(t, report.MessageID, 32)
+			assert.Equal(t, "PAYROLL-2024-02", report.OriginalMessageID)
+			assert.Equal(t, tt.wantGroup, report.GroupStatus)
+			assert.Equal(t, tt.wantGroupReason, reasonCode(report.GroupReason))
+
+			require.Len(t, report.Payments, 1)
+			payment := report.Payments[0]
+			assert.Equal(t, tt.wantPayment, payment.Status)
+			assert.Equal(t, tt.wantPaymentCode, reasonCode(payment.Reason))
+
+			var statuses []iso20022.Status
+			var codes []string
+			for _, tx := range payment.Transactions {
+				statuses = append(statuses, tx.Status)
+				codes = append(codes, reasonCode(tx.Reason))
+				if tx.Status == iso20022.StatusSettled || tx.Status == iso20022.StatusPending {
+					assert.NotEmpty(t, tx.TransferID)
+				}
+			}
+			assert.Equal(t, tt.wantTxs, statuses)
+			assert.Equal(t, tt.wantTxCodes, codes)
+		})
+	}
+}
+
+func TestBatchService_Execute_TooManyTransactions(t *testing.T) {
+	msg := payroll()
+	transactions := make([]iso20022.CreditTransfer, MaxTransactions+1)
+	msg.Payments[0].Transactions = transactions
+	msg.NumberOfTransactions = len(transactions)
+
+	s := NewService(new(mocks.BankServiceMock), storagemocks.NewPaymentMessageRepository(t), logging.Discard())
+	report, err := s.Execute(context.Background(), msg)
+	assert.ErrorIs(t, err, transfererrors.ErrInvalidPaymentMessage)
+	assert.Nil(t, report)
+}
+
+func TestBatchService_Execute_RepeatedMessage(t *testing.T) {
+	mark := &models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}}
+	ctx := auth.WithPrincipal(context.Background(), mark)
+
+	// The first execution is recorded with its report
+	bankService := new(mocks.BankServiceMock)
+	transferred(bankService, "Jane", 40.5, nil)
+	transferred(bankService, "Adam", 10, nil)
+	repo := newPaymentRepo(t)
+	s := NewService(bankService, repo, logging.Discard())
+	first, err := s.Execute(ctx, payroll())
+	require.NoError(t, err)
+
+	var saved *models.PaymentMessage
+	for _, call := range repo.Calls {
+		if call.Method == "SaveMessage" {
+			saved = call.Arguments.Get(1).(*models.PaymentMessage)
+		}
+	}
+	require.NotNil(t, saved)
+	assert.Equal(t, "mark", saved.Sender)
+	assert.Equal(t, "PAYROLL-2024-02", saved.MessageID)
+
+	t.Run("same message", func(t *testing.T) {
+		repo := storagemocks.NewPaymentMessageRepository(t)
+		repo.On("GetMessage", mock.Anything, "mark", "PAYROLL-2024-02").Return(saved, nil).Once()
+		s := NewService(new(mocks.BankServiceMock), repo, logging.Discard())
+
+		report, err := s.Execute(ctx, payroll())
+		require.NoError(t, err)
+		assert.Equal(t, first.MessageID, report.MessageID)
+		assert.Equal(t, iso20022.StatusSettled, report.GroupStatus)
+		require.Len(t, report.Payments, 1)
+		assert.Equal(t, first.Payments[0].Transactions, report.Payments[0].Transactions)
+	})
+
+	t.Run("message ID reused with other contents", func(t *testing.T) {
+		repo := storagemocks.NewPaymentMessageRepository(t)
+		repo.On("GetMessage", mock.Anything, "mark", "PAYROLL-2024-02").Return(saved, nil).Once()
+		s := NewService(new(mocks.BankServiceMock), repo, logging.Discard())
+
+		msg := payroll()
+		msg.Payments[0].Transactions[1].Amount = 20
+		report, err := s.Execute(ctx, msg)
+		assert.ErrorIs(t, err, transfererrors.ErrReferenceConflict)
+		assert.Nil(t, report)
+	})
+}
+
+func TestBatchService_Execute_RepeatedTransactions(t *testing.T) {
+	mark := &models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}}
+	ctx := auth.WithPrincipal(context.Background(), mark)
+	settled := iso20022.TransactionStatus{
+		OriginalEndToEndID: "SALARY-JANE",
+		Status:             iso20022.StatusSettled,
+		TransferID:         "00000000-0000-4000-8000-000000004050",
+		AcceptedAt:         today.Add(8 * time.Hour),
+	}
+
+	tests := []struct {
+		name      string
+		claimed   func(*models.PaymentTransaction)
+		mock      func(*mocks.BankServiceMock)
+		completed bool
+		wantTx    iso20022.TransactionStatus
+		wantCode  string
+	}{
+		{
+			name: "executed before",
+			claimed: func(claim *models.PaymentTransaction) {
+				claim.MessageID = "PAYROLL-2024-01"
+				claim.Result, _ = json.Marshal(settled)
+			},
+			mock:   func(_ *mocks.BankServiceMock) {},
+			wantTx: settled,
+		},
+		{
+			name: "still executing",
+			claimed: func(claim *models.PaymentTransaction) {
+				claim.MessageID = "PAYROLL-2024-01"
+				claim.CreatedAt = today.Add(10*time.Hour - time.Minute)
+			},
+			mock: func(_ *mocks.BankServiceMock) {},
+			wantTx: iso20022.TransactionStatus{
+				OriginalEndToEndID: "SALARY-JANE",
+				Status:             iso20022.StatusRejected,
+			},
+			wantCode: iso20022.ReasonDuplication,
+		},
+		{
+			name: "left without a result",
+			claimed: func(claim *models.PaymentTransaction) {
+				claim.MessageID = "PAYROLL-2024-01"
+				claim.CreatedAt = today.Add(8 * time.Hour)
+			},
+			// The transfer keyed by the transaction was made before the claim was left
+			mock: func(b *mocks.BankServiceMock) {
+				b.On("Transfer", mock.Anything, models.TransferRequest{
+					From: "Mark", To: "Jane", Amount: 40.5, Currency: "USD",
+					IdempotencyKey: transactionKey("Mark", "SALARY-JANE"),
+				}).Return(&models.Transfer{
+					ID: settled.TransferID, From: "Mark", To: "Jane", Amount: 40.5,
+					Status: models.TransferStatusCompleted, CreatedAt: settled.AcceptedAt,
+				}, nil).Once()
+			},
+			completed: true,
+			wantTx:    settled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bankService := new(mocks.BankServiceMock)
+			tt.mock(bankService)
+			transferred(bankService, "Adam", 10, nil)
+
+			repo := storagemocks.NewPaymentMessageRepository(t)
+			repo.On("GetMessage", mock.Anything, "mark", "PAYROLL-2024-02").
+				Return(nil, transfererrors.ErrPaymentMessageNotFound).Once()
+			repo.On("ClaimTransaction", mock.Anything, mock.MatchedBy(func(claim *models.PaymentTransaction) bool {
+				return claim.Debtor == "Mark" && claim.EndToEndID == "SALARY-JANE"
+			})).Run(func(args mock.Arguments) {
+				tt.claimed(args.Get(1).(*models.PaymentTransaction))
+			}).Return(false, nil).Once()
+			repo.On("ClaimTransaction", mock.Anything, mock.MatchedBy(func(claim *models.PaymentTransaction) bool {
+				return claim.Debtor == "Mark" && claim.EndToEndID == "BONUS-ADAM" && claim.MessageID == "PAYROLL-2024-02"
+			})).Return(true, nil).Once()
+			repo.On("CompleteTransaction", mock.Anything, mock.MatchedBy(func(claim *models.PaymentTransaction) bool {
+				return claim.EndToEndID == "BONUS-ADAM" && len(claim.Result) > 0
+			})).Return(nil).Once()
+			if tt.completed {
+				repo.On("CompleteTransaction", mock.Anything, mock.MatchedBy(func(claim *models.PaymentTransaction) bool {
+					return claim.EndToEndID == "SALARY-JANE" && len(claim.Result) > 0
+				})).Return(nil).Once()
+			}
+			repo.On("SaveMessage", mock.Anything, mock.Anything).Return(nil).Once()
+
+			s := NewService(bankService, repo, logging.Discard())
+			s.now = func() time.Time { return today.Add(10 * time.Hour) }
+			report, err := s.Execute(ctx, payroll())
+			require.NoError(t, err)
+			bankService.AssertExpectations(t)
+
+			require.Len(t, report.Payments, 1)
+			transactions := report.Payments[0].Transactions
+			require.Len(t, transactions, 2)
+			assert.Equal(t, tt.wantCode, reasonCode(transactions[0].Reason))
+			transactions[0].Reason = nil
+			assert.Equal(t, tt.wantTx, transactions[0])
+			assert.Equal(t, iso20022.StatusSettled, transactions[1].Status)
+		})
+	}
+
+	t.Run("no end-to-end reference", func(t *testing.T) {
+		bankService := new(mocks.BankServiceMock)
+		transferred(bankService, "Jane", 40.5, nil)
+		transferred(bankService, "Adam", 10, nil)
+		repo := newPaymentRepo(t)
+
+		msg := payroll()
+		msg.Payments[0].Transactions[0].EndToEndID = iso20022.NotProvided
+		msg.Payments[0].Transactions[1].EndToEndID = iso20022.NotProvided
+		s := NewService(bankService, repo, logging.Discard())
+		s.now = func() time.Time { return today.Add(10 * time.Hour) }
+		report, err := s.Execute(ctx, msg)
+		require.NoError(t, err)
+		assert.Equal(t, iso20022.StatusSettled, report.GroupStatus)
+		bankService.AssertExpectations(t)
+		repo.AssertNotCalled(t, "ClaimTransaction", mock.Anything, mock.Anything)
+	})
+}
+
+// reasonCode returns the code of an optional reason, or an empty string
+func reasonCode(reason *iso20022.StatusReason) string {
+	if reason == nil {
+		return ""
+	}
+	return reason.Code
+}
+
+func TestBatchService_Execute_CallerGoesAway(t *testing.T) {
+	mark := &models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}}
+	ctx, cancel := context.WithCancel(auth.WithPrincipal(context.Background(), mark))
+	defer cancel()
+
+	// The caller goes away while the first transaction executes
+	bankService := new(mocks.BankServiceMock)
+	transferred(bankService, "Jane", 40.5, nil)
+	bankService.ExpectedCalls[0].Run(func(mock.Arguments) { cancel() })
+
+	repo := storagemocks.NewPaymentMessageRepository(t)
+	repo.On("GetMessage", mock.Anything, "mark", "PAYROLL-2024-02").
+		Return(nil, transfererrors.ErrPaymentMessageNotFound).Once()
+	repo.On("ClaimTransaction", mock.Anything, mock.Anything).Return(true, nil).Once()
+	// Its result is still recorded, so that a resend is answered with it
+	repo.On("CompleteTransaction", mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil }),
+		mock.MatchedBy(func(claim *models.PaymentTransaction) bool {
+			return claim.EndToEndID == "SALARY-JANE" && len(claim.Result) > 0
+		})).Return(nil).Once()
+
+	s := NewService(bankService, repo, logging.Discard())
+	s.now = func() time.Time { return today.Add(10 * time.Hour) }
+	report, err := s.Execute(ctx, payroll())
+
+	// The next transaction is not claimed
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, report)
+	bankService.AssertExpectations(t)
+}
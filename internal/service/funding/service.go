@@ -0,0 +1,151 @@
+// Package funding moves money into and out of the ledger through system accounts
+package funding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/service"
+	"money-transfer/internal/service/bank"
+	"money-transfer/internal/storage"
+)
+
+// Config names the system accounts deposits and withdrawals may use
+type Config struct {
+	// SystemAccounts lists the system accounts fundings may name, such as cash and suspense
+	SystemAccounts []string
+	// DefaultAccount is used by fundings that name no system account
+	DefaultAccount string
+}
+
+// Service deposits funds into customer accounts and withdraws them
+// Deposits move money from a system account into a customer account and withdrawals move it
+// back, each as a transfer made through the bank service, so that they are screened and
+// observed like any other. Fundings are deduplicated by their external reference, so a deposit
+// reported twice is only credited once.
+type Service struct {
+	bankService service.BankService
+	accounts    storage.AccountRepository
+	fundings    storage.FundingRepository
+	config      Config
+	logger      *slog.Logger
+}
+
+// NewService creates a new instance of funding service moving funds through bankService
+func NewService(bankService service.BankService, store storage.Store, cfg Config, logger *slog.Logger) *Service {
+	return &Service{
+		bankService: bankService,
+		accounts:    store.Account(),
+		fundings:    store.Funding(),
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// Deposit credits the account of req with funds taken from a system account
+// created is false when a deposit with the same reference was made before; that deposit is
+// returned instead. A deposit held for sanctions review is returned unrecorded with
+// ErrTransferHeld; repeating it once the transfer was released records it.
+func (s *Service) Deposit(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error) {
+	return s.fund(ctx, models.FundingDeposit, req)
+}
+
+// Withdraw debits the account of req and moves the funds to a system account
+// created is false when a withdrawal with the same reference was made before; that withdrawal
+// is returned instead. A withdrawal held for sanctions review is returned unrecorded with
+// ErrTransferHeld; repeating it once the transfer was released records it.
+func (s *Service) Withdraw(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error) {
+	return s.fund(ctx, models.FundingWithdrawal, req)
+}
+
+// fund moves the funds of req in the direction of kind and records the funding once its
+// transfer completed
+// The transfer is keyed by kind and reference, so a funding repeated after its transfer was made
+// but before it was recorded does not move the funds again.
+func (s *Service) fund(ctx context.Context, kind models.FundingKind, req models.FundingRequest) (*models.Funding, bool, error) {
+	systemAccount := req.SystemAccount
+	if systemAccount == "" {
+		systemAccount = s.config.DefaultAccount
+	}
+	if !slices.Contains(s.config.SystemAccounts, systemAccount) {
+		return nil, false, transfererrors.ErrInvalidFundingAccount
+	}
+
+	funding := &models.Funding{
+		Kind:          kind,
+		Reference:     req.Reference,
+		Account:       req.Account,
+		SystemAccount: systemAccount,
+		Amount:        req.Amount,
+	}
+	if err := s.checkAccounts(ctx, funding); err != nil {
+		return nil, false, err
+	}
+
+	transfer, err := s.bankService.Transfer(bank.Internal(ctx), models.TransferRequest{
+		From:           funding.From(),
+		To:             funding.To(),
+		Amount:         funding.Amount,
+		IdempotencyKey: string(kind) + ":" + req.Reference,
+	})
+	// A repeated funding whose transfer is still held gets that transfer without an error
+	if errors.Is(err, transfererrors.ErrTransferHeld) || (err == nil && transfer.Status == models.TransferStatusHeld) {
+		funding.TransferID = transfer.ID
+		s.logger.InfoContext(ctx, "funding held for sanctions review", "kind", kind, "reference", req.Reference,
+			"transfer_id", transfer.ID)
+		return funding, true, transfererrors.ErrTransferHeld
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if transfer.Status != models.TransferStatusCompleted {
+		return nil, false, fmt.Errorf("transfer %s of %s %s is %s", transfer.ID, kind, req.Reference, transfer.Status)
+	}
+
+	funding.TransferID = transfer.ID
+	created, err := s.fundings.Record(ctx, funding)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if created {
+		s.logger.InfoContext(ctx, "funds moved", "kind", kind, "funding_id", funding.ID,
+			"account", funding.Account, "system_account", funding.SystemAccount, "amount", funding.Amount)
+	}
+	return funding, created, nil
+}
+
+// checkAccounts returns ErrInvalidFundingAccount unless the system account of funding is a
+// system account and its account is not, or ErrAccountNotFound if either does not exist
+func (s *Service) checkAccounts(ctx context.Context, funding *models.Funding) error {
+	accounts, err := s.accounts.GetAccounts(ctx, []string{funding.Account, funding.SystemAccount})
+	if err != nil {
+		return err
+	}
+	system := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		system[account.ID] = account.System
+	}
+
+	for _, account := range []struct {
+		id     string
+		system bool
+	}{
+		{funding.Account, false},
+		{funding.SystemAccount, true},
+	} {
+		isSystem, ok := system[account.id]
+		if !ok {
+			return transfererrors.ErrAccountNotFound
+		}
+		if isSystem != account.system {
+			return transfererrors.ErrInvalidFundingAccount
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package funding
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	servicemocks "money-transfer/internal/service/mocks"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{SystemAccounts: []string{"cash", "suspense"}, DefaultAccount: "cash"}
+
+func setup(t *testing.T) (*Service, *servicemocks.BankServiceMock, *mocks.FundingRepository) {
+	t.Helper()
+	bankService := new(servicemocks.BankServiceMock)
+	t.Cleanup(func() { bankService.AssertExpectations(t) })
+	accounts := mocks.NewAccountRepository(t)
+	accounts.On("GetAccounts", mock.Anything, mock.Anything).Return([]*models.Account{
+		{ID: "Mark"}, {ID: "Jane"}, {ID: "cash", System: true}, {ID: "suspense", System: true},
+	}, nil).Maybe()
+	fundings := mocks.NewFundingRepository(t)
+	store := &mocks.Store{}
+	store.On("Account").Return(accounts)
+	store.On("Funding").Return(fundings)
+	return NewService(bankService, store, testConfig, logging.Discard()), bankService, fundings
+}
+
+func TestService_Deposit(t *testing.T) {
+	service, bankService, fundings := setup(t)
+	ctx := context.Background()
+
+	bankService.On("Transfer", mock.Anything, models.TransferRequest{
+		From: "cash", To: "Mark", Amount: 40, IdempotencyKey: "deposit:dep-1",
+	}).Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+	fundings.On("Record", ctx, &models.Funding{
+		Kind: models.FundingDeposit, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 40, TransferID: "t-1",
+	}).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Funding).ID = "funding-1"
+	}).Return(true, nil).Once()
+
+	funding, created, err := service.Deposit(ctx, models.FundingRequest{Account: "Mark", Amount: 40, Reference: "dep-1"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "funding-1", funding.ID)
+	assert.Equal(t, "cash", funding.From())
+	assert.Equal(t, "Mark", funding.To())
+}
+
+func TestService_Withdraw(t *testing.T) {
+	tests := []struct {
+		name          string
+		account       string
+		systemAccount string
+		transfer      *models.Transfer
+		transferErr   error
+		wantCreated   bool
+		wantErr       error
+		wantFailed    bool
+	}{
+		{
+			name:          "withdrawn",
+			account:       "Mark",
+			systemAccount: "suspense",
+			transfer:      &models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted},
+			wantCreated:   true,
+		},
+		{
+			name:          "insufficient funds",
+			account:       "Mark",
+			systemAccount: "suspense",
+			transfer:      &models.Transfer{ID: "t-1", Status: models.TransferStatusFailed},
+			transferErr:   transfererrors.ErrInsufficientFunds,
+			wantErr:       transfererrors.ErrInsufficientFunds,
+		},
+		{
+			name:          "reference used for another withdrawal",
+			account:       "Mark",
+			systemAccount: "suspense",
+			transferErr:   transfererrors.ErrReferenceConflict,
+			wantErr:       transfererrors.ErrReferenceConflict,
+		},
+		{
+			name:          "transfer of the reference still processing",
+			account:       "Mark",
+			systemAccount: "suspense",
+			transfer:      &models.Transfer{ID: "t-1", Status: models.TransferStatusProcessing},
+			wantFailed:    true,
+		},
+		{name: "unconfigured system account", account: "Mark", systemAccount: "Jane", wantErr: transfererrors.ErrInvalidFundingAccount},
+		{
+			name:          "system account as customer account",
+			account:       "cash",
+			systemAccount: "suspense",
+			wantErr:       transfererrors.ErrInvalidFundingAccount,
+		},
+		{name: "missing account", account: "missing", systemAccount: "suspense", wantErr: transfererrors.ErrAccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, bankService, fundings := setup(t)
+			ctx := context.Background()
+
+			if tt.transfer != nil || tt.transferErr != nil {
+				bankService.On("Transfer", mock.Anything, models.TransferRequest{
+					From: tt.account, To: tt.systemAccount, Amount: 40, IdempotencyKey: "withdrawal:wd-1",
+				}).Return(tt.transfer, tt.transferErr).Once()
+			}
+			if tt.wantErr == nil && !tt.wantFailed {
+				fundings.On("Record", ctx, mock.MatchedBy(func(f *models.Funding) bool {
+					return f.Kind == models.FundingWithdrawal && f.SystemAccount == tt.systemAccount && f.TransferID == "t-1"
+				})).Return(true, nil).Once()
+			}
+
+			funding, created, err := service.Withdraw(ctx, models.FundingRequest{
+				Account: tt.account, Amount: 40, Reference: "wd-1", SystemAccount: tt.systemAccount,
+			})
+			if tt.wantErr != nil || tt.wantFailed {
+				assert.Error(t, err)
+				if tt.wantErr != nil {
+					assert.ErrorIs(t, err, tt.wantErr)
+				}
+				assert.Nil(t, funding)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+			assert.Equal(t, "Mark", funding.From())
+			assert.Equal(t, tt.systemAccount, funding.To())
+		})
+	}
+}
+
+func TestService_ReplayedReference(t *testing.T) {
+	service, bankService, fundings := setup(t)
+	ctx := context.Background()
+
+	// The bank service returns the transfer made before under the same key
+	bankService.On("Transfer", mock.Anything, mock.Anything).
+		Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusCompleted}, nil).Once()
+	fundings.On("Record", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(1).(*models.Funding).ID = "funding-1"
+	}).Return(false, nil).Once()
+
+	funding, created, err := service.Deposit(ctx, models.FundingRequest{Account: "Mark", Amount: 40, Reference: "dep-1"})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "funding-1", funding.ID)
+}
+
+func TestService_Held(t *testing.T) {
+	tests := []struct {
+		name        string
+		transferErr error
+	}{
+		{name: "held when made", transferErr: transfererrors.ErrTransferHeld},
+		{name: "repeated while held"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, bankService, _ := setup(t)
+			ctx := context.Background()
+
+			bankService.On("Transfer", mock.Anything, mock.Anything).
+				Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusHeld}, tt.transferErr).Once()
+
+			funding, _, err := service.Deposit(ctx, models.FundingRequest{Account: "Mark", Amount: 40, Reference: "dep-1"})
+			require.ErrorIs(t, err, transfererrors.ErrTransferHeld)
+			assert.Empty(t, funding.ID, "a held funding is not recorded")
+			assert.Equal(t, "t-1", funding.TransferID)
+		})
+	}
+}
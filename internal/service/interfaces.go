@@ -2,11 +2,64 @@ package service
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"money-transfer/internal/domain/models"
+	"money-transfer/internal/iso20022"
 )
 
 type BankService interface {
-	Transfer(ctx context.Context, req models.TransferRequest) error
+	Transfer(ctx context.Context, req models.TransferRequest) (*models.Transfer, error)
+	SubmitTransfer(ctx context.Context, req models.TransferRequest) (*models.Transfer, error)
+	GetTransfer(ctx context.Context, id string) (*models.Transfer, error)
+	AwaitTransfer(ctx context.Context, id string) (*models.Transfer, error)
 	GetBalance(ctx context.Context, accountID string) (float64, error)
+	BalanceAt(ctx context.Context, accountID string, at time.Time) (float64, error)
+	BalancesAt(ctx context.Context, accountIDs []string, at time.Time) (*models.BalanceSnapshot, error)
+	GetAccounts(ctx context.Context, ids []string) ([]*models.Account, error)
+	ListTransfers(ctx context.Context, accountID string, limit int) ([]*models.Transfer, error)
+	AccountActivity(ctx context.Context, accountID string, afterSequence int64, limit int) ([]*models.AccountActivity, error)
+	LatestActivitySequence(ctx context.Context) (int64, error)
+	Statement(ctx context.Context, accountID string, from, to time.Time) (*models.Statement, error)
+}
+
+type WebhookService interface {
+	CreateSubscription(ctx context.Context, req models.WebhookSubscriptionRequest) (*models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id string) error
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*models.WebhookDelivery, error)
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+type AuditService interface {
+	ListEvents(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error)
+}
+
+type BatchService interface {
+	Execute(ctx context.Context, msg *iso20022.CreditTransferInitiation) (*iso20022.PaymentStatusReport, error)
+}
+
+type PayoutService interface {
+	Pay(ctx context.Context, req models.PayoutRequest) (*models.Payout, error)
+	GetPayout(ctx context.Context, id string) (*models.Payout, error)
+	HandleCallback(ctx context.Context, rail string, callback models.RailCallback) (*models.Payout, error)
+}
+
+type ACHService interface {
+	CreateFile(ctx context.Context) (*models.ACHFile, error)
+	WriteFile(ctx context.Context, w io.Writer, id string) error
+	Settle(ctx context.Context, id string) (*models.ACHSettlement, error)
+	ProcessReturns(ctx context.Context, r io.Reader) ([]models.ACHReturn, error)
+}
+
+type FundingService interface {
+	Deposit(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error)
+	Withdraw(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error)
+}
+
+type ScreeningService interface {
+	ListScreeningDecisions(ctx context.Context, filter models.ScreeningFilter) ([]*models.ScreeningDecision, error)
+	ReviewTransfer(ctx context.Context, decisionID string, review models.ScreeningReview) (*models.Transfer, error)
 }
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"context"
+	"io"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ACHServiceMock struct {
+	mock.Mock
+}
+
+func (m *ACHServiceMock) CreateFile(ctx context.Context) (*models.ACHFile, error) {
+	args := m.Called(ctx)
+	file, _ := args.Get(0).(*models.ACHFile)
+	return file, args.Error(1)
+}
+
+func (m *ACHServiceMock) WriteFile(ctx context.Context, w io.Writer, id string) error {
+	args := m.Called(ctx, w, id)
+	return args.Error(0)
+}
+
+func (m *ACHServiceMock) Settle(ctx context.Context, id string) (*models.ACHSettlement, error) {
+	args := m.Called(ctx, id)
+	settlement, _ := args.Get(0).(*models.ACHSettlement)
+	return settlement, args.Error(1)
+}
+
+func (m *ACHServiceMock) ProcessReturns(ctx context.Context, r io.Reader) ([]models.ACHReturn, error) {
+	args := m.Called(ctx, r)
+	returns, _ := args.Get(0).([]models.ACHReturn)
+	return returns, args.Error(1)
+}
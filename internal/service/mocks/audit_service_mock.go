@@ -0,0 +1,18 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type AuditServiceMock struct {
+	mock.Mock
+}
+
+func (m *AuditServiceMock) ListEvents(ctx context.Context, filter models.AuditFilter) (*models.AuditPage, error) {
+	args := m.Called(ctx, filter)
+	page, _ := args.Get(0).(*models.AuditPage)
+	return page, args.Error(1)
+}
@@ -3,6 +3,7 @@ package mocks
 import (
 	"context"
 	"money-transfer/internal/domain/models"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -11,12 +12,77 @@ type BankServiceMock struct {
 	mock.Mock
 }
 
-func (m *BankServiceMock) Transfer(ctx context.Context, req models.TransferRequest) error {
+func (m *BankServiceMock) Transfer(ctx context.Context, req models.TransferRequest) (*models.Transfer, error) {
 	args := m.Called(ctx, req)
-	return args.Error(0)
+	transfer, _ := args.Get(0).(*models.Transfer)
+	return transfer, args.Error(1)
+}
+
+func (m *BankServiceMock) SubmitTransfer(ctx context.Context, req models.TransferRequest) (*models.Transfer, error) {
+	args := m.Called(ctx, req)
+	transfer, _ := args.Get(0).(*models.Transfer)
+	return transfer, args.Error(1)
+}
+
+func (m *BankServiceMock) GetTransfer(ctx context.Context, id string) (*models.Transfer, error) {
+	args := m.Called(ctx, id)
+	transfer, _ := args.Get(0).(*models.Transfer)
+	return transfer, args.Error(1)
+}
+
+func (m *BankServiceMock) AwaitTransfer(ctx context.Context, id string) (*models.Transfer, error) {
+	args := m.Called(ctx, id)
+	transfer, _ := args.Get(0).(*models.Transfer)
+	return transfer, args.Error(1)
 }
 
 func (m *BankServiceMock) GetBalance(ctx context.Context, accountID string) (float64, error) {
 	args := m.Called(ctx, accountID)
 	return args.Get(0).(float64), args.Error(1)
 }
+
+func (m *BankServiceMock) GetAccounts(ctx context.Context, ids []string) ([]*models.Account, error) {
+	args := m.Called(ctx, ids)
+	accounts, _ := args.Get(0).([]*models.Account)
+	return accounts, args.Error(1)
+}
+
+func (m *BankServiceMock) ListTransfers(ctx context.Context, accountID string, limit int) ([]*models.Transfer, error) {
+	args := m.Called(ctx, accountID, limit)
+	transfers, _ := args.Get(0).([]*models.Transfer)
+	return transfers, args.Error(1)
+}
+
+func (m *BankServiceMock) AccountActivity(
+	ctx context.Context, accountID string, afterSequence int64, limit int,
+) ([]*models.AccountActivity, error) {
+	args := m.Called(ctx, accountID, afterSequence, limit)
+	activities, _ := args.Get(0).([]*models.AccountActivity)
+	return activities, args.Error(1)
+}
+
+func (m *BankServiceMock) LatestActivitySequence(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *BankServiceMock) Statement(
+	ctx context.Context, accountID string, from, to time.Time,
+) (*models.Statement, error) {
+	args := m.Called(ctx, accountID, from, to)
+	statement, _ := args.Get(0).(*models.Statement)
+	return statement, args.Error(1)
+}
+
+func (m *BankServiceMock) BalanceAt(ctx context.Context, accountID string, at time.Time) (float64, error) {
+	args := m.Called(ctx, accountID, at)
+	return args.Get(0).(float64), args.Error(1)
+}
+
+func (m *BankServiceMock) BalancesAt(
+	ctx context.Context, accountIDs []string, at time.Time,
+) (*models.BalanceSnapshot, error) {
+	args := m.Called(ctx, accountIDs, at)
+	snapshot, _ := args.Get(0).(*models.BalanceSnapshot)
+	return snapshot, args.Error(1)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/iso20022"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type BatchServiceMock struct {
+	mock.Mock
+}
+
+func (m *BatchServiceMock) Execute(
+	ctx context.Context, msg *iso20022.CreditTransferInitiation,
+) (*iso20022.PaymentStatusReport, error) {
+	args := m.Called(ctx, msg)
+	report, _ := args.Get(0).(*iso20022.PaymentStatusReport)
+	return report, args.Error(1)
+}
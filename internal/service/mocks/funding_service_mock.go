@@ -0,0 +1,24 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type FundingServiceMock struct {
+	mock.Mock
+}
+
+func (m *FundingServiceMock) Deposit(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error) {
+	args := m.Called(ctx, req)
+	funding, _ := args.Get(0).(*models.Funding)
+	return funding, args.Bool(1), args.Error(2)
+}
+
+func (m *FundingServiceMock) Withdraw(ctx context.Context, req models.FundingRequest) (*models.Funding, bool, error) {
+	args := m.Called(ctx, req)
+	funding, _ := args.Get(0).(*models.Funding)
+	return funding, args.Bool(1), args.Error(2)
+}
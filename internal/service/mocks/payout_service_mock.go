@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type PayoutServiceMock struct {
+	mock.Mock
+}
+
+func (m *PayoutServiceMock) Pay(ctx context.Context, req models.PayoutRequest) (*models.Payout, error) {
+	args := m.Called(ctx, req)
+	payout, _ := args.Get(0).(*models.Payout)
+	return payout, args.Error(1)
+}
+
+func (m *PayoutServiceMock) GetPayout(ctx context.Context, id string) (*models.Payout, error) {
+	args := m.Called(ctx, id)
+	payout, _ := args.Get(0).(*models.Payout)
+	return payout, args.Error(1)
+}
+
+func (m *PayoutServiceMock) HandleCallback(
+	ctx context.Context, rail string, callback models.RailCallback,
+) (*models.Payout, error) {
+	args := m.Called(ctx, rail, callback)
+	payout, _ := args.Get(0).(*models.Payout)
+	return payout, args.Error(1)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type ScreeningServiceMock struct {
+	mock.Mock
+}
+
+func (m *ScreeningServiceMock) ListScreeningDecisions(
+	ctx context.Context, filter models.ScreeningFilter,
+) ([]*models.ScreeningDecision, error) {
+	args := m.Called(ctx, filter)
+	decisions, _ := args.Get(0).([]*models.ScreeningDecision)
+	return decisions, args.Error(1)
+}
+
+func (m *ScreeningServiceMock) ReviewTransfer(
+	ctx context.Context, decisionID string, review models.ScreeningReview,
+) (*models.Transfer, error) {
+	args := m.Called(ctx, decisionID, review)
+	transfer, _ := args.Get(0).(*models.Transfer)
+	return transfer, args.Error(1)
+}
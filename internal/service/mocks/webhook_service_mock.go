@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type WebhookServiceMock struct {
+	mock.Mock
+}
+
+func (m *WebhookServiceMock) CreateSubscription(
+	ctx context.Context, req models.WebhookSubscriptionRequest,
+) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, req)
+	subscription, _ := args.Get(0).(*models.WebhookSubscription)
+	return subscription, args.Error(1)
+}
+
+func (m *WebhookServiceMock) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	subscription, _ := args.Get(0).(*models.WebhookSubscription)
+	return subscription, args.Error(1)
+}
+
+func (m *WebhookServiceMock) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	args := m.Called(ctx)
+	subscriptions, _ := args.Get(0).([]*models.WebhookSubscription)
+	return subscriptions, args.Error(1)
+}
+
+func (m *WebhookServiceMock) DeleteSubscription(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *WebhookServiceMock) ListDeliveries(
+	ctx context.Context, subscriptionID string, limit int,
+) ([]*models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, limit)
+	deliveries, _ := args.Get(0).([]*models.WebhookDelivery)
+	return deliveries, args.Error(1)
+}
+
+func (m *WebhookServiceMock) Redeliver(
+	ctx context.Context, subscriptionID, deliveryID string,
+) (*models.WebhookDelivery, error) {
+	args := m.Called(ctx, subscriptionID, deliveryID)
+	delivery, _ := args.Get(0).(*models.WebhookDelivery)
+	return delivery, args.Error(1)
+}
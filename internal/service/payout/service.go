@@ -0,0 +1,350 @@
+// Package payout pays out from accounts of this ledger to accounts held at other banks
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/rail"
+	"money-transfer/internal/service"
+	"money-transfer/internal/service/bank"
+	"money-transfer/internal/storage"
+)
+
+// Config names the system accounts payouts move funds through
+type Config struct {
+	// ClearingAccount holds the funds of payouts until their rail settles or returns them
+	ClearingAccount string
+	// SettlementAccount receives the funds of settled payouts, which have left this ledger
+	SettlementAccount string
+}
+
+// reasonNotRecorded is the reason transfers into the clearing account are reversed when their
+// payout could not be recorded
+const reasonNotRecorded = "payout not recorded"
+
+// Service sends payouts through payment rails and applies the outcomes the rails report
+// A payout debits its source account by a transfer into the clearing account before it is
+// submitted. When the rail settles it, the funds move on to the settlement account; when the
+// rail refuses or returns it, the transfer is reversed.
+type Service struct {
+	bankService service.BankService
+	payouts     storage.PayoutRepository
+	transfers   storage.TransferRepository
+	rails       map[string]rail.PaymentRail
+	defaultRail string
+	config      Config
+	logger      *slog.Logger
+}
+
+// NewService creates a new instance of payout service sending payouts through rails
+// The first rail is used for payouts that name none.
+func NewService(
+	bankService service.BankService, store storage.Store, rails []rail.PaymentRail, cfg Config, logger *slog.Logger,
+) *Service {
+	s := &Service{
+		bankService: bankService,
+		payouts:     store.Payout(),
+		transfers:   store.Transfer(),
+		rails:       make(map[string]rail.PaymentRail, len(rails)),
+		config:      cfg,
+		logger:      logger,
+	}
+	for _, r := range rails {
+		s.rails[r.Name()] = r
+	}
+	if len(rails) > 0 {
+		s.defaultRail = rails[0].Name()
+	}
+	return s
+}
+
+// Pay moves the funds of req into the clearing account and submits the payout to its rail
+// The caller, read from ctx, must be allowed to access the source account. A payout the rail
+// refuses is returned failed, with its transfer reversed; err is only set when no payout was
+// made or its state could not be recorded. A payout whose transfer is held for sanctions
+// review is returned held; it is submitted by ResumeHeld once the transfer is released.
+// A request repeating the idempotency key of an earlier one of the caller gets the payout of
+// that request as it is now, without paying out again.
+func (s *Service) Pay(ctx context.Context, req models.PayoutRequest) (*models.Payout, error) {
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(req.From) {
+		return nil, transfererrors.ErrForbidden
+	}
+
+	name := req.Rail
+	if name == "" {
+		name = s.defaultRail
+	}
+	paymentRail, ok := s.rails[name]
+	if !ok {
+		return nil, transfererrors.ErrUnknownRail
+	}
+
+	// Keys are scoped to the caller, so that callers cannot get each other's payouts
+	var key string
+	if req.IdempotencyKey != "" {
+		key = "payout:" + principal.ID + ":" + req.IdempotencyKey
+	}
+	counterparty := req.Counterparty
+	transfer, err := s.bankService.Transfer(ctx, models.TransferRequest{
+		From:           req.From,
+		To:             s.config.ClearingAccount,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		Counterparty:   &counterparty,
+		IdempotencyKey: key,
+	})
+	// A repeated request whose transfer is still held gets that transfer without an error
+	held := errors.Is(err, transfererrors.ErrTransferHeld) || (err == nil && transfer.Status == models.TransferStatusHeld)
+	if err != nil && !held {
+		return nil, err
+	}
+	if !held && transfer.Status != models.TransferStatusCompleted {
+		// Only a repeated request gets a transfer that moved on, which made its payout already
+		payout, err := s.payouts.GetByTransfer(ctx, transfer.ID)
+		if errors.Is(err, transfererrors.ErrPayoutNotFound) {
+			return nil, fmt.Errorf("transfer %s of the payout is %s", transfer.ID, transfer.Status)
+		}
+		return payout, err
+	}
+
+	payout := &models.Payout{
+		TransferID:   transfer.ID,
+		From:         transfer.From,
+		Amount:       transfer.Amount,
+		Counterparty: *transfer.Counterparty,
+		Rail:         name,
+		Status:       models.PayoutStatusPending,
+	}
+	if held {
+		payout.Status = models.PayoutStatusHeld
+	}
+	err = s.payouts.Create(ctx, payout)
+	if errors.Is(err, transfererrors.ErrPayoutExists) {
+		// A repeated request gets the payout of its first attempt, which submits it
+		return payout, nil
+	}
+	if err != nil {
+		// Without a payout the funds would be stuck in the clearing account; a held transfer has
+		// not moved them yet and is reversed by ResumeHeld if it is released
+		if !held {
+			if reverseErr := s.transfers.Reverse(context.WithoutCancel(ctx), transfer.ID, reasonNotRecorded); reverseErr != nil {
+				s.logger.ErrorContext(ctx, "failed to reverse transfer of unrecorded payout",
+					"transfer_id", transfer.ID, "error", reverseErr)
+			}
+		}
+		return nil, err
+	}
+
+	if held {
+		s.logger.InfoContext(ctx, "payout held for sanctions review", "payout_id", payout.ID, "transfer_id", transfer.ID)
+		return payout, nil
+	}
+	return s.submit(ctx, paymentRail, payout)
+}
+
+// ResumeHeld carries on with the payout of a transfer resolved in sanctions review
+// The payout of a released transfer, which has moved its funds into the clearing account, is
+// submitted to its rail; the payout of a rejected transfer is marked failed. Transfers that made
+// no payout are ignored.
+func (s *Service) ResumeHeld(ctx context.Context, transfer *models.Transfer) error {
+	if transfer.To != s.config.ClearingAccount {
+		return nil
+	}
+
+	payout, err := s.payouts.GetByTransfer(ctx, transfer.ID)
+	if errors.Is(err, transfererrors.ErrPayoutNotFound) {
+		if transfer.Status != models.TransferStatusCompleted {
+			return nil
+		}
+		// The payout of the held transfer was never recorded; its funds must not stay in clearing
+		return s.transfers.Reverse(ctx, transfer.ID, reasonNotRecorded)
+	}
+	if err != nil {
+		return err
+	}
+	if payout.Status != models.PayoutStatusHeld {
+		return nil
+	}
+
+	if transfer.Status != models.TransferStatusCompleted {
+		payout.Status = models.PayoutStatusFailed
+		payout.FailureReason = transfer.FailureReason
+		return s.payouts.UpdateStatus(ctx, payout, models.PayoutStatusHeld)
+	}
+
+	paymentRail, ok := s.rails[payout.Rail]
+	if !ok {
+		return s.reverse(ctx, payout, models.PayoutStatusFailed, transfererrors.ErrUnknownRail.Error())
+	}
+	payout.Status = models.PayoutStatusPending
+	if err := s.payouts.UpdateStatus(ctx, payout, models.PayoutStatusHeld); err != nil {
+		return err
+	}
+	_, err = s.submit(ctx, paymentRail, payout)
+	return err
+}
+
+// submit hands a pending payout to its rail and records the outcome
+// A payout the rail refuses is returned failed, with its transfer reversed.
+func (s *Service) submit(ctx context.Context, paymentRail rail.PaymentRail, payout *models.Payout) (*models.Payout, error) {
+	// A rail that accepted the payout must have it recorded, even if the caller went away meanwhile
+	ctx = context.WithoutCancel(ctx)
+	reference, err := paymentRail.Submit(ctx, payout)
+	if err != nil {
+		s.logger.WarnContext(ctx, "payout refused by rail", "payout_id", payout.ID, "rail", payout.Rail, "error", err)
+		if err := s.reverse(ctx, payout, models.PayoutStatusFailed, err.Error()); err != nil {
+			return nil, err
+		}
+		return payout, nil
+	}
+
+	payout.Status = models.PayoutStatusSubmitted
+	payout.RailReference = reference
+	if err := s.payouts.UpdateStatus(ctx, payout, models.PayoutStatusPending); err != nil {
+		s.logger.ErrorContext(ctx, "failed to record submitted payout",
+			"payout_id", payout.ID, "rail", payout.Rail, "reference", reference, "error", err)
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "payout submitted", "payout_id", payout.ID, "rail", payout.Rail, "reference", reference)
+	return payout, nil
+}
+
+// GetPayout returns the current state of a payout
+// The caller, read from ctx, must be allowed to access its source account.
+func (s *Service) GetPayout(ctx context.Context, id string) (*models.Payout, error) {
+	payout, err := s.payouts.GetPayout(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, ok := auth.PrincipalFromContext(ctx)
+	if !ok || !principal.CanAccessAccount(payout.From) {
+		return nil, transfererrors.ErrForbidden
+	}
+	return payout, nil
+}
+
+// HandleCallback applies the outcome the rail named railName reported for one of its payouts
+// Reporting the outcome a payout already has again returns the payout unchanged, so that rails
+// may retry their callbacks. Returns ErrPayoutNotFound until the payout was recorded as submitted.
+func (s *Service) HandleCallback(ctx context.Context, railName string, callback models.RailCallback) (*models.Payout, error) {
+	if _, ok := s.rails[railName]; !ok {
+		return nil, transfererrors.ErrUnknownRail
+	}
+
+	payout, err := s.payouts.GetByReference(ctx, railName, callback.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if payout.Status == callback.Status {
+		return payout, nil
+	}
+	if !payout.Status.CanTransitionTo(callback.Status) {
+		return nil, transfererrors.ErrInvalidStatusTransition
+	}
+
+	switch callback.Status {
+	case models.PayoutStatusSettled:
+		err = s.settle(ctx, payout)
+	case models.PayoutStatusReturned:
+		if payout.Status == models.PayoutStatusSettled {
+			err = s.returnSettled(ctx, payout, callback.Reason)
+		} else {
+			err = s.reverse(ctx, payout, models.PayoutStatusReturned, callback.Reason)
+		}
+	default:
+		err = transfererrors.ErrInvalidStatusTransition
+	}
+	if errors.Is(err, transfererrors.ErrInvalidStatusTransition) {
+		// A concurrent callback got there first
+		return s.payouts.GetByReference(ctx, railName, callback.Reference)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "payout outcome applied", "payout_id", payout.ID, "rail", railName, "status", payout.Status)
+	return payout, nil
+}
+
+// settle moves the funds of a submitted payout on to the settlement account and marks it settled
+// The settlement transfer is keyed by the payout, so that the funds move once however often
+// the callback is retried or raced, including after they moved but the payout was not marked.
+func (s *Service) settle(ctx context.Context, payout *models.Payout) error {
+	transfer, err := s.bankService.Transfer(bank.Internal(ctx), models.TransferRequest{
+		From:           s.config.ClearingAccount,
+		To:             s.config.SettlementAccount,
+		Amount:         payout.Amount,
+		Currency:       models.Currency,
+		IdempotencyKey: "payout-settlement:" + payout.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferStatusCompleted {
+		return fmt.Errorf("settlement transfer %s of payout %s is %s", transfer.ID, payout.ID, transfer.Status)
+	}
+
+	previous := payout.Status
+	payout.Status = models.PayoutStatusSettled
+	payout.SettlementTransferID = transfer.ID
+	return s.payouts.UpdateStatus(ctx, payout, previous)
+}
+
+// returnSettled moves the funds of a settled payout the rail sent back from the settlement
+// account to its source account and marks it returned
+// The return transfer is keyed by the payout, so that the funds move back once however often
+// the return is reported, including after they moved but the payout was not marked.
+func (s *Service) returnSettled(ctx context.Context, payout *models.Payout, reason string) error {
+	transfer, err := s.bankService.Transfer(bank.Internal(ctx), models.TransferRequest{
+		From:           s.config.SettlementAccount,
+		To:             payout.From,
+		Amount:         payout.Amount,
+		Currency:       models.Currency,
+		IdempotencyKey: "payout-return:" + payout.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if transfer.Status != models.TransferStatusCompleted {
+		return fmt.Errorf("return transfer %s of payout %s is %s", transfer.ID, payout.ID, transfer.Status)
+	}
+
+	previous := payout.Status
+	payout.Status = models.PayoutStatusReturned
+	payout.FailureReason = reason
+	return s.payouts.UpdateStatus(ctx, payout, previous)
+}
+
+// reverse moves the funds of a payout back to its source account and marks it status
+// Reversing the transfer fails with ErrInvalidStatusTransition once it was reversed, so a
+// payout is never reversed twice; a transfer found reversed already, by an attempt that did not
+// get to mark the payout, still has the payout marked.
+func (s *Service) reverse(ctx context.Context, payout *models.Payout, status models.PayoutStatus, reason string) error {
+	err := s.transfers.Reverse(ctx, payout.TransferID, reason)
+	if errors.Is(err, transfererrors.ErrInvalidStatusTransition) {
+		transfer, getErr := s.transfers.GetTransfer(ctx, payout.TransferID)
+		if getErr != nil {
+			return getErr
+		}
+		if transfer.Status == models.TransferStatusReversed {
+			err = nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	previous := payout.Status
+	payout.Status = status
+	payout.FailureReason = reason
+	return s.payouts.UpdateStatus(ctx, payout, previous)
+}
@@ -0,0 +1,547 @@
+package payout
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/nacha"
+	"money-transfer/internal/rail"
+	servicemocks "money-transfer/internal/service/mocks"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{ClearingAccount: "clearing", SettlementAccount: "settlement"}
+
+var jane = models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "GB82WEST12345698765432", Name: "Jane Doe"}
+
+// testRail is a payment rail refusing payouts with err, or accepting them under reference
+type testRail struct {
+	reference string
+	err       error
+	// contextErr, when set, receives the error of the context payouts are submitted under
+	contextErr *error
+}
+
+func (r testRail) Name() string { return "test" }
+
+func (r testRail) Submit(ctx context.Context, _ *models.Payout) (string, error) {
+	if r.contextErr != nil {
+		*r.contextErr = ctx.Err()
+	}
+	return r.reference, r.err
+}
+
+type fixture struct {
+	bank      *servicemocks.BankServiceMock
+	payouts   *mocks.PayoutRepository
+	transfers *mocks.TransferRepository
+	service   *Service
+}
+
+func setup(t *testing.T, paymentRail rail.PaymentRail) *fixture {
+	t.Helper()
+	f := &fixture{
+		bank:      &servicemocks.BankServiceMock{},
+		payouts:   mocks.NewPayoutRepository(t),
+		transfers: mocks.NewTransferRepository(t),
+	}
+	store := &mocks.Store{}
+	store.On("Payout").Return(f.payouts)
+	store.On("Transfer").Return(f.transfers)
+	f.service = NewService(f.bank, store, []rail.PaymentRail{paymentRail}, testConfig, logging.Discard())
+	t.Cleanup(func() { f.bank.AssertExpectations(t) })
+	return f
+}
+
+// markContext is the context of a request by the owner of Mark
+func markContext() context.Context {
+	return auth.WithPrincipal(context.Background(),
+		&models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}})
+}
+
+// expectClearing makes the bank service move 25 from Mark into the clearing account as transfer t-1
+func expectClearing(f *fixture) {
+	counterparty := jane
+	f.bank.On("Transfer", mock.Anything, models.TransferRequest{
+		From: "Mark", To: "clearing", Amount: 25, Counterparty: &counterparty,
+	}).Return(&models.Transfer{
+		ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusCompleted, Counterparty: &counterparty,
+	}, nil).Once()
+	f.payouts.On("Create", mock.Anything, mock.AnythingOfType("*models.Payout")).
+		Run(func(args mock.Arguments) { args.Get(1).(*models.Payout).ID = "p-1" }).
+		Return(nil).Once()
+}
+
+// statusUpdate matches a payout update to status
+func statusUpdate(status models.PayoutStatus) any {
+	return mock.MatchedBy(func(p *models.Payout) bool { return p.Status == status })
+}
+
+func TestPayoutService_Pay(t *testing.T) {
+	req := models.PayoutRequest{From: "Mark", Amount: 25, Counterparty: jane}
+
+	t.Run("submitted", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		expectClearing(f)
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSubmitted), models.PayoutStatusPending).
+			Return(nil).Once()
+
+		payout, err := f.service.Pay(markContext(), req)
+		require.NoError(t, err)
+		assert.Equal(t, "p-1", payout.ID)
+		assert.Equal(t, "t-1", payout.TransferID)
+		assert.Equal(t, "test", payout.Rail)
+		assert.Equal(t, "REF-1", payout.RailReference)
+		assert.Equal(t, models.PayoutStatusSubmitted, payout.Status)
+		assert.Equal(t, jane, payout.Counterparty)
+	})
+
+	t.Run("refused by the rail", func(t *testing.T) {
+		f := setup(t, testRail{err: errors.New("beneficiary bank unreachable")})
+		expectClearing(f)
+		f.transfers.On("Reverse", mock.Anything, "t-1", "beneficiary bank unreachable").Return(nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusFailed), models.PayoutStatusPending).
+			Return(nil).Once()
+
+		payout, err := f.service.Pay(markContext(), req)
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusFailed, payout.Status)
+		assert.Equal(t, "beneficiary bank unreachable", payout.FailureReason)
+	})
+
+	t.Run("submitted after the caller went away", func(t *testing.T) {
+		var contextErr error
+		f := setup(t, testRail{reference: "REF-1", contextErr: &contextErr})
+		expectClearing(f)
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSubmitted), models.PayoutStatusPending).
+			Return(nil).Once()
+		ctx, cancel := context.WithCancel(markContext())
+		cancel()
+
+		payout, err := f.service.Pay(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusSubmitted, payout.Status)
+		assert.NoError(t, contextErr, "the rail is not told the caller went away")
+	})
+
+	t.Run("with an idempotency key", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.bank.On("Transfer", mock.Anything, mock.MatchedBy(func(req models.TransferRequest) bool {
+			return req.IdempotencyKey == "payout:mark:k-1"
+		})).Return(&models.Transfer{
+			ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusCompleted, Counterparty: &jane,
+		}, nil).Once()
+		f.payouts.On("Create", mock.Anything, mock.AnythingOfType("*models.Payout")).Return(nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSubmitted), models.PayoutStatusPending).
+			Return(nil).Once()
+
+		keyed := req
+		keyed.IdempotencyKey = "k-1"
+		payout, err := f.service.Pay(markContext(), keyed)
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusSubmitted, payout.Status)
+	})
+
+	t.Run("repeated with an idempotency key", func(t *testing.T) {
+		// The payout is not submitted again, which this rail would refuse
+		f := setup(t, testRail{err: errors.New("submitted twice")})
+		f.bank.On("Transfer", mock.Anything, mock.MatchedBy(func(req models.TransferRequest) bool {
+			return req.IdempotencyKey == "payout:mark:k-1"
+		})).Return(&models.Transfer{
+			ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusCompleted, Counterparty: &jane,
+		}, nil).Once()
+		existing := &models.Payout{ID: "p-1", TransferID: "t-1", Status: models.PayoutStatusSettled, RailReference: "REF-1"}
+		f.payouts.On("Create", mock.Anything, mock.AnythingOfType("*models.Payout")).
+			Run(func(args mock.Arguments) { *args.Get(1).(*models.Payout) = *existing }).
+			Return(transfererrors.ErrPayoutExists).Once()
+
+		keyed := req
+		keyed.IdempotencyKey = "k-1"
+		payout, err := f.service.Pay(markContext(), keyed)
+		require.NoError(t, err)
+		assert.Equal(t, existing, payout)
+	})
+
+	t.Run("repeated after the rail refused it", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.bank.On("Transfer", mock.Anything, mock.Anything).Return(&models.Transfer{
+			ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusReversed, Counterparty: &jane,
+		}, nil).Once()
+		failed := &models.Payout{ID: "p-1", TransferID: "t-1", Status: models.PayoutStatusFailed}
+		f.payouts.On("GetByTransfer", mock.Anything, "t-1").Return(failed, nil).Once()
+
+		keyed := req
+		keyed.IdempotencyKey = "k-1"
+		payout, err := f.service.Pay(markContext(), keyed)
+		require.NoError(t, err)
+		assert.Equal(t, failed, payout)
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.bank.On("Transfer", mock.Anything, mock.Anything).
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusFailed}, transfererrors.ErrInsufficientFunds).Once()
+
+		_, err := f.service.Pay(markContext(), req)
+		assert.ErrorIs(t, err, transfererrors.ErrInsufficientFunds)
+	})
+
+	t.Run("held for sanctions review", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		counterparty := jane
+		f.bank.On("Transfer", mock.Anything, mock.Anything).Return(&models.Transfer{
+			ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusHeld, Counterparty: &counterparty,
+		}, transfererrors.ErrTransferHeld).Once()
+		f.payouts.On("Create", mock.Anything, statusUpdate(models.PayoutStatusHeld)).Return(nil).Once()
+
+		payout, err := f.service.Pay(markContext(), req)
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusHeld, payout.Status)
+		assert.Equal(t, "t-1", payout.TransferID)
+	})
+
+	t.Run("blocked by sanctions screening", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.bank.On("Transfer", mock.Anything, mock.Anything).
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusFailed}, transfererrors.ErrTransferBlocked).Once()
+
+		_, err := f.service.Pay(markContext(), req)
+		assert.ErrorIs(t, err, transfererrors.ErrTransferBlocked)
+	})
+
+	t.Run("account of another principal", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+
+		_, err := f.service.Pay(markContext(), models.PayoutRequest{From: "Jane", Amount: 25, Counterparty: jane})
+		assert.ErrorIs(t, err, transfererrors.ErrForbidden)
+	})
+
+	t.Run("unknown rail", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+
+		_, err := f.service.Pay(markContext(), models.PayoutRequest{From: "Mark", Amount: 25, Counterparty: jane, Rail: "swift"})
+		assert.ErrorIs(t, err, transfererrors.ErrUnknownRail)
+	})
+}
+
+func TestPayoutService_ResumeHeld(t *testing.T) {
+	held := func() *models.Payout {
+		return &models.Payout{ID: "p-1", TransferID: "t-1", From: "Mark", Amount: 25, Rail: "test", Status: models.PayoutStatusHeld}
+	}
+	transfer := func(status models.TransferStatus, reason string) *models.Transfer {
+		return &models.Transfer{ID: "t-1", From: "Mark", To: "clearing", Amount: 25, Status: status, FailureReason: reason}
+	}
+
+	t.Run("released", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.payouts.On("GetByTransfer", mock.Anything, "t-1").Return(held(), nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusPending), models.PayoutStatusHeld).
+			Return(nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSubmitted), models.PayoutStatusPending).
+			Return(nil).Once()
+
+		require.NoError(t, f.service.ResumeHeld(context.Background(), transfer(models.TransferStatusCompleted, "")))
+	})
+
+	t.Run("released and refused by the rail", func(t *testing.T) {
+		f := setup(t, testRail{err: errors.New("beneficiary bank unreachable")})
+		f.payouts.On("GetByTransfer", mock.Anything, "t-1").Return(held(), nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusPending), models.PayoutStatusHeld).
+			Return(nil).Once()
+		f.transfers.On("Reverse", mock.Anything, "t-1", "beneficiary bank unreachable").Return(nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusFailed), models.PayoutStatusPending).
+			Return(nil).Once()
+
+		require.NoError(t, f.service.ResumeHeld(context.Background(), transfer(models.TransferStatusCompleted, "")))
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.payouts.On("GetByTransfer", mock.Anything, "t-1").Return(held(), nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, mock.MatchedBy(func(p *models.Payout) bool {
+			return p.Status == models.PayoutStatusFailed && p.FailureReason == "rejected in sanctions review"
+		}), models.PayoutStatusHeld).Return(nil).Once()
+
+		require.NoError(t, f.service.ResumeHeld(context.Background(),
+			transfer(models.TransferStatusFailed, "rejected in sanctions review")))
+	})
+
+	t.Run("payout never recorded", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+		f.payouts.On("GetByTransfer", mock.Anything, "t-1").Return(nil, transfererrors.ErrPayoutNotFound).Once()
+		f.transfers.On("Reverse", mock.Anything, "t-1", "payout not recorded").Return(nil).Once()
+
+		require.NoError(t, f.service.ResumeHeld(context.Background(), transfer(models.TransferStatusCompleted, "")))
+	})
+
+	t.Run("not a payout", func(t *testing.T) {
+		f := setup(t, testRail{reference: "REF-1"})
+
+		require.NoError(t, f.service.ResumeHeld(context.Background(),
+			&models.Transfer{ID: "t-2", From: "Mark", To: "Jane", Status: models.TransferStatusCompleted}))
+	})
+}
+
+func TestPayoutService_HandleCallback(t *testing.T) {
+	submitted := func() *models.Payout {
+		return &models.Payout{
+			ID: "p-1", TransferID: "t-1", From: "Mark", Amount: 25, Counterparty: jane,
+			Rail: "test", Status: models.PayoutStatusSubmitted, RailReference: "REF-1",
+		}
+	}
+
+	t.Run("settled", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.bank.On("Transfer", mock.Anything, models.TransferRequest{
+			From: "clearing", To: "settlement", Amount: 25, Currency: models.Currency, IdempotencyKey: "payout-settlement:p-1",
+		}).Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusCompleted}, nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSettled), models.PayoutStatusSubmitted).
+			Return(nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled})
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusSettled, payout.Status)
+		assert.Equal(t, "t-2", payout.SettlementTransferID)
+	})
+
+	t.Run("settled by a concurrent callback", func(t *testing.T) {
+		f := setup(t, testRail{})
+		settled := submitted()
+		settled.Status = models.PayoutStatusSettled
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		// Both callbacks get the one settlement transfer keyed by the payout
+		f.bank.On("Transfer", mock.Anything, mock.Anything).
+			Return(&models.Transfer{ID: "t-3", Status: models.TransferStatusCompleted}, nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, mock.Anything, models.PayoutStatusSubmitted).
+			Return(transfererrors.ErrInvalidStatusTransition).Once()
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(settled, nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled})
+		require.NoError(t, err)
+		assert.Equal(t, settled, payout)
+	})
+
+	t.Run("retried after the settlement was not recorded", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.bank.On("Transfer", mock.Anything, mock.MatchedBy(func(req models.TransferRequest) bool {
+			return req.IdempotencyKey == "payout-settlement:p-1"
+		})).Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusCompleted}, nil).Twice()
+		f.payouts.On("UpdateStatus", mock.Anything, mock.Anything, models.PayoutStatusSubmitted).
+			Return(assert.AnError).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusSettled), models.PayoutStatusSubmitted).
+			Return(nil).Once()
+
+		callback := models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled}
+		_, err := f.service.HandleCallback(context.Background(), "test", callback)
+		require.ErrorIs(t, err, assert.AnError)
+		payout, err := f.service.HandleCallback(context.Background(), "test", callback)
+		require.NoError(t, err)
+		assert.Equal(t, "t-2", payout.SettlementTransferID)
+	})
+
+	t.Run("settlement transfer not completed", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.bank.On("Transfer", mock.Anything, mock.Anything).
+			Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusProcessing}, nil).Once()
+
+		_, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled})
+		assert.ErrorContains(t, err, "settlement transfer t-2 of payout p-1 is processing")
+	})
+
+	t.Run("returned", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.transfers.On("Reverse", mock.Anything, "t-1", "account closed").Return(nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusReturned), models.PayoutStatusSubmitted).
+			Return(nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusReturned, Reason: "account closed"})
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusReturned, payout.Status)
+		assert.Equal(t, "account closed", payout.FailureReason)
+	})
+
+	t.Run("returned after settlement", func(t *testing.T) {
+		f := setup(t, testRail{})
+		settled := submitted()
+		settled.Status = models.PayoutStatusSettled
+		settled.SettlementTransferID = "t-2"
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(settled, nil).Once()
+		f.bank.On("Transfer", mock.Anything, models.TransferRequest{
+			From: "settlement", To: "Mark", Amount: 25, Currency: models.Currency, IdempotencyKey: "payout-return:p-1",
+		}).Return(&models.Transfer{ID: "t-3", Status: models.TransferStatusCompleted}, nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusReturned), models.PayoutStatusSettled).
+			Return(nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusReturned, Reason: "R01: Insufficient funds"})
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusReturned, payout.Status)
+		assert.Equal(t, "R01: Insufficient funds", payout.FailureReason)
+	})
+
+	t.Run("retried after the return was not recorded", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(submitted(), nil).Once()
+		f.transfers.On("Reverse", mock.Anything, "t-1", "account closed").
+			Return(transfererrors.ErrInvalidStatusTransition).Once()
+		f.transfers.On("GetTransfer", mock.Anything, "t-1").
+			Return(&models.Transfer{ID: "t-1", Status: models.TransferStatusReversed}, nil).Once()
+		f.payouts.On("UpdateStatus", mock.Anything, statusUpdate(models.PayoutStatusReturned), models.PayoutStatusSubmitted).
+			Return(nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusReturned, Reason: "account closed"})
+		require.NoError(t, err)
+		assert.Equal(t, models.PayoutStatusReturned, payout.Status)
+	})
+
+	t.Run("repeated", func(t *testing.T) {
+		f := setup(t, testRail{})
+		returned := submitted()
+		returned.Status = models.PayoutStatusReturned
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(returned, nil).Once()
+
+		payout, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusReturned})
+		require.NoError(t, err)
+		assert.Equal(t, returned, payout)
+	})
+
+	t.Run("contradicting a final outcome", func(t *testing.T) {
+		f := setup(t, testRail{})
+		returned := submitted()
+		returned.Status = models.PayoutStatusReturned
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-1").Return(returned, nil).Once()
+
+		_, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled})
+		assert.ErrorIs(t, err, transfererrors.ErrInvalidStatusTransition)
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		f := setup(t, testRail{})
+		f.payouts.On("GetByReference", mock.Anything, "test", "REF-9").Return(nil, transfererrors.ErrPayoutNotFound).Once()
+
+		_, err := f.service.HandleCallback(context.Background(), "test",
+			models.RailCallback{Reference: "REF-9", Status: models.PayoutStatusSettled})
+		assert.ErrorIs(t, err, transfererrors.ErrPayoutNotFound)
+	})
+
+	t.Run("unknown rail", func(t *testing.T) {
+		f := setup(t, testRail{})
+
+		_, err := f.service.HandleCallback(context.Background(), "swift",
+			models.RailCallback{Reference: "REF-1", Status: models.PayoutStatusSettled})
+		assert.ErrorIs(t, err, transfererrors.ErrUnknownRail)
+	})
+}
+
+func TestPayoutService_ACHReturnAfterSettlement(t *testing.T) {
+	achRepo := &mocks.ACHRepository{}
+	ach, err := rail.NewACH(rail.ACHConfig{
+		Destination: "021000021",
+		Origin:      "1234567890",
+		CompanyName: "MONEY TRANSFER",
+		CompanyID:   "1234567890",
+		ODFI:        "011000015",
+	}, achRepo, logging.Discard())
+	require.NoError(t, err)
+	f := setup(t, ach)
+	ach.OnOutcome(func(ctx context.Context, name string, callback models.RailCallback) error {
+		_, err := f.service.HandleCallback(ctx, name, callback)
+		return err
+	})
+
+	trace := nacha.TraceNumber("01100001", 1)
+	payout := &models.Payout{
+		ID: "p-1", TransferID: "t-1", From: "Mark", Amount: 25, Rail: rail.ACHName,
+		Status: models.PayoutStatusSubmitted, RailReference: trace + "-p1",
+	}
+	achRepo.On("GetFile", mock.Anything, "f-1").Return(&models.ACHFile{ID: "f-1"}, []*models.Payout{payout}, nil).Once()
+	f.payouts.On("GetByReference", mock.Anything, rail.ACHName, payout.RailReference).
+		Return(func(context.Context, string, string) *models.Payout { copied := *payout; return &copied }, nil)
+	f.payouts.On("UpdateStatus", mock.Anything, mock.AnythingOfType("*models.Payout"), mock.Anything).
+		Run(func(args mock.Arguments) { *payout = *args.Get(1).(*models.Payout) }).
+		Return(nil)
+	f.bank.On("Transfer", mock.Anything, models.TransferRequest{
+		From: "clearing", To: "settlement", Amount: 25, Currency: models.Currency, IdempotencyKey: "payout-settlement:p-1",
+	}).Return(&models.Transfer{ID: "t-2", Status: models.TransferStatusCompleted}, nil).Once()
+	f.bank.On("Transfer", mock.Anything, models.TransferRequest{
+		From: "settlement", To: "Mark", Amount: 25, Currency: models.Currency, IdempotencyKey: "payout-return:p-1",
+	}).Return(&models.Transfer{ID: "t-3", Status: models.TransferStatusCompleted}, nil).Once()
+
+	settlement, err := ach.Settle(context.Background(), "f-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, settlement.Settled)
+	assert.Equal(t, models.PayoutStatusSettled, payout.Status)
+
+	var returnFile bytes.Buffer
+	require.NoError(t, nacha.Write(&returnFile, &nacha.File{
+		Header: nacha.FileHeader{
+			ImmediateDestination: "011000015",
+			ImmediateOrigin:      "021000021",
+			CreatedAt:            time.Date(2024, 2, 20, 6, 0, 0, 0, time.UTC),
+			IDModifier:           'A',
+		},
+		Batches: []nacha.Batch{{
+			CompanyName:   "MONEY TRANSFER",
+			CompanyID:     "1234567890",
+			SECCode:       "PPD",
+			EffectiveDate: time.Date(2024, 2, 20, 0, 0, 0, 0, time.UTC),
+			ODFI:          "02100002",
+			Number:        1,
+			Entries: []nacha.Entry{{
+				TransactionCode: nacha.CheckingReturnCredit,
+				RDFI:            "011000015",
+				AccountNumber:   "123456789",
+				Amount:          2500,
+				IndividualID:    "p1",
+				IndividualName:  "JANE DOE",
+				TraceNumber:     nacha.TraceNumber("02100002", 1),
+				Return:          &nacha.Return{Code: "R16", OriginalTraceNumber: trace, OriginalRDFI: "02100002"},
+			}},
+		}},
+	}))
+
+	returns, err := ach.ProcessReturns(context.Background(), &returnFile)
+	require.NoError(t, err)
+	require.Len(t, returns, 1)
+	assert.Empty(t, returns[0].Error)
+	assert.Equal(t, models.PayoutStatusReturned, payout.Status)
+	assert.Equal(t, "R16: "+nacha.ReturnReason("R16"), payout.FailureReason)
+}
+
+func TestPayoutService_GetPayout(t *testing.T) {
+	f := setup(t, testRail{})
+	payout := &models.Payout{ID: "p-1", From: "Mark"}
+	f.payouts.On("GetPayout", mock.Anything, "p-1").Return(payout, nil).Twice()
+
+	got, err := f.service.GetPayout(markContext(), "p-1")
+	require.NoError(t, err)
+	assert.Equal(t, payout, got)
+
+	jane := auth.WithPrincipal(context.Background(),
+		&models.Principal{ID: "jane", Role: models.RoleCustomer, Accounts: []string{"Jane"}})
+	_, err = f.service.GetPayout(jane, "p-1")
+	assert.ErrorIs(t, err, transfererrors.ErrForbidden)
+}
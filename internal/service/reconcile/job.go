@@ -0,0 +1,114 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/metrics"
+	"money-transfer/internal/storage"
+)
+
+// ParseTime parses a daily run time given as HH:MM in UTC into its offset from midnight
+func ParseTime(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid reconciliation time %q: want HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Job reconciles the ledger once a day in the background, writing each report to a directory
+// and recording its outcome in the metrics
+type Job struct {
+	repo      storage.LedgerRepository
+	at        time.Duration
+	reportDir string
+	metrics   *metrics.Collector
+	logger    *slog.Logger
+	now       func() time.Time
+	wg        sync.WaitGroup
+}
+
+// NewJob creates a job running every day at the offset at from midnight UTC
+// Reports are written to reportDir unless it is empty.
+func NewJob(
+	repo storage.LedgerRepository, at time.Duration, reportDir string, collector *metrics.Collector, logger *slog.Logger,
+) *Job {
+	return &Job{
+		repo:      repo,
+		at:        at,
+		reportDir: reportDir,
+		metrics:   collector,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Start launches the schedule; it stops once ctx is canceled
+func (j *Job) Start(ctx context.Context) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+		j.run(ctx)
+	}()
+}
+
+// Wait blocks until the schedule has returned, including a run in progress
+func (j *Job) Wait() {
+	j.wg.Wait()
+}
+
+// run reconciles at every scheduled time until ctx is canceled
+func (j *Job) run(ctx context.Context) {
+	for {
+		timer := time.NewTimer(j.nextRun(j.now()).Sub(j.now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		_, _ = j.RunOnce(ctx)
+	}
+}
+
+// nextRun returns the first scheduled time after now
+func (j *Job) nextRun(now time.Time) time.Time {
+	next := now.UTC().Truncate(24 * time.Hour).Add(j.at)
+	if !next.After(now) {
+		next = next.Add(24 * time.Hour)
+	}
+	return next
+}
+
+// RunOnce reconciles the ledger, records the outcome and writes the report
+// Discrepancies are logged as errors naming the report files, so that they alert.
+func (j *Job) RunOnce(ctx context.Context) (*models.ReconciliationReport, error) {
+	report, err := Run(ctx, j.repo)
+	j.metrics.ObserveReconciliation(report, err)
+	if err != nil {
+		j.logger.ErrorContext(ctx, "reconciliation failed", "error", err)
+		return nil, err
+	}
+
+	var paths []string
+	if j.reportDir != "" {
+		if paths, err = WriteFiles(j.reportDir, report); err != nil {
+			j.logger.ErrorContext(ctx, "failed to write reconciliation report", "error", err)
+		}
+	}
+
+	if report.Balanced {
+		j.logger.InfoContext(ctx, "ledger reconciled", "accounts", report.Accounts, "postings", report.Postings,
+			"reports", paths)
+	} else {
+		j.logger.ErrorContext(ctx, "reconciliation found discrepancies", "discrepancies", len(report.Discrepancies),
+			"accounts", report.Accounts, "reports", paths)
+	}
+	return report, nil
+}
@@ -0,0 +1,117 @@
+// Package reconcile checks stored balances against the postings ledger and reports discrepancies
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/storage"
+)
+
+// Run recomputes every balance from the ledger and checks it against the stored balance, checks
+// that the postings of every transfer match it and that money was neither created nor destroyed
+// The report lists every failed check, accounts first in ID order; Balanced tells whether there
+// was none. Errors are only returned when the ledger could not be read.
+func Run(ctx context.Context, repo storage.LedgerRepository) (*models.ReconciliationReport, error) {
+	startedAt := time.Now().UTC()
+	summary, err := repo.Summarize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReconciliationReport{
+		StartedAt:     startedAt,
+		Accounts:      len(summary.Accounts),
+		Postings:      summary.Postings,
+		FundedTotal:   cents(summary.FundedTotal),
+		TransferNet:   cents(summary.TransferNet),
+		Discrepancies: []models.Discrepancy{},
+	}
+
+	for _, account := range summary.Accounts {
+		report.StoredTotal = cents(report.StoredTotal + account.StoredBalance)
+		report.Discrepancies = append(report.Discrepancies, checkAccount(account)...)
+	}
+	for _, transfer := range summary.Transfers {
+		report.Discrepancies = append(report.Discrepancies, models.Discrepancy{
+			Kind:       models.DiscrepancyTransfer,
+			TransferID: transfer.TransferID,
+			Actual:     cents(transfer.Net),
+			Detail:     describeTransfer(transfer),
+		})
+	}
+	report.Discrepancies = append(report.Discrepancies, checkConservation(report)...)
+
+	report.Balanced = len(report.Discrepancies) == 0
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+// checkAccount compares the stored balance and the balance recorded with the latest posting of
+// an account with the sum of its postings
+func checkAccount(account models.AccountSummary) []models.Discrepancy {
+	var discrepancies []models.Discrepancy
+	posted := cents(account.PostedBalance)
+
+	if stored := cents(account.StoredBalance); stored != posted {
+		discrepancies = append(discrepancies, models.Discrepancy{
+			Kind:      models.DiscrepancyBalance,
+			AccountID: account.AccountID,
+			Expected:  posted,
+			Actual:    stored,
+			Detail:    fmt.Sprintf("stored balance differs from the sum of %d postings", account.Postings),
+		})
+	}
+	if head := cents(account.HeadBalance); head != posted {
+		discrepancies = append(discrepancies, models.Discrepancy{
+			Kind:      models.DiscrepancyRunningBalance,
+			AccountID: account.AccountID,
+			Expected:  posted,
+			Actual:    head,
+			Detail:    "balance recorded with the latest posting differs from the sum of the postings",
+		})
+	}
+
+	return discrepancies
+}
+
+// describeTransfer explains how the postings of a transfer fail to match it
+func describeTransfer(transfer models.TransferPostings) string {
+	if transfer.Status == models.TransferStatusCompleted || transfer.Status == models.TransferStatusReversed {
+		return fmt.Sprintf("%s transfer of %.2f has %d postings netting %.2f instead of a debit and a credit of its amount",
+			transfer.Status, transfer.Amount, transfer.Postings, transfer.Net)
+	}
+	return fmt.Sprintf("%s transfer has %d postings although it moved no funds", transfer.Status, transfer.Postings)
+}
+
+// checkConservation checks that money only entered the bank through opening balances and
+// adjustments, which requires transfers to net to zero
+func checkConservation(report *models.ReconciliationReport) []models.Discrepancy {
+	var discrepancies []models.Discrepancy
+
+	if report.StoredTotal != report.FundedTotal {
+		discrepancies = append(discrepancies, models.Discrepancy{
+			Kind:     models.DiscrepancyConservation,
+			Expected: report.FundedTotal,
+			Actual:   report.StoredTotal,
+			Detail:   "stored balances add up to a different total than the money funded",
+		})
+	}
+	if report.TransferNet != 0 {
+		discrepancies = append(discrepancies, models.Discrepancy{
+			Kind:   models.DiscrepancyConservation,
+			Actual: report.TransferNet,
+			Detail: "transfer postings do not net to zero",
+		})
+	}
+
+	return discrepancies
+}
+
+// cents rounds an amount to whole cents, dropping the error of adding binary fractions
+func cents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,202 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/metrics"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// balancedSummary is the ledger of Mark and Jane after Mark sent Jane 40.1
+func balancedSummary() *models.LedgerSummary {
+	return &models.LedgerSummary{
+		Accounts: []models.AccountSummary{
+			{AccountID: "Jane", StoredBalance: 90.1, PostedBalance: 90.1, HeadBalance: 90.1, Postings: 2},
+			{AccountID: "Mark", StoredBalance: 59.9, PostedBalance: 59.9, HeadBalance: 59.9, Postings: 2},
+		},
+		Transfers:   []models.TransferPostings{},
+		Postings:    4,
+		FundedTotal: 150,
+		TransferNet: 0,
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		summary   func() *models.LedgerSummary
+		wantKinds []models.DiscrepancyKind
+	}{
+		{
+			name:    "balanced ledger",
+			summary: balancedSummary,
+		},
+		{
+			name: "stored balance changed outside the ledger",
+			summary: func() *models.LedgerSummary {
+				summary := balancedSummary()
+				summary.Accounts[1].StoredBalance = 100
+				return summary
+			},
+			wantKinds: []models.DiscrepancyKind{models.DiscrepancyBalance, models.DiscrepancyConservation},
+		},
+		{
+			name: "running balance recorded wrong",
+			summary: func() *models.LedgerSummary {
+				summary := balancedSummary()
+				summary.Accounts[0].HeadBalance = 90
+				return summary
+			},
+			wantKinds: []models.DiscrepancyKind{models.DiscrepancyRunningBalance},
+		},
+		{
+			name: "transfer posted on one side only",
+			summary: func() *models.LedgerSummary {
+				summary := balancedSummary()
+				summary.Accounts[0].PostedBalance = 50
+				summary.Accounts[0].HeadBalance = 50
+				summary.Accounts[0].Postings = 1
+				summary.Transfers = []models.TransferPostings{
+					{TransferID: "t-1", Status: models.TransferStatusCompleted, Amount: 40.1, Postings: 1, Net: -40.1},
+				}
+				summary.TransferNet = -40.1
+				return summary
+			},
+			wantKinds: []models.DiscrepancyKind{
+				models.DiscrepancyBalance, models.DiscrepancyTransfer, models.DiscrepancyConservation,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := mocks.NewLedgerRepository(t)
+			repo.On("Summarize", mock.Anything).Return(tt.summary(), nil)
+
+			report, err := Run(context.Background(), repo)
+			require.NoError(t, err)
+
+			var kinds []models.DiscrepancyKind
+			for _, discrepancy := range report.Discrepancies {
+				kinds = append(kinds, discrepancy.Kind)
+			}
+			assert.Equal(t, tt.wantKinds, kinds)
+			assert.Equal(t, len(tt.wantKinds) == 0, report.Balanced)
+			assert.Equal(t, 2, report.Accounts)
+			assert.Equal(t, 150.0, report.FundedTotal)
+		})
+	}
+}
+
+func TestRun_SummaryFails(t *testing.T) {
+	repo := mocks.NewLedgerRepository(t)
+	repo.On("Summarize", mock.Anything).Return(nil, assert.AnError)
+
+	report, err := Run(context.Background(), repo)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Nil(t, report)
+}
+
+func TestWriteFiles(t *testing.T) {
+	report := &models.ReconciliationReport{
+		StartedAt: time.Date(2024, 2, 1, 2, 0, 0, 0, time.UTC),
+		Discrepancies: []models.Discrepancy{
+			{Kind: models.DiscrepancyBalance, AccountID: "Mark", Expected: 59.9, Actual: 100, Detail: "stored balance differs"},
+		},
+	}
+
+	dir := t.TempDir() + "/reports"
+	paths, err := WriteFiles(dir, report)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		dir + "/reconciliation-20240201T020000Z.json",
+		dir + "/reconciliation-20240201T020000Z.csv",
+	}, paths)
+
+	content, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	var decoded models.ReconciliationReport
+	require.NoError(t, json.Unmarshal(content, &decoded))
+	assert.Equal(t, report.Discrepancies, decoded.Discrepancies)
+
+	content, err = os.ReadFile(paths[1])
+	require.NoError(t, err)
+	rows, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"kind", "account_id", "transfer_id", "expected", "actual", "detail"},
+		{"balance", "Mark", "", "59.90", "100.00", "stored balance differs"},
+	}, rows)
+}
+
+func TestParseTime(t *testing.T) {
+	at, err := ParseTime("02:30")
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Hour+30*time.Minute, at)
+
+	for _, value := range []string{"2:30am", "24:00", "02:30:00", ""} {
+		_, err := ParseTime(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestJob_NextRun(t *testing.T) {
+	job := NewJob(nil, 2*time.Hour, "", nil, logging.Discard())
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{
+			name: "later the same day",
+			now:  time.Date(2024, 2, 1, 1, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 2, 1, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "at the scheduled time",
+			now:  time.Date(2024, 2, 1, 2, 0, 0, 0, time.UTC),
+			want: time.Date(2024, 2, 2, 2, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "in another time zone",
+			now:  time.Date(2024, 2, 1, 23, 0, 0, 0, time.FixedZone("CET", 3600)),
+			want: time.Date(2024, 2, 2, 2, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, tt.want.Equal(job.nextRun(tt.now)), job.nextRun(tt.now))
+		})
+	}
+}
+
+func TestJob_RunOnce(t *testing.T) {
+	repo := mocks.NewLedgerRepository(t)
+	summary := balancedSummary()
+	summary.Accounts[1].StoredBalance = 100
+	repo.On("Summarize", mock.Anything).Return(summary, nil)
+	dir := t.TempDir()
+
+	job := NewJob(repo, 0, dir, metrics.New(), logging.Discard())
+	report, err := job.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Balanced)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+}
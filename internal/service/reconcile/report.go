@@ -0,0 +1,78 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"money-transfer/internal/domain/models"
+)
+
+// WriteJSON writes the full report as indented JSON
+func WriteJSON(w io.Writer, report *models.ReconciliationReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// WriteCSV writes the discrepancies of the report as CSV, one row each
+func WriteCSV(w io.Writer, report *models.ReconciliationReport) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{{"kind", "account_id", "transfer_id", "expected", "actual", "detail"}}
+	for _, discrepancy := range report.Discrepancies {
+		rows = append(rows, []string{
+			string(discrepancy.Kind),
+			discrepancy.AccountID,
+			discrepancy.TransferID,
+			strconv.FormatFloat(discrepancy.Expected, 'f', 2, 64),
+			strconv.FormatFloat(discrepancy.Actual, 'f', 2, 64),
+			discrepancy.Detail,
+		})
+	}
+
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+// WriteFiles writes the report to dir as JSON and CSV files named after the time the run started
+// and returns their paths; dir is created when missing.
+func WriteFiles(dir string, report *models.ReconciliationReport) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	name := "reconciliation-" + report.StartedAt.UTC().Format("20060102T150405Z")
+	var paths []string
+	for _, file := range []struct {
+		ext   string
+		write func(io.Writer, *models.ReconciliationReport) error
+	}{{".json", WriteJSON}, {".csv", WriteCSV}} {
+		path := filepath.Join(dir, name+file.ext)
+		if err := writeFile(path, report, file.write); err != nil {
+			return paths, fmt.Errorf("write %s: %w", path, err)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths, nil
+}
+
+// writeFile creates path and writes report into it with write
+func writeFile(path string, report *models.ReconciliationReport,
+	write func(io.Writer, *models.ReconciliationReport) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
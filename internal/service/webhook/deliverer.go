@@ -0,0 +1,183 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+	"money-transfer/internal/storage"
+)
+
+// claimBatchSize is how many due deliveries a worker leases at once
+const claimBatchSize = 10
+
+// maxErrorLength bounds the response excerpt stored with a failed attempt
+const maxErrorLength = 512
+
+// DelivererConfig holds the settings of a Deliverer
+type DelivererConfig struct {
+	Workers      int
+	PollInterval time.Duration
+	Timeout      time.Duration // per request
+	MaxAttempts  int           // attempts before a delivery is dead-lettered
+	BackoffBase  time.Duration // delay after the first failed attempt
+	BackoffMax   time.Duration // upper bound for the delay between attempts
+	Logger       *slog.Logger  // receives delivery errors; nothing is logged when it is nil
+}
+
+// Deliverer sends queued webhook deliveries on a pool of background workers
+// Failed attempts are retried with exponential backoff until MaxAttempts is reached
+type Deliverer struct {
+	store  storage.Store
+	client *http.Client
+	cfg    DelivererConfig
+	now    func() time.Time
+	wg     sync.WaitGroup
+}
+
+// NewDeliverer creates a worker pool delivering webhooks queued in store
+func NewDeliverer(store storage.Store, cfg DelivererConfig) *Deliverer {
+	if cfg.Logger == nil {
+		cfg.Logger = logging.Discard()
+	}
+	return &Deliverer{
+		store:  store,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Start launches the workers; they stop once ctx is canceled
+func (d *Deliverer) Start(ctx context.Context) {
+	for i := 0; i < d.cfg.Workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.work(ctx)
+		}()
+	}
+}
+
+// Wait blocks until all workers have returned
+func (d *Deliverer) Wait() {
+	d.wg.Wait()
+}
+
+// work delivers due webhooks until ctx is canceled
+func (d *Deliverer) work(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if d.DeliverDue(ctx) == claimBatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue attempts one batch of due deliveries and returns how many were attempted
+func (d *Deliverer) DeliverDue(ctx context.Context) int {
+	if ctx.Err() != nil {
+		return 0
+	}
+
+	// The lease must outlast every attempt in the batch, or another worker could pick them up
+	lease := time.Duration(claimBatchSize+1) * d.cfg.Timeout
+	deliveries, err := d.store.Webhook().ClaimDueDeliveries(ctx, claimBatchSize, lease)
+	if err != nil {
+		d.cfg.Logger.ErrorContext(ctx, "failed to claim webhook deliveries", "error", err)
+		return 0
+	}
+
+	for _, delivery := range deliveries {
+		attempt := d.attempt(ctx, delivery)
+		if err := d.store.Webhook().RecordAttempt(context.WithoutCancel(ctx), delivery.ID, attempt); err != nil {
+			d.cfg.Logger.ErrorContext(ctx, "failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+
+	return len(deliveries)
+}
+
+// attempt sends a delivery once and decides what happens next
+func (d *Deliverer) attempt(ctx context.Context, delivery *models.WebhookDelivery) models.WebhookAttempt {
+	code, err := d.send(ctx, delivery)
+	if err == nil {
+		return models.WebhookAttempt{
+			Status:        models.WebhookDeliveryDelivered,
+			ResponseCode:  code,
+			NextAttemptAt: d.now(),
+		}
+	}
+
+	attempts := delivery.Attempts + 1
+	result := models.WebhookAttempt{
+		Status:        models.WebhookDeliveryPending,
+		ResponseCode:  code,
+		Error:         err.Error(),
+		NextAttemptAt: d.now().Add(d.backoff(attempts)),
+	}
+	if attempts >= d.cfg.MaxAttempts {
+		result.Status = models.WebhookDeliveryDead
+	}
+
+	return result
+}
+
+// send POSTs the signed payload and returns the response code
+// Any non-2xx response is treated as a failure
+func (d *Deliverer) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	subscription, err := d.store.Webhook().GetSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := d.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEventType, string(delivery.EventType))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorLength))
+		return resp.StatusCode, fmt.Errorf("endpoint responded %d: %s", resp.StatusCode, excerpt)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns the delay before the next attempt after attempts failures
+func (d *Deliverer) backoff(attempts int) time.Duration {
+	delay := d.cfg.BackoffBase
+	for i := 1; i < attempts && delay < d.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, d.cfg.BackoffMax)
+}
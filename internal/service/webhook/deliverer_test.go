@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var testDelivererConfig = DelivererConfig{
+	Workers:      1,
+	PollInterval: time.Second,
+	Timeout:      time.Second,
+	MaxAttempts:  3,
+	BackoffBase:  10 * time.Second,
+	BackoffMax:   time.Minute,
+}
+
+// setupDeliverer wires a deliverer to a store holding one due delivery for a subscription at url
+func setupDeliverer(t *testing.T, url string, attempts int) (*Deliverer, *mocks.WebhookRepository, time.Time) {
+	t.Helper()
+	mockStore := mocks.NewStore(t)
+	mockWebhookRepo := mocks.NewWebhookRepository(t)
+	mockStore.On("Webhook").Return(mockWebhookRepo)
+
+	mockWebhookRepo.On("ClaimDueDeliveries", mock.Anything, claimBatchSize, mock.Anything).
+		Return([]*models.WebhookDelivery{{
+			ID:             "d-1",
+			SubscriptionID: "w-1",
+			EventID:        "e-1",
+			EventType:      models.EventTransferCompleted,
+			Payload:        []byte(`{"id":"e-1"}`),
+			Attempts:       attempts,
+		}}, nil)
+	mockWebhookRepo.On("GetSubscription", mock.Anything, "w-1").
+		Return(&models.WebhookSubscription{ID: "w-1", URL: url, Secret: "s3cret"}, nil)
+
+	now := time.Unix(1_700_000_000, 0)
+	deliverer := NewDeliverer(mockStore, testDelivererConfig)
+	deliverer.now = func() time.Time { return now }
+
+	return deliverer, mockWebhookRepo, now
+}
+
+func TestDeliverer_DeliverDueSignsRequest(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		err := Verify("s3cret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body,
+			time.Minute, time.Unix(1_700_000_000, 0))
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received <- r
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	deliverer, repo, _ := setupDeliverer(t, receiver.URL, 0)
+	repo.On("RecordAttempt", mock.Anything, "d-1", mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryDelivered && a.ResponseCode == http.StatusNoContent
+	})).Return(nil)
+
+	assert.Equal(t, 1, deliverer.DeliverDue(context.Background()))
+
+	req := <-received
+	assert.Equal(t, "e-1", req.Header.Get(HeaderEventID))
+	assert.Equal(t, string(models.EventTransferCompleted), req.Header.Get(HeaderEventType))
+}
+
+func TestDeliverer_DeliverDueRetriesWithBackoff(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	deliverer, repo, now := setupDeliverer(t, receiver.URL, 1)
+	repo.On("RecordAttempt", mock.Anything, "d-1", mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		// Second failure doubles the base delay
+		return a.Status == models.WebhookDeliveryPending &&
+			a.ResponseCode == http.StatusServiceUnavailable &&
+			a.NextAttemptAt.Equal(now.Add(20*time.Second))
+	})).Return(nil)
+
+	assert.Equal(t, 1, deliverer.DeliverDue(context.Background()))
+}
+
+func TestDeliverer_DeliverDueDeadLetters(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	deliverer, repo, _ := setupDeliverer(t, receiver.URL, testDelivererConfig.MaxAttempts-1)
+	repo.On("RecordAttempt", mock.Anything, "d-1", mock.MatchedBy(func(a models.WebhookAttempt) bool {
+		return a.Status == models.WebhookDeliveryDead && a.Error != ""
+	})).Return(nil)
+
+	assert.Equal(t, 1, deliverer.DeliverDue(context.Background()))
+}
+
+func TestDeliverer_Backoff(t *testing.T) {
+	deliverer := NewDeliverer(nil, testDelivererConfig)
+
+	assert.Equal(t, 10*time.Second, deliverer.backoff(1))
+	assert.Equal(t, 20*time.Second, deliverer.backoff(2))
+	assert.Equal(t, 40*time.Second, deliverer.backoff(3))
+	assert.Equal(t, time.Minute, deliverer.backoff(4))
+	assert.Equal(t, time.Minute, deliverer.backoff(30))
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":"e-1"}`)
+	signature := Sign("s3cret", now.Unix(), body)
+
+	require.NoError(t, Verify("s3cret", "1700000000", signature, body, time.Minute, now))
+	assert.ErrorIs(t, Verify("other", "1700000000", signature, body, time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", "1700000000", signature, []byte(`{}`), time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("s3cret", "1700000000", signature, body, time.Minute, now.Add(time.Hour)),
+		ErrStaleTimestamp)
+}
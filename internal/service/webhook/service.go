@@ -0,0 +1,146 @@
+// Package webhook manages webhook subscriptions and delivers domain events to them
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/storage"
+)
+
+// secretBytes is the length of generated signing secrets before hex encoding
+const secretBytes = 32
+
+// Service manages webhook subscriptions and queues events for delivery
+type Service struct {
+	store storage.Store
+}
+
+// NewService creates a new instance of webhook service
+func NewService(store storage.Store) *Service {
+	return &Service{
+		store: store,
+	}
+}
+
+// CreateSubscription registers a new endpoint for the requested event types
+// The returned subscription is the only place the signing secret is ever shown
+func (s *Service) CreateSubscription(
+	ctx context.Context, req models.WebhookSubscriptionRequest,
+) (*models.WebhookSubscription, error) {
+	if err := validateSubscription(req); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		secret = generated
+	}
+
+	eventTypes := req.EventTypes
+	if eventTypes == nil {
+		eventTypes = []models.EventType{}
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:        req.URL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+	}
+	if err := s.store.Webhook().CreateSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetSubscription returns a subscription without its secret
+func (s *Service) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	subscription, err := s.store.Webhook().GetSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// ListSubscriptions returns all subscriptions without their secrets
+func (s *Service) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	subscriptions, err := s.store.Webhook().ListSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+// DeleteSubscription removes a subscription and stops its pending deliveries
+func (s *Service) DeleteSubscription(ctx context.Context, id string) error {
+	return s.store.Webhook().DeleteSubscription(ctx, id)
+}
+
+// ListDeliveries returns the most recent deliveries of a subscription
+func (s *Service) ListDeliveries(
+	ctx context.Context, subscriptionID string, limit int,
+) ([]*models.WebhookDelivery, error) {
+	if _, err := s.store.Webhook().GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.store.Webhook().ListDeliveries(ctx, subscriptionID, limit)
+}
+
+// Redeliver queues a delivery for an immediate new attempt, including dead-lettered ones
+func (s *Service) Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error) {
+	return s.store.Webhook().Redeliver(ctx, subscriptionID, deliveryID)
+}
+
+// Publish queues the event for every subscription interested in it
+// Together with the outbox relay this makes the service an event publisher
+func (s *Service) Publish(ctx context.Context, event *models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.store.Webhook().EnqueueDeliveries(ctx, event, payload)
+	return err
+}
+
+// validateSubscription checks the URL and event types of a subscription request
+func validateSubscription(req models.WebhookSubscriptionRequest) error {
+	target, err := url.Parse(req.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return transfererrors.ErrInvalidWebhookURL
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !eventType.IsValid() {
+			return transfererrors.ErrInvalidEventType
+		}
+	}
+
+	return nil
+}
+
+// generateSecret returns a random hex-encoded signing secret
+func generateSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,120 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/storage/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookService_CreateSubscription(t *testing.T) {
+	tests := []struct {
+		name       string
+		req        models.WebhookSubscriptionRequest
+		mock       func(*mocks.Store, *mocks.WebhookRepository)
+		wantErr    error
+		wantSecret string
+	}{
+		{
+			name: "provided secret",
+			req: models.WebhookSubscriptionRequest{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []models.EventType{models.EventTransferCompleted},
+				Secret:     "s3cret",
+			},
+			mock: func(s *mocks.Store, wr *mocks.WebhookRepository) {
+				s.On("Webhook").Return(wr)
+				wr.On("CreateSubscription", mock.Anything, mock.MatchedBy(func(sub *models.WebhookSubscription) bool {
+					return sub.Secret == "s3cret" && sub.URL == "https://partner.example/hooks"
+				})).Return(nil)
+			},
+			wantSecret: "s3cret",
+		},
+		{
+			name: "generated secret",
+			req:  models.WebhookSubscriptionRequest{URL: "http://localhost:9000/hooks"},
+			mock: func(s *mocks.Store, wr *mocks.WebhookRepository) {
+				s.On("Webhook").Return(wr)
+				wr.On("CreateSubscription", mock.Anything, mock.AnythingOfType("*models.WebhookSubscription")).Return(nil)
+			},
+			wantSecret: "whsec_",
+		},
+		{
+			name:    "relative url",
+			req:     models.WebhookSubscriptionRequest{URL: "/hooks"},
+			mock:    func(_ *mocks.Store, _ *mocks.WebhookRepository) {},
+			wantErr: transfererrors.ErrInvalidWebhookURL,
+		},
+		{
+			name:    "unsupported scheme",
+			req:     models.WebhookSubscriptionRequest{URL: "ftp://partner.example/hooks"},
+			mock:    func(_ *mocks.Store, _ *mocks.WebhookRepository) {},
+			wantErr: transfererrors.ErrInvalidWebhookURL,
+		},
+		{
+			name: "unknown event type",
+			req: models.WebhookSubscriptionRequest{
+				URL:        "https://partner.example/hooks",
+				EventTypes: []models.EventType{"MoneyPrinted"},
+			},
+			mock:    func(_ *mocks.Store, _ *mocks.WebhookRepository) {},
+			wantErr: transfererrors.ErrInvalidEventType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := mocks.NewStore(t)
+			mockWebhookRepo := mocks.NewWebhookRepository(t)
+			tt.mock(mockStore, mockWebhookRepo)
+
+			service := NewService(mockStore)
+
+			subscription, err := service.CreateSubscription(context.Background(), tt.req)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(subscription.Secret, tt.wantSecret))
+			assert.NotNil(t, subscription.EventTypes)
+		})
+	}
+}
+
+func TestWebhookService_ListSubscriptionsHidesSecrets(t *testing.T) {
+	mockStore := mocks.NewStore(t)
+	mockWebhookRepo := mocks.NewWebhookRepository(t)
+	mockStore.On("Webhook").Return(mockWebhookRepo)
+	mockWebhookRepo.On("ListSubscriptions", mock.Anything).Return([]*models.WebhookSubscription{
+		{ID: "w-1", Secret: "s3cret"},
+	}, nil)
+
+	subscriptions, err := NewService(mockStore).ListSubscriptions(context.Background())
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Empty(t, subscriptions[0].Secret)
+}
+
+func TestWebhookService_Publish(t *testing.T) {
+	event := &models.Event{Sequence: 3, ID: "e-3", Type: models.EventTransferCompleted}
+
+	mockStore := mocks.NewStore(t)
+	mockWebhookRepo := mocks.NewWebhookRepository(t)
+	mockStore.On("Webhook").Return(mockWebhookRepo)
+	mockWebhookRepo.On("EnqueueDeliveries", mock.Anything, event, mock.MatchedBy(func(payload []byte) bool {
+		var decoded models.Event
+		return json.Unmarshal(payload, &decoded) == nil && decoded.ID == "e-3"
+	})).Return(2, nil)
+
+	require.NoError(t, NewService(mockStore).Publish(context.Background(), event))
+}
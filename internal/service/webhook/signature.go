@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Headers set on every webhook delivery
+const (
+	// HeaderEventID carries the event ID, stable across retries so receivers can deduplicate
+	HeaderEventID = "X-Webhook-Id"
+	// HeaderEventType carries the event type
+	HeaderEventType = "X-Webhook-Event"
+	// HeaderTimestamp carries the Unix time the delivery was signed at
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature carries "sha256=" followed by the hex HMAC of "<timestamp>.<body>"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// signaturePrefix names the HMAC algorithm in HeaderSignature
+const signaturePrefix = "sha256="
+
+// ErrInvalidSignature is returned by Verify when a delivery was not signed with the secret
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrStaleTimestamp is returned by Verify when a delivery was signed too long ago
+var ErrStaleTimestamp = errors.New("webhook timestamp outside tolerance")
+
+// Sign computes the HeaderSignature value for body sent at timestamp
+// Including the timestamp in the signed content prevents replaying old deliveries
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received delivery, as a receiver would
+// tolerance bounds how far the timestamp may be from now
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration, now time.Time) error {
+	sentAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	age := now.Sub(time.Unix(sentAt, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, sentAt, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
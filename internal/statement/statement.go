@@ -0,0 +1,162 @@
+// Package statement renders account statements as CSV and PDF documents
+package statement
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"money-transfer/internal/domain/models"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// dateLayout is how times are shown on statements
+const dateLayout = "2006-01-02 15:04:05"
+
+// ContentType returns the media type of statements in format
+func ContentType(format models.StatementFormat) string {
+	switch format {
+	case models.StatementCSV:
+		return "text/csv; charset=utf-8"
+	case models.StatementPDF:
+		return "application/pdf"
+	case models.StatementCamt053:
+		return "application/xml; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Filename returns the name statements of s are downloaded as in format
+func Filename(s *models.Statement, format models.StatementFormat) string {
+	extension := string(format)
+	if format == models.StatementCamt053 {
+		extension = "xml"
+	}
+	return fmt.Sprintf("statement-%s-%s-%s.%s",
+		s.AccountID, s.From.Format("20060102"), s.To.Format("20060102"), extension)
+}
+
+// WriteCSV writes s as CSV: one row per line, framed by the opening and closing balance
+func WriteCSV(w io.Writer, s *models.Statement) error {
+	out := csv.NewWriter(w)
+	rows := [][]string{
+		{"posted_at", "posting_id", "description", "transfer_id", "counterparty", "amount", "balance", "currency"},
+		{formatTime(s.From), "", "Opening balance", "", "", "", formatAmount(s.OpeningBalance), s.Currency},
+	}
+	for _, line := range s.Lines {
+		rows = append(rows, []string{
+			formatTime(line.PostedAt),
+			strconv.FormatInt(line.PostingID, 10),
+			line.Description,
+			line.TransferID,
+			line.Counterparty,
+			formatAmount(line.Amount),
+			formatAmount(line.Balance),
+			s.Currency,
+		})
+	}
+	rows = append(rows,
+		[]string{formatTime(s.To), "", "Closing balance", "", "", "", formatAmount(s.ClosingBalance), s.Currency})
+
+	if err := out.WriteAll(rows); err != nil {
+		return err
+	}
+	return out.Error()
+}
+
+// WritePDF writes s as a printable A4 document
+func WritePDF(w io.Writer, s *models.Statement) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("Statement of account "+s.AccountID, true)
+	pdf.SetCreationDate(s.GeneratedAt)
+	pdf.SetModificationDate(s.GeneratedAt)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AliasNbPages("")
+
+	// Columns of the postings table: width in mm, title and alignment
+	columns := []struct {
+		width float64
+		title string
+		align string
+	}{
+		{38, "Date (UTC)", "L"},
+		{82, "Description", "L"},
+		{35, "Amount", "R"},
+		{35, "Balance", "R"},
+	}
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for _, column := range columns {
+			pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	row := func(cells ...string) {
+		// Repeat the header on every page the table continues on
+		if pdf.GetY() > 270 {
+			pdf.AddPage()
+			header()
+		}
+		for i, column := range columns {
+			pdf.CellFormat(column.width, 6, tr(cells[i]), "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	pdf.AddPage()
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 10, "Statement of account", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	for _, field := range [][2]string{
+		{"Account", s.AccountID},
+		{"Period", formatTime(s.From) + " to " + formatTime(s.To)},
+		{"Currency", s.Currency},
+		{"Generated", formatTime(s.GeneratedAt)},
+	} {
+		pdf.CellFormat(30, 6, field[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, tr(field[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	header()
+	row(formatTime(s.From), "Opening balance", "", formatAmount(s.OpeningBalance))
+	for _, line := range s.Lines {
+		row(formatTime(line.PostedAt), line.Description, formatAmount(line.Amount), formatAmount(line.Balance))
+	}
+	pdf.SetFont("Helvetica", "B", 9)
+	row(formatTime(s.To), "Closing balance", "", formatAmount(s.ClosingBalance))
+
+	pdf.Ln(4)
+	pdf.SetFont("Helvetica", "", 10)
+	for _, total := range [][2]string{
+		{"Total credits", formatAmount(s.TotalCredits)},
+		{"Total debits", formatAmount(s.TotalDebits)},
+	} {
+		pdf.CellFormat(40, 6, total[0], "", 0, "L", false, 0, "")
+		pdf.CellFormat(35, 6, total[1], "", 1, "R", false, 0, "")
+	}
+
+	return pdf.Output(w)
+}
+
+// formatTime shows t in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format(dateLayout)
+}
+
+// formatAmount shows amount with two decimals
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
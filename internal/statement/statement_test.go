@@ -0,0 +1,85 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement(lines int) *models.Statement {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &models.Statement{
+		AccountID:      "Mark",
+		Currency:       models.Currency,
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: 100,
+		ClosingBalance: 100,
+		GeneratedAt:    from.AddDate(0, 1, 1),
+	}
+	for i := 0; i < lines; i++ {
+		s.Lines = append(s.Lines, models.StatementLine{
+			PostingID:    int64(i + 1),
+			PostedAt:     from.Add(time.Duration(i) * time.Hour),
+			Kind:         models.PostingTransfer,
+			Description:  "Transfer to Jane",
+			TransferID:   fmt.Sprintf("t-%d", i+1),
+			Counterparty: "Jane",
+			Amount:       -1,
+			Balance:      s.ClosingBalance - 1,
+		})
+		s.ClosingBalance--
+		s.TotalDebits++
+	}
+	return s
+}
+
+func TestWriteCSV(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WriteCSV(&out, testStatement(2)))
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"posted_at", "posting_id", "description", "transfer_id", "counterparty", "amount", "balance", "currency"},
+		{"2024-01-01 00:00:00", "", "Opening balance", "", "", "", "100.00", "USD"},
+		{"2024-01-01 00:00:00", "1", "Transfer to Jane", "t-1", "Jane", "-1.00", "99.00", "USD"},
+		{"2024-01-01 01:00:00", "2", "Transfer to Jane", "t-2", "Jane", "-1.00", "98.00", "USD"},
+		{"2024-02-01 00:00:00", "", "Closing balance", "", "", "", "98.00", "USD"},
+	}, rows)
+}
+
+func TestWritePDF(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int
+	}{
+		{name: "empty period", lines: 0},
+		{name: "table spanning pages", lines: 120},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WritePDF(&out, testStatement(tt.lines)))
+
+			assert.True(t, bytes.HasPrefix(out.Bytes(), []byte("%PDF-")))
+			assert.True(t, bytes.Contains(out.Bytes(), []byte("%%EOF")))
+		})
+	}
+}
+
+func TestFilename(t *testing.T) {
+	s := testStatement(0)
+
+	assert.Equal(t, "statement-Mark-20240101-20240201.csv", Filename(s, models.StatementCSV))
+	assert.Equal(t, "application/pdf", ContentType(models.StatementPDF))
+	assert.Equal(t, "statement-Mark-20240101-20240201.xml", Filename(s, models.StatementCamt053))
+}
@@ -3,14 +3,27 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"money-transfer/internal/domain/models"
+	"money-transfer/internal/ratelimit"
 )
 
 // Store represents the main interface for database operations
 type Store interface {
 	DB() *sql.DB
 	Account() AccountRepository
+	Transfer() TransferRepository
+	Ledger() LedgerRepository
+	Outbox() OutboxRepository
+	Webhook() WebhookRepository
+	RateLimit() RateLimitRepository
+	Audit() AuditRepository
+	Payout() PayoutRepository
+	Funding() FundingRepository
+	Screening() ScreeningRepository
+	PaymentMessage() PaymentMessageRepository
+	ACH() ACHRepository
 }
 
 // AccountRepository defines the interface for account-related database operations
@@ -18,9 +31,223 @@ type AccountRepository interface {
 	// GetAccount retrieves account information by ID
 	GetAccount(ctx context.Context, id string) (*models.Account, error)
 
-	// TransferWithinTx performs a money transfer between accounts
-	TransferWithinTx(ctx context.Context, fromID, toID string, amount float64) error
+	// GetAccounts retrieves the accounts with the given IDs in one query; unknown IDs are skipped
+	GetAccounts(ctx context.Context, ids []string) ([]*models.Account, error)
 
-	// InitializeTestData sets up test data in the database
+	// Totals returns the number of customer accounts and the sum of their balances
+	Totals(ctx context.Context) (*models.AccountTotals, error)
+
+	// CustomerAccounts lists the customer accounts ordered by ID
+	CustomerAccounts(ctx context.Context) ([]*models.Account, error)
+
+	// OpenAccount creates a customer account with a zero balance unless an account with its ID exists
+	// Fails if that account is a system account.
+	OpenAccount(ctx context.Context, id, holderName string) error
+
+	// InitializeTestData creates the demo accounts that do not exist yet, leaving existing ones as they are
 	InitializeTestData(ctx context.Context) error
+
+	// EnsureSystemAccounts creates the system accounts that do not exist yet with a zero balance
+	// Fails if one of ids is a customer account.
+	EnsureSystemAccounts(ctx context.Context, ids ...string) error
+}
+
+// TransferRepository defines the interface for transfer-related database operations
+type TransferRepository interface {
+	// Create persists a new transfer and fills in its ID and timestamps
+	// Returns ErrTransferExists with transfer replaced by the transfer holding its idempotency key,
+	// or ErrSerializationFailure if that transfer failed meanwhile and creating it may be retried.
+	Create(ctx context.Context, transfer *models.Transfer) error
+
+	// GetTransfer retrieves a transfer by ID
+	GetTransfer(ctx context.Context, id string) (*models.Transfer, error)
+
+	// UpdateStatus moves a transfer to a new status if it is still in the expected one
+	UpdateStatus(ctx context.Context, id string, from, to models.TransferStatus, reason string) error
+
+	// Execute moves the funds of a processing transfer and marks it completed in one transaction
+	Execute(ctx context.Context, id string) error
+
+	// Reverse moves the funds of a completed transfer back and marks it reversed in one transaction
+	Reverse(ctx context.Context, id string, reason string) error
+
+	// ClaimPending moves up to limit pending transfers to processing and returns them
+	ClaimPending(ctx context.Context, limit int) ([]*models.Transfer, error)
+
+	// RequeueStale moves up to limit claimed transfers processing for longer than lease back to pending and returns them
+	RequeueStale(ctx context.Context, lease time.Duration, limit int) ([]*models.Transfer, error)
+
+	// FailStale marks failed up to limit transfers executed by their caller that have been processing for longer
+	// than lease and returns them
+	FailStale(ctx context.Context, lease time.Duration, limit int) ([]*models.Transfer, error)
+
+	// ListByAccount returns the most recent transfers from or to the account, newest first
+	ListByAccount(ctx context.Context, accountID string, limit int) ([]*models.Transfer, error)
+
+	// CountByStatus returns how many transfers are in each status; statuses without transfers are omitted
+	CountByStatus(ctx context.Context) (map[models.TransferStatus]int, error)
+}
+
+// LedgerRepository reads the postings recorded with every balance change
+type LedgerRepository interface {
+	// AccountLedger returns the postings of the account made in [from, to) with the balances
+	// they start from and lead to, read from one snapshot
+	AccountLedger(ctx context.Context, accountID string, from, to time.Time) (*models.AccountLedger, error)
+
+	// BalancesAt returns the balances the accounts had at the time, ordered by account ID
+	// An account holds the balance of its last posting made at or before the time, or zero if it
+	// had none yet; unknown IDs are skipped.
+	BalancesAt(ctx context.Context, accountIDs []string, at time.Time) ([]models.AccountBalance, error)
+
+	// Summarize aggregates the whole ledger for reconciliation from one snapshot, listing only
+	// the transfers whose postings do not match their status and amount
+	Summarize(ctx context.Context) (*models.LedgerSummary, error)
+}
+
+// OutboxRepository defines the interface for relaying events from the transactional outbox
+type OutboxRepository interface {
+	// Relay positions the events of ended transactions, hands unpublished events to publish in
+	// order and marks the delivered ones
+	// Only one relay runs at a time across replicas; delivery stops at the first failure
+	Relay(ctx context.Context, limit int, publish func(ctx context.Context, event *models.Event) error) (int, error)
+
+	// AccountEvents returns up to limit events involving the account after the given sequence, oldest first
+	// Events are read once the relay has positioned them.
+	AccountEvents(ctx context.Context, accountID string, afterSequence int64, limit int) ([]*models.Event, error)
+
+	// LatestSequence returns the sequence of the newest positioned event, or zero when there is none
+	LatestSequence(ctx context.Context) (int64, error)
+}
+
+// WebhookRepository defines the interface for webhook subscription and delivery operations
+type WebhookRepository interface {
+	// CreateSubscription persists a new subscription and fills in its ID and creation time
+	CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error
+
+	// GetSubscription retrieves a subscription by ID, including its secret
+	GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+
+	// ListSubscriptions returns all subscriptions, including their secrets
+	ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error)
+
+	// DeleteSubscription removes a subscription and its deliveries
+	DeleteSubscription(ctx context.Context, id string) error
+
+	// EnqueueDeliveries queues the event for every matching subscription, ignoring ones already queued
+	EnqueueDeliveries(ctx context.Context, event *models.Event, payload []byte) (int, error)
+
+	// ClaimDueDeliveries leases up to limit due deliveries so no other worker attempts them meanwhile
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*models.WebhookDelivery, error)
+
+	// RecordAttempt stores the outcome of a delivery attempt
+	RecordAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error
+
+	// ListDeliveries returns the most recent deliveries of a subscription
+	ListDeliveries(ctx context.Context, subscriptionID string, limit int) ([]*models.WebhookDelivery, error)
+
+	// Redeliver resets a delivery so it is attempted again immediately
+	Redeliver(ctx context.Context, subscriptionID, deliveryID string) (*models.WebhookDelivery, error)
+}
+
+// RateLimitRepository keeps token buckets shared by every replica of the service
+type RateLimitRepository interface {
+	// Allow takes a token from the bucket identified by key, which starts full
+	Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+
+	// Sweep deletes the buckets that have refilled and returns how many were deleted
+	Sweep(ctx context.Context) (int, error)
+}
+
+// AuditRepository reads and seals the append-only audit log written along with every change
+type AuditRepository interface {
+	// List returns up to filter.Limit events matching filter, oldest first
+	List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error)
+
+	// Seal links up to limit unsealed events to the end of the hash chain and returns how many were sealed
+	// hash computes the hash of an event from the hash of the event before it
+	Seal(ctx context.Context, limit int, hash func(prevHash string, event *models.AuditEvent) string) (int, error)
+
+	// Chain returns up to limit sealed events after chain position afterIndex, in chain order
+	Chain(ctx context.Context, afterIndex int64, limit int) ([]*models.AuditEvent, error)
+
+	// CountUnsealed returns how many events are waiting to be sealed
+	CountUnsealed(ctx context.Context) (int, error)
+}
+
+// PayoutRepository tracks payouts through their payment rail
+type PayoutRepository interface {
+	// Create persists a new payout of an existing transfer and fills in its ID and timestamps
+	// Returns ErrPayoutExists with payout replaced by the existing one if the transfer made one already.
+	Create(ctx context.Context, payout *models.Payout) error
+
+	// GetPayout retrieves a payout by ID
+	GetPayout(ctx context.Context, id string) (*models.Payout, error)
+
+	// GetByReference retrieves the payout the rail assigned reference to
+	GetByReference(ctx context.Context, rail, reference string) (*models.Payout, error)
+
+	// GetByTransfer retrieves the payout made by a transfer
+	GetByTransfer(ctx context.Context, transferID string) (*models.Payout, error)
+
+	// UpdateStatus stores the status, rail reference, settlement transfer and failure reason of
+	// payout if it is still in status from
+	UpdateStatus(ctx context.Context, payout *models.Payout, from models.PayoutStatus) error
+}
+
+// ACHRepository keeps the trace numbers of the ACH rail and the files it carried payouts in
+type ACHRepository interface {
+	// NextSequence returns the next sequence number of entry trace numbers, from 1 to 9999999
+	// and then over again
+	NextSequence(ctx context.Context) (int, error)
+
+	// CreateFile records a file carrying up to limit submitted payouts of rail that no file
+	// carries yet and returns it with its payouts; the file is nil when there are none
+	CreateFile(ctx context.Context, rail string, limit int) (*models.ACHFile, []*models.Payout, error)
+
+	// GetFile retrieves a file with its payouts
+	GetFile(ctx context.Context, id string) (*models.ACHFile, []*models.Payout, error)
+}
+
+// FundingRepository moves money into and out of the ledger
+type FundingRepository interface {
+	// Record stores a deposit or withdrawal whose transfer completed and fills in its ID and
+	// creation time
+	// A funding whose kind and reference were recorded before is not stored again: funding is
+	// filled in from the earlier one and created is false. Returns ErrReferenceConflict when
+	// the earlier funding moved another amount or between other accounts.
+	Record(ctx context.Context, funding *models.Funding) (created bool, err error)
+}
+
+// PaymentMessageRepository records executed payment messages and their transactions, so that
+// repeats of them are answered without moving money again
+type PaymentMessageRepository interface {
+	// GetMessage retrieves the message sender executed with messageID
+	GetMessage(ctx context.Context, sender, messageID string) (*models.PaymentMessage, error)
+
+	// SaveMessage stores an executed message with its report and fills in its creation time
+	// A message saved before is kept, so the first report of a message is the one repeated.
+	SaveMessage(ctx context.Context, message *models.PaymentMessage) error
+
+	// ClaimTransaction records a transaction about to execute and fills in its creation time
+	// A transaction claimed before is not claimed again: transaction is filled in from the
+	// earlier claim, with its result if it has one, and claimed is false.
+	ClaimTransaction(ctx context.Context, transaction *models.PaymentTransaction) (claimed bool, err error)
+
+	// CompleteTransaction stores the result of a claimed transaction
+	CompleteTransaction(ctx context.Context, transaction *models.PaymentTransaction) error
+}
+
+// ScreeningRepository records how transfers were screened against the sanctions list and how
+// held transfers were reviewed
+type ScreeningRepository interface {
+	// Record stores how a created transfer was screened and fills in the ID and creation time of decision
+	// A held transfer moves to held and a blocked one to failed in the same transaction.
+	Record(ctx context.Context, transfer *models.Transfer, decision *models.ScreeningDecision) error
+
+	// List returns up to filter.Limit decisions matching filter, newest first
+	List(ctx context.Context, filter models.ScreeningFilter) ([]*models.ScreeningDecision, error)
+
+	// Review resolves the held transfer of a decision and returns the transfer, moved to
+	// processing when released or failed when rejected
+	Review(ctx context.Context, decisionID string, review models.ScreeningReview) (*models.Transfer, error)
 }
@@ -14,6 +14,61 @@ type AccountRepository struct {
 	mock.Mock
 }
 
+// CustomerAccounts provides a mock function with given fields: ctx
+func (_m *AccountRepository) CustomerAccounts(ctx context.Context) ([]*models.Account, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CustomerAccounts")
+	}
+
+	var r0 []*models.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*models.Account, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []*models.Account); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// EnsureSystemAccounts provides a mock function with given fields: ctx, ids
+func (_m *AccountRepository) EnsureSystemAccounts(ctx context.Context, ids ...string) error {
+	_va := make([]interface{}, len(ids))
+	for _i := range ids {
+		_va[_i] = ids[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, ctx)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EnsureSystemAccounts")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, ...string) error); ok {
+		r0 = rf(ctx, ids...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAccount provides a mock function with given fields: ctx, id
 func (_m *AccountRepository) GetAccount(ctx context.Context, id string) (*models.Account, error) {
 	ret := _m.Called(ctx, id)
@@ -44,6 +99,36 @@ func (_m *AccountRepository) GetAccount(ctx context.Context, id string) (*models
 	return r0, r1
 }
 
+// GetAccounts provides a mock function with given fields: ctx, ids
+func (_m *AccountRepository) GetAccounts(ctx context.Context, ids []string) ([]*models.Account, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAccounts")
+	}
+
+	var r0 []*models.Account
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]*models.Account, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*models.Account); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Account)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // InitializeTestData provides a mock function with given fields: ctx
 func (_m *AccountRepository) InitializeTestData(ctx context.Context) error {
 	ret := _m.Called(ctx)
@@ -62,17 +147,17 @@ func (_m *AccountRepository) InitializeTestData(ctx context.Context) error {
 	return r0
 }
 
-// TransferWithinTx provides a mock function with given fields: ctx, fromID, toID, amount
-func (_m *AccountRepository) TransferWithinTx(ctx context.Context, fromID string, toID string, amount float64) error {
-	ret := _m.Called(ctx, fromID, toID, amount)
+// OpenAccount provides a mock function with given fields: ctx, id, holderName
+func (_m *AccountRepository) OpenAccount(ctx context.Context, id string, holderName string) error {
+	ret := _m.Called(ctx, id, holderName)
 
 	if len(ret) == 0 {
-		panic("no return value specified for TransferWithinTx")
+		panic("no return value specified for OpenAccount")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, float64) error); ok {
-		r0 = rf(ctx, fromID, toID, amount)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, id, holderName)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -80,6 +165,36 @@ func (_m *AccountRepository) TransferWithinTx(ctx context.Context, fromID string
 	return r0
 }
 
+// Totals provides a mock function with given fields: ctx
+func (_m *AccountRepository) Totals(ctx context.Context) (*models.AccountTotals, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Totals")
+	}
+
+	var r0 *models.AccountTotals
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (*models.AccountTotals, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) *models.AccountTotals); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccountTotals)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewAccountRepository creates a new instance of AccountRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewAccountRepository(t interface {
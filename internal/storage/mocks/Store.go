@@ -4,10 +4,9 @@ package mocks
 
 import (
 	sql "database/sql"
+	storage "money-transfer/internal/storage"
 
 	mock "github.com/stretchr/testify/mock"
-
-	storage "money-transfer/internal/storage"
 )
 
 // Store is an autogenerated mock type for the Store type
@@ -15,6 +14,26 @@ type Store struct {
 	mock.Mock
 }
 
+// ACH provides a mock function with no fields
+func (_m *Store) ACH() storage.ACHRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ACH")
+	}
+
+	var r0 storage.ACHRepository
+	if rf, ok := ret.Get(0).(func() storage.ACHRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.ACHRepository)
+		}
+	}
+
+	return r0
+}
+
 // Account provides a mock function with no fields
 func (_m *Store) Account() storage.AccountRepository {
 	ret := _m.Called()
@@ -35,6 +54,26 @@ func (_m *Store) Account() storage.AccountRepository {
 	return r0
 }
 
+// Audit provides a mock function with no fields
+func (_m *Store) Audit() storage.AuditRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Audit")
+	}
+
+	var r0 storage.AuditRepository
+	if rf, ok := ret.Get(0).(func() storage.AuditRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.AuditRepository)
+		}
+	}
+
+	return r0
+}
+
 // DB provides a mock function with no fields
 func (_m *Store) DB() *sql.DB {
 	ret := _m.Called()
@@ -55,6 +94,186 @@ func (_m *Store) DB() *sql.DB {
 	return r0
 }
 
+// Funding provides a mock function with no fields
+func (_m *Store) Funding() storage.FundingRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Funding")
+	}
+
+	var r0 storage.FundingRepository
+	if rf, ok := ret.Get(0).(func() storage.FundingRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.FundingRepository)
+		}
+	}
+
+	return r0
+}
+
+// Ledger provides a mock function with no fields
+func (_m *Store) Ledger() storage.LedgerRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ledger")
+	}
+
+	var r0 storage.LedgerRepository
+	if rf, ok := ret.Get(0).(func() storage.LedgerRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.LedgerRepository)
+		}
+	}
+
+	return r0
+}
+
+// Outbox provides a mock function with no fields
+func (_m *Store) Outbox() storage.OutboxRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Outbox")
+	}
+
+	var r0 storage.OutboxRepository
+	if rf, ok := ret.Get(0).(func() storage.OutboxRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.OutboxRepository)
+		}
+	}
+
+	return r0
+}
+
+// PaymentMessage provides a mock function with no fields
+func (_m *Store) PaymentMessage() storage.PaymentMessageRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for PaymentMessage")
+	}
+
+	var r0 storage.PaymentMessageRepository
+	if rf, ok := ret.Get(0).(func() storage.PaymentMessageRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.PaymentMessageRepository)
+		}
+	}
+
+	return r0
+}
+
+// Payout provides a mock function with no fields
+func (_m *Store) Payout() storage.PayoutRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Payout")
+	}
+
+	var r0 storage.PayoutRepository
+	if rf, ok := ret.Get(0).(func() storage.PayoutRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.PayoutRepository)
+		}
+	}
+
+	return r0
+}
+
+// RateLimit provides a mock function with no fields
+func (_m *Store) RateLimit() storage.RateLimitRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for RateLimit")
+	}
+
+	var r0 storage.RateLimitRepository
+	if rf, ok := ret.Get(0).(func() storage.RateLimitRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.RateLimitRepository)
+		}
+	}
+
+	return r0
+}
+
+// Screening provides a mock function with no fields
+func (_m *Store) Screening() storage.ScreeningRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Screening")
+	}
+
+	var r0 storage.ScreeningRepository
+	if rf, ok := ret.Get(0).(func() storage.ScreeningRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.ScreeningRepository)
+		}
+	}
+
+	return r0
+}
+
+// Transfer provides a mock function with no fields
+func (_m *Store) Transfer() storage.TransferRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Transfer")
+	}
+
+	var r0 storage.TransferRepository
+	if rf, ok := ret.Get(0).(func() storage.TransferRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.TransferRepository)
+		}
+	}
+
+	return r0
+}
+
+// Webhook provides a mock function with no fields
+func (_m *Store) Webhook() storage.WebhookRepository {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Webhook")
+	}
+
+	var r0 storage.WebhookRepository
+	if rf, ok := ret.Get(0).(func() storage.WebhookRepository); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(storage.WebhookRepository)
+		}
+	}
+
+	return r0
+}
+
 // NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewStore(t interface {
@@ -3,29 +3,34 @@ package postgres
 import (
 	"context"
 	"database/sql"
-	"log"
+	"fmt"
+	"log/slog"
 
 	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/lib/pq"
 )
 
 // AccountRepository handles all database operations related to accounts
 type AccountRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
 }
 
 // NewAccountRepository creates a new instance of AccountRepository
-func NewAccountRepository(db *sql.DB) *AccountRepository {
+func NewAccountRepository(db *sql.DB, logger *slog.Logger) *AccountRepository {
 	return &AccountRepository{
-		db: db,
+		db:     db,
+		logger: logger,
 	}
 }
 
 // GetAccount retrieves account information by ID
 func (r *AccountRepository) GetAccount(ctx context.Context, id string) (*models.Account, error) {
 	var account models.Account
-	err := r.db.QueryRowContext(ctx, "SELECT id, balance FROM accounts WHERE id = $1", id).
-		Scan(&account.ID, &account.Balance)
+	err := r.db.QueryRowContext(ctx, "SELECT id, balance, holder_name, system FROM accounts WHERE id = $1", id).
+		Scan(&account.ID, &account.Balance, &account.HolderName, &account.System)
 
 	if err == sql.ErrNoRows {
 		return nil, transfererrors.ErrAccountNotFound
@@ -37,93 +42,225 @@ func (r *AccountRepository) GetAccount(ctx context.Context, id string) (*models.
 	return &account, nil
 }
 
-// InitializeTestData populates the database with test accounts
-func (r *AccountRepository) InitializeTestData(ctx context.Context) error {
-	accounts := []struct {
-		id      string
-		balance float64
-	}{
-		{"Mark", 100},
-		{"Jane", 50},
-		{"Adam", 0},
+// GetAccounts retrieves the accounts with the given IDs in one query; unknown IDs are skipped
+func (r *AccountRepository) GetAccounts(ctx context.Context, ids []string) ([]*models.Account, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, balance, holder_name, system FROM accounts WHERE id = ANY($1)", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := make([]*models.Account, 0, len(ids))
+	for rows.Next() {
+		var account models.Account
+		if err := rows.Scan(&account.ID, &account.Balance, &account.HolderName, &account.System); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, &account)
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
+	return accounts, rows.Err()
+}
+
+// Totals returns the number of customer accounts and the sum of their balances
+// System accounts are left out, since their balances stand for money held outside the ledger.
+func (r *AccountRepository) Totals(ctx context.Context) (*models.AccountTotals, error) {
+	var totals models.AccountTotals
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*), COALESCE(SUM(balance), 0) FROM accounts WHERE NOT system").
+		Scan(&totals.Accounts, &totals.Balance)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer func() {
-		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("Error rolling back transaction: %v", err)
+	return &totals, nil
+}
+
+// CustomerAccounts lists the customer accounts ordered by ID
+func (r *AccountRepository) CustomerAccounts(ctx context.Context) ([]*models.Account, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT id, balance, holder_name, system FROM accounts WHERE NOT system ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*models.Account
+	for rows.Next() {
+		var account models.Account
+		if err := rows.Scan(&account.ID, &account.Balance, &account.HolderName, &account.System); err != nil {
+			return nil, err
 		}
-	}()
+		accounts = append(accounts, &account)
+	}
+
+	return accounts, rows.Err()
+}
 
-	for _, acc := range accounts {
-		_, err := tx.ExecContext(ctx,
-			"INSERT INTO accounts (id, balance) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET balance = $2",
-			acc.id, acc.balance)
+// OpenAccount creates a customer account with a zero balance unless an account with its ID exists
+// Accounts created are opened in the ledger and announced with an AccountCreated event; an
+// existing customer account is left as it is. Fails if the account is a system account.
+func (r *AccountRepository) OpenAccount(ctx context.Context, id, holderName string) error {
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO accounts (id, balance, holder_name) VALUES ($1, 0, $2)
+			ON CONFLICT (id) DO NOTHING`,
+			id, holderName)
 		if err != nil {
 			return err
 		}
-	}
+		created, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if created == 0 {
+			var system bool
+			if err := tx.QueryRowContext(ctx, "SELECT system FROM accounts WHERE id = $1", id).Scan(&system); err != nil {
+				return err
+			}
+			if system {
+				return fmt.Errorf("account %s exists and is a system account", id)
+			}
+			return nil
+		}
 
-	return tx.Commit()
+		if err := insertPosting(ctx, tx, id, models.PostingOpening, "", 0, 0); err != nil {
+			return err
+		}
+		payload := models.AccountEventPayload{Account: models.Account{ID: id, HolderName: holderName}}
+		return insertEvent(ctx, tx, models.EventAccountCreated, models.AggregateAccount, id, []string{id}, payload)
+	})
 }
 
-// TransferWithinTx performs a money transfer between accounts within a transaction
-// Uses serializable isolation level to prevent concurrent modifications
-func (r *AccountRepository) TransferWithinTx(ctx context.Context, fromID, toID string, amount float64) error {
-	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable})
-	if err != nil {
-		return err
+// InitializeTestData creates the demo accounts that do not exist yet with their opening balances
+// Existing accounts are left as they are, so that seeding never moves money outside the ledger.
+func (r *AccountRepository) InitializeTestData(ctx context.Context) error {
+	accounts := []models.Account{
+		{ID: "Mark", HolderName: "Mark Smith", Balance: 100},
+		{ID: "Jane", HolderName: "Jane Doe", Balance: 50},
+		{ID: "Adam", HolderName: "Adam Brown", Balance: 0},
 	}
-	defer func() {
-		if err = tx.Rollback(); err != nil && err != sql.ErrTxDone {
-			log.Printf("Error rolling back transaction: %v", err)
+
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var created []models.Account
+		for _, account := range accounts {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO accounts (id, balance, holder_name) VALUES ($1, $2, $3)
+				ON CONFLICT (id) DO NOTHING`,
+				account.ID, account.Balance, account.HolderName)
+			if err != nil {
+				return err
+			}
+			inserted, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if inserted == 0 {
+				continue
+			}
+
+			if err := insertPosting(ctx, tx, account.ID, models.PostingOpening, "", account.Balance, account.Balance); err != nil {
+				return err
+			}
+			created = append(created, account)
 		}
-	}()
 
-	result, err := tx.ExecContext(ctx, `
-		UPDATE accounts 
-		SET balance = balance - $1 
-		WHERE id = $2 AND balance >= $1`,
-		amount, fromID)
-	if err != nil {
-		return err
-	}
+		for _, account := range created {
+			err := insertEvent(ctx, tx, models.EventAccountCreated, models.AggregateAccount, account.ID,
+				[]string{account.ID}, models.AccountEventPayload{Account: account})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
+// EnsureSystemAccounts creates the system accounts that do not exist yet with a zero balance
+// Accounts created are opened in the ledger and announced with an AccountCreated event;
+// existing system accounts are left as they are. Fails if one of ids is a customer account, so
+// that customer money is never treated as held outside the ledger.
+func (r *AccountRepository) EnsureSystemAccounts(ctx context.Context, ids ...string) error {
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		for _, id := range ids {
+			result, err := tx.ExecContext(ctx,
+				"INSERT INTO accounts (id, balance, system) VALUES ($1, 0, TRUE) ON CONFLICT (id) DO NOTHING", id)
+			if err != nil {
+				return err
+			}
+			created, err := result.RowsAffected()
+			if err != nil {
+				return err
+			}
+			if created == 0 {
+				var system bool
+				if err := tx.QueryRowContext(ctx, "SELECT system FROM accounts WHERE id = $1", id).Scan(&system); err != nil {
+					return err
+				}
+				if !system {
+					return fmt.Errorf("account %s exists and is not a system account", id)
+				}
+				continue
+			}
+
+			if err := insertPosting(ctx, tx, id, models.PostingOpening, "", 0, 0); err != nil {
+				return err
+			}
+			payload := models.AccountEventPayload{Account: models.Account{ID: id}}
+			if err := insertEvent(ctx, tx, models.EventAccountCreated, models.AggregateAccount, id,
+				[]string{id}, payload); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-	if rowsAffected == 0 {
+// moveFunds debits fromID and credits toID inside the given transaction and posts both changes
+// to the ledger under transferID
+// fromID may only be overdrawn when overdraft is set and it is a system account, as system
+// accounts are by deposits.
+// Returns the resulting balances, or ErrInsufficientFunds / ErrAccountNotFound without touching any balance
+func moveFunds(
+	ctx context.Context, tx *sql.Tx, transferID, fromID, toID string, amount float64, overdraft bool,
+) (float64, float64, error) {
+	var fromBalance, toBalance float64
+	err := tx.QueryRowContext(ctx, `
+		UPDATE accounts 
+		SET balance = balance - $1 
+		WHERE id = $2 AND (balance >= $1 OR ($3 AND system))
+		RETURNING balance`,
+		amount, fromID, overdraft).Scan(&fromBalance)
+	if err == sql.ErrNoRows {
 		var exists bool
 		err = tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM accounts WHERE id = $1)", fromID).Scan(&exists)
 		if err != nil {
-			return err
+			return 0, 0, err
 		}
 		if !exists {
-			return transfererrors.ErrAccountNotFound
+			return 0, 0, transfererrors.ErrAccountNotFound
 		}
-		return transfererrors.ErrInsufficientFunds
+		return 0, 0, transfererrors.ErrInsufficientFunds
 	}
-
-	result, err = tx.ExecContext(ctx,
-		"UPDATE accounts SET balance = balance + $1 WHERE id = $2",
-		amount, toID)
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
-	rowsAffected, err = result.RowsAffected()
+	err = tx.QueryRowContext(ctx,
+		"UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING balance",
+		amount, toID).Scan(&toBalance)
+	if err == sql.ErrNoRows {
+		return 0, 0, transfererrors.ErrAccountNotFound
+	}
 	if err != nil {
-		return err
+		return 0, 0, err
 	}
 
-	if rowsAffected == 0 {
-		return transfererrors.ErrAccountNotFound
+	if err := insertPosting(ctx, tx, fromID, models.PostingTransfer, transferID, -amount, fromBalance); err != nil {
+		return 0, 0, err
+	}
+	if err := insertPosting(ctx, tx, toID, models.PostingTransfer, transferID, amount, toBalance); err != nil {
+		return 0, 0, err
 	}
 
-	return tx.Commit()
+	return fromBalance, toBalance, nil
 }
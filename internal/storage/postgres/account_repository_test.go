@@ -2,13 +2,12 @@ package postgres
 
 import (
 	"context"
-	"math"
-	"sync"
 	"testing"
-	"time"
 
 	"money-transfer/config"
+	"money-transfer/internal/domain/models"
 	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,7 +17,7 @@ func setupTestDB(t *testing.T) *AccountRepository {
 	t.Helper()
 	cfg := config.LoadTestConfig(t)
 
-	store, err := NewStore(cfg.Database.GetDSN())
+	store, err := NewStore(cfg.Database.GetDSN(), logging.Discard())
 	require.NoError(t, err)
 
 	_, err = store.db.Exec(`
@@ -28,7 +27,8 @@ func setupTestDB(t *testing.T) *AccountRepository {
 	`)
 	require.NoError(t, err)
 
-	_, err = store.db.Exec("TRUNCATE TABLE accounts")
+	_, err = store.db.Exec(`TRUNCATE TABLE accounts, transfers, postings, outbox_events, webhook_subscriptions, webhook_deliveries,
+		rate_limit_buckets, audit_events, payouts, fundings, screening_decisions, payment_messages, payment_transactions, ach_files`)
 	require.NoError(t, err)
 
 	return store.accountRepo.(*AccountRepository)
@@ -77,164 +77,80 @@ func TestAccountRepository_GetAccount(t *testing.T) {
 	}
 }
 
-func TestAccountRepository_TransferWithinTx(t *testing.T) {
+func TestAccountRepository_GetAccounts(t *testing.T) {
 	repo := setupTestDB(t)
 	ctx := context.Background()
+	require.NoError(t, repo.InitializeTestData(ctx))
 
-	err := repo.InitializeTestData(ctx)
+	accounts, err := repo.GetAccounts(ctx, []string{"Mark", "NonExistent", "Jane"})
 	require.NoError(t, err)
 
-	tests := []struct {
-		name          string
-		fromID        string
-		toID          string
-		amount        float64
-		expectedError error
-	}{
-		{
-			name:   "successful transfer",
-			fromID: "Mark",
-			toID:   "Jane",
-			amount: 50,
-		},
-		{
-			name:          "insufficient funds",
-			fromID:        "Adam",
-			toID:          "Jane",
-			amount:        50,
-			expectedError: transfererrors.ErrInsufficientFunds,
-		},
-		{
-			name:          "sender does not exist",
-			fromID:        "NonExistent",
-			toID:          "Jane",
-			amount:        50,
-			expectedError: transfererrors.ErrAccountNotFound,
-		},
-		{
-			name:          "recipient does not exist",
-			fromID:        "Mark",
-			toID:          "NonExistent",
-			amount:        50,
-			expectedError: transfererrors.ErrAccountNotFound,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Get initial balances
-			var fromBalanceBefore, toBalanceBefore float64
-			fromAcc, getErr := repo.GetAccount(ctx, tt.fromID)
-			if getErr == nil {
-				fromBalanceBefore = fromAcc.Balance
-			}
-			toAcc, getErr := repo.GetAccount(ctx, tt.toID)
-			if getErr == nil {
-				toBalanceBefore = toAcc.Balance
-			}
-
-			// Perform transfer
-			err := repo.TransferWithinTx(ctx, tt.fromID, tt.toID, tt.amount)
-
-			if tt.expectedError != nil {
-				assert.ErrorIs(t, err, tt.expectedError)
-
-				// Check that balances haven't changed
-				fromAcc, getErr := repo.GetAccount(ctx, tt.fromID)
-				if getErr == nil {
-					assert.Equal(t, fromBalanceBefore, fromAcc.Balance)
-				}
-				toAcc, getErr := repo.GetAccount(ctx, tt.toID)
-				if getErr == nil {
-					assert.Equal(t, toBalanceBefore, toAcc.Balance)
-				}
-			} else {
-				assert.NoError(t, err)
-
-				// Check new balances
-				fromAcc, getErr := repo.GetAccount(ctx, tt.fromID)
-				require.NoError(t, getErr)
-				assert.Equal(t, fromBalanceBefore-tt.amount, fromAcc.Balance)
-
-				toAcc, getErr := repo.GetAccount(ctx, tt.toID)
-				require.NoError(t, getErr)
-				assert.Equal(t, toBalanceBefore+tt.amount, toAcc.Balance)
-			}
-		})
+	balances := make(map[string]float64)
+	holders := make(map[string]string)
+	for _, account := range accounts {
+		balances[account.ID] = account.Balance
+		holders[account.ID] = account.HolderName
 	}
+	assert.Equal(t, map[string]float64{"Mark": 100, "Jane": 50}, balances)
+	assert.Equal(t, map[string]string{"Mark": "Mark Smith", "Jane": "Jane Doe"}, holders)
 }
 
-func TestAccountRepository_ConcurrentTransfers(t *testing.T) {
+func TestAccountRepository_Totals(t *testing.T) {
 	repo := setupTestDB(t)
 	ctx := context.Background()
+	require.NoError(t, repo.InitializeTestData(ctx))
 
-	err := repo.InitializeTestData(ctx)
+	totals, err := repo.Totals(ctx)
 	require.NoError(t, err)
+	assert.Equal(t, 3, totals.Accounts)
+	assert.Equal(t, 150.0, totals.Balance)
+}
 
-	numTransfers := 10
-	transferAmount := 1.0
+func TestAccountRepository_EnsureSystemAccounts(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, repo.InitializeTestData(ctx))
 
-	markAcc, err := repo.GetAccount(ctx, "Mark")
+	require.NoError(t, repo.EnsureSystemAccounts(ctx, "cash", "clearing"))
+	require.NoError(t, repo.EnsureSystemAccounts(ctx, "cash"), "existing system accounts are left as they are")
+	cash, err := repo.GetAccount(ctx, "cash")
 	require.NoError(t, err)
-	janeAcc, err := repo.GetAccount(ctx, "Jane")
-	require.NoError(t, err)
-
-	initialMarkBalance := markAcc.Balance
-	initialJaneBalance := janeAcc.Balance
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var successfulTransfers int
+	assert.True(t, cash.System)
+	assert.Equal(t, 0.0, cash.Balance)
 
-	for i := 0; i < numTransfers; i++ {
-		wg.Add(2)
-
-		// Transfer from Mark to Jane
-		go func() {
-			defer wg.Done()
-			// Use a separate error variable to avoid shadowing
-			if transferErr := repo.TransferWithinTx(ctx, "Mark", "Jane", transferAmount); transferErr == nil {
-				mu.Lock()
-				successfulTransfers++
-				mu.Unlock()
-			}
-		}()
-
-		// Transfer from Jane to Mark
-		go func() {
-			defer wg.Done()
-			// Use a separate error variable to avoid shadowing
-			if transferErr := repo.TransferWithinTx(ctx, "Jane", "Mark", transferAmount); transferErr == nil {
-				mu.Lock()
-				successfulTransfers++
-				mu.Unlock()
-			}
-		}()
+	// A customer account of the same name is not taken for a system account
+	assert.Error(t, repo.EnsureSystemAccounts(ctx, "settlement", "Mark"))
+	mark, err := repo.GetAccount(ctx, "Mark")
+	require.NoError(t, err)
+	assert.False(t, mark.System)
+	_, err = repo.GetAccount(ctx, "settlement")
+	assert.ErrorIs(t, err, transfererrors.ErrAccountNotFound, "no account is created when one is not a system account")
+}
 
-		time.Sleep(10 * time.Millisecond)
-	}
+func TestAccountRepository_OpenAccount(t *testing.T) {
+	repo := setupTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, repo.InitializeTestData(ctx))
+	require.NoError(t, repo.EnsureSystemAccounts(ctx, "cash"))
 
-	wg.Wait()
+	id := "3f0c2b8e-9d4a-4c1e-8f5b-2a7d6e1c9b30"
+	require.NoError(t, repo.OpenAccount(ctx, id, "Mark Smith"))
+	require.NoError(t, repo.OpenAccount(ctx, "Mark", "Someone Else"), "existing customer accounts are left as they are")
+	assert.Error(t, repo.OpenAccount(ctx, "cash", "Mark Smith"))
 
-	// Check final balances
-	markAcc, err = repo.GetAccount(ctx, "Mark")
-	require.NoError(t, err)
-	janeAcc, err = repo.GetAccount(ctx, "Jane")
+	accounts, err := repo.CustomerAccounts(ctx)
 	require.NoError(t, err)
+	assert.Equal(t, []*models.Account{
+		{ID: id, HolderName: "Mark Smith"},
+		{ID: "Adam", HolderName: "Adam Brown"},
+		{ID: "Jane", Balance: 50, HolderName: "Jane Doe"},
+		{ID: "Mark", Balance: 100, HolderName: "Mark Smith"},
+	}, accounts)
+}
+
+func TestStore_CheckSchema(t *testing.T) {
+	repo := setupTestDB(t)
+	store := &Store{db: repo.db}
 
-	t.Logf("Successful transfers: %d out of %d attempts", successfulTransfers, numTransfers*2)
-	t.Logf("Final balances - Mark: %.2f, Jane: %.2f", markAcc.Balance, janeAcc.Balance)
-
-	totalBalanceBefore := initialMarkBalance + initialJaneBalance
-	totalBalanceAfter := markAcc.Balance + janeAcc.Balance
-	assert.Equal(t, totalBalanceBefore, totalBalanceAfter,
-		"Total balance changed: before=%.2f, after=%.2f", totalBalanceBefore, totalBalanceAfter)
-
-	maxBalanceChange := float64(successfulTransfers) * transferAmount
-	assert.LessOrEqual(t, math.Abs(markAcc.Balance-initialMarkBalance), maxBalanceChange,
-		"Mark's balance changed more than expected: initial=%.2f, final=%.2f, max change=%.2f",
-		initialMarkBalance, markAcc.Balance, maxBalanceChange)
-	assert.LessOrEqual(t, math.Abs(janeAcc.Balance-initialJaneBalance), maxBalanceChange,
-		"Jane's balance changed more than expected: initial=%.2f, final=%.2f, max change=%.2f",
-		initialJaneBalance, janeAcc.Balance, maxBalanceChange)
+	assert.NoError(t, store.CheckSchema(context.Background()))
 }
@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"math"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/lib/pq"
+)
+
+// achFileLockID is the advisory lock serializing the creation of ACH files, "achfil" in ASCII,
+// so that no two files carry the same payout or share a file ID modifier
+const achFileLockID = 0x61636866696c
+
+// fileIDModifiers are the file ID modifiers of the files of a day, in order
+const fileIDModifiers = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// ACHRepository handles all database operations related to the files of the ACH rail
+type ACHRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewACHRepository creates a new instance of ACHRepository
+func NewACHRepository(db *sql.DB, logger *slog.Logger) *ACHRepository {
+	return &ACHRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// NextSequence returns the next sequence number of entry trace numbers
+// Numbers run from 1 to 9999999, the 7 digits trace numbers hold, and then start over.
+func (r *ACHRepository) NextSequence(ctx context.Context) (int, error) {
+	var sequence int
+	err := r.db.QueryRowContext(ctx, "SELECT nextval('ach_trace_sequence')").Scan(&sequence)
+	return sequence, err
+}
+
+// CreateFile records a file carrying up to limit submitted payouts of rail that no file carries
+// yet and returns it with its payouts, oldest first
+// Returns a nil file when there is no such payout. The file ID modifier counts the files created
+// on the same day, starting over after the 36th.
+func (r *ACHRepository) CreateFile(
+	ctx context.Context, rail string, limit int,
+) (file *models.ACHFile, payouts []*models.Payout, err error) {
+	err = runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		file, payouts = nil, nil
+		if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", achFileLockID); err != nil {
+			return err
+		}
+
+		var ids []string
+		err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(array_agg(id ORDER BY created_at), '{}') FROM (
+				SELECT id, created_at FROM payouts
+				WHERE rail = $1 AND status = $2 AND ach_file_id IS NULL
+				ORDER BY created_at
+				LIMIT $3
+			) unfiled`,
+			rail, models.PayoutStatusSubmitted, limit).
+			Scan(pq.Array(&ids))
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		var today int
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM ach_files WHERE created_at >= CURRENT_DATE").Scan(&today)
+		if err != nil {
+			return err
+		}
+		created := &models.ACHFile{IDModifier: string(fileIDModifiers[today%len(fileIDModifiers)])}
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO ach_files (id_modifier) VALUES ($1)
+			RETURNING id, created_at`,
+			created.IDModifier).
+			Scan(&created.ID, &created.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE payouts SET ach_file_id = $1 WHERE id = ANY($2)", created.ID, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+
+		file = created
+		payouts, err = filePayouts(ctx, tx, file)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, payouts, nil
+}
+
+// GetFile retrieves a file with its payouts, oldest first, whatever became of them since
+func (r *ACHRepository) GetFile(ctx context.Context, id string) (file *models.ACHFile, payouts []*models.Payout, err error) {
+	err = runInTx(ctx, r.db, r.logger, snapshot, func(ctx context.Context, tx *sql.Tx) error {
+		file = &models.ACHFile{ID: id}
+		err := tx.QueryRowContext(ctx, "SELECT id_modifier, created_at FROM ach_files WHERE id = $1", id).
+			Scan(&file.IDModifier, &file.CreatedAt)
+		if err == sql.ErrNoRows {
+			return transfererrors.ErrACHFileNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		payouts, err = filePayouts(ctx, tx, file)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, payouts, nil
+}
+
+// filePayouts reads the payouts of file and fills in its number of entries and amount
+func filePayouts(ctx context.Context, tx *sql.Tx, file *models.ACHFile) ([]*models.Payout, error) {
+	rows, err := tx.QueryContext(ctx,
+		"SELECT "+payoutColumns+payoutsFrom+" WHERE p.ach_file_id = $1 ORDER BY p.created_at, p.id", file.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var payouts []*models.Payout
+	var cents int64
+	for rows.Next() {
+		payout, err := scanPayout(rows)
+		if err != nil {
+			return nil, err
+		}
+		payouts = append(payouts, payout)
+		cents += int64(math.Round(payout.Amount * 100))
+	}
+	file.Entries = len(payouts)
+	file.Amount = float64(cents) / 100
+	return payouts, rows.Err()
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACHRepository_Files(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	payoutRepo := NewPayoutRepository(accountRepo.db)
+	repo := NewACHRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	first, err := repo.NextSequence(ctx)
+	require.NoError(t, err)
+	second, err := repo.NextSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, first+1, second)
+
+	file, _, err := repo.CreateFile(ctx, "ach", 10)
+	require.NoError(t, err)
+	assert.Nil(t, file, "no payout is waiting")
+
+	require.NoError(t, accountRepo.EnsureSystemAccounts(ctx, "clearing"))
+	counterparty := models.ExternalAccount{Scheme: models.SchemeABA, Identifier: "021000021123456789", Name: "Jane Doe"}
+	submit := func(rail, reference string, amount float64) *models.Payout {
+		transfer := &models.Transfer{
+			From: "Mark", To: "clearing", Amount: amount, Status: models.TransferStatusCompleted, Counterparty: &counterparty,
+		}
+		require.NoError(t, transferRepo.Create(ctx, transfer))
+		payout := &models.Payout{TransferID: transfer.ID, Rail: rail, Status: models.PayoutStatusPending}
+		require.NoError(t, payoutRepo.Create(ctx, payout))
+		payout.Status, payout.RailReference = models.PayoutStatusSubmitted, reference
+		require.NoError(t, payoutRepo.UpdateStatus(ctx, payout, models.PayoutStatusPending))
+		return payout
+	}
+	mark := submit("ach", "011000010000001", 10.1)
+	jane := submit("ach", "011000010000002", 20.2)
+	submit("simulator", "sim-1", 5)
+	adam := submit("ach", "011000010000003", 1)
+
+	file, payouts, err := repo.CreateFile(ctx, "ach", 2)
+	require.NoError(t, err)
+	require.NotNil(t, file)
+	assert.Equal(t, "A", file.IDModifier)
+	assert.Equal(t, 2, file.Entries)
+	assert.Equal(t, 30.3, file.Amount)
+	require.Len(t, payouts, 2)
+	assert.Equal(t, mark.ID, payouts[0].ID)
+	assert.Equal(t, jane.ID, payouts[1].ID)
+	assert.Equal(t, counterparty, payouts[0].Counterparty)
+
+	// The next file carries the payouts left over and is told apart by its modifier
+	next, payouts, err := repo.CreateFile(ctx, "ach", 2)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "B", next.IDModifier)
+	require.Len(t, payouts, 1)
+	assert.Equal(t, adam.ID, payouts[0].ID)
+
+	empty, _, err := repo.CreateFile(ctx, "ach", 2)
+	require.NoError(t, err)
+	assert.Nil(t, empty)
+
+	// Files are read back with what became of their payouts since
+	jane.Status, jane.FailureReason = models.PayoutStatusReturned, "R01: Insufficient funds"
+	require.NoError(t, payoutRepo.UpdateStatus(ctx, jane, models.PayoutStatusSubmitted))
+	got, payouts, err := repo.GetFile(ctx, file.ID)
+	require.NoError(t, err)
+	assert.Equal(t, file.IDModifier, got.IDModifier)
+	assert.Equal(t, file.CreatedAt.UnixMicro(), got.CreatedAt.UnixMicro())
+	assert.Equal(t, 30.3, got.Amount)
+	require.Len(t, payouts, 2)
+	assert.Equal(t, models.PayoutStatusReturned, payouts[1].Status)
+
+	_, _, err = repo.GetFile(ctx, "missing")
+	assert.ErrorIs(t, err, transfererrors.ErrACHFileNotFound)
+}
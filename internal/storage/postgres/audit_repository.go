@@ -0,0 +1,319 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+
+	"github.com/lib/pq"
+)
+
+// auditSealLockID is the advisory lock key held while sealing audit events ("audit" in ASCII)
+const auditSealLockID = 0x6175646974
+
+// auditColumns lists the columns scanned by scanAuditEvents, in order
+const auditColumns = `sequence, event_id, occurred_at, actor, action, target_type, target_id,
+	COALESCE(transfer_id, ''), account_ids, state_before, state_after, request_id, source_ip,
+	chain_index, COALESCE(prev_hash, ''), COALESCE(hash, '')`
+
+// AuditRepository reads and seals the audit events recorded with every change
+type AuditRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewAuditRepository creates a new instance of AuditRepository
+func NewAuditRepository(db *sql.DB, logger *slog.Logger) *AuditRepository {
+	return &AuditRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// List returns the events matching filter in the order they were recorded
+func (r *AuditRepository) List(ctx context.Context, filter models.AuditFilter) ([]*models.AuditEvent, error) {
+	conditions := []string{"sequence > $1"}
+	args := []any{filter.AfterSequence}
+	where := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Actor != "" {
+		where("actor = $%d", filter.Actor)
+	}
+	if filter.Action != "" {
+		where("action = $%d", filter.Action)
+	}
+	if filter.AccountID != "" {
+		where("account_ids @> ARRAY[$%d]::text[]", filter.AccountID)
+	}
+	if filter.TransferID != "" {
+		where("transfer_id = $%d", filter.TransferID)
+	}
+	if !filter.Since.IsZero() {
+		where("occurred_at >= $%d", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		where("occurred_at < $%d", filter.Until)
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM audit_events
+		WHERE %s
+		ORDER BY sequence
+		LIMIT $%d`,
+		auditColumns, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// Seal links up to limit unsealed events to the end of the hash chain, in the order they
+// were recorded, and returns how many were sealed
+// Events are sealed once committed, so the chain follows commit order rather than sequence;
+// an advisory lock keeps sealers on other replicas from extending the chain concurrently.
+func (r *AuditRepository) Seal(
+	ctx context.Context, limit int, hash func(prevHash string, event *models.AuditEvent) string,
+) (int, error) {
+	sealed := 0
+
+	err := runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var locked bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", auditSealLockID).
+			Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		var index int64
+		var prevHash string
+		err := tx.QueryRowContext(ctx, `
+			SELECT chain_index, hash FROM audit_events
+			WHERE chain_index IS NOT NULL
+			ORDER BY chain_index DESC
+			LIMIT 1`).Scan(&index, &prevHash)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT `+auditColumns+`
+			FROM audit_events
+			WHERE hash IS NULL
+			ORDER BY sequence
+			LIMIT $1`, limit)
+		if err != nil {
+			return err
+		}
+		events, err := scanAuditEvents(rows)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			index++
+			event.ChainIndex = &index
+			event.PrevHash = prevHash
+			event.Hash = hash(prevHash, event)
+
+			_, err := tx.ExecContext(ctx,
+				"UPDATE audit_events SET chain_index = $1, prev_hash = $2, hash = $3 WHERE sequence = $4",
+				index, event.PrevHash, event.Hash, event.Sequence)
+			if err != nil {
+				return err
+			}
+			prevHash = event.Hash
+		}
+		sealed = len(events)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return sealed, nil
+}
+
+// Chain returns up to limit sealed events after chain position afterIndex, in chain order
+func (r *AuditRepository) Chain(ctx context.Context, afterIndex int64, limit int) ([]*models.AuditEvent, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+auditColumns+`
+		FROM audit_events
+		WHERE chain_index > $1
+		ORDER BY chain_index
+		LIMIT $2`,
+		afterIndex, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAuditEvents(rows)
+}
+
+// CountUnsealed returns how many events are waiting to be sealed
+func (r *AuditRepository) CountUnsealed(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_events WHERE hash IS NULL").Scan(&count)
+	return count, err
+}
+
+// scanAuditEvents reads and closes rows selected with auditColumns
+func scanAuditEvents(rows *sql.Rows) ([]*models.AuditEvent, error) {
+	defer rows.Close()
+
+	events := []*models.AuditEvent{}
+	for rows.Next() {
+		var event models.AuditEvent
+		var before []byte
+		var chainIndex sql.NullInt64
+		if err := rows.Scan(
+			&event.Sequence,
+			&event.ID,
+			&event.OccurredAt,
+			&event.Actor,
+			&event.Action,
+			&event.TargetType,
+			&event.TargetID,
+			&event.TransferID,
+			pq.Array(&event.AccountIDs),
+			&before,
+			&event.After,
+			&event.RequestID,
+			&event.SourceIP,
+			&chainIndex,
+			&event.PrevHash,
+			&event.Hash,
+		); err != nil {
+			return nil, err
+		}
+		if before != nil {
+			event.Before = before
+		}
+		if chainIndex.Valid {
+			event.ChainIndex = &chainIndex.Int64
+		}
+		event.OccurredAt = event.OccurredAt.UTC()
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// auditEntry describes a change to record in the audit log
+type auditEntry struct {
+	action     models.AuditAction
+	targetType string
+	targetID   string
+	transferID string
+	accountIDs []string
+	before     any // nil when the target was created
+	after      any
+}
+
+// transferState is the audited state of a transfer changing status
+type transferState struct {
+	Status        models.TransferStatus `json:"status"`
+	FailureReason string                `json:"failure_reason,omitempty"`
+}
+
+// balanceState is the audited state of an account whose balance changes
+type balanceState struct {
+	Balance float64 `json:"balance"`
+}
+
+// insertAudit records entry in the same transaction as the change it describes
+// The actor, request ID and client address are taken from ctx.
+func insertAudit(ctx context.Context, tx *sql.Tx, entry auditEntry) error {
+	var before []byte
+	if entry.before != nil {
+		var err error
+		if before, err = json.Marshal(entry.before); err != nil {
+			return err
+		}
+	}
+	after, err := json.Marshal(entry.after)
+	if err != nil {
+		return err
+	}
+
+	var transferID sql.NullString
+	if entry.transferID != "" {
+		transferID = sql.NullString{String: entry.transferID, Valid: true}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_events (actor, action, target_type, target_id, transfer_id, account_ids,
+			state_before, state_after, request_id, source_ip)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		auditActor(ctx), entry.action, entry.targetType, entry.targetID, transferID, pq.Array(entry.accountIDs),
+		before, after, logging.RequestIDFromContext(ctx), auth.ClientIPFromContext(ctx))
+	return err
+}
+
+// insertStatusAudit records that transfer moved from previous to its current status
+func insertStatusAudit(ctx context.Context, tx *sql.Tx, transfer *models.Transfer, previous models.TransferStatus) error {
+	return insertAudit(ctx, tx, auditEntry{
+		action:     models.AuditTransferStatusChanged,
+		targetType: models.AggregateTransfer,
+		targetID:   transfer.ID,
+		transferID: transfer.ID,
+		accountIDs: []string{transfer.From, transfer.To},
+		before:     transferState{Status: previous},
+		after:      transferState{Status: transfer.Status, FailureReason: transfer.FailureReason},
+	})
+}
+
+// insertBalanceAudits records the balance changes of both accounts of a completed transfer
+func insertBalanceAudits(
+	ctx context.Context, tx *sql.Tx, transfer *models.Transfer, fromBalance, toBalance float64,
+) error {
+	err := insertAudit(ctx, tx, auditEntry{
+		action:     models.AuditAccountDebited,
+		targetType: models.AggregateAccount,
+		targetID:   transfer.From,
+		transferID: transfer.ID,
+		accountIDs: []string{transfer.From},
+		before:     balanceState{Balance: fromBalance + transfer.Amount},
+		after:      balanceState{Balance: fromBalance},
+	})
+	if err != nil {
+		return err
+	}
+
+	return insertAudit(ctx, tx, auditEntry{
+		action:     models.AuditAccountCredited,
+		targetType: models.AggregateAccount,
+		targetID:   transfer.To,
+		transferID: transfer.ID,
+		accountIDs: []string{transfer.To},
+		before:     balanceState{Balance: toBalance - transfer.Amount},
+		after:      balanceState{Balance: toBalance},
+	})
+}
+
+// auditActor identifies who is making the changes of ctx: the authenticated principal,
+// an anonymous API caller, or the system when no request is being served
+func auditActor(ctx context.Context) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		return principal.ID
+	}
+	if logging.RequestIDFromContext(ctx) != "" {
+		return models.ActorAnonymous
+	}
+	return models.ActorSystem
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chainHash is a stand-in for the audit service hash, covering the event ID and its link
+func chainHash(prevHash string, event *models.AuditEvent) string {
+	sum := sha256.Sum256([]byte(prevHash + event.ID + string(event.After)))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAuditRepository_RecordsMutations(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewAuditRepository(accountRepo.db, logging.Discard())
+
+	principal := &models.Principal{ID: "mark", Role: models.RoleCustomer, Accounts: []string{"Mark"}}
+	ctx := auth.WithPrincipal(context.Background(), principal)
+	ctx = logging.WithRequestID(ctx, "req-1")
+	ctx = auth.WithClientIP(ctx, "10.0.0.1")
+
+	transfer := &models.Transfer{From: "Mark", To: "Jane", Amount: 40, Status: models.TransferStatusCreated}
+	require.NoError(t, transferRepo.Create(ctx, transfer))
+	require.NoError(t, transferRepo.UpdateStatus(ctx, transfer.ID,
+		models.TransferStatusCreated, models.TransferStatusProcessing, ""))
+	// Executed in the background, outside any request
+	require.NoError(t, transferRepo.Execute(context.Background(), transfer.ID))
+
+	events, err := repo.List(context.Background(), models.AuditFilter{Limit: 100})
+	require.NoError(t, err)
+	var actions []models.AuditAction
+	for _, event := range events {
+		actions = append(actions, event.Action)
+		assert.Equal(t, transfer.ID, event.TransferID)
+		assert.Nil(t, event.ChainIndex)
+	}
+	assert.Equal(t, []models.AuditAction{
+		models.AuditTransferCreated,
+		models.AuditTransferStatusChanged,
+		models.AuditAccountDebited,
+		models.AuditAccountCredited,
+		models.AuditTransferStatusChanged,
+	}, actions)
+
+	created := events[0]
+	assert.Equal(t, "mark", created.Actor)
+	assert.Equal(t, "req-1", created.RequestID)
+	assert.Equal(t, "10.0.0.1", created.SourceIP)
+	assert.Nil(t, created.Before)
+	assert.ElementsMatch(t, []string{"Mark", "Jane"}, created.AccountIDs)
+
+	debited := events[2]
+	assert.Equal(t, models.ActorSystem, debited.Actor)
+	assert.Equal(t, "Mark", debited.TargetID)
+	assert.JSONEq(t, `{"balance": 100}`, string(debited.Before))
+	assert.JSONEq(t, `{"balance": 60}`, string(debited.After))
+
+	var completed transferState
+	require.NoError(t, json.Unmarshal(events[4].After, &completed))
+	assert.Equal(t, models.TransferStatusCompleted, completed.Status)
+
+	// Filters narrow the log down
+	events, err = repo.List(context.Background(), models.AuditFilter{AccountID: "Jane", Actor: models.ActorSystem, Limit: 100})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, models.AuditAccountCredited, events[0].Action)
+
+	events, err = repo.List(context.Background(), models.AuditFilter{AfterSequence: events[1].Sequence, Limit: 100})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestAuditRepository_Seal(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewAuditRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	createTransfer(t, transferRepo, "Mark", "Jane", 10, models.TransferStatusCreated)
+	createTransfer(t, transferRepo, "Jane", "Mark", 5, models.TransferStatusCreated)
+	createTransfer(t, transferRepo, "Mark", "Adam", 1, models.TransferStatusCreated)
+
+	sealed, err := repo.Seal(ctx, 2, chainHash)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sealed)
+	unsealed, err := repo.CountUnsealed(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, unsealed)
+
+	// Sealing continues the chain where it ended
+	sealed, err = repo.Seal(ctx, 2, chainHash)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sealed)
+
+	chain, err := repo.Chain(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, chain, 3)
+	prevHash := ""
+	for i, event := range chain {
+		require.NotNil(t, event.ChainIndex)
+		assert.Equal(t, int64(i+1), *event.ChainIndex)
+		assert.Equal(t, prevHash, event.PrevHash)
+		assert.Equal(t, chainHash(prevHash, event), event.Hash)
+		prevHash = event.Hash
+	}
+
+	chain, err = repo.Chain(ctx, 2, 10)
+	require.NoError(t, err)
+	assert.Len(t, chain, 1)
+}
+
+func TestAuditRepository_AppendOnly(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewAuditRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	createTransfer(t, transferRepo, "Mark", "Jane", 10, models.TransferStatusCreated)
+	createTransfer(t, transferRepo, "Jane", "Mark", 5, models.TransferStatusCreated)
+	_, err := repo.Seal(ctx, 1, chainHash)
+	require.NoError(t, err)
+
+	statements := []struct {
+		name  string
+		query string
+	}{
+		{name: "alter sealed event", query: "UPDATE audit_events SET actor = 'jane' WHERE hash IS NOT NULL"},
+		{name: "reseal sealed event", query: "UPDATE audit_events SET hash = 'forged' WHERE hash IS NOT NULL"},
+		{name: "alter unsealed event", query: "UPDATE audit_events SET actor = 'jane' WHERE hash IS NULL"},
+		{name: "delete event", query: "DELETE FROM audit_events"},
+	}
+	for _, tt := range statements {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := accountRepo.db.ExecContext(ctx, tt.query)
+			assert.ErrorContains(t, err, "audit events are append-only")
+		})
+	}
+
+	events, err := repo.List(ctx, models.AuditFilter{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, events, 2)
+}
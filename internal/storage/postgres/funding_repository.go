@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// fundingColumns lists the columns scanned by scanFunding, in order
+const fundingColumns = "id, kind, reference, account_id, system_account, amount, transfer_id, created_at"
+
+// FundingRepository handles all database operations related to deposits and withdrawals
+type FundingRepository struct {
+	db *sql.DB
+}
+
+// NewFundingRepository creates a new instance of FundingRepository
+func NewFundingRepository(db *sql.DB) *FundingRepository {
+	return &FundingRepository{
+		db: db,
+	}
+}
+
+// Record stores a deposit or withdrawal whose transfer completed and fills in its ID and creation time
+// A funding whose kind and reference were recorded before is not stored again: funding is filled
+// in from the earlier one and created is false. Returns ErrReferenceConflict when the earlier
+// funding moved another amount or between other accounts.
+func (r *FundingRepository) Record(ctx context.Context, funding *models.Funding) (created bool, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO fundings (kind, reference, account_id, system_account, amount, transfer_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (kind, reference) DO NOTHING
+		RETURNING id, created_at`,
+		funding.Kind, funding.Reference, funding.Account, funding.SystemAccount, funding.Amount, funding.TransferID).
+		Scan(&funding.ID, &funding.CreatedAt)
+	if err == nil {
+		return true, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	existing, err := scanFunding(r.db.QueryRowContext(ctx,
+		"SELECT "+fundingColumns+" FROM fundings WHERE kind = $1 AND reference = $2", funding.Kind, funding.Reference))
+	if err != nil {
+		return false, err
+	}
+	if existing.Account != funding.Account || existing.SystemAccount != funding.SystemAccount ||
+		existing.Amount != funding.Amount {
+		return false, transfererrors.ErrReferenceConflict
+	}
+	*funding = *existing
+	return false, nil
+}
+
+// scanFunding reads a funding selected with fundingColumns
+func scanFunding(row rowScanner) (*models.Funding, error) {
+	var funding models.Funding
+	err := row.Scan(
+		&funding.ID,
+		&funding.Kind,
+		&funding.Reference,
+		&funding.Account,
+		&funding.SystemAccount,
+		&funding.Amount,
+		&funding.TransferID,
+		&funding.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &funding, nil
+}
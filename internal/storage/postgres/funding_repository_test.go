@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFundingRepository_Record(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewFundingRepository(accountRepo.db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.EnsureSystemAccounts(ctx, "cash"))
+	transfer := createTransfer(t, transferRepo, "cash", "Mark", 40, models.TransferStatusCompleted)
+
+	deposit := &models.Funding{
+		Kind: models.FundingDeposit, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 40, TransferID: transfer.ID,
+	}
+	created, err := repo.Record(ctx, deposit)
+	require.NoError(t, err)
+	assert.True(t, created)
+	require.NotEmpty(t, deposit.ID)
+	assert.False(t, deposit.CreatedAt.IsZero())
+
+	t.Run("replayed reference", func(t *testing.T) {
+		replay := &models.Funding{
+			Kind: models.FundingDeposit, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 40, TransferID: transfer.ID,
+		}
+		created, err := repo.Record(ctx, replay)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, deposit.ID, replay.ID)
+		assert.Equal(t, deposit.TransferID, replay.TransferID)
+	})
+
+	t.Run("reused reference", func(t *testing.T) {
+		other := &models.Funding{
+			Kind: models.FundingDeposit, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 50, TransferID: transfer.ID,
+		}
+		_, err := repo.Record(ctx, other)
+		assert.ErrorIs(t, err, transfererrors.ErrReferenceConflict)
+	})
+
+	t.Run("withdrawal with the reference of a deposit", func(t *testing.T) {
+		withdrawn := createTransfer(t, transferRepo, "Mark", "cash", 10, models.TransferStatusCompleted)
+		withdrawal := &models.Funding{
+			Kind: models.FundingWithdrawal, Reference: "dep-1", Account: "Mark", SystemAccount: "cash", Amount: 10, TransferID: withdrawn.ID,
+		}
+		created, err := repo.Record(ctx, withdrawal)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.NotEqual(t, deposit.ID, withdrawal.ID)
+	})
+}
@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/lib/pq"
+)
+
+// snapshot are the options of read-only transactions that must see a single point in time
+var snapshot = &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+
+// LedgerRepository reads the postings recorded with every balance change
+type LedgerRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewLedgerRepository creates a new instance of LedgerRepository
+func NewLedgerRepository(db *sql.DB, logger *slog.Logger) *LedgerRepository {
+	return &LedgerRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// AccountLedger returns the postings of the account made in [from, to) together with the
+// balances they start from and lead to, all read from one snapshot
+// Returns ErrAccountNotFound if the account does not exist.
+func (r *LedgerRepository) AccountLedger(
+	ctx context.Context, accountID string, from, to time.Time,
+) (*models.AccountLedger, error) {
+	ledger := &models.AccountLedger{AccountID: accountID}
+
+	err := runInTx(ctx, r.db, r.logger, snapshot, func(ctx context.Context, tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, "SELECT balance FROM accounts WHERE id = $1", accountID).Scan(&ledger.Balance)
+		if err == sql.ErrNoRows {
+			return transfererrors.ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			SELECT
+				COALESCE((SELECT balance_after FROM postings
+					WHERE account_id = $1 AND posted_at < $2
+					ORDER BY posted_at DESC, id DESC LIMIT 1), 0),
+				COALESCE((SELECT balance_after FROM postings
+					WHERE account_id = $1
+					ORDER BY posted_at DESC, id DESC LIMIT 1), 0)`,
+			accountID, from).Scan(&ledger.OpeningBalance, &ledger.HeadBalance)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT p.id, p.account_id, p.kind, COALESCE(p.transfer_id, ''),
+				COALESCE(CASE WHEN t.from_account = p.account_id THEN t.to_account ELSE t.from_account END, ''),
+				p.amount, p.balance_after, p.posted_at
+			FROM postings p
+			LEFT JOIN transfers t ON t.id = p.transfer_id
+			WHERE p.account_id = $1 AND p.posted_at >= $2 AND p.posted_at < $3
+			ORDER BY p.posted_at, p.id`,
+			accountID, from, to)
+		if err != nil {
+			return err
+		}
+		ledger.Postings, err = scanPostings(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ledger, nil
+}
+
+// BalancesAt returns the balances the accounts had at the time, ordered by account ID
+// An account holds the balance of its last posting made at or before the time, or zero if it
+// had none yet; unknown IDs are skipped.
+func (r *LedgerRepository) BalancesAt(
+	ctx context.Context, accountIDs []string, at time.Time,
+) ([]models.AccountBalance, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT a.id,
+			COALESCE((SELECT p.balance_after FROM postings p
+				WHERE p.account_id = a.id AND p.posted_at <= $2
+				ORDER BY p.posted_at DESC, p.id DESC LIMIT 1), 0)
+		FROM accounts a
+		WHERE a.id = ANY($1)
+		ORDER BY a.id`,
+		pq.Array(accountIDs), at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make([]models.AccountBalance, 0, len(accountIDs))
+	for rows.Next() {
+		var balance models.AccountBalance
+		if err := rows.Scan(&balance.AccountID, &balance.Balance); err != nil {
+			return nil, err
+		}
+		balances = append(balances, balance)
+	}
+
+	return balances, rows.Err()
+}
+
+// Summarize aggregates the whole ledger for reconciliation from one snapshot
+// Transfers are summarized only when their postings do not match them: a completed or reversed
+// transfer must have debited its amount from the source account and credited it to the
+// destination, netting to zero, and any other transfer must have no postings at all. The
+// postings moving a reversed transfer back net to zero as well.
+func (r *LedgerRepository) Summarize(ctx context.Context) (*models.LedgerSummary, error) {
+	summary := &models.LedgerSummary{}
+
+	err := runInTx(ctx, r.db, r.logger, snapshot, func(ctx context.Context, tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*),
+				COALESCE(SUM(amount) FILTER (WHERE kind <> 'transfer'), 0),
+				COALESCE(SUM(amount) FILTER (WHERE kind = 'transfer'), 0)
+			FROM postings`).Scan(&summary.Postings, &summary.FundedTotal, &summary.TransferNet)
+		if err != nil {
+			return err
+		}
+
+		if summary.Accounts, err = r.summarizeAccounts(ctx, tx); err != nil {
+			return err
+		}
+		summary.Transfers, err = r.mismatchedTransfers(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// summarizeAccounts returns every account with the sums of its postings, ordered by ID
+func (r *LedgerRepository) summarizeAccounts(ctx context.Context, tx *sql.Tx) ([]models.AccountSummary, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.balance, COALESCE(SUM(p.amount), 0), COUNT(p.id),
+			COALESCE((SELECT h.balance_after FROM postings h
+				WHERE h.account_id = a.id
+				ORDER BY h.posted_at DESC, h.id DESC LIMIT 1), 0)
+		FROM accounts a
+		LEFT JOIN postings p ON p.account_id = a.id
+		GROUP BY a.id
+		ORDER BY a.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accounts := []models.AccountSummary{}
+	for rows.Next() {
+		var account models.AccountSummary
+		if err := rows.Scan(
+			&account.AccountID,
+			&account.StoredBalance,
+			&account.PostedBalance,
+			&account.Postings,
+			&account.HeadBalance,
+		); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
+
+// mismatchedTransfers returns the transfers whose postings do not match their status and amount
+func (r *LedgerRepository) mismatchedTransfers(ctx context.Context, tx *sql.Tx) ([]models.TransferPostings, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT t.id, t.status, t.amount, COUNT(p.id), COALESCE(SUM(p.amount), 0)
+		FROM transfers t
+		LEFT JOIN postings p ON p.transfer_id = t.id
+		GROUP BY t.id
+		HAVING CASE WHEN t.status IN ('completed', 'reversed') THEN
+				COUNT(*) FILTER (WHERE p.account_id = t.from_account AND p.amount = -t.amount) <> 1
+				OR COUNT(*) FILTER (WHERE p.account_id = t.to_account AND p.amount = t.amount) <> 1
+				OR SUM(p.amount) <> 0
+			ELSE COUNT(p.id) <> 0
+		END
+		ORDER BY t.id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []models.TransferPostings{}
+	for rows.Next() {
+		var transfer models.TransferPostings
+		if err := rows.Scan(
+			&transfer.TransferID,
+			&transfer.Status,
+			&transfer.Amount,
+			&transfer.Postings,
+			&transfer.Net,
+		); err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// scanPostings reads and closes rows of postings joined with their counterparty
+func scanPostings(rows *sql.Rows) ([]*models.Posting, error) {
+	defer rows.Close()
+
+	postings := []*models.Posting{}
+	for rows.Next() {
+		var posting models.Posting
+		if err := rows.Scan(
+			&posting.ID,
+			&posting.AccountID,
+			&posting.Kind,
+			&posting.TransferID,
+			&posting.Counterparty,
+			&posting.Amount,
+			&posting.BalanceAfter,
+			&posting.PostedAt,
+		); err != nil {
+			return nil, err
+		}
+		posting.PostedAt = posting.PostedAt.UTC()
+		postings = append(postings, &posting)
+	}
+
+	return postings, rows.Err()
+}
+
+// insertPosting records a change of the balance of accountID in the same transaction as the change
+func insertPosting(
+	ctx context.Context, tx *sql.Tx, accountID string, kind models.PostingKind, transferID string,
+	amount, balanceAfter float64,
+) error {
+	var transfer sql.NullString
+	if transferID != "" {
+		transfer = sql.NullString{String: transferID, Valid: true}
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO postings (account_id, kind, transfer_id, amount, balance_after)
+		VALUES ($1, $2, $3, $4, $5)`,
+		accountID, kind, transfer, amount, balanceAfter)
+	return err
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerRepository_AccountLedger(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewLedgerRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Minute)
+	transfer := &models.Transfer{From: "Mark", To: "Jane", Amount: 40, Status: models.TransferStatusCreated}
+	require.NoError(t, transferRepo.Create(ctx, transfer))
+	require.NoError(t, transferRepo.UpdateStatus(ctx, transfer.ID,
+		models.TransferStatusCreated, models.TransferStatusProcessing, ""))
+	require.NoError(t, transferRepo.Execute(ctx, transfer.ID))
+	back := executeTransfer(t, transferRepo, "Jane", "Mark", 10)
+	end := time.Now().Add(time.Minute)
+
+	ledger, err := repo.AccountLedger(ctx, "Mark", start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, 70.0, ledger.Balance)
+	assert.Equal(t, 70.0, ledger.HeadBalance)
+	require.Len(t, ledger.Postings, 3)
+	assert.Equal(t, models.PostingOpening, ledger.Postings[0].Kind)
+	assert.Equal(t, 100.0, ledger.Postings[0].BalanceAfter)
+
+	debit := ledger.Postings[1]
+	assert.Equal(t, models.PostingTransfer, debit.Kind)
+	assert.Equal(t, transfer.ID, debit.TransferID)
+	assert.Equal(t, "Jane", debit.Counterparty)
+	assert.Equal(t, -40.0, debit.Amount)
+	assert.Equal(t, 60.0, debit.BalanceAfter)
+
+	credit := ledger.Postings[2]
+	assert.Equal(t, back.ID, credit.TransferID)
+	assert.Equal(t, "Jane", credit.Counterparty)
+	assert.Equal(t, 10.0, credit.Amount)
+	assert.Equal(t, 70.0, credit.BalanceAfter)
+
+	// A period after the postings opens at the head balance and lists nothing
+	later, err := repo.AccountLedger(ctx, "Mark", end, end.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 70.0, later.OpeningBalance)
+	assert.Empty(t, later.Postings)
+
+	_, err = repo.AccountLedger(ctx, "NonExistent", start, end)
+	assert.ErrorIs(t, err, transfererrors.ErrAccountNotFound)
+}
+
+func TestLedgerRepository_BalancesAt(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewLedgerRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	beforeOpening := time.Now().Add(-time.Hour)
+	executeTransfer(t, transferRepo, "Mark", "Jane", 30)
+	afterFirst := time.Now()
+	executeTransfer(t, transferRepo, "Jane", "Mark", 5)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want []models.AccountBalance
+	}{
+		{
+			name: "before the accounts were opened",
+			at:   beforeOpening,
+			want: []models.AccountBalance{{AccountID: "Jane", Balance: 0}, {AccountID: "Mark", Balance: 0}},
+		},
+		{
+			name: "between transfers",
+			at:   afterFirst,
+			want: []models.AccountBalance{{AccountID: "Jane", Balance: 80}, {AccountID: "Mark", Balance: 70}},
+		},
+		{
+			name: "now",
+			at:   time.Now(),
+			want: []models.AccountBalance{{AccountID: "Jane", Balance: 75}, {AccountID: "Mark", Balance: 75}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balances, err := repo.BalancesAt(ctx, []string{"Mark", "Jane", "NonExistent"}, tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, balances)
+		})
+	}
+}
+
+func TestLedgerRepository_Summarize(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewLedgerRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	completed := createTransfer(t, transferRepo, "Mark", "Jane", 40, models.TransferStatusCreated)
+	require.NoError(t, transferRepo.UpdateStatus(ctx, completed.ID,
+		models.TransferStatusCreated, models.TransferStatusProcessing, ""))
+	require.NoError(t, transferRepo.Execute(ctx, completed.ID))
+	createTransfer(t, transferRepo, "Jane", "Adam", 10, models.TransferStatusCreated)
+
+	summary, err := repo.Summarize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, summary.Postings)
+	assert.Equal(t, 150.0, summary.FundedTotal)
+	assert.Equal(t, 0.0, summary.TransferNet)
+	assert.Empty(t, summary.Transfers)
+	assert.Equal(t, []models.AccountSummary{
+		{AccountID: "Adam", StoredBalance: 0, PostedBalance: 0, HeadBalance: 0, Postings: 1},
+		{AccountID: "Jane", StoredBalance: 90, PostedBalance: 90, HeadBalance: 90, Postings: 2},
+		{AccountID: "Mark", StoredBalance: 60, PostedBalance: 60, HeadBalance: 60, Postings: 2},
+	}, summary.Accounts)
+
+	// A balance changed outside the ledger and a transfer missing its credit show up
+	_, err = accountRepo.db.Exec("UPDATE accounts SET balance = 5 WHERE id = 'Adam'")
+	require.NoError(t, err)
+	_, err = accountRepo.db.Exec("DELETE FROM postings WHERE transfer_id = $1 AND amount > 0", completed.ID)
+	require.NoError(t, err)
+
+	summary, err = repo.Summarize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, -40.0, summary.TransferNet)
+	assert.Equal(t, []models.TransferPostings{
+		{TransferID: completed.ID, Status: models.TransferStatusCompleted, Amount: 40, Postings: 1, Net: -40},
+	}, summary.Transfers)
+	assert.Equal(t, 5.0, summary.Accounts[0].StoredBalance)
+	assert.Equal(t, 50.0, summary.Accounts[1].PostedBalance)
+}
+
+func TestAccountRepository_InitializeTestDataKeepsExistingAccounts(t *testing.T) {
+	repo := setupTestDB(t)
+	ledgerRepo := NewLedgerRepository(repo.db, logging.Discard())
+	ctx := context.Background()
+
+	require.NoError(t, repo.InitializeTestData(ctx))
+	executeTransfer(t, NewTransferRepository(repo.db, logging.Discard()), "Mark", "Jane", 25)
+	// Seeding again leaves existing accounts and their ledger as they are
+	require.NoError(t, repo.InitializeTestData(ctx))
+
+	ledger, err := ledgerRepo.AccountLedger(ctx, "Mark", time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	var kinds []models.PostingKind
+	for _, posting := range ledger.Postings {
+		kinds = append(kinds, posting.Kind)
+	}
+	assert.Equal(t, []models.PostingKind{models.PostingOpening, models.PostingTransfer}, kinds)
+	assert.Equal(t, 75.0, ledger.HeadBalance)
+	assert.Equal(t, ledger.Balance, ledger.HeadBalance)
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"money-transfer/internal/domain/models"
+
+	"github.com/lib/pq"
+)
+
+// outboxRelayLockID is the advisory lock key held by the active outbox relay ("outbox" in ASCII)
+const outboxRelayLockID = 0x6f7574626f78
+
+// outboxPositionLockID is the advisory lock key held while positioning committed events ("evtpos" in ASCII)
+const outboxPositionLockID = 0x657674706f73
+
+// eventColumns lists the columns scanned by scanEvents, in order
+const eventColumns = "position, event_id, event_type, aggregate_type, aggregate_id, payload, created_at"
+
+// OutboxRepository handles reading and acknowledging outbox events
+type OutboxRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository
+func NewOutboxRepository(db *sql.DB, logger *slog.Logger) *OutboxRepository {
+	return &OutboxRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Relay positions the events of ended transactions, then hands unpublished events to publish
+// in sequence order and marks the delivered ones
+// An advisory lock keeps relays on other replicas from interleaving deliveries.
+// Delivery stops at the first publish error so later events never overtake earlier ones.
+func (r *OutboxRepository) Relay(
+	ctx context.Context, limit int, publish func(ctx context.Context, event *models.Event) error,
+) (int, error) {
+	if err := r.positionEvents(ctx); err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var publishErr error
+
+	err := runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var locked bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockID).
+			Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		events, err := unpublishedEvents(ctx, tx, limit)
+		if err != nil {
+			return err
+		}
+
+		sequences := make([]int64, 0, len(events))
+		for _, event := range events {
+			if publishErr = publish(ctx, event); publishErr != nil {
+				break
+			}
+			sequences = append(sequences, event.Sequence)
+		}
+		if len(sequences) == 0 {
+			return nil
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE outbox_events SET published_at = NOW() WHERE position = ANY($1)",
+			pq.Array(sequences))
+		if err != nil {
+			return err
+		}
+		published = len(sequences)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return published, publishErr
+}
+
+// AccountEvents returns up to limit events involving the account with a sequence after afterSequence
+// Only events positioned by the relay are returned, whether or not they have been published yet;
+// readers never position events themselves so that they only ever read.
+func (r *OutboxRepository) AccountEvents(
+	ctx context.Context, accountID string, afterSequence int64, limit int,
+) ([]*models.Event, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE account_ids @> ARRAY[$1]::text[] AND position > $2
+		ORDER BY position
+		LIMIT $3`,
+		accountID, afterSequence, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+// LatestSequence returns the sequence of the newest positioned event, or zero when there is none
+func (r *OutboxRepository) LatestSequence(ctx context.Context) (int64, error) {
+	var sequence int64
+	err := r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(position), 0) FROM outbox_events").Scan(&sequence)
+	return sequence, err
+}
+
+// positionEvents gives the events of ended transactions the positions they are read in
+// Writers draw no lock, so an event is positioned only once every transaction that started
+// writing before its own has ended: positions then follow the order of the writing transactions,
+// and readers resuming after a position never miss an event committed later. Only the relay
+// positions events; it skips positioning while the relay of another replica holds the advisory
+// lock, as that relay positions the same events.
+func (r *OutboxRepository) positionEvents(ctx context.Context) error {
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var locked bool
+		if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxPositionLockID).
+			Scan(&locked); err != nil {
+			return err
+		}
+		if !locked {
+			return nil
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			WITH ended AS (
+				SELECT sequence, ROW_NUMBER() OVER (ORDER BY xid, sequence) AS n
+				FROM outbox_events
+				WHERE position IS NULL AND xid < pg_snapshot_xmin(pg_current_snapshot())
+			)
+			UPDATE outbox_events
+			SET position = (SELECT COALESCE(MAX(position), 0) FROM outbox_events) + ended.n
+			FROM ended
+			WHERE outbox_events.sequence = ended.sequence`)
+		return err
+	})
+}
+
+// unpublishedEvents returns the oldest positioned events not yet delivered
+func unpublishedEvents(ctx context.Context, tx *sql.Tx, limit int) ([]*models.Event, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE published_at IS NULL AND position IS NOT NULL
+		ORDER BY position
+		LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEvents(rows)
+}
+
+// scanEvents reads and closes rows selected with eventColumns
+func scanEvents(rows *sql.Rows) ([]*models.Event, error) {
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		var event models.Event
+		if err := rows.Scan(
+			&event.Sequence,
+			&event.ID,
+			&event.Type,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.Payload,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// insertEvent records a domain event in the outbox as part of tx
+// accountIDs lists the accounts whose activity feeds include the event. The event is read once
+// tx ends and every transaction that started writing before it has ended too; see positionEvents.
+func insertEvent(
+	ctx context.Context, tx *sql.Tx, eventType models.EventType, aggregateType, aggregateID string,
+	accountIDs []string, payload any,
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, account_ids, payload)
+		VALUES ($1, $2, $3, $4, $5)`,
+		eventType, aggregateType, aggregateID, pq.Array(accountIDs), data)
+	return err
+}
+
+// insertTransferEvent records that transfer entered its current status
+func insertTransferEvent(
+	ctx context.Context, tx *sql.Tx, transfer *models.Transfer, previous models.TransferStatus,
+) error {
+	return insertEvent(ctx, tx, models.TransferEventType(transfer.Status), models.AggregateTransfer, transfer.ID,
+		[]string{transfer.From, transfer.To}, models.TransferEventPayload{Transfer: *transfer, PreviousStatus: previous})
+}
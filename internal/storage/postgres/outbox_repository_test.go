@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository_Relay(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	outbox := NewOutboxRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	transfer := createTransfer(t, transferRepo, "Mark", "Jane", 40, models.TransferStatusCreated)
+	require.NoError(t, transferRepo.UpdateStatus(ctx, transfer.ID,
+		models.TransferStatusCreated, models.TransferStatusProcessing, ""))
+	require.NoError(t, transferRepo.Execute(ctx, transfer.ID))
+
+	var delivered []*models.Event
+	collect := func(_ context.Context, event *models.Event) error {
+		delivered = append(delivered, event)
+		return nil
+	}
+
+	published, err := outbox.Relay(ctx, 100, collect)
+	require.NoError(t, err)
+
+	// Three accounts were created by InitializeTestData, then the transfer moved through three statuses
+	require.Equal(t, 6, published)
+	var types []models.EventType
+	for _, event := range delivered {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []models.EventType{
+		models.EventAccountCreated,
+		models.EventAccountCreated,
+		models.EventAccountCreated,
+		models.EventTransferCreated,
+		models.EventTransferProcessing,
+		models.EventTransferCompleted,
+	}, types)
+
+	var payload models.TransferEventPayload
+	require.NoError(t, json.Unmarshal(delivered[5].Payload, &payload))
+	assert.Equal(t, transfer.ID, payload.Transfer.ID)
+	assert.Equal(t, models.TransferStatusProcessing, payload.PreviousStatus)
+	require.NotNil(t, payload.FromBalance)
+	assert.Equal(t, 60.0, *payload.FromBalance)
+	require.NotNil(t, payload.ToBalance)
+	assert.Equal(t, 90.0, *payload.ToBalance)
+
+	// Delivered events are not published again
+	published, err = outbox.Relay(ctx, 100, collect)
+	require.NoError(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestOutboxRepository_RelayStopsAtFailure(t *testing.T) {
+	accountRepo, _ := setupTransferTestDB(t)
+	outbox := NewOutboxRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	calls := 0
+	failSecond := func(_ context.Context, _ *models.Event) error {
+		calls++
+		if calls == 2 {
+			return assert.AnError
+		}
+		return nil
+	}
+
+	published, err := outbox.Relay(ctx, 100, failSecond)
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 1, published)
+
+	// The failed event and everything after it are redelivered in order
+	var sequences []int64
+	published, err = outbox.Relay(ctx, 100, func(_ context.Context, event *models.Event) error {
+		sequences = append(sequences, event.Sequence)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Less(t, sequences[0], sequences[1])
+}
+
+// relayAll runs the relay once with a publisher accepting every event
+func relayAll(t *testing.T, outbox *OutboxRepository) {
+	t.Helper()
+	_, err := outbox.Relay(context.Background(), 1000, func(context.Context, *models.Event) error { return nil })
+	require.NoError(t, err)
+}
+
+func TestOutboxRepository_AccountEvents(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	outbox := NewOutboxRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	relayAll(t, outbox)
+	start, err := outbox.LatestSequence(ctx)
+	require.NoError(t, err)
+
+	transfer := createTransfer(t, transferRepo, "Mark", "Jane", 40, models.TransferStatusCreated)
+	createTransfer(t, transferRepo, "Jane", "Adam", 10, models.TransferStatusCreated)
+
+	// Readers only see the events the relay has positioned
+	unpositioned, err := outbox.AccountEvents(ctx, "Mark", start, 100)
+	require.NoError(t, err)
+	assert.Empty(t, unpositioned)
+	relayAll(t, outbox)
+
+	markEvents, err := outbox.AccountEvents(ctx, "Mark", start, 100)
+	require.NoError(t, err)
+	require.Len(t, markEvents, 1)
+	assert.Equal(t, transfer.ID, markEvents[0].AggregateID)
+
+	janeEvents, err := outbox.AccountEvents(ctx, "Jane", start, 100)
+	require.NoError(t, err)
+	require.Len(t, janeEvents, 2)
+	assert.Less(t, janeEvents[0].Sequence, janeEvents[1].Sequence)
+
+	// Resuming after the last seen event returns nothing new
+	resumed, err := outbox.AccountEvents(ctx, "Jane", janeEvents[1].Sequence, 100)
+	require.NoError(t, err)
+	assert.Empty(t, resumed)
+
+	latest, err := outbox.LatestSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, janeEvents[1].Sequence, latest)
+}
+
+func TestOutboxRepository_AccountEventsInterleavedCommits(t *testing.T) {
+	accountRepo, _ := setupTransferTestDB(t)
+	outbox := NewOutboxRepository(accountRepo.db, logging.Discard())
+	ctx := context.Background()
+
+	relayAll(t, outbox)
+	start, err := outbox.LatestSequence(ctx)
+	require.NoError(t, err)
+
+	insert := func(tx *sql.Tx, aggregateID string) error {
+		return insertEvent(ctx, tx, models.EventTransferCreated, models.AggregateTransfer, aggregateID,
+			[]string{"Mark"}, map[string]string{"id": aggregateID})
+	}
+
+	// The first transaction starts writing and stays open
+	first, err := accountRepo.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { _ = first.Rollback() }()
+	_, err = first.ExecContext(ctx, "SELECT pg_current_xact_id()")
+	require.NoError(t, err)
+
+	// A later transaction records and commits its event without waiting for the first one
+	second, err := accountRepo.db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer func() { _ = second.Rollback() }()
+	require.NoError(t, insert(second, "second"))
+	require.NoError(t, second.Commit())
+
+	// The committed event is held back, as the first transaction may still record an event
+	// ordered before it
+	relayAll(t, outbox)
+	seen, err := outbox.AccountEvents(ctx, "Mark", start, 100)
+	require.NoError(t, err)
+	assert.Empty(t, seen)
+
+	require.NoError(t, insert(first, "first"))
+	require.NoError(t, first.Commit())
+
+	relayAll(t, outbox)
+	seen, err = outbox.AccountEvents(ctx, "Mark", start, 100)
+	require.NoError(t, err)
+	require.Len(t, seen, 2)
+	assert.Equal(t, "first", seen[0].AggregateID)
+	assert.Equal(t, "second", seen[1].AggregateID)
+	assert.Less(t, seen[0].Sequence, seen[1].Sequence)
+
+	resumed, err := outbox.AccountEvents(ctx, "Mark", seen[1].Sequence, 100)
+	require.NoError(t, err)
+	assert.Empty(t, resumed)
+}
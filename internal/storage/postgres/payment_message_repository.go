@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// PaymentMessageRepository handles all database operations related to executed payment messages
+type PaymentMessageRepository struct {
+	db *sql.DB
+}
+
+// NewPaymentMessageRepository creates a new instance of PaymentMessageRepository
+func NewPaymentMessageRepository(db *sql.DB) *PaymentMessageRepository {
+	return &PaymentMessageRepository{db: db}
+}
+
+// GetMessage retrieves the message sender executed with messageID
+// Returns ErrPaymentMessageNotFound if sender executed no message with that ID.
+func (r *PaymentMessageRepository) GetMessage(ctx context.Context, sender, messageID string) (*models.PaymentMessage, error) {
+	message := models.PaymentMessage{Sender: sender, MessageID: messageID}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT digest, report, created_at FROM payment_messages WHERE sender = $1 AND message_id = $2`,
+		sender, messageID).
+		Scan(&message.Digest, &message.Report, &message.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, transfererrors.ErrPaymentMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// SaveMessage stores an executed message with its report and fills in its creation time
+// A message saved before is kept, so that when two copies of a message run at once the report
+// of the first to finish is the one repeated; message is filled in from it.
+func (r *PaymentMessageRepository) SaveMessage(ctx context.Context, message *models.PaymentMessage) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payment_messages (sender, message_id, digest, report)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (sender, message_id) DO NOTHING
+		RETURNING created_at`,
+		message.Sender, message.MessageID, message.Digest, string(message.Report)).
+		Scan(&message.CreatedAt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	saved, err := r.GetMessage(ctx, message.Sender, message.MessageID)
+	if err != nil {
+		return err
+	}
+	*message = *saved
+	return nil
+}
+
+// ClaimTransaction records a transaction about to execute and fills in its creation time
+// A transaction claimed before is not claimed again: transaction is filled in from the earlier
+// claim, with its result if it has one, and claimed is false. Claims are never removed; a claim
+// that outlived a crash before its result was stored is resolved by the batch service.
+func (r *PaymentMessageRepository) ClaimTransaction(
+	ctx context.Context, transaction *models.PaymentTransaction,
+) (claimed bool, err error) {
+	err = r.db.QueryRowContext(ctx, `
+		INSERT INTO payment_transactions (debtor_account, end_to_end_id, message_id)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (debtor_account, end_to_end_id) DO NOTHING
+		RETURNING created_at`,
+		transaction.Debtor, transaction.EndToEndID, transaction.MessageID).
+		Scan(&transaction.CreatedAt)
+	if err == nil {
+		return true, nil
+	}
+	if err != sql.ErrNoRows {
+		return false, err
+	}
+
+	err = r.db.QueryRowContext(ctx, `
+		SELECT message_id, result, created_at FROM payment_transactions
+		WHERE debtor_account = $1 AND end_to_end_id = $2`,
+		transaction.Debtor, transaction.EndToEndID).
+		Scan(&transaction.MessageID, &transaction.Result, &transaction.CreatedAt)
+	return false, err
+}
+
+// CompleteTransaction stores the result of a claimed transaction
+func (r *PaymentMessageRepository) CompleteTransaction(ctx context.Context, transaction *models.PaymentTransaction) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE payment_transactions SET result = $3
+		WHERE debtor_account = $1 AND end_to_end_id = $2`,
+		transaction.Debtor, transaction.EndToEndID, string(transaction.Result))
+	return err
+}
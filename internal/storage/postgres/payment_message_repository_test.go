@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentMessageRepository_Messages(t *testing.T) {
+	accountRepo := setupTestDB(t)
+	repo := NewPaymentMessageRepository(accountRepo.db)
+	ctx := context.Background()
+
+	_, err := repo.GetMessage(ctx, "mark", "PAYROLL-2024-02")
+	assert.ErrorIs(t, err, transfererrors.ErrPaymentMessageNotFound)
+
+	message := &models.PaymentMessage{
+		Sender: "mark", MessageID: "PAYROLL-2024-02", Digest: "1f2e", Report: json.RawMessage(`{"GroupStatus":"ACSC"}`),
+	}
+	require.NoError(t, repo.SaveMessage(ctx, message))
+	assert.False(t, message.CreatedAt.IsZero())
+
+	saved, err := repo.GetMessage(ctx, "mark", "PAYROLL-2024-02")
+	require.NoError(t, err)
+	assert.Equal(t, "1f2e", saved.Digest)
+	assert.JSONEq(t, `{"GroupStatus":"ACSC"}`, string(saved.Report))
+
+	// Message IDs are unique per sender only
+	_, err = repo.GetMessage(ctx, "jane", "PAYROLL-2024-02")
+	assert.ErrorIs(t, err, transfererrors.ErrPaymentMessageNotFound)
+
+	t.Run("saved twice", func(t *testing.T) {
+		second := &models.PaymentMessage{
+			Sender: "mark", MessageID: "PAYROLL-2024-02", Digest: "1f2e", Report: json.RawMessage(`{"GroupStatus":"RJCT"}`),
+		}
+		require.NoError(t, repo.SaveMessage(ctx, second))
+		assert.JSONEq(t, `{"GroupStatus":"ACSC"}`, string(second.Report))
+		assert.Equal(t, message.CreatedAt.UnixMicro(), second.CreatedAt.UnixMicro())
+	})
+}
+
+func TestPaymentMessageRepository_Transactions(t *testing.T) {
+	accountRepo := setupTestDB(t)
+	repo := NewPaymentMessageRepository(accountRepo.db)
+	ctx := context.Background()
+
+	claim := &models.PaymentTransaction{Debtor: "Mark", EndToEndID: "SALARY-JANE", MessageID: "PAYROLL-2024-02"}
+	claimed, err := repo.ClaimTransaction(ctx, claim)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A repeat in another message finds the claim still executing
+	repeat := &models.PaymentTransaction{Debtor: "Mark", EndToEndID: "SALARY-JANE", MessageID: "PAYROLL-2024-03"}
+	claimed, err = repo.ClaimTransaction(ctx, repeat)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, "PAYROLL-2024-02", repeat.MessageID)
+	assert.Empty(t, repeat.Result)
+
+	claim.Result = json.RawMessage(`{"Status":"ACSC"}`)
+	require.NoError(t, repo.CompleteTransaction(ctx, claim))
+
+	repeat = &models.PaymentTransaction{Debtor: "Mark", EndToEndID: "SALARY-JANE", MessageID: "PAYROLL-2024-03"}
+	claimed, err = repo.ClaimTransaction(ctx, repeat)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+	assert.JSONEq(t, `{"Status":"ACSC"}`, string(repeat.Result))
+
+	// End-to-end IDs are unique per debtor account only
+	other := &models.PaymentTransaction{Debtor: "Jane", EndToEndID: "SALARY-JANE", MessageID: "PAYROLL-2024-02"}
+	claimed, err = repo.ClaimTransaction(ctx, other)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
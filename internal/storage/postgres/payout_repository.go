@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// payoutColumns lists the columns scanned by scanPayout, in order
+// The source account, amount and counterparty are those of the payout's transfer.
+const payoutColumns = `p.id, p.transfer_id, t.from_account, t.amount, t.counterparty_scheme,
+	t.counterparty_identifier, t.counterparty_name, p.rail, p.status, COALESCE(p.rail_reference, ''),
+	COALESCE(p.settlement_transfer_id, ''), p.failure_reason, p.created_at, p.updated_at`
+
+// payoutsFrom joins payouts to the transfers they were made by
+const payoutsFrom = " FROM payouts p JOIN transfers t ON t.id = p.transfer_id"
+
+// PayoutRepository handles all database operations related to payouts
+type PayoutRepository struct {
+	db *sql.DB
+}
+
+// NewPayoutRepository creates a new instance of PayoutRepository
+func NewPayoutRepository(db *sql.DB) *PayoutRepository {
+	return &PayoutRepository{db: db}
+}
+
+// Create persists a new payout of an existing transfer and fills in its ID and timestamps
+// Returns ErrPayoutExists with payout replaced by the existing one if the transfer made one
+// already, so that a retried payout request gets the payout of its first attempt.
+func (r *PayoutRepository) Create(ctx context.Context, payout *models.Payout) error {
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO payouts (transfer_id, rail, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (transfer_id) DO NOTHING
+		RETURNING id, created_at, updated_at`,
+		payout.TransferID, payout.Rail, payout.Status).
+		Scan(&payout.ID, &payout.CreatedAt, &payout.UpdatedAt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	existing, err := r.GetByTransfer(ctx, payout.TransferID)
+	if err != nil {
+		return err
+	}
+	*payout = *existing
+	return transfererrors.ErrPayoutExists
+}
+
+// GetPayout retrieves a payout by ID
+func (r *PayoutRepository) GetPayout(ctx context.Context, id string) (*models.Payout, error) {
+	return r.getPayout(ctx, "p.id = $1", id)
+}
+
+// GetByReference retrieves the payout the rail assigned reference to
+func (r *PayoutRepository) GetByReference(ctx context.Context, rail, reference string) (*models.Payout, error) {
+	return r.getPayout(ctx, "p.rail = $1 AND p.rail_reference = $2", rail, reference)
+}
+
+// GetByTransfer retrieves the payout made by a transfer
+func (r *PayoutRepository) GetByTransfer(ctx context.Context, transferID string) (*models.Payout, error) {
+	return r.getPayout(ctx, "p.transfer_id = $1", transferID)
+}
+
+func (r *PayoutRepository) getPayout(ctx context.Context, where string, args ...any) (*models.Payout, error) {
+	payout, err := scanPayout(r.db.QueryRowContext(ctx,
+		"SELECT "+payoutColumns+payoutsFrom+" WHERE "+where, args...))
+	if err == sql.ErrNoRows {
+		return nil, transfererrors.ErrPayoutNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+// UpdateStatus stores the status, rail reference, settlement transfer and failure reason of
+// payout if it is still in status from
+// Returns ErrInvalidStatusTransition if the lifecycle forbids the move or another process
+// changed the status first
+func (r *PayoutRepository) UpdateStatus(ctx context.Context, payout *models.Payout, from models.PayoutStatus) error {
+	if !from.CanTransitionTo(payout.Status) {
+		return transfererrors.ErrInvalidStatusTransition
+	}
+
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE payouts
+		SET status = $1, rail_reference = NULLIF($2, ''), settlement_transfer_id = NULLIF($3, ''),
+			failure_reason = $4, updated_at = NOW()
+		WHERE id = $5 AND status = $6
+		RETURNING updated_at`,
+		payout.Status, payout.RailReference, payout.SettlementTransferID, payout.FailureReason, payout.ID, from).
+		Scan(&payout.UpdatedAt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	var exists bool
+	err = r.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM payouts WHERE id = $1)", payout.ID).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return transfererrors.ErrPayoutNotFound
+	}
+	return transfererrors.ErrInvalidStatusTransition
+}
+
+// scanPayout reads a payout selected with payoutColumns
+func scanPayout(row rowScanner) (*models.Payout, error) {
+	var payout models.Payout
+	var scheme, identifier, name sql.NullString
+	err := row.Scan(
+		&payout.ID,
+		&payout.TransferID,
+		&payout.From,
+		&payout.Amount,
+		&scheme,
+		&identifier,
+		&name,
+		&payout.Rail,
+		&payout.Status,
+		&payout.RailReference,
+		&payout.SettlementTransferID,
+		&payout.FailureReason,
+		&payout.CreatedAt,
+		&payout.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	payout.Counterparty = models.ExternalAccount{
+		Scheme:     models.IdentifierScheme(scheme.String),
+		Identifier: identifier.String,
+		Name:       name.String,
+	}
+	return &payout, nil
+}
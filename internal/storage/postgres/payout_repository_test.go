@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPayoutRepository_Lifecycle(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewPayoutRepository(accountRepo.db)
+	ctx := context.Background()
+
+	require.NoError(t, accountRepo.EnsureSystemAccounts(ctx, "clearing"))
+	clearing, err := accountRepo.GetAccount(ctx, "clearing")
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, clearing.Balance)
+	mark, err := accountRepo.GetAccount(ctx, "Mark")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, mark.Balance)
+
+	counterparty := models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "GB82WEST12345698765432", Name: "Jane Doe"}
+	transfer := &models.Transfer{
+		From: "Mark", To: "clearing", Amount: 25, Status: models.TransferStatusCompleted, Counterparty: &counterparty,
+	}
+	require.NoError(t, transferRepo.Create(ctx, transfer))
+
+	payout := &models.Payout{TransferID: transfer.ID, Rail: "simulator", Status: models.PayoutStatusPending}
+	require.NoError(t, repo.Create(ctx, payout))
+	require.NotEmpty(t, payout.ID)
+
+	// A retried request gets the payout its transfer made
+	again := &models.Payout{TransferID: transfer.ID, Rail: "simulator", Status: models.PayoutStatusPending}
+	require.ErrorIs(t, repo.Create(ctx, again), transfererrors.ErrPayoutExists)
+	assert.Equal(t, payout.ID, again.ID)
+	assert.Equal(t, "Mark", again.From)
+
+	payout.Status = models.PayoutStatusSubmitted
+	payout.RailReference = "sim-1"
+	require.NoError(t, repo.UpdateStatus(ctx, payout, models.PayoutStatusPending))
+
+	got, err := repo.GetByReference(ctx, "simulator", "sim-1")
+	require.NoError(t, err)
+	assert.Equal(t, payout.ID, got.ID)
+	assert.Equal(t, "Mark", got.From)
+	assert.Equal(t, 25.0, got.Amount)
+	assert.Equal(t, counterparty, got.Counterparty)
+	assert.Equal(t, models.PayoutStatusSubmitted, got.Status)
+
+	got, err = repo.GetByTransfer(ctx, transfer.ID)
+	require.NoError(t, err)
+	assert.Equal(t, payout.ID, got.ID)
+
+	// A second callback racing the first finds the payout moved on
+	assert.ErrorIs(t, repo.UpdateStatus(ctx, payout, models.PayoutStatusPending), transfererrors.ErrInvalidStatusTransition)
+
+	payout.Status = models.PayoutStatusReturned
+	payout.FailureReason = "account closed"
+	require.NoError(t, repo.UpdateStatus(ctx, payout, models.PayoutStatusSubmitted))
+
+	got, err = repo.GetPayout(ctx, payout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PayoutStatusReturned, got.Status)
+	assert.Equal(t, "sim-1", got.RailReference)
+	assert.Equal(t, "account closed", got.FailureReason)
+
+	_, err = repo.GetPayout(ctx, "missing")
+	assert.ErrorIs(t, err, transfererrors.ErrPayoutNotFound)
+	_, err = repo.GetByReference(ctx, "other", "sim-1")
+	assert.ErrorIs(t, err, transfererrors.ErrPayoutNotFound)
+	_, err = repo.GetByTransfer(ctx, "missing")
+	assert.ErrorIs(t, err, transfererrors.ErrPayoutNotFound)
+
+	missing := &models.Payout{ID: "missing", Status: models.PayoutStatusSettled}
+	assert.ErrorIs(t, repo.UpdateStatus(ctx, missing, models.PayoutStatusSubmitted), transfererrors.ErrPayoutNotFound)
+}
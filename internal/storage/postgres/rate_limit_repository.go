@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"money-transfer/internal/ratelimit"
+)
+
+// availableTokens is the SQL expression of the tokens of bucket b refilled up to now,
+// with $2 the capacity and $3 the tokens regained per second
+const availableTokens = "LEAST($2::float8, b.tokens + EXTRACT(EPOCH FROM now() - b.updated_at)::float8 * $3::float8)"
+
+// RateLimitRepository keeps token buckets in the rate_limit_buckets table
+// Each request takes its token in a single statement, so replicas share buckets without locking.
+type RateLimitRepository struct {
+	db *sql.DB
+}
+
+// NewRateLimitRepository creates a new instance of RateLimitRepository
+func NewRateLimitRepository(db *sql.DB) *RateLimitRepository {
+	return &RateLimitRepository{db: db}
+}
+
+// Allow takes a token from the bucket identified by key, which starts full
+// A denied request leaves the bucket untouched, which is how it would have refilled anyway.
+func (r *RateLimitRepository) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	count, rate := float64(limit.Count), limit.Rate()
+
+	var tokens float64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_buckets AS b (key, tokens, updated_at, full_at)
+		VALUES ($1, $2::float8 - 1, now(), now() + make_interval(secs => 1 / $3::float8))
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = `+availableTokens+` - 1,
+			updated_at = now(),
+			full_at = GREATEST(b.full_at, now()) + make_interval(secs => 1 / $3::float8)
+		WHERE `+availableTokens+` >= 1
+		RETURNING tokens`,
+		key, count, rate).Scan(&tokens)
+	if err == nil {
+		return ratelimit.NewResult(limit, tokens, true), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return ratelimit.Result{}, err
+	}
+
+	// Denied; read how far the bucket has refilled to tell the caller when to retry
+	err = r.db.QueryRowContext(ctx,
+		"SELECT "+availableTokens+" FROM rate_limit_buckets b WHERE b.key = $1",
+		key, count, rate).Scan(&tokens)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Swept in between, so the bucket is full again
+		tokens = count
+	} else if err != nil {
+		return ratelimit.Result{}, err
+	}
+	return ratelimit.NewResult(limit, tokens, false), nil
+}
+
+// Sweep deletes the buckets that have refilled and returns how many were deleted
+func (r *RateLimitRepository) Sweep(ctx context.Context) (int, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM rate_limit_buckets WHERE full_at <= now()")
+	if err != nil {
+		return 0, err
+	}
+	swept, err := result.RowsAffected()
+	return int(swept), err
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"money-transfer/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupRateLimitTestDB(t *testing.T) *RateLimitRepository {
+	t.Helper()
+	return NewRateLimitRepository(setupTestDB(t).db)
+}
+
+func TestRateLimitRepository_Allow(t *testing.T) {
+	repo := setupRateLimitTestDB(t)
+	ctx := context.Background()
+	limit := ratelimit.Limit{Count: 2, Period: time.Hour}
+
+	for _, wantRemaining := range []int{1, 0} {
+		result, err := repo.Allow(ctx, "ip=10.0.0.1", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, wantRemaining, result.Remaining)
+	}
+
+	result, err := repo.Allow(ctx, "ip=10.0.0.1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, 30*time.Minute, result.RetryAfter, float64(time.Second))
+
+	result, err = repo.Allow(ctx, "ip=10.0.0.2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+func TestRateLimitRepository_AllowConcurrently(t *testing.T) {
+	repo := setupRateLimitTestDB(t)
+	limit := ratelimit.Limit{Count: 10, Period: time.Hour}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 30 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := repo.Allow(context.Background(), "principal=mark", limit)
+			assert.NoError(t, err)
+			if result.Allowed {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Replicas share buckets, so no more than Count requests get through
+	assert.Equal(t, limit.Count, allowed)
+}
+
+func TestRateLimitRepository_Sweep(t *testing.T) {
+	repo := setupRateLimitTestDB(t)
+	ctx := context.Background()
+
+	_, err := repo.Allow(ctx, "short", ratelimit.Limit{Count: 100, Period: 100 * time.Millisecond})
+	require.NoError(t, err)
+	_, err = repo.Allow(ctx, "long", ratelimit.Limit{Count: 10, Period: time.Hour})
+	require.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+	swept, err := repo.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, swept)
+
+	var keys []string
+	rows, err := repo.db.QueryContext(ctx, "SELECT key FROM rate_limit_buckets")
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		require.NoError(t, rows.Scan(&key))
+		keys = append(keys, key)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{"long"}, keys)
+}
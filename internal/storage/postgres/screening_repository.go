@@ -0,0 +1,247 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// screeningColumns lists the columns scanned by scanScreeningDecision, in order
+const screeningColumns = `id, transfer_id, outcome, score, party, match_id, match_name, match_program, list_version,
+	COALESCE(resolution, ''), reviewed_by, review_note, reviewed_at, created_at`
+
+// Failure reasons of transfers stopped by sanctions screening
+const (
+	reasonBlocked  = "blocked by sanctions screening"
+	reasonRejected = "rejected in sanctions review"
+)
+
+// ScreeningRepository handles all database operations related to sanctions screening
+type ScreeningRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewScreeningRepository creates a new instance of ScreeningRepository
+func NewScreeningRepository(db *sql.DB, logger *slog.Logger) *ScreeningRepository {
+	return &ScreeningRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Record stores how a created transfer was screened and fills in the ID and creation time of decision
+// A held transfer moves to held and a blocked one to failed in the same transaction; transfer is
+// updated accordingly. Returns ErrInvalidStatusTransition if the transfer is no longer created.
+func (r *ScreeningRepository) Record(
+	ctx context.Context, transfer *models.Transfer, decision *models.ScreeningDecision,
+) error {
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		decision.TransferID = transfer.ID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO screening_decisions (transfer_id, outcome, score, party, match_id, match_name,
+				match_program, list_version)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at`,
+			decision.TransferID, decision.Outcome, decision.Score, decision.Party, decision.MatchID,
+			decision.MatchName, decision.MatchProgram, decision.ListVersion).
+			Scan(&decision.ID, &decision.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		err = insertAudit(ctx, tx, auditEntry{
+			action:     models.AuditTransferScreened,
+			targetType: models.AggregateTransfer,
+			targetID:   transfer.ID,
+			transferID: transfer.ID,
+			accountIDs: []string{transfer.From, transfer.To},
+			after:      decision,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch decision.Outcome {
+		case models.ScreeningHold:
+			return moveScreenedTransfer(ctx, tx, transfer, models.TransferStatusCreated, models.TransferStatusHeld, "")
+		case models.ScreeningBlock:
+			return moveScreenedTransfer(ctx, tx, transfer, models.TransferStatusCreated, models.TransferStatusFailed,
+				reasonBlocked)
+		}
+		return nil
+	})
+}
+
+// List returns up to filter.Limit decisions matching filter, newest first
+func (r *ScreeningRepository) List(
+	ctx context.Context, filter models.ScreeningFilter,
+) ([]*models.ScreeningDecision, error) {
+	conditions := []string{"TRUE"}
+	var args []any
+	if filter.Outcome != "" {
+		args = append(args, filter.Outcome)
+		conditions = append(conditions, fmt.Sprintf("outcome = $%d", len(args)))
+	}
+	if filter.Unreviewed {
+		args = append(args, models.ScreeningHold)
+		conditions = append(conditions, fmt.Sprintf("outcome = $%d AND resolution IS NULL", len(args)))
+	}
+	args = append(args, filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM screening_decisions
+		WHERE %s
+		ORDER BY created_at DESC, id
+		LIMIT $%d`,
+		screeningColumns, strings.Join(conditions, " AND "), len(args)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []*models.ScreeningDecision
+	for rows.Next() {
+		decision, err := scanScreeningDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return decisions, rows.Err()
+}
+
+// Review resolves the held transfer of a decision and returns the transfer
+// A released transfer moves to processing, ready to be executed, and a rejected one to failed;
+// the review and the status change share one transaction. The reviewer is the principal of ctx.
+// Returns ErrScreeningDecisionNotFound if there is no such decision and ErrInvalidStatusTransition
+// if it did not hold its transfer or was already reviewed.
+func (r *ScreeningRepository) Review(
+	ctx context.Context, decisionID string, review models.ScreeningReview,
+) (*models.Transfer, error) {
+	var transfer *models.Transfer
+
+	err := runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		decision, err := scanScreeningDecision(tx.QueryRowContext(ctx,
+			"SELECT "+screeningColumns+" FROM screening_decisions WHERE id = $1 FOR UPDATE", decisionID))
+		if err == sql.ErrNoRows {
+			return transfererrors.ErrScreeningDecisionNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if decision.Outcome != models.ScreeningHold || decision.Resolution != "" {
+			return transfererrors.ErrInvalidStatusTransition
+		}
+
+		before := *decision
+		decision.Resolution = review.Resolution
+		decision.ReviewedBy = auditActor(ctx)
+		decision.ReviewNote = review.Note
+		err = tx.QueryRowContext(ctx, `
+			UPDATE screening_decisions
+			SET resolution = $1, reviewed_by = $2, review_note = $3, reviewed_at = NOW()
+			WHERE id = $4
+			RETURNING reviewed_at`,
+			decision.Resolution, decision.ReviewedBy, decision.ReviewNote, decision.ID).
+			Scan(&decision.ReviewedAt)
+		if err != nil {
+			return err
+		}
+
+		transfer, err = scanTransfer(tx.QueryRowContext(ctx,
+			"SELECT "+transferColumns+" FROM transfers WHERE id = $1", decision.TransferID))
+		if err != nil {
+			return err
+		}
+
+		err = insertAudit(ctx, tx, auditEntry{
+			action:     models.AuditTransferReviewed,
+			targetType: models.AggregateTransfer,
+			targetID:   transfer.ID,
+			transferID: transfer.ID,
+			accountIDs: []string{transfer.From, transfer.To},
+			before:     before,
+			after:      decision,
+		})
+		if err != nil {
+			return err
+		}
+
+		if review.Resolution == models.ScreeningReleased {
+			return moveScreenedTransfer(ctx, tx, transfer, models.TransferStatusHeld, models.TransferStatusProcessing, "")
+		}
+		return moveScreenedTransfer(ctx, tx, transfer, models.TransferStatusHeld, models.TransferStatusFailed,
+			reasonRejected)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// moveScreenedTransfer moves transfer from one status to another as part of tx, recording the
+// change in the audit log and the outbox
+// Returns ErrInvalidStatusTransition if the transfer is no longer in status from.
+func moveScreenedTransfer(
+	ctx context.Context, tx *sql.Tx, transfer *models.Transfer, from, to models.TransferStatus, reason string,
+) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE transfers
+		SET status = $1, failure_reason = $2, updated_at = NOW()
+		WHERE id = $3 AND status = $4
+		RETURNING updated_at`,
+		to, reason, transfer.ID, from).Scan(&transfer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return missingOrStale(ctx, tx, transfer.ID)
+	}
+	if err != nil {
+		return err
+	}
+	transfer.Status = to
+	transfer.FailureReason = reason
+
+	if err := insertStatusAudit(ctx, tx, transfer, from); err != nil {
+		return err
+	}
+
+	return insertTransferEvent(ctx, tx, transfer, from)
+}
+
+// scanScreeningDecision reads a decision selected with screeningColumns
+func scanScreeningDecision(row rowScanner) (*models.ScreeningDecision, error) {
+	var decision models.ScreeningDecision
+	var reviewedAt sql.NullTime
+	err := row.Scan(
+		&decision.ID,
+		&decision.TransferID,
+		&decision.Outcome,
+		&decision.Score,
+		&decision.Party,
+		&decision.MatchID,
+		&decision.MatchName,
+		&decision.MatchProgram,
+		&decision.ListVersion,
+		&decision.Resolution,
+		&decision.ReviewedBy,
+		&decision.ReviewNote,
+		&reviewedAt,
+		&decision.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if reviewedAt.Valid {
+		decision.ReviewedAt = &reviewedAt.Time
+	}
+	return &decision, nil
+}
@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"money-transfer/internal/auth"
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreeningRepository_RecordAndReview(t *testing.T) {
+	accountRepo, transferRepo := setupTransferTestDB(t)
+	repo := NewScreeningRepository(accountRepo.db, accountRepo.logger)
+	ctx := context.Background()
+
+	screen := func(outcome models.ScreeningOutcome, score float64) (*models.Transfer, *models.ScreeningDecision) {
+		transfer := &models.Transfer{From: "Mark", To: "Jane", Amount: 10, Status: models.TransferStatusCreated}
+		require.NoError(t, transferRepo.Create(ctx, transfer))
+		decision := &models.ScreeningDecision{
+			Outcome: outcome, Score: score, Party: "Jane", MatchID: "1001", MatchName: "DOE, Jane", ListVersion: "abc",
+		}
+		require.NoError(t, repo.Record(ctx, transfer, decision))
+		require.NotEmpty(t, decision.ID)
+		assert.Equal(t, transfer.ID, decision.TransferID)
+		return transfer, decision
+	}
+
+	cleared, _ := screen(models.ScreeningClear, 0.4)
+	assert.Equal(t, models.TransferStatusCreated, cleared.Status)
+
+	blocked, _ := screen(models.ScreeningBlock, 0.98)
+	assert.Equal(t, models.TransferStatusFailed, blocked.Status)
+	stored, err := transferRepo.GetTransfer(ctx, blocked.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusFailed, stored.Status)
+	assert.Equal(t, "blocked by sanctions screening", stored.FailureReason)
+
+	released, releasedDecision := screen(models.ScreeningHold, 0.9)
+	assert.Equal(t, models.TransferStatusHeld, released.Status)
+	rejected, rejectedDecision := screen(models.ScreeningHold, 0.88)
+
+	decisions, err := repo.List(ctx, models.ScreeningFilter{Unreviewed: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, rejectedDecision.ID, decisions[0].ID, "newest first")
+	assert.Equal(t, releasedDecision.ID, decisions[1].ID)
+
+	reviewer := auth.WithPrincipal(ctx, &models.Principal{ID: "admin", Role: models.RoleAdmin})
+	transfer, err := repo.Review(reviewer, releasedDecision.ID,
+		models.ScreeningReview{Resolution: models.ScreeningReleased, Note: "different date of birth"})
+	require.NoError(t, err)
+	assert.Equal(t, released.ID, transfer.ID)
+	assert.Equal(t, models.TransferStatusProcessing, transfer.Status)
+
+	transfer, err = repo.Review(reviewer, rejectedDecision.ID, models.ScreeningReview{Resolution: models.ScreeningRejected})
+	require.NoError(t, err)
+	assert.Equal(t, rejected.ID, transfer.ID)
+	assert.Equal(t, models.TransferStatusFailed, transfer.Status)
+	assert.Equal(t, "rejected in sanctions review", transfer.FailureReason)
+
+	decisions, err = repo.List(ctx, models.ScreeningFilter{Outcome: models.ScreeningHold, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, models.ScreeningReleased, decisions[1].Resolution)
+	assert.Equal(t, "admin", decisions[1].ReviewedBy)
+	assert.Equal(t, "different date of birth", decisions[1].ReviewNote)
+	assert.NotNil(t, decisions[1].ReviewedAt)
+
+	decisions, err = repo.List(ctx, models.ScreeningFilter{Unreviewed: true, Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+
+	t.Run("reviewed twice", func(t *testing.T) {
+		_, err := repo.Review(reviewer, releasedDecision.ID, models.ScreeningReview{Resolution: models.ScreeningRejected})
+		assert.ErrorIs(t, err, transfererrors.ErrInvalidStatusTransition)
+	})
+
+	t.Run("unknown decision", func(t *testing.T) {
+		_, err := repo.Review(reviewer, "missing", models.ScreeningReview{Resolution: models.ScreeningReleased})
+		assert.ErrorIs(t, err, transfererrors.ErrScreeningDecisionNotFound)
+	})
+
+	t.Run("transfer moved on", func(t *testing.T) {
+		transfer := &models.Transfer{From: "Mark", To: "Jane", Amount: 10, Status: models.TransferStatusCreated}
+		require.NoError(t, transferRepo.Create(ctx, transfer))
+		require.NoError(t, transferRepo.UpdateStatus(ctx, transfer.ID,
+			models.TransferStatusCreated, models.TransferStatusProcessing, ""))
+
+		decision := &models.ScreeningDecision{Outcome: models.ScreeningHold, Score: 0.9, ListVersion: "abc"}
+		assert.ErrorIs(t, repo.Record(ctx, transfer, decision), transfererrors.ErrInvalidStatusTransition)
+	})
+}
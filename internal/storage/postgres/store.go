@@ -1,54 +1,376 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"strings"
 
 	"money-transfer/internal/storage"
 
-	// Import PostgreSQL driver for side effects - registers postgres driver
-	_ "github.com/lib/pq"
+	"github.com/XSAM/otelsql"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Store implements the Store interface for PostgreSQL database
 type Store struct {
-	db          *sql.DB
-	accountRepo storage.AccountRepository
+	db           *sql.DB
+	accountRepo  storage.AccountRepository
+	transferRepo storage.TransferRepository
+	ledgerRepo   storage.LedgerRepository
+	outboxRepo   storage.OutboxRepository
+	webhookRepo  storage.WebhookRepository
+	rateLimit    storage.RateLimitRepository
+	auditRepo    storage.AuditRepository
+	payoutRepo   storage.PayoutRepository
+	fundingRepo  storage.FundingRepository
+	screening    storage.ScreeningRepository
+	paymentRepo  storage.PaymentMessageRepository
+	achRepo      storage.ACHRepository
 }
 
 // NewStore creates a new instance of Store and initializes the database
-// Returns error if database connection or schema creation fails
-func NewStore(connStr string) (*Store, error) {
-	db, err := sql.Open("postgres", connStr)
+// Repositories log to logger and every statement run for a traced request is recorded as a span;
+// returns error if database connection or schema creation fails
+func NewStore(connStr string, logger *slog.Logger) (*Store, error) {
+	store, err := Open(connStr, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := db.Ping(); err != nil {
+	if err := createSchema(store.db); err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Open connects to the database without creating or migrating the schema, for tools that must
+// not write to it; CheckSchema reports whether the schema was applied
+func Open(connStr string, logger *slog.Logger) (*Store, error) {
+	db, err := otelsql.Open("postgres", connStr,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitConnPrepare:      true,
+			OmitRows:             true,
+			OmitConnectorConnect: true,
+			SpanFilter:           inTrace,
+		}))
+	if err != nil {
 		return nil, err
 	}
 
-	if err := createSchema(db); err != nil {
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
 		return nil, err
 	}
 
 	store := &Store{
 		db: db,
 	}
-	store.accountRepo = NewAccountRepository(db)
+	store.accountRepo = NewAccountRepository(db, logger)
+	store.transferRepo = NewTransferRepository(db, logger)
+	store.ledgerRepo = NewLedgerRepository(db, logger)
+	store.outboxRepo = NewOutboxRepository(db, logger)
+	store.webhookRepo = NewWebhookRepository(db, logger)
+	store.rateLimit = NewRateLimitRepository(db)
+	store.auditRepo = NewAuditRepository(db, logger)
+	store.payoutRepo = NewPayoutRepository(db)
+	store.fundingRepo = NewFundingRepository(db)
+	store.screening = NewScreeningRepository(db, logger)
+	store.paymentRepo = NewPaymentMessageRepository(db)
+	store.achRepo = NewACHRepository(db, logger)
 
 	return store, nil
 }
 
+// inTrace keeps statements run by background polling, which is not part of any trace, out of the traces
+func inTrace(ctx context.Context, _ otelsql.Method, _ string, _ []driver.NamedValue) bool {
+	return trace.SpanContextFromContext(ctx).IsValid()
+}
+
+// schema lists the statements that create the required database objects, in order
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS accounts (
+		id VARCHAR(255) PRIMARY KEY,
+		balance DECIMAL(10, 2) NOT NULL
+	)`,
+	// System accounts stand for money held outside the ledger and may be overdrawn by deposits
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS system BOOLEAN NOT NULL DEFAULT FALSE`,
+	// Names of the customers owning the accounts, screened against sanctions lists
+	`ALTER TABLE accounts ADD COLUMN IF NOT EXISTS holder_name VARCHAR(140) NOT NULL DEFAULT ''`,
+	`CREATE TABLE IF NOT EXISTS transfers (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		from_account VARCHAR(255) NOT NULL,
+		to_account VARCHAR(255) NOT NULL,
+		amount DECIMAL(10, 2) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		failure_reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		counterparty_scheme VARCHAR(20),
+		counterparty_identifier VARCHAR(64),
+		counterparty_name VARCHAR(140)
+	)`,
+	// Tables created before counterparties were introduced gain their columns
+	`ALTER TABLE transfers
+		ADD COLUMN IF NOT EXISTS counterparty_scheme VARCHAR(20),
+		ADD COLUMN IF NOT EXISTS counterparty_identifier VARCHAR(64),
+		ADD COLUMN IF NOT EXISTS counterparty_name VARCHAR(140)`,
+	// Transfers retried by the services of this ledger are made once; failed ones free their key
+	`ALTER TABLE transfers ADD COLUMN IF NOT EXISTS idempotency_key VARCHAR(255)`,
+	`CREATE UNIQUE INDEX IF NOT EXISTS transfers_idempotency_key_idx
+		ON transfers (idempotency_key) WHERE status <> 'failed'`,
+	// Transfers claimed by executor workers are requeued once their lease expires; those executed by
+	// their caller are not. Transfers processing before the column existed keep being requeued.
+	`ALTER TABLE transfers ADD COLUMN IF NOT EXISTS claimed BOOLEAN NOT NULL DEFAULT TRUE`,
+	`ALTER TABLE transfers ALTER COLUMN claimed SET DEFAULT FALSE`,
+	`CREATE INDEX IF NOT EXISTS transfers_pending_idx
+		ON transfers (created_at) WHERE status = 'pending'`,
+	`CREATE INDEX IF NOT EXISTS transfers_from_account_idx ON transfers (from_account, created_at)`,
+	`CREATE INDEX IF NOT EXISTS transfers_to_account_idx ON transfers (to_account, created_at)`,
+	`CREATE TABLE IF NOT EXISTS postings (
+		id BIGSERIAL PRIMARY KEY,
+		account_id VARCHAR(255) NOT NULL REFERENCES accounts (id),
+		kind VARCHAR(20) NOT NULL,
+		transfer_id VARCHAR(36),
+		amount DECIMAL(12, 2) NOT NULL,
+		balance_after DECIMAL(10, 2) NOT NULL,
+		posted_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+	)`,
+	`CREATE INDEX IF NOT EXISTS postings_account_idx ON postings (account_id, posted_at, id)`,
+	`CREATE INDEX IF NOT EXISTS postings_transfer_idx ON postings (transfer_id) WHERE transfer_id IS NOT NULL`,
+	// Accounts created before the ledger start it with the balance they have when it is introduced
+	`INSERT INTO postings (account_id, kind, amount, balance_after)
+		SELECT id, 'opening', balance, balance FROM accounts
+		WHERE NOT EXISTS (SELECT 1 FROM postings WHERE postings.account_id = accounts.id)`,
+	`CREATE TABLE IF NOT EXISTS outbox_events (
+		sequence BIGSERIAL PRIMARY KEY,
+		event_id VARCHAR(36) NOT NULL UNIQUE DEFAULT gen_random_uuid()::text,
+		event_type VARCHAR(64) NOT NULL,
+		aggregate_type VARCHAR(32) NOT NULL,
+		aggregate_id VARCHAR(255) NOT NULL,
+		account_ids TEXT[] NOT NULL DEFAULT '{}',
+		payload JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		published_at TIMESTAMPTZ
+	)`,
+	// Tables created before activity streams gain the accounts events involve
+	`ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS account_ids TEXT[] NOT NULL DEFAULT '{}'`,
+	`CREATE INDEX IF NOT EXISTS outbox_events_account_ids_idx
+		ON outbox_events USING GIN (account_ids)`,
+	// Events are read in the order of the transactions that wrote them, which is known once those
+	// transactions end. Events written before, under a lock held until commit, keep their sequence.
+	`DO $$
+	BEGIN
+		IF NOT EXISTS (SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'outbox_events' AND column_name = 'position') THEN
+			ALTER TABLE outbox_events
+				ADD COLUMN xid xid8 NOT NULL DEFAULT pg_current_xact_id(),
+				ADD COLUMN position BIGINT UNIQUE;
+			UPDATE outbox_events SET position = sequence;
+		END IF;
+	END
+	$$`,
+	`CREATE INDEX IF NOT EXISTS outbox_events_unpositioned_idx
+		ON outbox_events (xid, sequence) WHERE position IS NULL`,
+	`CREATE INDEX IF NOT EXISTS outbox_events_unpublished_position_idx
+		ON outbox_events (position) WHERE published_at IS NULL`,
+	`DROP INDEX IF EXISTS outbox_events_unpublished_idx`,
+	`CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		url TEXT NOT NULL,
+		event_types TEXT[] NOT NULL DEFAULT '{}',
+		secret TEXT NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		subscription_id VARCHAR(36) NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+		event_id VARCHAR(36) NOT NULL,
+		event_type VARCHAR(64) NOT NULL,
+		payload JSONB NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',
+		attempts INT NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		last_response_code INT NOT NULL DEFAULT 0,
+		last_error TEXT NOT NULL DEFAULT '',
+		delivered_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE (subscription_id, event_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx
+		ON webhook_deliveries (next_attempt_at) WHERE status = 'pending'`,
+	`CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+		key TEXT PRIMARY KEY,
+		tokens DOUBLE PRECISION NOT NULL,
+		updated_at TIMESTAMPTZ NOT NULL,
+		full_at TIMESTAMPTZ NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS rate_limit_buckets_full_at_idx ON rate_limit_buckets (full_at)`,
+	`CREATE TABLE IF NOT EXISTS audit_events (
+		sequence BIGSERIAL PRIMARY KEY,
+		event_id VARCHAR(36) NOT NULL UNIQUE DEFAULT gen_random_uuid()::text,
+		occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		actor VARCHAR(255) NOT NULL,
+		action VARCHAR(64) NOT NULL,
+		target_type VARCHAR(32) NOT NULL,
+		target_id VARCHAR(255) NOT NULL,
+		transfer_id VARCHAR(36),
+		account_ids TEXT[] NOT NULL DEFAULT '{}',
+		state_before JSONB,
+		state_after JSONB NOT NULL,
+		request_id VARCHAR(255) NOT NULL DEFAULT '',
+		source_ip VARCHAR(64) NOT NULL DEFAULT '',
+		chain_index BIGINT UNIQUE,
+		prev_hash TEXT,
+		hash TEXT
+	)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_unsealed_idx ON audit_events (sequence) WHERE hash IS NULL`,
+	`CREATE INDEX IF NOT EXISTS audit_events_account_ids_idx ON audit_events USING GIN (account_ids)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_transfer_id_idx ON audit_events (transfer_id)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_actor_idx ON audit_events (actor, sequence)`,
+	`CREATE INDEX IF NOT EXISTS audit_events_occurred_at_idx ON audit_events (occurred_at)`,
+	// Audit events are append-only: the only change allowed is sealing an unsealed event
+	`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+	BEGIN
+		IF TG_OP = 'DELETE' THEN
+			RAISE EXCEPTION 'audit events are append-only';
+		END IF;
+		IF OLD.hash IS NOT NULL
+			OR to_jsonb(NEW) - ARRAY['chain_index', 'prev_hash', 'hash']
+				IS DISTINCT FROM to_jsonb(OLD) - ARRAY['chain_index', 'prev_hash', 'hash'] THEN
+			RAISE EXCEPTION 'audit events are append-only';
+		END IF;
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`CREATE OR REPLACE TRIGGER audit_events_append_only
+		BEFORE UPDATE OR DELETE ON audit_events
+		FOR EACH ROW EXECUTE FUNCTION audit_events_append_only()`,
+	`CREATE TABLE IF NOT EXISTS payouts (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		transfer_id VARCHAR(36) NOT NULL UNIQUE REFERENCES transfers (id),
+		rail VARCHAR(32) NOT NULL,
+		status VARCHAR(20) NOT NULL,
+		rail_reference VARCHAR(64),
+		settlement_transfer_id VARCHAR(36) REFERENCES transfers (id),
+		failure_reason TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE (rail, rail_reference)
+	)`,
+	`CREATE TABLE IF NOT EXISTS fundings (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		kind VARCHAR(20) NOT NULL,
+		reference VARCHAR(64) NOT NULL,
+		account_id VARCHAR(255) NOT NULL REFERENCES accounts (id),
+		system_account VARCHAR(255) NOT NULL REFERENCES accounts (id),
+		amount DECIMAL(10, 2) NOT NULL,
+		transfer_id VARCHAR(36) NOT NULL UNIQUE REFERENCES transfers (id),
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		UNIQUE (kind, reference)
+	)`,
+	`CREATE TABLE IF NOT EXISTS screening_decisions (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		transfer_id VARCHAR(36) NOT NULL REFERENCES transfers (id),
+		outcome VARCHAR(20) NOT NULL,
+		score DOUBLE PRECISION NOT NULL,
+		party VARCHAR(255) NOT NULL DEFAULT '',
+		match_id VARCHAR(32) NOT NULL DEFAULT '',
+		match_name TEXT NOT NULL DEFAULT '',
+		match_program TEXT NOT NULL DEFAULT '',
+		list_version VARCHAR(64) NOT NULL,
+		resolution VARCHAR(20),
+		reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+		review_note TEXT NOT NULL DEFAULT '',
+		reviewed_at TIMESTAMPTZ,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`CREATE INDEX IF NOT EXISTS screening_decisions_unreviewed_idx
+		ON screening_decisions (created_at) WHERE outcome = 'hold' AND resolution IS NULL`,
+	`CREATE INDEX IF NOT EXISTS screening_decisions_transfer_id_idx ON screening_decisions (transfer_id)`,
+	// Executed pain.001 messages and their transactions, kept to answer repeats without moving money again
+	`CREATE TABLE IF NOT EXISTS payment_messages (
+		sender VARCHAR(255) NOT NULL,
+		message_id TEXT NOT NULL,
+		digest CHAR(64) NOT NULL,
+		report JSONB NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (sender, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS payment_transactions (
+		debtor_account VARCHAR(255) NOT NULL,
+		end_to_end_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		result JSONB,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+		PRIMARY KEY (debtor_account, end_to_end_id)
+	)`,
+	// Trace numbers of ACH entries end in a 7 digit sequence number, which starts over after 9999999
+	`CREATE SEQUENCE IF NOT EXISTS ach_trace_sequence MAXVALUE 9999999 CYCLE`,
+	// The references of ACH payouts add the individual ID of their entry to its trace number, so
+	// that they stay unique once trace numbers start over
+	`UPDATE payouts SET rail_reference = rail_reference || '-' || LEFT(REPLACE(id, '-', ''), 15)
+		WHERE rail = 'ach' AND rail_reference NOT LIKE '%-%'`,
+	`CREATE TABLE IF NOT EXISTS ach_files (
+		id VARCHAR(36) PRIMARY KEY DEFAULT gen_random_uuid()::text,
+		id_modifier CHAR(1) NOT NULL,
+		created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`,
+	`ALTER TABLE payouts ADD COLUMN IF NOT EXISTS ach_file_id VARCHAR(36) REFERENCES ach_files (id)`,
+	`CREATE INDEX IF NOT EXISTS payouts_ach_file_id_idx ON payouts (ach_file_id)`,
+	`CREATE INDEX IF NOT EXISTS payouts_unfiled_idx ON payouts (created_at) WHERE ach_file_id IS NULL AND status = 'submitted'`,
+}
+
+// tables lists the tables created by schema
+var tables = []string{
+	"accounts", "transfers", "postings", "outbox_events", "webhook_subscriptions", "webhook_deliveries", "rate_limit_buckets",
+	"audit_events", "payouts", "fundings", "screening_decisions", "payment_messages", "payment_transactions",
+	"ach_files",
+}
+
 // createSchema ensures that the required database tables exist
 func createSchema(db *sql.DB) error {
-	query := `
-		CREATE TABLE IF NOT EXISTS accounts (
-			id VARCHAR(255) PRIMARY KEY,
-			balance DECIMAL(10, 2) NOT NULL
-		)`
+	for _, query := range schema {
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckSchema returns an error naming the tables of the schema that do not exist
+func (s *Store) CheckSchema(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT name FROM unnest($1::text[]) AS name WHERE to_regclass(name) IS NULL", pq.Array(tables))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var missing []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		missing = append(missing, name)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
-	_, err := db.Exec(query)
-	return err
+	if len(missing) > 0 {
+		return fmt.Errorf("schema not applied, missing tables: %s", strings.Join(missing, ", "))
+	}
+	return nil
 }
 
 // DB returns the underlying database connection
@@ -56,7 +378,67 @@ func (s *Store) DB() *sql.DB {
 	return s.db
 }
 
+// Close closes the database connection pool once the queries in progress have finished
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
 // Account returns the account repository instance
 func (s *Store) Account() storage.AccountRepository {
 	return s.accountRepo
 }
+
+// Transfer returns the transfer repository instance
+func (s *Store) Transfer() storage.TransferRepository {
+	return s.transferRepo
+}
+
+// Ledger returns the ledger repository instance
+func (s *Store) Ledger() storage.LedgerRepository {
+	return s.ledgerRepo
+}
+
+// Outbox returns the outbox repository instance
+func (s *Store) Outbox() storage.OutboxRepository {
+	return s.outboxRepo
+}
+
+// Webhook returns the webhook repository instance
+func (s *Store) Webhook() storage.WebhookRepository {
+	return s.webhookRepo
+}
+
+// RateLimit returns the rate limit repository instance
+func (s *Store) RateLimit() storage.RateLimitRepository {
+	return s.rateLimit
+}
+
+// Audit returns the audit repository instance
+func (s *Store) Audit() storage.AuditRepository {
+	return s.auditRepo
+}
+
+// Payout returns the payout repository instance
+func (s *Store) Payout() storage.PayoutRepository {
+	return s.payoutRepo
+}
+
+// Funding returns the funding repository instance
+func (s *Store) Funding() storage.FundingRepository {
+	return s.fundingRepo
+}
+
+// Screening returns the screening repository instance
+func (s *Store) Screening() storage.ScreeningRepository {
+	return s.screening
+}
+
+// PaymentMessage returns the payment message repository instance
+func (s *Store) PaymentMessage() storage.PaymentMessageRepository {
+	return s.paymentRepo
+}
+
+// ACH returns the ACH repository instance
+func (s *Store) ACH() storage.ACHRepository {
+	return s.achRepo
+}
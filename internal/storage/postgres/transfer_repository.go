@@ -0,0 +1,427 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+)
+
+// reasonInterrupted is the failure reason of transfers whose execution by their caller was interrupted
+const reasonInterrupted = "interrupted while processing"
+
+// transferColumns lists the columns scanned by scanTransfer, in order
+const transferColumns = "id, from_account, to_account, amount, status, failure_reason, created_at, updated_at, " +
+	"counterparty_scheme, counterparty_identifier, counterparty_name"
+
+// TransferRepository handles all database operations related to transfers
+type TransferRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewTransferRepository creates a new instance of TransferRepository
+func NewTransferRepository(db *sql.DB, logger *slog.Logger) *TransferRepository {
+	return &TransferRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create persists a new transfer and fills in its ID and timestamps
+// A transfer whose idempotency key is held by a transfer that did not fail is not created:
+// transfer is filled in from that one and ErrTransferExists returned. ErrSerializationFailure is
+// returned if that transfer failed between the insert and the lookup; retrying creates this one.
+func (r *TransferRepository) Create(ctx context.Context, transfer *models.Transfer) error {
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		var scheme, id, name sql.NullString
+		if c := transfer.Counterparty; c != nil {
+			scheme = sql.NullString{String: string(c.Scheme), Valid: true}
+			id = sql.NullString{String: c.Identifier, Valid: true}
+			name = sql.NullString{String: c.Name, Valid: true}
+		}
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO transfers (
+				from_account, to_account, amount, status,
+				counterparty_scheme, counterparty_identifier, counterparty_name, idempotency_key
+			)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''))
+			ON CONFLICT (idempotency_key) WHERE status <> 'failed' DO NOTHING
+			RETURNING id, created_at, updated_at`,
+			transfer.From, transfer.To, transfer.Amount, transfer.Status, scheme, id, name, transfer.IdempotencyKey).
+			Scan(&transfer.ID, &transfer.CreatedAt, &transfer.UpdatedAt)
+		if err == sql.ErrNoRows {
+			existing, err := scanTransfer(tx.QueryRowContext(ctx,
+				"SELECT "+transferColumns+" FROM transfers WHERE idempotency_key = $1 AND status <> 'failed'",
+				transfer.IdempotencyKey))
+			if err == sql.ErrNoRows {
+				// The transfer holding the key failed meanwhile; retrying creates this one
+				return transfererrors.ErrSerializationFailure
+			}
+			if err != nil {
+				return err
+			}
+			existing.IdempotencyKey = transfer.IdempotencyKey
+			*transfer = *existing
+			return transfererrors.ErrTransferExists
+		}
+		if err != nil {
+			return err
+		}
+
+		err = insertAudit(ctx, tx, auditEntry{
+			action:     models.AuditTransferCreated,
+			targetType: models.AggregateTransfer,
+			targetID:   transfer.ID,
+			transferID: transfer.ID,
+			accountIDs: []string{transfer.From, transfer.To},
+			after:      transfer,
+		})
+		if err != nil {
+			return err
+		}
+
+		return insertTransferEvent(ctx, tx, transfer, "")
+	})
+}
+
+// GetTransfer retrieves a transfer by ID
+func (r *TransferRepository) GetTransfer(ctx context.Context, id string) (*models.Transfer, error) {
+	transfer, err := scanTransfer(r.db.QueryRowContext(ctx,
+		"SELECT "+transferColumns+" FROM transfers WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, transfererrors.ErrTransferNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// UpdateStatus moves a transfer to a new status if it is still in the expected one
+// Returns ErrInvalidStatusTransition if the lifecycle forbids the move or another
+// process changed the status first
+func (r *TransferRepository) UpdateStatus(
+	ctx context.Context, id string, from, to models.TransferStatus, reason string,
+) error {
+	if !from.CanTransitionTo(to) {
+		return transfererrors.ErrInvalidStatusTransition
+	}
+
+	return runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		transfer, err := scanTransfer(tx.QueryRowContext(ctx, `
+			UPDATE transfers
+			SET status = $1, failure_reason = $2, updated_at = NOW()
+			WHERE id = $3 AND status = $4
+			RETURNING `+transferColumns,
+			to, reason, id, from))
+		if err == sql.ErrNoRows {
+			return missingOrStale(ctx, tx, id)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := insertStatusAudit(ctx, tx, transfer, from); err != nil {
+			return err
+		}
+
+		return insertTransferEvent(ctx, tx, transfer, from)
+	})
+}
+
+// Execute moves the funds of a processing transfer and marks it completed
+// Balance changes, the status change and the TransferCompleted event share one serializable transaction.
+// A system source account may be overdrawn: only the services of this ledger move money out of
+// system accounts, such as deposits out of cash.
+func (r *TransferRepository) Execute(ctx context.Context, id string) error {
+	return runInTx(ctx, r.db, r.logger, serializable, func(ctx context.Context, tx *sql.Tx) error {
+		transfer, err := scanTransfer(tx.QueryRowContext(ctx,
+			"SELECT "+transferColumns+" FROM transfers WHERE id = $1 FOR UPDATE", id))
+		if err == sql.ErrNoRows {
+			return transfererrors.ErrTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := transfer.Status
+		if !previous.CanTransitionTo(models.TransferStatusCompleted) {
+			return transfererrors.ErrInvalidStatusTransition
+		}
+
+		fromBalance, toBalance, err := moveFunds(ctx, tx, transfer.ID, transfer.From, transfer.To, transfer.Amount, true)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx,
+			"UPDATE transfers SET status = $1, updated_at = NOW() WHERE id = $2 RETURNING updated_at",
+			models.TransferStatusCompleted, id).Scan(&transfer.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		transfer.Status = models.TransferStatusCompleted
+
+		if err := insertBalanceAudits(ctx, tx, transfer, fromBalance, toBalance); err != nil {
+			return err
+		}
+		if err := insertStatusAudit(ctx, tx, transfer, previous); err != nil {
+			return err
+		}
+
+		return insertEvent(ctx, tx, models.EventTransferCompleted, models.AggregateTransfer, transfer.ID,
+			[]string{transfer.From, transfer.To}, models.TransferEventPayload{
+				Transfer:       *transfer,
+				PreviousStatus: previous,
+				FromBalance:    &fromBalance,
+				ToBalance:      &toBalance,
+			})
+	})
+}
+
+// Reverse moves the funds of a completed transfer back to its source account and marks it reversed
+// The reversing postings carry the transfer's ID, so that its postings still net to zero.
+// Returns ErrInvalidStatusTransition if the transfer is not completed, and ErrInsufficientFunds
+// if its destination account, unless a system account, no longer holds the amount.
+func (r *TransferRepository) Reverse(ctx context.Context, id string, reason string) error {
+	return runInTx(ctx, r.db, r.logger, serializable, func(ctx context.Context, tx *sql.Tx) error {
+		transfer, err := scanTransfer(tx.QueryRowContext(ctx,
+			"SELECT "+transferColumns+" FROM transfers WHERE id = $1 FOR UPDATE", id))
+		if err == sql.ErrNoRows {
+			return transfererrors.ErrTransferNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		previous := transfer.Status
+		if !previous.CanTransitionTo(models.TransferStatusReversed) {
+			return transfererrors.ErrInvalidStatusTransition
+		}
+
+		toBalance, fromBalance, err := moveFunds(ctx, tx, transfer.ID, transfer.To, transfer.From, transfer.Amount, true)
+		if err != nil {
+			return err
+		}
+
+		err = tx.QueryRowContext(ctx,
+			"UPDATE transfers SET status = $1, failure_reason = $2, updated_at = NOW() WHERE id = $3 RETURNING updated_at",
+			models.TransferStatusReversed, reason, id).Scan(&transfer.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		transfer.Status = models.TransferStatusReversed
+		transfer.FailureReason = reason
+
+		// The destination is debited and the source credited
+		reversal := *transfer
+		reversal.From, reversal.To = transfer.To, transfer.From
+		if err := insertBalanceAudits(ctx, tx, &reversal, toBalance, fromBalance); err != nil {
+			return err
+		}
+		if err := insertStatusAudit(ctx, tx, transfer, previous); err != nil {
+			return err
+		}
+
+		return insertEvent(ctx, tx, models.EventTransferReversed, models.AggregateTransfer, transfer.ID,
+			[]string{transfer.From, transfer.To}, models.TransferEventPayload{
+				Transfer:       *transfer,
+				PreviousStatus: previous,
+				FromBalance:    &fromBalance,
+				ToBalance:      &toBalance,
+			})
+	})
+}
+
+// ClaimPending moves up to limit pending transfers to processing and returns them
+// Rows locked by other workers are skipped so each transfer is claimed once
+func (r *TransferRepository) ClaimPending(ctx context.Context, limit int) ([]*models.Transfer, error) {
+	return r.moveBatch(ctx, models.TransferStatusPending, models.TransferStatusProcessing, true, "", `
+		SELECT id FROM transfers
+		WHERE status = $2
+		ORDER BY created_at
+		LIMIT $5
+		FOR UPDATE SKIP LOCKED`, limit)
+}
+
+// RequeueStale moves up to limit claimed transfers that have been processing for longer than lease
+// back to pending and returns them
+// Their funds have not moved, since Execute completes a transfer in the transaction moving them.
+// Transfers being executed hold a row lock and are skipped, as are transfers executed by their
+// caller rather than claimed: the caller may still be executing them.
+func (r *TransferRepository) RequeueStale(
+	ctx context.Context, lease time.Duration, limit int,
+) ([]*models.Transfer, error) {
+	return r.moveBatch(ctx, models.TransferStatusProcessing, models.TransferStatusPending, false, "", `
+		SELECT id FROM transfers
+		WHERE status = $2 AND claimed AND updated_at < NOW() - make_interval(secs => $6)
+		ORDER BY updated_at
+		LIMIT $5
+		FOR UPDATE SKIP LOCKED`, limit, lease.Seconds())
+}
+
+// FailStale marks failed up to limit transfers executed by their caller that have been processing
+// for longer than lease and returns them
+// The process executing them stopped before their funds moved, so its caller never learned the
+// outcome; a caller still executing one finds it failed. Transfers being executed are skipped.
+func (r *TransferRepository) FailStale(
+	ctx context.Context, lease time.Duration, limit int,
+) ([]*models.Transfer, error) {
+	return r.moveBatch(ctx, models.TransferStatusProcessing, models.TransferStatusFailed, false, reasonInterrupted, `
+		SELECT id FROM transfers
+		WHERE status = $2 AND NOT claimed AND updated_at < NOW() - make_interval(secs => $6)
+		ORDER BY updated_at
+		LIMIT $5
+		FOR UPDATE SKIP LOCKED`, limit, lease.Seconds())
+}
+
+// moveBatch moves the transfers selected by query from one status to another, recording whether
+// they are claimed and the failure reason, and returns them
+// query selects IDs of transfers in status $2, at most $5 of them; args fill in $5 onwards.
+func (r *TransferRepository) moveBatch(
+	ctx context.Context, from, to models.TransferStatus, claimed bool, reason string, query string, args ...any,
+) ([]*models.Transfer, error) {
+	var transfers []*models.Transfer
+
+	err := runInTx(ctx, r.db, r.logger, nil, func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, `
+			UPDATE transfers
+			SET status = $1, claimed = $3, failure_reason = $4, updated_at = NOW()
+			WHERE id IN (`+query+`)
+			RETURNING `+transferColumns,
+			append([]any{to, from, claimed, reason}, args...)...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			transfer, err := scanTransfer(rows)
+			if err != nil {
+				return err
+			}
+			transfers = append(transfers, transfer)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		rows.Close()
+
+		for _, transfer := range transfers {
+			if err := insertStatusAudit(ctx, tx, transfer, from); err != nil {
+				return err
+			}
+			if err := insertTransferEvent(ctx, tx, transfer, from); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transfers, nil
+}
+
+// ListByAccount returns the most recent transfers from or to the account, newest first
+func (r *TransferRepository) ListByAccount(ctx context.Context, accountID string, limit int) ([]*models.Transfer, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+transferColumns+`
+		FROM transfers
+		WHERE from_account = $1 OR to_account = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transfers := []*models.Transfer{}
+	for rows.Next() {
+		transfer, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	return transfers, rows.Err()
+}
+
+// CountByStatus returns how many transfers are in each status; statuses without transfers are omitted
+func (r *TransferRepository) CountByStatus(ctx context.Context) (map[models.TransferStatus]int, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT status, COUNT(*) FROM transfers GROUP BY status")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.TransferStatus]int)
+	for rows.Next() {
+		var status models.TransferStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// missingOrStale distinguishes a missing transfer from a lost status race
+func missingOrStale(ctx context.Context, tx *sql.Tx, id string) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM transfers WHERE id = $1)", id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return transfererrors.ErrTransferNotFound
+	}
+	return transfererrors.ErrInvalidStatusTransition
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanTransfer reads a transfer selected with transferColumns
+func scanTransfer(row rowScanner) (*models.Transfer, error) {
+	var transfer models.Transfer
+	var scheme, id, name sql.NullString
+	err := row.Scan(
+		&transfer.ID,
+		&transfer.From,
+		&transfer.To,
+		&transfer.Amount,
+		&transfer.Status,
+		&transfer.FailureReason,
+		&transfer.CreatedAt,
+		&transfer.UpdatedAt,
+		&scheme,
+		&id,
+		&name,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if scheme.Valid {
+		transfer.Counterparty = &models.ExternalAccount{
+			Scheme:     models.IdentifierScheme(scheme.String),
+			Identifier: id.String,
+			Name:       name.String,
+		}
+	}
+	return &transfer, nil
+}
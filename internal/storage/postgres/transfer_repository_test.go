@@ -0,0 +1,353 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupTransferTestDB(t *testing.T) (*AccountRepository, *TransferRepository) {
+	t.Helper()
+	accountRepo := setupTestDB(t)
+	require.NoError(t, accountRepo.InitializeTestData(context.Background()))
+
+	return accountRepo, NewTransferRepository(accountRepo.db, logging.Discard())
+}
+
+func createTransfer(t *testing.T, repo *TransferRepository, from, to string, amount float64,
+	status models.TransferStatus) *models.Transfer {
+	t.Helper()
+	transfer := &models.Transfer{From: from, To: to, Amount: amount, Status: status}
+	require.NoError(t, repo.Create(context.Background(), transfer))
+	require.NotEmpty(t, transfer.ID)
+	return transfer
+}
+
+// executeTransfer moves amount between two accounts through a completed transfer
+func executeTransfer(t *testing.T, repo *TransferRepository, from, to string, amount float64) *models.Transfer {
+	t.Helper()
+	transfer := createTransfer(t, repo, from, to, amount, models.TransferStatusProcessing)
+	require.NoError(t, repo.Execute(context.Background(), transfer.ID))
+	return transfer
+}
+
+func TestTransferRepository_CreateAndGet(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	created := createTransfer(t, repo, "Mark", "Jane", 25, models.TransferStatusCreated)
+
+	transfer, err := repo.GetTransfer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Mark", transfer.From)
+	assert.Equal(t, "Jane", transfer.To)
+	assert.Equal(t, 25.0, transfer.Amount)
+	assert.Equal(t, models.TransferStatusCreated, transfer.Status)
+	assert.Nil(t, transfer.Counterparty)
+
+	_, err = repo.GetTransfer(ctx, "missing")
+	assert.ErrorIs(t, err, transfererrors.ErrTransferNotFound)
+}
+
+func TestTransferRepository_CreateWithCounterparty(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	counterparty := &models.ExternalAccount{Scheme: models.SchemeIBAN, Identifier: "GB82WEST12345698765432", Name: "Jane Doe"}
+	created := &models.Transfer{From: "Mark", To: "Jane", Amount: 25, Status: models.TransferStatusCreated, Counterparty: counterparty}
+	require.NoError(t, repo.Create(ctx, created))
+
+	transfer, err := repo.GetTransfer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, counterparty, transfer.Counterparty)
+}
+
+func TestTransferRepository_CreateIdempotent(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	first := &models.Transfer{From: "Mark", To: "Jane", Amount: 25, Status: models.TransferStatusCreated, IdempotencyKey: "k-1"}
+	require.NoError(t, repo.Create(ctx, first))
+
+	again := &models.Transfer{From: "Mark", To: "Jane", Amount: 30, Status: models.TransferStatusCreated, IdempotencyKey: "k-1"}
+	require.ErrorIs(t, repo.Create(ctx, again), transfererrors.ErrTransferExists)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, 25.0, again.Amount, "filled in from the transfer holding the key")
+
+	// A failed transfer frees its key for the next attempt
+	require.NoError(t, repo.UpdateStatus(ctx, first.ID, models.TransferStatusCreated, models.TransferStatusFailed, ""))
+	retry := &models.Transfer{From: "Mark", To: "Jane", Amount: 25, Status: models.TransferStatusCreated, IdempotencyKey: "k-1"}
+	require.NoError(t, repo.Create(ctx, retry))
+	assert.NotEqual(t, first.ID, retry.ID)
+
+	// Transfers without a key never conflict
+	createTransfer(t, repo, "Mark", "Jane", 25, models.TransferStatusCreated)
+	createTransfer(t, repo, "Mark", "Jane", 25, models.TransferStatusCreated)
+}
+
+func TestTransferRepository_UpdateStatus(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	transfer := createTransfer(t, repo, "Mark", "Jane", 25, models.TransferStatusCreated)
+
+	tests := []struct {
+		name          string
+		id            string
+		from          models.TransferStatus
+		to            models.TransferStatus
+		expectedError error
+	}{
+		{
+			name: "allowed transition",
+			id:   transfer.ID,
+			from: models.TransferStatusCreated,
+			to:   models.TransferStatusPending,
+		},
+		{
+			name:          "stale expected status",
+			id:            transfer.ID,
+			from:          models.TransferStatusCreated,
+			to:            models.TransferStatusProcessing,
+			expectedError: transfererrors.ErrInvalidStatusTransition,
+		},
+		{
+			name:          "forbidden transition",
+			id:            transfer.ID,
+			from:          models.TransferStatusPending,
+			to:            models.TransferStatusCompleted,
+			expectedError: transfererrors.ErrInvalidStatusTransition,
+		},
+		{
+			name:          "transfer does not exist",
+			id:            "missing",
+			from:          models.TransferStatusPending,
+			to:            models.TransferStatusProcessing,
+			expectedError: transfererrors.ErrTransferNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := repo.UpdateStatus(ctx, tt.id, tt.from, tt.to, "")
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransferRepository_Execute(t *testing.T) {
+	accountRepo, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	ok := createTransfer(t, repo, "Mark", "Jane", 40, models.TransferStatusProcessing)
+	require.NoError(t, repo.Execute(ctx, ok.ID))
+
+	transfer, err := repo.GetTransfer(ctx, ok.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusCompleted, transfer.Status)
+
+	mark, err := accountRepo.GetAccount(ctx, "Mark")
+	require.NoError(t, err)
+	assert.Equal(t, 60.0, mark.Balance)
+
+	// Executing again must not move the funds twice
+	assert.ErrorIs(t, repo.Execute(ctx, ok.ID), transfererrors.ErrInvalidStatusTransition)
+
+	overdraft := createTransfer(t, repo, "Adam", "Jane", 40, models.TransferStatusProcessing)
+	assert.ErrorIs(t, repo.Execute(ctx, overdraft.ID), transfererrors.ErrInsufficientFunds)
+
+	transfer, err = repo.GetTransfer(ctx, overdraft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusProcessing, transfer.Status)
+}
+
+func TestTransferRepository_ExecuteFromSystemAccount(t *testing.T) {
+	accountRepo, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+	require.NoError(t, accountRepo.EnsureSystemAccounts(ctx, "cash"))
+
+	// Deposits take the cash account below zero
+	deposit := createTransfer(t, repo, "cash", "Mark", 40, models.TransferStatusProcessing)
+	require.NoError(t, repo.Execute(ctx, deposit.ID))
+
+	cash, err := accountRepo.GetAccount(ctx, "cash")
+	require.NoError(t, err)
+	assert.Equal(t, -40.0, cash.Balance)
+	mark, err := accountRepo.GetAccount(ctx, "Mark")
+	require.NoError(t, err)
+	assert.Equal(t, 140.0, mark.Balance)
+
+	// Customer accounts are never overdrawn, not even when a deposit is reversed
+	executeTransfer(t, repo, "Mark", "Jane", 140)
+	assert.ErrorIs(t, repo.Reverse(ctx, deposit.ID, "returned"), transfererrors.ErrInsufficientFunds)
+}
+
+func TestTransferRepository_Reverse(t *testing.T) {
+	accountRepo, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	created := createTransfer(t, repo, "Mark", "Jane", 40, models.TransferStatusProcessing)
+	require.NoError(t, repo.Execute(ctx, created.ID))
+	require.NoError(t, repo.Reverse(ctx, created.ID, "account closed"))
+
+	transfer, err := repo.GetTransfer(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusReversed, transfer.Status)
+	assert.Equal(t, "account closed", transfer.FailureReason)
+
+	mark, err := accountRepo.GetAccount(ctx, "Mark")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, mark.Balance)
+
+	// Reversing again must not move the funds back twice
+	assert.ErrorIs(t, repo.Reverse(ctx, created.ID, ""), transfererrors.ErrInvalidStatusTransition)
+
+	pending := createTransfer(t, repo, "Mark", "Jane", 40, models.TransferStatusPending)
+	assert.ErrorIs(t, repo.Reverse(ctx, pending.ID, ""), transfererrors.ErrInvalidStatusTransition)
+	assert.ErrorIs(t, repo.Reverse(ctx, "missing", ""), transfererrors.ErrTransferNotFound)
+
+	summary, err := NewLedgerRepository(accountRepo.db, logging.Discard()).Summarize(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, summary.Transfers)
+}
+
+func TestTransferRepository_CountByStatus(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+
+	createTransfer(t, repo, "Mark", "Jane", 10, models.TransferStatusPending)
+	createTransfer(t, repo, "Mark", "Jane", 20, models.TransferStatusPending)
+	createTransfer(t, repo, "Jane", "Mark", 5, models.TransferStatusCompleted)
+
+	counts, err := repo.CountByStatus(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[models.TransferStatus]int{
+		models.TransferStatusPending:   2,
+		models.TransferStatusCompleted: 1,
+	}, counts)
+}
+
+func TestTransferRepository_ClaimPending(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	first := createTransfer(t, repo, "Mark", "Jane", 1, models.TransferStatusPending)
+	second := createTransfer(t, repo, "Mark", "Jane", 2, models.TransferStatusPending)
+	createTransfer(t, repo, "Mark", "Jane", 3, models.TransferStatusCreated)
+
+	claimed, err := repo.ClaimPending(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, first.ID, claimed[0].ID)
+	assert.Equal(t, models.TransferStatusProcessing, claimed[0].Status)
+
+	claimed, err = repo.ClaimPending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, second.ID, claimed[0].ID)
+
+	claimed, err = repo.ClaimPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestTransferRepository_RequeueStale(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	stale := createTransfer(t, repo, "Mark", "Jane", 1, models.TransferStatusProcessing)
+	fresh := createTransfer(t, repo, "Mark", "Jane", 2, models.TransferStatusProcessing)
+	pending := createTransfer(t, repo, "Mark", "Jane", 3, models.TransferStatusPending)
+	inline := createTransfer(t, repo, "Mark", "Jane", 4, models.TransferStatusProcessing)
+	for _, id := range []string{stale.ID, fresh.ID} {
+		_, err := repo.db.ExecContext(ctx, "UPDATE transfers SET claimed = TRUE WHERE id = $1", id)
+		require.NoError(t, err)
+	}
+	for _, id := range []string{stale.ID, pending.ID, inline.ID} {
+		_, err := repo.db.ExecContext(ctx, "UPDATE transfers SET updated_at = NOW() - INTERVAL '1 hour' WHERE id = $1", id)
+		require.NoError(t, err)
+	}
+
+	requeued, err := repo.RequeueStale(ctx, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, requeued, 1)
+	assert.Equal(t, stale.ID, requeued[0].ID)
+	assert.Equal(t, models.TransferStatusPending, requeued[0].Status)
+
+	stored, err := repo.GetTransfer(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusProcessing, stored.Status, "transfers within their lease are left alone")
+
+	stored, err = repo.GetTransfer(ctx, inline.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusProcessing, stored.Status, "transfers executed by their caller are not requeued")
+
+	claimed, err := repo.ClaimPending(ctx, 10)
+	require.NoError(t, err)
+	assert.Len(t, claimed, 2, "the requeued transfer is claimed again")
+
+	requeued, err = repo.RequeueStale(ctx, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, requeued)
+}
+
+func TestTransferRepository_FailStale(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	inline := createTransfer(t, repo, "Mark", "Jane", 1, models.TransferStatusProcessing)
+	fresh := createTransfer(t, repo, "Mark", "Jane", 2, models.TransferStatusProcessing)
+	pending := createTransfer(t, repo, "Mark", "Jane", 3, models.TransferStatusPending)
+	claimed, err := repo.ClaimPending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	for _, id := range []string{inline.ID, pending.ID} {
+		_, err := repo.db.ExecContext(ctx, "UPDATE transfers SET updated_at = NOW() - INTERVAL '1 hour' WHERE id = $1", id)
+		require.NoError(t, err)
+	}
+
+	failed, err := repo.FailStale(ctx, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, inline.ID, failed[0].ID)
+	assert.Equal(t, models.TransferStatusFailed, failed[0].Status)
+	assert.Equal(t, reasonInterrupted, failed[0].FailureReason)
+
+	stored, err := repo.GetTransfer(ctx, fresh.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusProcessing, stored.Status, "transfers within their lease are left alone")
+
+	stored, err = repo.GetTransfer(ctx, pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransferStatusProcessing, stored.Status, "claimed transfers are requeued rather than failed")
+}
+
+func TestTransferRepository_ListByAccount(t *testing.T) {
+	_, repo := setupTransferTestDB(t)
+	ctx := context.Background()
+
+	outgoing := createTransfer(t, repo, "Mark", "Jane", 1, models.TransferStatusCompleted)
+	createTransfer(t, repo, "Jane", "Adam", 2, models.TransferStatusCompleted)
+	incoming := createTransfer(t, repo, "Adam", "Mark", 3, models.TransferStatusCompleted)
+
+	transfers, err := repo.ListByAccount(ctx, "Mark", 10)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	assert.Equal(t, incoming.ID, transfers[0].ID)
+	assert.Equal(t, outgoing.ID, transfers[1].ID)
+
+	transfers, err = repo.ListByAccount(ctx, "Mark", 1)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, incoming.ID, transfers[0].ID)
+}
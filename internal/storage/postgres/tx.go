@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"money-transfer/internal/domain/transfer_errors"
+
+	"money-transfer/internal/tracing"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Postgres error codes of transactions that lost a race and may succeed when retried
+const (
+	codeSerializationFailure = "40001"
+	codeDeadlockDetected     = "40P01"
+)
+
+// serializable are the options of transactions that must not observe concurrent changes
+var serializable = &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+var tracer = otel.Tracer("money-transfer/internal/storage/postgres")
+
+// runInTx executes fn inside a transaction, committing only if fn succeeds
+// fn must run its statements with the context it is given so they are traced as part of
+// the transaction. Serialization failures and deadlocks are reported as ErrSerializationFailure.
+func runInTx(
+	ctx context.Context, db *sql.DB, logger *slog.Logger, opts *sql.TxOptions,
+	fn func(ctx context.Context, tx *sql.Tx) error,
+) (err error) {
+	isolation := sql.LevelDefault
+	if opts != nil {
+		isolation = opts.Isolation
+	}
+	ctx, span := tracing.StartChild(ctx, tracer, "postgres.transaction",
+		trace.WithAttributes(attribute.String("db.transaction.isolation", isolation.String())))
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			logger.ErrorContext(ctx, "failed to roll back transaction", "error", err)
+		}
+	}()
+
+	if err := fn(ctx, tx); err != nil {
+		logger.DebugContext(ctx, "rolling back transaction", "error", err)
+		return retryable(err)
+	}
+
+	return retryable(tx.Commit())
+}
+
+// retryable marks errors of transactions that may succeed when retried with ErrSerializationFailure
+func retryable(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code == codeSerializationFailure || pqErr.Code == codeDeadlockDetected) {
+		return fmt.Errorf("%w: %v", transfererrors.ErrSerializationFailure, err)
+	}
+	return err
+}
@@ -0,0 +1,266 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+
+	"github.com/lib/pq"
+)
+
+// webhookSubscriptionColumns lists the columns scanned by scanWebhookSubscription, in order
+const webhookSubscriptionColumns = "id, url, event_types, secret, created_at"
+
+// webhookDeliveryColumns lists the columns scanned by scanWebhookDelivery, in order
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_response_code, last_error, delivered_at, created_at`
+
+// WebhookRepository handles all database operations related to webhooks
+type WebhookRepository struct {
+	db     *sql.DB
+	logger *slog.Logger
+}
+
+// NewWebhookRepository creates a new instance of WebhookRepository
+func NewWebhookRepository(db *sql.DB, logger *slog.Logger) *WebhookRepository {
+	return &WebhookRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateSubscription persists a new subscription and fills in its ID and creation time
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, subscription *models.WebhookSubscription) error {
+	eventTypes := make([]string, 0, len(subscription.EventTypes))
+	for _, eventType := range subscription.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return r.db.QueryRowContext(ctx, `
+		INSERT INTO webhook_subscriptions (url, event_types, secret)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`,
+		subscription.URL, pq.Array(eventTypes), subscription.Secret).
+		Scan(&subscription.ID, &subscription.CreatedAt)
+}
+
+// GetSubscription retrieves a subscription by ID, including its secret
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	subscription, err := scanWebhookSubscription(r.db.QueryRowContext(ctx,
+		"SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions WHERE id = $1", id))
+	if err == sql.ErrNoRows {
+		return nil, transfererrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ListSubscriptions returns all subscriptions, oldest first
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]*models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions ORDER BY created_at")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []*models.WebhookSubscription{}
+	for rows.Next() {
+		subscription, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// DeleteSubscription removes a subscription; its deliveries are removed by cascade
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return transfererrors.ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries queues the event for every subscription interested in its type
+// Re-enqueuing an event that was already queued is a no-op, so outbox redeliveries are harmless
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, event *models.Event, payload []byte) (int, error) {
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3::jsonb
+		FROM webhook_subscriptions
+		WHERE cardinality(event_types) = 0 OR $2 = ANY(event_types)
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`,
+		event.ID, event.Type, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	return int(rowsAffected), err
+}
+
+// ClaimDueDeliveries leases up to limit due deliveries by pushing their next attempt past the lease
+// A worker that dies mid-attempt therefore only delays the delivery until the lease expires
+func (r *WebhookRepository) ClaimDueDeliveries(
+	ctx context.Context, limit int, lease time.Duration,
+) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = NOW() + make_interval(secs => $1)
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = $2 AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns,
+		lease.Seconds(), models.WebhookDeliveryPending, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookDeliveries(rows)
+}
+
+// RecordAttempt stores the outcome of a delivery attempt
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, id string, attempt models.WebhookAttempt) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1,
+			attempts = attempts + 1,
+			last_response_code = $2,
+			last_error = $3,
+			next_attempt_at = $4,
+			delivered_at = CASE WHEN $5::boolean THEN NOW() ELSE delivered_at END
+		WHERE id = $6`,
+		attempt.Status, attempt.ResponseCode, attempt.Error, attempt.NextAttemptAt,
+		attempt.Status == models.WebhookDeliveryDelivered, id)
+	return err
+}
+
+// ListDeliveries returns the most recent deliveries of a subscription, newest first
+func (r *WebhookRepository) ListDeliveries(
+	ctx context.Context, subscriptionID string, limit int,
+) ([]*models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookDeliveries(rows)
+}
+
+// Redeliver resets a delivery so it is attempted again immediately with a fresh attempt budget
+func (r *WebhookRepository) Redeliver(
+	ctx context.Context, subscriptionID, deliveryID string,
+) (*models.WebhookDelivery, error) {
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = 0, next_attempt_at = NOW(), last_error = ''
+		WHERE id = $2 AND subscription_id = $3
+		RETURNING `+webhookDeliveryColumns,
+		models.WebhookDeliveryPending, deliveryID, subscriptionID))
+	if err == sql.ErrNoRows {
+		return nil, transfererrors.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// scanWebhookSubscription reads a subscription selected with webhookSubscriptionColumns
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	var eventTypes []string
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.URL,
+		pq.Array(&eventTypes),
+		&subscription.Secret,
+		&subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.EventTypes = make([]models.EventType, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		subscription.EventTypes = append(subscription.EventTypes, models.EventType(eventType))
+	}
+
+	return &subscription, nil
+}
+
+// scanWebhookDelivery reads a delivery selected with webhookDeliveryColumns
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var deliveredAt sql.NullTime
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.NextAttemptAt,
+		&delivery.LastResponseCode,
+		&delivery.LastError,
+		&deliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+
+	return &delivery, nil
+}
+
+// scanWebhookDeliveries reads and closes rows selected with webhookDeliveryColumns
+func scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	defer rows.Close()
+
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"money-transfer/internal/domain/models"
+	"money-transfer/internal/domain/transfer_errors"
+	"money-transfer/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupWebhookTestDB(t *testing.T) *WebhookRepository {
+	t.Helper()
+	return NewWebhookRepository(setupTestDB(t).db, logging.Discard())
+}
+
+func createSubscription(t *testing.T, repo *WebhookRepository, eventTypes ...models.EventType) *models.WebhookSubscription {
+	t.Helper()
+	subscription := &models.WebhookSubscription{URL: "http://localhost/hooks", EventTypes: eventTypes, Secret: "s3cret"}
+	require.NoError(t, repo.CreateSubscription(context.Background(), subscription))
+	return subscription
+}
+
+func TestWebhookRepository_EnqueueDeliveries(t *testing.T) {
+	repo := setupWebhookTestDB(t)
+	ctx := context.Background()
+
+	all := createSubscription(t, repo)
+	completedOnly := createSubscription(t, repo, models.EventTransferCompleted)
+
+	created := &models.Event{ID: "e-1", Type: models.EventTransferCreated}
+	queued, err := repo.EnqueueDeliveries(ctx, created, []byte(`{"id":"e-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+
+	completed := &models.Event{ID: "e-2", Type: models.EventTransferCompleted}
+	queued, err = repo.EnqueueDeliveries(ctx, completed, []byte(`{"id":"e-2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, queued)
+
+	// A redelivered outbox event must not be queued twice
+	queued, err = repo.EnqueueDeliveries(ctx, completed, []byte(`{"id":"e-2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 0, queued)
+
+	deliveries, err := repo.ListDeliveries(ctx, all.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 2)
+
+	deliveries, err = repo.ListDeliveries(ctx, completedOnly.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "e-2", deliveries[0].EventID)
+	assert.JSONEq(t, `{"id":"e-2"}`, string(deliveries[0].Payload))
+}
+
+func TestWebhookRepository_DeliveryLifecycle(t *testing.T) {
+	repo := setupWebhookTestDB(t)
+	ctx := context.Background()
+
+	subscription := createSubscription(t, repo)
+	_, err := repo.EnqueueDeliveries(ctx, &models.Event{ID: "e-1", Type: models.EventTransferCreated}, []byte(`{}`))
+	require.NoError(t, err)
+
+	claimed, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	// Leased deliveries are not handed out again
+	again, err := repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	require.NoError(t, repo.RecordAttempt(ctx, claimed[0].ID, models.WebhookAttempt{
+		Status:        models.WebhookDeliveryDead,
+		ResponseCode:  500,
+		Error:         "endpoint responded 500",
+		NextAttemptAt: time.Now(),
+	}))
+
+	deliveries, err := repo.ListDeliveries(ctx, subscription.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliveryDead, deliveries[0].Status)
+	assert.Equal(t, 1, deliveries[0].Attempts)
+	assert.Equal(t, 500, deliveries[0].LastResponseCode)
+
+	redelivered, err := repo.Redeliver(ctx, subscription.ID, claimed[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryPending, redelivered.Status)
+	assert.Equal(t, 0, redelivered.Attempts)
+
+	claimed, err = repo.ClaimDueDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	require.NoError(t, repo.RecordAttempt(ctx, claimed[0].ID, models.WebhookAttempt{
+		Status:        models.WebhookDeliveryDelivered,
+		ResponseCode:  204,
+		NextAttemptAt: time.Now(),
+	}))
+
+	deliveries, err = repo.ListDeliveries(ctx, subscription.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, models.WebhookDeliveryDelivered, deliveries[0].Status)
+	assert.NotNil(t, deliveries[0].DeliveredAt)
+
+	_, err = repo.Redeliver(ctx, subscription.ID, "missing")
+	assert.ErrorIs(t, err, transfererrors.ErrWebhookDeliveryNotFound)
+}
+
+func TestWebhookRepository_DeleteSubscription(t *testing.T) {
+	repo := setupWebhookTestDB(t)
+	ctx := context.Background()
+
+	subscription := createSubscription(t, repo)
+	require.NoError(t, repo.DeleteSubscription(ctx, subscription.ID))
+
+	_, err := repo.GetSubscription(ctx, subscription.ID)
+	assert.ErrorIs(t, err, transfererrors.ErrWebhookNotFound)
+	assert.ErrorIs(t, repo.DeleteSubscription(ctx, subscription.ID), transfererrors.ErrWebhookNotFound)
+}
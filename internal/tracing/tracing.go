@@ -0,0 +1,151 @@
+// Package tracing correlates the work done for a single request and records it as
+// OpenTelemetry spans
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ServiceName identifies this application in exported traces
+const ServiceName = "money-transfer"
+
+// traceIDLength is the length of a hex encoded W3C trace ID
+const traceIDLength = 32
+
+// Propagator reads and writes the W3C traceparent header
+var Propagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+type traceIDKey struct{}
+
+// Config selects where spans are exported
+type Config struct {
+	Exporter     string // none, stdout, file or otlp
+	FilePath     string
+	OTLPEndpoint string  // URL of an OTLP/HTTP collector, e.g. http://localhost:4318
+	SampleRatio  float64 // share of new traces that are recorded; incoming traces keep their decision
+}
+
+// Provider records spans and exports them until it is shut down
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	file     io.Closer
+}
+
+// Setup installs a tracer provider exporting spans as configured as the global one
+// Spans are created and carry trace IDs even when the exporter is none.
+func Setup(ctx context.Context, cfg Config) (*Provider, error) {
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe tracing resource: %w", err)
+	}
+
+	p := &Provider{}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+
+	switch cfg.Exporter {
+	case "", "none":
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case "file":
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+		p.file = file
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case "otlp":
+		exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+
+	p.provider = sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(p.provider)
+	otel.SetTextMapPropagator(Propagator)
+
+	return p, nil
+}
+
+// Shutdown exports the spans still buffered and releases the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.provider.Shutdown(ctx)
+	if p.file != nil {
+		err = errors.Join(err, p.file.Close())
+	}
+	return err
+}
+
+// StartChild starts a span only when ctx is already part of a trace, so that work
+// polled in the background does not start a new trace on every tick
+func StartChild(
+	ctx context.Context, tracer trace.Tracer, name string, opts ...trace.SpanStartOption,
+) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// End records err, if any, as the outcome of span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// NewTraceID returns a random W3C compatible trace ID
+func NewTraceID() string {
+	var id [traceIDLength / 2]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// WithTraceID returns a copy of ctx carrying the trace ID
+// It is only consulted when ctx carries no span, e.g. when no tracer provider is installed.
+func WithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, traceID)
+}
+
+// TraceIDFromContext returns the trace ID of the span in ctx, falling back to the
+// one stored with WithTraceID, or an empty string
+func TraceIDFromContext(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	traceID, _ := ctx.Value(traceIDKey{}).(string)
+	return traceID
+}
@@ -0,0 +1,92 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"money-transfer/internal/tracing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     tracing.Config
+		wantErr bool
+	}{
+		{name: "none", cfg: tracing.Config{Exporter: "none", SampleRatio: 1}},
+		{name: "default", cfg: tracing.Config{SampleRatio: 1}},
+		{name: "stdout", cfg: tracing.Config{Exporter: "stdout", SampleRatio: 1}},
+		{name: "otlp", cfg: tracing.Config{Exporter: "otlp", OTLPEndpoint: "http://localhost:4318", SampleRatio: 1}},
+		{name: "unknown exporter", cfg: tracing.Config{Exporter: "zipkin"}, wantErr: true},
+		{name: "unwritable file", cfg: tracing.Config{Exporter: "file", FilePath: t.TempDir()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := tracing.Setup(context.Background(), tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, provider.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestSetup_FileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	provider, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "file", FilePath: path, SampleRatio: 1})
+	require.NoError(t, err)
+
+	ctx, span := otel.Tracer("test").Start(context.Background(), "transfer")
+	traceID := tracing.TraceIDFromContext(ctx)
+	assert.Len(t, traceID, 32)
+	span.End()
+
+	require.NoError(t, provider.Shutdown(context.Background()))
+
+	exported, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(exported), `"Name":"transfer"`)
+	assert.Contains(t, string(exported), traceID)
+}
+
+func TestStartChild(t *testing.T) {
+	provider, err := tracing.Setup(context.Background(), tracing.Config{SampleRatio: 1})
+	require.NoError(t, err)
+	defer func() { _ = provider.Shutdown(context.Background()) }()
+	tracer := otel.Tracer("test")
+
+	// Background work outside a trace starts no span
+	ctx, span := tracing.StartChild(context.Background(), tracer, "poll")
+	assert.False(t, span.SpanContext().IsValid())
+	assert.Empty(t, tracing.TraceIDFromContext(ctx))
+
+	parentCtx, parent := tracer.Start(context.Background(), "request")
+	ctx, span = tracing.StartChild(parentCtx, tracer, "query")
+	assert.True(t, span.IsRecording())
+	assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	assert.NotEqual(t, parent.SpanContext().SpanID(), trace.SpanContextFromContext(ctx).SpanID())
+}
+
+func TestTraceIDFromContext(t *testing.T) {
+	assert.Empty(t, tracing.TraceIDFromContext(context.Background()))
+
+	ctx := tracing.WithTraceID(context.Background(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tracing.TraceIDFromContext(ctx))
+
+	// The trace of a span takes precedence
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{1},
+	}))
+	assert.Equal(t, "01000000000000000000000000000000", tracing.TraceIDFromContext(ctx))
+}